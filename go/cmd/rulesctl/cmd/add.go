@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"strings"
@@ -22,6 +23,10 @@ var (
 	addOptQueryRE           string
 	addOptLeadingCommentRE  string
 	addOptTrailingCommentRE string
+	addOptMaxQPS            float64
+	addOptOptimizerHints    string
+	addOptIndexHints        []string
+	addOptSampleRate        float64
 	// TODO: other stuff, bind vars etc
 )
 
@@ -54,6 +59,29 @@ func runAdd(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	switch ruleAction {
+	case vtrules.QRThrottle:
+		if err := rule.SetMaxQPS(addOptMaxQPS); err != nil {
+			log.Fatalf("Max QPS invalid for the throttle action: %v", err)
+		}
+	case vtrules.QRRewrite:
+		if addOptOptimizerHints == "" && len(addOptIndexHints) == 0 {
+			log.Fatalf("The rewrite action needs --optimizer-hints or --index-hint")
+		}
+		rule.SetOptimizerHints(addOptOptimizerHints)
+		for _, ih := range addOptIndexHints {
+			if err := addIndexHint(rule, ih); err != nil {
+				log.Fatalf("Index hint invalid '%v': %v", ih, err)
+			}
+		}
+	case vtrules.QRLog:
+		if addOptSampleRate != 0 {
+			if err := rule.SetSampleRate(addOptSampleRate); err != nil {
+				log.Fatalf("Sample rate invalid: %v", err)
+			}
+		}
+	}
+
 	var rules *vtrules.Rules
 	_, err := os.Stat(configFile)
 	if os.IsNotExist(err) {
@@ -97,6 +125,20 @@ func mkPlanSlice() []planbuilder.PlanType {
 	return plans
 }
 
+// addIndexHint parses an index hint of the form <table>:<use|ignore|force>:<index>[,<index>...]
+// and adds it to the rule.
+func addIndexHint(rule *vtrules.Rule, ih string) error {
+	parts := strings.Split(ih, ":")
+	if len(parts) != 3 {
+		return fmt.Errorf("want <table>:<use|ignore|force>:<index>[,<index>...]")
+	}
+	hintType, err := vtrules.MapStrIndexHintType(strings.ToUpper(parts[1]))
+	if err != nil {
+		return err
+	}
+	return rule.AddIndexHint(parts[0], hintType, strings.Split(parts[2], ",")...)
+}
+
 func mkAction() vtrules.Action {
 	switch strings.ToLower(addOptAction) {
 	case "fail":
@@ -105,6 +147,12 @@ func mkAction() vtrules.Action {
 		return vtrules.QRFailRetry
 	case "continue":
 		return vtrules.QRContinue
+	case "throttle":
+		return vtrules.QRThrottle
+	case "rewrite":
+		return vtrules.QRRewrite
+	case "log":
+		return vtrules.QRLog
 	default:
 		log.Fatalf("Unknown action '%v'", addOptAction)
 	}
@@ -139,7 +187,7 @@ func Add() *cobra.Command {
 		&addOptAction,
 		"action", "a",
 		"",
-		"What action should be taken when this rule is matched {continue, fail, fail-retry} (required)")
+		"What action should be taken when this rule is matched {continue, fail, fail-retry, throttle, rewrite, log} (required)")
 	addCmd.Flags().StringSliceVarP(
 		&addOptPlans,
 		"plan", "p",
@@ -165,6 +213,26 @@ func Add() *cobra.Command {
 		"trailing-comment", "r",
 		"",
 		"A regexp that will be applied to comments after a SQL statement")
+	addCmd.Flags().Float64Var(
+		&addOptMaxQPS,
+		"max-qps",
+		0,
+		"The rate at which the throttle action lets matching queries through; queries over the rate fail")
+	addCmd.Flags().StringVar(
+		&addOptOptimizerHints,
+		"optimizer-hints",
+		"",
+		"Optimizer hints the rewrite action adds to matching queries, e.g. \"MAX_EXECUTION_TIME(1000)\"")
+	addCmd.Flags().StringArrayVar(
+		&addOptIndexHints,
+		"index-hint",
+		nil,
+		"An index hint the rewrite action adds to matching queries, as <table>:<use|ignore|force>:<index>[,<index>...]; may be specified multiple times")
+	addCmd.Flags().Float64Var(
+		&addOptSampleRate,
+		"sample-rate",
+		0,
+		"The fraction of matching queries the log action sends to the rule log; all of them if unset")
 
 	for _, f := range []string{"name", "action"} {
 		addCmd.MarkFlagRequired(f)
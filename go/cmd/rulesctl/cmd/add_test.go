@@ -102,6 +102,45 @@ func TestAdd(t *testing.T) {
     ]
   }
 ]
+`,
+		},
+		{
+			name: "Action rewrite",
+			args: []string{"--dry-run=true", "--name=Rule", `--description="New rules that will be added to the file"`, "--action=rewrite", "--optimizer-hints=MAX_EXECUTION_TIME(1000)", "--index-hint=Temp:force:idx1,idx2"},
+			expectedOutput: `[
+  {
+    "Description": "Some value",
+    "Name": "Name",
+    "Action": "FAIL"
+  },
+  {
+    "Description": "\"New rules that will be added to the file\"",
+    "Name": "Rule",
+    "Query": "secret",
+    "LeadingComment": "None",
+    "TrailingComment": "Yoho",
+    "Plans": [
+      "Select",
+      "Select",
+      "Select"
+    ],
+    "TableNames": [
+      "Temp"
+    ],
+    "Action": "REWRITE",
+    "OptimizerHints": "MAX_EXECUTION_TIME(1000)",
+    "IndexHints": [
+      {
+        "Table": "Temp",
+        "Type": "FORCE",
+        "Indexes": [
+          "idx1",
+          "idx2"
+        ]
+      }
+    ]
+  }
+]
 `,
 		},
 	}
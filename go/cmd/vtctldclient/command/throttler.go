@@ -27,18 +27,27 @@ import (
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle"
 )
 
 var (
 	// UpdateThrottlerConfig makes a UpdateThrottlerConfig gRPC call to a vtctld.
 	UpdateThrottlerConfig = &cobra.Command{
-		Use:                   "UpdateThrottlerConfig [--enable|--disable] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] [--throttle-app|unthrottle-app=<name>] [--throttle-app-ratio=<float, range [0..1]>] [--throttle-app-duration=<duration>] <keyspace>",
+		Use:                   "UpdateThrottlerConfig [--enable|--disable] [--metric-name=<name>] [--threshold=<float64>] [--custom-query=<query>] [--check-as-check-self|--check-as-check-shard] [--throttle-app|unthrottle-app=<name>] [--throttle-app-ratio=<float, range [0..1]>] [--throttle-app-duration=<duration>] [--app-name=<name> --app-metrics=<metrics>] <keyspace>",
 		Short:                 "Update the tablet throttler configuration for all tablets in the given keyspace (across all cells)",
 		DisableFlagsInUseLine: true,
 		Args:                  cobra.ExactArgs(1),
 		RunE:                  commandUpdateThrottlerConfig,
 	}
+	// CheckThrottler makes a CheckThrottler gRPC call to a vtctld.
+	CheckThrottler = &cobra.Command{
+		Use:                   "CheckThrottler [--app-name=<name>] <tablet alias>",
+		Short:                 "Issue a throttler check on the given tablet, and output the results of the checked metrics.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandCheckThrottler,
+	}
 )
 
 var (
@@ -46,6 +55,7 @@ var (
 	throttledAppRule             topodatapb.ThrottledAppRule
 	unthrottledAppRule           topodatapb.ThrottledAppRule
 	throttledAppDuration         time.Duration
+	checkThrottlerOptions        vtctldatapb.CheckThrottlerRequest
 )
 
 func commandUpdateThrottlerConfig(cmd *cobra.Command, args []string) error {
@@ -74,10 +84,34 @@ func commandUpdateThrottlerConfig(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func commandCheckThrottler(cmd *cobra.Command, args []string) error {
+	alias, err := topoproto.ParseTabletAlias(cmd.Flags().Arg(0))
+	if err != nil {
+		return err
+	}
+
+	cli.FinishedParsing(cmd)
+
+	checkThrottlerOptions.TabletAlias = alias
+	resp, err := client.CheckThrottler(commandCtx, &checkThrottlerOptions)
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s\n", data)
+
+	return nil
+}
+
 func init() {
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.Enable, "enable", false, "Enable the throttler")
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.Disable, "disable", false, "Disable the throttler")
-	UpdateThrottlerConfig.Flags().Float64Var(&updateThrottlerConfigOptions.Threshold, "threshold", 0, "threshold for the either default check (replication lag seconds) or custom check")
+	UpdateThrottlerConfig.Flags().Float64Var(&updateThrottlerConfigOptions.Threshold, "threshold", 0, "threshold for the either default check (replication lag seconds) or custom check, or for the metric given in --metric-name")
+	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.MetricName, "metric-name", "", "name of the metric that --threshold applies to (lag, threads_running, loadavg, history_list_length, custom). loadavg is the load average per CPU core of the vttablet host, not of the MySQL host if they differ. A zero threshold restores the metric's built-in threshold. Empty for the default metric")
 	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.CustomQuery, "custom-query", "", "custom throttler check query")
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.CheckAsCheckSelf, "check-as-check-self", false, "/throttler/check requests behave as is /throttler/check-self was called")
 	UpdateThrottlerConfig.Flags().BoolVar(&updateThrottlerConfigOptions.CheckAsCheckShard, "check-as-check-shard", false, "use standard behavior for /throttler/check requests")
@@ -88,5 +122,12 @@ func init() {
 	UpdateThrottlerConfig.Flags().DurationVar(&throttledAppDuration, "throttle-app-duration", throttle.DefaultAppThrottleDuration, "duration after which throttled app rule expires (app specififed in --throttled-app)")
 	UpdateThrottlerConfig.Flags().BoolVar(&throttledAppRule.Exempt, "throttle-app-exempt", throttledAppRule.Exempt, "exempt this app from being at all throttled. WARNING: use with extreme care, as this is likely to push metrics beyond the throttler's threshold, and starve other apps")
 
+	UpdateThrottlerConfig.Flags().StringVar(&updateThrottlerConfigOptions.AppName, "app-name", "", "an app name (or \"all\" for all apps) whose checked metrics are set to --app-metrics")
+	UpdateThrottlerConfig.Flags().StringSliceVar(&updateThrottlerConfigOptions.AppCheckedMetrics, "app-metrics", nil, "comma separated list of metrics that the app given in --app-name is checked against. Empty to check the app against the default metric")
+
 	Root.AddCommand(UpdateThrottlerConfig)
+
+	CheckThrottler.Flags().StringVar(&checkThrottlerOptions.AppName, "app-name", "", "app name to check the throttler with. Empty for the throttler's own \"vitess\" app, which is checked against all metrics")
+
+	Root.AddCommand(CheckThrottler)
 }
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package command
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"vitess.io/vitess/go/cmd/vtctldclient/cli"
+
+	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
+)
+
+var (
+	// DistributedTransaction is the parent command for the distributed
+	// (two-phase commit) transaction commands.
+	DistributedTransaction = &cobra.Command{
+		Use:                   "DistributedTransaction <cmd>",
+		Short:                 "Perform commands on distributed transactions.",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+	}
+	// DistributedTransactionList makes a GetUnresolvedTransactions gRPC call to a vtctld.
+	DistributedTransactionList = &cobra.Command{
+		Use:   "list [--abandon-age <duration>] <keyspace>",
+		Short: "Lists the unresolved distributed transactions of a keyspace, oldest first.",
+		Long: `Lists the unresolved distributed transactions of a keyspace, oldest first.
+
+The transactions are read from the dt_state and dt_participant sidecar tables on
+the primary of every shard of the keyspace. With --abandon-age, only the
+transactions created longer ago than that age are listed.`,
+		Example: `DistributedTransaction list commerce
+DistributedTransaction list --abandon-age 5m commerce`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDistributedTransactionList,
+	}
+	// DistributedTransactionRead makes a GetTransactionInfo gRPC call to a vtctld.
+	DistributedTransactionRead = &cobra.Command{
+		Use:                   "read <dtid>",
+		Short:                 "Shows the state, participants and prepared statements of a distributed transaction.",
+		Example:               "DistributedTransaction read commerce:-80:1722289456123456789",
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDistributedTransactionRead,
+	}
+	// DistributedTransactionConclude makes a ConcludeTransaction gRPC call to a vtctld.
+	DistributedTransactionConclude = &cobra.Command{
+		Use:   "conclude [--force] <dtid>",
+		Short: "Resolves an unresolved distributed transaction.",
+		Long: `Resolves an unresolved distributed transaction.
+
+A transaction with a commit decision is committed on all of its participants,
+and a transaction with a rollback decision is rolled back on all of them. The
+transaction is then removed from the metadata manager shard. Concluding a
+transaction that was already resolved is a no-op.
+
+A transaction that is still in the PREPARE state has no decision yet, and its
+coordinator may still be committing it. It is only rolled back with --force,
+which should only be used once the transaction has been abandoned.`,
+		Example: `DistributedTransaction conclude commerce:-80:1722289456123456789
+DistributedTransaction conclude --force commerce:-80:1722289456123456789`,
+		DisableFlagsInUseLine: true,
+		Args:                  cobra.ExactArgs(1),
+		RunE:                  commandDistributedTransactionConclude,
+	}
+)
+
+var distributedTransactionListOptions = struct {
+	AbandonAge time.Duration
+}{}
+
+var distributedTransactionConcludeOptions = struct {
+	Force bool
+}{}
+
+func commandDistributedTransactionList(cmd *cobra.Command, args []string) error {
+	keyspace := cmd.Flags().Arg(0)
+	if distributedTransactionListOptions.AbandonAge < 0 {
+		return fmt.Errorf("--abandon-age must not be negative, got %v", distributedTransactionListOptions.AbandonAge)
+	}
+
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetUnresolvedTransactions(commandCtx, &vtctldatapb.GetUnresolvedTransactionsRequest{
+		Keyspace:   keyspace,
+		AbandonAge: int64(distributedTransactionListOptions.AbandonAge / time.Second),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func commandDistributedTransactionRead(cmd *cobra.Command, args []string) error {
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.GetTransactionInfo(commandCtx, &vtctldatapb.GetTransactionInfoRequest{
+		Dtid: cmd.Flags().Arg(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func commandDistributedTransactionConclude(cmd *cobra.Command, args []string) error {
+	dtid := cmd.Flags().Arg(0)
+	cli.FinishedParsing(cmd)
+
+	resp, err := client.ConcludeTransaction(commandCtx, &vtctldatapb.ConcludeTransactionRequest{
+		Dtid:  dtid,
+		Force: distributedTransactionConcludeOptions.Force,
+	})
+	if err != nil {
+		return err
+	}
+
+	data, err := cli.MarshalJSONPretty(resp)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("%s\n", data)
+	return nil
+}
+
+func init() {
+	DistributedTransactionList.Flags().DurationVar(&distributedTransactionListOptions.AbandonAge, "abandon-age", 0, "Only list the transactions created longer ago than this age.")
+	DistributedTransaction.AddCommand(DistributedTransactionList)
+	DistributedTransaction.AddCommand(DistributedTransactionRead)
+	DistributedTransactionConclude.Flags().BoolVar(&distributedTransactionConcludeOptions.Force, "force", false, "Roll back the transaction even if it is still in the PREPARE state, which its coordinator may still be committing.")
+	DistributedTransaction.AddCommand(DistributedTransactionConclude)
+
+	Root.AddCommand(DistributedTransaction)
+}
@@ -47,6 +47,10 @@ var (
 	normalize          bool
 	dbName             string
 	plannerVersionStr  string
+	adviseVindexes     bool
+	queryLogFileFlag   string
+
+	adviseKeyspace = "ks"
 
 	numShards       = 2
 	replicationMode = "ROW"
@@ -82,7 +86,9 @@ If no keyspace name is present, VTExplain will return the following error:
 		Example: "Explain how Vitess will execute the query `SELECT * FROM users` using the VSchema contained in `vschemas.json` and the database schema `schema.sql`:\n\n" +
 			"```\nvtexplain --vschema-file vschema.json --schema-file schema.sql --sql \"SELECT * FROM users\"\n```\n\n" +
 			"Explain how the example will execute on 128 shards using Row-based replication:\n\n" +
-			"```\nvtexplain -- -shards 128 --vschema-file vschema.json --schema-file schema.sql --replication-mode \"ROW\" --output-mode text --sql \"INSERT INTO users (user_id, name) VALUES(1, 'john')\"\n```\n",
+			"```\nvtexplain -- -shards 128 --vschema-file vschema.json --schema-file schema.sql --replication-mode \"ROW\" --output-mode text --sql \"INSERT INTO users (user_id, name) VALUES(1, 'john')\"\n```\n\n" +
+			"Recommend vindexes for the tables in `schema.sql` based on the queries in a vtgate query log, simulating 16 shards:\n\n" +
+			"```\nvtexplain --advise-vindexes --schema-file schema.sql --query-log-file vtgate_querylog.txt --shards 16\n```\n",
 		Args:    cobra.NoArgs,
 		PreRunE: servenv.CobraPreRunE,
 		Version: servenv.AppVersion.String(),
@@ -107,6 +113,9 @@ func init() {
 	Main.Flags().IntVar(&numShards, "shards", numShards, "Number of shards per keyspace. Passing --ks-shard-map/--ks-shard-map-file causes this flag to be ignored.")
 	Main.Flags().StringVar(&executionMode, "execution-mode", executionMode, "The execution mode to simulate -- must be set to multi, legacy-autocommit, or twopc")
 	Main.Flags().StringVar(&outputMode, "output-mode", outputMode, "Output in human-friendly text or json")
+	Main.Flags().BoolVar(&adviseVindexes, "advise-vindexes", adviseVindexes, "Recommend a primary vindex for each table of the schema and lookup vindexes, based on the routing of the analyzed queries. The vschema is not used in this mode.")
+	Main.Flags().StringVar(&queryLogFileFlag, "query-log-file", queryLogFileFlag, "Identifies a vtgate query log, in text or json format, with queries to analyze when recommending vindexes")
+	Main.Flags().StringVar(&adviseKeyspace, "advise-keyspace", adviseKeyspace, "The name of the keyspace to recommend vindexes for")
 
 	acl.RegisterFlags(Main.Flags())
 }
@@ -148,7 +157,7 @@ func parseAndRun(ctx context.Context) error {
 		return fmt.Errorf("invalid value specified for planner-version of '%s' -- valid value is Gen4 or an empty value to use the default planner", plannerVersionStr)
 	}
 
-	sql, err := getFileParam(sqlFlag, sqlFileFlag, "sql", true)
+	sql, err := getFileParam(sqlFlag, sqlFileFlag, "sql", !adviseVindexes)
 	if err != nil {
 		return err
 	}
@@ -158,6 +167,10 @@ func parseAndRun(ctx context.Context) error {
 		return err
 	}
 
+	if adviseVindexes {
+		return parseAndAdvise(ctx, sql, schema)
+	}
+
 	vschema, err := getFileParam(vschemaFlag, vschemaFileFlag, "vschema", true)
 	if err != nil {
 		return err
@@ -177,11 +190,7 @@ func parseAndRun(ctx context.Context) error {
 		Target:          dbName,
 	}
 
-	env, err := vtenv.New(vtenv.Options{
-		MySQLServerVersion: servenv.MySQLServerVersion(),
-		TruncateUILen:      servenv.TruncateUILen,
-		TruncateErrLen:     servenv.TruncateErrLen,
-	})
+	env, err := newEnv()
 	if err != nil {
 		return err
 	}
@@ -206,3 +215,63 @@ func parseAndRun(ctx context.Context) error {
 
 	return nil
 }
+
+func newEnv() (*vtenv.Environment, error) {
+	return vtenv.New(vtenv.Options{
+		MySQLServerVersion: servenv.MySQLServerVersion(),
+		TruncateUILen:      servenv.TruncateUILen,
+		TruncateErrLen:     servenv.TruncateErrLen,
+	})
+}
+
+func parseAndAdvise(ctx context.Context, sql, schema string) error {
+	env, err := newEnv()
+	if err != nil {
+		return err
+	}
+
+	var queries []string
+	if sql != "" {
+		queries, err = env.Parser().SplitStatementToPieces(sql)
+		if err != nil {
+			return err
+		}
+	}
+	if queryLogFileFlag != "" {
+		f, err := os.Open(queryLogFileFlag)
+		if err != nil {
+			return fmt.Errorf("cannot read file %v: %v", queryLogFileFlag, err)
+		}
+		defer f.Close()
+		logged, err := vtexplain.ReadQueryLog(f, env.Parser())
+		if err != nil {
+			return fmt.Errorf("cannot read query log %v: %v", queryLogFileFlag, err)
+		}
+		queries = append(queries, logged...)
+	}
+	if len(queries) == 0 {
+		return fmt.Errorf("action requires queries from one of sql, sql-file or query-log-file")
+	}
+
+	opts := &vtexplain.Options{
+		ExecutionMode:   executionMode,
+		ReplicationMode: replicationMode,
+		NumShards:       numShards,
+	}
+	srvTopoCounts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
+	advice, err := vtexplain.AdviseVindexes(ctx, env, schema, queries, adviseKeyspace, opts, srvTopoCounts)
+	if err != nil {
+		return err
+	}
+
+	if outputMode == "text" {
+		fmt.Print(vtexplain.AdviceAsText(advice))
+	} else {
+		out, err := vtexplain.AdviceAsJSON(advice)
+		if err != nil {
+			return err
+		}
+		fmt.Print(out)
+	}
+	return nil
+}
@@ -0,0 +1,348 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binlog
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"vitess.io/vitess/go/mysql/datetime"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// FieldType returns the binlog type and metadata to use for the given field
+// in a TABLE_MAP event. It is the reverse of what CellValue does. ENUM, SET
+// and JSON values, whose binlog representation can't be computed from
+// their text representation, are written as blobs.
+func FieldType(field *querypb.Field) (byte, uint16) {
+	switch field.Type {
+	case querypb.Type_INT8, querypb.Type_UINT8:
+		return TypeTiny, 0
+	case querypb.Type_INT16, querypb.Type_UINT16:
+		return TypeShort, 0
+	case querypb.Type_INT24, querypb.Type_UINT24:
+		return TypeInt24, 0
+	case querypb.Type_INT32, querypb.Type_UINT32:
+		return TypeLong, 0
+	case querypb.Type_INT64, querypb.Type_UINT64:
+		return TypeLongLong, 0
+	case querypb.Type_YEAR:
+		return TypeYear, 0
+	case querypb.Type_FLOAT32:
+		return TypeFloat, 4
+	case querypb.Type_FLOAT64:
+		return TypeDouble, 8
+	case querypb.Type_DECIMAL:
+		scale := field.Decimals
+		// The length of a DECIMAL column counts the sign and the decimal point.
+		precision := field.ColumnLength
+		if field.Flags&uint32(querypb.MySqlFlag_UNSIGNED_FLAG) == 0 {
+			precision--
+		}
+		if scale > 0 {
+			precision--
+		}
+		if precision == 0 || precision > 65 || precision < scale {
+			precision = 65
+		}
+		return TypeNewDecimal, uint16(precision)<<8 | uint16(scale)
+	case querypb.Type_DATE:
+		return TypeDate, 0
+	case querypb.Type_DATETIME:
+		return TypeDateTime2, uint16(min(field.Decimals, 6))
+	case querypb.Type_TIMESTAMP:
+		return TypeTimestamp2, uint16(min(field.Decimals, 6))
+	case querypb.Type_TIME:
+		return TypeTime2, uint16(min(field.Decimals, 6))
+	case querypb.Type_VARCHAR, querypb.Type_VARBINARY, querypb.Type_CHAR, querypb.Type_BINARY:
+		if field.ColumnLength == 0 || field.ColumnLength > math.MaxUint16 {
+			return TypeVarchar, math.MaxUint16
+		}
+		return TypeVarchar, uint16(field.ColumnLength)
+	case querypb.Type_TEXT, querypb.Type_BLOB:
+		switch {
+		case field.ColumnLength == 0:
+			return TypeBlob, 4
+		case field.ColumnLength <= math.MaxUint8:
+			return TypeBlob, 1
+		case field.ColumnLength <= math.MaxUint16:
+			return TypeBlob, 2
+		case field.ColumnLength <= 1<<24-1:
+			return TypeBlob, 3
+		}
+		return TypeBlob, 4
+	case querypb.Type_BIT:
+		bits := max(field.ColumnLength, 1)
+		return TypeBit, uint16(bits/8)<<8 | uint16(bits%8)
+	case querypb.Type_GEOMETRY:
+		return TypeGeometry, 4
+	}
+	return TypeBlob, 4
+}
+
+// AppendCellValue appends the binlog representation of value to data,
+// for a column with the given binlog type and metadata.
+func AppendCellValue(data []byte, value sqltypes.Value, typ byte, metadata uint16) ([]byte, error) {
+	switch typ {
+	case TypeTiny:
+		return appendCellInt(data, value, 1)
+	case TypeShort:
+		return appendCellInt(data, value, 2)
+	case TypeInt24:
+		return appendCellInt(data, value, 3)
+	case TypeLong:
+		return appendCellInt(data, value, 4)
+	case TypeLongLong:
+		return appendCellInt(data, value, 8)
+	case TypeYear:
+		year, err := strconv.ParseUint(value.ToString(), 10, 16)
+		if err != nil {
+			return nil, err
+		}
+		if year == 0 {
+			return append(data, 0), nil
+		}
+		return append(data, byte(year-1900)), nil
+	case TypeFloat:
+		f, err := strconv.ParseFloat(value.ToString(), 32)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint32(data, math.Float32bits(float32(f))), nil
+	case TypeDouble:
+		f, err := strconv.ParseFloat(value.ToString(), 64)
+		if err != nil {
+			return nil, err
+		}
+		return binary.LittleEndian.AppendUint64(data, math.Float64bits(f)), nil
+	case TypeNewDecimal:
+		return appendCellDecimal(data, value.ToString(), int(metadata>>8), int(metadata&0xff))
+	case TypeDate:
+		d, ok := parseCellDate(value.ToString())
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid DATE value: %v", value)
+		}
+		v := uint32(d.Year())<<9 | uint32(d.Month())<<5 | uint32(d.Day())
+		return append(data, byte(v), byte(v>>8), byte(v>>16)), nil
+	case TypeDateTime2:
+		dt, ok := parseCellDateTime(value.ToString())
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid DATETIME value: %v", value)
+		}
+		ym := uint64(dt.Date.Year())*13 + uint64(dt.Date.Month())
+		ymd := ym<<5 | uint64(dt.Date.Day())
+		hms := uint64(dt.Time.Hour())<<12 | uint64(dt.Time.Minute())<<6 | uint64(dt.Time.Second())
+		v := ymd<<17 | hms + 0x8000000000
+		data = append(data, byte(v>>32), byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+		return appendCellFraction(data, int64(dt.Time.Nanosecond()/1000), metadata), nil
+	case TypeTimestamp2:
+		dt, ok := parseCellDateTime(value.ToString())
+		if !ok {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid TIMESTAMP value: %v", value)
+		}
+		var seconds int64
+		if !dt.IsZero() {
+			seconds = dt.ToStdTime(time.Time{}.In(time.UTC)).Unix()
+		}
+		data = binary.BigEndian.AppendUint32(data, uint32(seconds))
+		return appendCellFraction(data, int64(dt.Time.Nanosecond()/1000), metadata), nil
+	case TypeTime2:
+		t, _, state := datetime.ParseTime(value.ToString(), -1)
+		if state != datetime.TimeOK {
+			return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "invalid TIME value: %v", value)
+		}
+		return appendCellTime(data, t, metadata), nil
+	case TypeVarchar, TypeVarString:
+		raw := value.Raw()
+		if metadata > 255 {
+			data = binary.LittleEndian.AppendUint16(data, uint16(len(raw)))
+		} else {
+			data = append(data, byte(len(raw)))
+		}
+		return append(data, raw...), nil
+	case TypeBlob, TypeGeometry, TypeJSON:
+		raw := value.Raw()
+		var length [4]byte
+		binary.LittleEndian.PutUint32(length[:], uint32(len(raw)))
+		data = append(data, length[:metadata]...)
+		return append(data, raw...), nil
+	case TypeBit:
+		nbits := int(metadata>>8)*8 + int(metadata&0xff)
+		l := (nbits + 7) / 8
+		raw := value.Raw()
+		if len(raw) > l {
+			raw = raw[len(raw)-l:]
+		}
+		for i := len(raw); i < l; i++ {
+			data = append(data, 0)
+		}
+		return append(data, raw...), nil
+	}
+	return nil, vterrors.Errorf(vtrpc.Code_UNIMPLEMENTED, "unsupported binlog type: %v", typ)
+}
+
+func appendCellInt(data []byte, value sqltypes.Value, size int) ([]byte, error) {
+	var v uint64
+	if sqltypes.IsSigned(value.Type()) {
+		i, err := strconv.ParseInt(value.ToString(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v = uint64(i)
+	} else {
+		u, err := strconv.ParseUint(value.ToString(), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		v = u
+	}
+	for i := 0; i < size; i++ {
+		data = append(data, byte(v>>(8*i)))
+	}
+	return data, nil
+}
+
+// parseCellDate parses a DATE, including the zero date.
+func parseCellDate(s string) (datetime.Date, bool) {
+	if strings.HasPrefix(s, "0000-00-00") {
+		return datetime.Date{}, true
+	}
+	return datetime.ParseDate(s)
+}
+
+// parseCellDateTime parses a DATETIME or a TIMESTAMP, including the zero date.
+func parseCellDateTime(s string) (datetime.DateTime, bool) {
+	if strings.HasPrefix(s, "0000-00-00") {
+		t, _, state := datetime.ParseTime(strings.TrimSpace(s[len("0000-00-00"):]), -1)
+		return datetime.DateTime{Time: t}, state == datetime.TimeOK
+	}
+	dt, _, ok := datetime.ParseDateTime(s, -1)
+	return dt, ok
+}
+
+// appendCellFraction appends the fractional seconds of a temporal type
+// with the given precision.
+func appendCellFraction(data []byte, micro int64, precision uint16) []byte {
+	switch precision {
+	case 1, 2:
+		return append(data, byte(micro/10000))
+	case 3, 4:
+		v := micro / 100
+		return append(data, byte(v>>8), byte(v))
+	case 5, 6:
+		return append(data, byte(micro>>16), byte(micro>>8), byte(micro))
+	}
+	return data
+}
+
+// appendCellTime appends a TIME2 value. Like MySQL, it packs the value as
+// a signed integer first, so that negative values with a fractional part
+// are stored the way CellValue expects them.
+func appendCellTime(data []byte, t datetime.Time, precision uint16) []byte {
+	hms := int64(t.Hour())<<12 | int64(t.Minute())<<6 | int64(t.Second())
+	packed := hms<<24 + int64(t.Nanosecond()/1000)
+	if t.Neg() {
+		packed = -packed
+	}
+
+	intPart := packed >> 24
+	frac := packed % (1 << 24)
+	var v int64
+	switch precision {
+	case 1, 2:
+		v = intPart<<8 + int64(uint8(frac/10000))
+	case 3, 4:
+		v = intPart<<16 + int64(uint16(frac/100))
+	case 5, 6:
+		v = packed
+	default:
+		v = intPart
+	}
+
+	length := 3 + (int(precision)+1)/2
+	v += int64(0x800000) << (8 * (length - 3))
+	for i := length - 1; i >= 0; i-- {
+		data = append(data, byte(v>>(8*i)))
+	}
+	return data
+}
+
+// appendCellDecimal appends a DECIMAL value with the given precision and scale.
+func appendCellDecimal(data []byte, s string, precision, scale int) ([]byte, error) {
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimLeft(s, "+-")
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	intPart = strings.TrimLeft(intPart, "0")
+
+	intg := precision - scale
+	if len(intPart) > intg {
+		return nil, vterrors.Errorf(vtrpc.Code_INVALID_ARGUMENT, "DECIMAL value %v doesn't fit in DECIMAL(%d,%d)", s, precision, scale)
+	}
+	intPart = strings.Repeat("0", intg-len(intPart)) + intPart
+	if len(fracPart) > scale {
+		fracPart = fracPart[:scale]
+	}
+	fracPart += strings.Repeat("0", scale-len(fracPart))
+
+	start := len(data)
+	appendDigits := func(digits string) error {
+		if digits == "" {
+			return nil
+		}
+		v, err := strconv.ParseUint(digits, 10, 32)
+		if err != nil {
+			return err
+		}
+		for i := dig2bytes[len(digits)] - 1; i >= 0; i-- {
+			data = append(data, byte(v>>(8*i)))
+		}
+		return nil
+	}
+
+	// the leftover integral digits come first, then groups of 9 digits
+	leftover := intg % 9
+	if err := appendDigits(intPart[:leftover]); err != nil {
+		return nil, err
+	}
+	for i := leftover; i < intg; i += 9 {
+		if err := appendDigits(intPart[i : i+9]); err != nil {
+			return nil, err
+		}
+	}
+	// the fractional digits are in groups of 9, then the leftover digits
+	for i := 0; i < scale; i += 9 {
+		if err := appendDigits(fracPart[i:min(i+9, scale)]); err != nil {
+			return nil, err
+		}
+	}
+
+	if negative {
+		for i := start; i < len(data); i++ {
+			data[i] ^= 0xff
+		}
+	}
+	if len(data) > start {
+		data[start] ^= 0x80
+	}
+	return data, nil
+}
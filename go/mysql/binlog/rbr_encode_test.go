@@ -0,0 +1,98 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package binlog
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestAppendCellValue(t *testing.T) {
+	unsigned := uint32(querypb.MySqlFlag_UNSIGNED_FLAG)
+	testcases := []struct {
+		field *querypb.Field
+		value string
+		// out is the value read back, if it isn't the same as value.
+		out string
+	}{
+		{field: &querypb.Field{Type: querypb.Type_INT8}, value: "-2"},
+		{field: &querypb.Field{Type: querypb.Type_UINT8}, value: "130"},
+		{field: &querypb.Field{Type: querypb.Type_INT16}, value: "-32000"},
+		{field: &querypb.Field{Type: querypb.Type_UINT16}, value: "65000"},
+		{field: &querypb.Field{Type: querypb.Type_INT24}, value: "-8000000"},
+		{field: &querypb.Field{Type: querypb.Type_UINT24}, value: "16000000"},
+		{field: &querypb.Field{Type: querypb.Type_INT32}, value: "-2000000000"},
+		{field: &querypb.Field{Type: querypb.Type_UINT32}, value: "4000000000"},
+		{field: &querypb.Field{Type: querypb.Type_INT64}, value: "-9000000000000000000"},
+		{field: &querypb.Field{Type: querypb.Type_UINT64}, value: "18000000000000000000"},
+		{field: &querypb.Field{Type: querypb.Type_YEAR}, value: "2030"},
+		{field: &querypb.Field{Type: querypb.Type_FLOAT32}, value: "1.5", out: "1.5E+00"},
+		{field: &querypb.Field{Type: querypb.Type_FLOAT64}, value: "-3.25E+100"},
+		{field: &querypb.Field{Type: querypb.Type_DECIMAL, ColumnLength: 14, Decimals: 2}, value: "1234567890.12"},
+		{field: &querypb.Field{Type: querypb.Type_DECIMAL, ColumnLength: 14, Decimals: 2}, value: "-1234567890.12"},
+		{field: &querypb.Field{Type: querypb.Type_DECIMAL, ColumnLength: 14, Decimals: 2}, value: "-0.50", out: "-.50"},
+		{field: &querypb.Field{Type: querypb.Type_DECIMAL, ColumnLength: 21, Decimals: 0, Flags: unsigned}, value: "123456789012345678901"},
+		{field: &querypb.Field{Type: querypb.Type_DECIMAL, ColumnLength: 34, Decimals: 20}, value: "12345678901.12345678901234567890"},
+		{field: &querypb.Field{Type: querypb.Type_DATE}, value: "2024-02-29"},
+		{field: &querypb.Field{Type: querypb.Type_DATE}, value: "0000-00-00"},
+		{field: &querypb.Field{Type: querypb.Type_DATETIME}, value: "2024-02-29 12:34:56"},
+		{field: &querypb.Field{Type: querypb.Type_DATETIME, Decimals: 2}, value: "2024-02-29 12:34:56.78"},
+		{field: &querypb.Field{Type: querypb.Type_DATETIME, Decimals: 4}, value: "2024-02-29 12:34:56.7891"},
+		{field: &querypb.Field{Type: querypb.Type_DATETIME, Decimals: 6}, value: "2024-02-29 12:34:56.789123"},
+		{field: &querypb.Field{Type: querypb.Type_TIMESTAMP}, value: "2024-02-29 12:34:56"},
+		{field: &querypb.Field{Type: querypb.Type_TIMESTAMP, Decimals: 3}, value: "2024-02-29 12:34:56.789"},
+		{field: &querypb.Field{Type: querypb.Type_TIME}, value: "12:34:56"},
+		{field: &querypb.Field{Type: querypb.Type_TIME}, value: "-838:59:59"},
+		{field: &querypb.Field{Type: querypb.Type_TIME, Decimals: 2}, value: "-00:00:01.50"},
+		{field: &querypb.Field{Type: querypb.Type_TIME, Decimals: 4}, value: "-12:34:56.7800"},
+		{field: &querypb.Field{Type: querypb.Type_TIME, Decimals: 6}, value: "12:34:56.000001"},
+		{field: &querypb.Field{Type: querypb.Type_VARCHAR, ColumnLength: 40}, value: "abc"},
+		{field: &querypb.Field{Type: querypb.Type_VARCHAR, ColumnLength: 1024}, value: "abcdef"},
+		{field: &querypb.Field{Type: querypb.Type_VARBINARY, ColumnLength: 16}, value: "\x00\x01"},
+		{field: &querypb.Field{Type: querypb.Type_BLOB, ColumnLength: 65535}, value: "blob"},
+		{field: &querypb.Field{Type: querypb.Type_TEXT, ColumnLength: 4294967295}, value: "text"},
+		{field: &querypb.Field{Type: querypb.Type_BIT, ColumnLength: 12}, value: "\x0a\xbc"},
+	}
+
+	for _, tcase := range testcases {
+		t.Run(tcase.field.Type.String()+" "+tcase.value, func(t *testing.T) {
+			typ, metadata := FieldType(tcase.field)
+			value := sqltypes.MakeTrusted(tcase.field.Type, []byte(tcase.value))
+
+			data, err := AppendCellValue([]byte{0xff}, value, typ, metadata)
+			require.NoError(t, err)
+
+			l, err := CellLength(data, 1, typ, metadata)
+			require.NoError(t, err)
+			assert.Equal(t, len(data)-1, l)
+
+			out, l, err := CellValue(data, 1, typ, metadata, tcase.field)
+			require.NoError(t, err)
+			assert.Equal(t, len(data)-1, l)
+			want := tcase.value
+			if tcase.out != "" {
+				want = tcase.out
+			}
+			assert.Equal(t, want, out.ToString())
+		})
+	}
+}
@@ -75,15 +75,22 @@ func (c *Conn) parseComBinlogDumpGTID(data []byte) (logFile string, logPos uint6
 	if flags2&BinlogDumpNonBlock != 0 {
 		return logFile, logPos, position, io.EOF
 	}
-	if flags2&BinlogThroughGTID != 0 {
+	// MySQL clients send the GTID data without setting BinlogThroughGTID.
+	if flags2&BinlogThroughGTID != 0 || pos < len(data) {
 		dataSize, pos, ok := readUint32(data, pos)
-		if !ok {
+		if !ok || pos+int(dataSize) > len(data) {
 			return logFile, logPos, position, readPacketErr
 		}
-		if gtid := string(data[pos : pos+int(dataSize)]); gtid != "" {
-			position, err = replication.DecodePosition(gtid)
+		if gtid := data[pos : pos+int(dataSize)]; len(gtid) > 0 {
+			position, err = replication.DecodePosition(string(gtid))
 			if err != nil {
-				return logFile, logPos, position, err
+				// MySQL clients send the SID block of the GTID set
+				// instead of its encoded position.
+				set, sidErr := replication.NewMysql56GTIDSetFromSIDBlock(gtid)
+				if sidErr != nil {
+					return logFile, logPos, position, err
+				}
+				position, err = replication.Position{GTIDSet: set}, nil
 			}
 		}
 	}
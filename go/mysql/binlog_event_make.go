@@ -324,6 +324,39 @@ func NewMariaDBGTIDEvent(f BinlogFormat, s *FakeBinlogStream, gtid replication.M
 	return NewMariadbBinlogEvent(ev)
 }
 
+// NewMySQL56GTIDEvent returns a MySQL 5.6 GTID event.
+func NewMySQL56GTIDEvent(f BinlogFormat, s *FakeBinlogStream, gtid replication.Mysql56GTID) BinlogEvent {
+	ev := s.Packetize(f, eGTIDEvent, 0, mysql56GTIDEventData(gtid))
+	return NewMysql56BinlogEvent(ev)
+}
+
+// NewMySQL56AnonymousGTIDEvent returns an Anonymous GTID event, which
+// starts a transaction that doesn't have a GTID.
+func NewMySQL56AnonymousGTIDEvent(f BinlogFormat, s *FakeBinlogStream) BinlogEvent {
+	ev := s.Packetize(f, eAnonymousGTIDEvent, 0, mysql56GTIDEventData(replication.Mysql56GTID{}))
+	return NewMysql56BinlogEvent(ev)
+}
+
+func mysql56GTIDEventData(gtid replication.Mysql56GTID) []byte {
+	length := 1 + // flags
+		16 + // SID
+		8 // GNO
+	data := make([]byte, length)
+
+	// The commit flag, always set by MySQL 5.6.
+	data[0] = 1
+	copy(data[1:17], gtid.Server[:])
+	binary.LittleEndian.PutUint64(data[17:25], uint64(gtid.Sequence))
+	return data
+}
+
+// NewPreviousGTIDsEvent returns a Previous GTIDs event, with the set
+// of GTIDs that were executed before the current binlog file.
+func NewPreviousGTIDsEvent(f BinlogFormat, s *FakeBinlogStream, set replication.Mysql56GTIDSet) BinlogEvent {
+	ev := s.Packetize(f, ePreviousGTIDsEvent, 0, set.SIDBlock())
+	return NewMysql56BinlogEvent(ev)
+}
+
 // NewTableMapEvent returns a TableMap event.
 // Only works with post_header_length=8.
 func NewTableMapEvent(f BinlogFormat, s *FakeBinlogStream, tableID uint64, tm *TableMap) BinlogEvent {
@@ -190,6 +190,47 @@ func TestMariadDBGTIDEVent(t *testing.T) {
 	}
 }
 
+func TestMySQL56GTIDEvent(t *testing.T) {
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+
+	sid, err := replication.ParseSID("00010203-0405-0607-0809-0a0b0c0d0e0f")
+	require.NoError(t, err)
+	event := NewMySQL56GTIDEvent(f, s, replication.Mysql56GTID{Server: sid, Sequence: 0x123456789abcdef0})
+	require.True(t, event.IsValid(), "NewMySQL56GTIDEvent().IsValid() is false")
+	require.True(t, event.IsGTID(), "NewMySQL56GTIDEvent().IsGTID() is false")
+
+	event, _, err = event.StripChecksum(f)
+	require.NoError(t, err, "StripChecksum failed: %v", err)
+
+	gtid, hasBegin, err := event.GTID(f)
+	require.NoError(t, err, "NewMySQL56GTIDEvent().GTID() returned error: %v", err)
+	require.False(t, hasBegin)
+	require.Equal(t, replication.Mysql56GTID{Server: sid, Sequence: 0x123456789abcdef0}, gtid)
+
+	event = NewMySQL56AnonymousGTIDEvent(f, s)
+	require.True(t, event.IsValid(), "NewMySQL56AnonymousGTIDEvent().IsValid() is false")
+	require.False(t, event.IsGTID(), "NewMySQL56AnonymousGTIDEvent().IsGTID() is true")
+}
+
+func TestPreviousGTIDsEvent(t *testing.T) {
+	f := NewMySQL56BinlogFormat()
+	s := NewFakeBinlogStream()
+
+	set, err := replication.ParseMysql56GTIDSet("00010203-0405-0607-0809-0a0b0c0d0e0f:1-5:8,00010203-0405-0607-0809-0a0b0c0d0eff:1-3")
+	require.NoError(t, err)
+	event := NewPreviousGTIDsEvent(f, s, set)
+	require.True(t, event.IsValid(), "NewPreviousGTIDsEvent().IsValid() is false")
+	require.True(t, event.IsPreviousGTIDs(), "NewPreviousGTIDsEvent().IsPreviousGTIDs() is false")
+
+	event, _, err = event.StripChecksum(f)
+	require.NoError(t, err, "StripChecksum failed: %v", err)
+
+	pos, err := event.PreviousGTIDs(f)
+	require.NoError(t, err, "NewPreviousGTIDsEvent().PreviousGTIDs() returned error: %v", err)
+	require.True(t, set.Equal(pos.GTIDSet), "got %v, want %v", pos.GTIDSet, set)
+}
+
 func TestTableMapEvent(t *testing.T) {
 	f := NewMySQL56BinlogFormat()
 	s := NewFakeBinlogStream()
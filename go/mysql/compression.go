@@ -0,0 +1,289 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"compress/zlib"
+	"io"
+	"sync"
+
+	"github.com/klauspost/compress/zstd"
+
+	"vitess.io/vitess/go/stats"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// This file implements the compressed protocol. Once it is negotiated,
+// the regular packets are sent as a stream, which is cut into chunks
+// that are each sent as a compressed packet:
+//
+//	int<3> length of the payload
+//	int<1> sequence id
+//	int<3> length of the payload before compression, 0 if it isn't compressed
+//	payload
+//
+// The payload is compressed with zlib, or with zstd if the client asked
+// for CLIENT_ZSTD_COMPRESSION_ALGORITHM instead of CLIENT_COMPRESS.
+// See https://dev.mysql.com/doc/dev/mysql-server/latest/page_protocol_basic_compression.html
+
+const (
+	// CompressionZlib is the name of the zlib compression algorithm, used with CLIENT_COMPRESS.
+	CompressionZlib = "zlib"
+
+	// CompressionZstd is the name of the zstd compression algorithm, used with CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	CompressionZstd = "zstd"
+
+	// DefaultZstdCompressionLevel is the zstd compression level MySQL uses when the client doesn't pick one.
+	DefaultZstdCompressionLevel = 3
+
+	compressedPacketHeaderSize = 7
+
+	// minCompressLength is the size below which payloads are sent uncompressed,
+	// because compressing them doesn't pay off. This is the same as MySQL's MIN_COMPRESS_LENGTH.
+	minCompressLength = 50
+)
+
+var (
+	compressedBytes     = stats.NewCountersWithSingleLabel("MysqlCompressedBytes", "Bytes sent and received by MySQL connections using the compressed protocol, as they went over the network", "direction")
+	compressionRawBytes = stats.NewCountersWithSingleLabel("MysqlCompressionRawBytes", "Bytes sent and received by MySQL connections using the compressed protocol, before compression", "direction")
+
+	zstdEncodersMu sync.Mutex
+	zstdEncoders   = map[zstd.EncoderLevel]*zstd.Encoder{}
+)
+
+// getZstdEncoder returns an encoder for the given level. Encoders are shared
+// by all connections, as EncodeAll can be used concurrently.
+func getZstdEncoder(level int) (*zstd.Encoder, error) {
+	encoderLevel := zstd.EncoderLevelFromZstd(level)
+
+	zstdEncodersMu.Lock()
+	defer zstdEncodersMu.Unlock()
+	if enc, ok := zstdEncoders[encoderLevel]; ok {
+		return enc, nil
+	}
+	enc, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(encoderLevel), zstd.WithEncoderConcurrency(1))
+	if err != nil {
+		return nil, err
+	}
+	zstdEncoders[encoderLevel] = enc
+	return enc, nil
+}
+
+// compressionAlgorithm returns the compression algorithm negotiated in the
+// handshake, or an empty string if the connection doesn't use compression.
+func (c *Conn) compressionAlgorithm() string {
+	switch {
+	case c.Capabilities&CapabilityClientCompress != 0:
+		return CompressionZlib
+	case c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0:
+		return CompressionZstd
+	}
+	return ""
+}
+
+// compressedConn sits between a Conn and its network connection, and
+// implements the compressed protocol on top of it.
+type compressedConn struct {
+	r io.Reader
+	w io.Writer
+
+	// sequence is the sequence id of the next compressed packet. It follows the sequence
+	// of the compressed packets read from the other side, like MySQL does.
+	sequence uint8
+
+	zstd       *zstd.Encoder
+	zlibWriter *zlib.Writer
+	zlibReader io.ReadCloser
+
+	// reads and writes keep separate buffers, as the flush timer
+	// of the Conn can write while a read is waiting for data
+	readHeader [compressedPacketHeaderSize]byte
+	readBuf    []byte
+	data       []byte
+	writeBuf   bytes.Buffer
+	zstdBuf    []byte
+	packetBuf  []byte
+}
+
+func newCompressedConn(r io.Reader, w io.Writer, algorithm string, zstdLevel int) (*compressedConn, error) {
+	cc := &compressedConn{r: r, w: w}
+	switch algorithm {
+	case CompressionZlib:
+		cc.zlibWriter = zlib.NewWriter(&cc.writeBuf)
+	case CompressionZstd:
+		enc, err := getZstdEncoder(zstdLevel)
+		if err != nil {
+			return nil, err
+		}
+		cc.zstd = enc
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unknown compression algorithm: %s", algorithm)
+	}
+	return cc, nil
+}
+
+// Read implements the io.Reader interface. It returns the uncompressed stream.
+func (cc *compressedConn) Read(p []byte) (int, error) {
+	for len(cc.data) == 0 {
+		if err := cc.readCompressedPacket(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, cc.data)
+	cc.data = cc.data[n:]
+	return n, nil
+}
+
+func (cc *compressedConn) readCompressedPacket() error {
+	header := cc.readHeader[:]
+	if _, err := io.ReadFull(cc.r, header); err != nil {
+		return err
+	}
+	length := int(uint32(header[0]) | uint32(header[1])<<8 | uint32(header[2])<<16)
+	uncompressedLength := int(uint32(header[4]) | uint32(header[5])<<8 | uint32(header[6])<<16)
+	cc.sequence = header[3] + 1
+
+	if cap(cc.readBuf) < length {
+		cc.readBuf = make([]byte, length)
+	}
+	payload := cc.readBuf[:length]
+	if _, err := io.ReadFull(cc.r, payload); err != nil {
+		return vterrors.Wrapf(err, "io.ReadFull(compressed packet body of length %v) failed", length)
+	}
+	compressedBytes.Add("read", int64(compressedPacketHeaderSize+length))
+
+	if uncompressedLength == 0 {
+		// the payload was too small to be worth compressing
+		cc.data = payload
+		compressionRawBytes.Add("read", int64(length))
+		return nil
+	}
+
+	data, err := cc.decompress(payload, uncompressedLength)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot decompress packet")
+	}
+	if len(data) != uncompressedLength {
+		return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "decompressed packet has length %v, expected %v", len(data), uncompressedLength)
+	}
+	cc.data = data
+	compressionRawBytes.Add("read", int64(uncompressedLength))
+	return nil
+}
+
+func (cc *compressedConn) decompress(payload []byte, uncompressedLength int) ([]byte, error) {
+	data := make([]byte, uncompressedLength)
+	if cc.zstd != nil {
+		return zstdDecoder.DecodeAll(payload, data[:0])
+	}
+
+	var err error
+	if cc.zlibReader == nil {
+		cc.zlibReader, err = zlib.NewReader(bytes.NewReader(payload))
+	} else {
+		err = cc.zlibReader.(zlib.Resetter).Reset(bytes.NewReader(payload), nil)
+	}
+	if err != nil {
+		return nil, err
+	}
+	n, err := io.ReadFull(cc.zlibReader, data)
+	if err != nil {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+// Write implements the io.Writer interface. Every call sends the data right
+// away, in one or more compressed packets.
+func (cc *compressedConn) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > MaxPacketSize {
+			chunk = chunk[:MaxPacketSize]
+		}
+		if err := cc.writeCompressedPacket(chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+func (cc *compressedConn) writeCompressedPacket(data []byte) error {
+	payload, uncompressedLength, err := cc.compress(data)
+	if err != nil {
+		return vterrors.Wrapf(err, "cannot compress packet")
+	}
+
+	length := len(payload)
+	packet := append(cc.packetBuf[:0],
+		byte(length),
+		byte(length>>8),
+		byte(length>>16),
+		cc.sequence,
+		byte(uncompressedLength),
+		byte(uncompressedLength>>8),
+		byte(uncompressedLength>>16),
+	)
+	packet = append(packet, payload...)
+	if cap(packet) <= 4*connBufferSize {
+		// don't hold on to the buffers of unusually large packets
+		cc.packetBuf = packet
+	}
+	cc.sequence++
+
+	if _, err := cc.w.Write(packet); err != nil {
+		return vterrors.Wrapf(err, "Write(compressed packet) failed")
+	}
+	compressedBytes.Add("written", int64(compressedPacketHeaderSize+length))
+	compressionRawBytes.Add("written", int64(len(data)))
+	return nil
+}
+
+// compress returns the payload to send for the given data, and its length
+// before compression, which is 0 if the data was not compressed.
+func (cc *compressedConn) compress(data []byte) ([]byte, int, error) {
+	if len(data) < minCompressLength {
+		return data, 0, nil
+	}
+
+	var payload []byte
+	if cc.zstd != nil {
+		payload = cc.zstd.EncodeAll(data, cc.zstdBuf[:0])
+		cc.zstdBuf = payload
+	} else {
+		cc.writeBuf.Reset()
+		cc.zlibWriter.Reset(&cc.writeBuf)
+		if _, err := cc.zlibWriter.Write(data); err != nil {
+			return nil, 0, err
+		}
+		if err := cc.zlibWriter.Close(); err != nil {
+			return nil, 0, err
+		}
+		payload = cc.writeBuf.Bytes()
+	}
+
+	if len(payload) >= len(data) {
+		// incompressible data is sent as-is
+		return data, 0, nil
+	}
+	return payload, len(data), nil
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/vttls"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+func TestCompressedConn(t *testing.T) {
+	random := make([]byte, 64*1024)
+	_, err := rand.Read(random)
+	require.NoError(t, err)
+
+	payloads := [][]byte{
+		[]byte("small"),
+		[]byte(strings.Repeat("compressible ", 1000)),
+		random,
+	}
+
+	for _, algorithm := range []string{CompressionZlib, CompressionZstd} {
+		t.Run(algorithm, func(t *testing.T) {
+			var network bytes.Buffer
+			w, err := newCompressedConn(nil, &network, algorithm, DefaultZstdCompressionLevel)
+			require.NoError(t, err)
+			r, err := newCompressedConn(&network, nil, algorithm, DefaultZstdCompressionLevel)
+			require.NoError(t, err)
+
+			for i, payload := range payloads {
+				before := network.Len()
+				_, err := w.Write(payload)
+				require.NoError(t, err)
+
+				sent := network.Bytes()[before:]
+				assert.EqualValues(t, i, sent[3], "sequence")
+				uncompressedLength := int(uint32(sent[4]) | uint32(sent[5])<<8 | uint32(sent[6])<<16)
+				switch i {
+				case 0, 2:
+					// too small, or incompressible
+					assert.Zero(t, uncompressedLength)
+					assert.Equal(t, compressedPacketHeaderSize+len(payload), len(sent))
+				case 1:
+					assert.Equal(t, len(payload), uncompressedLength)
+					assert.Less(t, len(sent), len(payload)/10)
+				}
+			}
+
+			for _, payload := range payloads {
+				got := make([]byte, len(payload))
+				_, err := r.readFull(got)
+				require.NoError(t, err)
+				assert.Equal(t, payload, got)
+			}
+			assert.EqualValues(t, len(payloads), r.sequence)
+		})
+	}
+}
+
+func (cc *compressedConn) readFull(p []byte) (int, error) {
+	read := 0
+	for read < len(p) {
+		n, err := cc.Read(p[read:])
+		if err != nil {
+			return read, err
+		}
+		read += n
+	}
+	return read, nil
+}
+
+func TestCompressionHandshake(t *testing.T) {
+	rows := make([][]sqltypes.Value, 1000)
+	for i := range rows {
+		rows[i] = []sqltypes.Value{sqltypes.MakeTrusted(querypb.Type_VARCHAR, []byte(fmt.Sprintf("row number %d", i)))}
+	}
+	largeResult := &sqltypes.Result{
+		Fields: []*querypb.Field{{Name: "name", Type: querypb.Type_VARCHAR, Charset: uint32(collations.CollationUtf8mb4ID)}},
+		Rows:   rows,
+	}
+
+	tests := []struct {
+		name        string
+		listener    func(l *Listener)
+		params      func(p *ConnParams)
+		algorithm   string
+		serverLevel int
+	}{{
+		name:      "zlib",
+		listener:  func(l *Listener) { l.AllowCompression = true },
+		params:    func(p *ConnParams) { p.Compression = CompressionZlib },
+		algorithm: CompressionZlib,
+	}, {
+		name:      "zlib from the client flags",
+		listener:  func(l *Listener) { l.AllowCompression = true },
+		params:    func(p *ConnParams) { p.Flags = CapabilityClientCompress },
+		algorithm: CompressionZlib,
+	}, {
+		name:        "zstd",
+		listener:    func(l *Listener) { l.AllowZstdCompression = true },
+		params:      func(p *ConnParams) { p.Compression = CompressionZstd; p.ZstdCompressionLevel = 7 },
+		algorithm:   CompressionZstd,
+		serverLevel: 7,
+	}, {
+		name:     "server doesn't allow compression",
+		listener: func(l *Listener) { l.AllowZstdCompression = true },
+		params:   func(p *ConnParams) { p.Compression = CompressionZlib },
+	}, {
+		name:     "client doesn't ask for compression",
+		listener: func(l *Listener) { l.AllowCompression = true; l.AllowZstdCompression = true },
+		params:   func(p *ConnParams) {},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			th := &testHandler{}
+			authServer := NewAuthServerNone()
+			l, err := NewListener("tcp", "127.0.0.1:", authServer, th, 0, 0, false, false, 0, 0)
+			require.NoError(t, err)
+			defer l.Close()
+			tc.listener(l)
+			go l.Accept()
+
+			params := &ConnParams{
+				Host:    l.Addr().(*net.TCPAddr).IP.String(),
+				Port:    l.Addr().(*net.TCPAddr).Port,
+				Uname:   "user1",
+				SslMode: vttls.Disabled,
+			}
+			tc.params(params)
+
+			compressedBefore := compressedBytes.Counts()["read"]
+			conn, err := Connect(context.Background(), params)
+			require.NoError(t, err)
+			defer conn.Close()
+
+			assert.Equal(t, tc.algorithm, conn.compressionAlgorithm())
+			assert.Equal(t, tc.algorithm, th.LastConn().compressionAlgorithm())
+			assert.Equal(t, tc.algorithm != "", conn.compression != nil)
+			if tc.serverLevel != 0 {
+				assert.Equal(t, tc.serverLevel, th.LastConn().zstdCompressionLevel)
+			}
+
+			result, err := conn.ExecuteFetch("select rows", 10000, true)
+			require.NoError(t, err)
+			utils.MustMatch(t, selectRowsResult, result)
+
+			th.mu.Lock()
+			th.result = largeResult
+			th.mu.Unlock()
+			result, err = conn.ExecuteFetch("select large", 10000, true)
+			require.NoError(t, err)
+			assert.Equal(t, largeResult.Rows, result.Rows)
+
+			if tc.algorithm != "" {
+				assert.Greater(t, compressedBytes.Counts()["read"], compressedBefore)
+			}
+
+			conn.writeComQuit()
+		})
+	}
+}
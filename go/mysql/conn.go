@@ -194,6 +194,23 @@ type Conn struct {
 	// Packet encoding variables.
 	sequence uint8
 
+	// compression is set once the compressed protocol has been negotiated.
+	// All reads and writes then go through it.
+	compression *compressedConn
+
+	// zstdCompressionLevel is the compression level negotiated in the handshake,
+	// if the connection uses zstd compression.
+	zstdCompressionLevel int
+
+	// streamingCursor is the cursor whose handler is still streaming the
+	// result, if any. There is at most one, as the handler can't run another
+	// command until it has finished.
+	streamingCursor *cursor
+
+	// queryAttributes are the query attributes the client sent with the
+	// COM_QUERY being executed, if it uses CLIENT_QUERY_ATTRIBUTES.
+	queryAttributes map[string]sqltypes.Value
+
 	// ExpectSemiSyncIndicator is applicable when the connection is used for replication (ComBinlogDump).
 	// When 'true', events are assumed to be padded with 2-byte semi-sync information
 	// See https://dev.mysql.com/doc/internals/en/semi-sync-binlog-event.html
@@ -227,6 +244,19 @@ type PrepareData struct {
 	BindVars    map[string]*querypb.BindVariable
 	StatementID uint32
 	ParamsCount uint16
+
+	// CursorType is the cursor type the statement is being executed with.
+	// With CursorTypeReadOnly, the handler should stream the result, as the
+	// client reads it in batches with COM_STMT_FETCH.
+	CursorType byte
+
+	// QueryAttributes are the query attributes the client sent with the
+	// COM_STMT_EXECUTE, if it uses CLIENT_QUERY_ATTRIBUTES.
+	QueryAttributes map[string]sqltypes.Value
+
+	// cursor holds the result of the last execution, if it was executed
+	// with a cursor and the client hasn't read all the rows yet.
+	cursor *cursor
 }
 
 // execResult is an enum signifying the result of executing a query
@@ -323,7 +353,7 @@ func (c *Conn) startWriterBuffering() {
 	defer c.bufMu.Unlock()
 
 	c.bufferedWriter = writersPool.Get().(*bufio.Writer)
-	c.bufferedWriter.Reset(c.conn)
+	c.bufferedWriter.Reset(c.getWriter())
 }
 
 // endWriterBuffering must be called to terminate startWriteBuffering.
@@ -345,6 +375,17 @@ func (c *Conn) endWriterBuffering() error {
 	return c.bufferedWriter.Flush()
 }
 
+// flush writes out whatever is buffered, without ending the write buffering.
+func (c *Conn) flush() error {
+	c.bufMu.Lock()
+	defer c.bufMu.Unlock()
+
+	if c.bufferedWriter == nil {
+		return nil
+	}
+	return c.bufferedWriter.Flush()
+}
+
 func (c *Conn) returnReader() {
 	if c.bufferedReader == nil {
 		return
@@ -371,14 +412,49 @@ func (c *Conn) startFlushTimer() {
 }
 
 // getReader returns reader for connection. It can be *bufio.Reader or net.Conn
-// depending on which buffer size was passed to newServerConn.
+// depending on which buffer size was passed to newServerConn, or the
+// decompressing reader if the compressed protocol is used.
 func (c *Conn) getReader() io.Reader {
+	if c.compression != nil {
+		return c.compression
+	}
+	return c.getNetworkReader()
+}
+
+func (c *Conn) getNetworkReader() io.Reader {
 	if c.bufferedReader != nil {
 		return c.bufferedReader
 	}
 	return c.conn
 }
 
+// getWriter returns the unbuffered writer for the connection.
+func (c *Conn) getWriter() io.Writer {
+	if c.compression != nil {
+		return c.compression
+	}
+	return c.conn
+}
+
+// enableCompression switches the connection to the compressed protocol.
+// It must be called right after the handshake completed, on both sides.
+func (c *Conn) enableCompression(algorithm string, zstdLevel int) error {
+	cc, err := newCompressedConn(c.getNetworkReader(), c.conn, algorithm, zstdLevel)
+	if err != nil {
+		return err
+	}
+	c.compression = cc
+	return nil
+}
+
+// resetSequence resets the packet sequence, at the start of a new command.
+func (c *Conn) resetSequence() {
+	c.sequence = 0
+	if c.compression != nil {
+		c.compression.sequence = 0
+	}
+}
+
 func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	// Note io.ReadFull will return two different types of errors:
 	// 1. if the socket is already closed, and the go runtime knows it,
@@ -400,11 +476,13 @@ func (c *Conn) readHeaderFrom(r io.Reader) (int, error) {
 	}
 
 	sequence := uint8(c.header[3])
-	if sequence != c.sequence {
+	// With the compressed protocol, the sequence of the packets inside the
+	// compressed ones isn't reliable, and MySQL doesn't check it either.
+	if sequence != c.sequence && c.compression == nil {
 		return 0, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid sequence, expected %v got %v", c.sequence, sequence)
 	}
 
-	c.sequence++
+	c.sequence = sequence + 1
 
 	return int(uint32(c.header[0]) | uint32(c.header[1])<<8 | uint32(c.header[2])<<16), nil
 }
@@ -611,7 +689,7 @@ func (c *Conn) writePacket(data []byte) error {
 		}()
 	} else {
 		c.bufMu.Unlock()
-		w = c.conn
+		w = c.getWriter()
 	}
 
 	var header [packetHeaderSize]byte
@@ -715,7 +793,7 @@ func (c *Conn) recycleWritePacket() {
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComQuit() error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(1)
 	data[pos] = ComQuit
@@ -921,7 +999,7 @@ func (c *Conn) writeEOFPacket(flags uint16, warnings uint16) error {
 // handleNextCommand is called in the server loop to process
 // incoming packets.
 func (c *Conn) handleNextCommand(handler Handler) bool {
-	c.sequence = 0
+	c.resetSequence()
 	data, err := c.readEphemeralPacket()
 	if err != nil {
 		// Don't log EOF errors. They cause too much spam.
@@ -938,6 +1016,14 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 		return false
 	}
 
+	switch data[0] {
+	case ComQuit, ComPing, ComStmtFetch, ComStmtClose, ComStmtReset, ComStmtSendLongData, ComResetConnection:
+	default:
+		// The handler will be called, so it has to be done with the
+		// result of an open cursor first.
+		c.materializeCursor()
+	}
+
 	switch data[0] {
 	case ComQuit:
 		c.recycleReadPacket()
@@ -962,9 +1048,12 @@ func (c *Conn) handleNextCommand(handler Handler) bool {
 	case ComStmtClose:
 		stmtID, ok := c.parseComStmtClose(data)
 		c.recycleReadPacket()
-		if ok {
+		if prepare, exists := c.PrepareData[stmtID]; ok && exists {
+			c.closeCursor(prepare)
 			delete(c.PrepareData, stmtID)
 		}
+	case ComStmtFetch:
+		return c.handleComStmtFetch(handler, data)
 	case ComStmtReset:
 		return c.handleComStmtReset(data)
 	case ComResetConnection:
@@ -1029,6 +1118,8 @@ func (c *Conn) handleComBinlogDump(handler Handler, data []byte) (kontinue bool)
 	}
 	if err := handler.ComBinlogDump(c, logfile, binlogPos); err != nil {
 		log.Error(err.Error())
+		// Like MySQL, let the client know why the stream ended.
+		c.writeErrorPacketFromErrorAndLog(sqlerror.NewSQLErrorFromError(err))
 		return false
 	}
 	return kontinue
@@ -1053,6 +1144,8 @@ func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue b
 	}
 	if err := handler.ComBinlogDumpGTID(c, logFile, logPos, position.GTIDSet); err != nil {
 		log.Error(err.Error())
+		// Like MySQL, let the client know why the stream ended.
+		c.writeErrorPacketFromErrorAndLog(sqlerror.NewSQLErrorFromError(err))
 		return false
 	}
 	return kontinue
@@ -1061,6 +1154,7 @@ func (c *Conn) handleComBinlogDumpGTID(handler Handler, data []byte) (kontinue b
 func (c *Conn) handleComResetConnection(handler Handler) {
 	// Clean up and reset the connection
 	c.recycleReadPacket()
+	c.closeCursors()
 	handler.ComResetConnection(c)
 	// Reset prepared statements
 	c.PrepareData = make(map[uint32]*PrepareData)
@@ -1088,6 +1182,7 @@ func (c *Conn) handleComStmtReset(data []byte) bool {
 		}
 	}
 
+	c.closeCursor(prepare)
 	if prepare.BindVars != nil {
 		for k := range prepare.BindVars {
 			prepare.BindVars[k] = nil
@@ -1140,7 +1235,7 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		}
 	}()
 	queryStart := time.Now()
-	stmtID, _, err := c.parseComStmtExecute(c.PrepareData, data)
+	stmtID, cursorType, err := c.parseComStmtExecute(c.PrepareData, data)
 	c.recycleReadPacket()
 
 	if stmtID != uint32(0) {
@@ -1155,10 +1250,17 @@ func (c *Conn) handleComStmtExecute(handler Handler, data []byte) (kontinue bool
 		return c.writeErrorPacketFromErrorAndLog(err)
 	}
 
+	prepare := c.PrepareData[stmtID]
+	// executing the statement again closes its cursor
+	c.closeCursor(prepare)
+	prepare.CursorType = cursorType
+	if cursorType&CursorTypeReadOnly != 0 {
+		return c.executeWithCursor(handler, prepare, queryStart)
+	}
+
 	fieldSent := false
 	// sendFinished is set if the response should just be an OK packet.
 	sendFinished := false
-	prepare := c.PrepareData[stmtID]
 	err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
 		if sendFinished {
 			// Failsafe: Unreachable if server is well-behaved.
@@ -1358,11 +1460,19 @@ func (c *Conn) handleComQuery(handler Handler, data []byte) (kontinue bool) {
 	}()
 
 	queryStart := time.Now()
-	query := c.parseComQuery(data)
+	query, attributes, err := c.parseComQuery(data)
 	c.recycleReadPacket()
+	if err != nil {
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	// all the statements of a multi-statement query share its attributes
+	c.queryAttributes = attributes
+	defer func() {
+		c.queryAttributes = nil
+	}()
 
 	var queries []string
-	var err error
 	if c.Capabilities&CapabilityClientMultiStatements != 0 {
 		queries, err = handler.Env().Parser().SplitStatementToPieces(query)
 		if err != nil {
@@ -1664,6 +1774,12 @@ func (c *Conn) IsUnixSocket() bool {
 	return ok
 }
 
+// QueryAttributes returns the query attributes the client sent with the
+// COM_QUERY being executed. It is only valid during Handler.ComQuery.
+func (c *Conn) QueryAttributes() map[string]sqltypes.Value {
+	return c.queryAttributes
+}
+
 // GetRawConn returns the raw net.Conn for nefarious purposes.
 func (c *Conn) GetRawConn() net.Conn {
 	return c.conn
@@ -64,9 +64,45 @@ type ConnParams struct {
 	// FlushDelay is the delay after which buffered response will be flushed to the client.
 	FlushDelay time.Duration
 
+	// Compression is the compression algorithm to use if the server supports it,
+	// either CompressionZlib or CompressionZstd. Setting CapabilityClientCompress
+	// in Flags is the same as asking for CompressionZlib.
+	Compression string
+
+	// ZstdCompressionLevel is the compression level to use with CompressionZstd.
+	// DefaultZstdCompressionLevel is used if it's not set.
+	ZstdCompressionLevel int
+
 	TruncateErrLen int
 }
 
+// compressionCapability returns the capability to ask for in the handshake,
+// to use the configured compression algorithm. It returns 0 if the server
+// doesn't support that algorithm, in which case we don't use compression.
+func (cp *ConnParams) compressionCapability(serverCapabilities uint32) uint32 {
+	algorithm := cp.Compression
+	if algorithm == "" && cp.Flags&CapabilityClientCompress != 0 {
+		algorithm = CompressionZlib
+	}
+
+	var capability uint32
+	switch algorithm {
+	case CompressionZlib:
+		capability = CapabilityClientCompress
+	case CompressionZstd:
+		capability = CapabilityClientZstdCompressionAlgorithm
+	}
+	return capability & serverCapabilities
+}
+
+// zstdCompressionLevel returns the zstd compression level to use.
+func (cp *ConnParams) zstdCompressionLevel() int {
+	if cp.ZstdCompressionLevel == 0 {
+		return DefaultZstdCompressionLevel
+	}
+	return cp.ZstdCompressionLevel
+}
+
 // EnableSSL will set the right flag on the parameters.
 func (cp *ConnParams) EnableSSL() {
 	cp.SslMode = vttls.VerifyIdentity
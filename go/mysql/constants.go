@@ -76,15 +76,18 @@ const (
 	// CLIENT_NO_SCHEMA 1 << 4
 	// Do not permit database.table.column. We do permit it.
 
-	// CLIENT_COMPRESS 1 << 5
-	// We do not support compression. CPU is usually our bottleneck.
+	// CapabilityClientCompress is CLIENT_COMPRESS.
+	// Use the compressed protocol, with zlib.
+	// We only set it when the listener is configured to allow compression.
+	CapabilityClientCompress = 1 << 5
 
 	// CLIENT_ODBC 1 << 6
 	// No special behavior since 3.22.
 
-	// CLIENT_LOCAL_FILES 1 << 7
+	// CapabilityClientLocalFiles is CLIENT_LOCAL_FILES.
 	// Client can use LOCAL INFILE request of LOAD DATA|XML.
-	// We do not set it.
+	// We only set it when the listener is configured to accept LOAD DATA LOCAL INFILE.
+	CapabilityClientLocalFiles = 1 << 7
 
 	// CLIENT_IGNORE_SPACE 1 << 8
 	// Parser can ignore spaces before '('.
@@ -148,6 +151,16 @@ const (
 	// CapabilityClientDeprecateEOF is CLIENT_DEPRECATE_EOF
 	// Expects an OK (instead of EOF) after the resultset rows of a Text Resultset.
 	CapabilityClientDeprecateEOF = 1 << 24
+
+	// CapabilityClientZstdCompressionAlgorithm is CLIENT_ZSTD_COMPRESSION_ALGORITHM.
+	// Use the compressed protocol, with zstd.
+	// We only set it when the listener is configured to allow zstd compression.
+	CapabilityClientZstdCompressionAlgorithm = 1 << 26
+
+	// CapabilityClientQueryAttributes is CLIENT_QUERY_ATTRIBUTES.
+	// Can send query attributes with COM_QUERY and COM_STMT_EXECUTE.
+	// We only set it when the listener is configured to allow query attributes.
+	CapabilityClientQueryAttributes = 1 << 27
 )
 
 // Status flags. They are returned by the server in a few cases.
@@ -257,6 +270,25 @@ const (
 
 	// NullValue is the encoded value of NULL.
 	NullValue = 0xfb
+
+	// LocalInfilePacket is the header of the packet asking the client to send a file for LOAD DATA LOCAL INFILE.
+	LocalInfilePacket = 0xfb
+)
+
+// Cursor types of COM_STMT_EXECUTE.
+// Originally found in include/mysql/mysql_com.h
+const (
+	// CursorTypeNoCursor is CURSOR_TYPE_NO_CURSOR.
+	CursorTypeNoCursor = 0x00
+
+	// CursorTypeReadOnly is CURSOR_TYPE_READ_ONLY. The result of the statement
+	// is kept on the server, and the client reads it with COM_STMT_FETCH.
+	CursorTypeReadOnly = 0x01
+
+	// ParameterCountAvailable is PARAMETER_COUNT_AVAILABLE. It is set with
+	// CLIENT_QUERY_ATTRIBUTES when the parameter count is sent even though
+	// the statement has no parameters, because there are query attributes.
+	ParameterCountAvailable = 0x08
 )
 
 // Auth packet types
@@ -0,0 +1,322 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/tb"
+	"vitess.io/vitess/go/vt/log"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// errCursorClosed is returned to the handler callback when the client
+// closes a cursor before reading all its rows.
+var errCursorClosed = errors.New("cursor closed")
+
+// cursor holds the result of a statement executed with CURSOR_TYPE_READ_ONLY.
+//
+// The handler streams the result from its own goroutine, so that rows are
+// only produced as the client fetches them. It never runs at the same time as
+// the connection goroutine though: each call to the callback hands a result
+// over, and blocks until the connection asks for the next one. This keeps the
+// guarantee that the Handler methods for a Conn are serialized.
+type cursor struct {
+	fields []*querypb.Field
+
+	// rows are the rows received from the handler, but not fetched yet.
+	rows [][]sqltypes.Value
+
+	results chan *sqltypes.Result
+	resume  chan bool
+	done    chan struct{}
+
+	// waiting is set when the handler is blocked in the callback,
+	// waiting to be resumed.
+	waiting bool
+
+	// finished is set once the handler has returned, and err is what it returned.
+	finished bool
+	err      error
+}
+
+func (c *Conn) startCursor(handler Handler, prepare *PrepareData) *cursor {
+	cur := &cursor{
+		results: make(chan *sqltypes.Result),
+		resume:  make(chan bool),
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(cur.done)
+		defer func() {
+			if x := recover(); x != nil {
+				log.Errorf("mysql_server caught panic in cursor:\n%v\n%s", x, tb.Stack(4))
+				cur.err = fmt.Errorf("panic while streaming the result: %v", x)
+			}
+		}()
+		cur.err = handler.ComStmtExecute(c, prepare, func(qr *sqltypes.Result) error {
+			cur.results <- qr
+			if !<-cur.resume {
+				return errCursorClosed
+			}
+			return nil
+		})
+	}()
+	return cur
+}
+
+// next resumes the handler and waits for its next result.
+// It returns nil once the handler has returned.
+func (cur *cursor) next() *sqltypes.Result {
+	if cur.finished {
+		return nil
+	}
+	if cur.waiting {
+		cur.waiting = false
+		cur.resume <- true
+	}
+	select {
+	case qr := <-cur.results:
+		cur.waiting = true
+		return qr
+	case <-cur.done:
+		cur.finished = true
+		return nil
+	}
+}
+
+// fetch returns up to numRows rows, asking the handler for more as needed.
+// Unless the handler has returned, it makes sure there is at least one row
+// left afterwards, so that the caller knows whether the last row was sent.
+func (cur *cursor) fetch(numRows int) [][]sqltypes.Value {
+	for len(cur.rows) <= numRows && !cur.finished {
+		if qr := cur.next(); qr != nil {
+			cur.rows = append(cur.rows, qr.Rows...)
+		}
+	}
+	n := min(numRows, len(cur.rows))
+	rows := cur.rows[:n:n]
+	cur.rows = cur.rows[n:]
+	return rows
+}
+
+// exhausted returns true if all the rows have been fetched,
+// and the handler returned successfully.
+func (cur *cursor) exhausted() bool {
+	return cur.finished && cur.err == nil && len(cur.rows) == 0
+}
+
+// materialize reads the rest of the result into memory, so that
+// the handler can be called for other commands. If maxRows is not zero,
+// and more than maxRows rows are left, it stops the handler and fails
+// the cursor instead.
+func (cur *cursor) materialize(maxRows int) {
+	for !cur.finished {
+		if qr := cur.next(); qr != nil {
+			cur.rows = append(cur.rows, qr.Rows...)
+		}
+		if maxRows > 0 && len(cur.rows) > maxRows {
+			cur.close()
+			cur.err = sqlerror.NewSQLError(sqlerror.EROutOfResources, sqlerror.SSUnknownSQLState,
+				"cursor result exceeded the allowed limit of %d rows held in memory while running another command", maxRows)
+			return
+		}
+	}
+}
+
+// close stops the handler, and waits until it has returned.
+func (cur *cursor) close() {
+	for !cur.finished {
+		if cur.waiting {
+			cur.waiting = false
+			cur.resume <- false
+		}
+		select {
+		case <-cur.results:
+			// the handler ignored the error of the callback
+			cur.waiting = true
+		case <-cur.done:
+			cur.finished = true
+		}
+	}
+	cur.rows = nil
+}
+
+// closeCursor closes the cursor of the statement, if it has one.
+func (c *Conn) closeCursor(prepare *PrepareData) {
+	cur := prepare.cursor
+	if cur == nil {
+		return
+	}
+	prepare.cursor = nil
+	if c.streamingCursor == cur {
+		c.streamingCursor = nil
+	}
+	cur.close()
+}
+
+// closeCursors closes the cursors of all the statements.
+func (c *Conn) closeCursors() {
+	for _, prepare := range c.PrepareData {
+		c.closeCursor(prepare)
+	}
+}
+
+// materializeCursor reads the rest of the result of the cursor that is still
+// streaming into memory, if there is one. This has to happen before the
+// handler is called for another command. The cursor fails if its result
+// has more rows than the listener allows to hold in memory.
+func (c *Conn) materializeCursor() {
+	if c.streamingCursor == nil {
+		return
+	}
+	var maxRows int
+	if c.listener != nil {
+		maxRows = c.listener.MaxCursorMemoryRows
+	}
+	c.streamingCursor.materialize(maxRows)
+	c.streamingCursor = nil
+}
+
+// executeWithCursor executes a statement for which the client asked for a
+// read-only cursor. It only sends the fields, and the client then
+// reads the rows with COM_STMT_FETCH.
+func (c *Conn) executeWithCursor(handler Handler, prepare *PrepareData, queryStart time.Time) bool {
+	cur := c.startCursor(handler, prepare)
+	qr := cur.next()
+	if qr == nil {
+		err := cur.err
+		if err == nil || err == io.EOF {
+			err = sqlerror.NewSQLErrorFromError(errors.New("unexpected: query ended without no results and no error"))
+		}
+		return c.writeErrorPacketFromErrorAndLog(err)
+	}
+
+	if len(qr.Fields) == 0 {
+		// The statement doesn't return rows, so there is nothing
+		// to fetch, and we just send an OK packet.
+		cur.materialize(0)
+		if cur.err != nil {
+			log.Errorf("Error after the result was sent to %s: %v", c, cur.err)
+			return false
+		}
+		if err := c.writeOKPacket(&PacketOK{
+			affectedRows:     qr.RowsAffected,
+			lastInsertID:     qr.InsertID,
+			statusFlags:      c.StatusFlags,
+			sessionStateData: qr.SessionStateChanges,
+		}); err != nil {
+			log.Errorf("Error writing result to %s: %v", c, err)
+			return false
+		}
+		timings.Record(queryTimingKey, queryStart)
+		return true
+	}
+
+	cur.fields = qr.Fields
+	cur.rows = qr.Rows
+	prepare.cursor = cur
+	c.streamingCursor = cur
+
+	if err := c.sendColumnCount(uint64(len(cur.fields))); err != nil {
+		log.Errorf("Error writing fields to %s: %v", c, err)
+		return false
+	}
+	for _, field := range cur.fields {
+		if err := c.writeColumnDefinition(field); err != nil {
+			log.Errorf("Error writing fields to %s: %v", c, err)
+			return false
+		}
+	}
+	// Like MySQL, we always end the column definitions when opening a cursor,
+	// as this is how the client learns that the cursor exists.
+	if err := c.writeCursorStatus(c.StatusFlags|ServerStatusCursorExists, 0); err != nil {
+		log.Errorf("Error writing fields to %s: %v", c, err)
+		return false
+	}
+
+	timings.Record(queryTimingKey, queryStart)
+	return true
+}
+
+func (c *Conn) handleComStmtFetch(handler Handler, data []byte) (kontinue bool) {
+	c.startWriterBuffering()
+	defer func() {
+		if err := c.endWriterBuffering(); err != nil {
+			log.Errorf("conn %v: flush() failed: %v", c.ID(), err)
+			kontinue = false
+		}
+	}()
+
+	stmtID, numRows, ok := c.parseComStmtFetch(data)
+	c.recycleReadPacket()
+	if !ok {
+		log.Error("Got unhandled packet from client %v, returning error: %v", c.ConnectionID, data)
+		return c.writeErrorAndLog(sqlerror.ERUnknownComError, sqlerror.SSNetError, "error handling packet: %v", data)
+	}
+
+	prepare, ok := c.PrepareData[stmtID]
+	if !ok {
+		return c.writeErrorAndLog(sqlerror.ERUnknownStmtHandler, sqlerror.SSUnknownSQLState, "Unknown prepared statement handler (%v) given to mysqld_stmt_fetch", stmtID)
+	}
+	cur := prepare.cursor
+	if cur == nil {
+		return c.writeErrorAndLog(sqlerror.ERStmtHasNoOpenCursor, sqlerror.SSUnknownSQLState, "The statement (%v) has no open cursor.", stmtID)
+	}
+
+	rows := cur.fetch(int(numRows))
+	if len(rows) == 0 && cur.finished && cur.err != nil {
+		err := cur.err
+		c.closeCursor(prepare)
+		return c.writeErrorPacketFromErrorAndLog(sqlerror.NewSQLErrorFromError(err))
+	}
+
+	if err := c.writeBinaryRows(&sqltypes.Result{Fields: cur.fields, Rows: rows}); err != nil {
+		log.Errorf("Error writing rows to %s: %v", c, err)
+		return false
+	}
+
+	flags := c.StatusFlags | ServerStatusCursorExists
+	var warnings uint16
+	if cur.exhausted() {
+		c.closeCursor(prepare)
+		flags = c.StatusFlags | ServerStatusLastRowSent
+		warnings = handler.WarningCount(c)
+	}
+	if err := c.writeCursorStatus(flags, warnings); err != nil {
+		log.Errorf("Error writing result to %s: %v", c, err)
+		return false
+	}
+	return true
+}
+
+// writeCursorStatus sends the packet that ends the column definitions
+// of a cursor, or the rows of a fetch.
+func (c *Conn) writeCursorStatus(flags uint16, warnings uint16) error {
+	if c.Capabilities&CapabilityClientDeprecateEOF == 0 {
+		return c.writeEOFPacket(flags, warnings)
+	}
+	return c.writeOKPacketWithEOFHeader(&PacketOK{
+		statusFlags: flags,
+		warnings:    warnings,
+	})
+}
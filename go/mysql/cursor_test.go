@@ -0,0 +1,256 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/mysql/sqlerror"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+// cursorHandler streams its rows in batches of two.
+type cursorHandler struct {
+	testRun
+
+	rows      []string
+	callbacks int
+	finished  bool
+	err       error
+}
+
+func (h *cursorHandler) ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error {
+	h.err = func() error {
+		h.callbacks++
+		err := callback(&sqltypes.Result{
+			Fields: []*querypb.Field{{Name: "name", Type: querypb.Type_VARCHAR, Charset: uint32(collations.CollationUtf8mb4ID)}},
+		})
+		if err != nil {
+			return err
+		}
+		for i := 0; i < len(h.rows); i += 2 {
+			qr := &sqltypes.Result{}
+			for _, row := range h.rows[i:min(i+2, len(h.rows))] {
+				qr.Rows = append(qr.Rows, []sqltypes.Value{sqltypes.NewVarChar(row)})
+			}
+			h.callbacks++
+			if err := callback(qr); err != nil {
+				return err
+			}
+		}
+		return nil
+	}()
+	h.finished = true
+	return h.err
+}
+
+func (h *cursorHandler) ComQuery(c *Conn, query string, callback func(*sqltypes.Result) error) error {
+	// the cursor must be done with the handler by now
+	assert.True(h.t, h.finished)
+	return callback(&sqltypes.Result{})
+}
+
+func writeComStmtExecuteWithCursor(t *testing.T, cConn *Conn, stmtID uint32) {
+	data := []byte{ComStmtExecute, 0, 0, 0, 0, CursorTypeReadOnly, 1, 0, 0, 0}
+	binary.LittleEndian.PutUint32(data[1:], stmtID)
+	cConn.resetSequence()
+	useWritePacket(t, cConn, data)
+}
+
+func writeComStmtFetch(t *testing.T, cConn *Conn, stmtID, numRows uint32) {
+	data := make([]byte, 9)
+	data[0] = ComStmtFetch
+	binary.LittleEndian.PutUint32(data[1:], stmtID)
+	binary.LittleEndian.PutUint32(data[5:], numRows)
+	cConn.resetSequence()
+	useWritePacket(t, cConn, data)
+}
+
+// readFetchedRows reads the binary rows of a single VARCHAR column,
+// and the status flags at the end.
+func readFetchedRows(t *testing.T, cConn *Conn) ([]string, uint16) {
+	var rows []string
+	for {
+		data, err := cConn.readPacket()
+		require.NoError(t, err)
+		if data[0] == EOFPacket {
+			_, flags, err := parseEOFPacket(data)
+			require.NoError(t, err)
+			return rows, flags
+		}
+		require.EqualValues(t, 0, data[0], "row header")
+		rows = append(rows, string(data[3:3+data[2]]))
+	}
+}
+
+func openTestCursor(t *testing.T, sConn, cConn *Conn, handler Handler) {
+	sConn.PrepareData[1] = &PrepareData{StatementID: 1, PrepareStmt: "select name from t"}
+	writeComStmtExecuteWithCursor(t, cConn, 1)
+	require.True(t, sConn.handleNextCommand(handler))
+
+	count, err := cConn.readComQueryResponse(&PacketOK{})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+	_, err = cConn.readPacket()
+	require.NoError(t, err)
+	data, err := cConn.readPacket()
+	require.NoError(t, err)
+	require.EqualValues(t, EOFPacket, data[0])
+	_, flags, err := parseEOFPacket(data)
+	require.NoError(t, err)
+	assert.NotZero(t, flags&ServerStatusCursorExists)
+}
+
+func TestCursorFetch(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	handler := &cursorHandler{testRun: testRun{t: t}, rows: []string{"a", "b", "c", "d", "e"}}
+	openTestCursor(t, sConn, cConn, handler)
+	// only the fields have been produced so far
+	assert.Equal(t, 1, handler.callbacks)
+
+	writeComStmtFetch(t, cConn, 1, 3)
+	require.True(t, sConn.handleNextCommand(handler))
+	rows, flags := readFetchedRows(t, cConn)
+	assert.Equal(t, []string{"a", "b", "c"}, rows)
+	assert.NotZero(t, flags&ServerStatusCursorExists)
+	assert.Zero(t, flags&ServerStatusLastRowSent)
+	assert.Equal(t, 3, handler.callbacks)
+	assert.False(t, handler.finished)
+
+	writeComStmtFetch(t, cConn, 1, 3)
+	require.True(t, sConn.handleNextCommand(handler))
+	rows, flags = readFetchedRows(t, cConn)
+	assert.Equal(t, []string{"d", "e"}, rows)
+	assert.Zero(t, flags&ServerStatusCursorExists)
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+	assert.True(t, handler.finished)
+	assert.NoError(t, handler.err)
+
+	writeComStmtFetch(t, cConn, 1, 3)
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err := cConn.readPacket()
+	require.NoError(t, err)
+	err = ParseErrorPacket(data)
+	assert.ErrorContains(t, err, "The statement (1) has no open cursor.")
+	assert.Equal(t, sqlerror.ERStmtHasNoOpenCursor, err.(*sqlerror.SQLError).Number())
+}
+
+func TestCursorOtherCommand(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	handler := &cursorHandler{testRun: testRun{t: t}, rows: []string{"a", "b", "c", "d", "e"}}
+	openTestCursor(t, sConn, cConn, handler)
+
+	// the rest of the result is read into memory before the query runs
+	cConn.resetSequence()
+	require.NoError(t, cConn.WriteComQuery("select 1"))
+	require.True(t, sConn.handleNextCommand(handler))
+	_, _, _, err := cConn.ReadQueryResult(1, false)
+	require.NoError(t, err)
+
+	writeComStmtFetch(t, cConn, 1, 10)
+	require.True(t, sConn.handleNextCommand(handler))
+	rows, flags := readFetchedRows(t, cConn)
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, rows)
+	assert.NotZero(t, flags&ServerStatusLastRowSent)
+}
+
+func TestCursorOtherCommandMaxRows(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+	sConn.listener = &Listener{MaxCursorMemoryRows: 3}
+
+	handler := &cursorHandler{testRun: testRun{t: t}, rows: []string{"a", "b", "c", "d", "e"}}
+	openTestCursor(t, sConn, cConn, handler)
+
+	writeComStmtFetch(t, cConn, 1, 1)
+	require.True(t, sConn.handleNextCommand(handler))
+	rows, _ := readFetchedRows(t, cConn)
+	assert.Equal(t, []string{"a"}, rows)
+
+	// the rest of the result doesn't fit in memory, so the handler is
+	// stopped and the query runs
+	cConn.resetSequence()
+	require.NoError(t, cConn.WriteComQuery("select 1"))
+	require.True(t, sConn.handleNextCommand(handler))
+	_, _, _, err := cConn.ReadQueryResult(1, false)
+	require.NoError(t, err)
+	assert.ErrorIs(t, handler.err, errCursorClosed)
+
+	// and the cursor fails
+	writeComStmtFetch(t, cConn, 1, 10)
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err := cConn.readPacket()
+	require.NoError(t, err)
+	err = ParseErrorPacket(data)
+	assert.ErrorContains(t, err, "cursor result exceeded the allowed limit of 3 rows held in memory")
+	assert.Equal(t, sqlerror.EROutOfResources, err.(*sqlerror.SQLError).Number())
+
+	writeComStmtFetch(t, cConn, 1, 10)
+	require.True(t, sConn.handleNextCommand(handler))
+	data, err = cConn.readPacket()
+	require.NoError(t, err)
+	assert.ErrorContains(t, ParseErrorPacket(data), "The statement (1) has no open cursor.")
+}
+
+func TestCursorClose(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	handler := &cursorHandler{testRun: testRun{t: t}, rows: []string{"a", "b", "c", "d", "e"}}
+	openTestCursor(t, sConn, cConn, handler)
+
+	writeComStmtFetch(t, cConn, 1, 1)
+	require.True(t, sConn.handleNextCommand(handler))
+	rows, _ := readFetchedRows(t, cConn)
+	assert.Equal(t, []string{"a"}, rows)
+
+	data := []byte{ComStmtClose, 1, 0, 0, 0}
+	cConn.resetSequence()
+	useWritePacket(t, cConn, data)
+	require.True(t, sConn.handleNextCommand(handler))
+
+	assert.True(t, handler.finished)
+	assert.Equal(t, errCursorClosed, handler.err)
+	assert.Empty(t, sConn.PrepareData)
+	assert.Nil(t, sConn.streamingCursor)
+}
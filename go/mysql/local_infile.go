@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"io"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+)
+
+// RequestLocalInfile asks the client to send the contents of the given file,
+// while executing a LOAD DATA LOCAL INFILE statement. It must be called from
+// the ComQuery handler of that statement, before any result is sent.
+//
+// The returned reader returns io.EOF once the client has sent the whole file.
+// It must be closed before the handler returns: closing it discards whatever
+// the client has not sent yet, so the client is ready for the response.
+func (c *Conn) RequestLocalInfile(fileName string) (io.ReadCloser, error) {
+	if c.Capabilities&CapabilityClientLocalFiles == 0 {
+		return nil, sqlerror.NewSQLError(sqlerror.ERNotAllowedCommand, sqlerror.SSClientError, "Loading local data is disabled; this must be enabled on both the client and server sides")
+	}
+
+	data, pos := c.startEphemeralPacketWithHeader(1 + len(fileName))
+	data[pos] = LocalInfilePacket
+	copy(data[pos+1:], fileName)
+	if err := c.writeEphemeralPacket(); err != nil {
+		return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+
+	// the client won't send anything until it has seen the request
+	if err := c.flush(); err != nil {
+		return nil, sqlerror.NewSQLError(sqlerror.CRServerGone, sqlerror.SSUnknownSQLState, "%v", err)
+	}
+	return &localInfileReader{c: c}, nil
+}
+
+// localInfileReader reads the file contents the client sends after a LOCAL INFILE request.
+// The client sends the file as a sequence of packets, followed by an empty packet.
+type localInfileReader struct {
+	c    *Conn
+	data []byte
+	done bool
+	err  error
+}
+
+// Read implements the io.Reader interface
+func (r *localInfileReader) Read(p []byte) (int, error) {
+	for len(r.data) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.done {
+			return 0, io.EOF
+		}
+		data, err := r.c.readPacket()
+		if err != nil {
+			r.err = sqlerror.NewSQLError(sqlerror.CRServerLost, sqlerror.SSUnknownSQLState, "%v", err)
+			return 0, r.err
+		}
+		r.data = data
+		r.done = len(data) == 0
+	}
+
+	n := copy(p, r.data)
+	r.data = r.data[n:]
+	return n, nil
+}
+
+// Close implements the io.Closer interface
+func (r *localInfileReader) Close() error {
+	for !r.done && r.err == nil {
+		r.data = nil
+		if _, err := r.Read(nil); err != nil && err != io.EOF {
+			return err
+		}
+	}
+	r.data = nil
+	return r.err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/sqlerror"
+)
+
+func TestRequestLocalInfile(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	sConn.Capabilities |= CapabilityClientLocalFiles
+
+	type result struct {
+		data []byte
+		err  error
+	}
+	done := make(chan result)
+	go func() {
+		sConn.startWriterBuffering()
+		defer sConn.endWriterBuffering()
+
+		r, err := sConn.RequestLocalInfile("/tmp/data.csv")
+		if err != nil {
+			done <- result{err: err}
+			return
+		}
+		data, err := io.ReadAll(r)
+		if err == nil {
+			err = r.Close()
+		}
+		done <- result{data: data, err: err}
+	}()
+
+	// the request must reach the client even though the server is buffering writes
+	request, err := cConn.readPacket()
+	require.NoError(t, err)
+	assert.Equal(t, append([]byte{LocalInfilePacket}, "/tmp/data.csv"...), request)
+
+	useWritePacket(t, cConn, []byte("1,a\n2,"))
+	useWritePacket(t, cConn, []byte("b\n"))
+	useWritePacket(t, cConn, nil)
+
+	res := <-done
+	require.NoError(t, res.err)
+	assert.Equal(t, "1,a\n2,b\n", string(res.data))
+}
+
+func TestRequestLocalInfileClose(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	sConn.Capabilities |= CapabilityClientLocalFiles
+
+	done := make(chan error)
+	go func() {
+		r, err := sConn.RequestLocalInfile("data.csv")
+		if err != nil {
+			done <- err
+			return
+		}
+		// read a single byte, then give up on the file
+		_, err = r.Read(make([]byte, 1))
+		if err == nil {
+			err = r.Close()
+		}
+		done <- err
+	}()
+
+	_, err := cConn.readPacket()
+	require.NoError(t, err)
+	useWritePacket(t, cConn, []byte("1,a\n"))
+	useWritePacket(t, cConn, []byte("2,b\n"))
+	useWritePacket(t, cConn, nil)
+	require.NoError(t, <-done)
+
+	// closing consumed the whole file, so the next packet the server reads is the next command
+	useWritePacket(t, cConn, []byte{ComPing})
+	data, err := sConn.readPacket()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{ComPing}, data)
+}
+
+func TestRequestLocalInfileDisabled(t *testing.T) {
+	listener, sConn, cConn := createSocketPair(t)
+	defer func() {
+		listener.Close()
+		sConn.Close()
+		cConn.Close()
+	}()
+
+	_, err := sConn.RequestLocalInfile("data.csv")
+	require.Error(t, err)
+	sqlErr, ok := err.(*sqlerror.SQLError)
+	require.True(t, ok)
+	assert.Equal(t, sqlerror.ERNotAllowedCommand, sqlErr.Number())
+}
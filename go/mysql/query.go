@@ -55,7 +55,7 @@ const (
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) WriteComQuery(query string) error {
 	// This is a new command, need to reset the sequence.
-	c.sequence = 0
+	c.resetSequence()
 
 	data, pos := c.startEphemeralPacketWithHeader(len(query) + 1)
 	data[pos] = ComQuery
@@ -71,6 +71,9 @@ func (c *Conn) WriteComQuery(query string) error {
 // Client -> Server.
 // Returns SQLError(CRServerGone) if it can't.
 func (c *Conn) writeComInitDB(db string) error {
+	// This is a new command, need to reset the sequence.
+	c.resetSequence()
+
 	data, pos := c.startEphemeralPacketWithHeader(len(db) + 1)
 	data[pos] = ComInitDB
 	pos++
@@ -574,8 +577,89 @@ func (c *Conn) readComQueryResponse(packetOk *PacketOK) (int, error) {
 // Server side methods.
 //
 
-func (c *Conn) parseComQuery(data []byte) string {
-	return string(data[1:])
+// parseComQuery returns the query of a COM_QUERY packet, and the query
+// attributes sent before it if the client uses CLIENT_QUERY_ATTRIBUTES.
+func (c *Conn) parseComQuery(data []byte) (string, map[string]sqltypes.Value, error) {
+	payload := data[1:]
+	if c.Capabilities&CapabilityClientQueryAttributes == 0 {
+		return string(payload), nil, nil
+	}
+
+	count, pos, ok := readLenEncInt(payload, 0)
+	if !ok {
+		return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+	}
+	// parameter_set_count is always 1.
+	_, pos, ok = readLenEncInt(payload, pos)
+	if !ok {
+		return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter set count failed")
+	}
+	if count == 0 {
+		return string(payload[pos:]), nil, nil
+	}
+	// each attribute takes at least one byte, so this bounds the allocations below
+	if count > uint64(len(payload)) {
+		return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "invalid parameter count %v", count)
+	}
+
+	bitMap, pos, ok := readBytes(payload, pos, (int(count)+7)/8)
+	if !ok {
+		return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
+	}
+	newParamsBoundFlag, pos, ok := readByte(payload, pos)
+	if !ok || newParamsBoundFlag != 0x01 {
+		return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter types failed")
+	}
+
+	names := make([]string, count)
+	types := make([]querypb.Type, count)
+	var err error
+	for i := range types {
+		types[i], pos, err = readParamType(payload, pos)
+		if err != nil {
+			return "", nil, err
+		}
+		names[i], pos, ok = readLenEncString(payload, pos)
+		if !ok {
+			return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter name failed")
+		}
+	}
+
+	attributes := make(map[string]sqltypes.Value, count)
+	for i, name := range names {
+		var val sqltypes.Value
+		if (bitMap[i/8] & (1 << uint(i%8))) > 0 {
+			val, pos, ok = c.parseStmtArgs(nil, sqltypes.Null, pos)
+		} else {
+			val, pos, ok = c.parseStmtArgs(payload, types[i], pos)
+		}
+		if !ok {
+			return "", nil, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding parameter value failed: %v", types[i])
+		}
+		attributes[name] = val
+	}
+	return string(payload[pos:]), attributes, nil
+}
+
+// readParamType reads the type and flags of a parameter of COM_STMT_EXECUTE,
+// or of a query attribute, and converts them to the internal type.
+func readParamType(payload []byte, pos int) (querypb.Type, int, error) {
+	mysqlType, pos, ok := readByte(payload, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+	}
+
+	flags, pos, ok := readByte(payload, pos)
+	if !ok {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+	}
+
+	// convert MySQL type to internal type.
+	valType, err := sqltypes.MySQLToType(mysqlType, int64(flags))
+	if err != nil {
+		return 0, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+	}
+	return valType, pos, nil
 }
 
 func (c *Conn) parseComSetOption(data []byte) (uint16, bool) {
@@ -617,35 +701,58 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "iteration count is not equal to 1")
 	}
 
-	if prepare.ParamsCount > 0 {
-		bitMap, pos, ok = readBytes(payload, pos, (int(prepare.ParamsCount)+7)/8)
+	// With CLIENT_QUERY_ATTRIBUTES, the parameter count is sent, and includes
+	// the query attributes that follow the parameters of the statement.
+	paramsCount := int(prepare.ParamsCount)
+	queryAttributes := c.Capabilities&CapabilityClientQueryAttributes != 0
+	if queryAttributes && (paramsCount > 0 || cursorType&ParameterCountAvailable != 0) {
+		count, newPos, ok := readLenEncInt(payload, pos)
+		if !ok {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter count failed")
+		}
+		if count < uint64(paramsCount) || count > uint64(len(payload)) {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "invalid parameter count %v", count)
+		}
+		pos = newPos
+		paramsCount = int(count)
+	}
+	cursorType &^= ParameterCountAvailable
+
+	if paramsCount > 0 {
+		bitMap, pos, ok = readBytes(payload, pos, (paramsCount+7)/8)
 		if !ok {
 			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading NULL-bitmap failed")
 		}
 	}
 
+	var attributeNames []string
+	var attributeTypes []querypb.Type
 	newParamsBoundFlag, pos, ok := readByte(payload, pos)
 	if ok && newParamsBoundFlag == 0x01 {
-		var mysqlType, flags byte
-		for i := uint16(0); i < prepare.ParamsCount; i++ {
-			mysqlType, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter type failed")
+		for i := 0; i < paramsCount; i++ {
+			valType, newPos, err := readParamType(payload, pos)
+			if err != nil {
+				return stmtID, 0, err
 			}
+			pos = newPos
 
-			flags, pos, ok = readByte(payload, pos)
-			if !ok {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter flags failed")
+			var name string
+			if queryAttributes {
+				name, pos, ok = readLenEncString(payload, pos)
+				if !ok {
+					return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "reading parameter name failed")
+				}
 			}
 
-			// convert MySQL type to internal type.
-			valType, err := sqltypes.MySQLToType(mysqlType, int64(flags))
-			if err != nil {
-				return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "MySQLToType(%v,%v) failed: %v", mysqlType, flags, err)
+			if i < int(prepare.ParamsCount) {
+				prepare.ParamsType[i] = int32(valType)
+				continue
 			}
-
-			prepare.ParamsType[i] = int32(valType)
+			attributeNames = append(attributeNames, name)
+			attributeTypes = append(attributeTypes, valType)
 		}
+	} else if paramsCount > int(prepare.ParamsCount) {
+		return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "query attributes sent without their types")
 	}
 
 	for i := 0; i < len(prepare.ParamsType); i++ {
@@ -669,6 +776,24 @@ func (c *Conn) parseComStmtExecute(prepareData map[uint32]*PrepareData, data []b
 		prepare.BindVars[parameterID] = sqltypes.ValueBindVariable(val)
 	}
 
+	prepare.QueryAttributes = nil
+	for i, name := range attributeNames {
+		var val sqltypes.Value
+		j := int(prepare.ParamsCount) + i
+		if (bitMap[j/8] & (1 << uint(j%8))) > 0 {
+			val, pos, ok = c.parseStmtArgs(nil, sqltypes.Null, pos)
+		} else {
+			val, pos, ok = c.parseStmtArgs(payload, attributeTypes[i], pos)
+		}
+		if !ok {
+			return stmtID, 0, sqlerror.NewSQLError(sqlerror.CRMalformedPacket, sqlerror.SSUnknownSQLState, "decoding parameter value failed: %v", attributeTypes[i])
+		}
+		if prepare.QueryAttributes == nil {
+			prepare.QueryAttributes = make(map[string]sqltypes.Value, len(attributeNames))
+		}
+		prepare.QueryAttributes[name] = val
+	}
+
 	return stmtID, cursorType, nil
 }
 
@@ -928,6 +1053,15 @@ func (c *Conn) parseComStmtReset(data []byte) (uint32, bool) {
 	return val, ok
 }
 
+func (c *Conn) parseComStmtFetch(data []byte) (uint32, uint32, bool) {
+	stmtID, pos, ok := readUint32(data, 1)
+	if !ok {
+		return 0, 0, false
+	}
+	numRows, _, ok := readUint32(data, pos)
+	return stmtID, numRows, ok
+}
+
 func (c *Conn) parseComInitDB(data []byte) string {
 	return string(data[1:])
 }
@@ -331,6 +331,117 @@ func TestComStmtExecuteUpdStmt(t *testing.T) {
 	assert.EqualValues(t, querypb.Type_CHAR, prepData.ParamsType[28], "got: %s", querypb.Type(prepData.ParamsType[28]))
 }
 
+func TestComQueryAttributes(t *testing.T) {
+	c := &Conn{}
+	data := []byte{ComQuery, 's', 'e', 'l', 'e', 'c', 't', ' ', '1'}
+	query, attributes, err := c.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, attributes)
+
+	c.Capabilities = CapabilityClientQueryAttributes
+	data = []byte{
+		ComQuery,
+		0x00, 0x01, // no attributes, one parameter set
+		's', 'e', 'l', 'e', 'c', 't', ' ', '1',
+	}
+	query, attributes, err = c.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Nil(t, attributes)
+
+	data = []byte{
+		ComQuery,
+		0x03, 0x01, // three attributes, one parameter set
+		0x04,                                                     // NULL-bitmap: the third attribute is NULL
+		0x01,                                                     // new params bound flag
+		0xfd, 0x00, 0x08, 'w', 'o', 'r', 'k', 'l', 'o', 'a', 'd', // VAR_STRING 'workload'
+		0x08, 0x00, 0x07, 't', 'i', 'm', 'e', 'o', 'u', 't', // LONGLONG 'timeout'
+		0x06, 0x00, 0x01, 'x', // NULL 'x'
+		0x04, 'o', 'l', 'a', 'p',
+		0xe8, 0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		's', 'e', 'l', 'e', 'c', 't', ' ', '1',
+	}
+	query, attributes, err = c.parseComQuery(data)
+	require.NoError(t, err)
+	assert.Equal(t, "select 1", query)
+	assert.Equal(t, map[string]sqltypes.Value{
+		"workload": sqltypes.NewVarBinary("olap"),
+		"timeout":  sqltypes.NewInt64(1000),
+		"x":        sqltypes.NULL,
+	}, attributes)
+
+	// the attribute value is truncated
+	_, _, err = c.parseComQuery(data[:30])
+	require.ErrorContains(t, err, "decoding parameter value failed")
+}
+
+func TestComStmtExecuteQueryAttributes(t *testing.T) {
+	c := &Conn{Capabilities: CapabilityClientQueryAttributes}
+	prepareData := map[uint32]*PrepareData{
+		1: {
+			StatementID: 1,
+			ParamsCount: 1,
+			ParamsType:  make([]int32, 1),
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+		2: {
+			StatementID: 2,
+			BindVars:    map[string]*querypb.BindVariable{},
+		},
+	}
+
+	data := []byte{
+		ComStmtExecute,
+		0x01, 0x00, 0x00, 0x00, // statement ID
+		CursorTypeNoCursor,
+		0x01, 0x00, 0x00, 0x00, // iteration count
+		0x02,             // the parameter and one attribute
+		0x00,             // NULL-bitmap
+		0x01,             // new params bound flag
+		0x08, 0x00, 0x00, // LONGLONG, unnamed
+		0xfd, 0x00, 0x08, 'p', 'r', 'i', 'o', 'r', 'i', 't', 'y', // VAR_STRING 'priority'
+		0x05, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x02, '1', '0',
+	}
+	stmtID, cursorType, err := c.parseComStmtExecute(prepareData, data)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, stmtID)
+	assert.EqualValues(t, CursorTypeNoCursor, cursorType)
+	prepare := prepareData[1]
+	assert.Equal(t, sqltypes.Int64BindVariable(5), prepare.BindVars["v1"])
+	assert.Equal(t, map[string]sqltypes.Value{"priority": sqltypes.NewVarBinary("10")}, prepare.QueryAttributes)
+
+	// the statement has no parameters, so the count is only sent with PARAMETER_COUNT_AVAILABLE
+	data = []byte{
+		ComStmtExecute,
+		0x02, 0x00, 0x00, 0x00, // statement ID
+		CursorTypeReadOnly | ParameterCountAvailable,
+		0x01, 0x00, 0x00, 0x00, // iteration count
+		0x01,                  // one attribute
+		0x00,                  // NULL-bitmap
+		0x01,                  // new params bound flag
+		0xfd, 0x00, 0x01, 'a', // VAR_STRING 'a'
+		0x01, 'b',
+	}
+	stmtID, cursorType, err = c.parseComStmtExecute(prepareData, data)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, stmtID)
+	assert.EqualValues(t, CursorTypeReadOnly, cursorType)
+	assert.Equal(t, map[string]sqltypes.Value{"a": sqltypes.NewVarBinary("b")}, prepareData[2].QueryAttributes)
+
+	// without the flag, there is no parameter count
+	data = []byte{
+		ComStmtExecute,
+		0x02, 0x00, 0x00, 0x00, // statement ID
+		CursorTypeNoCursor,
+		0x01, 0x00, 0x00, 0x00, // iteration count
+	}
+	_, _, err = c.parseComStmtExecute(prepareData, data)
+	require.NoError(t, err)
+	assert.Nil(t, prepareData[2].QueryAttributes)
+}
+
 func TestComStmtClose(t *testing.T) {
 	listener, sConn, cConn := createSocketPair(t)
 	defer func() {
@@ -467,6 +467,20 @@ func (set Mysql56GTIDSet) SIDBlock() []byte {
 	return buf.Bytes()
 }
 
+// GTIDs returns every GTID of the set, ordered by SID and sequence number.
+// As it doesn't compress intervals, it should only be used on small sets.
+func (set Mysql56GTIDSet) GTIDs() []Mysql56GTID {
+	var gtids []Mysql56GTID
+	for _, sid := range set.SIDs() {
+		for _, iv := range set[sid] {
+			for sequence := iv.start; sequence <= iv.end; sequence++ {
+				gtids = append(gtids, Mysql56GTID{Server: sid, Sequence: sequence})
+			}
+		}
+	}
+	return gtids
+}
+
 // Difference will supply the difference between the receiver and supplied Mysql56GTIDSets, and supply the result
 // as a Mysql56GTIDSet.
 func (set Mysql56GTIDSet) Difference(other Mysql56GTIDSet) Mysql56GTIDSet {
@@ -585,6 +585,25 @@ func TestMySQL56GTIDSetLast(t *testing.T) {
 	}
 }
 
+func TestMysql56GTIDSetGTIDs(t *testing.T) {
+	sid1 := SID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+	sid2 := SID{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 255}
+
+	set := Mysql56GTIDSet{
+		sid2: []interval{{7, 7}},
+		sid1: []interval{{1, 2}, {5, 6}},
+	}
+	want := []Mysql56GTID{
+		{Server: sid1, Sequence: 1},
+		{Server: sid1, Sequence: 2},
+		{Server: sid1, Sequence: 5},
+		{Server: sid1, Sequence: 6},
+		{Server: sid2, Sequence: 7},
+	}
+	assert.Equal(t, want, set.GTIDs())
+	assert.Empty(t, Mysql56GTIDSet{}.GTIDs())
+}
+
 func TestSubtract(t *testing.T) {
 	tests := []struct {
 		name       string
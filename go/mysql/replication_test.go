@@ -23,6 +23,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"vitess.io/vitess/go/mysql/replication"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 )
 
@@ -119,6 +120,23 @@ func TestComBinlogDumpGTID(t *testing.T) {
 		}
 		assert.Equal(t, expectedData, data)
 	})
+
+	sConn.sequence = 0
+
+	t.Run("parse SID block", func(t *testing.T) {
+		// MySQL clients send the SID block of their GTID set,
+		// and don't set BinlogThroughGTID.
+		set, err := replication.ParseMysql56GTIDSet("00010203-0405-0607-0809-0a0b0c0d0e0f:1-5")
+		require.NoError(t, err)
+		err = cConn.WriteComBinlogDumpGTID(0x01020304, "", 4, 0, set.SIDBlock())
+		assert.NoError(t, err)
+		data, err := sConn.ReadPacket()
+		require.NoError(t, err, "sConn.ReadPacket - ComBinlogDumpGTID failed: %v", err)
+
+		_, _, position, err := sConn.parseComBinlogDumpGTID(data)
+		require.NoError(t, err)
+		assert.True(t, set.Equal(position.GTIDSet), "got %v, want %v", position.GTIDSet, set)
+	})
 	f := NewMySQL56BinlogFormat()
 	s := NewFakeBinlogStream()
 
@@ -104,7 +104,8 @@ type Handler interface {
 	// ComQuery is called when a connection receives a query.
 	// Note the contents of the query slice may change after
 	// the first call to callback. So the Handler should not
-	// hang on to the byte slice.
+	// hang on to the byte slice. The query attributes the client
+	// sent with the query are returned by c.QueryAttributes().
 	ComQuery(c *Conn, query string, callback func(*sqltypes.Result) error) error
 
 	// ComPrepare is called when a connection receives a prepared
@@ -112,7 +113,10 @@ type Handler interface {
 	ComPrepare(c *Conn, query string, bindVars map[string]*querypb.BindVariable) ([]*querypb.Field, error)
 
 	// ComStmtExecute is called when a connection receives a statement
-	// execute query.
+	// execute query. If prepare.CursorType is CursorTypeReadOnly, the
+	// Handler should stream the result, as callback then blocks until
+	// the client fetches more rows. The query attributes the client
+	// sent with the execute are in prepare.QueryAttributes.
 	ComStmtExecute(c *Conn, prepare *PrepareData, callback func(*sqltypes.Result) error) error
 
 	// ComRegisterReplica is called when a connection receives a ComRegisterReplica request
@@ -207,6 +211,27 @@ type Listener struct {
 	// RequireSecureTransport configures the server to reject connections from insecure clients
 	RequireSecureTransport bool
 
+	// AllowLocalInfile configures the server to advertise CLIENT_LOCAL_FILES,
+	// so clients can send files with LOAD DATA LOCAL INFILE
+	AllowLocalInfile bool
+
+	// AllowCompression configures the server to advertise CLIENT_COMPRESS,
+	// so clients can use the compressed protocol with zlib
+	AllowCompression bool
+
+	// AllowZstdCompression configures the server to advertise CLIENT_ZSTD_COMPRESSION_ALGORITHM,
+	// so clients can use the compressed protocol with zstd
+	AllowZstdCompression bool
+
+	// AllowQueryAttributes configures the server to advertise CLIENT_QUERY_ATTRIBUTES,
+	// so clients can send query attributes with their queries
+	AllowQueryAttributes bool
+
+	// MaxCursorMemoryRows is the maximum number of rows of an open cursor that
+	// are read into memory when the client sends another command before fetching
+	// the whole result. The cursor fails past it. There is no limit when zero.
+	MaxCursorMemoryRows int
+
 	// PreHandleFunc is called for each incoming connection, immediately after
 	// accepting a new connection. By default it's no-op. Useful for custom
 	// connection inspection or TLS termination. The returned connection is
@@ -391,7 +416,7 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	defer connCount.Add(-1)
 
 	// First build and send the server handshake packet.
-	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil)
+	serverAuthPluginData, err := c.writeHandshakeV10(l.ServerVersion, l.authServer, uint8(l.charset), l.TLSConfig.Load() != nil, l.optionalCapabilities())
 	if err != nil {
 		if err != io.EOF {
 			log.Errorf("Cannot send HandshakeV10 packet to %s: %v", c, err)
@@ -534,6 +559,14 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 		return
 	}
 
+	// Everything after the OK packet uses the compressed protocol, if negotiated.
+	if algorithm := c.compressionAlgorithm(); algorithm != "" {
+		if err := c.enableCompression(algorithm, c.zstdCompressionLevel); err != nil {
+			log.Errorf("Cannot enable %s compression for %s: %v", algorithm, c, err)
+			return
+		}
+	}
+
 	// Record how long we took to establish the connection
 	timings.Record(connectTimingKey, acceptTime)
 
@@ -548,6 +581,9 @@ func (l *Listener) handle(conn net.Conn, connectionID uint32, acceptTime time.Ti
 	// process commands.
 	l.handler.ConnectionReady(c)
 
+	// Stop the handler of any cursor the client left open.
+	defer c.closeCursors()
+
 	for {
 		kontinue := c.handleNextCommand(l.handler)
 		// before going for next command check if the connection should be closed or not.
@@ -572,7 +608,7 @@ func (l *Listener) Shutdown() {
 
 // writeHandshakeV10 writes the Initial Handshake Packet, server side.
 // It returns the salt data.
-func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool) ([]byte, error) {
+func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, charset uint8, enableTLS bool, optionalCapabilities uint32) ([]byte, error) {
 	capabilities := CapabilityClientLongPassword |
 		CapabilityClientFoundRows |
 		CapabilityClientLongFlag |
@@ -589,6 +625,7 @@ func (c *Conn) writeHandshakeV10(serverVersion string, authServer AuthServer, ch
 	if enableTLS {
 		capabilities |= CapabilityClientSSL
 	}
+	capabilities |= int(optionalCapabilities)
 
 	// Grab the default auth method. This can only be either
 	// mysql_native_password or caching_sha2_password. Both
@@ -714,6 +751,24 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 		c.Capabilities |= CapabilityClientMultiStatements
 	}
 
+	// the client can only send local files if we advertised that we accept them
+	if l.AllowLocalInfile && clientFlags&CapabilityClientLocalFiles > 0 {
+		c.Capabilities |= CapabilityClientLocalFiles
+	}
+
+	// like MySQL, prefer zlib if the client asks for both compression algorithms
+	if l.AllowCompression && clientFlags&CapabilityClientCompress > 0 {
+		c.Capabilities |= CapabilityClientCompress
+	} else if l.AllowZstdCompression && clientFlags&CapabilityClientZstdCompressionAlgorithm > 0 {
+		c.Capabilities |= CapabilityClientZstdCompressionAlgorithm
+		c.zstdCompressionLevel = DefaultZstdCompressionLevel
+	}
+
+	// query attributes change the format of COM_QUERY and COM_STMT_EXECUTE
+	if l.AllowQueryAttributes && clientFlags&CapabilityClientQueryAttributes > 0 {
+		c.Capabilities |= CapabilityClientQueryAttributes
+	}
+
 	// Max packet size. Don't do anything with this now.
 	// See doc.go for more information.
 	_, pos, ok = readUint32(data, pos)
@@ -805,15 +860,44 @@ func (l *Listener) parseClientHandshakePacket(c *Conn, firstTime bool, data []by
 	}
 
 	// Decode connection attributes send by the client
+	attrsOK := true
 	if clientFlags&CapabilityClientConnAttr != 0 {
-		if _, _, err := parseConnAttrs(data, pos); err != nil {
+		_, next, err := parseConnAttrs(data, pos)
+		if err != nil {
 			log.Warningf("Decode connection attributes send by the client: %v", err)
 		}
+		pos, attrsOK = next, err == nil
+	}
+
+	// The zstd compression level comes last.
+	if attrsOK && c.Capabilities&CapabilityClientZstdCompressionAlgorithm != 0 {
+		if level, _, ok := readByte(data, pos); ok && level != 0 {
+			c.zstdCompressionLevel = int(level)
+		}
 	}
 
 	return username, AuthMethodDescription(authMethod), authResponse, nil
 }
 
+// optionalCapabilities returns the capabilities the server advertises
+// depending on how the listener is configured.
+func (l *Listener) optionalCapabilities() uint32 {
+	var capabilities uint32
+	if l.AllowLocalInfile {
+		capabilities |= CapabilityClientLocalFiles
+	}
+	if l.AllowCompression {
+		capabilities |= CapabilityClientCompress
+	}
+	if l.AllowZstdCompression {
+		capabilities |= CapabilityClientZstdCompressionAlgorithm
+	}
+	if l.AllowQueryAttributes {
+		capabilities |= CapabilityClientQueryAttributes
+	}
+	return capabilities
+}
+
 func parseConnAttrs(data []byte, pos int) (map[string]string, int, error) {
 	var attrLen uint64
 
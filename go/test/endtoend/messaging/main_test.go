@@ -98,7 +98,10 @@ var (
 			"unsharded_message": {},
 			"vitess_message": {},
 			"vitess_message3": {},
-			"vitess_message4": {}
+			"vitess_message4": {},
+			"vitess_message5": {},
+			"vitess_message6": {},
+			"vitess_message_dlq": {}
 	  }
 	}`
 )
@@ -354,6 +354,179 @@ func TestSpecificStreamingColsMessage(t *testing.T) {
 	assert.Equal(t, uint64(1), qr.RowsAffected)
 }
 
+var createDeadLetterMessage = `create table vitess_message_dlq(
+	# required columns
+	id bigint NOT NULL COMMENT 'often an event id, can also be auto-increment or a sequence',
+	priority tinyint NOT NULL DEFAULT '50' COMMENT 'lower number priorities process first',
+	epoch bigint NOT NULL DEFAULT '0' COMMENT 'Vitess increments this each time it sends a message, and is used for incremental backoff doubling',
+	time_next bigint DEFAULT 0 COMMENT 'the earliest time the message will be sent in epoch nanoseconds. Must be null if time_acked is set',
+	time_acked bigint DEFAULT NULL COMMENT 'the time the message was acked in epoch nanoseconds. Must be null if time_next is set',
+
+	# add as many custom fields here as required
+	# optional - these are suggestions
+	tenant_id bigint,
+	message json,
+
+	# required indexes
+	primary key(id),
+	index poller_idx(time_acked, priority, time_next desc)
+) comment 'vitess_message,vt_ack_wait=1,vt_purge_after=3,vt_batch_size=2,vt_cache_size=10,vt_poller_interval=1'`
+
+var createMaxRetriesMessage = `create table vitess_message5(
+	# required columns
+	id bigint NOT NULL COMMENT 'often an event id, can also be auto-increment or a sequence',
+	priority tinyint NOT NULL DEFAULT '50' COMMENT 'lower number priorities process first',
+	epoch bigint NOT NULL DEFAULT '0' COMMENT 'Vitess increments this each time it sends a message, and is used for incremental backoff doubling',
+	time_next bigint DEFAULT 0 COMMENT 'the earliest time the message will be sent in epoch nanoseconds. Must be null if time_acked is set',
+	time_acked bigint DEFAULT NULL COMMENT 'the time the message was acked in epoch nanoseconds. Must be null if time_next is set',
+
+	# add as many custom fields here as required
+	# optional - these are suggestions
+	tenant_id bigint,
+	message json,
+
+	# required indexes
+	primary key(id),
+	index poller_idx(time_acked, priority, time_next desc)
+) comment 'vitess_message,vt_ack_wait=1,vt_purge_after=3,vt_batch_size=2,vt_cache_size=10,vt_poller_interval=1,vt_max_retries=1,vt_dead_letter_table=vitess_message_dlq'`
+
+func TestDeadLetterMessage(t *testing.T) {
+	ctx := context.Background()
+
+	vtParams := mysql.ConnParams{
+		Host: "localhost",
+		Port: clusterInstance.VtgateMySQLPort,
+	}
+	conn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	streamConn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer streamConn.Close()
+
+	dlqStreamConn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer dlqStreamConn.Close()
+
+	utils.Exec(t, conn, fmt.Sprintf("use %s", lookupKeyspace))
+	utils.Exec(t, conn, createDeadLetterMessage)
+	defer utils.Exec(t, conn, "drop table vitess_message_dlq")
+	utils.Exec(t, conn, createMaxRetriesMessage)
+	defer utils.Exec(t, conn, "drop table vitess_message5")
+	clusterInstance.VtctlProcess.ExecuteCommand(fmt.Sprintf("ReloadSchemaKeyspace %s", lookupKeyspace))
+
+	for sc, name := range map[*mysql.Conn]string{streamConn: "vitess_message5", dlqStreamConn: "vitess_message_dlq"} {
+		utils.Exec(t, sc, "set workload = 'olap'")
+		err = sc.ExecuteStreamFetch("stream * from " + name)
+		require.NoError(t, err)
+		_, err = sc.Fields()
+		require.NoError(t, err)
+	}
+
+	utils.Exec(t, conn, fmt.Sprintf("insert into vitess_message5(id, tenant_id, message) values(1, 1, '%s')", testMessage))
+	want := []sqltypes.Value{
+		sqltypes.NewInt64(1),
+		sqltypes.NewInt64(1),
+		sqltypes.TestValue(sqltypes.TypeJSON, testMessage),
+	}
+
+	// The message is sent once.
+	got, err := streamConn.FetchNext(nil)
+	require.NoError(t, err)
+	cmp.MustMatch(t, want, got)
+
+	// Instead of being resent, it's moved to the dead-letter table.
+	got, err = dlqStreamConn.FetchNext(nil)
+	require.NoError(t, err)
+	cmp.MustMatch(t, want, got)
+	qr := utils.Exec(t, conn, "select id from vitess_message5 where id = 1")
+	assert.Equal(t, 0, len(qr.Rows))
+
+	// Replay the dead letter.
+	utils.Exec(t, conn, "begin")
+	utils.Exec(t, conn, "insert into vitess_message5(id, tenant_id, message) select id, tenant_id, message from vitess_message_dlq where id = 1")
+	utils.Exec(t, conn, "delete from vitess_message_dlq where id = 1")
+	utils.Exec(t, conn, "commit")
+
+	got, err = streamConn.FetchNext(nil)
+	require.NoError(t, err)
+	cmp.MustMatch(t, want, got)
+
+	qr = utils.Exec(t, conn, "update vitess_message5 set time_acked = 123, time_next = null where id = 1 and time_acked is null")
+	assert.Equal(t, uint64(1), qr.RowsAffected)
+}
+
+var createPartitionedMessage = `create table vitess_message6(
+	# required columns
+	id bigint NOT NULL COMMENT 'often an event id, can also be auto-increment or a sequence',
+	priority tinyint NOT NULL DEFAULT '50' COMMENT 'lower number priorities process first',
+	epoch bigint NOT NULL DEFAULT '0' COMMENT 'Vitess increments this each time it sends a message, and is used for incremental backoff doubling',
+	time_next bigint DEFAULT 0 COMMENT 'the earliest time the message will be sent in epoch nanoseconds. Must be null if time_acked is set',
+	time_acked bigint DEFAULT NULL COMMENT 'the time the message was acked in epoch nanoseconds. Must be null if time_next is set',
+
+	# required for partitioned messages
+	time_scheduled bigint NOT NULL COMMENT 'messages of a partition are sent in this order',
+	tenant_id bigint COMMENT 'the partition key',
+
+	message json,
+
+	# required indexes
+	primary key(id),
+	index poller_idx(time_acked, priority, time_next desc),
+	index partition_idx(tenant_id, time_acked, time_scheduled)
+) comment 'vitess_message,vt_ack_wait=1,vt_purge_after=3,vt_batch_size=2,vt_cache_size=10,vt_poller_interval=1,vt_partition_key=tenant_id'`
+
+func TestPartitionedMessage(t *testing.T) {
+	ctx := context.Background()
+
+	vtParams := mysql.ConnParams{
+		Host: "localhost",
+		Port: clusterInstance.VtgateMySQLPort,
+	}
+	conn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer conn.Close()
+
+	streamConn, err := mysql.Connect(ctx, &vtParams)
+	require.NoError(t, err)
+	defer streamConn.Close()
+
+	utils.Exec(t, conn, fmt.Sprintf("use %s", lookupKeyspace))
+	utils.Exec(t, conn, createPartitionedMessage)
+	defer utils.Exec(t, conn, "drop table vitess_message6")
+	clusterInstance.VtctlProcess.ExecuteCommand(fmt.Sprintf("ReloadSchemaKeyspace %s", lookupKeyspace))
+
+	utils.Exec(t, streamConn, "set workload = 'olap'")
+	err = streamConn.ExecuteStreamFetch("stream * from vitess_message6")
+	require.NoError(t, err)
+	_, err = streamConn.Fields()
+	require.NoError(t, err)
+
+	// Messages 1 and 2 share a partition, and 2 is scheduled last.
+	utils.Exec(t, conn, fmt.Sprintf("insert into vitess_message6(id, time_scheduled, tenant_id, message) values(2, 2, 1, '%s'), (1, 1, 1, '%s'), (3, 1, 2, '%s')", testMessage, testMessage, testMessage))
+
+	got := map[int64]bool{}
+	for i := 0; i < 2; i++ {
+		row, err := streamConn.FetchNext(nil)
+		require.NoError(t, err)
+		id, err := row[0].ToInt64()
+		require.NoError(t, err)
+		got[id] = true
+	}
+	assert.Equal(t, map[int64]bool{1: true, 3: true}, got)
+
+	// Message 2 is sent only after message 1 is acked.
+	qr := utils.Exec(t, conn, "update vitess_message6 set time_acked = 123, time_next = null where id in (1, 3) and time_acked is null")
+	assert.Equal(t, uint64(2), qr.RowsAffected)
+	row, err := streamConn.FetchNext(nil)
+	require.NoError(t, err)
+	assert.Equal(t, sqltypes.NewInt64(2), row[0])
+
+	qr = utils.Exec(t, conn, "update vitess_message6 set time_acked = 123, time_next = null where id = 2 and time_acked is null")
+	assert.Equal(t, uint64(1), qr.RowsAffected)
+}
+
 func getTimeEpoch(qr *sqltypes.Result) (int64, int64) {
 	if len(qr.Rows) != 1 {
 		return 0, 0
@@ -1425,9 +1425,10 @@ func TestReplaceWithFK(t *testing.T) {
 	conn := mcmp.VtConn
 	defer closer()
 
-	// replace some data.
-	_, err := utils.ExecAllowError(t, conn, `replace into t1(id, col) values (1, 1)`)
-	require.ErrorContains(t, err, "VT12001: unsupported: REPLACE INTO with sharded keyspace (errno 1235) (sqlstate 42000)")
+	// replace some data in the sharded keyspace.
+	_ = utils.Exec(t, conn, `replace into t1(id, col) values (1, 1)`)
+	_ = utils.Exec(t, conn, `replace into t1(id, col) values (1, 2)`)
+	utils.AssertMatches(t, conn, `select id, col from t1 where id = 1`, `[[INT64(1) INT64(2)]]`)
 
 	_ = utils.Exec(t, conn, `use uks`)
 
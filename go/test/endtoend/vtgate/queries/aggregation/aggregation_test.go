@@ -112,6 +112,7 @@ func TestGroupBy(t *testing.T) {
 		if utils.BinaryIsAtLeastAtVersion(20, "vtgate") &&
 			utils.BinaryIsAtLeastAtVersion(20, "vttablet") {
 			mcmp.Exec("select id6, id7, count(*) k from t3 group by id6, id7 with rollup")
+			mcmp.Exec("select id6, k from (select id6, count(*) k from t3 group by id6 with rollup) as t where t.id6 is null")
 		}
 	}
 }
@@ -89,6 +89,8 @@ type DBConfigs struct {
 	ConnectTimeoutMilliseconds int           `json:"connectTimeoutMilliseconds,omitempty"`
 	DBName                     string        `json:"dbName,omitempty"`
 	EnableQueryInfo            bool          `json:"enableQueryInfo,omitempty"`
+	Compression                string        `json:"compression,omitempty"`
+	ZstdCompressionLevel       int           `json:"zstdCompressionLevel,omitempty"`
 
 	App          UserConfig `json:"app,omitempty"`
 	Dba          UserConfig `json:"dba,omitempty"`
@@ -145,6 +147,8 @@ func registerBaseFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&GlobalDBConfigs.ServerName, "db_server_name", "", "server name of the DB we are connecting to.")
 	fs.IntVar(&GlobalDBConfigs.ConnectTimeoutMilliseconds, "db_connect_timeout_ms", 0, "connection timeout to mysqld in milliseconds (0 for no timeout)")
 	fs.BoolVar(&GlobalDBConfigs.EnableQueryInfo, "db_conn_query_info", false, "enable parsing and processing of QUERY_OK info fields")
+	fs.StringVar(&GlobalDBConfigs.Compression, "db-compression", "", "Compression algorithm to use for connections to mysqld if it supports it, zlib or zstd. Compression is disabled if empty.")
+	fs.IntVar(&GlobalDBConfigs.ZstdCompressionLevel, "db-zstd-compression-level", mysql.DefaultZstdCompressionLevel, "Compression level to use with zstd compression.")
 }
 
 // The flags will change the global singleton
@@ -355,6 +359,8 @@ func (dbcfgs *DBConfigs) InitWithSocket(defaultSocketFile string, collationEnv *
 		}
 		cp.ConnectTimeoutMs = uint64(dbcfgs.ConnectTimeoutMilliseconds)
 		cp.EnableQueryInfo = dbcfgs.EnableQueryInfo
+		cp.Compression = dbcfgs.Compression
+		cp.ZstdCompressionLevel = dbcfgs.ZstdCompressionLevel
 
 		cp.Uname = uc.User
 		cp.Pass = uc.Password
@@ -0,0 +1,238 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlparser
+
+import (
+	"io"
+	"slices"
+)
+
+// RewriteAnyAllComparison rewrites a comparison with an ANY, SOME or ALL modifier against a subquery
+// into an expression that can be evaluated across shards:
+//
+//	x = ANY (subq)  => x IN (subq)
+//	x <> ALL (subq) => x NOT IN (subq)
+//
+// All other comparisons are turned into a CASE over scalar aggregations of the subquery.
+// For `x > ALL (subq)` this is
+//
+//	CASE
+//	  WHEN (SELECT SUM(c IS NULL) ...) IS NULL THEN 1                 -- the subquery returned no rows
+//	  WHEN x <= (SELECT MAX(c) ...) THEN 0                            -- a row is larger or equal to x
+//	  WHEN x IS NULL OR (SELECT SUM(c IS NULL) ...) > 0 THEN NULL     -- a comparison was unknown
+//	  ELSE 1
+//	END
+//
+// which gives the same TRUE, FALSE and NULL results as MySQL does.
+// The second return value is false when the expression can't be rewritten.
+func RewriteAnyAllComparison(cmp *ComparisonExpr) (Expr, bool) {
+	subq, ok := cmp.Right.(*Subquery)
+	if !ok || cmp.Modifier == Missing {
+		return nil, false
+	}
+
+	switch {
+	case cmp.Operator == EqualOp && cmp.Modifier == Any:
+		return &ComparisonExpr{Operator: InOp, Left: cmp.Left, Right: subq}, true
+	case cmp.Operator == NotEqualOp && cmp.Modifier == All:
+		return &ComparisonExpr{Operator: NotInOp, Left: cmp.Left, Right: subq}, true
+	}
+
+	if _, isTuple := cmp.Left.(ValTuple); isTuple || len(subq.Select.GetColumns()) != 1 {
+		// MySQL only allows row comparisons with = ANY and <> ALL
+		return nil, false
+	}
+
+	// empty is the result when the subquery returns no rows
+	var empty int
+	if cmp.Modifier == All {
+		empty = 1
+	}
+
+	var decisive Expr
+	switch cmp.Operator {
+	case EqualOp, NotEqualOp:
+		// x = ALL is false and x <> ANY is true as soon as one row differs from x,
+		// which is the case when either the smallest or the largest row differs.
+		decisive = &OrExpr{
+			Left:  &ComparisonExpr{Operator: NotEqualOp, Left: CloneExpr(cmp.Left), Right: aggregateSubquery(subq, minOf)},
+			Right: &ComparisonExpr{Operator: NotEqualOp, Left: CloneExpr(cmp.Left), Right: aggregateSubquery(subq, maxOf)},
+		}
+	case LessThanOp, LessEqualOp, GreaterThanOp, GreaterEqualOp:
+		op := cmp.Operator
+		if cmp.Modifier == All {
+			// x > ALL is false as soon as one row is not smaller than x
+			_, op = inverseOp(op)
+		}
+		// the row that decides the outcome is the one closest to x from the side the operator looks at
+		aggr := minOf
+		if op == LessThanOp || op == LessEqualOp {
+			aggr = maxOf
+		}
+		decisive = &ComparisonExpr{Operator: op, Left: CloneExpr(cmp.Left), Right: aggregateSubquery(subq, aggr)}
+	default:
+		return nil, false
+	}
+
+	return &CaseExpr{
+		Whens: []*When{{
+			Cond: &IsExpr{Left: aggregateSubquery(subq, nullCount), Right: IsNullOp},
+			Val:  NewIntLiteral(boolToIntString(empty == 1)),
+		}, {
+			Cond: decisive,
+			Val:  NewIntLiteral(boolToIntString(empty == 0)),
+		}, {
+			Cond: &OrExpr{
+				Left:  &IsExpr{Left: CloneExpr(cmp.Left), Right: IsNullOp},
+				Right: &ComparisonExpr{Operator: GreaterThanOp, Left: aggregateSubquery(subq, nullCount), Right: NewIntLiteral("0")},
+			},
+			Val: &NullVal{},
+		}},
+		Else: NewIntLiteral(boolToIntString(empty == 1)),
+	}, true
+}
+
+// RewriteAnyAllComparisons returns a copy of the statement where all the comparisons using ANY, SOME or ALL
+// have been rewritten using RewriteAnyAllComparison. MIN and MAX compare the rows of the subquery using the type
+// of its column, and not the type the comparison uses, so comparisons that are rewritten into aggregations are
+// only rewritten when canAggregate returns true for them. It returns nil if there was nothing to rewrite.
+func RewriteAnyAllComparisons(stmt SelectStatement, canAggregate func(cmp *ComparisonExpr) bool) SelectStatement {
+	// canAggregate is called with the comparisons of the statement passed in, which are matched
+	// with the comparisons of the copy by the order they are visited in.
+	var allowed []bool
+	_ = Walk(func(node SQLNode) (bool, error) {
+		if cmp, ok := node.(*ComparisonExpr); ok && cmp.Modifier != Missing {
+			allowed = append(allowed, rewritesToIn(cmp) || canAggregate(cmp))
+		}
+		return true, nil
+	}, stmt)
+	if !slices.Contains(allowed, true) {
+		return nil
+	}
+
+	rewrite := make(map[*ComparisonExpr]bool, len(allowed))
+	rewritten := false
+	result := Rewrite(CloneSelectStatement(stmt), func(cursor *Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case *ComparisonExpr:
+			if node.Modifier != Missing {
+				rewrite[node] = allowed[len(rewrite)]
+			}
+		case *AliasedExpr:
+			if node.As.IsEmpty() && ContainsAnyAllComparison(node.Expr) {
+				// the column keeps the name it has in the original query
+				node.As = NewIdentifierCI(node.ColumnName())
+			}
+		}
+		return true
+	}, func(cursor *Cursor) bool {
+		cmp, ok := cursor.Node().(*ComparisonExpr)
+		if !ok || !rewrite[cmp] {
+			return true
+		}
+		if newExpr, ok := RewriteAnyAllComparison(cmp); ok {
+			cursor.Replace(newExpr)
+			rewritten = true
+		}
+		return true
+	})
+	if !rewritten {
+		return nil
+	}
+	return result.(SelectStatement)
+}
+
+// rewritesToIn returns true if the comparison is rewritten into IN or NOT IN, which compare the values
+// the same way the comparison does
+func rewritesToIn(cmp *ComparisonExpr) bool {
+	return (cmp.Operator == EqualOp && cmp.Modifier == Any) || (cmp.Operator == NotEqualOp && cmp.Modifier == All)
+}
+
+// ContainsAnyAllComparison returns true if the node contains a comparison using ANY, SOME or ALL.
+func ContainsAnyAllComparison(node SQLNode) bool {
+	found := false
+	_ = Walk(func(node SQLNode) (bool, error) {
+		if cmp, ok := node.(*ComparisonExpr); ok && cmp.Modifier != Missing {
+			found = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, node)
+	return found
+}
+
+func minOf(e Expr) Expr { return &Min{Arg: e} }
+
+func maxOf(e Expr) Expr { return &Max{Arg: e} }
+
+// nullCount returns NULL when there are no rows, and the number of NULL values otherwise
+func nullCount(e Expr) Expr { return &Sum{Arg: &IsExpr{Left: e, Right: IsNullOp}} }
+
+func boolToIntString(b bool) string {
+	if b {
+		return "1"
+	}
+	return "0"
+}
+
+// aggregateSubquery returns a scalar subquery that aggregates the single column of the given subquery.
+func aggregateSubquery(subq *Subquery, aggr func(Expr) Expr) *Subquery {
+	if sel, ok := subq.Select.(*Select); ok && canAggregateInPlace(sel) {
+		clone := CloneRefOfSelect(sel)
+		ae := clone.SelectExprs[0].(*AliasedExpr)
+		clone.SelectExprs = SelectExprs{&AliasedExpr{Expr: aggr(ae.Expr)}}
+		clone.OrderBy = nil
+		return &Subquery{Select: clone}
+	}
+
+	// anything more complicated is aggregated on top of a derived table
+	const alias = "__vt_anyall"
+	derived := &AliasedTableExpr{
+		Expr:    &DerivedTable{Select: CloneSelectStatement(subq.Select)},
+		As:      NewIdentifierCS(alias),
+		Columns: Columns{NewIdentifierCI("c")},
+	}
+	col := NewColNameWithQualifier("c", NewTableName(alias))
+	return &Subquery{Select: &Select{
+		SelectExprs: SelectExprs{&AliasedExpr{Expr: aggr(col)}},
+		From:        []TableExpr{derived},
+	}}
+}
+
+// canAggregateInPlace returns true if the select expression of the query can be wrapped in an aggregation
+// without changing which rows are aggregated
+func canAggregateInPlace(sel *Select) bool {
+	if sel.Distinct || sel.GroupBy != nil || sel.Having != nil || sel.Limit != nil || len(sel.Windows) > 0 || sel.Into != nil {
+		return false
+	}
+	ae, ok := sel.SelectExprs[0].(*AliasedExpr)
+	if !ok {
+		return false
+	}
+	aggrOrWindow := false
+	_ = Walk(func(node SQLNode) (bool, error) {
+		switch node.(type) {
+		case *Subquery:
+			return false, nil
+		case AggrFunc, *OverClause:
+			aggrOrWindow = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, ae.Expr)
+	return !aggrOrWindow
+}
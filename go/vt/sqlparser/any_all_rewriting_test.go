@@ -0,0 +1,138 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sqlparser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRewriteAnyAllComparison(in *testing.T) {
+	tests := []struct {
+		in       string
+		expected string
+	}{{
+		in:       "a = any (select b from t)",
+		expected: "a in (select b from t)",
+	}, {
+		in:       "a = some (select b from t)",
+		expected: "a in (select b from t)",
+	}, {
+		in:       "a <> all (select b from t)",
+		expected: "a not in (select b from t)",
+	}, {
+		in:       "a > all (select b from t where c = 1 order by b asc)",
+		expected: "case when (select sum(b is null) from t where c = 1) is null then 1 when a <= (select max(b) from t where c = 1) then 0 when a is null or (select sum(b is null) from t where c = 1) > 0 then null else 1 end",
+	}, {
+		in:       "a < all (select b from t)",
+		expected: "case when (select sum(b is null) from t) is null then 1 when a >= (select min(b) from t) then 0 when a is null or (select sum(b is null) from t) > 0 then null else 1 end",
+	}, {
+		in:       "a >= any (select b from t)",
+		expected: "case when (select sum(b is null) from t) is null then 0 when a >= (select min(b) from t) then 1 when a is null or (select sum(b is null) from t) > 0 then null else 0 end",
+	}, {
+		in:       "a <= some (select b from t)",
+		expected: "case when (select sum(b is null) from t) is null then 0 when a <= (select max(b) from t) then 1 when a is null or (select sum(b is null) from t) > 0 then null else 0 end",
+	}, {
+		in:       "a = all (select b from t)",
+		expected: "case when (select sum(b is null) from t) is null then 1 when a != (select min(b) from t) or a != (select max(b) from t) then 0 when a is null or (select sum(b is null) from t) > 0 then null else 1 end",
+	}, {
+		in:       "a <> any (select b from t)",
+		expected: "case when (select sum(b is null) from t) is null then 0 when a != (select min(b) from t) or a != (select max(b) from t) then 1 when a is null or (select sum(b is null) from t) > 0 then null else 0 end",
+	}, {
+		in:       "a > all (select max(b) from t group by c)",
+		expected: "case when (select sum(__vt_anyall.c is null) from (select max(b) from t group by c) as __vt_anyall(c)) is null then 1 when a <= (select max(__vt_anyall.c) from (select max(b) from t group by c) as __vt_anyall(c)) then 0 when a is null or (select sum(__vt_anyall.c is null) from (select max(b) from t group by c) as __vt_anyall(c)) > 0 then null else 1 end",
+	}, {
+		in:       "a < any (select b from t union select c from u)",
+		expected: "case when (select sum(__vt_anyall.c is null) from (select b from t union select c from u) as __vt_anyall(c)) is null then 0 when a < (select max(__vt_anyall.c) from (select b from t union select c from u) as __vt_anyall(c)) then 1 when a is null or (select sum(__vt_anyall.c is null) from (select b from t union select c from u) as __vt_anyall(c)) > 0 then null else 0 end",
+	}}
+
+	parser := NewTestParser()
+	for _, tc := range tests {
+		in.Run(tc.in, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tc.in)
+			require.NoError(t, err)
+
+			expr, changed := RewriteAnyAllComparison(expr.(*ComparisonExpr))
+			require.True(t, changed)
+			assert.Equal(t, tc.expected, String(expr))
+		})
+	}
+
+	for _, query := range []string{
+		"a > b",
+		"a in (select b from t)",
+		"(a, b) > all (select c, d from t)",
+		"a < any (select b, c from t)",
+	} {
+		expr, err := parser.ParseExpr(query)
+		require.NoError(in, err)
+
+		_, changed := RewriteAnyAllComparison(expr.(*ComparisonExpr))
+		assert.False(in, changed, query)
+	}
+}
+
+func TestRewriteAnyAllComparisons(t *testing.T) {
+	parser := NewTestParser()
+	always := func(*ComparisonExpr) bool { return true }
+	stmt, err := parser.Parse("select a from t where a > all (select b from u) and not a = any (select c from v)")
+	require.NoError(t, err)
+
+	rewritten := RewriteAnyAllComparisons(stmt.(SelectStatement), always)
+	require.NotNil(t, rewritten)
+	assert.Equal(t, "select a from t where case when (select sum(b is null) from u) is null then 1 when a <= (select max(b) from u) then 0 when a is null or (select sum(b is null) from u) > 0 then null else 1 end and not a in (select c from v)", String(rewritten))
+	// the original statement is left untouched
+	assert.Equal(t, "select a from t where a > all (select b from u) and not a = any (select c from v)", String(stmt))
+
+	// columns keep the names they have in the original query
+	stmt, err = parser.Parse("select a < some (select b from u) from t")
+	require.NoError(t, err)
+	rewritten = RewriteAnyAllComparisons(stmt.(SelectStatement), always)
+	require.NotNil(t, rewritten)
+	assert.Equal(t, "select case when (select sum(b is null) from u) is null then 0 when a < (select max(b) from u) then 1 when a is null or (select sum(b is null) from u) > 0 then null else 0 end as `a < any (select b from u)` from t", String(rewritten))
+
+	for _, query := range []string{
+		"select a from t where a in (select b from u)",
+		"select a from t where (a, b) > all (select c, d from u)",
+	} {
+		stmt, err := parser.Parse(query)
+		require.NoError(t, err)
+		assert.Nil(t, RewriteAnyAllComparisons(stmt.(SelectStatement), always), query)
+	}
+
+	// comparisons are only rewritten into aggregations when canAggregate allows it,
+	// and it is asked about the comparisons of the original statement
+	stmt, err = parser.Parse("select a from t where a > all (select b from u) and a < any (select c from v) and a = any (select d from w)")
+	require.NoError(t, err)
+	var asked []*ComparisonExpr
+	rewritten = RewriteAnyAllComparisons(stmt.(SelectStatement), func(cmp *ComparisonExpr) bool {
+		asked = append(asked, cmp)
+		return cmp.Modifier == Any
+	})
+	require.NotNil(t, rewritten)
+	assert.Equal(t, "select a from t where a > all (select b from u) and case when (select sum(c is null) from v) is null then 0 when a < (select max(c) from v) then 1 when a is null or (select sum(c is null) from v) > 0 then null else 0 end and a in (select d from w)", String(rewritten))
+	original := stmt.(*Select).Where.Expr.(*AndExpr).Left.(*AndExpr)
+	require.Len(t, asked, 2)
+	assert.Same(t, original.Left, asked[0])
+	assert.Same(t, original.Right, asked[1])
+
+	stmt, err = parser.Parse("select a from t where a > all (select b from u)")
+	require.NoError(t, err)
+	assert.Nil(t, RewriteAnyAllComparisons(stmt.(SelectStatement), func(*ComparisonExpr) bool { return false }))
+}
@@ -563,8 +563,31 @@ type (
 	// DDLAction is an enum for DDL.Action
 	DDLAction int8
 
-	// Load represents a LOAD statement
+	// Load represents a LOAD statement.
+	// Only LOAD DATA LOCAL INFILE is parsed into its parts, other forms of LOAD are passed through as they are.
 	Load struct {
+		Local       bool
+		FileName    string
+		Action      InsertAction
+		Ignore      Ignore
+		Table       TableName
+		Partitions  Partitions
+		Charset     ColumnCharset
+		DataFormat  *LoadDataFormat
+		IgnoreLines int
+		Columns     Columns
+		SetExprs    UpdateExprs
+	}
+
+	// LoadDataFormat holds the FIELDS and LINES options of a LOAD DATA statement.
+	// The separators are stored unescaped.
+	LoadDataFormat struct {
+		FieldsTerminatedBy string
+		FieldsEnclosedBy   string
+		OptionallyEnclosed bool
+		FieldsEscapedBy    string
+		LinesStartingBy    string
+		LinesTerminatedBy  string
 	}
 
 	// PurgeBinaryLogs represents a PURGE BINARY LOGS statement
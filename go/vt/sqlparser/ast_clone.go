@@ -2061,6 +2061,12 @@ func CloneRefOfLoad(n *Load) *Load {
 		return nil
 	}
 	out := *n
+	out.Table = CloneTableName(n.Table)
+	out.Partitions = ClonePartitions(n.Partitions)
+	out.Charset = CloneColumnCharset(n.Charset)
+	out.DataFormat = CloneRefOfLoadDataFormat(n.DataFormat)
+	out.Columns = CloneColumns(n.Columns)
+	out.SetExprs = CloneUpdateExprs(n.SetExprs)
 	return &out
 }
 
@@ -4687,6 +4693,15 @@ func CloneRefOfColumnCharset(n *ColumnCharset) *ColumnCharset {
 	return &out
 }
 
+// CloneRefOfLoadDataFormat creates a deep clone of the input.
+func CloneRefOfLoadDataFormat(n *LoadDataFormat) *LoadDataFormat {
+	if n == nil {
+		return nil
+	}
+	out := *n
+	return &out
+}
+
 // CloneRefOfIndexColumn creates a deep clone of the input.
 func CloneRefOfIndexColumn(n *IndexColumn) *IndexColumn {
 	if n == nil {
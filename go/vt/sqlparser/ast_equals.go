@@ -3382,7 +3382,17 @@ func (cmp *Comparator) RefOfLoad(a, b *Load) bool {
 	if a == nil || b == nil {
 		return false
 	}
-	return true
+	return a.Local == b.Local &&
+		a.FileName == b.FileName &&
+		a.IgnoreLines == b.IgnoreLines &&
+		a.Action == b.Action &&
+		a.Ignore == b.Ignore &&
+		cmp.TableName(a.Table, b.Table) &&
+		cmp.Partitions(a.Partitions, b.Partitions) &&
+		cmp.ColumnCharset(a.Charset, b.Charset) &&
+		cmp.RefOfLoadDataFormat(a.DataFormat, b.DataFormat) &&
+		cmp.Columns(a.Columns, b.Columns) &&
+		cmp.UpdateExprs(a.SetExprs, b.SetExprs)
 }
 
 // RefOfLocateExpr does deep equals between the two objects.
@@ -7578,6 +7588,22 @@ func (cmp *Comparator) RefOfColumnCharset(a, b *ColumnCharset) bool {
 		a.Binary == b.Binary
 }
 
+// RefOfLoadDataFormat does deep equals between the two objects.
+func (cmp *Comparator) RefOfLoadDataFormat(a, b *LoadDataFormat) bool {
+	if a == b {
+		return true
+	}
+	if a == nil || b == nil {
+		return false
+	}
+	return a.FieldsTerminatedBy == b.FieldsTerminatedBy &&
+		a.FieldsEnclosedBy == b.FieldsEnclosedBy &&
+		a.OptionallyEnclosed == b.OptionallyEnclosed &&
+		a.FieldsEscapedBy == b.FieldsEscapedBy &&
+		a.LinesStartingBy == b.LinesStartingBy &&
+		a.LinesTerminatedBy == b.LinesTerminatedBy
+}
+
 // RefOfIndexColumn does deep equals between the two objects.
 func (cmp *Comparator) RefOfIndexColumn(a, b *IndexColumn) bool {
 	if a == b {
@@ -2058,7 +2058,43 @@ func (node IdentifierCS) Format(buf *TrackedBuffer) {
 
 // Format formats the node.
 func (node *Load) Format(buf *TrackedBuffer) {
-	buf.literal("AST node missing for Load type")
+	if !node.Local {
+		buf.literal("AST node missing for Load type")
+		return
+	}
+	buf.astPrintf(node, "load data local infile %#s", encodeSQLString(node.FileName))
+	if node.Action == ReplaceAct {
+		buf.literal(" replace")
+	} else if node.Ignore {
+		buf.literal(" ignore")
+	}
+	buf.astPrintf(node, " into table %v%v", node.Table, node.Partitions)
+	if node.Charset.Name != "" {
+		buf.astPrintf(node, " character set %#s", node.Charset.Name)
+	}
+	if format := node.DataFormat; format != nil {
+		buf.astPrintf(node, " fields terminated by %#s", encodeSQLString(format.FieldsTerminatedBy))
+		if format.FieldsEnclosedBy != "" {
+			if format.OptionallyEnclosed {
+				buf.literal(" optionally")
+			}
+			buf.astPrintf(node, " enclosed by %#s", encodeSQLString(format.FieldsEnclosedBy))
+		}
+		buf.astPrintf(node, " escaped by %#s lines", encodeSQLString(format.FieldsEscapedBy))
+		if format.LinesStartingBy != "" {
+			buf.astPrintf(node, " starting by %#s", encodeSQLString(format.LinesStartingBy))
+		}
+		buf.astPrintf(node, " terminated by %#s", encodeSQLString(format.LinesTerminatedBy))
+	}
+	if node.IgnoreLines > 0 {
+		buf.astPrintf(node, " ignore %d lines", node.IgnoreLines)
+	}
+	if node.Columns != nil {
+		buf.astPrintf(node, " %v", node.Columns)
+	}
+	if len(node.SetExprs) > 0 {
+		buf.astPrintf(node, " set %v", node.SetExprs)
+	}
 }
 
 // Format formats the node.
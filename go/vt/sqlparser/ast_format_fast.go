@@ -2728,7 +2728,57 @@ func (node IdentifierCS) FormatFast(buf *TrackedBuffer) {
 
 // FormatFast formats the node.
 func (node *Load) FormatFast(buf *TrackedBuffer) {
-	buf.WriteString("AST node missing for Load type")
+	if !node.Local {
+		buf.WriteString("AST node missing for Load type")
+		return
+	}
+	buf.WriteString("load data local infile ")
+	buf.WriteString(encodeSQLString(node.FileName))
+	if node.Action == ReplaceAct {
+		buf.WriteString(" replace")
+	} else if node.Ignore {
+		buf.WriteString(" ignore")
+	}
+	buf.WriteString(" into table ")
+	node.Table.FormatFast(buf)
+	node.Partitions.FormatFast(buf)
+	if node.Charset.Name != "" {
+		buf.WriteString(" character set ")
+		buf.WriteString(node.Charset.Name)
+	}
+	if format := node.DataFormat; format != nil {
+		buf.WriteString(" fields terminated by ")
+		buf.WriteString(encodeSQLString(format.FieldsTerminatedBy))
+		if format.FieldsEnclosedBy != "" {
+			if format.OptionallyEnclosed {
+				buf.WriteString(" optionally")
+			}
+			buf.WriteString(" enclosed by ")
+			buf.WriteString(encodeSQLString(format.FieldsEnclosedBy))
+		}
+		buf.WriteString(" escaped by ")
+		buf.WriteString(encodeSQLString(format.FieldsEscapedBy))
+		buf.WriteString(" lines")
+		if format.LinesStartingBy != "" {
+			buf.WriteString(" starting by ")
+			buf.WriteString(encodeSQLString(format.LinesStartingBy))
+		}
+		buf.WriteString(" terminated by ")
+		buf.WriteString(encodeSQLString(format.LinesTerminatedBy))
+	}
+	if node.IgnoreLines > 0 {
+		buf.WriteString(" ignore ")
+		buf.WriteString(fmt.Sprintf("%d", node.IgnoreLines))
+		buf.WriteString(" lines")
+	}
+	if node.Columns != nil {
+		buf.WriteByte(' ')
+		node.Columns.FormatFast(buf)
+	}
+	if len(node.SetExprs) > 0 {
+		buf.WriteString(" set ")
+		node.SetExprs.FormatFast(buf)
+	}
 }
 
 // FormatFast formats the node.
@@ -1895,6 +1895,10 @@ func (ty VExplainType) ToString() string {
 		return QueriesStr
 	case AllVExplainType:
 		return AllVExplainStr
+	case TraceVExplainType:
+		return TraceStr
+	case KeysVExplainType:
+		return KeysStr
 	default:
 		return "Unknown VExplainType"
 	}
@@ -2138,6 +2142,15 @@ func encodeSQLString(val string) string {
 	return sqltypes.EncodeStringSQL(val)
 }
 
+// defaultLoadDataFormat returns the FIELDS and LINES options LOAD DATA uses when none are given
+func defaultLoadDataFormat() *LoadDataFormat {
+	return &LoadDataFormat{
+		FieldsTerminatedBy: "\t",
+		FieldsEscapedBy:    "\\",
+		LinesTerminatedBy:  "\n",
+	}
+}
+
 // ToString prints the list of table expressions as a string
 // To be used as an alternate for String for []TableExpr
 func ToString(exprs []TableExpr) string {
@@ -2184,6 +2197,82 @@ func ContainsAggregation(e SQLNode) bool {
 	return hasAggregates
 }
 
+// GetOverClause returns the OVER clause of a window function call.
+// It returns nil if the node is not a window function, or if it is an
+// aggregation function being used without an OVER clause.
+func GetOverClause(node SQLNode) *OverClause {
+	switch node := node.(type) {
+	case *ArgumentLessWindowExpr:
+		return node.OverClause
+	case *FirstOrLastValueExpr:
+		return node.OverClause
+	case *NtileExpr:
+		return node.OverClause
+	case *NTHValueExpr:
+		return node.OverClause
+	case *LagLeadExpr:
+		return node.OverClause
+	case *Count:
+		return node.OverClause
+	case *CountStar:
+		return node.OverClause
+	case *Avg:
+		return node.OverClause
+	case *Max:
+		return node.OverClause
+	case *Min:
+		return node.OverClause
+	case *Sum:
+		return node.OverClause
+	case *BitAnd:
+		return node.OverClause
+	case *BitOr:
+		return node.OverClause
+	case *BitXor:
+		return node.OverClause
+	case *Std:
+		return node.OverClause
+	case *StdDev:
+		return node.OverClause
+	case *StdPop:
+		return node.OverClause
+	case *StdSamp:
+		return node.OverClause
+	case *VarPop:
+		return node.OverClause
+	case *VarSamp:
+		return node.OverClause
+	case *Variance:
+		return node.OverClause
+	}
+	return nil
+}
+
+// IsWindowFunc returns true if the node is a function call with an OVER clause
+func IsWindowFunc(node SQLNode) bool {
+	return GetOverClause(node) != nil
+}
+
+// ContainsWindowFunc returns true if the expression contains a window function.
+// Subqueries are not inspected, since their window functions are evaluated separately.
+func ContainsWindowFunc(e SQLNode) bool {
+	hasWindow := false
+	_ = Walk(func(node SQLNode) (kontinue bool, err error) {
+		switch node.(type) {
+		case *Offset:
+			return false, nil
+		case *Subquery:
+			return false, nil
+		}
+		if IsWindowFunc(node) {
+			hasWindow = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, e)
+	return hasWindow
+}
+
 // GetFirstSelect gets the first select statement
 func GetFirstSelect(selStmt SelectStatement) *Select {
 	if selStmt == nil {
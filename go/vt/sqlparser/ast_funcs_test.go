@@ -172,3 +172,25 @@ func TestColumns_Indexes(t *testing.T) {
 		})
 	}
 }
+
+func TestContainsWindowFunc(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "row_number() over (partition by a order by b)", want: true},
+		{expr: "sum(a) over (partition by b)", want: true},
+		{expr: "1 + lag(a, 2) over (order by b)", want: true},
+		{expr: "sum(a)", want: false},
+		{expr: "count(*)", want: false},
+		{expr: "a + (select rank() over (order by b) from t)", want: false},
+	}
+	parser := NewTestParser()
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := parser.ParseExpr(tt.expr)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, ContainsWindowFunc(expr))
+		})
+	}
+}
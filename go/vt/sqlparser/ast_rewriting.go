@@ -281,6 +281,7 @@ func (er *astRewriter) rewriteNotExpr(cursor *Cursor, node *NotExpr) {
 		canChange, inverse := inverseOp(inner.Operator)
 		if canChange {
 			inner.Operator = inverse
+			inner.Modifier = inner.Modifier.Inverse()
 			cursor.Replace(inner)
 		}
 	case *NotExpr:
@@ -256,6 +256,9 @@ func TestRewrites(in *testing.T) {
 	}, {
 		in:       "SELECT * FROM tbl WHERE not id not in (1,2,3)",
 		expected: "SELECT * FROM tbl WHERE id in (1,2,3)",
+	}, {
+		in:       "SELECT * FROM tbl WHERE not id > all (select col from t)",
+		expected: "SELECT * FROM tbl WHERE id <= any (select col from t)",
 	}, {
 		in:       "SELECT * FROM tbl WHERE not id like '%foobar'",
 		expected: "SELECT * FROM tbl WHERE id not like '%foobar'",
@@ -35,6 +35,9 @@ const (
 	DirectiveMultiShardAutocommit = "MULTI_SHARD_AUTOCOMMIT"
 	// DirectiveSkipQueryPlanCache skips query plan cache when set.
 	DirectiveSkipQueryPlanCache = "SKIP_QUERY_PLAN_CACHE"
+	// DirectiveResultCache caches the result of a select in vtgate when set, or skips the result cache
+	// of the tables that vtgate caches by default when set to false.
+	DirectiveResultCache = "RESULT_CACHE"
 	// DirectiveQueryTimeout sets a query timeout in vtgate. Only supported for SELECTS.
 	DirectiveQueryTimeout = "QUERY_TIMEOUT_MS"
 	// DirectiveScatterErrorsAsWarnings enables partial success scatter select queries
@@ -629,6 +632,21 @@ func Consolidator(stmt Statement) querypb.ExecuteOptions_Consolidator {
 	return querypb.ExecuteOptions_CONSOLIDATOR_UNSPECIFIED
 }
 
+// ResultCache returns whether the result of a select statement should be cached in vtgate, as set by
+// the RESULT_CACHE directive, and whether the directive is set at all.
+func ResultCache(stmt Statement) (cache bool, isSet bool) {
+	sel, ok := stmt.(SelectStatement)
+	if !ok {
+		return false, false
+	}
+	val, isSet := sel.GetParsedComments().Directives().GetString(DirectiveResultCache, "")
+	if !isSet {
+		return false, false
+	}
+	cache, _ = strconv.ParseBool(val)
+	return cache, true
+}
+
 // GetWorkloadNameFromStatement gets the workload name from the provided Statement, using workloadLabel as the name of
 // the query directive that specifies it.
 func GetWorkloadNameFromStatement(statement Statement) string {
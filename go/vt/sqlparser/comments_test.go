@@ -480,6 +480,33 @@ func TestConsolidator(t *testing.T) {
 	}
 }
 
+func TestResultCache(t *testing.T) {
+	testCases := []struct {
+		query  string
+		cache  bool
+		hasSet bool
+	}{
+		{"select * from users", false, false},
+		{"select /*vt+ RESULT_CACHE */ * from users", true, true},
+		{"select /*vt+ RESULT_CACHE=true */ * from users", true, true},
+		{"select /*vt+ RESULT_CACHE=false */ * from users", false, true},
+		{"select /*vt+ RESULT_CACHE */ * from users union select * from admins", true, true},
+		{"select /*vt+ IGNORE_MAX_MEMORY_ROWS=1 */ * from users", false, false},
+		{"update /*vt+ RESULT_CACHE */ users set name=1", false, false},
+	}
+
+	parser := NewTestParser()
+	for _, test := range testCases {
+		t.Run(test.query, func(t *testing.T) {
+			stmt, err := parser.Parse(test.query)
+			require.NoError(t, err)
+			cache, isSet := ResultCache(stmt)
+			assert.Equal(t, test.cache, cache)
+			assert.Equal(t, test.hasSet, isSet)
+		})
+	}
+}
+
 func TestGetPriorityFromStatement(t *testing.T) {
 	testCases := []struct {
 		query            string
@@ -272,6 +272,8 @@ const (
 	QueriesStr     = "queries"
 	AllVExplainStr = "all"
 	PlanStr        = "plan"
+	TraceStr       = "trace"
+	KeysStr        = "keys"
 
 	// Lock Types
 	ReadStr             = "read"
@@ -715,6 +717,18 @@ func (op ComparisonExprOperator) Inverse() ComparisonExprOperator {
 	panic("unreachable")
 }
 
+// Inverse returns the modifier to use when the comparison operator is inverted:
+// NOT (a > ALL (...)) is the same as a <= ANY (...)
+func (m ComparisonModifier) Inverse() ComparisonModifier {
+	switch m {
+	case Any:
+		return All
+	case All:
+		return Any
+	}
+	return m
+}
+
 func (op ComparisonExprOperator) IsCommutative() bool {
 	switch op {
 	case EqualOp, NotEqualOp, NullSafeEqualOp:
@@ -724,6 +738,26 @@ func (op ComparisonExprOperator) IsCommutative() bool {
 	}
 }
 
+// SwitchSides returns the operator to use when the operands of the comparison
+// are swapped: a < b is the same as b > a. The boolean is false for operators
+// such as LIKE and IN, where the operands cannot be swapped.
+func (op ComparisonExprOperator) SwitchSides() (ComparisonExprOperator, bool) {
+	switch op {
+	case EqualOp, NotEqualOp, NullSafeEqualOp:
+		return op, true
+	case LessThanOp:
+		return GreaterThanOp, true
+	case GreaterThanOp:
+		return LessThanOp, true
+	case LessEqualOp:
+		return GreaterEqualOp, true
+	case GreaterEqualOp:
+		return LessEqualOp, true
+	default:
+		return op, false
+	}
+}
+
 // Constant for Enum Type - IsExprOperator
 const (
 	IsNullOp IsExprOperator = iota
@@ -839,6 +873,8 @@ const (
 	QueriesVExplainType VExplainType = iota
 	PlanVExplainType
 	AllVExplainType
+	TraceVExplainType
+	KeysVExplainType
 )
 
 // Constant for Enum Type - SelectIntoType
@@ -309,7 +309,7 @@ var keywords = []keyword{
 	{"gtid_subtract", GTID_SUBTRACT},
 	{"grant", UNUSED},
 	{"group", GROUP},
-	{"grouping", UNUSED},
+	{"grouping", GROUPING},
 	{"groups", UNUSED},
 	{"group_concat", GROUP_CONCAT},
 	{"hash", HASH},
@@ -327,7 +327,7 @@ var keywords = []keyword{
 	{"in", IN},
 	{"index", INDEX},
 	{"indexes", INDEXES},
-	{"infile", UNUSED},
+	{"infile", INFILE},
 	{"inout", UNUSED},
 	{"inner", INNER},
 	{"inplace", INPLACE},
@@ -708,6 +708,7 @@ var keywords = []keyword{
 	{"tinyint", TINYINT},
 	{"tinytext", TINYTEXT},
 	{"to", TO},
+	{"trace", TRACE},
 	{"trailing", TRAILING},
 	{"transaction", TRANSACTION},
 	{"tree", TREE},
@@ -1153,6 +1153,9 @@ var (
 		input: "select /* order by asc */ 1 from t order by a asc",
 	}, {
 		input: "select a, b, c, count(*), sum(foo) from t group by a, b, c with rollup",
+	}, {
+		input:  "select a, b, GROUPING(a, b), sum(foo) from t group by a, b with rollup having grouping(b) = 1",
+		output: "select a, b, grouping(a, b), sum(foo) from t group by a, b with rollup having grouping(b) = 1",
 	}, {
 		input: "select /* order by desc */ 1 from t order by a desc",
 	}, {
@@ -2543,6 +2546,10 @@ var (
 		input: "vexplain all select * from t",
 	}, {
 		input: "vexplain plan select * from t",
+	}, {
+		input: "vexplain trace select * from t",
+	}, {
+		input: "vexplain keys select * from t where id = 1",
 	}, {
 		input:  "vexplain select * from t",
 		output: "vexplain plan select * from t",
@@ -4696,6 +4703,7 @@ func TestLoadData(t *testing.T) {
 		"load data from s3 manifest 'x.txt'",
 		"load data from s3 file 'x.txt'",
 		"load data infile 'x.txt' into table 'c'",
+		"load data low_priority infile 'x.txt' into table c",
 		"load data from s3 'x.txt' into table x"}
 
 	parser := NewTestParser()
@@ -4703,6 +4711,29 @@ func TestLoadData(t *testing.T) {
 		_, err := parser.Parse(tcase)
 		require.NoError(t, err)
 	}
+
+	localInfile := []struct {
+		input, output string
+	}{{
+		input:  "load data local infile 'x.csv' into table t",
+		output: "load data local infile 'x.csv' into table t fields terminated by '\\t' escaped by '\\\\' lines terminated by '\\n'",
+	}, {
+		input:  "LOAD DATA LOCAL INFILE '/tmp/x.csv' REPLACE INTO TABLE ks.t PARTITION (p0) CHARACTER SET utf8mb4 FIELDS TERMINATED BY ',' OPTIONALLY ENCLOSED BY '\"' ESCAPED BY '' LINES STARTING BY 'x' TERMINATED BY '\\r\\n' IGNORE 1 LINES (a, b, c) SET d = 1",
+		output: "load data local infile '/tmp/x.csv' replace into table ks.t partition (p0) character set utf8mb4 fields terminated by ',' optionally enclosed by '\\\"' escaped by '' lines starting by 'x' terminated by '\\r\\n' ignore 1 lines (a, b, c) set d = 1",
+	}, {
+		input:  "load data local infile 'x.csv' ignore into table t columns enclosed by '\\'' ignore 2 rows",
+		output: "load data local infile 'x.csv' ignore into table t fields terminated by '\\t' enclosed by '\\'' escaped by '\\\\' lines terminated by '\\n' ignore 2 lines",
+	}}
+	for _, tcase := range localInfile {
+		t.Run(tcase.input, func(t *testing.T) {
+			tree, err := parser.Parse(tcase.input)
+			require.NoError(t, err)
+			load, ok := tree.(*Load)
+			require.True(t, ok)
+			assert.True(t, load.Local)
+			assert.Equal(t, tcase.output, String(tree))
+		})
+	}
 }
 
 func TestCreateTable(t *testing.T) {
@@ -70,6 +70,8 @@ func markBindVariable(yylex yyLexer, bvar string) {
   alterOption      AlterOption
 
   ins           *Insert
+  load          *Load
+  loadDataFormat *LoadDataFormat
   colName       *ColName
   colNames      []*ColName
   indexHint    *IndexHint
@@ -249,7 +251,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %token <str> DISTINCT AS EXISTS ASC DESC INTO DUPLICATE DEFAULT SET LOCK UNLOCK KEYS DO CALL
 %left <str> ALL ANY SOME
 %token <str> DISTINCTROW PARSER GENERATED ALWAYS
-%token <str> OUTFILE S3 DATA LOAD LINES TERMINATED ESCAPED ENCLOSED
+%token <str> OUTFILE S3 DATA LOAD LINES TERMINATED ESCAPED ENCLOSED INFILE
 %token <str> DUMPFILE CSV HEADER MANIFEST OVERWRITE STARTING OPTIONALLY
 %token <str> VALUES LAST_INSERT_ID
 %token <str> NEXT VALUE SHARE MODE
@@ -399,7 +401,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %token <str> GTID_SUBSET GTID_SUBTRACT WAIT_FOR_EXECUTED_GTID_SET WAIT_UNTIL_SQL_THREAD_AFTER_GTIDS
 
 // Explain tokens
-%token <str> FORMAT TREE VITESS TRADITIONAL VTEXPLAIN VEXPLAIN PLAN
+%token <str> FORMAT TREE VITESS TRADITIONAL VTEXPLAIN VEXPLAIN PLAN TRACE
 
 // Lock type tokens
 %token <str> LOCAL LOW_PRIORITY
@@ -502,6 +504,11 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %type <boolVal> boolean_value
 %type <comparisonExprOperator> compare any_all_compare
 %type <ins> insert_data
+%type <load> load_duplicate_opt
+%type <loadDataFormat> load_fields_opt load_field_list load_lines_opt load_line_list
+%type <integer> load_ignore_lines_opt
+%type <columns> load_column_list_opt
+%type <updateExprs> load_set_opt
 %type <expr> num_val
 %type <expr> function_call_keyword function_call_nonkeyword function_call_generic function_call_conflict
 %type <isExprOperator> is_suffix
@@ -551,7 +558,7 @@ func markBindVariable(yylex yyLexer, bvar string) {
 %type <identifierCS> table_id reserved_table_id table_alias as_opt_id table_id_opt from_database_opt use_table_name
 %type <rowAlias> row_alias_opt
 %type <empty> as_opt work_opt savepoint_opt
-%type <empty> skip_to_end ddl_skip_to_end
+%type <empty> skip_to_end ddl_skip_to_end load_passthrough_start
 %type <str> charset
 %type <scope> set_session_or_global
 %type <convertType> convert_type returning_type_opt convert_type_weight_string
@@ -712,6 +719,141 @@ load_statement:
   {
     $$ = &Load{}
   }
+| LOAD DATA load_passthrough_start skip_to_end
+  {
+    $$ = &Load{}
+  }
+| LOAD DATA LOCAL INFILE STRING load_duplicate_opt INTO TABLE table_name opt_partition_clause charset_opt load_fields_opt load_lines_opt load_ignore_lines_opt load_column_list_opt load_set_opt
+  {
+    ld := $6
+    ld.Local = true
+    ld.FileName = $5
+    ld.Table = $9
+    ld.Partitions = $10
+    ld.Charset = $11
+    ld.DataFormat = $12
+    ld.DataFormat.LinesStartingBy = $13.LinesStartingBy
+    ld.DataFormat.LinesTerminatedBy = $13.LinesTerminatedBy
+    ld.IgnoreLines = $14
+    ld.Columns = $15
+    ld.SetExprs = $16
+    $$ = ld
+  }
+
+// load_passthrough_start lists the tokens that can start a LOAD DATA statement which is not parsed any further.
+// LOAD DATA LOCAL INFILE is parsed, so the parser has to look at the token that follows DATA.
+load_passthrough_start:
+  FROM
+  { $$ = struct{}{} }
+| INFILE
+  { $$ = struct{}{} }
+| LOW_PRIORITY
+  { $$ = struct{}{} }
+| ID
+  { $$ = struct{}{} }
+
+load_duplicate_opt:
+  {
+    $$ = &Load{}
+  }
+| REPLACE
+  {
+    $$ = &Load{Action: ReplaceAct}
+  }
+| IGNORE
+  {
+    $$ = &Load{Ignore: true}
+  }
+
+load_fields_opt:
+  {
+    $$ = defaultLoadDataFormat()
+  }
+| columns_or_fields load_field_list
+  {
+    $$ = $2
+  }
+
+load_field_list:
+  {
+    $$ = defaultLoadDataFormat()
+  }
+| load_field_list TERMINATED BY STRING
+  {
+    $1.FieldsTerminatedBy = $4
+    $$ = $1
+  }
+| load_field_list ENCLOSED BY STRING
+  {
+    $1.FieldsEnclosedBy = $4
+    $$ = $1
+  }
+| load_field_list OPTIONALLY ENCLOSED BY STRING
+  {
+    $1.FieldsEnclosedBy = $5
+    $1.OptionallyEnclosed = true
+    $$ = $1
+  }
+| load_field_list ESCAPED BY STRING
+  {
+    $1.FieldsEscapedBy = $4
+    $$ = $1
+  }
+
+load_lines_opt:
+  {
+    $$ = defaultLoadDataFormat()
+  }
+| LINES load_line_list
+  {
+    $$ = $2
+  }
+
+load_line_list:
+  {
+    $$ = defaultLoadDataFormat()
+  }
+| load_line_list STARTING BY STRING
+  {
+    $1.LinesStartingBy = $4
+    $$ = $1
+  }
+| load_line_list TERMINATED BY STRING
+  {
+    $1.LinesTerminatedBy = $4
+    $$ = $1
+  }
+
+load_ignore_lines_opt:
+  {
+    $$ = 0
+  }
+| IGNORE INTEGRAL LINES
+  {
+    $$ = convertStringToInt($2)
+  }
+| IGNORE INTEGRAL ROWS
+  {
+    $$ = convertStringToInt($2)
+  }
+
+load_column_list_opt:
+  {
+    $$ = nil
+  }
+| openb ins_column_list closeb
+  {
+    $$ = $2
+  }
+
+load_set_opt:
+  {
+    $$ = nil
+  }
+| SET update_list
+  {
+    $$ = $2
+  }
 
 with_clause:
   WITH with_list
@@ -4463,6 +4605,14 @@ vexplain_type_opt:
   {
     $$ = QueriesVExplainType
   }
+| TRACE
+  {
+    $$ = TraceVExplainType
+  }
+| KEYS
+  {
+    $$ = KeysVExplainType
+  }
 
 explain_synonyms:
   EXPLAIN
@@ -5910,6 +6060,10 @@ function_call_keyword:
   {
     $$ = &FuncExpr{Name: NewIdentifierCI("right"), Exprs: $3}
   }
+| GROUPING openb expression_list closeb
+  {
+    $$ = &FuncExpr{Name: NewIdentifierCI("grouping"), Exprs: $3}
+  }
 | SUBSTRING openb expression ',' expression ',' expression closeb
   {
     $$ = &SubstrExpr{Name: $3, From: $5, To: $7}
@@ -8594,6 +8748,7 @@ non_reserved_keyword:
 | TINYBLOB
 | TINYINT
 | TINYTEXT
+| TRACE
 | TRADITIONAL
 | TRANSACTION
 | TREE
@@ -57,7 +57,8 @@ var (
 	off     = "0"
 	utf8mb4 = "'utf8mb4'"
 
-	ForeignKeyChecks = "foreign_key_checks"
+	ForeignKeyChecks     = "foreign_key_checks"
+	CTEMaxRecursionDepth = "cte_max_recursion_depth"
 
 	Autocommit                  = SystemVariable{Name: "autocommit", IsBoolean: true, Default: on}
 	Charset                     = SystemVariable{Name: "charset", Default: utf8mb4, IdentifierAsString: true}
@@ -184,6 +185,7 @@ var (
 		{Name: "transaction_write_set_extraction"},
 	}
 	UseReservedConn = []SystemVariable{
+		{Name: CTEMaxRecursionDepth, SupportSetVar: true},
 		{Name: "default_week_format"},
 		{Name: "end_markers_in_json", IsBoolean: true, SupportSetVar: true},
 		{Name: "eq_range_index_dive_limit", SupportSetVar: true},
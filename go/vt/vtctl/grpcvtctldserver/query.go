@@ -22,12 +22,15 @@ import (
 
 	"vitess.io/vitess/go/mysql/collations"
 	"vitess.io/vitess/go/protoutil"
+	"vitess.io/vitess/go/sqlescape"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
+	"vitess.io/vitess/go/vt/vterrors"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	"vitess.io/vitess/go/vt/proto/vttime"
 )
@@ -39,8 +42,31 @@ const (
 	*
 	from _vt.schema_migrations where %s %s %s`
 	AllMigrationsIndicator = "all"
+
+	selectUnresolvedTransactionsSql = `select t.dtid, t.state, t.time_created, p.keyspace, p.shard
+	from %s.dt_state t
+	join %s.dt_participant p on t.dtid = p.dtid
+	where t.time_created < %a
+	order by t.dtid, p.id`
+	selectTransactionSql = `select t.dtid, t.state, t.time_created, p.keyspace, p.shard
+	from %s.dt_state t
+	join %s.dt_participant p on t.dtid = p.dtid
+	where t.dtid = %a
+	order by p.id`
+	selectPreparedTransactionSql = `select t.state, t.time_created, s.statement
+	from %s.redo_state t
+	join %s.redo_statement s on t.dtid = s.dtid
+	where t.dtid = %a
+	order by s.id`
 )
 
+// redoStates are the states of a prepared transaction in the redo_state table,
+// see go/vt/vttablet/tabletserver/twopc.go.
+var redoStates = map[int64]string{
+	0: "FAILED",
+	1: "PREPARED",
+}
+
 func alterSchemaMigrationQuery(command, uuid string) (string, error) {
 	if strings.ToLower(uuid) == AllMigrationsIndicator {
 		return fmt.Sprintf(alterAllSchemaMigrationSql, command), nil
@@ -52,6 +78,86 @@ func selectSchemaMigrationsQuery(condition, order, skipLimit string) string {
 	return fmt.Sprintf(selectSchemaMigrationsSql, condition, order, skipLimit)
 }
 
+func selectUnresolvedTransactionsQuery(sidecarDB string, abandonTime time.Time) (string, error) {
+	pq := sqlparser.BuildParsedQuery(selectUnresolvedTransactionsSql, sqlescape.EscapeID(sidecarDB), sqlescape.EscapeID(sidecarDB), ":time_created")
+	return pq.GenerateQuery(map[string]*querypb.BindVariable{
+		"time_created": sqltypes.Int64BindVariable(abandonTime.UnixNano()),
+	}, nil)
+}
+
+func selectTransactionQuery(sidecarDB, dtid string) (string, error) {
+	pq := sqlparser.BuildParsedQuery(selectTransactionSql, sqlescape.EscapeID(sidecarDB), sqlescape.EscapeID(sidecarDB), ":dtid")
+	return pq.GenerateQuery(map[string]*querypb.BindVariable{
+		"dtid": sqltypes.StringBindVariable(dtid),
+	}, nil)
+}
+
+func selectPreparedTransactionQuery(sidecarDB, dtid string) (string, error) {
+	pq := sqlparser.BuildParsedQuery(selectPreparedTransactionSql, sqlescape.EscapeID(sidecarDB), sqlescape.EscapeID(sidecarDB), ":dtid")
+	return pq.GenerateQuery(map[string]*querypb.BindVariable{
+		"dtid": sqltypes.StringBindVariable(dtid),
+	}, nil)
+}
+
+// rowsToTransactions converts the rows of the dt_state table, joined with the
+// dt_participant table and ordered by dtid, into TransactionMetadata protobufs.
+func rowsToTransactions(qr *sqltypes.Result) ([]*querypb.TransactionMetadata, error) {
+	var (
+		transactions []*querypb.TransactionMetadata
+		cur          *querypb.TransactionMetadata
+	)
+	for _, row := range qr.Rows {
+		dtid := row[0].ToString()
+		if cur == nil || cur.Dtid != dtid {
+			state, err := row[1].ToCastInt64()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "error parsing state for dtid %s", dtid)
+			}
+			timeCreated, err := row[2].ToCastInt64()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "error parsing time_created for dtid %s", dtid)
+			}
+			cur = &querypb.TransactionMetadata{
+				Dtid:        dtid,
+				State:       querypb.TransactionState(state),
+				TimeCreated: timeCreated,
+			}
+			transactions = append(transactions, cur)
+		}
+		cur.Participants = append(cur.Participants, &querypb.Target{
+			Keyspace:   row[3].ToString(),
+			Shard:      row[4].ToString(),
+			TabletType: topodatapb.TabletType_PRIMARY,
+		})
+	}
+	return transactions, nil
+}
+
+// rowsToShardTransactionState converts the rows of the redo_state table of a
+// participant, joined with the redo_statement table, into a ShardTransactionState
+// protobuf.
+func rowsToShardTransactionState(target *querypb.Target, qr *sqltypes.Result) (*vtctldatapb.ShardTransactionState, error) {
+	sts := &vtctldatapb.ShardTransactionState{
+		Keyspace: target.Keyspace,
+		Shard:    target.Shard,
+	}
+	for i, row := range qr.Rows {
+		if i == 0 {
+			state, err := row[0].ToCastInt64()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "error parsing state on shard %s/%s", target.Keyspace, target.Shard)
+			}
+			sts.State = redoStates[state]
+			sts.TimeCreated, err = row[1].ToCastInt64()
+			if err != nil {
+				return nil, vterrors.Wrapf(err, "error parsing time_created on shard %s/%s", target.Keyspace, target.Shard)
+			}
+		}
+		sts.Statements = append(sts.Statements, row[2].ToString())
+	}
+	return sts, nil
+}
+
 // rowToSchemaMigration converts a single row into a SchemaMigration protobuf.
 func rowToSchemaMigration(row sqltypes.RowNamedValues) (sm *vtctldatapb.SchemaMigration, err error) {
 	sm = new(vtctldatapb.SchemaMigration)
@@ -28,6 +28,8 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vttimepb "vitess.io/vitess/go/vt/proto/vttime"
 )
@@ -234,3 +236,80 @@ func TestAlterSchemaMigrationQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestSelectTransactionQueries(t *testing.T) {
+	t.Parallel()
+
+	query, err := selectUnresolvedTransactionsQuery("_vt", time.Unix(0, 1000))
+	require.NoError(t, err)
+	assert.Equal(t, "select t.dtid, t.state, t.time_created, p.keyspace, p.shard\n\tfrom `_vt`.dt_state t\n\tjoin `_vt`.dt_participant p on t.dtid = p.dtid\n\twhere t.time_created < 1000\n\torder by t.dtid, p.id", query)
+
+	query, err = selectTransactionQuery("my-sidecar", "ks:-80:1234")
+	require.NoError(t, err)
+	assert.Equal(t, "select t.dtid, t.state, t.time_created, p.keyspace, p.shard\n\tfrom `my-sidecar`.dt_state t\n\tjoin `my-sidecar`.dt_participant p on t.dtid = p.dtid\n\twhere t.dtid = 'ks:-80:1234'\n\torder by p.id", query)
+
+	query, err = selectPreparedTransactionQuery("_vt", "ks:-80:1234")
+	require.NoError(t, err)
+	assert.Equal(t, "select t.state, t.time_created, s.statement\n\tfrom `_vt`.redo_state t\n\tjoin `_vt`.redo_statement s on t.dtid = s.dtid\n\twhere t.dtid = 'ks:-80:1234'\n\torder by s.id", query)
+}
+
+func TestRowsToTransactions(t *testing.T) {
+	t.Parallel()
+
+	qr := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("dtid|state|time_created|keyspace|shard", "varbinary|int64|int64|varchar|varchar"),
+		"ks:-80:1|1|100|ks|80-",
+		"ks:-80:1|1|100|ks2|-",
+		"ks:-80:2|2|200|ks|80-",
+	)
+	transactions, err := rowsToTransactions(qr)
+	require.NoError(t, err)
+	utils.MustMatch(t, []*querypb.TransactionMetadata{
+		{
+			Dtid:        "ks:-80:1",
+			State:       querypb.TransactionState_PREPARE,
+			TimeCreated: 100,
+			Participants: []*querypb.Target{
+				{Keyspace: "ks", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+				{Keyspace: "ks2", Shard: "-", TabletType: topodatapb.TabletType_PRIMARY},
+			},
+		},
+		{
+			Dtid:        "ks:-80:2",
+			State:       querypb.TransactionState_COMMIT,
+			TimeCreated: 200,
+			Participants: []*querypb.Target{
+				{Keyspace: "ks", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+			},
+		},
+	}, transactions)
+
+	transactions, err = rowsToTransactions(&sqltypes.Result{})
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestRowsToShardTransactionState(t *testing.T) {
+	t.Parallel()
+
+	target := &querypb.Target{Keyspace: "ks", Shard: "80-"}
+	qr := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("state|time_created|statement", "int64|int64|varbinary"),
+		"1|100|insert into t(id) values (1)",
+		"1|100|update t set c = 2 where id = 1",
+	)
+	sts, err := rowsToShardTransactionState(target, qr)
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ShardTransactionState{
+		Keyspace:    "ks",
+		Shard:       "80-",
+		State:       "PREPARED",
+		TimeCreated: 100,
+		Statements:  []string{"insert into t(id) values (1)", "update t set c = 2 where id = 1"},
+	}, sts)
+
+	// A participant that has not prepared, or already resolved, the transaction.
+	sts, err = rowsToShardTransactionState(target, &sqltypes.Result{})
+	require.NoError(t, err)
+	utils.MustMatch(t, &vtctldatapb.ShardTransactionState{Keyspace: "ks", Shard: "80-"}, sts)
+}
@@ -34,6 +34,7 @@ import (
 	"golang.org/x/sync/semaphore"
 	"google.golang.org/grpc"
 
+	"vitess.io/vitess/go/constants/sidecar"
 	"vitess.io/vitess/go/event"
 	"vitess.io/vitess/go/netutil"
 	"vitess.io/vitess/go/protoutil"
@@ -43,6 +44,8 @@ import (
 	"vitess.io/vitess/go/trace"
 	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
+	"vitess.io/vitess/go/vt/dtids"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/key"
 	"vitess.io/vitess/go/vt/log"
@@ -64,6 +67,9 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 
 	logutilpb "vitess.io/vitess/go/vt/proto/logutil"
@@ -681,6 +687,33 @@ func (s *VtctldServer) ChangeTabletType(ctx context.Context, req *vtctldatapb.Ch
 	}, nil
 }
 
+// CheckThrottler is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) CheckThrottler(ctx context.Context, req *vtctldatapb.CheckThrottlerRequest) (resp *vtctldatapb.CheckThrottlerResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.CheckThrottler")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("tablet_alias", topoproto.TabletAliasString(req.TabletAlias))
+	span.Annotate("app_name", req.AppName)
+
+	ti, err := s.ts.GetTablet(ctx, req.TabletAlias)
+	if err != nil {
+		err = vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "Failed to get tablet %v: %v", req.TabletAlias, err)
+		return nil, err
+	}
+
+	check, err := s.tmc.CheckThrottler(ctx, ti.Tablet, &tabletmanagerdatapb.CheckThrottlerRequest{AppName: req.AppName})
+	if err != nil {
+		return nil, err
+	}
+
+	return &vtctldatapb.CheckThrottlerResponse{
+		TabletAlias: req.TabletAlias,
+		Check:       check,
+	}, nil
+}
+
 // CleanupSchemaMigration is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CleanupSchemaMigration(ctx context.Context, req *vtctldatapb.CleanupSchemaMigrationRequest) (resp *vtctldatapb.CleanupSchemaMigrationResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CleanupSchemaMigration")
@@ -774,6 +807,79 @@ func (s *VtctldServer) CompleteSchemaMigration(ctx context.Context, req *vtctlda
 	return resp, nil
 }
 
+// ConcludeTransaction is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) ConcludeTransaction(ctx context.Context, req *vtctldatapb.ConcludeTransactionRequest) (resp *vtctldatapb.ConcludeTransactionResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.ConcludeTransaction")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("dtid", req.Dtid)
+	span.Annotate("force", req.Force)
+
+	transaction, mmPrimary, err := s.readTransaction(ctx, req.Dtid)
+	if err != nil {
+		return nil, err
+	}
+	resp = &vtctldatapb.ConcludeTransactionResponse{}
+	if transaction == nil {
+		// It was already resolved.
+		return resp, nil
+	}
+	if transaction.State == querypb.TransactionState_PREPARE && !req.Force {
+		// The vtgate running the transaction may still be committing it, and
+		// rolling it back now would leave it committed on some of the shards.
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "distributed transaction %s is in the PREPARE state and may still be committed by its coordinator; use force to roll it back once it is abandoned", transaction.Dtid)
+	}
+
+	mm, err := tabletconn.GetDialer()(ctx, mmPrimary, grpcclient.FailFast(false))
+	if err != nil {
+		return nil, err
+	}
+	defer mm.Close(ctx)
+	mmTarget := &querypb.Target{
+		Keyspace:   mmPrimary.Keyspace,
+		Shard:      mmPrimary.Shard,
+		TabletType: topodatapb.TabletType_PRIMARY,
+	}
+
+	switch transaction.State {
+	case querypb.TransactionState_PREPARE:
+		// No commit decision was made, so decide to roll back. This fails if
+		// the vtgate running the transaction decides to commit it meanwhile.
+		txid, err := dtids.TransactionID(transaction.Dtid)
+		if err != nil {
+			return nil, err
+		}
+		if err := mm.SetRollback(ctx, mmTarget, transaction.Dtid, txid); err != nil {
+			return nil, err
+		}
+		fallthrough
+	case querypb.TransactionState_ROLLBACK:
+		err = s.resolveParticipants(ctx, transaction.Participants, func(qs queryservice.QueryService, target *querypb.Target) error {
+			return qs.RollbackPrepared(ctx, target, transaction.Dtid, 0)
+		})
+		resp.State = querypb.TransactionState_ROLLBACK
+	case querypb.TransactionState_COMMIT:
+		err = s.resolveParticipants(ctx, transaction.Participants, func(qs queryservice.QueryService, target *querypb.Target) error {
+			return qs.CommitPrepared(ctx, target, transaction.Dtid)
+		})
+		resp.State = querypb.TransactionState_COMMIT
+	default:
+		return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "invalid state for dtid %s: %v", transaction.Dtid, transaction.State)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := mm.ConcludeTransaction(ctx, mmTarget, transaction.Dtid); err != nil {
+		return nil, err
+	}
+	log.Infof("Concluded distributed transaction %s with %v", transaction.Dtid, resp.State)
+
+	return resp, nil
+}
+
 // CreateKeyspace is part of the vtctlservicepb.VtctldServer interface.
 func (s *VtctldServer) CreateKeyspace(ctx context.Context, req *vtctldatapb.CreateKeyspaceRequest) (resp *vtctldatapb.CreateKeyspaceResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.CreateKeyspace")
@@ -1930,6 +2036,18 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 	if req.CheckAsCheckSelf && req.CheckAsCheckShard {
 		return nil, fmt.Errorf("--check-as-check-self and --check-as-check-shard are mutually exclusive")
 	}
+	metricNames, err := base.ParseMetricNames([]string{req.MetricName})
+	if err != nil {
+		return nil, err
+	}
+	metricName := base.DefaultMetricName
+	if len(metricNames) > 0 {
+		metricName = metricNames[0]
+	}
+	appCheckedMetrics, err := base.ParseMetricNames(req.AppCheckedMetrics)
+	if err != nil {
+		return nil, err
+	}
 
 	update := func(throttlerConfig *topodatapb.ThrottlerConfig) *topodatapb.ThrottlerConfig {
 		if throttlerConfig == nil {
@@ -1938,11 +2056,27 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 		if throttlerConfig.ThrottledApps == nil {
 			throttlerConfig.ThrottledApps = make(map[string]*topodatapb.ThrottledAppRule)
 		}
+		if throttlerConfig.MetricThresholds == nil {
+			throttlerConfig.MetricThresholds = make(map[string]float64)
+		}
+		if throttlerConfig.AppCheckedMetrics == nil {
+			throttlerConfig.AppCheckedMetrics = make(map[string]*topodatapb.ThrottlerConfig_MetricNames)
+		}
 		if req.CustomQuerySet {
 			// custom query provided
 			throttlerConfig.CustomQuery = req.CustomQuery
+		}
+		switch {
+		case metricName != base.DefaultMetricName:
+			// threshold of a specific metric. Zero/negative values restore the metric's built-in threshold
+			if req.Threshold > 0 {
+				throttlerConfig.MetricThresholds[metricName.String()] = req.Threshold
+			} else {
+				delete(throttlerConfig.MetricThresholds, metricName.String())
+			}
+		case req.CustomQuerySet:
 			throttlerConfig.Threshold = req.Threshold // allowed to be zero/negative because who knows what kind of custom query this is
-		} else {
+		default:
 			// no custom query, throttler works by querying replication lag. We only allow positive values
 			if req.Threshold > 0 {
 				throttlerConfig.Threshold = req.Threshold
@@ -1963,6 +2097,18 @@ func (s *VtctldServer) UpdateThrottlerConfig(ctx context.Context, req *vtctldata
 		if req.ThrottledApp != nil && req.ThrottledApp.Name != "" {
 			throttlerConfig.ThrottledApps[req.ThrottledApp.Name] = req.ThrottledApp
 		}
+		if req.AppName != "" {
+			if len(appCheckedMetrics) == 0 {
+				// the app is checked against the default metric
+				delete(throttlerConfig.AppCheckedMetrics, req.AppName)
+			} else {
+				names := make([]string, 0, len(appCheckedMetrics))
+				for _, name := range appCheckedMetrics {
+					names = append(names, name.String())
+				}
+				throttlerConfig.AppCheckedMetrics[req.AppName] = &topodatapb.ThrottlerConfig_MetricNames{Names: names}
+			}
+		}
 		return throttlerConfig
 	}
 
@@ -2289,6 +2435,143 @@ func (s *VtctldServer) GetTopologyPath(ctx context.Context, req *vtctldatapb.Get
 	}, nil
 }
 
+// GetTransactionInfo is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetTransactionInfo(ctx context.Context, req *vtctldatapb.GetTransactionInfoRequest) (resp *vtctldatapb.GetTransactionInfoResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetTransactionInfo")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("dtid", req.Dtid)
+
+	transaction, _, err := s.readTransaction(ctx, req.Dtid)
+	if err != nil {
+		return nil, err
+	}
+	if transaction == nil {
+		return nil, vterrors.Errorf(vtrpcpb.Code_NOT_FOUND, "transaction %s not found, it may have been resolved", req.Dtid)
+	}
+
+	var (
+		wg          sync.WaitGroup
+		rec         concurrency.AllErrorRecorder
+		shardStates = make([]*vtctldatapb.ShardTransactionState, len(transaction.Participants))
+	)
+	for i, target := range transaction.Participants {
+		wg.Add(1)
+		go func(i int, target *querypb.Target) {
+			defer wg.Done()
+
+			primary, err := s.shardPrimary(ctx, target.Keyspace, target.Shard)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			sidecarDB, err := s.sidecarDBName(ctx, target.Keyspace)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			query, err := selectPreparedTransactionQuery(sidecarDB, transaction.Dtid)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			qr, err := s.tmc.ExecuteFetchAsDba(ctx, primary, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: 10_000,
+			})
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			shardStates[i], err = rowsToShardTransactionState(target, sqltypes.Proto3ToResult(qr))
+			rec.RecordError(err)
+		}(i, target)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	return &vtctldatapb.GetTransactionInfoResponse{
+		Metadata:    transaction,
+		ShardStates: shardStates,
+	}, nil
+}
+
+// GetUnresolvedTransactions is part of the vtctlservicepb.VtctldServer interface.
+func (s *VtctldServer) GetUnresolvedTransactions(ctx context.Context, req *vtctldatapb.GetUnresolvedTransactionsRequest) (resp *vtctldatapb.GetUnresolvedTransactionsResponse, err error) {
+	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetUnresolvedTransactions")
+	defer span.Finish()
+
+	defer panicHandler(&err)
+
+	span.Annotate("keyspace", req.Keyspace)
+	span.Annotate("abandon_age", req.AbandonAge)
+
+	shards, err := s.ts.GetShardNames(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	sidecarDB, err := s.sidecarDBName(ctx, req.Keyspace)
+	if err != nil {
+		return nil, err
+	}
+	query, err := selectUnresolvedTransactionsQuery(sidecarDB, time.Now().Add(-time.Duration(req.AbandonAge)*time.Second))
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		m   sync.Mutex
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	resp = &vtctldatapb.GetUnresolvedTransactionsResponse{}
+	for _, shard := range shards {
+		wg.Add(1)
+		go func(shard string) {
+			defer wg.Done()
+
+			primary, err := s.shardPrimary(ctx, req.Keyspace, shard)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			qr, err := s.tmc.ExecuteFetchAsDba(ctx, primary, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+				Query:   []byte(query),
+				MaxRows: 10_000,
+			})
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			transactions, err := rowsToTransactions(sqltypes.Proto3ToResult(qr))
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+
+			m.Lock()
+			defer m.Unlock()
+			resp.Transactions = append(resp.Transactions, transactions...)
+		}(shard)
+	}
+	wg.Wait()
+	if rec.HasErrors() {
+		return nil, rec.Error()
+	}
+
+	sort.Slice(resp.Transactions, func(i, j int) bool {
+		if resp.Transactions[i].TimeCreated != resp.Transactions[j].TimeCreated {
+			return resp.Transactions[i].TimeCreated < resp.Transactions[j].TimeCreated
+		}
+		return resp.Transactions[i].Dtid < resp.Transactions[j].Dtid
+	})
+	return resp, nil
+}
+
 // GetVersion returns the version of a tablet from its debug vars
 func (s *VtctldServer) GetVersion(ctx context.Context, req *vtctldatapb.GetVersionRequest) (resp *vtctldatapb.GetVersionResponse, err error) {
 	span, ctx := trace.NewSpan(ctx, "VtctldServer.GetVersion")
@@ -5065,6 +5348,101 @@ func StartServer(s *grpc.Server, env *vtenv.Environment, ts *topo.Server) {
 	vtctlservicepb.RegisterVtctldServer(s, NewVtctldServer(env, ts))
 }
 
+// shardPrimary returns the primary tablet of a shard.
+func (s *VtctldServer) shardPrimary(ctx context.Context, keyspace, shard string) (*topodatapb.Tablet, error) {
+	si, err := s.ts.GetShard(ctx, keyspace, shard)
+	if err != nil {
+		return nil, err
+	}
+	if !si.HasPrimary() {
+		return nil, vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "shard %s/%s has no primary", keyspace, shard)
+	}
+	ti, err := s.ts.GetTablet(ctx, si.PrimaryAlias)
+	if err != nil {
+		return nil, err
+	}
+	return ti.Tablet, nil
+}
+
+// sidecarDBName returns the name of the sidecar database of the tablets of a keyspace.
+func (s *VtctldServer) sidecarDBName(ctx context.Context, keyspace string) (string, error) {
+	ki, err := s.ts.GetKeyspace(ctx, keyspace)
+	if err != nil {
+		return "", err
+	}
+	if ki.SidecarDbName == "" {
+		return sidecar.DefaultName, nil
+	}
+	return ki.SidecarDbName, nil
+}
+
+// readTransaction reads the metadata of a distributed transaction from the
+// primary of the shard that manages it, which is also returned. The metadata
+// is nil if the transaction does not exist, because it was resolved.
+func (s *VtctldServer) readTransaction(ctx context.Context, dtid string) (*querypb.TransactionMetadata, *topodatapb.Tablet, error) {
+	mmShard, err := dtids.ShardSession(dtid)
+	if err != nil {
+		return nil, nil, err
+	}
+	primary, err := s.shardPrimary(ctx, mmShard.Target.Keyspace, mmShard.Target.Shard)
+	if err != nil {
+		return nil, nil, err
+	}
+	sidecarDB, err := s.sidecarDBName(ctx, mmShard.Target.Keyspace)
+	if err != nil {
+		return nil, nil, err
+	}
+	query, err := selectTransactionQuery(sidecarDB, dtid)
+	if err != nil {
+		return nil, nil, err
+	}
+	qr, err := s.tmc.ExecuteFetchAsDba(ctx, primary, false, &tabletmanagerdatapb.ExecuteFetchAsDbaRequest{
+		Query:   []byte(query),
+		MaxRows: 10_000,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	transactions, err := rowsToTransactions(sqltypes.Proto3ToResult(qr))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(transactions) == 0 {
+		return nil, primary, nil
+	}
+	return transactions[0], primary, nil
+}
+
+// resolveParticipants runs the resolve function against the primary of each
+// participant of a distributed transaction, in parallel.
+func (s *VtctldServer) resolveParticipants(ctx context.Context, participants []*querypb.Target, resolve func(qs queryservice.QueryService, target *querypb.Target) error) error {
+	var (
+		wg  sync.WaitGroup
+		rec concurrency.AllErrorRecorder
+	)
+	for _, target := range participants {
+		wg.Add(1)
+		go func(target *querypb.Target) {
+			defer wg.Done()
+
+			primary, err := s.shardPrimary(ctx, target.Keyspace, target.Shard)
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			qs, err := tabletconn.GetDialer()(ctx, primary, grpcclient.FailFast(false))
+			if err != nil {
+				rec.RecordError(err)
+				return
+			}
+			defer qs.Close(ctx)
+			rec.RecordError(resolve(qs, target))
+		}(target)
+	}
+	wg.Wait()
+	return rec.Error()
+}
+
 // getTopologyCell is a helper method that returns a topology cell given its path.
 func (s *VtctldServer) getTopologyCell(ctx context.Context, cellPath string, version int64, asJSON bool) (*vtctldatapb.TopologyCell, error) {
 	// extract cell and relative path
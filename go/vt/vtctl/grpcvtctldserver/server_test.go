@@ -21,10 +21,12 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"slices"
 	"sort"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -38,6 +40,7 @@ import (
 	"vitess.io/vitess/go/protoutil"
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/grpcclient"
 	hk "vitess.io/vitess/go/vt/hook"
 	"vitess.io/vitess/go/vt/mysqlctl/backupstorage"
 	"vitess.io/vitess/go/vt/proto/vttime"
@@ -48,6 +51,11 @@ import (
 	"vitess.io/vitess/go/vt/vtctl/localvtctldclient"
 	"vitess.io/vitess/go/vt/vtctl/schematools"
 	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletconntest"
 	"vitess.io/vitess/go/vt/vttablet/tmclient"
 	"vitess.io/vitess/go/vt/vttablet/tmclienttest"
 
@@ -60,6 +68,7 @@ import (
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	vtctldatapb "vitess.io/vitess/go/vt/proto/vtctldata"
 	vtctlservicepb "vitess.io/vitess/go/vt/proto/vtctlservice"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 )
 
 func init() {
@@ -76,8 +85,21 @@ func init() {
 	tmclient.RegisterTabletManagerClientFactory("grpcvtctldserver.test", func() tmclient.TabletManagerClient {
 		return nil
 	})
+
+	// Tests that resolve distributed transactions dial the tablets through
+	// this dialer, which returns the connections registered in sandboxConns.
+	tabletconntest.SetProtocol("go.vt.vtctl.grpcvtctldserver", "grpcvtctldserver.test")
+	tabletconn.RegisterDialer("grpcvtctldserver.test", func(ctx context.Context, tablet *topodatapb.Tablet, failFast grpcclient.FailFast) (queryservice.QueryService, error) {
+		if sbc, ok := sandboxConns.Load(topoproto.TabletAliasString(tablet.Alias)); ok {
+			return sbc.(*sandboxconn.SandboxConn), nil
+		}
+		return nil, fmt.Errorf("%w: no sandbox connection for tablet %s", assert.AnError, topoproto.TabletAliasString(tablet.Alias))
+	})
 }
 
+// sandboxConns maps tablet alias strings to the connections the test dialer returns.
+var sandboxConns sync.Map
+
 func TestPanicHandler(t *testing.T) {
 	t.Parallel()
 
@@ -1753,6 +1775,152 @@ func TestChangeTabletType(t *testing.T) {
 	})
 }
 
+func TestCheckThrottler(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		tablets   []*topodatapb.Tablet
+		tmc       testutil.TabletManagerClient
+		req       *vtctldatapb.CheckThrottlerRequest
+		expected  *vtctldatapb.CheckThrottlerResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				CheckThrottlerResults: map[string]*tabletmanagerdatapb.CheckThrottlerResponse{
+					"zone1-0000000100": {
+						StatusCode: http.StatusTooManyRequests,
+						Value:      150,
+						Threshold:  100,
+						Metrics: map[string]*tabletmanagerdatapb.CheckThrottlerResponse_Metric{
+							"lag": {
+								Name:       "lag",
+								StatusCode: http.StatusOK,
+								Value:      1,
+								Threshold:  5,
+							},
+							"threads_running": {
+								Name:       "threads_running",
+								StatusCode: http.StatusTooManyRequests,
+								Value:      150,
+								Threshold:  100,
+							},
+						},
+					},
+				},
+			},
+			req: &vtctldatapb.CheckThrottlerRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				AppName: "online-ddl",
+			},
+			expected: &vtctldatapb.CheckThrottlerResponse{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+				Check: &tabletmanagerdatapb.CheckThrottlerResponse{
+					StatusCode: http.StatusTooManyRequests,
+					Value:      150,
+					Threshold:  100,
+					Metrics: map[string]*tabletmanagerdatapb.CheckThrottlerResponse_Metric{
+						"lag": {
+							Name:       "lag",
+							StatusCode: http.StatusOK,
+							Value:      1,
+							Threshold:  5,
+						},
+						"threads_running": {
+							Name:       "threads_running",
+							StatusCode: http.StatusTooManyRequests,
+							Value:      150,
+							Threshold:  100,
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "no tablet",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  404,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{
+				CheckThrottlerResults: map[string]*tabletmanagerdatapb.CheckThrottlerResponse{
+					"zone1-0000000100": {StatusCode: http.StatusOK},
+				},
+			},
+			req: &vtctldatapb.CheckThrottlerRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "tmc call failed",
+			tablets: []*topodatapb.Tablet{
+				{
+					Alias: &topodatapb.TabletAlias{
+						Cell: "zone1",
+						Uid:  100,
+					},
+				},
+			},
+			tmc: testutil.TabletManagerClient{},
+			req: &vtctldatapb.CheckThrottlerRequest{
+				TabletAlias: &topodatapb.TabletAlias{
+					Cell: "zone1",
+					Uid:  100,
+				},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddTablets(ctx, t, ts, nil, tt.tablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, &tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.CheckThrottler(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestCleanupSchemaMigration(t *testing.T) {
 	t.Parallel()
 
@@ -2365,6 +2533,138 @@ func TestCompleteSchemaMigration(t *testing.T) {
 	}
 }
 
+// transactionTestTablets are the primaries of a keyspace with two shards,
+// where -80 is the metadata manager shard of the distributed transactions.
+var transactionTestTablets = []*topodatapb.Tablet{
+	{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 100},
+		Keyspace: "testkeyspace",
+		Shard:    "-80",
+		Type:     topodatapb.TabletType_PRIMARY,
+	},
+	{
+		Alias:    &topodatapb.TabletAlias{Cell: "zone1", Uid: 200},
+		Keyspace: "testkeyspace",
+		Shard:    "80-",
+		Type:     topodatapb.TabletType_PRIMARY,
+	},
+}
+
+// dtStateResult returns a result of the dt_state table joined with the
+// dt_participant table, with each row formatted as "dtid|state|time_created|keyspace|shard".
+func dtStateResult(rows ...string) *querypb.QueryResult {
+	return sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("dtid|state|time_created|keyspace|shard", "varbinary|int64|int64|varchar|varchar"),
+		rows...,
+	))
+}
+
+func TestConcludeTransaction(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		dtStateResult      *querypb.QueryResult
+		req                *vtctldatapb.ConcludeTransactionRequest
+		expected           *vtctldatapb.ConcludeTransactionResponse
+		wantSetRollback    int64
+		wantCommitPrepared int64
+		wantRollbackPrep   int64
+		wantConclude       int64
+		shouldErr          bool
+	}{
+		{
+			name:               "commit decision",
+			dtStateResult:      dtStateResult("testkeyspace:-80:1234|2|100|testkeyspace|80-"),
+			req:                &vtctldatapb.ConcludeTransactionRequest{Dtid: "testkeyspace:-80:1234"},
+			expected:           &vtctldatapb.ConcludeTransactionResponse{State: querypb.TransactionState_COMMIT},
+			wantCommitPrepared: 1,
+			wantConclude:       1,
+		},
+		{
+			name:             "rollback decision",
+			dtStateResult:    dtStateResult("testkeyspace:-80:1234|3|100|testkeyspace|80-"),
+			req:              &vtctldatapb.ConcludeTransactionRequest{Dtid: "testkeyspace:-80:1234"},
+			expected:         &vtctldatapb.ConcludeTransactionResponse{State: querypb.TransactionState_ROLLBACK},
+			wantRollbackPrep: 1,
+			wantConclude:     1,
+		},
+		{
+			name:             "no decision",
+			dtStateResult:    dtStateResult("testkeyspace:-80:1234|1|100|testkeyspace|80-"),
+			req:              &vtctldatapb.ConcludeTransactionRequest{Dtid: "testkeyspace:-80:1234", Force: true},
+			expected:         &vtctldatapb.ConcludeTransactionResponse{State: querypb.TransactionState_ROLLBACK},
+			wantSetRollback:  1,
+			wantRollbackPrep: 1,
+			wantConclude:     1,
+		},
+		{
+			// The coordinator may still be committing the transaction.
+			name:          "no decision without force",
+			dtStateResult: dtStateResult("testkeyspace:-80:1234|1|100|testkeyspace|80-"),
+			req:           &vtctldatapb.ConcludeTransactionRequest{Dtid: "testkeyspace:-80:1234"},
+			shouldErr:     true,
+		},
+		{
+			name:          "already resolved",
+			dtStateResult: dtStateResult(),
+			req:           &vtctldatapb.ConcludeTransactionRequest{Dtid: "testkeyspace:-80:1234"},
+			expected:      &vtctldatapb.ConcludeTransactionResponse{},
+		},
+		{
+			name:          "invalid dtid",
+			dtStateResult: dtStateResult(),
+			req:           &vtctldatapb.ConcludeTransactionRequest{Dtid: "1234"},
+			shouldErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		// The subtests share the sandbox connections, so they do not run in
+		// parallel.
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, transactionTestTablets...)
+
+			mm := sandboxconn.NewSandboxConn(transactionTestTablets[0])
+			rm := sandboxconn.NewSandboxConn(transactionTestTablets[1])
+			sandboxConns.Store("zone1-0000000100", mm)
+			sandboxConns.Store("zone1-0000000200", rm)
+			defer sandboxConns.Delete("zone1-0000000100")
+			defer sandboxConns.Delete("zone1-0000000200")
+
+			tmc := &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: tt.dtStateResult,
+					},
+				},
+			}
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.ConcludeTransaction(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				utils.MustMatch(t, tt.expected, resp)
+			}
+			assert.EqualValues(t, tt.wantSetRollback, mm.SetRollbackCount.Load(), "SetRollback")
+			assert.EqualValues(t, tt.wantCommitPrepared, rm.CommitPreparedCount.Load(), "CommitPrepared")
+			assert.EqualValues(t, tt.wantRollbackPrep, rm.RollbackPreparedCount.Load(), "RollbackPrepared")
+			assert.EqualValues(t, tt.wantConclude, mm.ConcludeTransactionCount.Load(), "ConcludeTransaction")
+		})
+	}
+}
+
 func TestCreateKeyspace(t *testing.T) {
 	t.Parallel()
 
@@ -7888,6 +8188,244 @@ func TestGetTopologyPath(t *testing.T) {
 	}
 }
 
+func TestGetTransactionInfo(t *testing.T) {
+	t.Parallel()
+
+	redoResult := sqltypes.ResultToProto3(sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields("state|time_created|statement", "int64|int64|varbinary"),
+		"1|100|insert into t(id) values (1)",
+		"1|100|update t set c = 2 where id = 1",
+	))
+
+	tests := []struct {
+		name      string
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.GetTransactionInfoRequest
+		expected  *vtctldatapb.GetTransactionInfoResponse
+		wantCode  vtrpcpb.Code
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: dtStateResult("testkeyspace:-80:1234|1|100|testkeyspace|80-"),
+					},
+					"zone1-0000000200": {
+						Response: redoResult,
+					},
+				},
+			},
+			req: &vtctldatapb.GetTransactionInfoRequest{Dtid: "testkeyspace:-80:1234"},
+			expected: &vtctldatapb.GetTransactionInfoResponse{
+				Metadata: &querypb.TransactionMetadata{
+					Dtid:        "testkeyspace:-80:1234",
+					State:       querypb.TransactionState_PREPARE,
+					TimeCreated: 100,
+					Participants: []*querypb.Target{
+						{Keyspace: "testkeyspace", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+					},
+				},
+				ShardStates: []*vtctldatapb.ShardTransactionState{
+					{
+						Keyspace:    "testkeyspace",
+						Shard:       "80-",
+						State:       "PREPARED",
+						TimeCreated: 100,
+						Statements:  []string{"insert into t(id) values (1)", "update t set c = 2 where id = 1"},
+					},
+				},
+			},
+		},
+		{
+			name: "not found",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: dtStateResult(),
+					},
+				},
+			},
+			req:       &vtctldatapb.GetTransactionInfoRequest{Dtid: "testkeyspace:-80:1234"},
+			wantCode:  vtrpcpb.Code_NOT_FOUND,
+			shouldErr: true,
+		},
+		{
+			name: "participant error",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: dtStateResult("testkeyspace:-80:1234|1|100|testkeyspace|80-"),
+					},
+					"zone1-0000000200": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			req:       &vtctldatapb.GetTransactionInfoRequest{Dtid: "testkeyspace:-80:1234"},
+			shouldErr: true,
+		},
+		{
+			name:      "unknown metadata manager shard",
+			tmc:       &testutil.TabletManagerClient{},
+			req:       &vtctldatapb.GetTransactionInfoRequest{Dtid: "testkeyspace:80-c0:1234"},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, transactionTestTablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.GetTransactionInfo(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				if tt.wantCode != vtrpcpb.Code_OK {
+					assert.Equal(t, tt.wantCode, vterrors.Code(err))
+				}
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
+func TestGetUnresolvedTransactions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		tmc       *testutil.TabletManagerClient
+		req       *vtctldatapb.GetUnresolvedTransactionsRequest
+		expected  *vtctldatapb.GetUnresolvedTransactionsResponse
+		shouldErr bool
+	}{
+		{
+			name: "ok",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: dtStateResult(
+							"testkeyspace:-80:1|1|300|testkeyspace|80-",
+							"testkeyspace:-80:2|2|100|testkeyspace|80-",
+						),
+					},
+					"zone1-0000000200": {
+						Response: dtStateResult(
+							"testkeyspace:80-:3|3|200|testkeyspace|-80",
+							"testkeyspace:80-:3|3|200|otherkeyspace|0",
+						),
+					},
+				},
+			},
+			req: &vtctldatapb.GetUnresolvedTransactionsRequest{Keyspace: "testkeyspace", AbandonAge: 60},
+			expected: &vtctldatapb.GetUnresolvedTransactionsResponse{
+				Transactions: []*querypb.TransactionMetadata{
+					{
+						Dtid:        "testkeyspace:-80:2",
+						State:       querypb.TransactionState_COMMIT,
+						TimeCreated: 100,
+						Participants: []*querypb.Target{
+							{Keyspace: "testkeyspace", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+						},
+					},
+					{
+						Dtid:        "testkeyspace:80-:3",
+						State:       querypb.TransactionState_ROLLBACK,
+						TimeCreated: 200,
+						Participants: []*querypb.Target{
+							{Keyspace: "testkeyspace", Shard: "-80", TabletType: topodatapb.TabletType_PRIMARY},
+							{Keyspace: "otherkeyspace", Shard: "0", TabletType: topodatapb.TabletType_PRIMARY},
+						},
+					},
+					{
+						Dtid:        "testkeyspace:-80:1",
+						State:       querypb.TransactionState_PREPARE,
+						TimeCreated: 300,
+						Participants: []*querypb.Target{
+							{Keyspace: "testkeyspace", Shard: "80-", TabletType: topodatapb.TabletType_PRIMARY},
+						},
+					},
+				},
+			},
+		},
+		{
+			name: "shard error",
+			tmc: &testutil.TabletManagerClient{
+				ExecuteFetchAsDbaResults: map[string]struct {
+					Response *querypb.QueryResult
+					Error    error
+				}{
+					"zone1-0000000100": {
+						Response: dtStateResult(),
+					},
+					"zone1-0000000200": {
+						Error: assert.AnError,
+					},
+				},
+			},
+			req:       &vtctldatapb.GetUnresolvedTransactionsRequest{Keyspace: "testkeyspace"},
+			shouldErr: true,
+		},
+		{
+			name:      "keyspace not found",
+			tmc:       &testutil.TabletManagerClient{},
+			req:       &vtctldatapb.GetUnresolvedTransactionsRequest{Keyspace: "doesnotexist"},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddTablets(ctx, t, ts, &testutil.AddTabletOptions{
+				AlsoSetShardPrimary: true,
+			}, transactionTestTablets...)
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, tt.tmc, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			resp, err := vtctld.GetUnresolvedTransactions(ctx, tt.req)
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, resp)
+		})
+	}
+}
+
 func TestGetVSchema(t *testing.T) {
 	t.Parallel()
 
@@ -13139,6 +13677,112 @@ func TestUpdateCellsAlias(t *testing.T) {
 	}
 }
 
+func TestUpdateThrottlerConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		reqs      []*vtctldatapb.UpdateThrottlerConfigRequest
+		expected  *topodatapb.ThrottlerConfig
+		shouldErr bool
+	}{
+		{
+			name: "threshold",
+			reqs: []*vtctldatapb.UpdateThrottlerConfigRequest{
+				{Keyspace: "testkeyspace", Enable: true, Threshold: 3},
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Enabled:           true,
+				Threshold:         3,
+				ThrottledApps:     map[string]*topodatapb.ThrottledAppRule{},
+				MetricThresholds:  map[string]float64{},
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_MetricNames{},
+			},
+		},
+		{
+			name: "metric thresholds",
+			reqs: []*vtctldatapb.UpdateThrottlerConfigRequest{
+				{Keyspace: "testkeyspace", Threshold: 3, MetricName: "default"},
+				{Keyspace: "testkeyspace", Threshold: 50, MetricName: "threads_running"},
+				{Keyspace: "testkeyspace", Threshold: 10000, MetricName: "History_List_Length"},
+				{Keyspace: "testkeyspace", Threshold: 0, MetricName: "history_list_length"},
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				Threshold:         3,
+				ThrottledApps:     map[string]*topodatapb.ThrottledAppRule{},
+				MetricThresholds:  map[string]float64{"threads_running": 50},
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_MetricNames{},
+			},
+		},
+		{
+			name: "app checked metrics",
+			reqs: []*vtctldatapb.UpdateThrottlerConfigRequest{
+				{Keyspace: "testkeyspace", AppName: "online-ddl", AppCheckedMetrics: []string{"lag", "history_list_length"}},
+				{Keyspace: "testkeyspace", AppName: "all", AppCheckedMetrics: []string{"default", "loadavg"}},
+				{Keyspace: "testkeyspace", AppName: "vreplication", AppCheckedMetrics: []string{"threads_running"}},
+				{Keyspace: "testkeyspace", AppName: "vreplication"},
+			},
+			expected: &topodatapb.ThrottlerConfig{
+				ThrottledApps:    map[string]*topodatapb.ThrottledAppRule{},
+				MetricThresholds: map[string]float64{},
+				AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_MetricNames{
+					"online-ddl": {Names: []string{"lag", "history_list_length"}},
+					"all":        {Names: []string{"default", "loadavg"}},
+				},
+			},
+		},
+		{
+			name: "unknown metric name",
+			reqs: []*vtctldatapb.UpdateThrottlerConfigRequest{
+				{Keyspace: "testkeyspace", Threshold: 3, MetricName: "no_such_metric"},
+			},
+			shouldErr: true,
+		},
+		{
+			name: "unknown app checked metric",
+			reqs: []*vtctldatapb.UpdateThrottlerConfigRequest{
+				{Keyspace: "testkeyspace", AppName: "online-ddl", AppCheckedMetrics: []string{"lag", "no_such_metric"}},
+			},
+			shouldErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ts := memorytopo.NewServer(ctx, "zone1")
+			testutil.AddKeyspace(ctx, t, ts, &vtctldatapb.Keyspace{
+				Name:     "testkeyspace",
+				Keyspace: &topodatapb.Keyspace{},
+			})
+
+			vtctld := testutil.NewVtctldServerWithTabletManagerClient(t, ts, nil, func(ts *topo.Server) vtctlservicepb.VtctldServer {
+				return NewVtctldServer(vtenv.NewTestEnv(), ts)
+			})
+			var err error
+			for _, req := range tt.reqs {
+				_, err = vtctld.UpdateThrottlerConfig(ctx, req)
+				if err != nil {
+					break
+				}
+			}
+			if tt.shouldErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			ki, err := ts.GetKeyspace(ctx, "testkeyspace")
+			require.NoError(t, err)
+			utils.MustMatch(t, tt.expected, ki.ThrottlerConfig)
+		})
+	}
+}
+
 func TestValidate(t *testing.T) {
 	t.Parallel()
 
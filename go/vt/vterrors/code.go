@@ -59,6 +59,8 @@ var (
 	VT03031 = errorWithoutState("VT03031", vtrpcpb.Code_INVALID_ARGUMENT, "EXPLAIN is only supported for single keyspace", "EXPLAIN has to be sent down as a single query to the underlying MySQL, and this is not possible if it uses tables from multiple keyspaces")
 	VT03032 = errorWithState("VT03032", vtrpcpb.Code_INVALID_ARGUMENT, NonUpdateableTable, "the target table %s of the UPDATE is not updatable", "You cannot update a table that is not a real MySQL table.")
 	VT03033 = errorWithState("VT03033", vtrpcpb.Code_INVALID_ARGUMENT, ViewWrongList, "In definition of view, derived table or common table expression, SELECT list and column names list have different column counts", "The table column list and derived column list have different column counts.")
+	VT03034 = errorWithoutState("VT03034", vtrpcpb.Code_INVALID_ARGUMENT, "argument #%d of GROUPING function is not in GROUP BY", "The arguments of the GROUPING function have to be expressions that are used in the GROUP BY clause.")
+	VT03035 = errorWithState("VT03035", vtrpcpb.Code_INVALID_ARGUMENT, SubqueryNo1Row, "Subquery returns more than 1 row", "A subquery used as an expression returned more than one row.")
 
 	VT05001 = errorWithState("VT05001", vtrpcpb.Code_NOT_FOUND, DbDropExists, "cannot drop database '%s'; database does not exists", "The given database does not exist; Vitess cannot drop it.")
 	VT05002 = errorWithState("VT05002", vtrpcpb.Code_NOT_FOUND, BadDb, "cannot alter database '%s'; unknown database", "The given database does not exist; Vitess cannot alter it.")
@@ -96,6 +98,7 @@ var (
 	VT09022 = errorWithoutState("VT09022", vtrpcpb.Code_FAILED_PRECONDITION, "Destination does not have exactly one shard: %v", "Cannot send query to multiple shards.")
 	VT09023 = errorWithoutState("VT09023", vtrpcpb.Code_FAILED_PRECONDITION, "could not map %v to a keyspace id", "Unable to determine the shard for the given row.")
 	VT09024 = errorWithoutState("VT09024", vtrpcpb.Code_FAILED_PRECONDITION, "could not map %v to a unique keyspace id: %v", "Unable to determine the shard for the given row.")
+	VT09025 = errorWithoutState("VT09025", vtrpcpb.Code_FAILED_PRECONDITION, "Recursive query aborted after %d iterations. Try increasing @@cte_max_recursion_depth or --cte-max-recursion-depth to a larger value", "The recursive part of a common table expression was evaluated more times than allowed by the cte_max_recursion_depth of the session, or the --cte-max-recursion-depth flag of vtgate if the session doesn't set it.")
 
 	VT10001 = errorWithoutState("VT10001", vtrpcpb.Code_ABORTED, "foreign key constraints are not allowed", "Foreign key constraints are not allowed, see https://vitess.io/blog/2021-06-15-online-ddl-why-no-fk/.")
 
@@ -148,6 +151,8 @@ var (
 		VT03031,
 		VT03032,
 		VT03033,
+		VT03034,
+		VT03035,
 		VT05001,
 		VT05002,
 		VT05003,
@@ -181,6 +186,7 @@ var (
 		VT09022,
 		VT09023,
 		VT09024,
+		VT09025,
 		VT10001,
 		VT12001,
 		VT12002,
@@ -50,6 +50,7 @@ const (
 	BadNullError
 	InvalidGroupFuncUse
 	ViewWrongList
+	SubqueryNo1Row
 
 	// failed precondition
 	NoDB
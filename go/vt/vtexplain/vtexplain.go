@@ -218,16 +218,20 @@ func Init(ctx context.Context, env *vtenv.Environment, ts *topo.Server, vSchemaS
 
 // Stop and cleans up fake execution environment
 func (vte *VTExplain) Stop() {
+	// Stop the tablets before the executor closes the topo server they use.
+	if vte.explainTopo != nil {
+		for _, conn := range vte.explainTopo.TabletConns {
+			conn.tsv.StopService()
+			conn.tsv.Close(context.Background())
+		}
+	}
+
 	if vte.vtgateExecutor != nil {
 		vte.vtgateExecutor.Close()
 	}
 
 	// Cleanup all created fake dbs.
 	if vte.explainTopo != nil {
-		for _, conn := range vte.explainTopo.TabletConns {
-			conn.tsv.StopService()
-			conn.tsv.Close(context.Background())
-		}
 		for _, conn := range vte.explainTopo.TabletConns {
 			conn.db.Close()
 		}
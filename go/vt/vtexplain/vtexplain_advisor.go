@@ -0,0 +1,672 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"vitess.io/vitess/go/json2"
+	"vitess.io/vitess/go/sqlescape"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/memorytopo"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+)
+
+type (
+	// VindexAdvice is the result of the vindex advisor. It describes the vindexes
+	// recommended for the tables of a sharded keyspace, and how the queries of the
+	// corpus are routed when these vindexes are used.
+	VindexAdvice struct {
+		Keyspace  string
+		NumShards int
+
+		// Queries is the number of queries analyzed, counting repeated queries
+		Queries int
+		// SingleShardQueries is the number of queries sent to a single shard
+		SingleShardQueries int
+		// FailedQueries is the number of queries that could not be planned
+		FailedQueries int
+		// AvgShards is the average number of shards a query is sent to
+		AvgShards float64
+
+		Tables         []*TableVindexAdvice
+		LookupVindexes []*LookupVindexAdvice
+
+		// VSchema is the vschema of the keyspace with the recommended vindexes
+		VSchema json.RawMessage
+	}
+
+	// TableVindexAdvice is the primary vindex recommended for a table
+	TableVindexAdvice struct {
+		Table  string
+		Column string
+		Vindex string
+
+		// Queries is the number of queries using the table
+		Queries            int
+		SingleShardQueries int
+		AvgShards          float64
+	}
+
+	// LookupVindexAdvice is a lookup vindex recommended for a table
+	LookupVindexAdvice struct {
+		Table       string
+		Column      string
+		Vindex      string
+		LookupTable string
+
+		// SingleShardQueries is the number of queries the lookup vindex sends to a single shard,
+		// that would be sent to more shards without it
+		SingleShardQueries int
+	}
+
+	advisorTable struct {
+		name    string
+		columns []string
+		types   map[string]querypb.Type
+		pk      []string
+		unique  map[string]bool
+	}
+
+	advisorQuery struct {
+		sql    string
+		count  int
+		tables []string
+	}
+
+	// advisorLookup is a lookup vindex on the column of a table
+	advisorLookup struct {
+		table, column string
+	}
+
+	// advisorResult is how a query is routed with a given vschema
+	advisorResult struct {
+		shards int
+		failed bool
+	}
+
+	vindexAdvisor struct {
+		env           *vtenv.Environment
+		opts          *Options
+		keyspace      string
+		schema        string
+		srvTopoCounts *stats.CountersWithSingleLabel
+
+		tables  map[string]*advisorTable
+		queries []*advisorQuery
+
+		// the primary vindex column of each table, and the lookup vindexes
+		primary map[string]string
+		lookups []advisorLookup
+		results []advisorResult
+	}
+)
+
+// AdviseVindexes recommends a primary vindex for each table of the schema, and lookup vindexes
+// for other columns the queries filter on. The keyspace is simulated with the candidate vindexes,
+// and the candidates are scored by how many queries of the corpus are sent to a single shard,
+// and then by the number of shards the other queries are sent to.
+// The queries that look up the lookup vindexes are not counted.
+func AdviseVindexes(ctx context.Context, env *vtenv.Environment, sqlSchema string, queries []string, keyspace string, opts *Options, srvTopoCounts *stats.CountersWithSingleLabel) (*VindexAdvice, error) {
+	va := &vindexAdvisor{
+		env:           env,
+		opts:          opts,
+		keyspace:      keyspace,
+		schema:        sqlSchema,
+		srvTopoCounts: srvTopoCounts,
+		tables:        map[string]*advisorTable{},
+		primary:       map[string]string{},
+	}
+	if err := va.loadSchema(); err != nil {
+		return nil, err
+	}
+	if err := va.loadQueries(queries); err != nil {
+		return nil, err
+	}
+
+	for name, table := range va.tables {
+		va.primary[name] = table.columns[0]
+		if len(table.pk) > 0 {
+			va.primary[name] = table.pk[0]
+		}
+	}
+	candidates, err := va.candidateColumns(ctx)
+	if err != nil {
+		return nil, err
+	}
+	va.results, err = va.evaluate(ctx, va.primary, nil, va.allQueries())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range va.tableNames() {
+		if err := va.choosePrimaryVindex(ctx, name, candidates[name]); err != nil {
+			return nil, err
+		}
+	}
+
+	var lookupAdvice []*LookupVindexAdvice
+	for _, name := range va.tableNames() {
+		for _, column := range candidates[name] {
+			if column == va.primary[name] {
+				continue
+			}
+			advice, err := va.tryLookupVindex(ctx, advisorLookup{table: name, column: column})
+			if err != nil {
+				return nil, err
+			}
+			if advice != nil {
+				lookupAdvice = append(lookupAdvice, advice)
+			}
+		}
+	}
+
+	return va.advice(lookupAdvice)
+}
+
+func (va *vindexAdvisor) loadSchema() error {
+	ddls, err := parseSchema(va.schema, va.opts, va.env.Parser())
+	if err != nil {
+		return fmt.Errorf("parseSchema: %v", err)
+	}
+	for _, ddl := range ddls {
+		spec := ddl.GetTableSpec()
+		if spec == nil || len(spec.Columns) == 0 {
+			continue
+		}
+		table := &advisorTable{
+			name:   ddl.GetTable().Name.String(),
+			types:  map[string]querypb.Type{},
+			unique: map[string]bool{},
+		}
+		for _, col := range spec.Columns {
+			name := col.Name.Lowered()
+			table.columns = append(table.columns, name)
+			table.types[name] = col.Type.SQLType()
+			if col.Type.Options == nil {
+				continue
+			}
+			switch col.Type.Options.KeyOpt {
+			case sqlparser.ColKeyPrimary:
+				table.pk = append(table.pk, name)
+				table.unique[name] = true
+			case sqlparser.ColKeyUnique, sqlparser.ColKeyUniqueKey:
+				table.unique[name] = true
+			}
+		}
+		for _, idx := range spec.Indexes {
+			if idx.Info.Type != sqlparser.IndexTypePrimary && idx.Info.Type != sqlparser.IndexTypeUnique {
+				continue
+			}
+			if len(idx.Columns) == 1 {
+				table.unique[idx.Columns[0].Column.Lowered()] = true
+			}
+			if idx.Info.Type == sqlparser.IndexTypePrimary {
+				for _, col := range idx.Columns {
+					table.pk = append(table.pk, col.Column.Lowered())
+				}
+			}
+		}
+		va.tables[table.name] = table
+	}
+	if len(va.tables) == 0 {
+		return fmt.Errorf("no tables found in the schema")
+	}
+	return nil
+}
+
+// loadQueries parses the queries, and merges the repeated ones
+func (va *vindexAdvisor) loadQueries(queries []string) error {
+	seen := map[string]*advisorQuery{}
+	for _, sql := range queries {
+		stmt, err := va.env.Parser().Parse(sql)
+		if err != nil {
+			log.Warningf("skipping query %s: %v", sql, err)
+			continue
+		}
+		sql = sqlparser.String(stmt)
+		if q, ok := seen[sql]; ok {
+			q.count++
+			continue
+		}
+		q := &advisorQuery{sql: sql, count: 1}
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if tbl, ok := node.(sqlparser.TableName); ok && !slices.Contains(q.tables, tbl.Name.String()) {
+				q.tables = append(q.tables, tbl.Name.String())
+			}
+			return true, nil
+		}, stmt)
+		seen[sql] = q
+		va.queries = append(va.queries, q)
+	}
+	if len(va.queries) == 0 {
+		return fmt.Errorf("no queries to analyze")
+	}
+	return nil
+}
+
+// candidateColumns uses VEXPLAIN KEYS to find the columns of each table that queries
+// compare for equality, in filters and in joins. The primary key is always a candidate.
+// The columns used by the most queries come first.
+func (va *vindexAdvisor) candidateColumns(ctx context.Context) (map[string][]string, error) {
+	vte, stop, err := va.initExplain(ctx, va.primary, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	uses := map[string]map[string]int{}
+	for name, table := range va.tables {
+		uses[name] = map[string]int{}
+		if len(table.pk) > 0 {
+			uses[name][table.pk[0]] = 0
+		}
+	}
+	for _, q := range va.queries {
+		session := vtgate.NewSafeSession(vte.vtgateSession)
+		qr, err := vte.vtgateExecutor.Execute(ctx, nil, "VtexplainAdvisor", session, "vexplain keys "+q.sql, nil)
+		if err != nil || len(qr.Rows) != 1 {
+			log.Warningf("could not get the columns used by %s: %v", q.sql, err)
+			continue
+		}
+		var keys struct {
+			JoinColumns   []string `json:"joinColumns"`
+			FilterColumns []string `json:"filterColumns"`
+		}
+		if err := json.Unmarshal(qr.Rows[0][0].Raw(), &keys); err != nil {
+			return nil, err
+		}
+		for _, use := range append(keys.JoinColumns, keys.FilterColumns...) {
+			column, op, _ := strings.Cut(use, " ")
+			table, column, _ := strings.Cut(column, ".")
+			if op != sqlparser.EqualStr && op != sqlparser.InStr {
+				continue
+			}
+			if t, ok := va.tables[table]; ok && t.types[column] != querypb.Type_NULL_TYPE {
+				uses[table][column] += q.count
+			}
+		}
+	}
+	vte.vtgateExecutor.ClearPlans()
+
+	candidates := map[string][]string{}
+	for name, columns := range uses {
+		for column := range columns {
+			candidates[name] = append(candidates[name], column)
+		}
+		slices.SortFunc(candidates[name], func(a, b string) int {
+			if columns[a] != columns[b] {
+				return columns[b] - columns[a]
+			}
+			return strings.Compare(a, b)
+		})
+	}
+	return candidates, nil
+}
+
+// choosePrimaryVindex tries the candidate columns of the table as its primary vindex, and keeps the best one
+func (va *vindexAdvisor) choosePrimaryVindex(ctx context.Context, table string, candidates []string) error {
+	queries := va.queriesUsing(table)
+	for _, column := range candidates {
+		if column == va.primary[table] {
+			continue
+		}
+		primary := maps.Clone(va.primary)
+		primary[table] = column
+		results, err := va.evaluate(ctx, primary, va.lookups, queries)
+		if err != nil {
+			return err
+		}
+		if va.isBetter(results, queries) {
+			va.primary = primary
+			va.applyResults(results, queries)
+		}
+	}
+	return nil
+}
+
+// tryLookupVindex adds the lookup vindex if it sends more queries to a single shard
+func (va *vindexAdvisor) tryLookupVindex(ctx context.Context, lookup advisorLookup) (*LookupVindexAdvice, error) {
+	queries := va.queriesUsing(lookup.table)
+	lookups := append(slices.Clone(va.lookups), lookup)
+	results, err := va.evaluate(ctx, va.primary, lookups, queries)
+	if err != nil {
+		return nil, err
+	}
+	before := va.singleShard(va.results, queries)
+	after := va.singleShard(results, queries)
+	if after <= before {
+		return nil, nil
+	}
+	va.lookups = lookups
+	va.applyResults(results, queries)
+	return &LookupVindexAdvice{
+		Table:              lookup.table,
+		Column:             lookup.column,
+		Vindex:             va.lookupVindexType(lookup),
+		LookupTable:        lookup.tableName(),
+		SingleShardQueries: after - before,
+	}, nil
+}
+
+// evaluate runs the given queries against a keyspace using the given vindexes
+func (va *vindexAdvisor) evaluate(ctx context.Context, primary map[string]string, lookups []advisorLookup, queries []int) ([]advisorResult, error) {
+	vte, stop, err := va.initExplain(ctx, primary, lookups)
+	if err != nil {
+		return nil, err
+	}
+	defer stop()
+
+	lookupTables := map[string]bool{}
+	for _, lookup := range lookups {
+		lookupTables[lookup.tableName()] = true
+	}
+
+	results := make([]advisorResult, len(va.queries))
+	for _, idx := range queries {
+		explains, err := vte.Run(va.queries[idx].sql)
+		if err != nil {
+			results[idx] = advisorResult{shards: va.opts.NumShards, failed: true}
+			continue
+		}
+		for _, actions := range explains[0].TabletActions {
+			if va.usesTables(actions, lookupTables) {
+				results[idx].shards++
+			}
+		}
+	}
+	return results, nil
+}
+
+// usesTables returns true if any query sent to the tablet uses a table that is not a lookup table
+func (va *vindexAdvisor) usesTables(actions *TabletActions, lookupTables map[string]bool) bool {
+	for _, q := range actions.TabletQueries {
+		stmt, err := va.env.Parser().Parse(q.SQL)
+		if err != nil {
+			return true
+		}
+		found := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if tbl, ok := node.(sqlparser.TableName); ok && !tbl.IsEmpty() && !lookupTables[tbl.Name.String()] {
+				found = true
+			}
+			return !found, nil
+		}, stmt)
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
+func (va *vindexAdvisor) initExplain(ctx context.Context, primary map[string]string, lookups []advisorLookup) (*VTExplain, func(), error) {
+	vschemaStr, err := json2.MarshalPB(va.vschema(primary, lookups))
+	if err != nil {
+		return nil, nil, err
+	}
+	schema := va.schema
+	for _, lookup := range lookups {
+		schema += ";\n" + va.lookupTableDDL(lookup)
+	}
+
+	ts := memorytopo.NewServer(ctx, Cell)
+	vte, err := Init(ctx, va.env, ts, fmt.Sprintf("{%q: %s}", va.keyspace, vschemaStr), schema, "", va.opts, va.srvTopoCounts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return vte, vte.Stop, nil
+}
+
+// vschema returns the vschema of the keyspace with the given vindexes
+func (va *vindexAdvisor) vschema(primary map[string]string, lookups []advisorLookup) *vschemapb.Keyspace {
+	ks := &vschemapb.Keyspace{
+		Sharded:  true,
+		Vindexes: map[string]*vschemapb.Vindex{},
+		Tables:   map[string]*vschemapb.Table{},
+	}
+	for name, column := range primary {
+		vindex := primaryVindexType(va.tables[name].types[column])
+		ks.Vindexes[vindex] = &vschemapb.Vindex{Type: vindex}
+		ks.Tables[name] = &vschemapb.Table{
+			ColumnVindexes: []*vschemapb.ColumnVindex{{Column: column, Name: vindex}},
+		}
+	}
+	for _, lookup := range lookups {
+		typ := va.tables[lookup.table].types[lookup.column]
+		vindex := primaryVindexType(typ)
+		ks.Vindexes[vindex] = &vschemapb.Vindex{Type: vindex}
+		ks.Vindexes[lookup.tableName()] = &vschemapb.Vindex{
+			Type: va.lookupVindexType(lookup),
+			Params: map[string]string{
+				"table": fmt.Sprintf("%s.%s", va.keyspace, lookup.tableName()),
+				"from":  lookup.column,
+				"to":    "keyspace_id",
+			},
+			Owner: lookup.table,
+		}
+		table := ks.Tables[lookup.table]
+		table.ColumnVindexes = append(table.ColumnVindexes, &vschemapb.ColumnVindex{Column: lookup.column, Name: lookup.tableName()})
+		ks.Tables[lookup.tableName()] = &vschemapb.Table{
+			ColumnVindexes: []*vschemapb.ColumnVindex{{Column: lookup.column, Name: vindex}},
+		}
+	}
+	return ks
+}
+
+func (va *vindexAdvisor) lookupVindexType(lookup advisorLookup) string {
+	if va.tables[lookup.table].unique[lookup.column] {
+		return "consistent_lookup_unique"
+	}
+	return "consistent_lookup"
+}
+
+// lookupTableDDL returns the CREATE TABLE statement of the table backing the lookup vindex
+func (va *vindexAdvisor) lookupTableDDL(lookup advisorLookup) string {
+	colType := "varbinary(255)"
+	switch typ := va.tables[lookup.table].types[lookup.column]; {
+	case sqltypes.IsIntegral(typ):
+		colType = "bigint"
+	case sqltypes.IsText(typ):
+		colType = "varchar(255)"
+	}
+	pk := sqlescape.EscapeID(lookup.column)
+	if va.lookupVindexType(lookup) == "consistent_lookup" {
+		pk += ", keyspace_id"
+	}
+	return fmt.Sprintf("create table %s (%s %s, keyspace_id varbinary(128), primary key (%s))",
+		sqlescape.EscapeID(lookup.tableName()), sqlescape.EscapeID(lookup.column), colType, pk)
+}
+
+func (lookup advisorLookup) tableName() string {
+	return fmt.Sprintf("%s_%s_lookup", lookup.table, lookup.column)
+}
+
+// primaryVindexType returns the functional vindex to use for a column of the given type
+func primaryVindexType(typ querypb.Type) string {
+	switch {
+	case sqltypes.IsIntegral(typ):
+		return "hash"
+	case sqltypes.IsText(typ):
+		return "unicode_loose_xxhash"
+	default:
+		return "xxhash"
+	}
+}
+
+func (va *vindexAdvisor) tableNames() []string {
+	names := make([]string, 0, len(va.tables))
+	for name := range va.tables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (va *vindexAdvisor) allQueries() []int {
+	queries := make([]int, len(va.queries))
+	for i := range queries {
+		queries[i] = i
+	}
+	return queries
+}
+
+func (va *vindexAdvisor) queriesUsing(table string) []int {
+	var queries []int
+	for i, q := range va.queries {
+		if slices.Contains(q.tables, table) {
+			queries = append(queries, i)
+		}
+	}
+	return queries
+}
+
+// isBetter returns true if the results send more of the queries to a single shard than the current ones,
+// or as many queries to a single shard but the other queries to fewer shards
+func (va *vindexAdvisor) isBetter(results []advisorResult, queries []int) bool {
+	single, current := va.singleShard(results, queries), va.singleShard(va.results, queries)
+	if single != current {
+		return single > current
+	}
+	return va.shards(results, queries) < va.shards(va.results, queries)
+}
+
+func (va *vindexAdvisor) applyResults(results []advisorResult, queries []int) {
+	for _, idx := range queries {
+		va.results[idx] = results[idx]
+	}
+}
+
+func (va *vindexAdvisor) singleShard(results []advisorResult, queries []int) int {
+	count := 0
+	for _, idx := range queries {
+		if !results[idx].failed && results[idx].shards <= 1 {
+			count += va.queries[idx].count
+		}
+	}
+	return count
+}
+
+func (va *vindexAdvisor) shards(results []advisorResult, queries []int) int {
+	count := 0
+	for _, idx := range queries {
+		count += results[idx].shards * va.queries[idx].count
+	}
+	return count
+}
+
+func (va *vindexAdvisor) failed(queries []int) int {
+	count := 0
+	for _, idx := range queries {
+		if va.results[idx].failed {
+			count += va.queries[idx].count
+		}
+	}
+	return count
+}
+
+func (va *vindexAdvisor) count(queries []int) int {
+	count := 0
+	for _, idx := range queries {
+		count += va.queries[idx].count
+	}
+	return count
+}
+
+func (va *vindexAdvisor) advice(lookups []*LookupVindexAdvice) (*VindexAdvice, error) {
+	vschemaJSON, err := json2.MarshalIndentPB(va.vschema(va.primary, va.lookups), "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	all := va.allQueries()
+	advice := &VindexAdvice{
+		Keyspace:           va.keyspace,
+		NumShards:          va.opts.NumShards,
+		Queries:            va.count(all),
+		SingleShardQueries: va.singleShard(va.results, all),
+		FailedQueries:      va.failed(all),
+		AvgShards:          float64(va.shards(va.results, all)) / float64(va.count(all)),
+		LookupVindexes:     lookups,
+		VSchema:            vschemaJSON,
+	}
+	for _, name := range va.tableNames() {
+		column := va.primary[name]
+		queries := va.queriesUsing(name)
+		tableAdvice := &TableVindexAdvice{
+			Table:              name,
+			Column:             column,
+			Vindex:             primaryVindexType(va.tables[name].types[column]),
+			Queries:            va.count(queries),
+			SingleShardQueries: va.singleShard(va.results, queries),
+		}
+		if tableAdvice.Queries > 0 {
+			tableAdvice.AvgShards = float64(va.shards(va.results, queries)) / float64(tableAdvice.Queries)
+		}
+		advice.Tables = append(advice.Tables, tableAdvice)
+	}
+	return advice, nil
+}
+
+// AdviceAsText returns a text representation of the vindex advice
+func AdviceAsText(advice *VindexAdvice) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Keyspace %s with %d shards, %d queries analyzed\n", advice.Keyspace, advice.NumShards, advice.Queries)
+	fmt.Fprintf(&b, "%d queries sent to a single shard, %.2f shards per query on average, %d queries failed\n\n",
+		advice.SingleShardQueries, advice.AvgShards, advice.FailedQueries)
+
+	w := tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(w, "Table\tPrimary Vindex\tQueries\tSingle Shard\tAvg Shards\n")
+	for _, t := range advice.Tables {
+		fmt.Fprintf(w, "%s\t%s(%s)\t%d\t%d\t%.2f\n", t.Table, t.Vindex, t.Column, t.Queries, t.SingleShardQueries, t.AvgShards)
+	}
+	w.Flush()
+
+	if len(advice.LookupVindexes) > 0 {
+		fmt.Fprintf(&b, "\n")
+		w = tabwriter.NewWriter(&b, 0, 8, 2, ' ', 0)
+		fmt.Fprintf(w, "Table\tLookup Vindex\tLookup Table\tNew Single Shard\n")
+		for _, l := range advice.LookupVindexes {
+			fmt.Fprintf(w, "%s\t%s(%s)\t%s\t%d\n", l.Table, l.Vindex, l.Column, l.LookupTable, l.SingleShardQueries)
+		}
+		w.Flush()
+	}
+
+	fmt.Fprintf(&b, "\nVSchema:\n%s\n", advice.VSchema)
+	return b.String()
+}
+
+// AdviceAsJSON returns a json representation of the vindex advice
+func AdviceAsJSON(advice *VindexAdvice) (string, error) {
+	out, err := json.MarshalIndent(advice, "", "    ")
+	if err != nil {
+		return "", err
+	}
+	return string(out) + "\n", nil
+}
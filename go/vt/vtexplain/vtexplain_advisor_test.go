@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/test/utils"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+)
+
+func TestAdviseVindexes(t *testing.T) {
+	ctx := utils.LeakCheckContext(t)
+
+	schema := `
+create table customer (id bigint primary key, name varchar(64), region bigint);
+create table orders (id bigint primary key, customer_id bigint, amount bigint);
+`
+	var queries []string
+	for i := 0; i < 5; i++ {
+		queries = append(queries, "select * from orders where customer_id = 1")
+	}
+	queries = append(queries,
+		"select * from customer where id = 1",
+		"select * from customer where id = 2",
+		"select o.amount from orders o join customer c on o.customer_id = c.id where c.id = 3",
+		"select * from orders where id in (1, 2, 3)",
+		"select * from orders where id = 7",
+		"select count(*) from customer where region = 2",
+	)
+
+	opts := &Options{
+		ReplicationMode: "ROW",
+		NumShards:       4,
+		ExecutionMode:   ModeMulti,
+	}
+	srvTopoCounts := stats.NewCountersWithSingleLabel("", "Resilient srvtopo server operations", "type")
+	advice, err := AdviseVindexes(ctx, vtenv.NewTestEnv(), schema, queries, "ks", opts, srvTopoCounts)
+	require.NoError(t, err)
+
+	assert.Equal(t, 11, advice.Queries)
+	assert.Equal(t, 11, advice.SingleShardQueries)
+	assert.Equal(t, 0, advice.FailedQueries)
+	require.Len(t, advice.Tables, 2)
+	assert.Equal(t, TableVindexAdvice{
+		Table:              "customer",
+		Column:             "id",
+		Vindex:             "hash",
+		Queries:            4,
+		SingleShardQueries: 4,
+		AvgShards:          1,
+	}, *advice.Tables[0])
+	assert.Equal(t, "orders", advice.Tables[1].Table)
+	assert.Equal(t, "customer_id", advice.Tables[1].Column)
+	assert.Equal(t, 8, advice.Tables[1].SingleShardQueries)
+
+	require.Len(t, advice.LookupVindexes, 2)
+	assert.Equal(t, LookupVindexAdvice{
+		Table:              "customer",
+		Column:             "region",
+		Vindex:             "consistent_lookup",
+		LookupTable:        "customer_region_lookup",
+		SingleShardQueries: 1,
+	}, *advice.LookupVindexes[0])
+	assert.Equal(t, LookupVindexAdvice{
+		Table:              "orders",
+		Column:             "id",
+		Vindex:             "consistent_lookup_unique",
+		LookupTable:        "orders_id_lookup",
+		SingleShardQueries: 2,
+	}, *advice.LookupVindexes[1])
+	assert.Contains(t, string(advice.VSchema), `"orders_id_lookup"`)
+
+	text := AdviceAsText(advice)
+	assert.Contains(t, text, "orders    hash(customer_id)  8        8             1.00")
+}
+
+func TestReadQueryLog(t *testing.T) {
+	textLog := strings.Join([]string{
+		"Execute\t127.0.0.1:1234\tuser\t''\t''\t2024-01-01 00:00:00.000000\t2024-01-01 00:00:00.000100\t0.000100\t0.000010\t0.000090\t0.000000\tSELECT\t\"select * from user where id = :vtg1\"\t{\"vtg1\": {\"type\": \"INT64\", \"value\": 5}}\t1\t1\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[\"ks.user\"]\t\"ks\"\t{}",
+		"Execute\t127.0.0.1:1234\tuser\t''\t''\t2024-01-01 00:00:00.000000\t2024-01-01 00:00:00.000100\t0.000100\t0.000010\t0.000090\t0.000000\tBEGIN\t\"begin\"\t{}\t0\t0\t\"\"\t\"PRIMARY\"\t\"\"\tfalse\t[]\t\"ks\"\t{}",
+		`{"Method": "Execute", "StmtType": "SELECT", "SQL": "select * from user where name = :vtg1 and id in ::vtg2", "BindVars": {"vtg1": {"type": "VARCHAR", "value": "4 bytes"}, "vtg2": {"type": "TUPLE", "value": "3 items"}}}`,
+		`{"Method": "Execute", "StmtType": "SELECT", "SQL": "select * from user where name = :vtg1", "BindVars": {"vtg1": {"type": "VARCHAR", "value": "alice"}}}`,
+		`{"Method": "Execute", "StmtType": "UPDATE", "SQL": "update user set name = :vtg1 where id = :vtg2", "BindVars": "[REDACTED]"}`,
+		"not a query log line",
+	}, "\n")
+
+	queries, err := ReadQueryLog(strings.NewReader(textLog), sqlparser.NewTestParser())
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"select * from `user` where id = 5",
+		"select * from `user` where `name` = 1 and id in (1, 2, 3)",
+		"select * from `user` where `name` = 'alice'",
+		"update `user` set `name` = 1 where id = 1",
+	}, queries)
+}
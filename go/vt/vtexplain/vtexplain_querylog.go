@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtexplain
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+)
+
+const (
+	// the positions of the SQL and the bind variables in the text format of the vtgate query log
+	queryLogSQLField      = 12
+	queryLogBindVarsField = 13
+
+	maxQueryLogLineSize = 16 * 1024 * 1024
+)
+
+// loggedBindVar is a bind variable as it is written to the vtgate query log.
+// Unless the log was requested with full bind variables, strings are replaced
+// by their length and tuples by their number of items, so their values are unknown.
+type loggedBindVar struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// ReadQueryLog reads the queries of a vtgate query log, in either the text or the JSON format.
+// Statements other than SELECT, INSERT, UPDATE and DELETE are skipped. The arguments of
+// normalized queries are replaced by the logged bind variables where the log contains
+// their values, and by placeholder values otherwise.
+func ReadQueryLog(r io.Reader, parser *sqlparser.Parser) ([]string, error) {
+	var queries []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, maxQueryLogLineSize)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		sql, bindVars, err := parseQueryLogLine(line)
+		if err != nil {
+			log.Warningf("skipping line %d of the query log: %v", lineNum, err)
+			continue
+		}
+		switch sqlparser.Preview(sql) {
+		case sqlparser.StmtSelect, sqlparser.StmtInsert, sqlparser.StmtUpdate, sqlparser.StmtDelete:
+		default:
+			continue
+		}
+		stmt, err := parser.Parse(sql)
+		if err != nil {
+			log.Warningf("skipping line %d of the query log: %v", lineNum, err)
+			continue
+		}
+		queries = append(queries, sqlparser.String(replaceLoggedBindVars(stmt, bindVars)))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// parseQueryLogLine returns the SQL and the bind variables of a line of the query log
+func parseQueryLogLine(line string) (string, map[string]loggedBindVar, error) {
+	var sql string
+	var bindVars json.RawMessage
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			SQL      string
+			BindVars json.RawMessage
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return "", nil, err
+		}
+		sql, bindVars = entry.SQL, entry.BindVars
+	} else {
+		fields := strings.Split(line, "\t")
+		if len(fields) <= queryLogBindVarsField {
+			return "", nil, fmt.Errorf("expected at least %d fields, got %d", queryLogBindVarsField+1, len(fields))
+		}
+		var err error
+		sql, err = strconv.Unquote(fields[queryLogSQLField])
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid SQL field %s: %v", fields[queryLogSQLField], err)
+		}
+		bindVars = json.RawMessage(fields[queryLogBindVarsField])
+	}
+
+	// the bind variables are redacted when the query log is configured to do so
+	var bvs map[string]loggedBindVar
+	if err := json.Unmarshal(bindVars, &bvs); err != nil {
+		bvs = nil
+	}
+	return sql, bvs, nil
+}
+
+// replaceLoggedBindVars replaces the arguments in the statement by literals
+func replaceLoggedBindVars(stmt sqlparser.Statement, bindVars map[string]loggedBindVar) sqlparser.Statement {
+	return sqlparser.Rewrite(stmt, nil, func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case *sqlparser.Argument:
+			cursor.Replace(bindVars[node.Name].literal())
+		case sqlparser.ListArg:
+			bv := bindVars[string(node)]
+			if tuple, ok := bv.tuple(); ok {
+				cursor.Replace(tuple)
+			} else {
+				cursor.Replace(sqlparser.ValTuple{bv.literal()})
+			}
+		}
+		return true
+	}).(sqlparser.Statement)
+}
+
+// literal returns a literal with the logged value of the bind variable
+// when it is known, and a placeholder value if it is not.
+func (bv loggedBindVar) literal() sqlparser.Expr {
+	if tuple, ok := bv.tuple(); ok {
+		return tuple
+	}
+	typ := querypb.Type(querypb.Type_value[bv.Type])
+	switch {
+	case len(bv.Value) == 0:
+	case sqltypes.IsIntegral(typ):
+		return sqlparser.NewIntLiteral(string(bv.Value))
+	case sqltypes.IsFloat(typ):
+		return sqlparser.NewFloatLiteral(string(bv.Value))
+	default:
+		var value string
+		if err := json.Unmarshal(bv.Value, &value); err == nil && !isLoggedLength(value) {
+			return sqlparser.NewStrLiteral(value)
+		}
+	}
+	return sqlparser.NewIntLiteral("1")
+}
+
+// isLoggedLength returns true if the value is the length the query log writes instead of a string,
+// e.g. `4 bytes`. A string that has this very form and was logged with full bind variables is
+// treated as unknown too, which only costs a placeholder value.
+func isLoggedLength(value string) bool {
+	length, found := strings.CutSuffix(value, " bytes")
+	if !found {
+		return false
+	}
+	_, err := strconv.ParseUint(length, 10, 64)
+	return err == nil
+}
+
+// tuple returns a tuple with as many placeholder values as the logged tuple has items
+func (bv loggedBindVar) tuple() (sqlparser.ValTuple, bool) {
+	if bv.Type != querypb.Type_TUPLE.String() {
+		return nil, false
+	}
+	var value string
+	if err := json.Unmarshal(bv.Value, &value); err != nil {
+		return nil, false
+	}
+	items, err := strconv.Atoi(strings.TrimSuffix(value, " items"))
+	if err != nil || items < 1 {
+		return nil, false
+	}
+	tuple := make(sqlparser.ValTuple, 0, items)
+	for i := 1; i <= items; i++ {
+		tuple = append(tuple, sqlparser.NewIntLiteral(strconv.Itoa(i)))
+	}
+	return tuple, true
+}
@@ -0,0 +1,542 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"strings"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/binlog"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+const (
+	// binlogDumpFilePrefix is the prefix of the binlog file names
+	// vtgate reports to replication clients.
+	binlogDumpFilePrefix = "vtgate-bin"
+
+	// binlogDumpMaxFileSize is the size after which the binlog dump switches
+	// to a new file, like max_binlog_size does for MySQL. This keeps the
+	// positions within the 32 bits of the event headers.
+	binlogDumpMaxFileSize = 1 << 30
+
+	// binlogDumpMaxRowsEventSize is the size after which rows are split
+	// into another rows event, like binlog_row_event_max_size does for MySQL.
+	binlogDumpMaxRowsEventSize = 8192
+
+	// binlogDumpHeartbeatInterval is the interval, in seconds, of the
+	// VStream heartbeats, which are sent to the client as heartbeat events.
+	binlogDumpHeartbeatInterval = 30
+
+	// rowsEventStmtEndFlag is STMT_END_F, set on the last rows event
+	// of a statement.
+	rowsEventStmtEndFlag = 0x0001
+)
+
+// binlogDump translates the events of a VStream for a whole keyspace into
+// MySQL binlog events, so that vtgate can act as a replication source.
+//
+// Each transaction keeps the GTID it has on its shard, so the GTID set
+// executed by a client is the union of the positions of all the shards,
+// and it can resume from there. GTIDs the VStream skipped, because they
+// didn't change any row, are sent as empty transactions.
+type binlogDump struct {
+	keyspace string
+	format   mysql.BinlogFormat
+	stream   *mysql.FakeBinlogStream
+	send     func(ev mysql.BinlogEvent) error
+
+	// fileIndex and position are the position of the next event.
+	fileIndex int
+	position  uint32
+
+	// gtids is the set of GTIDs sent so far.
+	gtids replication.Mysql56GTIDSet
+
+	// shardPositions has the last known position of each shard.
+	shardPositions map[string]replication.Mysql56GTIDSet
+
+	// tables are the tables described by FIELD events, by name.
+	tables      map[string]*binlogDumpTable
+	nextTableID uint64
+
+	// gtid is the GTID of the current transaction, nil if it isn't known.
+	gtid *replication.Mysql56GTID
+	// rows are the row events of the current transaction.
+	rows []*binlogdatapb.RowEvent
+}
+
+// binlogDumpTable is a table of the keyspace, with the table map
+// we send for it.
+type binlogDumpTable struct {
+	id       uint64
+	fields   []*querypb.Field
+	tableMap *mysql.TableMap
+}
+
+func newBinlogDump(keyspace, serverVersion string, checksum bool, gtids replication.Mysql56GTIDSet, shardPositions map[string]replication.Mysql56GTIDSet, send func(ev mysql.BinlogEvent) error) *binlogDump {
+	format := mysql.NewMySQL56BinlogFormat()
+	format.ServerVersion = serverVersion
+	if !checksum {
+		format.ChecksumAlgorithm = mysql.BinlogChecksumAlgOff
+	}
+	serverID := crc32.ChecksumIEEE([]byte(keyspace))
+	if serverID == 0 {
+		serverID = 1
+	}
+	return &binlogDump{
+		keyspace: keyspace,
+		format:   format,
+		stream: &mysql.FakeBinlogStream{
+			ServerID: serverID,
+		},
+		send:           send,
+		fileIndex:      1,
+		gtids:          gtids,
+		shardPositions: shardPositions,
+		tables:         make(map[string]*binlogDumpTable),
+	}
+}
+
+func (bd *binlogDump) fileName() string {
+	return fmt.Sprintf("%s.%06d", binlogDumpFilePrefix, bd.fileIndex)
+}
+
+// start sends the events a MySQL server sends at the start of a binlog dump.
+func (bd *binlogDump) start() error {
+	if err := bd.write(mysql.NewFakeRotateEvent(bd.format, bd.stream, bd.fileName()), 0); err != nil {
+		return err
+	}
+	return bd.startFile()
+}
+
+// startFile sends the events at the start of a binlog file.
+func (bd *binlogDump) startFile() error {
+	bd.position = 4
+	if err := bd.writeNext(mysql.NewFormatDescriptionEvent(bd.format, bd.stream)); err != nil {
+		return err
+	}
+	return bd.writeNext(mysql.NewPreviousGTIDsEvent(bd.format, bd.stream, bd.gtids))
+}
+
+// write sends an event, with the given position in its header.
+func (bd *binlogDump) write(ev mysql.BinlogEvent, logPos uint32) error {
+	data := ev.Bytes()
+	binary.LittleEndian.PutUint32(data[13:17], logPos)
+	if bd.format.ChecksumAlgorithm == mysql.BinlogChecksumAlgCRC32 {
+		binary.LittleEndian.PutUint32(data[len(data)-4:], crc32.ChecksumIEEE(data[:len(data)-4]))
+	}
+	return bd.send(ev)
+}
+
+// writeNext sends an event of the binlog file, and advances the position.
+func (bd *binlogDump) writeNext(ev mysql.BinlogEvent) error {
+	bd.position += uint32(len(ev.Bytes()))
+	return bd.write(ev, bd.position)
+}
+
+// rotateIfNeeded switches to the next binlog file once the current one is large enough.
+func (bd *binlogDump) rotateIfNeeded() error {
+	if bd.position < binlogDumpMaxFileSize {
+		return nil
+	}
+	bd.fileIndex++
+	if err := bd.writeNext(mysql.NewRotateEvent(bd.format, bd.stream, 4, bd.fileName())); err != nil {
+		return err
+	}
+	return bd.startFile()
+}
+
+// handle translates the events received from the VStream.
+func (bd *binlogDump) handle(events []*binlogdatapb.VEvent) error {
+	for _, event := range events {
+		if event.Timestamp != 0 {
+			bd.stream.Timestamp = uint32(event.Timestamp)
+		}
+
+		var err error
+		switch event.Type {
+		case binlogdatapb.VEventType_BEGIN:
+			bd.gtid = nil
+			bd.rows = nil
+		case binlogdatapb.VEventType_FIELD:
+			bd.addTable(event.FieldEvent)
+		case binlogdatapb.VEventType_ROW:
+			bd.rows = append(bd.rows, event.RowEvent)
+		case binlogdatapb.VEventType_VGTID:
+			err = bd.updatePosition(event)
+		case binlogdatapb.VEventType_COMMIT:
+			err = bd.commit()
+		case binlogdatapb.VEventType_DDL:
+			err = bd.ddl(event.Statement)
+		case binlogdatapb.VEventType_OTHER:
+			err = bd.emptyTransaction()
+		case binlogdatapb.VEventType_HEARTBEAT:
+			err = bd.write(mysql.NewHeartbeatEventWithLogFile(bd.format, bd.stream, bd.fileName()), bd.position)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addTable records the fields of a table. Its table ID changes every time,
+// as a FIELD event means that the table may have changed.
+func (bd *binlogDump) addTable(fe *binlogdatapb.FieldEvent) {
+	tm := &mysql.TableMap{
+		Database:  bd.keyspace,
+		Name:      strings.TrimPrefix(fe.TableName, bd.keyspace+"."),
+		Types:     make([]byte, len(fe.Fields)),
+		CanBeNull: mysql.NewServerBitmap(len(fe.Fields)),
+		Metadata:  make([]uint16, len(fe.Fields)),
+	}
+	for i, field := range fe.Fields {
+		tm.Types[i], tm.Metadata[i] = binlog.FieldType(field)
+		tm.CanBeNull.Set(i, field.Flags&uint32(querypb.MySqlFlag_NOT_NULL_FLAG) == 0)
+	}
+	bd.nextTableID++
+	bd.tables[fe.TableName] = &binlogDumpTable{
+		id:       bd.nextTableID,
+		fields:   fe.Fields,
+		tableMap: tm,
+	}
+}
+
+// updatePosition finds the GTID of the current transaction, from the
+// difference between the new and the previous position of its shard.
+func (bd *binlogDump) updatePosition(event *binlogdatapb.VEvent) error {
+	var gtid string
+	for _, sgtid := range event.Vgtid.GetShardGtids() {
+		if sgtid.Keyspace == event.Keyspace && sgtid.Shard == event.Shard {
+			gtid = sgtid.Gtid
+			break
+		}
+	}
+	if gtid == "" {
+		return nil
+	}
+	pos, err := replication.DecodePosition(gtid)
+	if err != nil {
+		return err
+	}
+	set, ok := pos.GTIDSet.(replication.Mysql56GTIDSet)
+	if !ok {
+		return vterrors.VT12001(fmt.Sprintf("binlog dump of a %s position", pos.GTIDSet.Flavor()))
+	}
+
+	previous, ok := bd.shardPositions[event.Shard]
+	bd.shardPositions[event.Shard] = set
+	if !ok {
+		// This is a new shard, after a resharding for instance,
+		// so we don't know which GTIDs are new.
+		return nil
+	}
+	gtids := set.Difference(previous).Difference(bd.gtids).GTIDs()
+	if len(gtids) == 0 {
+		return nil
+	}
+	for _, skipped := range gtids[:len(gtids)-1] {
+		bd.gtid = &skipped
+		if err := bd.emptyTransaction(); err != nil {
+			return err
+		}
+	}
+	bd.gtid = &gtids[len(gtids)-1]
+	return nil
+}
+
+// writeGTID sends the GTID event of the current transaction.
+func (bd *binlogDump) writeGTID() error {
+	if bd.gtid == nil {
+		return bd.writeNext(mysql.NewMySQL56AnonymousGTIDEvent(bd.format, bd.stream))
+	}
+	if err := bd.writeNext(mysql.NewMySQL56GTIDEvent(bd.format, bd.stream, *bd.gtid)); err != nil {
+		return err
+	}
+	bd.gtids = bd.gtids.AddGTID(*bd.gtid).(replication.Mysql56GTIDSet)
+	return nil
+}
+
+// begin sends the events that start a transaction.
+func (bd *binlogDump) begin() error {
+	if err := bd.writeGTID(); err != nil {
+		return err
+	}
+	return bd.writeQuery("BEGIN")
+}
+
+// end finishes the current transaction.
+func (bd *binlogDump) end() error {
+	bd.gtid = nil
+	return bd.rotateIfNeeded()
+}
+
+func (bd *binlogDump) writeQuery(sql string) error {
+	return bd.writeNext(mysql.NewQueryEvent(bd.format, bd.stream, mysql.Query{
+		Database: bd.keyspace,
+		SQL:      sql,
+	}))
+}
+
+// emptyTransaction sends a transaction without any row, like MySQL does
+// for the transactions that are filtered out.
+func (bd *binlogDump) emptyTransaction() error {
+	if err := bd.begin(); err != nil {
+		return err
+	}
+	if err := bd.writeQuery("COMMIT"); err != nil {
+		return err
+	}
+	return bd.end()
+}
+
+// ddl sends a DDL, which isn't part of a transaction.
+func (bd *binlogDump) ddl(statement string) error {
+	if err := bd.writeGTID(); err != nil {
+		return err
+	}
+	if err := bd.writeQuery(statement); err != nil {
+		return err
+	}
+	return bd.end()
+}
+
+// commit sends the current transaction: a table map for each of its tables,
+// then its rows.
+func (bd *binlogDump) commit() error {
+	if err := bd.begin(); err != nil {
+		return err
+	}
+
+	var events []mysql.BinlogEvent
+	mapped := make(map[*binlogDumpTable]bool)
+	for _, re := range bd.rows {
+		table, ok := bd.tables[re.TableName]
+		if !ok {
+			return vterrors.Errorf(vtrpcpb.Code_INTERNAL, "no fields received for table %s", re.TableName)
+		}
+		if !mapped[table] {
+			mapped[table] = true
+			if err := bd.writeNext(mysql.NewTableMapEvent(bd.format, bd.stream, table.id, table.tableMap)); err != nil {
+				return err
+			}
+		}
+		rowsEvents, err := bd.rowsEvents(table, re.RowChanges)
+		if err != nil {
+			return err
+		}
+		events = append(events, rowsEvents...)
+	}
+	for i, ev := range events {
+		if i == len(events)-1 {
+			// Table maps are only valid until the end of the statement,
+			// so the whole transaction is a single statement.
+			data := ev.Bytes()
+			flags := binary.LittleEndian.Uint16(data[bd.format.HeaderLength+6:])
+			binary.LittleEndian.PutUint16(data[bd.format.HeaderLength+6:], flags|rowsEventStmtEndFlag)
+		}
+		if err := bd.writeNext(ev); err != nil {
+			return err
+		}
+	}
+
+	if err := bd.writeNext(mysql.NewXIDEvent(bd.format, bd.stream)); err != nil {
+		return err
+	}
+	bd.rows = nil
+	return bd.end()
+}
+
+type rowsEventKind int
+
+const (
+	writeRows rowsEventKind = iota
+	updateRows
+	deleteRows
+)
+
+// rowsEvents translates row changes into rows events. Consecutive changes
+// of the same kind are sent in the same event, up to binlogDumpMaxRowsEventSize.
+func (bd *binlogDump) rowsEvents(table *binlogDumpTable, changes []*binlogdatapb.RowChange) ([]mysql.BinlogEvent, error) {
+	var events []mysql.BinlogEvent
+	var rows mysql.Rows
+	var kind rowsEventKind
+	size := 0
+
+	flush := func() {
+		if len(rows.Rows) == 0 {
+			return
+		}
+		switch kind {
+		case writeRows:
+			events = append(events, mysql.NewWriteRowsEvent(bd.format, bd.stream, table.id, rows))
+		case updateRows:
+			events = append(events, mysql.NewUpdateRowsEvent(bd.format, bd.stream, table.id, rows))
+		case deleteRows:
+			events = append(events, mysql.NewDeleteRowsEvent(bd.format, bd.stream, table.id, rows))
+		}
+		rows.Rows = nil
+		size = 0
+	}
+
+	for _, change := range changes {
+		changeKind := updateRows
+		switch {
+		case change.Before == nil:
+			changeKind = writeRows
+		case change.After == nil:
+			changeKind = deleteRows
+		}
+		if len(rows.Rows) == 0 || changeKind != kind || size >= binlogDumpMaxRowsEventSize {
+			flush()
+			kind = changeKind
+			rows = bd.newRows(table, kind)
+		}
+
+		var row mysql.Row
+		var err error
+		if change.Before != nil {
+			row.NullIdentifyColumns, row.Identify, err = bd.encodeRow(table, change.Before)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if change.After != nil {
+			row.NullColumns, row.Data, err = bd.encodeRow(table, change.After)
+			if err != nil {
+				return nil, err
+			}
+		}
+		rows.Rows = append(rows.Rows, row)
+		size += len(row.Identify) + len(row.Data)
+	}
+	flush()
+	return events, nil
+}
+
+// newRows returns the Rows of an event that has all the columns of the table.
+func (bd *binlogDump) newRows(table *binlogDumpTable, kind rowsEventKind) mysql.Rows {
+	allColumns := func() mysql.Bitmap {
+		bitmap := mysql.NewServerBitmap(len(table.fields))
+		for i := range table.fields {
+			bitmap.Set(i, true)
+		}
+		return bitmap
+	}
+
+	var rows mysql.Rows
+	if kind != writeRows {
+		rows.IdentifyColumns = allColumns()
+	}
+	if kind != deleteRows {
+		rows.DataColumns = allColumns()
+	}
+	return rows
+}
+
+// encodeRow returns the NULL bitmap and the binlog representation of a row.
+func (bd *binlogDump) encodeRow(table *binlogDumpTable, row *querypb.Row) (mysql.Bitmap, []byte, error) {
+	values := sqltypes.MakeRowTrusted(table.fields, row)
+	if len(values) != len(table.fields) {
+		return mysql.Bitmap{}, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "row of table %s has %d values, expected %d", table.tableMap.Name, len(values), len(table.fields))
+	}
+
+	nulls := mysql.NewServerBitmap(len(values))
+	var data []byte
+	for i, value := range values {
+		if value.IsNull() {
+			nulls.Set(i, true)
+			continue
+		}
+		var err error
+		data, err = binlog.AppendCellValue(data, value, table.tableMap.Types[i], table.tableMap.Metadata[i])
+		if err != nil {
+			return mysql.Bitmap{}, nil, vterrors.Wrapf(err, "column %s of table %s", table.fields[i].Name, table.tableMap.Name)
+		}
+	}
+	return nulls, data, nil
+}
+
+// binlogDumpPositions returns the position each shard of the keyspace starts
+// streaming from. When the client doesn't have a GTID set, they start from
+// their current position, which we read from their gtid_executed.
+// Otherwise they all start from the client's GTID set, as a MySQL server
+// only sends the transactions of that set it hasn't executed.
+func (vh *vtgateHandler) binlogDumpPositions(ctx context.Context, keyspace string, tabletType topodatapb.TabletType, gtids replication.Mysql56GTIDSet) (*binlogdatapb.VGtid, map[string]replication.Mysql56GTIDSet, replication.Mysql56GTIDSet, error) {
+	rss, _, err := vh.vtg.resolver.resolver.GetAllShards(ctx, keyspace, tabletType)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	vgtid := &binlogdatapb.VGtid{}
+	shardPositions := make(map[string]replication.Mysql56GTIDSet, len(rss))
+	current := len(gtids) == 0
+	if current {
+		gtids = replication.Mysql56GTIDSet{}
+	}
+	for _, rs := range rss {
+		set := gtids
+		if current {
+			qr, err := rs.Gateway.Execute(ctx, rs.Target, "select @@global.gtid_executed", nil, 0, 0, nil)
+			if err != nil {
+				return nil, nil, nil, err
+			}
+			if len(qr.Rows) != 1 || len(qr.Rows[0]) != 1 {
+				return nil, nil, nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "unexpected result for gtid_executed on %s/%s: %v", keyspace, rs.Target.Shard, qr.Rows)
+			}
+			set, err = replication.ParseMysql56GTIDSet(qr.Rows[0][0].ToString())
+			if err != nil {
+				return nil, nil, nil, err
+			}
+		}
+		shardPositions[rs.Target.Shard] = set
+		vgtid.ShardGtids = append(vgtid.ShardGtids, &binlogdatapb.ShardGtid{
+			Keyspace: keyspace,
+			Shard:    rs.Target.Shard,
+			Gtid:     replication.EncodePosition(replication.Position{GTIDSet: set}),
+		})
+	}
+	if current {
+		for _, set := range shardPositions {
+			gtids = gtids.Union(set).(replication.Mysql56GTIDSet)
+		}
+	}
+	return vgtid, shardPositions, gtids, nil
+}
+
+// binlogDumpChecksum returns true if the client asked for checksums,
+// as MySQL only sends them to clients that set @source_binlog_checksum.
+func binlogDumpChecksum(session *vtgatepb.Session) bool {
+	for _, name := range []string{"source_binlog_checksum", "master_binlog_checksum"} {
+		if bv, ok := session.UserDefinedVariables[name]; ok {
+			return strings.EqualFold(string(bv.GetValue()), "CRC32")
+		}
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql"
+	"vitess.io/vitess/go/mysql/replication"
+	"vitess.io/vitess/go/sqltypes"
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+func TestBinlogDump(t *testing.T) {
+	const sid = "00010203-0405-0607-0809-0a0b0c0d0e0f"
+	start, err := replication.ParseMysql56GTIDSet(sid + ":1-5")
+	require.NoError(t, err)
+
+	var events []mysql.BinlogEvent
+	bd := newBinlogDump("ks", "8.0.30-Vitess", true, start, map[string]replication.Mysql56GTIDSet{"-80": start}, func(ev mysql.BinlogEvent) error {
+		events = append(events, ev)
+		return nil
+	})
+	require.NoError(t, bd.start())
+
+	vgtid := func(gtids string) *binlogdatapb.VEvent {
+		return &binlogdatapb.VEvent{
+			Type:     binlogdatapb.VEventType_VGTID,
+			Keyspace: "ks",
+			Shard:    "-80",
+			Vgtid: &binlogdatapb.VGtid{ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: "ks",
+				Shard:    "-80",
+				Gtid:     "MySQL56/" + sid + ":" + gtids,
+			}}},
+		}
+	}
+	fields := []*querypb.Field{
+		{Name: "id", Type: querypb.Type_INT64, Flags: uint32(querypb.MySqlFlag_NOT_NULL_FLAG)},
+		{Name: "name", Type: querypb.Type_VARCHAR, ColumnLength: 256},
+	}
+	row := func(values ...sqltypes.Value) *querypb.Row {
+		return sqltypes.RowToProto3(values)
+	}
+	err = bd.handle([]*binlogdatapb.VEvent{
+		{Type: binlogdatapb.VEventType_BEGIN, Timestamp: 1700000000},
+		{Type: binlogdatapb.VEventType_FIELD, FieldEvent: &binlogdatapb.FieldEvent{TableName: "ks.t1", Fields: fields}},
+		{Type: binlogdatapb.VEventType_ROW, RowEvent: &binlogdatapb.RowEvent{TableName: "ks.t1", RowChanges: []*binlogdatapb.RowChange{
+			{After: row(sqltypes.NewInt64(1), sqltypes.NewVarChar("a"))},
+			{After: row(sqltypes.NewInt64(2), sqltypes.NULL)},
+			{Before: row(sqltypes.NewInt64(1), sqltypes.NewVarChar("a")), After: row(sqltypes.NewInt64(1), sqltypes.NewVarChar("b"))},
+		}}},
+		// GTID 6 didn't change any row, so it is sent as an empty transaction.
+		vgtid("1-7"),
+		{Type: binlogdatapb.VEventType_COMMIT},
+		vgtid("1-8"),
+		{Type: binlogdatapb.VEventType_DDL, Statement: "alter table t1 add column c int"},
+	})
+	require.NoError(t, err)
+
+	f := bd.format
+	var types []string
+	for _, ev := range events {
+		require.True(t, ev.IsValid())
+		// Rotate and Format Description events are parsed with their checksum.
+		switch {
+		case ev.IsRotate():
+			types = append(types, "rotate")
+			file, pos, err := ev.NextLogFile(f)
+			require.NoError(t, err)
+			assert.Equal(t, "vtgate-bin.000001", file)
+			assert.EqualValues(t, 4, pos)
+			continue
+		case ev.IsFormatDescription():
+			types = append(types, "format")
+			format, err := ev.Format()
+			require.NoError(t, err)
+			assert.EqualValues(t, mysql.BinlogChecksumAlgCRC32, format.ChecksumAlgorithm)
+			continue
+		}
+
+		ev, _, err := ev.StripChecksum(f)
+		require.NoError(t, err)
+		switch {
+		case ev.IsPreviousGTIDs():
+			types = append(types, "previous_gtids")
+			pos, err := ev.PreviousGTIDs(f)
+			require.NoError(t, err)
+			assert.True(t, start.Equal(pos.GTIDSet))
+		case ev.IsGTID():
+			gtid, _, err := ev.GTID(f)
+			require.NoError(t, err)
+			types = append(types, "gtid "+gtid.String())
+		case ev.IsQuery():
+			q, err := ev.Query(f)
+			require.NoError(t, err)
+			assert.Equal(t, "ks", q.Database)
+			types = append(types, q.SQL)
+		case ev.IsTableMap():
+			tm, err := ev.TableMap(f)
+			require.NoError(t, err)
+			types = append(types, "table_map "+tm.Database+"."+tm.Name)
+		case ev.IsWriteRows():
+			tm := bd.tables["ks.t1"].tableMap
+			rows, err := ev.Rows(f, tm)
+			require.NoError(t, err)
+			require.Len(t, rows.Rows, 2)
+			values, err := rows.StringValuesForTests(tm, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1", "a"}, values)
+			values, err = rows.StringValuesForTests(tm, 1)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"2", "NULL"}, values)
+			types = append(types, "write_rows")
+		case ev.IsUpdateRows():
+			tm := bd.tables["ks.t1"].tableMap
+			rows, err := ev.Rows(f, tm)
+			require.NoError(t, err)
+			assert.NotZero(t, rows.Flags&rowsEventStmtEndFlag)
+			identify, err := rows.StringIdentifiesForTests(tm, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1", "a"}, identify)
+			values, err := rows.StringValuesForTests(tm, 0)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"1", "b"}, values)
+			types = append(types, "update_rows")
+		case ev.IsXID():
+			types = append(types, "xid")
+		default:
+			types = append(types, "unexpected")
+		}
+	}
+
+	assert.Equal(t, []string{
+		"rotate", "format", "previous_gtids",
+		"gtid " + sid + ":6", "BEGIN", "COMMIT",
+		"gtid " + sid + ":7", "BEGIN", "table_map ks.t1", "write_rows", "update_rows", "xid",
+		"gtid " + sid + ":8", "alter table t1 add column c int",
+	}, types)
+
+	// The events are consecutive in the binlog file.
+	pos := uint32(4)
+	for _, ev := range events[1:] {
+		pos += uint32(len(ev.Bytes()))
+		assert.Equal(t, pos, ev.NextPosition())
+	}
+
+	want, err := replication.ParseMysql56GTIDSet(sid + ":1-8")
+	require.NoError(t, err)
+	assert.True(t, want.Equal(bd.gtids), "got %v, want %v", bd.gtids, want)
+}
+
+func TestBinlogDumpChecksum(t *testing.T) {
+	session := &vtgatepb.Session{}
+	assert.False(t, binlogDumpChecksum(session))
+
+	session.UserDefinedVariables = map[string]*querypb.BindVariable{
+		"master_binlog_checksum": sqltypes.StringBindVariable("crc32"),
+	}
+	assert.True(t, binlogDumpChecksum(session))
+
+	session.UserDefinedVariables = map[string]*querypb.BindVariable{
+		"source_binlog_checksum": sqltypes.StringBindVariable("NONE"),
+	}
+	assert.False(t, binlogDumpChecksum(session))
+}
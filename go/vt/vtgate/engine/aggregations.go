@@ -17,7 +17,9 @@ limitations under the License.
 package engine
 
 import (
+	"bytes"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -50,6 +52,18 @@ type AggregateParams struct {
 	// not what we use to aggregate at the engine primitive level.
 	OrigOpcode AggregateOpcode
 
+	// GroupingKeys is only used by the GROUPING() function. It contains the
+	// indexes of the group by keys that are passed as arguments to the function.
+	GroupingKeys []int `json:",omitempty"`
+
+	// ExtraCols contains the input columns of the arguments after the first one,
+	// for aggregations such as GROUP_CONCAT(a, b) and COUNT(DISTINCT a, b).
+	ExtraCols []int `json:",omitempty"`
+
+	// OrderBy is only used by GROUP_CONCAT, and contains the ordering
+	// that is used inside the function call.
+	OrderBy evalengine.Comparison `json:",omitempty"`
+
 	CollationEnv *collations.Environment
 }
 
@@ -79,6 +93,12 @@ func (ap *AggregateParams) String() string {
 	if sqltypes.IsText(ap.Type.Type()) && ap.CollationEnv.IsSupported(ap.Type.Collation()) {
 		keyCol += " COLLATE " + ap.CollationEnv.LookupName(ap.Type.Collation())
 	}
+	for _, col := range ap.ExtraCols {
+		keyCol += ", " + strconv.Itoa(col)
+	}
+	if len(ap.OrderBy) > 0 {
+		keyCol += " order by " + GenericJoin(ap.OrderBy, orderByParamsToString)
+	}
 	dispOrigOp := ""
 	if ap.OrigOpcode != AggregateUnassigned && ap.OrigOpcode != ap.Opcode {
 		dispOrigOp = "_" + ap.OrigOpcode.String()
@@ -134,14 +154,80 @@ func (a *aggregatorDistinct) reset() {
 	a.last = sqltypes.NULL
 }
 
+// distinctFilter decides if a row should be skipped by a distinct aggregation
+type distinctFilter interface {
+	shouldReturn(row []sqltypes.Value) (bool, error)
+	reset()
+}
+
+// aggregatorDistinctSet is used by distinct aggregations over more than one column,
+// and by GROUP_CONCAT(DISTINCT ...). The input is not sorted by these columns,
+// so it keeps track of the tuple weight strings it has already seen in the current group.
+type aggregatorDistinctSet struct {
+	columns []int
+	types   []evalengine.Type
+	seen    map[string]struct{}
+
+	values []sqltypes.Value
+	buf    []byte
+}
+
+func newDistinctSet(fields []*querypb.Field, columns []int) *aggregatorDistinctSet {
+	types := make([]evalengine.Type, 0, len(columns))
+	for _, col := range columns {
+		types = append(types, evalengine.NewTypeFromField(fields[col]))
+	}
+	return &aggregatorDistinctSet{
+		columns: columns,
+		types:   types,
+		seen:    make(map[string]struct{}),
+		values:  make([]sqltypes.Value, len(columns)),
+	}
+}
+
+func (a *aggregatorDistinctSet) shouldReturn(row []sqltypes.Value) (bool, error) {
+	for i, col := range a.columns {
+		a.values[i] = row[col]
+	}
+	var err error
+	a.buf, err = evalengine.TupleWeightString(a.buf[:0], a.values, a.types, 0)
+	if err != nil {
+		return true, err
+	}
+	if _, found := a.seen[string(a.buf)]; found {
+		return true, nil
+	}
+	a.seen[string(a.buf)] = struct{}{}
+	return false, nil
+}
+
+func (a *aggregatorDistinctSet) reset() {
+	clear(a.seen)
+}
+
+// hasNull returns true if any of the given columns is NULL in the row.
+// Aggregations over several arguments ignore these rows, like MySQL does.
+func hasNull(row []sqltypes.Value, from int, extra []int) bool {
+	if row[from].IsNull() {
+		return true
+	}
+	for _, col := range extra {
+		if row[col].IsNull() {
+			return true
+		}
+	}
+	return false
+}
+
 type aggregatorCount struct {
 	from     int
+	extra    []int
 	n        int64
-	distinct aggregatorDistinct
+	distinct distinctFilter
 }
 
 func (a *aggregatorCount) add(row []sqltypes.Value) error {
-	if row[a.from].IsNull() {
+	if hasNull(row, a.from, a.extra) {
 		return nil
 	}
 	if ret, err := a.distinct.shouldReturn(row); ret {
@@ -209,7 +295,7 @@ func (a *aggregatorMinMax) reset() {
 type aggregatorSum struct {
 	from     int
 	sum      evalengine.Sum
-	distinct aggregatorDistinct
+	distinct distinctFilter
 }
 
 func (a *aggregatorSum) add(row []sqltypes.Value) error {
@@ -255,26 +341,75 @@ func (a *aggregatorScalar) reset() {
 }
 
 type aggregatorGroupConcat struct {
-	from  int
-	type_ sqltypes.Type
+	from      int
+	extra     []int
+	type_     sqltypes.Type
+	separator []byte
+	distinct  distinctFilter
+	orderBy   evalengine.Comparison
 
 	concat []byte
 	n      int
+
+	// sorted contains the values of the group when GROUP_CONCAT uses ORDER BY,
+	// the values are only concatenated once all of them have been seen
+	sorted []groupConcatValue
+}
+
+type groupConcatValue struct {
+	key   []byte
+	value []byte
 }
 
 func (a *aggregatorGroupConcat) add(row []sqltypes.Value) error {
-	if row[a.from].IsNull() {
+	if hasNull(row, a.from, a.extra) {
+		return nil
+	}
+	if a.distinct != nil {
+		if ret, err := a.distinct.shouldReturn(row); ret {
+			return err
+		}
+	}
+	if a.orderBy != nil {
+		key, err := appendOrderKey(nil, row, a.orderBy)
+		if err != nil {
+			return err
+		}
+		a.sorted = append(a.sorted, groupConcatValue{key: key, value: a.appendValue(nil, row)})
 		return nil
 	}
 	if a.n > 0 {
-		a.concat = append(a.concat, ',')
+		a.concat = append(a.concat, a.separator...)
 	}
-	a.concat = append(a.concat, row[a.from].Raw()...)
+	a.concat = a.appendValue(a.concat, row)
 	a.n++
 	return nil
 }
 
+// appendValue appends the arguments of the function for this row to dst.
+// Several arguments are concatenated without any separator between them.
+func (a *aggregatorGroupConcat) appendValue(dst []byte, row []sqltypes.Value) []byte {
+	dst = append(dst, row[a.from].Raw()...)
+	for _, col := range a.extra {
+		dst = append(dst, row[col].Raw()...)
+	}
+	return dst
+}
+
 func (a *aggregatorGroupConcat) finish() sqltypes.Value {
+	if len(a.sorted) > 0 {
+		slices.SortStableFunc(a.sorted, func(x, y groupConcatValue) int {
+			return bytes.Compare(x.key, y.key)
+		})
+		for _, v := range a.sorted {
+			if a.n > 0 {
+				a.concat = append(a.concat, a.separator...)
+			}
+			a.concat = append(a.concat, v.value...)
+			a.n++
+		}
+		a.sorted = nil
+	}
 	if a.n == 0 {
 		return sqltypes.NULL
 	}
@@ -284,8 +419,60 @@ func (a *aggregatorGroupConcat) finish() sqltypes.Value {
 func (a *aggregatorGroupConcat) reset() {
 	a.n = 0
 	a.concat = nil // not safe to reuse this byte slice as it's returned as MakeTrusted
+	a.sorted = nil
+	if a.distinct != nil {
+		a.distinct.reset()
+	}
+}
+
+// appendOrderKey appends a key for the ORDER BY values of the row to dst.
+// Comparing two keys bytewise gives the same result as comparing the rows using the ordering.
+func appendOrderKey(dst []byte, row []sqltypes.Value, orderBy evalengine.Comparison) ([]byte, error) {
+	for _, ob := range orderBy {
+		start := len(dst)
+		v := row[ob.Col]
+		if v.IsNull() {
+			// NULL values come first when sorting in ascending order
+			dst = append(dst, 0)
+		} else {
+			ws, _, err := evalengine.WeightString(nil, v, ob.Type.Type(), ob.Type.Collation(), 0, 0, ob.Type.Values(), 0)
+			if err != nil {
+				return nil, err
+			}
+			// zero bytes are escaped, and the weight string is terminated with two zero bytes,
+			// so that a weight string sorts before all the longer weight strings that start with it
+			dst = append(dst, 1)
+			for _, b := range ws {
+				dst = append(dst, b)
+				if b == 0 {
+					dst = append(dst, 0xff)
+				}
+			}
+			dst = append(dst, 0, 0)
+		}
+		if ob.Desc {
+			for i := start; i < len(dst); i++ {
+				dst[i] = ^dst[i]
+			}
+		}
+	}
+	return dst, nil
+}
+
+type aggregatorConstant struct {
+	value sqltypes.Value
+}
+
+func (a *aggregatorConstant) add(_ []sqltypes.Value) error {
+	return nil
+}
+
+func (a *aggregatorConstant) finish() sqltypes.Value {
+	return a.value
 }
 
+func (a *aggregatorConstant) reset() {}
+
 type aggregatorGtid struct {
 	from   int
 	shards []*binlogdatapb.ShardGtid
@@ -353,6 +540,7 @@ func isComparable(typ sqltypes.Type) bool {
 }
 
 func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (aggregationState, []*querypb.Field, error) {
+	input := fields
 	fields = slice.Map(fields, func(from *querypb.Field) *querypb.Field { return from.CloneVT() })
 
 	agstate := make([]aggregator, len(fields))
@@ -381,14 +569,19 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 			ag = &aggregatorCountStar{}
 
 		case AggregateCount, AggregateCountDistinct:
+			var filter distinctFilter = &aggregatorDistinct{
+				column:       distinct,
+				coll:         aggr.Type.Collation(),
+				collationEnv: aggr.CollationEnv,
+				values:       aggr.Type.Values(),
+			}
+			if aggr.Opcode.IsDistinct() && len(aggr.ExtraCols) > 0 {
+				filter = newDistinctSet(input, append([]int{aggr.Col}, aggr.ExtraCols...))
+			}
 			ag = &aggregatorCount{
-				from: aggr.Col,
-				distinct: aggregatorDistinct{
-					column:       distinct,
-					coll:         aggr.Type.Collation(),
-					collationEnv: aggr.CollationEnv,
-					values:       aggr.Type.Values(),
-				},
+				from:     aggr.Col,
+				extra:    aggr.ExtraCols,
+				distinct: filter,
 			}
 
 		case AggregateSum, AggregateSumDistinct:
@@ -403,7 +596,7 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 			ag = &aggregatorSum{
 				from: aggr.Col,
 				sum:  sum,
-				distinct: aggregatorDistinct{
+				distinct: &aggregatorDistinct{
 					column:       distinct,
 					coll:         aggr.Type.Collation(),
 					collationEnv: aggr.CollationEnv,
@@ -434,7 +627,15 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 			ag = &aggregatorScalar{from: aggr.Col}
 
 		case AggregateGroupConcat:
-			ag = &aggregatorGroupConcat{from: aggr.Col, type_: targetType}
+			gc, err := newGroupConcat(input, aggr, targetType)
+			if err != nil {
+				return nil, nil, err
+			}
+			ag = gc
+
+		case AggregateGrouping:
+			// outside of rollup subtotals, none of the group by columns are aggregated
+			ag = &aggregatorConstant{value: sqltypes.NewInt64(0)}
 
 		default:
 			panic("BUG: unexpected Aggregation opcode")
@@ -455,3 +656,107 @@ func newAggregation(fields []*querypb.Field, aggregates []*AggregateParams) (agg
 
 	return agstate, fields, nil
 }
+
+func newGroupConcat(fields []*querypb.Field, aggr *AggregateParams, targetType sqltypes.Type) (*aggregatorGroupConcat, error) {
+	ag := &aggregatorGroupConcat{
+		from:      aggr.Col,
+		extra:     aggr.ExtraCols,
+		type_:     targetType,
+		separator: []byte{','},
+		orderBy:   slices.Clone(aggr.OrderBy),
+	}
+	fn, ok := aggr.Expr.(*sqlparser.GroupConcatExpr)
+	if !ok {
+		return ag, nil
+	}
+	if fn.Separator != "" {
+		separator, err := sqltypes.DecodeStringSQL(fn.Separator)
+		if err != nil {
+			return nil, err
+		}
+		ag.separator = []byte(separator)
+	}
+	if fn.Distinct {
+		ag.distinct = newDistinctSet(fields, append([]int{aggr.Col}, aggr.ExtraCols...))
+	}
+	for i, ob := range ag.orderBy {
+		if !ob.Type.Valid() {
+			ag.orderBy[i].Type = evalengine.NewTypeFromField(fields[ob.Col])
+		}
+	}
+	return ag, nil
+}
+
+// rollupState holds the aggregations needed to evaluate GROUP BY ... WITH ROLLUP.
+// The first level aggregates the groups, and every following level aggregates
+// the super-aggregate rows of one less group by column, ending with the grand total.
+type rollupState []aggregationState
+
+func newRollupAggregation(fields []*querypb.Field, aggregates []*AggregateParams, groupByKeys []*GroupByParams) (rollupState, []*querypb.Field, error) {
+	for _, aggr := range aggregates {
+		if aggr.Opcode.IsDistinct() {
+			// the input is only sorted by the distinct column inside each group,
+			// so we are not able to calculate the distinct values of the super-aggregate rows
+			return nil, nil, vterrors.VT12001("distinct aggregation together with WITH ROLLUP")
+		}
+	}
+
+	state := make(rollupState, 0, len(groupByKeys)+1)
+	var outFields []*querypb.Field
+	for kept := len(groupByKeys); kept >= 0; kept-- {
+		agg, aggFields, err := newAggregation(fields, aggregates)
+		if err != nil {
+			return nil, nil, err
+		}
+		if outFields == nil {
+			outFields = aggFields
+		}
+
+		// the group by columns that are rolled up are returned as NULL in the super-aggregate rows
+		for _, gb := range groupByKeys[kept:] {
+			agg[gb.KeyCol] = &aggregatorConstant{value: sqltypes.NULL}
+			if gb.WeightStringCol >= 0 {
+				agg[gb.WeightStringCol] = &aggregatorConstant{value: sqltypes.NULL}
+			}
+		}
+		for _, aggr := range aggregates {
+			if aggr.Opcode == AggregateGrouping {
+				agg[aggr.Col] = &aggregatorConstant{value: sqltypes.NewInt64(groupingValue(aggr.GroupingKeys, kept))}
+			}
+		}
+		state = append(state, agg)
+	}
+	return state, outFields, nil
+}
+
+// groupingValue returns the value of GROUPING() for a row where only the first `kept` group by columns are used.
+// Every argument is a bit in the result, with the last argument being the least significant bit.
+func groupingValue(keys []int, kept int) int64 {
+	var value int64
+	for _, key := range keys {
+		value <<= 1
+		if key >= kept {
+			value |= 1
+		}
+	}
+	return value
+}
+
+func (r rollupState) add(row []sqltypes.Value) error {
+	for _, level := range r {
+		if err := level.add(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// finish returns the rows of the given number of levels, starting with the most detailed one,
+// and resets these levels so they can start aggregating the next group
+func (r rollupState) finish(levels int) (rows []sqltypes.Row) {
+	for _, level := range r[:levels] {
+		rows = append(rows, level.finish())
+		level.reset()
+	}
+	return rows
+}
@@ -19,6 +19,7 @@ package engine
 import (
 	"context"
 	"fmt"
+	"maps"
 
 	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -68,10 +69,19 @@ func (dml *DMLWithInput) TryExecute(ctx context.Context, vcursor VCursor, bindVa
 		return &sqltypes.Result{}, nil
 	}
 
+	// the values for the DMLs are bound on a copy, to leave the bind variables of the caller untouched.
+	bindVars = maps.Clone(bindVars)
+	if bindVars == nil {
+		bindVars = map[string]*querypb.BindVariable{}
+	}
 	var res *sqltypes.Result
 	for idx, prim := range dml.DMLs {
 		var qr *sqltypes.Result
-		if len(dml.BVList) == 0 || len(dml.BVList[idx]) == 0 {
+		if ins, isInsert := prim.(*InsertSelect); isInsert {
+			// the insert takes the rows themselves, not the values bound for the other DMLs.
+			delete(bindVars, DmlVals)
+			qr, err = ins.insertRows(ctx, vcursor, bindVars, projectRows(inputRes.Rows, dml.OutputCols[idx]))
+		} else if len(dml.BVList) == 0 || len(dml.BVList[idx]) == 0 {
 			qr, err = executeLiteralUpdate(ctx, vcursor, bindVars, prim, inputRes, dml.OutputCols[idx])
 		} else {
 			qr, err = executeNonLiteralUpdate(ctx, vcursor, bindVars, prim, inputRes, dml.OutputCols[idx], dml.BVList[idx])
@@ -84,11 +94,27 @@ func (dml *DMLWithInput) TryExecute(ctx context.Context, vcursor VCursor, bindVa
 			res = qr
 		} else {
 			res.RowsAffected += qr.RowsAffected
+			if res.InsertID == 0 {
+				res.InsertID = qr.InsertID
+			}
 		}
 	}
 	return res, nil
 }
 
+// projectRows returns the rows with only the columns at the given offsets.
+func projectRows(rows []sqltypes.Row, offsets []int) []sqltypes.Row {
+	out := make([]sqltypes.Row, 0, len(rows))
+	for _, row := range rows {
+		newRow := make(sqltypes.Row, 0, len(offsets))
+		for _, offset := range offsets {
+			newRow = append(newRow, row[offset])
+		}
+		out = append(out, newRow)
+	}
+	return out
+}
+
 // executeLiteralUpdate executes the primitive that can be executed with a single bind variable from the input result.
 // The column updated have same value for all rows in the input result.
 func executeLiteralUpdate(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, prim Primitive, inputRes *sqltypes.Result, outputCols []int) (*sqltypes.Result, error) {
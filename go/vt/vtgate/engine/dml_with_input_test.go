@@ -24,6 +24,8 @@ import (
 
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
 )
@@ -180,3 +182,114 @@ func TestDeleteWithMultiTarget(t *testing.T) {
 		`ExecuteMultiShard ks.-20: dummy_delete_2 {dml_vals: type:TUPLE values:{type:TUPLE value:"\x89\x02\x03100\x89\x02\x011"} values:{type:TUPLE value:"\x89\x02\x03100\x89\x02\x012"} values:{type:TUPLE value:"\x89\x02\x03200\x89\x02\x013"}} true true`,
 	})
 }
+
+func TestReplaceWithInput(t *testing.T) {
+	invschema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"}},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Name:    "hash",
+							Columns: []string{"id"}}}}}}}}
+	vs := vindexes.BuildVSchema(invschema, sqlparser.NewTestParser())
+	ks := vs.Keyspaces["sharded"]
+
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|name", "int64|varchar"), "1|a", "2|b"),
+	}}
+
+	replace := &DMLWithInput{
+		Input: input,
+		DMLs: []Primitive{
+			&Delete{
+				DML: &DML{
+					RoutingParameters: &RoutingParameters{
+						Opcode:   Scatter,
+						Keyspace: ks.Keyspace,
+					},
+					Query: "dummy_delete",
+				},
+			},
+			// the rows to insert come from the input.
+			newInsertSelect(false, ks.Keyspace, ks.Tables["t1"], "prefix ", nil, [][]int{{0}}, nil),
+		},
+		OutputCols: [][]int{{0}, {0, 1}},
+	}
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"-20", "20-"}
+	_, err := replace.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`sharded.-20: dummy_delete {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"}} ` +
+			`sharded.20-: dummy_delete {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"}} true false`,
+		`ResolveDestinations sharded [value:"0" value:"1"] Destinations:DestinationKeyspaceID(166b40b44aba4bd6),DestinationKeyspaceID(06e7ea22ce92708f)`,
+		`ExecuteMultiShard ` +
+			`sharded.-20: prefix values (:_c0_0, :_c0_1) {_c0_0: type:INT64 value:"1" _c0_1: type:VARCHAR value:"a"} ` +
+			`sharded.20-: prefix values (:_c1_0, :_c1_1) {_c1_0: type:INT64 value:"2" _c1_1: type:VARCHAR value:"b"} true false`,
+	})
+}
+
+func TestReplaceWithInputDropsReplacedRows(t *testing.T) {
+	invschema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"}},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Name:    "hash",
+							Columns: []string{"id"}}}}}}}}
+	vs := vindexes.BuildVSchema(invschema, sqlparser.NewTestParser())
+	ks := vs.Keyspaces["sharded"]
+
+	// the first row is replaced by the last one, as they have the same id.
+	input := &fakePrimitive{results: []*sqltypes.Result{
+		sqltypes.MakeTestResult(sqltypes.MakeTestFields("id|name", "int64|varchar"), "1|a", "2|b", "1|c"),
+	}}
+
+	insert := newInsertSelect(false, ks.Keyspace, ks.Tables["t1"], "prefix ", nil, [][]int{{0}}, nil)
+	insert.ReplaceKeyOffsets = [][]int{{0}}
+	replace := &DMLWithInput{
+		Input: input,
+		DMLs: []Primitive{
+			&Delete{
+				DML: &DML{
+					RoutingParameters: &RoutingParameters{
+						Opcode:   Scatter,
+						Keyspace: ks.Keyspace,
+					},
+					Query: "dummy_delete",
+				},
+			},
+			insert,
+		},
+		OutputCols: [][]int{{0}, {0, 1}},
+	}
+
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"20-", "-20"}
+	bindVars := map[string]*querypb.BindVariable{}
+	_, err := replace.TryExecute(context.Background(), vc, bindVars, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [] Destinations:DestinationAllShards()`,
+		`ExecuteMultiShard ` +
+			`sharded.-20: dummy_delete {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} values:{type:INT64 value:"1"}} ` +
+			`sharded.20-: dummy_delete {dml_vals: type:TUPLE values:{type:INT64 value:"1"} values:{type:INT64 value:"2"} values:{type:INT64 value:"1"}} true false`,
+		`ResolveDestinations sharded [value:"0" value:"1"] Destinations:DestinationKeyspaceID(06e7ea22ce92708f),DestinationKeyspaceID(166b40b44aba4bd6)`,
+		`ExecuteMultiShard ` +
+			`sharded.20-: prefix values (:_c0_0, :_c0_1) {_c0_0: type:INT64 value:"2" _c0_1: type:VARCHAR value:"b"} ` +
+			`sharded.-20: prefix values (:_c1_0, :_c1_1) {_c1_0: type:INT64 value:"1" _c1_1: type:VARCHAR value:"c"} true false`,
+	})
+	// the values bound for the DMLs are not left in the bind variables of the caller.
+	require.Empty(t, bindVars)
+}
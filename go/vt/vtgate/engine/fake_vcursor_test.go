@@ -48,6 +48,7 @@ import (
 
 var testMaxMemoryRows = 100
 var testIgnoreMaxMemoryRows = false
+var testMaxCTERecursionDepth = 1000
 
 var _ VCursor = (*noopVCursor)(nil)
 var _ SessionActions = (*noopVCursor)(nil)
@@ -333,6 +334,10 @@ func (t *noopVCursor) MaxMemoryRows() int {
 	return testMaxMemoryRows
 }
 
+func (t *noopVCursor) MaxCTERecursionDepth() int {
+	return testMaxCTERecursionDepth
+}
+
 func (t *noopVCursor) ExceedsMaxMemoryRows(numRows int) bool {
 	return !testIgnoreMaxMemoryRows && numRows > testMaxMemoryRows
 }
@@ -53,6 +53,11 @@ type Insert struct {
 	// Insert.Values[i].Values[j].Values[k] represents the value pulled from row k for that column: (k < len(ins.rows))
 	VindexValues [][][]evalengine.Expr
 
+	// ReplaceKeys specifies, for a REPLACE, the values of the primary and unique key columns.
+	// The structure is key, column, row. A row that is replaced by a later row
+	// of the same statement is not inserted.
+	ReplaceKeys [][][]evalengine.Expr
+
 	// Mid is the row values for the sharded insert plans.
 	Mid sqlparser.Values
 
@@ -215,7 +220,12 @@ func (ins *Insert) getInsertShardedQueries(
 		return nil, nil, vterrors.NewErrorf(vtrpcpb.Code_FAILED_PRECONDITION, vterrors.RequiresPrimaryKey, vterrors.PrimaryVindexNotSet, ins.TableName)
 	}
 
-	keyspaceIDs, err := ins.processVindexes(ctx, vcursor, vindexRowsValues)
+	var keyspaceIDs []ksID
+	if len(ins.ReplaceKeys) > 0 {
+		keyspaceIDs, err = ins.processReplaceVindexes(ctx, vcursor, bindVars, vindexRowsValues)
+	} else {
+		keyspaceIDs, err = ins.processVindexes(ctx, vcursor, vindexRowsValues)
+	}
 	if err != nil {
 		return nil, nil, err
 	}
@@ -347,6 +357,55 @@ func (ins *Insert) buildVindexRowsValues(ctx context.Context, vcursor VCursor, b
 	return vindexRowsValues, nil
 }
 
+// processReplaceVindexes processes the vindexes of the rows that are not replaced by a later row
+// of the same REPLACE. The keyspace ids of the replaced rows are nil, so they are not inserted.
+func (ins *Insert) processReplaceVindexes(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, vindexRowsValues [][]sqltypes.Row) ([]ksID, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	rowCount := len(vindexRowsValues[0])
+	keyValues := make([][]sqltypes.Row, len(ins.ReplaceKeys))
+	for keyIdx, keyCols := range ins.ReplaceKeys {
+		keyValues[keyIdx] = make([]sqltypes.Row, rowCount)
+		for _, colValues := range keyCols {
+			if len(colValues) != rowCount {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] uneven row values for replace keys: %d %d", rowCount, len(colValues))
+			}
+			for rowNum, colValue := range colValues {
+				result, err := env.Evaluate(colValue)
+				if err != nil {
+					return nil, err
+				}
+				keyValues[keyIdx][rowNum] = append(keyValues[keyIdx][rowNum], result.Value(vcursor.ConnCollation()))
+			}
+		}
+	}
+	replaced, err := replacedRows(vcursor, keyValues, rowCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var kept []int
+	for rowNum, isReplaced := range replaced {
+		if !isReplaced {
+			kept = append(kept, rowNum)
+		}
+	}
+	keptValues := make([][]sqltypes.Row, len(vindexRowsValues))
+	for vIdx, rows := range vindexRowsValues {
+		for _, rowNum := range kept {
+			keptValues[vIdx] = append(keptValues[vIdx], rows[rowNum])
+		}
+	}
+	keptIDs, err := ins.processVindexes(ctx, vcursor, keptValues)
+	if err != nil {
+		return nil, err
+	}
+	keyspaceIDs := make([]ksID, rowCount)
+	for idx, rowNum := range kept {
+		keyspaceIDs[rowNum] = keptIDs[idx]
+	}
+	return keyspaceIDs, nil
+}
+
 func (ins *Insert) description() PrimitiveDescription {
 	other := ins.commonDesc()
 	other["Query"] = ins.Query
@@ -373,6 +432,22 @@ func (ins *Insert) description() PrimitiveDescription {
 		other["VindexValues"] = valuesOffsets
 	}
 
+	if len(ins.ReplaceKeys) > 0 {
+		var keys []string
+		for _, keyCols := range ins.ReplaceKeys {
+			var cols []string
+			for _, exprs := range keyCols {
+				var this []string
+				for _, expr := range exprs {
+					this = append(this, sqlparser.String(expr))
+				}
+				cols = append(cols, strings.Join(this, ", "))
+			}
+			keys = append(keys, strings.Join(cols, ", "))
+		}
+		other["ReplaceKeys"] = keys
+	}
+
 	// This is a check to ensure we send the correct query to the database.
 	// "ActualQuery" should not be part of the plan output, if it does, it means the query was not rewritten correctly.
 	if ins.Mid != nil {
@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
 	"vitess.io/vitess/go/vt/vtgate/vindexes"
+	"vitess.io/vitess/go/vt/vthash"
 )
 
 type (
@@ -195,6 +196,38 @@ func (ins *InsertCommon) processVindexes(ctx context.Context, vcursor VCursor, v
 	return keyspaceIDs, nil
 }
 
+// replacedRows returns which of the rows of a REPLACE are replaced by a later row of the same statement.
+// MySQL inserts the rows one after the other, so a row is deleted again by a later row that has
+// the same values for one of the keys. keyValues holds the values of the keys, indexed by key and row.
+func replacedRows(vcursor VCursor, keyValues [][]sqltypes.Row, rowCount int) ([]bool, error) {
+	replaced := make([]bool, rowCount)
+	sqlmode := evalengine.ParseSQLMode(vcursor.SQLMode())
+	for _, rows := range keyValues {
+		seen := make(map[vthash.Hash]struct{}, len(rows))
+	nextRow:
+		for rowNum := len(rows) - 1; rowNum >= 0; rowNum-- {
+			hasher := vthash.New()
+			for _, value := range rows[rowNum] {
+				if value.IsNull() {
+					// a NULL never clashes on a unique key.
+					continue nextRow
+				}
+				err := evalengine.NullsafeHashcode128(&hasher, value, vcursor.ConnCollation(), value.Type(), sqlmode, nil)
+				if err != nil {
+					return nil, err
+				}
+			}
+			code := hasher.Sum128()
+			if _, found := seen[code]; found {
+				replaced[rowNum] = true
+				continue
+			}
+			seen[code] = struct{}{}
+		}
+	}
+	return replaced, nil
+}
+
 // processPrimary maps the primary vindex values to the keyspace ids.
 func (ic *InsertCommon) processPrimary(ctx context.Context, vcursor VCursor, vindexColumnsKeys []sqltypes.Row, colVindex *vindexes.ColumnVindex) ([]ksID, error) {
 	destinations, err := vindexes.Map(ctx, colVindex.Vindex, vcursor, vindexColumnsKeys)
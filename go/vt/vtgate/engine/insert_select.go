@@ -42,11 +42,16 @@ type (
 		InsertCommon
 
 		// Input is a select query plan to retrieve results for inserting data.
+		// It is nil when the rows come from a DMLWithInput, as for REPLACE ... SELECT.
 		Input Primitive
 
 		// VindexValueOffset stores the offset for each column in the ColumnVindex
 		// that will appear in the result set of the select query.
 		VindexValueOffset [][]int
+
+		// ReplaceKeyOffsets stores, for a REPLACE, the offsets of the primary and unique key columns
+		// in the rows. A row that is replaced by a later row of the same statement is not inserted.
+		ReplaceKeyOffsets [][]int
 	}
 )
 
@@ -83,6 +88,9 @@ func newInsertSelect(
 }
 
 func (ins *InsertSelect) Inputs() ([]Primitive, []map[string]any) {
+	if ins.Input == nil {
+		return nil, nil
+	}
 	return []Primitive{ins.Input}, nil
 }
 
@@ -145,6 +153,55 @@ func (ins *InsertSelect) TryStreamExecute(ctx context.Context, vcursor VCursor,
 	return callback(output)
 }
 
+// insertRows inserts the given rows instead of the rows from the input.
+func (ins *InsertSelect) insertRows(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, rows []sqltypes.Row) (*sqltypes.Result, error) {
+	ctx, cancelFunc := addQueryTimeout(ctx, vcursor, ins.QueryTimeout)
+	defer cancelFunc()
+
+	if len(ins.ReplaceKeyOffsets) > 0 {
+		var err error
+		rows, err = ins.dropReplacedRows(vcursor, rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	insertID, err := ins.processGenerateFromSelect(ctx, vcursor, ins, rows)
+	if err != nil {
+		return nil, err
+	}
+	irr := insertRowsResult{rows: rows, insertID: uint64(insertID)}
+	if ins.Keyspace.Sharded {
+		return ins.insertIntoShardedTable(ctx, vcursor, bindVars, irr)
+	}
+	return ins.insertIntoUnshardedTable(ctx, vcursor, bindVars, irr)
+}
+
+// dropReplacedRows returns the rows that are not replaced by a later row of the same REPLACE.
+func (ins *InsertSelect) dropReplacedRows(vcursor VCursor, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	keyValues := make([][]sqltypes.Row, len(ins.ReplaceKeyOffsets))
+	for keyIdx, offsets := range ins.ReplaceKeyOffsets {
+		for _, row := range rows {
+			keyRow := make(sqltypes.Row, 0, len(offsets))
+			for _, offset := range offsets {
+				keyRow = append(keyRow, row[offset])
+			}
+			keyValues[keyIdx] = append(keyValues[keyIdx], keyRow)
+		}
+	}
+	replaced, err := replacedRows(vcursor, keyValues, len(rows))
+	if err != nil {
+		return nil, err
+	}
+	kept := make([]sqltypes.Row, 0, len(rows))
+	for rowNum, row := range rows {
+		if !replaced[rowNum] {
+			kept = append(kept, row)
+		}
+	}
+	return kept, nil
+}
+
 func (ins *InsertSelect) execInsertUnsharded(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
 	irr, err := ins.execSelect(ctx, vcursor, bindVars)
 	if err != nil {
@@ -333,6 +390,10 @@ func (ins *InsertSelect) description() PrimitiveDescription {
 		}
 		other["VindexOffsetFromSelect"] = valuesOffsets
 	}
+	if len(ins.ReplaceKeyOffsets) > 0 {
+		marshal, _ := json.Marshal(ins.ReplaceKeyOffsets)
+		other["ReplaceKeyOffsets"] = string(marshal)
+	}
 
 	return PrimitiveDescription{
 		OperatorType:     "Insert",
@@ -2504,3 +2504,62 @@ func TestInsertSelectShardingCases(t *testing.T) {
 		`ResolveDestinations uks1 [] Destinations:DestinationAllShards()`,
 		`ExecuteMultiShard uks1.0: prefix values (:_c0_0) {_c0_0: type:INT64 value:"1"} true true`})
 }
+
+func TestReplaceShardedDropsReplacedRows(t *testing.T) {
+	invschema := &vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"sharded": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {
+						Type: "hash",
+					},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"t1": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{
+							Name:    "hash",
+							Columns: []string{"id"},
+						}},
+					},
+				},
+			},
+		},
+	}
+	vs := vindexes.BuildVSchema(invschema, sqlparser.NewTestParser())
+	ks := vs.Keyspaces["sharded"]
+
+	// the first row is replaced by the last one, as they have the same id
+	ids := []evalengine.Expr{
+		evalengine.NewLiteralInt(1),
+		evalengine.NewLiteralInt(2),
+		evalengine.NewLiteralInt(1),
+	}
+	ins := newInsert(
+		InsertSharded,
+		false,
+		ks.Keyspace,
+		[][][]evalengine.Expr{{ids}},
+		ks.Tables["t1"],
+		"prefix",
+		sqlparser.Values{
+			{&sqlparser.Argument{Name: "_id_0", Type: sqltypes.Int64}},
+			{&sqlparser.Argument{Name: "_id_1", Type: sqltypes.Int64}},
+			{&sqlparser.Argument{Name: "_id_2", Type: sqltypes.Int64}},
+		},
+		nil,
+	)
+	ins.ReplaceKeys = [][][]evalengine.Expr{{ids}}
+	vc := newDMLTestVCursor("-20", "20-")
+	vc.shardForKsid = []string{"20-", "-20"}
+
+	_, err := ins.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, false)
+	require.NoError(t, err)
+	vc.ExpectLog(t, []string{
+		`ResolveDestinations sharded [value:"1" value:"2"] Destinations:DestinationKeyspaceID(06e7ea22ce92708f),DestinationKeyspaceID(166b40b44aba4bd6)`,
+		`ExecuteMultiShard ` +
+			`sharded.20-: prefix(:_id_1 /* INT64 */) {_id_1: type:INT64 value:"2"} ` +
+			`sharded.-20: prefix(:_id_2 /* INT64 */) {_id_2: type:INT64 value:"1"} ` +
+			`true false`,
+	})
+}
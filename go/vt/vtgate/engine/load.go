@@ -0,0 +1,343 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+var _ Primitive = (*Load)(nil)
+
+// LocalInfileFunc asks the client for the contents of the named file.
+type LocalInfileFunc func(fileName string) (io.ReadCloser, error)
+
+// WithLocalInfile returns a context that lets LOAD DATA LOCAL INFILE statements
+// read files from the client using the given function.
+func WithLocalInfile(ctx context.Context, f LocalInfileFunc) context.Context {
+	return context.WithValue(ctx, localInfileKey, f)
+}
+
+// Load is a primitive that executes LOAD DATA LOCAL INFILE. It reads the rows
+// the client sends and inserts them in batches, using the Insert template.
+// Every batch is planned like a regular INSERT, so rows are routed using the
+// primary vindex, and owned lookup vindexes are filled in.
+type Load struct {
+	noInputs
+	txNeeded
+
+	// Keyspace specifies the keyspace of the table.
+	Keyspace *vindexes.Keyspace
+
+	// TableName is the name of the table the rows are loaded into.
+	TableName string
+
+	// FileName is the name of the file on the client.
+	FileName string
+
+	// Insert is the template for the insert statements. Its rows are replaced
+	// with the rows of each batch.
+	Insert *sqlparser.Insert
+
+	// Format describes how the file is split into rows and fields.
+	Format *sqlparser.LoadDataFormat
+
+	// IgnoreLines is the number of lines at the start of the file to skip.
+	IgnoreLines int
+
+	// BatchSize is the number of rows sent in a single insert.
+	BatchSize int
+}
+
+// RouteType implements the Primitive interface
+func (l *Load) RouteType() string {
+	return "Load"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (l *Load) GetKeyspaceName() string {
+	return l.Keyspace.Name
+}
+
+// GetTableName implements the Primitive interface
+func (l *Load) GetTableName() string {
+	return l.TableName
+}
+
+// GetFields implements the Primitive interface
+func (l *Load) GetFields(context.Context, VCursor, map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return &sqltypes.Result{}, nil
+}
+
+// TryExecute implements the Primitive interface
+func (l *Load) TryExecute(ctx context.Context, vcursor VCursor, _ map[string]*querypb.BindVariable, _ bool) (*sqltypes.Result, error) {
+	localInfile, ok := ctx.Value(localInfileKey).(LocalInfileFunc)
+	if !ok {
+		return nil, vterrors.VT12001("LOAD DATA LOCAL INFILE on this connection")
+	}
+	file, err := localInfile(l.FileName)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	reader := newLoadDataReader(file, l.Format)
+	for i := 0; i < l.IgnoreLines; i++ {
+		if _, err := reader.readRow(); err != nil {
+			if err == io.EOF {
+				return &sqltypes.Result{}, nil
+			}
+			return nil, err
+		}
+	}
+
+	result := &sqltypes.Result{}
+	var rows [][]sqltypes.Value
+	flush := func() error {
+		if len(rows) == 0 {
+			return nil
+		}
+		query, bvs := l.batch(rows)
+		qr, err := vcursor.Execute(ctx, "Load", query, bvs, true, vtgatepb.CommitOrder_NORMAL)
+		if err != nil {
+			return err
+		}
+		result.RowsAffected += qr.RowsAffected
+		rows = rows[:0]
+		return nil
+	}
+
+	for {
+		row, err := reader.readRow()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(row) < len(l.Insert.Columns) {
+			return nil, vterrors.VT03006()
+		}
+		// like MySQL, extra fields are ignored
+		rows = append(rows, row[:len(l.Insert.Columns)])
+		if len(rows) >= l.BatchSize {
+			if err := flush(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (l *Load) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	res, err := l.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// batch returns the insert query and bind variables for the given rows
+func (l *Load) batch(rows [][]sqltypes.Value) (string, map[string]*querypb.BindVariable) {
+	bvs := make(map[string]*querypb.BindVariable, len(rows)*len(l.Insert.Columns))
+	values := make(sqlparser.Values, len(rows))
+	for i, row := range rows {
+		tuple := make(sqlparser.ValTuple, len(row))
+		for j, val := range row {
+			name := fmt.Sprintf("v%d", len(bvs)+1)
+			bvs[name] = sqltypes.ValueBindVariable(val)
+			tuple[j] = sqlparser.NewArgument(name)
+		}
+		values[i] = tuple
+	}
+	ins := *l.Insert
+	ins.Rows = values
+	return sqlparser.String(&ins), bvs
+}
+
+func (l *Load) description() PrimitiveDescription {
+	query, _ := l.batch([][]sqltypes.Value{make([]sqltypes.Value, len(l.Insert.Columns))})
+	other := map[string]any{
+		"BatchSize": l.BatchSize,
+		"FileName":  l.FileName,
+		"Query":     query,
+		"TableName": l.TableName,
+	}
+	if l.IgnoreLines > 0 {
+		other["IgnoreLines"] = l.IgnoreLines
+	}
+	return PrimitiveDescription{
+		OperatorType: "Load",
+		Keyspace:     l.Keyspace,
+		Other:        other,
+	}
+}
+
+// loadDataReader splits the contents of a file into rows, following the
+// FIELDS and LINES options of a LOAD DATA statement.
+type loadDataReader struct {
+	r      *bufio.Reader
+	format *sqlparser.LoadDataFormat
+}
+
+func newLoadDataReader(r io.Reader, format *sqlparser.LoadDataFormat) *loadDataReader {
+	return &loadDataReader{r: bufio.NewReader(r), format: format}
+}
+
+// readRow returns the fields of the next row, or io.EOF when there are no rows left.
+// NULL fields are returned as NULL values, all other fields as VARCHAR.
+func (r *loadDataReader) readRow() ([]sqltypes.Value, error) {
+	if _, err := r.r.Peek(1); err != nil {
+		return nil, err
+	}
+
+	if r.format.LinesStartingBy != "" {
+		// everything up to the prefix is skipped, and so are lines without it
+		for {
+			if r.consume(r.format.LinesStartingBy) {
+				break
+			}
+			if r.consume(r.format.LinesTerminatedBy) {
+				continue
+			}
+			if _, err := r.r.ReadByte(); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var row []sqltypes.Value
+	for {
+		val, endOfLine, err := r.readField()
+		if err != nil {
+			return nil, err
+		}
+		row = append(row, val)
+		if endOfLine {
+			return row, nil
+		}
+	}
+}
+
+// readField reads a single field and the terminator after it.
+// The second return value is true when the field is the last one of the row.
+func (r *loadDataReader) readField() (sqltypes.Value, bool, error) {
+	var (
+		buf      []byte
+		enclosed = r.consume(r.format.FieldsEnclosedBy)
+		quoted   = enclosed
+		null     = false
+	)
+	value := func() sqltypes.Value {
+		if null || (!quoted && r.format.FieldsEnclosedBy != "" && string(buf) == "NULL") {
+			return sqltypes.NULL
+		}
+		return sqltypes.NewVarChar(string(buf))
+	}
+
+	for {
+		if enclosed {
+			if r.consume(r.format.FieldsEnclosedBy) {
+				if r.consume(r.format.FieldsEnclosedBy) {
+					// a doubled enclosing character stands for itself
+					buf = append(buf, r.format.FieldsEnclosedBy...)
+					null = false
+					continue
+				}
+				enclosed = false
+				continue
+			}
+		} else {
+			if r.consume(r.format.FieldsTerminatedBy) {
+				return value(), false, nil
+			}
+			if r.consume(r.format.LinesTerminatedBy) {
+				return value(), true, nil
+			}
+		}
+
+		if r.consume(r.format.FieldsEscapedBy) {
+			c, err := r.r.ReadByte()
+			if err == io.EOF {
+				buf = append(buf, r.format.FieldsEscapedBy...)
+				return value(), true, nil
+			}
+			if err != nil {
+				return sqltypes.Value{}, false, err
+			}
+			null = c == 'N' && len(buf) == 0 && !quoted
+			buf = append(buf, unescapeLoadData(c))
+			continue
+		}
+
+		c, err := r.r.ReadByte()
+		if err == io.EOF {
+			// the last line doesn't need a terminator
+			return value(), true, nil
+		}
+		if err != nil {
+			return sqltypes.Value{}, false, err
+		}
+		buf = append(buf, c)
+		null = false
+	}
+}
+
+// consume skips over s if the input continues with it, and reports whether it did
+func (r *loadDataReader) consume(s string) bool {
+	if s == "" {
+		return false
+	}
+	next, err := r.r.Peek(len(s))
+	if err != nil || string(next) != s {
+		return false
+	}
+	_, _ = r.r.Discard(len(s))
+	return true
+}
+
+// unescapeLoadData returns the character an escape sequence stands for
+func unescapeLoadData(c byte) byte {
+	switch c {
+	case '0':
+		return 0
+	case 'b':
+		return '\b'
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'Z':
+		return 0x1a
+	}
+	return c
+}
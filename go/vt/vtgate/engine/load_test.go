@@ -0,0 +1,165 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func newTestLoad(t *testing.T, format *sqlparser.LoadDataFormat) *Load {
+	stmt, err := sqlparser.NewTestParser().Parse("insert into t(a, b) values (1, 2)")
+	require.NoError(t, err)
+	ins := stmt.(*sqlparser.Insert)
+	ins.Rows = nil
+	if format == nil {
+		format = &sqlparser.LoadDataFormat{FieldsTerminatedBy: ",", FieldsEscapedBy: "\\", LinesTerminatedBy: "\n"}
+	}
+	return &Load{
+		Keyspace:  &vindexes.Keyspace{Name: "ks", Sharded: true},
+		TableName: "t",
+		FileName:  "data.csv",
+		Insert:    ins,
+		Format:    format,
+		BatchSize: 2,
+	}
+}
+
+func withLocalFile(ctx context.Context, contents string) context.Context {
+	return WithLocalInfile(ctx, func(string) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(contents)), nil
+	})
+}
+
+func TestLoadExecute(t *testing.T) {
+	load := newTestLoad(t, nil)
+	load.IgnoreLines = 1
+
+	vc := &loggingVCursor{results: []*sqltypes.Result{{RowsAffected: 2}, {RowsAffected: 1}}}
+	ctx := withLocalFile(context.Background(), "a,b\n1,x\n2,y\n3,z,extra\n")
+	qr, err := load.TryExecute(ctx, vc, nil, false)
+	require.NoError(t, err)
+	assert.EqualValues(t, 3, qr.RowsAffected)
+
+	require.Len(t, vc.log, 2)
+	assert.True(t, strings.HasPrefix(vc.log[0], "Execute insert into t(a, b) values (:v1, :v2), (:v3, :v4) "), vc.log[0])
+	assert.True(t, strings.HasPrefix(vc.log[1], "Execute insert into t(a, b) values (:v1, :v2) "), vc.log[1])
+}
+
+func TestLoadExecuteErrors(t *testing.T) {
+	load := newTestLoad(t, nil)
+
+	_, err := load.TryExecute(context.Background(), &loggingVCursor{}, nil, false)
+	require.ErrorContains(t, err, "VT12001: unsupported: LOAD DATA LOCAL INFILE on this connection")
+
+	vc := &loggingVCursor{}
+	_, err = load.TryExecute(withLocalFile(context.Background(), "1\n"), vc, nil, false)
+	require.ErrorContains(t, err, "VT03006: column count does not match value count with the row")
+	assert.Empty(t, vc.log)
+}
+
+func TestLoadBatch(t *testing.T) {
+	load := newTestLoad(t, nil)
+	load.Insert.Action = sqlparser.ReplaceAct
+
+	query, bvs := load.batch([][]sqltypes.Value{
+		{sqltypes.NewVarChar("1"), sqltypes.NULL},
+		{sqltypes.NewVarChar("2"), sqltypes.NewVarChar("b")},
+	})
+	assert.Equal(t, "replace into t(a, b) values (:v1, :v2), (:v3, :v4)", query)
+	assert.Equal(t, map[string]string{"v1": "VARCHAR(\"1\")", "v2": "NULL", "v3": "VARCHAR(\"2\")", "v4": "VARCHAR(\"b\")"}, printValues(t, bvs))
+}
+
+func TestLoadDataReader(t *testing.T) {
+	defaults := &sqlparser.LoadDataFormat{FieldsTerminatedBy: "\t", FieldsEscapedBy: "\\", LinesTerminatedBy: "\n"}
+	csv := &sqlparser.LoadDataFormat{FieldsTerminatedBy: ",", FieldsEnclosedBy: "\"", FieldsEscapedBy: "\\", LinesTerminatedBy: "\r\n"}
+	tests := []struct {
+		name   string
+		format *sqlparser.LoadDataFormat
+		input  string
+		output [][]string
+	}{{
+		name:   "tab separated",
+		format: defaults,
+		input:  "1\ta\n2\tb\n",
+		output: [][]string{{"1", "a"}, {"2", "b"}},
+	}, {
+		name:   "no terminator on the last line",
+		format: defaults,
+		input:  "1\ta\n2\tb",
+		output: [][]string{{"1", "a"}, {"2", "b"}},
+	}, {
+		name:   "escape sequences",
+		format: defaults,
+		input:  "a\\tb\\\\\t\\N\tN\\N\n",
+		output: [][]string{{"a\tb\\", "<null>", "NN"}},
+	}, {
+		name:   "enclosed fields",
+		format: csv,
+		input:  "1,\"a,\"\"b\"\"\r\nc\",NULL,\"NULL\"\r\n",
+		output: [][]string{{"1", "a,\"b\"\r\nc", "<null>", "NULL"}},
+	}, {
+		name:   "lines starting by",
+		format: &sqlparser.LoadDataFormat{FieldsTerminatedBy: ",", LinesStartingBy: "xxx", LinesTerminatedBy: "\n"},
+		input:  "xxx1,a\nskipped\nfooxxx2,b\n",
+		output: [][]string{{"1", "a"}, {"2", "b"}},
+	}}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			r := newLoadDataReader(strings.NewReader(tc.input), tc.format)
+			var rows [][]string
+			for {
+				row, err := r.readRow()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				var fields []string
+				for _, val := range row {
+					if val.IsNull() {
+						fields = append(fields, "<null>")
+						continue
+					}
+					fields = append(fields, val.ToString())
+				}
+				rows = append(rows, fields)
+			}
+			assert.Equal(t, tc.output, rows)
+		})
+	}
+}
+
+func printValues(t *testing.T, bvs map[string]*querypb.BindVariable) map[string]string {
+	out := make(map[string]string, len(bvs))
+	for k, bv := range bvs {
+		val, err := sqltypes.BindVariableToValue(bv)
+		require.NoError(t, err)
+		out[k] = val.String()
+	}
+	return out
+}
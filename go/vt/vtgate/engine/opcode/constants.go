@@ -77,6 +77,7 @@ const (
 	AggregateCountStar
 	AggregateGroupConcat
 	AggregateAvg
+	AggregateGrouping
 	AggregateUDF  // This is an opcode used to represent UDFs
 	_NumOfOpCodes // This line must be last of the opcodes!
 )
@@ -111,6 +112,7 @@ var AggregateName = map[AggregateOpcode]string{
 	AggregateGroupConcat:   "group_concat",
 	AggregateAnyValue:      "any_value",
 	AggregateAvg:           "avg",
+	AggregateGrouping:      "grouping",
 }
 
 func (code AggregateOpcode) String() string {
@@ -150,7 +152,7 @@ func (code AggregateOpcode) SQLType(typ querypb.Type) querypb.Type {
 			return sqltypes.Decimal
 		}
 		return sqltypes.Float64
-	case AggregateCount, AggregateCountStar, AggregateCountDistinct:
+	case AggregateCount, AggregateCountStar, AggregateCountDistinct, AggregateGrouping:
 		return sqltypes.Int64
 	case AggregateGtid:
 		return sqltypes.VarChar
@@ -163,7 +165,7 @@ func (code AggregateOpcode) SQLType(typ querypb.Type) querypb.Type {
 
 func (code AggregateOpcode) Nullable() bool {
 	switch code {
-	case AggregateCount, AggregateCountStar:
+	case AggregateCount, AggregateCountStar, AggregateGrouping:
 		return false
 	default:
 		return true
@@ -200,3 +202,102 @@ func (code AggregateOpcode) IsDistinct() bool {
 		return false
 	}
 }
+
+// WindowOpcode is the opcode of a window function
+type WindowOpcode int
+
+// These constants list the window functions that can be evaluated at the vtgate level
+const (
+	WindowUnassigned = WindowOpcode(iota)
+	WindowRowNumber
+	WindowRank
+	WindowDenseRank
+	WindowPercentRank
+	WindowCumeDist
+	WindowNtile
+	WindowLag
+	WindowLead
+	WindowFirstValue
+	WindowLastValue
+	WindowNthValue
+	WindowCount
+	WindowCountStar
+	WindowSum
+	WindowMin
+	WindowMax
+	WindowAvg
+	_NumOfWindowOpCodes // This line must be last of the opcodes!
+)
+
+var WindowName = map[WindowOpcode]string{
+	WindowRowNumber:   "row_number",
+	WindowRank:        "rank",
+	WindowDenseRank:   "dense_rank",
+	WindowPercentRank: "percent_rank",
+	WindowCumeDist:    "cume_dist",
+	WindowNtile:       "ntile",
+	WindowLag:         "lag",
+	WindowLead:        "lead",
+	WindowFirstValue:  "first_value",
+	WindowLastValue:   "last_value",
+	WindowNthValue:    "nth_value",
+	WindowCount:       "count",
+	WindowCountStar:   "count_star",
+	WindowSum:         "sum",
+	WindowMin:         "min",
+	WindowMax:         "max",
+	WindowAvg:         "avg",
+}
+
+func (code WindowOpcode) String() string {
+	name := WindowName[code]
+	if name == "" {
+		name = "ERROR"
+	}
+	return name
+}
+
+// MarshalJSON serializes the WindowOpcode as a JSON string.
+// It's used for testing and diagnostics.
+func (code WindowOpcode) MarshalJSON() ([]byte, error) {
+	return ([]byte)(fmt.Sprintf("\"%s\"", code.String())), nil
+}
+
+// SQLType returns the type of the values produced by the window function, given the type of its argument
+func (code WindowOpcode) SQLType(typ querypb.Type) querypb.Type {
+	switch code {
+	case WindowUnassigned:
+		return sqltypes.Null
+	case WindowRowNumber, WindowRank, WindowDenseRank, WindowNtile, WindowCount, WindowCountStar:
+		return sqltypes.Int64
+	case WindowPercentRank, WindowCumeDist:
+		return sqltypes.Float64
+	case WindowLag, WindowLead, WindowFirstValue, WindowLastValue, WindowNthValue, WindowMin, WindowMax:
+		return typ
+	case WindowSum, WindowAvg:
+		return AggregateSum.SQLType(typ)
+	default:
+		panic(code.String()) // we have a unit test checking we never reach here
+	}
+}
+
+// IsAggregation returns true for the aggregation functions that are being used as window functions
+func (code WindowOpcode) IsAggregation() bool {
+	switch code {
+	case WindowCount, WindowCountStar, WindowSum, WindowMin, WindowMax, WindowAvg:
+		return true
+	default:
+		return false
+	}
+}
+
+// UsesFrame returns true if the window function is evaluated over the window frame.
+// The other window functions are evaluated over the whole partition, ignoring any frame clause.
+func (code WindowOpcode) UsesFrame() bool {
+	switch code {
+	case WindowFirstValue, WindowLastValue, WindowNthValue:
+		return true
+	default:
+		return code.IsAggregation()
+	}
+}
@@ -34,6 +34,40 @@ func TestCheckAllAggrOpCodes(t *testing.T) {
 	}
 }
 
+func TestCheckAllWindowOpCodes(t *testing.T) {
+	// This test is just checking that we never reach the panic when using SQLType() on valid opcodes
+	for i := WindowOpcode(0); i < _NumOfWindowOpCodes; i++ {
+		i.SQLType(sqltypes.Null)
+		if i != WindowUnassigned {
+			assert.NotEqual(t, "ERROR", i.String(), "missing name for window opcode %d", i)
+		}
+	}
+}
+
+func TestWindowType(t *testing.T) {
+	tt := []struct {
+		opcode WindowOpcode
+		typ    querypb.Type
+		out    querypb.Type
+	}{
+		{WindowRowNumber, sqltypes.Null, sqltypes.Int64},
+		{WindowRank, sqltypes.Null, sqltypes.Int64},
+		{WindowCumeDist, sqltypes.Null, sqltypes.Float64},
+		{WindowLag, sqltypes.VarChar, sqltypes.VarChar},
+		{WindowFirstValue, sqltypes.Datetime, sqltypes.Datetime},
+		{WindowSum, sqltypes.Int64, sqltypes.Decimal},
+		{WindowSum, sqltypes.Float32, sqltypes.Float64},
+		{WindowAvg, sqltypes.Int32, sqltypes.Decimal},
+		{WindowCount, sqltypes.VarChar, sqltypes.Int64},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.opcode.String()+"_"+tc.typ.String(), func(t *testing.T) {
+			assert.Equal(t, tc.out, tc.opcode.SQLType(tc.typ))
+		})
+	}
+}
+
 func TestType(t *testing.T) {
 	tt := []struct {
 		opcode AggregateOpcode
@@ -52,6 +86,7 @@ func TestType(t *testing.T) {
 		{AggregateCount, sqltypes.Int32, sqltypes.Int64},
 		{AggregateCountStar, sqltypes.Int64, sqltypes.Int64},
 		{AggregateGtid, sqltypes.VarChar, sqltypes.VarChar},
+		{AggregateGrouping, sqltypes.VarChar, sqltypes.Int64},
 	}
 
 	for _, tc := range tt {
@@ -130,6 +165,7 @@ func TestAggregateOpcode_MarshalJSON(t *testing.T) {
 		{AggregateGroupConcat, "\"group_concat\""},
 		{AggregateAnyValue, "\"any_value\""},
 		{AggregateAvg, "\"avg\""},
+		{AggregateGrouping, "\"grouping\""},
 		{999, "\"ERROR\""},
 	}
 
@@ -49,6 +49,11 @@ type OrderedAggregate struct {
 	// from the result received. If 0, no truncation happens.
 	TruncateColumnCount int `json:",omitempty"`
 
+	// WithRollup is set when the query uses GROUP BY ... WITH ROLLUP.
+	// Super-aggregate rows will be added after the rows of every group,
+	// with the rolled up group by columns set to NULL.
+	WithRollup bool `json:",omitempty"`
+
 	// Input is the primitive that will feed into this Primitive.
 	Input Primitive
 }
@@ -142,6 +147,9 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 	if err != nil {
 		return nil, err
 	}
+	if oa.WithRollup {
+		return oa.executeRollup(result)
+	}
 	if len(oa.Aggregates) == 0 {
 		return oa.executeGroupBy(result)
 	}
@@ -182,6 +190,43 @@ func (oa *OrderedAggregate) execute(ctx context.Context, vcursor VCursor, bindVa
 	return out, nil
 }
 
+func (oa *OrderedAggregate) executeRollup(result *sqltypes.Result) (*sqltypes.Result, error) {
+	rollup, fields, err := newRollupAggregation(result.Fields, oa.Aggregates, oa.GroupByKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqltypes.Result{
+		Fields: fields,
+		Rows:   make([][]sqltypes.Value, 0, len(result.Rows)),
+	}
+
+	var currentKey []sqltypes.Value
+	for _, row := range result.Rows {
+		var changed int
+
+		currentKey, changed, err = oa.firstChangedKey(currentKey, row)
+		if err != nil {
+			return nil, err
+		}
+
+		if changed >= 0 {
+			// all the groups that include the changed column are done
+			out.Rows = append(out.Rows, rollup.finish(len(oa.GroupByKeys)-changed)...)
+		}
+
+		if err := rollup.add(row); err != nil {
+			return nil, err
+		}
+	}
+
+	if currentKey != nil {
+		out.Rows = append(out.Rows, rollup.finish(len(rollup))...)
+	}
+
+	return out, nil
+}
+
 func (oa *OrderedAggregate) executeStreamGroupBy(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
 	cb := func(qr *sqltypes.Result) error {
 		return callback(qr.Truncate(oa.TruncateColumnCount))
@@ -233,8 +278,69 @@ func (oa *OrderedAggregate) executeStreamGroupBy(ctx context.Context, vcursor VC
 	return nil
 }
 
+func (oa *OrderedAggregate) executeStreamRollup(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, callback func(*sqltypes.Result) error) error {
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(oa.TruncateColumnCount))
+	}
+
+	var rollup rollupState
+	var fields []*querypb.Field
+	var currentKey []sqltypes.Value
+
+	visitor := func(qr *sqltypes.Result) error {
+		var err error
+
+		if rollup == nil && len(qr.Fields) != 0 {
+			rollup, fields, err = newRollupAggregation(qr.Fields, oa.Aggregates, oa.GroupByKeys)
+			if err != nil {
+				return err
+			}
+			if err = cb(&sqltypes.Result{Fields: fields}); err != nil {
+				return err
+			}
+		}
+
+		// This code is similar to the one in executeRollup.
+		for _, row := range qr.Rows {
+			var changed int
+
+			currentKey, changed, err = oa.firstChangedKey(currentKey, row)
+			if err != nil {
+				return err
+			}
+
+			if changed >= 0 {
+				if err := cb(&sqltypes.Result{Rows: rollup.finish(len(oa.GroupByKeys) - changed)}); err != nil {
+					return err
+				}
+			}
+
+			if err := rollup.add(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	/* we need the input fields types to correctly calculate the output types */
+	err := vcursor.StreamExecutePrimitive(ctx, oa.Input, bindVars, true, visitor)
+	if err != nil {
+		return err
+	}
+
+	if currentKey != nil {
+		if err := cb(&sqltypes.Result{Rows: rollup.finish(len(rollup))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // TryStreamExecute is a Primitive function.
 func (oa *OrderedAggregate) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) error {
+	if oa.WithRollup {
+		return oa.executeStreamRollup(ctx, vcursor, bindVars, callback)
+	}
 	if len(oa.Aggregates) == 0 {
 		return oa.executeStreamGroupBy(ctx, vcursor, bindVars, callback)
 	}
@@ -326,34 +432,41 @@ func (oa *OrderedAggregate) NeedsTransaction() bool {
 }
 
 func (oa *OrderedAggregate) nextGroupBy(currentKey, nextRow []sqltypes.Value) (nextKey []sqltypes.Value, nextGroup bool, err error) {
+	nextKey, changed, err := oa.firstChangedKey(currentKey, nextRow)
+	return nextKey, changed >= 0, err
+}
+
+// firstChangedKey returns the index of the first group by key that has a different value in nextRow.
+// If nextRow belongs to the current group, -1 is returned.
+func (oa *OrderedAggregate) firstChangedKey(currentKey, nextRow []sqltypes.Value) (nextKey []sqltypes.Value, changed int, err error) {
 	if currentKey == nil {
-		return nextRow, false, nil
+		return nextRow, -1, nil
 	}
 
-	for _, gb := range oa.GroupByKeys {
+	for idx, gb := range oa.GroupByKeys {
 		v1 := currentKey[gb.KeyCol]
 		v2 := nextRow[gb.KeyCol]
 		if v1.TinyWeightCmp(v2) != 0 {
-			return nextRow, true, nil
+			return nextRow, idx, nil
 		}
 
 		cmp, err := evalengine.NullsafeCompare(v1, v2, gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
 		if err != nil {
 			_, isCollationErr := err.(evalengine.UnsupportedCollationError)
 			if !isCollationErr || gb.WeightStringCol == -1 {
-				return nil, false, err
+				return nil, -1, err
 			}
 			gb.KeyCol = gb.WeightStringCol
 			cmp, err = evalengine.NullsafeCompare(currentKey[gb.WeightStringCol], nextRow[gb.WeightStringCol], gb.CollationEnv, gb.Type.Collation(), gb.Type.Values())
 			if err != nil {
-				return nil, false, err
+				return nil, -1, err
 			}
 		}
 		if cmp != 0 {
-			return nextRow, true, nil
+			return nextRow, idx, nil
 		}
 	}
-	return currentKey, false, nil
+	return currentKey, -1, nil
 }
 func aggregateParamsToString(in any) string {
 	return in.(*AggregateParams).String()
@@ -373,6 +486,9 @@ func (oa *OrderedAggregate) description() PrimitiveDescription {
 	if oa.TruncateColumnCount > 0 {
 		other["ResultColumns"] = oa.TruncateColumnCount
 	}
+	if oa.WithRollup {
+		other["WithRollup"] = true
+	}
 	return PrimitiveDescription{
 		OperatorType: "Aggregate",
 		Variant:      "Ordered",
@@ -32,6 +32,7 @@ import (
 	"vitess.io/vitess/go/test/utils"
 	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
 	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
 )
 
@@ -1164,3 +1165,238 @@ func TestGroupConcat(t *testing.T) {
 		})
 	}
 }
+
+func TestCountDistinctMultipleColumns(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3",
+		"int64|varchar|int64",
+	)
+	fields[1].Charset = uint32(collations.MySQL8().DefaultConnectionCharset())
+
+	// the input is not sorted by the distinct columns
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"10|a|1",
+			"10|b|1",
+			"10|A|1",
+			"10|a|2",
+			"10|null|1",
+			"10|b|null",
+			"20|a|1",
+			"20|a|1",
+			"30|null|null",
+		)},
+	}
+
+	aggr := NewAggregateParam(AggregateCountDistinct, 1, "count(distinct c2, c3)", collations.MySQL8())
+	aggr.ExtraCols = []int{2}
+	oa := &OrderedAggregate{
+		Aggregates:          []*AggregateParams{aggr},
+		GroupByKeys:         []*GroupByParams{{KeyCol: 0}},
+		TruncateColumnCount: 2,
+		Input:               fp,
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"c1|count(distinct c2, c3)",
+			"int64|int64",
+		),
+		`10|3`,
+		`20|1`,
+		`30|0`,
+	)
+	want.Fields[1].Charset = fields[1].Charset
+
+	qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, qr)
+
+	fp.rewind()
+	results := &sqltypes.Result{}
+	err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			results.Fields = qr.Fields
+		}
+		results.Rows = append(results.Rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, want, results)
+}
+
+func TestGroupConcatMultipleColumns(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"c1|c2|c3|c4",
+		"int64|varchar|varchar|int64",
+	)
+	fields[1].Charset = uint32(collations.MySQL8().DefaultConnectionCharset())
+	fields[2].Charset = uint32(collations.MySQL8().DefaultConnectionCharset())
+
+	var tcases = []struct {
+		name     string
+		expr     string
+		orderBy  evalengine.Comparison
+		expected []string
+	}{{
+		name:     "multiple arguments",
+		expr:     "group_concat(c2, c3)",
+		expected: []string{`1|ax,by,ax,dz`, `2|ef`, `3|null`},
+	}, {
+		name:     "separator",
+		expr:     "group_concat(c2, c3 separator '; ')",
+		expected: []string{`1|ax; by; ax; dz`, `2|ef`, `3|null`},
+	}, {
+		name:     "distinct",
+		expr:     "group_concat(distinct c2, c3)",
+		expected: []string{`1|ax,by,dz`, `2|ef`, `3|null`},
+	}, {
+		name:     "order by",
+		expr:     "group_concat(c2, c3 order by c4 desc separator '')",
+		orderBy:  evalengine.Comparison{{Col: 3, WeightStringCol: -1, Desc: true}},
+		expected: []string{`1|bydzaxax`, `2|ef`, `3|null`},
+	}, {
+		name:     "distinct and order by",
+		expr:     "group_concat(distinct c2, c3 order by c4)",
+		orderBy:  evalengine.Comparison{{Col: 3, WeightStringCol: -1}},
+		expected: []string{`1|ax,dz,by`, `2|ef`, `3|null`},
+	}}
+
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			fp := &fakePrimitive{results: []*sqltypes.Result{sqltypes.MakeTestResult(fields,
+				"1|a|x|1", "1|b|y|4", "1|a|x|2", "1|c|null|5", "1|d|z|3",
+				"2|e|f|null",
+				"3|null|a|1")}}
+
+			expr, err := sqlparser.NewTestParser().ParseExpr(tcase.expr)
+			require.NoError(t, err)
+			aggr := NewAggregateParam(AggregateGroupConcat, 1, "", collations.MySQL8())
+			aggr.Expr = expr
+			aggr.ExtraCols = []int{2}
+			aggr.OrderBy = tcase.orderBy
+			oa := &OrderedAggregate{
+				Aggregates:          []*AggregateParams{aggr},
+				GroupByKeys:         []*GroupByParams{{KeyCol: 0}},
+				TruncateColumnCount: 2,
+				Input:               fp,
+			}
+
+			want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("c1|c2", "int64|text"), tcase.expected...)
+			want.Fields[1].Charset = fields[1].Charset
+			qr, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+			require.NoError(t, err)
+			utils.MustMatch(t, want, qr)
+
+			fp.rewind()
+			results := &sqltypes.Result{}
+			err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+				if qr.Fields != nil {
+					results.Fields = qr.Fields
+				}
+				results.Rows = append(results.Rows, qr.Rows...)
+				return nil
+			})
+			require.NoError(t, err)
+			utils.MustMatch(t, want, results)
+		})
+	}
+}
+
+func TestOrderedAggregateWithRollup(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"a|b|sum(c)|grouping(a, b)",
+		"varbinary|varbinary|decimal|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"x|1|1|0",
+			"x|1|2|0",
+			"x|2|3|0",
+			"y|1|4|0",
+		)},
+	}
+
+	grouping := NewAggregateParam(AggregateGrouping, 3, "", collations.MySQL8())
+	grouping.GroupingKeys = []int{0, 1}
+	oa := &OrderedAggregate{
+		Aggregates: []*AggregateParams{
+			NewAggregateParam(AggregateSum, 2, "", collations.MySQL8()),
+			grouping,
+		},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}, {KeyCol: 1, WeightStringCol: -1}},
+		WithRollup:  true,
+		Input:       fp,
+	}
+
+	wantResult := sqltypes.MakeTestResult(
+		fields,
+		"x|1|3|0",
+		"x|2|3|0",
+		"x|null|6|1",
+		"y|1|4|0",
+		"y|null|4|1",
+		"null|null|10|3",
+	)
+
+	result, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, result)
+
+	fp.rewind()
+	results := &sqltypes.Result{}
+	err = oa.TryStreamExecute(context.Background(), &noopVCursor{}, nil, true, func(qr *sqltypes.Result) error {
+		if qr.Fields != nil {
+			results.Fields = qr.Fields
+		}
+		results.Rows = append(results.Rows, qr.Rows...)
+		return nil
+	})
+	require.NoError(t, err)
+	utils.MustMatch(t, wantResult, results)
+}
+
+func TestOrderedAggregateWithRollupEmptyInput(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"a|count(*)",
+		"varbinary|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(fields)},
+	}
+
+	aggr := NewAggregateParam(AggregateSum, 1, "", collations.MySQL8())
+	aggr.OrigOpcode = AggregateCountStar
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{aggr},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		WithRollup:  true,
+		Input:       fp,
+	}
+
+	// just like in MySQL, no super-aggregate rows are produced when there are no groups
+	result, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Rows)
+}
+
+func TestOrderedAggregateWithRollupDistinct(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("a|b", "varbinary|int64"),
+			"x|1",
+		)},
+	}
+
+	oa := &OrderedAggregate{
+		Aggregates:  []*AggregateParams{NewAggregateParam(AggregateCountDistinct, 1, "", collations.MySQL8())},
+		GroupByKeys: []*GroupByParams{{KeyCol: 0, WeightStringCol: -1}},
+		WithRollup:  true,
+		Input:       fp,
+	}
+
+	_, err := oa.TryExecute(context.Background(), &noopVCursor{}, nil, false)
+	require.EqualError(t, err, "VT12001: unsupported: distinct aggregation together with WITH ROLLUP")
+}
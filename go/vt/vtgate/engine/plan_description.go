@@ -47,6 +47,9 @@ type PrimitiveDescription struct {
 
 	InputName string
 	Inputs    []PrimitiveDescription
+
+	// Stats are the runtime statistics of the primitive, only set by VEXPLAIN TRACE.
+	Stats *PrimitiveStats
 }
 
 // MarshalJSON serializes the PlanDescription into a JSON representation.
@@ -95,6 +98,12 @@ func (pd PrimitiveDescription) MarshalJSON() ([]byte, error) {
 		return nil, err
 	}
 
+	if pd.Stats != nil {
+		if err := marshalAdd(prepend, buf, "Stats", pd.Stats); err != nil {
+			return nil, err
+		}
+	}
+
 	if len(pd.Inputs) > 0 {
 		if err := marshalAdd(prepend, buf, "Inputs", pd.Inputs); err != nil {
 			return nil, err
@@ -51,6 +51,9 @@ type (
 		// MaxMemoryRows returns the maxMemoryRows flag value.
 		MaxMemoryRows() int
 
+		// MaxCTERecursionDepth returns the maximum number of iterations allowed when evaluating a recursive CTE
+		MaxCTERecursionDepth() int
+
 		// ExceedsMaxMemoryRows returns a boolean indicating whether
 		// the maxMemoryRows value has been exceeded. Returns false
 		// if the max memory rows override directive is set to true
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+var _ Primitive = (*RecurseCTE)(nil)
+
+// RecurseCTE is used to evaluate recursive CTEs.
+// The Seed produces the initial rows. The Term is then executed once for every row
+// produced by the previous iteration, with the columns of that row passed in as bind variables,
+// until an iteration produces no new rows.
+type RecurseCTE struct {
+	Seed, Term Primitive
+
+	// Vars maps the bind variables used by the Term to the
+	// offsets of the columns in the rows of the previous iteration
+	Vars map[string]int
+
+	// CheckCols is set when the seed and term are combined using UNION DISTINCT.
+	// Rows that have already been produced are then not used again.
+	CheckCols []CheckCol
+}
+
+// TryExecute implements the Primitive interface
+func (r *RecurseCTE) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	res, err := vcursor.ExecutePrimitive(ctx, r.Seed, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	var pt *probeTable
+	if r.CheckCols != nil {
+		pt = newProbeTable(r.CheckCols, vcursor.Environment().CollationEnv())
+		res.Rows, err = r.newRows(pt, res.Rows)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	rows := res.Rows
+	for iteration := 1; len(rows) > 0; iteration++ {
+		if iteration > vcursor.MaxCTERecursionDepth() {
+			return nil, vterrors.VT09025(iteration)
+		}
+		var next []sqltypes.Row
+		for _, row := range rows {
+			termRes, err := vcursor.ExecutePrimitive(ctx, r.Term, r.bindVarsFor(bindVars, row), false)
+			if err != nil {
+				return nil, err
+			}
+			next = append(next, termRes.Rows...)
+		}
+		if pt != nil {
+			next, err = r.newRows(pt, next)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res.Rows = append(res.Rows, next...)
+		if vcursor.ExceedsMaxMemoryRows(len(res.Rows)) {
+			return nil, fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		rows = next
+	}
+	return res, nil
+}
+
+func (r *RecurseCTE) bindVarsFor(bindVars map[string]*querypb.BindVariable, row sqltypes.Row) map[string]*querypb.BindVariable {
+	cteVars := make(map[string]*querypb.BindVariable, len(r.Vars))
+	for k, col := range r.Vars {
+		cteVars[k] = sqltypes.ValueBindVariable(row[col])
+	}
+	return combineVars(bindVars, cteVars)
+}
+
+// newRows filters out the rows that have already been seen
+func (r *RecurseCTE) newRows(pt *probeTable, rows []sqltypes.Row) ([]sqltypes.Row, error) {
+	var result []sqltypes.Row
+	for _, row := range rows {
+		newRow, err := pt.exists(row)
+		if err != nil {
+			return nil, err
+		}
+		if newRow != nil {
+			result = append(result, newRow)
+		}
+	}
+	return result, nil
+}
+
+// TryStreamExecute implements the Primitive interface
+func (r *RecurseCTE) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	// every iteration needs all the rows of the previous one, so there is not much to gain from streaming
+	res, err := r.TryExecute(ctx, vcursor, bindVars, wantfields)
+	if err != nil {
+		return err
+	}
+	return callback(res)
+}
+
+// GetFields implements the Primitive interface
+func (r *RecurseCTE) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	return r.Seed.GetFields(ctx, vcursor, bindVars)
+}
+
+// Inputs implements the Primitive interface
+func (r *RecurseCTE) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{r.Seed, r.Term}, nil
+}
+
+// RouteType implements the Primitive interface
+func (r *RecurseCTE) RouteType() string {
+	return "RecurseCTE"
+}
+
+// GetKeyspaceName implements the Primitive interface
+func (r *RecurseCTE) GetKeyspaceName() string {
+	if r.Seed.GetKeyspaceName() == r.Term.GetKeyspaceName() {
+		return r.Seed.GetKeyspaceName()
+	}
+	return r.Seed.GetKeyspaceName() + "_" + r.Term.GetKeyspaceName()
+}
+
+// GetTableName implements the Primitive interface
+func (r *RecurseCTE) GetTableName() string {
+	return r.Seed.GetTableName()
+}
+
+// NeedsTransaction implements the Primitive interface
+func (r *RecurseCTE) NeedsTransaction() bool {
+	return r.Seed.NeedsTransaction() || r.Term.NeedsTransaction()
+}
+
+func (r *RecurseCTE) description() PrimitiveDescription {
+	other := map[string]any{
+		"JoinVars": orderedStringIntMap(r.Vars),
+	}
+	variant := ""
+	if r.CheckCols != nil {
+		variant = "Distinct"
+		var colls []string
+		for _, checkCol := range r.CheckCols {
+			colls = append(colls, checkCol.String())
+		}
+		other["Collations"] = colls
+	}
+
+	return PrimitiveDescription{
+		OperatorType: "RecurseCTE",
+		Variant:      variant,
+		Other:        other,
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func TestRecurseCTEExecute(t *testing.T) {
+	fields := sqltypes.MakeTestFields("n", "int64")
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1", "2"),
+		},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "3"),
+			sqltypes.MakeTestResult(fields),
+			sqltypes.MakeTestResult(fields),
+		},
+	}
+	rcte := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{"cte_n": 0},
+	}
+
+	r, err := rcte.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+
+	seed.ExpectLog(t, []string{
+		`Execute  true`,
+	})
+	term.ExpectLog(t, []string{
+		`Execute cte_n: type:INT64 value:"1" false`,
+		`Execute cte_n: type:INT64 value:"2" false`,
+		`Execute cte_n: type:INT64 value:"3" false`,
+	})
+	expectResult(t, r, sqltypes.MakeTestResult(fields, "1", "2", "3"))
+}
+
+func TestRecurseCTEDistinct(t *testing.T) {
+	fields := sqltypes.MakeTestFields("n", "int64")
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1", "1"),
+		},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "2", "1"),
+			sqltypes.MakeTestResult(fields, "1"),
+		},
+	}
+	rcte := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{"cte_n": 0},
+		CheckCols: []CheckCol{{
+			Col:          0,
+			Type:         evalengine.NewTypeEx(sqltypes.Int64, collations.CollationBinaryID, false, 0, 0, nil),
+			CollationEnv: collations.MySQL8(),
+		}},
+	}
+
+	r, err := rcte.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+
+	term.ExpectLog(t, []string{
+		`Execute cte_n: type:INT64 value:"1" false`,
+		`Execute cte_n: type:INT64 value:"2" false`,
+	})
+	expectResult(t, r, sqltypes.MakeTestResult(fields, "1", "2"))
+}
+
+func TestRecurseCTEMaxRecursionDepth(t *testing.T) {
+	saveMax := testMaxCTERecursionDepth
+	testMaxCTERecursionDepth = 2
+	defer func() {
+		testMaxCTERecursionDepth = saveMax
+	}()
+
+	fields := sqltypes.MakeTestFields("n", "int64")
+	seed := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1"),
+		},
+	}
+	term := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "2"),
+			sqltypes.MakeTestResult(fields, "3"),
+		},
+	}
+	rcte := &RecurseCTE{
+		Seed: seed,
+		Term: term,
+		Vars: map[string]int{"cte_n": 0},
+	}
+
+	_, err := rcte.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.EqualError(t, err, "VT09025: Recursive query aborted after 3 iterations. Try increasing @@cte_max_recursion_depth or --cte-max-recursion-depth to a larger value")
+}
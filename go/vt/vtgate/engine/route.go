@@ -152,6 +152,7 @@ type cxtKey int
 
 const (
 	IgnoreReserveTxn cxtKey = iota
+	localInfileKey
 )
 
 func (route *Route) executeInternal(
@@ -18,15 +18,21 @@ package engine
 
 import (
 	"context"
+	"fmt"
 
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/vterrors"
 )
 
 var _ Primitive = (*SemiJoin)(nil)
 
 // SemiJoin specifies the parameters for a SemiJoin primitive.
+// The RHS is a correlated subquery that is executed once for every row of the LHS.
 type SemiJoin struct {
+	// Opcode decides what is done with the rows the subquery returns.
+	Opcode SemiJoinOpcode
+
 	// Left and Right are the LHS and RHS primitives
 	// of the SemiJoin. They can be any primitive.
 	Left, Right Primitive `json:",omitempty"`
@@ -37,6 +43,56 @@ type SemiJoin struct {
 	Vars map[string]int `json:",omitempty"`
 }
 
+// SemiJoinOpcode is a number representing the opcode
+// for the SemiJoin primitive.
+type SemiJoinOpcode int
+
+// This is the list of SemiJoinOpcode values.
+const (
+	// SemiJoinExists keeps the rows of the LHS for which the subquery returns rows.
+	SemiJoinExists = SemiJoinOpcode(iota)
+	// SemiJoinNotExists keeps the rows of the LHS for which the subquery returns no rows.
+	SemiJoinNotExists
+	// SemiJoinValue keeps all the rows of the LHS, and adds the value returned by the subquery
+	// as the first column of the row. The value is NULL if the subquery returns no rows.
+	SemiJoinValue
+	// SemiJoinHasValues keeps all the rows of the LHS, and adds 1 as the first column of the row
+	// if the subquery returns rows, and 0 if it does not.
+	SemiJoinHasValues
+	// SemiJoinIn keeps all the rows of the LHS, and adds the result of an IN comparison as the
+	// first column of the row. The subquery returns the result of comparing the value of the LHS
+	// with each of its values, so the result is 1 if any of these is true, NULL if none are true
+	// but some are NULL, and 0 otherwise.
+	SemiJoinIn
+	// SemiJoinNotIn is the same as SemiJoinIn, but for NOT IN comparisons.
+	SemiJoinNotIn
+)
+
+var semiJoinName = map[SemiJoinOpcode]string{
+	SemiJoinExists:    "Exists",
+	SemiJoinNotExists: "NotExists",
+	SemiJoinValue:     "Value",
+	SemiJoinHasValues: "HasValues",
+	SemiJoinIn:        "In",
+	SemiJoinNotIn:     "NotIn",
+}
+
+func (code SemiJoinOpcode) String() string {
+	return semiJoinName[code]
+}
+
+// MarshalJSON serializes the SemiJoinOpcode as a JSON string.
+// It's used for testing and diagnostics.
+func (code SemiJoinOpcode) MarshalJSON() ([]byte, error) {
+	return ([]byte)(fmt.Sprintf("\"%s\"", code.String())), nil
+}
+
+// IsProjection returns true if the SemiJoin adds a column to the rows of the LHS
+// instead of filtering them.
+func (code SemiJoinOpcode) IsProjection() bool {
+	return code != SemiJoinExists && code != SemiJoinNotExists
+}
+
 // TryExecute performs a non-streaming exec.
 func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
 	joinVars := make(map[string]*querypb.BindVariable)
@@ -45,6 +101,15 @@ func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 		return nil, err
 	}
 	result := &sqltypes.Result{Fields: lresult.Fields}
+	if jn.Opcode.IsProjection() {
+		result.Fields = nil
+		if wantfields {
+			result.Fields, err = jn.joinFields(ctx, vcursor, bindVars, lresult.Fields)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
 	for _, lrow := range lresult.Rows {
 		for k, col := range jn.Vars {
 			joinVars[k] = sqltypes.ValueBindVariable(lrow[col])
@@ -53,8 +118,12 @@ func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 		if err != nil {
 			return nil, err
 		}
-		if len(rresult.Rows) > 0 {
-			result.Rows = append(result.Rows, lrow)
+		row, err := jn.joinRow(lrow, rresult.Rows)
+		if err != nil {
+			return nil, err
+		}
+		if row != nil {
+			result.Rows = append(result.Rows, row)
 		}
 	}
 	return result, nil
@@ -63,32 +132,147 @@ func (jn *SemiJoin) TryExecute(ctx context.Context, vcursor VCursor, bindVars ma
 // TryStreamExecute performs a streaming exec.
 func (jn *SemiJoin) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
 	joinVars := make(map[string]*querypb.BindVariable)
+	fieldsSent := !wantfields
 	err := vcursor.StreamExecutePrimitive(ctx, jn.Left, bindVars, wantfields, func(lresult *sqltypes.Result) error {
-		result := &sqltypes.Result{Fields: lresult.Fields}
+		result := &sqltypes.Result{}
+		switch {
+		case !jn.Opcode.IsProjection():
+			result.Fields = lresult.Fields
+		case !fieldsSent && lresult.Fields != nil:
+			fields, err := jn.joinFields(ctx, vcursor, bindVars, lresult.Fields)
+			if err != nil {
+				return err
+			}
+			result.Fields = fields
+			fieldsSent = true
+		}
 		for _, lrow := range lresult.Rows {
 			for k, col := range jn.Vars {
 				joinVars[k] = sqltypes.ValueBindVariable(lrow[col])
 			}
-			rowAdded := false
+			var rrows []sqltypes.Row
 			err := vcursor.StreamExecutePrimitive(ctx, jn.Right, combineVars(bindVars, joinVars), false, func(rresult *sqltypes.Result) error {
-				if len(rresult.Rows) > 0 && !rowAdded {
-					result.Rows = append(result.Rows, lrow)
-					rowAdded = true
+				if !jn.Opcode.IsProjection() && len(rrows) > 0 {
+					// we already know that the subquery returns rows, and the rows themselves are not needed
+					return nil
 				}
+				rrows = append(rrows, rresult.Rows...)
 				return nil
 			})
 			if err != nil {
 				return err
 			}
+			row, err := jn.joinRow(lrow, rrows)
+			if err != nil {
+				return err
+			}
+			if row != nil {
+				result.Rows = append(result.Rows, row)
+			}
 		}
 		return callback(result)
 	})
 	return err
 }
 
+// joinRow returns the row to send for the given row of the LHS and the rows the subquery returned for it.
+// A nil row means that the row of the LHS is filtered out.
+func (jn *SemiJoin) joinRow(lrow sqltypes.Row, rrows []sqltypes.Row) (sqltypes.Row, error) {
+	var value sqltypes.Value
+	switch jn.Opcode {
+	case SemiJoinExists:
+		if len(rrows) == 0 {
+			return nil, nil
+		}
+		return lrow, nil
+	case SemiJoinNotExists:
+		if len(rrows) > 0 {
+			return nil, nil
+		}
+		return lrow, nil
+	case SemiJoinValue:
+		switch len(rrows) {
+		case 0:
+			value = sqltypes.NULL
+		case 1:
+			value = rrows[0][0]
+		default:
+			return nil, vterrors.VT03035()
+		}
+	case SemiJoinHasValues:
+		value = boolValue(len(rrows) > 0)
+	case SemiJoinIn, SemiJoinNotIn:
+		matched, sawNull, err := anyRowMatches(rrows)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case matched:
+			value = boolValue(jn.Opcode == SemiJoinIn)
+		case sawNull:
+			value = sqltypes.NULL
+		default:
+			value = boolValue(jn.Opcode == SemiJoinNotIn)
+		}
+	default:
+		return nil, vterrors.VT13001(fmt.Sprintf("unknown semi join opcode: %d", jn.Opcode))
+	}
+	row := make(sqltypes.Row, 0, len(lrow)+1)
+	row = append(row, value)
+	return append(row, lrow...), nil
+}
+
+// anyRowMatches checks if the first column of any of the rows is true, and if not, if any of them is NULL.
+// This is what is needed to evaluate IN comparisons the way MySQL does.
+func anyRowMatches(rows []sqltypes.Row) (matched, sawNull bool, err error) {
+	for _, row := range rows {
+		if row[0].IsNull() {
+			sawNull = true
+			continue
+		}
+		matched, err = row[0].ToBool()
+		if err != nil || matched {
+			return matched, false, err
+		}
+	}
+	return false, sawNull, nil
+}
+
+func boolValue(b bool) sqltypes.Value {
+	if b {
+		return sqltypes.NewInt64(1)
+	}
+	return sqltypes.NewInt64(0)
+}
+
+// joinFields returns the fields of the LHS, with the field of the value the SemiJoin adds in front of them.
+func (jn *SemiJoin) joinFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, lfields []*querypb.Field) ([]*querypb.Field, error) {
+	field := &querypb.Field{Name: jn.Opcode.String(), Type: sqltypes.Int64}
+	if jn.Opcode == SemiJoinValue {
+		joinVars := make(map[string]*querypb.BindVariable)
+		for k := range jn.Vars {
+			joinVars[k] = sqltypes.NullBindVariable
+		}
+		rresult, err := jn.Right.GetFields(ctx, vcursor, combineVars(bindVars, joinVars))
+		if err != nil {
+			return nil, err
+		}
+		field = rresult.Fields[0]
+	}
+	return append([]*querypb.Field{field}, lfields...), nil
+}
+
 // GetFields fetches the field info.
 func (jn *SemiJoin) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
-	return jn.Left.GetFields(ctx, vcursor, bindVars)
+	lresult, err := jn.Left.GetFields(ctx, vcursor, bindVars)
+	if err != nil || !jn.Opcode.IsProjection() {
+		return lresult, err
+	}
+	fields, err := jn.joinFields(ctx, vcursor, bindVars, lresult.Fields)
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{Fields: fields}, nil
 }
 
 // Inputs returns the input primitives for this SemiJoin
@@ -132,6 +316,7 @@ func (jn *SemiJoin) description() PrimitiveDescription {
 	}
 	return PrimitiveDescription{
 		OperatorType: "SemiJoin",
+		Variant:      jn.Opcode.String(),
 		Other:        other,
 	}
 }
@@ -159,3 +159,204 @@ func TestSemiJoinStreamExecute(t *testing.T) {
 		"4|d|dd",
 	))
 }
+
+func TestSemiJoinNotExists(t *testing.T) {
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col1|col2",
+					"int64|varchar",
+				),
+				"1|a",
+				"2|b",
+				"3|c",
+			),
+		},
+	}
+	rightFields := sqltypes.MakeTestFields(
+		"col3",
+		"int64",
+	)
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(rightFields, "4"),
+			sqltypes.MakeTestResult(rightFields),
+			sqltypes.MakeTestResult(rightFields, "5", "6"),
+		},
+	}
+
+	jn := &SemiJoin{
+		Opcode: SemiJoinNotExists,
+		Left:   leftPrim,
+		Right:  rightPrim,
+		Vars: map[string]int{
+			"bv": 1,
+		},
+	}
+	r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	rightPrim.ExpectLog(t, []string{
+		`Execute bv: type:VARCHAR value:"a" false`,
+		`Execute bv: type:VARCHAR value:"b" false`,
+		`Execute bv: type:VARCHAR value:"c" false`,
+	})
+	expectResult(t, r, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col1|col2",
+			"int64|varchar",
+		),
+		"2|b",
+	))
+}
+
+func TestSemiJoinValue(t *testing.T) {
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col1|col2",
+					"int64|varchar",
+				),
+				"1|a",
+				"2|b",
+			),
+		},
+	}
+	rightFields := sqltypes.MakeTestFields(
+		"col3",
+		"int64",
+	)
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(rightFields),
+			sqltypes.MakeTestResult(rightFields, "10"),
+			sqltypes.MakeTestResult(rightFields),
+		},
+	}
+
+	jn := &SemiJoin{
+		Opcode: SemiJoinValue,
+		Left:   leftPrim,
+		Right:  rightPrim,
+		Vars: map[string]int{
+			"bv": 0,
+		},
+	}
+	r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	rightPrim.ExpectLog(t, []string{
+		`GetFields bv: `,
+		`Execute bv:  true`,
+		`Execute bv: type:INT64 value:"1" false`,
+		`Execute bv: type:INT64 value:"2" false`,
+	})
+	expectResult(t, r, sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"col3|col1|col2",
+			"int64|int64|varchar",
+		),
+		"10|1|a",
+		"null|2|b",
+	))
+}
+
+func TestSemiJoinValueTooManyRows(t *testing.T) {
+	leftPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"col1",
+					"int64",
+				),
+				"1",
+			),
+		},
+	}
+	rightFields := sqltypes.MakeTestFields(
+		"col2",
+		"int64",
+	)
+	rightPrim := &fakePrimitive{
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(rightFields, "10", "20"),
+		},
+	}
+
+	jn := &SemiJoin{
+		Opcode: SemiJoinValue,
+		Left:   leftPrim,
+		Right:  rightPrim,
+		Vars: map[string]int{
+			"bv": 0,
+		},
+	}
+	_, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "VT03035: Subquery returns more than 1 row")
+
+	leftPrim.rewind()
+	rightPrim.rewind()
+	_, err = wrapStreamExecute(jn, &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+	require.EqualError(t, err, "VT03035: Subquery returns more than 1 row")
+}
+
+func TestSemiJoinIn(t *testing.T) {
+	tcases := []struct {
+		opcode SemiJoinOpcode
+		result []string
+	}{{
+		opcode: SemiJoinHasValues,
+		result: []string{"1|1", "1|2", "0|3", "1|4"},
+	}, {
+		opcode: SemiJoinIn,
+		result: []string{"1|1", "null|2", "0|3", "1|4"},
+	}, {
+		opcode: SemiJoinNotIn,
+		result: []string{"0|1", "null|2", "1|3", "0|4"},
+	}}
+	for _, tc := range tcases {
+		t.Run(tc.opcode.String(), func(t *testing.T) {
+			leftPrim := &fakePrimitive{
+				results: []*sqltypes.Result{
+					sqltypes.MakeTestResult(
+						sqltypes.MakeTestFields(
+							"col1",
+							"int64",
+						),
+						"1",
+						"2",
+						"3",
+						"4",
+					),
+				},
+			}
+			rightFields := sqltypes.MakeTestFields(
+				"cmp",
+				"int64",
+			)
+			rightPrim := &fakePrimitive{
+				// the comparison for each row the subquery returns
+				results: []*sqltypes.Result{
+					sqltypes.MakeTestResult(rightFields, "1"),
+					sqltypes.MakeTestResult(rightFields, "null"),
+					sqltypes.MakeTestResult(rightFields),
+					sqltypes.MakeTestResult(rightFields, "null", "1"),
+				},
+			}
+
+			jn := &SemiJoin{
+				Opcode: tc.opcode,
+				Left:   leftPrim,
+				Right:  rightPrim,
+				Vars: map[string]int{
+					"bv": 0,
+				},
+			}
+			r, err := jn.TryExecute(context.Background(), &noopVCursor{}, map[string]*querypb.BindVariable{}, false)
+			require.NoError(t, err)
+			want := sqltypes.MakeTestResult(sqltypes.MakeTestFields("value|col1", "int64|int64"), tc.result...)
+			want.Fields = nil
+			expectResult(t, r, want)
+		})
+	}
+}
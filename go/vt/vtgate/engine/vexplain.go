@@ -73,6 +73,9 @@ func (v *VExplain) NeedsTransaction() bool {
 
 // TryExecute implements the Primitive interface
 func (v *VExplain) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	if v.Type == sqlparser.TraceVExplainType {
+		return v.trace(ctx, vcursor, bindVars, wantfields)
+	}
 	vcursor.Session().VExplainLogging()
 	_, err := vcursor.ExecutePrimitive(ctx, v.Input, bindVars, wantfields)
 	if err != nil {
@@ -83,6 +86,13 @@ func (v *VExplain) TryExecute(ctx context.Context, vcursor VCursor, bindVars map
 
 // TryStreamExecute implements the Primitive interface
 func (v *VExplain) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	if v.Type == sqlparser.TraceVExplainType {
+		result, err := v.trace(ctx, vcursor, bindVars, wantfields)
+		if err != nil {
+			return err
+		}
+		return callback(result)
+	}
 	vcursor.Session().VExplainLogging()
 	err := vcursor.StreamExecutePrimitive(ctx, v.Input, bindVars, wantfields, func(result *sqltypes.Result) error {
 		return nil
@@ -97,6 +107,25 @@ func (v *VExplain) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVa
 	return callback(result)
 }
 
+// trace executes the input, and returns its plan annotated with the
+// statistics of each primitive.
+func (v *VExplain) trace(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	tracer := newPrimitiveTracer()
+	_, err := tracer.vcursor(vcursor).ExecutePrimitive(ctx, v.Input, bindVars, wantfields)
+	if err != nil {
+		return nil, err
+	}
+
+	resultBytes, err := json.MarshalIndent(tracer.description(v.Input), "", "\t")
+	if err != nil {
+		return nil, err
+	}
+	return &sqltypes.Result{
+		Fields: []*querypb.Field{{Name: "Trace", Type: sqltypes.VarChar}},
+		Rows:   []sqltypes.Row{{sqltypes.NewVarChar(string(resultBytes))}},
+	}, nil
+}
+
 func (v *VExplain) convertToResult(ctx context.Context, vcursor VCursor) (*sqltypes.Result, error) {
 	switch v.Type {
 	case sqlparser.QueriesVExplainType:
@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"bytes"
+	"context"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/srvtopo"
+)
+
+type (
+	// PrimitiveStats are the runtime statistics of a primitive,
+	// recorded while executing the query for VEXPLAIN TRACE.
+	PrimitiveStats struct {
+		// Calls is the number of times the primitive was executed.
+		Calls int
+		// RowsIn is the number of rows the primitive received from its inputs and from the shards.
+		RowsIn int
+		// RowsOut is the number of rows the primitive returned.
+		RowsOut int
+		// ShardQueries is the number of queries the primitive sent to shards.
+		ShardQueries int
+		// Shards is the number of distinct shards the primitive sent queries to.
+		Shards int
+		// Time is the time spent executing the primitive, including its inputs.
+		Time time.Duration
+	}
+
+	// primitiveTracer records the statistics of the primitives executed by VEXPLAIN TRACE.
+	primitiveTracer struct {
+		// now is time.Now, except in tests.
+		now func() time.Time
+
+		mu     sync.Mutex
+		stats  map[Primitive]*PrimitiveStats
+		shards map[Primitive]map[string]struct{}
+	}
+
+	// tracedVCursor is the VCursor that the primitives of a VEXPLAIN TRACE
+	// are executed with. It wraps every primitive it executes in a
+	// tracedPrimitive, and records the queries the primitives send to shards.
+	tracedVCursor struct {
+		VCursor
+		tracer *primitiveTracer
+	}
+
+	// tracedPrimitive instruments the execution of a primitive.
+	tracedPrimitive struct {
+		Primitive
+		tracer *primitiveTracer
+	}
+)
+
+func newPrimitiveTracer() *primitiveTracer {
+	return &primitiveTracer{
+		now:    time.Now,
+		stats:  make(map[Primitive]*PrimitiveStats),
+		shards: make(map[Primitive]map[string]struct{}),
+	}
+}
+
+func (t *primitiveTracer) vcursor(vcursor VCursor) VCursor {
+	return &tracedVCursor{VCursor: vcursor, tracer: t}
+}
+
+func (t *primitiveTracer) wrap(primitive Primitive) Primitive {
+	if _, ok := primitive.(*tracedPrimitive); ok {
+		return primitive
+	}
+	return &tracedPrimitive{Primitive: primitive, tracer: t}
+}
+
+// get must be called with the mutex held.
+func (t *primitiveTracer) get(primitive Primitive) *PrimitiveStats {
+	stats := t.stats[primitive]
+	if stats == nil {
+		stats = &PrimitiveStats{}
+		t.stats[primitive] = stats
+	}
+	return stats
+}
+
+func (t *primitiveTracer) addCall(primitive Primitive, rows int, elapsed time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.get(primitive)
+	stats.Calls++
+	stats.RowsOut += rows
+	stats.Time += elapsed
+}
+
+func (t *primitiveTracer) addRows(primitive Primitive, rows int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(primitive).RowsOut += rows
+}
+
+func (t *primitiveTracer) addShardQueries(primitive Primitive, rss []*srvtopo.ResolvedShard) {
+	if primitive == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	stats := t.get(primitive)
+	stats.ShardQueries += len(rss)
+	shards := t.shards[primitive]
+	if shards == nil {
+		shards = make(map[string]struct{})
+		t.shards[primitive] = shards
+	}
+	for _, rs := range rss {
+		shards[rs.Target.Keyspace+"/"+rs.Target.Shard] = struct{}{}
+	}
+	stats.Shards = len(shards)
+}
+
+func (t *primitiveTracer) addShardRows(primitive Primitive, rows int) {
+	if primitive == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.get(primitive).RowsIn += rows
+}
+
+// description returns the plan description of the primitive, annotated with the statistics.
+func (t *primitiveTracer) description(in Primitive) PrimitiveDescription {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.describe(in)
+}
+
+func (t *primitiveTracer) describe(in Primitive) PrimitiveDescription {
+	this := in.description()
+	stats := PrimitiveStats{}
+	if s := t.stats[in]; s != nil {
+		stats = *s
+	}
+
+	inputs, infos := in.Inputs()
+	for idx, input := range inputs {
+		pd := t.describe(input)
+		if infos != nil {
+			for k, v := range infos[idx] {
+				if k == inputName {
+					pd.InputName = v.(string)
+					continue
+				}
+				if pd.Other == nil {
+					pd.Other = map[string]any{}
+				}
+				pd.Other[k] = v
+			}
+		}
+		if pd.Stats != nil {
+			stats.RowsIn += pd.Stats.RowsOut
+		}
+		this.Inputs = append(this.Inputs, pd)
+	}
+	this.Stats = &stats
+
+	if len(inputs) == 0 {
+		this.Inputs = []PrimitiveDescription{}
+	}
+
+	return this
+}
+
+// MarshalJSON serializes the statistics, with the time in a readable form.
+func (s PrimitiveStats) MarshalJSON() ([]byte, error) {
+	buf := &bytes.Buffer{}
+	buf.WriteString("{")
+	for i, field := range []struct {
+		name  string
+		value any
+	}{
+		{"Calls", s.Calls},
+		{"RowsIn", s.RowsIn},
+		{"RowsOut", s.RowsOut},
+		{"ShardQueries", s.ShardQueries},
+		{"Shards", s.Shards},
+		{"Time", s.Time.String()},
+	} {
+		prepend := ","
+		if i == 0 {
+			prepend = ""
+		}
+		if err := marshalAdd(prepend, buf, field.name, field.value); err != nil {
+			return nil, err
+		}
+	}
+	buf.WriteString("}")
+	return buf.Bytes(), nil
+}
+
+// ExecutePrimitive implements the VCursor interface
+func (vc *tracedVCursor) ExecutePrimitive(ctx context.Context, primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	return vc.VCursor.ExecutePrimitive(ctx, vc.tracer.wrap(primitive), bindVars, wantfields)
+}
+
+// ExecutePrimitiveStandalone implements the VCursor interface
+func (vc *tracedVCursor) ExecutePrimitiveStandalone(ctx context.Context, primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	return vc.VCursor.ExecutePrimitiveStandalone(ctx, vc.tracer.wrap(primitive), bindVars, wantfields)
+}
+
+// StreamExecutePrimitive implements the VCursor interface
+func (vc *tracedVCursor) StreamExecutePrimitive(ctx context.Context, primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	return vc.VCursor.StreamExecutePrimitive(ctx, vc.tracer.wrap(primitive), bindVars, wantfields, callback)
+}
+
+// StreamExecutePrimitiveStandalone implements the VCursor interface
+func (vc *tracedVCursor) StreamExecutePrimitiveStandalone(ctx context.Context, primitive Primitive, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(result *sqltypes.Result) error) error {
+	return vc.VCursor.StreamExecutePrimitiveStandalone(ctx, vc.tracer.wrap(primitive), bindVars, wantfields, callback)
+}
+
+// ExecuteMultiShard implements the VCursor interface
+func (vc *tracedVCursor) ExecuteMultiShard(ctx context.Context, primitive Primitive, rss []*srvtopo.ResolvedShard, queries []*querypb.BoundQuery, rollbackOnError, canAutocommit bool) (*sqltypes.Result, []error) {
+	vc.tracer.addShardQueries(primitive, rss)
+	result, errs := vc.VCursor.ExecuteMultiShard(ctx, primitive, rss, queries, rollbackOnError, canAutocommit)
+	if result != nil {
+		vc.tracer.addShardRows(primitive, len(result.Rows))
+	}
+	return result, errs
+}
+
+// ExecuteStandalone implements the VCursor interface
+func (vc *tracedVCursor) ExecuteStandalone(ctx context.Context, primitive Primitive, query string, bindVars map[string]*querypb.BindVariable, rs *srvtopo.ResolvedShard) (*sqltypes.Result, error) {
+	vc.tracer.addShardQueries(primitive, []*srvtopo.ResolvedShard{rs})
+	result, err := vc.VCursor.ExecuteStandalone(ctx, primitive, query, bindVars, rs)
+	if result != nil {
+		vc.tracer.addShardRows(primitive, len(result.Rows))
+	}
+	return result, err
+}
+
+// StreamExecuteMulti implements the VCursor interface
+func (vc *tracedVCursor) StreamExecuteMulti(ctx context.Context, primitive Primitive, query string, rss []*srvtopo.ResolvedShard, bindVars []map[string]*querypb.BindVariable, rollbackOnError bool, autocommit bool, callback func(reply *sqltypes.Result) error) []error {
+	vc.tracer.addShardQueries(primitive, rss)
+	return vc.VCursor.StreamExecuteMulti(ctx, primitive, query, rss, bindVars, rollbackOnError, autocommit, func(reply *sqltypes.Result) error {
+		vc.tracer.addShardRows(primitive, len(reply.Rows))
+		return callback(reply)
+	})
+}
+
+// TryExecute implements the Primitive interface
+func (p *tracedPrimitive) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool) (*sqltypes.Result, error) {
+	start := p.tracer.now()
+	result, err := p.Primitive.TryExecute(ctx, p.tracer.vcursor(vcursor), bindVars, wantfields)
+	rows := 0
+	if result != nil {
+		rows = len(result.Rows)
+	}
+	p.tracer.addCall(p.Primitive, rows, p.tracer.now().Sub(start))
+	return result, err
+}
+
+// TryStreamExecute implements the Primitive interface
+func (p *tracedPrimitive) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, wantfields bool, callback func(*sqltypes.Result) error) error {
+	start := p.tracer.now()
+	err := p.Primitive.TryStreamExecute(ctx, p.tracer.vcursor(vcursor), bindVars, wantfields, func(result *sqltypes.Result) error {
+		p.tracer.addRows(p.Primitive, len(result.Rows))
+		return callback(result)
+	})
+	p.tracer.addCall(p.Primitive, 0, p.tracer.now().Sub(start))
+	return err
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func newTraceTestJoin() (*Join, *loggingVCursor) {
+	ks := &vindexes.Keyspace{Name: "ks", Sharded: true}
+	fields := sqltypes.MakeTestFields("id|col", "int64|varchar")
+	join := &Join{
+		Opcode: InnerJoin,
+		Left:   NewRoute(Scatter, ks, "select id, col from t1", "select id, col from t1 where 1 != 1"),
+		Right:  NewRoute(Scatter, ks, "select id, col from t2 where t2.col = :t1_col", "select id, col from t2 where 1 != 1"),
+		Cols:   []int{-1, 1},
+		Vars:   map[string]int{"t1_col": 1},
+	}
+	vc := &loggingVCursor{
+		shards: []string{"-80", "80-"},
+		results: []*sqltypes.Result{
+			sqltypes.MakeTestResult(fields, "1|a", "2|b"),
+			sqltypes.MakeTestResult(fields, "3|a"),
+			sqltypes.MakeTestResult(fields, "4|b", "5|b"),
+		},
+	}
+	return join, vc
+}
+
+func TestPrimitiveTracer(t *testing.T) {
+	join, vc := newTraceTestJoin()
+
+	tracer := newPrimitiveTracer()
+	clock := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	tracer.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+
+	result, err := tracer.vcursor(vc).ExecutePrimitive(context.Background(), join, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 3)
+
+	description := tracer.description(join)
+	assert.Equal(t, &PrimitiveStats{Calls: 1, RowsIn: 5, RowsOut: 3, Time: 7 * time.Millisecond}, description.Stats)
+	require.Len(t, description.Inputs, 2)
+	assert.Equal(t, &PrimitiveStats{Calls: 1, RowsIn: 2, RowsOut: 2, ShardQueries: 2, Shards: 2, Time: time.Millisecond}, description.Inputs[0].Stats)
+	assert.Equal(t, &PrimitiveStats{Calls: 2, RowsIn: 3, RowsOut: 3, ShardQueries: 4, Shards: 2, Time: 2 * time.Millisecond}, description.Inputs[1].Stats)
+
+	out, err := json.Marshal(description.Stats)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"Calls":1,"RowsIn":5,"RowsOut":3,"ShardQueries":0,"Shards":0,"Time":"7ms"}`, string(out))
+}
+
+func TestPrimitiveTracerStreaming(t *testing.T) {
+	join, vc := newTraceTestJoin()
+
+	tracer := newPrimitiveTracer()
+	var rows int
+	err := tracer.vcursor(vc).StreamExecutePrimitive(context.Background(), join, map[string]*querypb.BindVariable{}, true, func(qr *sqltypes.Result) error {
+		rows += len(qr.Rows)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 3, rows)
+
+	description := tracer.description(join)
+	assert.Equal(t, 1, description.Stats.Calls)
+	assert.Equal(t, 3, description.Stats.RowsOut)
+	assert.Equal(t, 2, description.Inputs[0].Stats.ShardQueries)
+	assert.Equal(t, 2, description.Inputs[1].Stats.Calls)
+	assert.Equal(t, 3, description.Inputs[1].Stats.RowsIn)
+}
+
+func TestVExplainTrace(t *testing.T) {
+	join, vc := newTraceTestJoin()
+	vexplain := &VExplain{Input: join, Type: sqlparser.TraceVExplainType}
+
+	result, err := vexplain.TryExecute(context.Background(), vc, map[string]*querypb.BindVariable{}, true)
+	require.NoError(t, err)
+	require.Len(t, result.Rows, 1)
+	assert.Equal(t, "Trace", result.Fields[0].Name)
+
+	var plan struct {
+		OperatorType string
+		Stats        struct{ Calls, RowsOut int }
+		Inputs       []struct {
+			OperatorType string
+			Variant      string
+			Stats        struct{ Calls, ShardQueries int }
+		}
+	}
+	require.NoError(t, json.Unmarshal([]byte(result.Rows[0][0].ToString()), &plan))
+	assert.Equal(t, "Join", plan.OperatorType)
+	assert.Equal(t, 1, plan.Stats.Calls)
+	assert.Equal(t, 3, plan.Stats.RowsOut)
+	require.Len(t, plan.Inputs, 2)
+	assert.Equal(t, "Route", plan.Inputs[1].OperatorType)
+	assert.Equal(t, 2, plan.Inputs[1].Stats.Calls)
+	assert.Equal(t, 4, plan.Inputs[1].Stats.ShardQueries)
+}
@@ -0,0 +1,475 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/sqltypes"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+var _ Primitive = (*Window)(nil)
+
+// Window is a primitive that evaluates window functions over the rows of its input.
+// The input must be sorted by the PARTITION BY columns followed by the ORDER BY
+// columns of the window. All the functions share the same partitioning and ordering,
+// but each of them can be evaluated over a different frame.
+type Window struct {
+	// Functions specifies the window functions to evaluate.
+	// The result of each function replaces the value of its column in the input.
+	Functions []*WindowParams
+
+	// PartitionBy specifies the input columns that divide the rows into partitions.
+	// Only equality is checked between these columns, the sort direction is irrelevant.
+	PartitionBy evalengine.Comparison
+
+	// OrderBy specifies the order of the rows inside a partition.
+	// Rows that compare equal are peers of each other.
+	OrderBy evalengine.Comparison
+
+	// TruncateColumnCount specifies the number of columns to return
+	// in the final result. Rest of the columns are truncated
+	// from the result received. If 0, no truncation happens.
+	TruncateColumnCount int `json:",omitempty"`
+
+	// Input is the primitive that will feed into this Primitive.
+	Input Primitive
+}
+
+// WindowParams specify the parameters for each window function.
+type WindowParams struct {
+	Opcode WindowOpcode
+	// Col is the input column that holds the argument of the function.
+	// The result of the function is returned in the same column.
+	Col int
+
+	// Frame is the frame the function is evaluated over.
+	// It is only used by the functions where Opcode.UsesFrame() is true.
+	Frame evalengine.WindowFrame
+
+	// N is the integer argument of NTILE, LAG, LEAD and NTH_VALUE.
+	N evalengine.Expr
+	// Default is the value returned by LAG and LEAD when the offset is outside the partition.
+	Default evalengine.Expr
+
+	Alias string `json:",omitempty"`
+	Expr  sqlparser.Expr
+	Type  evalengine.Type
+
+	CollationEnv *collations.Environment
+}
+
+func (wp *WindowParams) String() string {
+	args := strconv.Itoa(wp.Col)
+	if wp.N != nil {
+		args += ", " + sqlparser.String(wp.N)
+	}
+	if wp.Default != nil {
+		args += ", " + sqlparser.String(wp.Default)
+	}
+	out := fmt.Sprintf("%s(%s)", wp.Opcode.String(), args)
+	if wp.Opcode.UsesFrame() {
+		out += " " + wp.Frame.String()
+	}
+	if wp.Alias != "" {
+		out += " AS " + wp.Alias
+	}
+	return out
+}
+
+// windowArgs holds the constant arguments of a window function, resolved for a single execution
+type windowArgs struct {
+	n   int
+	def sqltypes.Value
+}
+
+// RouteType returns a description of the query routing type used by the primitive
+func (w *Window) RouteType() string {
+	return w.Input.RouteType()
+}
+
+// GetKeyspaceName specifies the Keyspace that this primitive routes to.
+func (w *Window) GetKeyspaceName() string {
+	return w.Input.GetKeyspaceName()
+}
+
+// GetTableName specifies the table that this primitive routes to.
+func (w *Window) GetTableName() string {
+	return w.Input.GetTableName()
+}
+
+// TryExecute is a Primitive function.
+func (w *Window) TryExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool) (_ *sqltypes.Result, err error) {
+	defer evalengine.PanicHandler(&err)
+
+	args, err := w.resolveArgs(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := vcursor.ExecutePrimitive(
+		ctx,
+		w.Input,
+		bindVars,
+		true, /*wantFields - we need the input fields types to correctly calculate the output types*/
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &sqltypes.Result{
+		Fields: w.fields(result.Fields),
+		Rows:   result.Rows,
+	}
+
+	start := 0
+	for i := 1; i <= len(out.Rows); i++ {
+		if i < len(out.Rows) && w.PartitionBy.Compare(out.Rows[i-1], out.Rows[i]) == 0 {
+			continue
+		}
+		if err := w.evaluatePartition(result.Fields, out.Rows[start:i], args); err != nil {
+			return nil, err
+		}
+		start = i
+	}
+
+	return out.Truncate(w.TruncateColumnCount), nil
+}
+
+// TryStreamExecute is a Primitive function.
+func (w *Window) TryStreamExecute(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable, _ bool, callback func(*sqltypes.Result) error) (err error) {
+	defer evalengine.PanicHandler(&err)
+
+	args, err := w.resolveArgs(ctx, vcursor, bindVars)
+	if err != nil {
+		return err
+	}
+
+	cb := func(qr *sqltypes.Result) error {
+		return callback(qr.Truncate(w.TruncateColumnCount))
+	}
+
+	var fields []*querypb.Field
+	var partition []sqltypes.Row
+
+	flush := func() error {
+		if len(partition) == 0 {
+			return nil
+		}
+		if err := w.evaluatePartition(fields, partition, args); err != nil {
+			return err
+		}
+		rows := partition
+		partition = nil
+		return cb(&sqltypes.Result{Rows: rows})
+	}
+
+	visitor := func(qr *sqltypes.Result) error {
+		if fields == nil && len(qr.Fields) != 0 {
+			fields = qr.Fields
+			if err := cb(&sqltypes.Result{Fields: w.fields(fields)}); err != nil {
+				return err
+			}
+		}
+
+		for _, row := range qr.Rows {
+			if len(partition) > 0 && w.PartitionBy.Compare(partition[len(partition)-1], row) != 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+			partition = append(partition, row)
+		}
+		if vcursor.ExceedsMaxMemoryRows(len(partition)) {
+			return fmt.Errorf("in-memory row count exceeded allowed limit of %d", vcursor.MaxMemoryRows())
+		}
+		return nil
+	}
+
+	/* we need the input fields types to correctly calculate the output types */
+	err = vcursor.StreamExecutePrimitive(ctx, w.Input, bindVars, true, visitor)
+	if err != nil {
+		return err
+	}
+	return flush()
+}
+
+// GetFields is a Primitive function.
+func (w *Window) GetFields(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	qr, err := w.Input.GetFields(ctx, vcursor, bindVars)
+	if err != nil {
+		return nil, err
+	}
+
+	qr = &sqltypes.Result{Fields: w.fields(qr.Fields)}
+	return qr.Truncate(w.TruncateColumnCount), nil
+}
+
+// Inputs returns the Primitive input for this window
+func (w *Window) Inputs() ([]Primitive, []map[string]any) {
+	return []Primitive{w.Input}, nil
+}
+
+// NeedsTransaction implements the Primitive interface
+func (w *Window) NeedsTransaction() bool {
+	return w.Input.NeedsTransaction()
+}
+
+func (w *Window) fields(fields []*querypb.Field) []*querypb.Field {
+	fields = slice.Map(fields, func(from *querypb.Field) *querypb.Field { return from.CloneVT() })
+	for _, fn := range w.Functions {
+		fields[fn.Col].Type = fn.Opcode.SQLType(fields[fn.Col].Type)
+		if fn.Alias != "" {
+			fields[fn.Col].Name = fn.Alias
+		}
+	}
+	return fields
+}
+
+// resolveArgs evaluates the constant arguments of the window functions
+func (w *Window) resolveArgs(ctx context.Context, vcursor VCursor, bindVars map[string]*querypb.BindVariable) ([]windowArgs, error) {
+	env := evalengine.NewExpressionEnv(ctx, bindVars, vcursor)
+	args := make([]windowArgs, len(w.Functions))
+	for i, fn := range w.Functions {
+		args[i].n = 1
+		args[i].def = sqltypes.NULL
+
+		if fn.N != nil {
+			resolved, err := env.Evaluate(fn.N)
+			if err != nil {
+				return nil, err
+			}
+			value := resolved.Value(vcursor.ConnCollation())
+			n, err := strconv.Atoi(value.RawStr())
+			if err != nil || !value.IsIntegral() || n < 0 || (n == 0 && fn.Opcode != WindowLag && fn.Opcode != WindowLead) {
+				return nil, vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.WrongArguments, "Incorrect arguments to %s", fn.Opcode.String())
+			}
+			args[i].n = n
+		}
+
+		if fn.Default != nil {
+			resolved, err := env.Evaluate(fn.Default)
+			if err != nil {
+				return nil, err
+			}
+			args[i].def = resolved.Value(vcursor.ConnCollation())
+		}
+	}
+	return args, nil
+}
+
+// evaluatePartition computes all the window functions for the rows of a single partition,
+// and stores the results in the rows
+func (w *Window) evaluatePartition(fields []*querypb.Field, rows []sqltypes.Row, args []windowArgs) error {
+	// find the peers of each row; peers have the same values for the ORDER BY columns
+	peerStart := make([]int, len(rows))
+	peerEnd := make([]int, len(rows))
+	start := 0
+	for i := 1; i <= len(rows); i++ {
+		if i < len(rows) && w.OrderBy.Compare(rows[i-1], rows[i]) == 0 {
+			continue
+		}
+		for j := start; j < i; j++ {
+			peerStart[j], peerEnd[j] = start, i
+		}
+		start = i
+	}
+
+	// all the results are computed before any is written, since the
+	// frames of the rows that come later can include the earlier rows
+	results := make([][]sqltypes.Value, len(w.Functions))
+	for i, fn := range w.Functions {
+		var err error
+		results[i], err = fn.evaluate(fields, rows, peerStart, peerEnd, args[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	for i, fn := range w.Functions {
+		for j, row := range rows {
+			row[fn.Col] = results[i][j]
+		}
+	}
+	return nil
+}
+
+func (wp *WindowParams) evaluate(fields []*querypb.Field, rows []sqltypes.Row, peerStart, peerEnd []int, args windowArgs) ([]sqltypes.Value, error) {
+	size := len(rows)
+	out := make([]sqltypes.Value, size)
+
+	if wp.Opcode.IsAggregation() {
+		return out, wp.aggregate(fields, rows, peerStart, peerEnd, out)
+	}
+
+	rank := 0
+	for i := range rows {
+		if peerStart[i] == i {
+			rank++
+		}
+
+		switch wp.Opcode {
+		case WindowRowNumber:
+			out[i] = sqltypes.NewInt64(int64(i + 1))
+		case WindowRank:
+			out[i] = sqltypes.NewInt64(int64(peerStart[i] + 1))
+		case WindowDenseRank:
+			out[i] = sqltypes.NewInt64(int64(rank))
+		case WindowPercentRank:
+			var pr float64
+			if size > 1 {
+				pr = float64(peerStart[i]) / float64(size-1)
+			}
+			out[i] = sqltypes.NewFloat64(pr)
+		case WindowCumeDist:
+			out[i] = sqltypes.NewFloat64(float64(peerEnd[i]) / float64(size))
+		case WindowNtile:
+			out[i] = sqltypes.NewInt64(ntileBucket(i, size, args.n))
+		case WindowLag, WindowLead:
+			pos := i - args.n
+			if wp.Opcode == WindowLead {
+				pos = i + args.n
+			}
+			if pos >= 0 && pos < size {
+				out[i] = rows[pos][wp.Col]
+			} else {
+				out[i] = args.def
+			}
+		case WindowFirstValue, WindowLastValue, WindowNthValue:
+			start, end := wp.Frame.Bounds(i, size, peerStart[i], peerEnd[i])
+			pos := -1
+			switch wp.Opcode {
+			case WindowFirstValue:
+				pos = start
+			case WindowLastValue:
+				pos = end - 1
+			case WindowNthValue:
+				pos = start + args.n - 1
+			}
+			if pos >= start && pos < end {
+				out[i] = rows[pos][wp.Col]
+			} else {
+				out[i] = sqltypes.NULL
+			}
+		default:
+			return nil, vterrors.VT13001(fmt.Sprintf("unexpected window function: %s", wp.Opcode.String()))
+		}
+	}
+	return out, nil
+}
+
+// aggregate evaluates an aggregation function over the frame of each row
+func (wp *WindowParams) aggregate(fields []*querypb.Field, rows []sqltypes.Row, peerStart, peerEnd []int, out []sqltypes.Value) error {
+	agg := wp.newAggregator(fields[wp.Col].Type)
+
+	// frames that start at the beginning of the partition only grow,
+	// so the rows can be added to the aggregation as the frame moves
+	cumulative := wp.Frame.IsCumulative()
+	added := 0
+
+	for i := range rows {
+		start, end := wp.Frame.Bounds(i, len(rows), peerStart[i], peerEnd[i])
+		if !cumulative {
+			agg.reset()
+			added = start
+		}
+		for ; added < end; added++ {
+			if err := agg.add(rows[added]); err != nil {
+				return err
+			}
+		}
+		out[i] = agg.finish()
+	}
+	return nil
+}
+
+func (wp *WindowParams) newAggregator(sourceType sqltypes.Type) aggregator {
+	switch wp.Opcode {
+	case WindowCountStar:
+		return &aggregatorCountStar{}
+	case WindowCount:
+		return &aggregatorCount{from: wp.Col, distinct: &aggregatorDistinct{column: -1}}
+	case WindowSum:
+		return &aggregatorSum{from: wp.Col, sum: evalengine.NewAggregationSum(sourceType), distinct: &aggregatorDistinct{column: -1}}
+	case WindowAvg:
+		return &aggregatorSum{from: wp.Col, sum: evalengine.NewAggregationAvg(sourceType), distinct: &aggregatorDistinct{column: -1}}
+	case WindowMin:
+		return &aggregatorMin{
+			aggregatorMinMax{
+				from:   wp.Col,
+				minmax: evalengine.NewAggregationMinMax(sourceType, wp.CollationEnv, wp.Type.Collation(), wp.Type.Values()),
+			},
+		}
+	case WindowMax:
+		return &aggregatorMax{
+			aggregatorMinMax{
+				from:   wp.Col,
+				minmax: evalengine.NewAggregationMinMax(sourceType, wp.CollationEnv, wp.Type.Collation(), wp.Type.Values()),
+			},
+		}
+	default:
+		panic("BUG: unexpected window aggregation opcode")
+	}
+}
+
+// ntileBucket returns the bucket of the row at position pos when a partition of
+// size rows is divided into n buckets. As in MySQL, the first size%n buckets get one extra row.
+func ntileBucket(pos, size, n int) int64 {
+	small := size / n
+	if small == 0 {
+		return int64(pos + 1)
+	}
+	big := small + 1
+	extra := size % n
+	if pos < extra*big {
+		return int64(pos/big + 1)
+	}
+	return int64(extra + (pos-extra*big)/small + 1)
+}
+
+func windowParamsToString(in any) string {
+	return in.(*WindowParams).String()
+}
+
+func (w *Window) description() PrimitiveDescription {
+	other := map[string]any{
+		"Functions": GenericJoin(w.Functions, windowParamsToString),
+	}
+	if len(w.PartitionBy) > 0 {
+		other["PartitionBy"] = GenericJoin(w.PartitionBy, orderByParamsToString)
+	}
+	if len(w.OrderBy) > 0 {
+		other["OrderBy"] = GenericJoin(w.OrderBy, orderByParamsToString)
+	}
+	if w.TruncateColumnCount > 0 {
+		other["ResultColumns"] = w.TruncateColumnCount
+	}
+	return PrimitiveDescription{
+		OperatorType: "Window",
+		Other:        other,
+	}
+}
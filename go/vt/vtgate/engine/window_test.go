@@ -0,0 +1,201 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package engine
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+	. "vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/evalengine"
+)
+
+func TestWindowRanking(t *testing.T) {
+	input := func() *fakePrimitive {
+		return &fakePrimitive{
+			results: []*sqltypes.Result{sqltypes.MakeTestResult(
+				sqltypes.MakeTestFields(
+					"part|ord|rn|rk|drk|cd|pr|nt|s",
+					"varbinary|int64|null|null|null|null|null|null|int64",
+				),
+				"a|1|null|null|null|null|null|null|1",
+				"a|2|null|null|null|null|null|null|2",
+				"a|2|null|null|null|null|null|null|2",
+				"a|3|null|null|null|null|null|null|3",
+				"a|4|null|null|null|null|null|null|4",
+				"b|5|null|null|null|null|null|null|5",
+				"b|6|null|null|null|null|null|null|6",
+			)},
+		}
+	}
+
+	w := &Window{
+		Functions: []*WindowParams{
+			{Opcode: WindowRowNumber, Col: 2},
+			{Opcode: WindowRank, Col: 3},
+			{Opcode: WindowDenseRank, Col: 4},
+			{Opcode: WindowCumeDist, Col: 5},
+			{Opcode: WindowPercentRank, Col: 6},
+			{Opcode: WindowNtile, Col: 7, N: evalengine.NewLiteralInt(3)},
+			{Opcode: WindowSum, Col: 8, Frame: evalengine.DefaultWindowFrame()},
+		},
+		PartitionBy: evalengine.Comparison{{Col: 0, WeightStringCol: -1}},
+		OrderBy:     evalengine.Comparison{{Col: 1, WeightStringCol: -1}},
+	}
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"part|ord|rn|rk|drk|cd|pr|nt|s",
+			"varbinary|int64|int64|int64|int64|float64|float64|int64|decimal",
+		),
+		"a|1|1|1|1|0.2|0|1|1",
+		"a|2|2|2|2|0.6|0.25|1|5",
+		"a|2|3|2|2|0.6|0.25|2|5",
+		"a|3|4|4|3|0.8|0.75|2|8",
+		"a|4|5|5|4|1|1|3|12",
+		"b|5|1|1|1|0.5|0|1|5",
+		"b|6|2|2|2|1|1|2|11",
+	)
+
+	w.Input = input()
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, result)
+
+	w.Input = input()
+	result, err = wrapStreamExecute(w, &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+	utils.MustMatch(t, want, result)
+}
+
+func TestWindowFrames(t *testing.T) {
+	fields := sqltypes.MakeTestFields(
+		"ord|fv|lv|nv|lg|ld|mn|av",
+		"int64|int64|int64|int64|int64|int64|int64|int64",
+	)
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			fields,
+			"1|1|1|1|1|1|1|1",
+			"2|2|2|2|2|2|2|2",
+			"3|3|3|3|3|3|3|3",
+			"4|4|4|4|4|4|4|4",
+		)},
+	}
+
+	sliding := evalengine.WindowFrame{
+		Unit:  evalengine.WindowFrameRows,
+		Start: evalengine.WindowFrameBound{Type: evalengine.Preceding, Offset: 1},
+		End:   evalengine.WindowFrameBound{Type: evalengine.Following, Offset: 1},
+	}
+	previous := evalengine.WindowFrame{
+		Unit:  evalengine.WindowFrameRows,
+		Start: evalengine.WindowFrameBound{Type: evalengine.Preceding, Offset: 1},
+		End:   evalengine.WindowFrameBound{Type: evalengine.CurrentRow},
+	}
+	whole := evalengine.WindowFrame{
+		Unit:  evalengine.WindowFrameRows,
+		Start: evalengine.WindowFrameBound{Type: evalengine.UnboundedPreceding},
+		End:   evalengine.WindowFrameBound{Type: evalengine.UnboundedFollowing},
+	}
+
+	w := &Window{
+		Functions: []*WindowParams{
+			{Opcode: WindowFirstValue, Col: 1, Frame: sliding},
+			{Opcode: WindowLastValue, Col: 2, Frame: sliding},
+			{Opcode: WindowNthValue, Col: 3, N: evalengine.NewLiteralInt(2), Frame: evalengine.DefaultWindowFrame()},
+			{Opcode: WindowLag, Col: 4, N: evalengine.NewLiteralInt(1), Default: evalengine.NewLiteralInt(0)},
+			{Opcode: WindowLead, Col: 5, N: evalengine.NewLiteralInt(2)},
+			{Opcode: WindowMin, Col: 6, Frame: previous},
+			{Opcode: WindowAvg, Col: 7, Frame: whole},
+		},
+		OrderBy:             evalengine.Comparison{{Col: 0, WeightStringCol: -1}},
+		TruncateColumnCount: 7,
+		Input:               fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"ord|fv|lv|nv|lg|ld|mn",
+			"int64|int64|int64|int64|int64|int64|int64",
+		),
+		"1|1|2|null|0|3|1",
+		"2|1|3|2|1|4|1",
+		"3|2|4|2|2|null|2",
+		"4|3|4|2|3|null|3",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestWindowAverage(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields(
+				"ord|av",
+				"int64|int64",
+			),
+			"1|1",
+			"2|null",
+			"3|2",
+		)},
+	}
+
+	w := &Window{
+		Functions: []*WindowParams{{Opcode: WindowAvg, Col: 1, Frame: evalengine.DefaultWindowFrame(), Alias: "avg(av) over (order by ord)"}},
+		OrderBy:   evalengine.Comparison{{Col: 0, WeightStringCol: -1}},
+		Input:     fp,
+	}
+
+	result, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.NoError(t, err)
+
+	want := sqltypes.MakeTestResult(
+		sqltypes.MakeTestFields(
+			"ord|avg(av) over (order by ord)",
+			"int64|decimal",
+		),
+		"1|1.0000",
+		"2|1.0000",
+		"3|1.5000",
+	)
+	utils.MustMatch(t, want, result)
+}
+
+func TestWindowInvalidArgument(t *testing.T) {
+	fp := &fakePrimitive{
+		results: []*sqltypes.Result{sqltypes.MakeTestResult(
+			sqltypes.MakeTestFields("ord|nt", "int64|null"),
+			"1|null",
+		)},
+	}
+
+	w := &Window{
+		Functions: []*WindowParams{{Opcode: WindowNtile, Col: 1, N: evalengine.NewLiteralInt(0)}},
+		OrderBy:   evalengine.Comparison{{Col: 0, WeightStringCol: -1}},
+		Input:     fp,
+	}
+
+	_, err := w.TryExecute(context.Background(), &noopVCursor{}, nil, true)
+	require.EqualError(t, err, "Incorrect arguments to ntile")
+}
@@ -440,6 +440,55 @@ func NewAggregationSum(type_ sqltypes.Type) Sum {
 	}
 }
 
+// aggregationAvg implements AVG aggregation on top of a SUM aggregation.
+// Matching MySQL's behavior, the average of integral and DECIMAL values is a
+// DECIMAL with divPrecisionIncrement more digits than the aggregated values,
+// and the average of any other type is a FLOAT64. If no values have been
+// aggregated, the result is NULL.
+type aggregationAvg struct {
+	sum Sum
+	n   int64
+}
+
+func (a *aggregationAvg) Add(value sqltypes.Value) error {
+	if value.IsNull() {
+		return nil
+	}
+	if err := a.sum.Add(value); err != nil {
+		return err
+	}
+	a.n++
+	return nil
+}
+
+func (a *aggregationAvg) Result() sqltypes.Value {
+	if a.n == 0 {
+		return sqltypes.NULL
+	}
+	sum := a.sum.Result()
+	if sum.Type() != sqltypes.Decimal {
+		f, _ := sum.ToFloat64()
+		return sqltypes.NewFloat64(f / float64(a.n))
+	}
+	dec, err := decimal.NewFromMySQL(sum.Raw())
+	if err != nil {
+		return sqltypes.NULL
+	}
+	prec := max(-dec.Exponent(), 0) + divPrecisionIncrement
+	avg := dec.Div(decimal.NewFromInt(a.n), divPrecisionIncrement).Round(prec)
+	return sqltypes.MakeTrusted(sqltypes.Decimal, avg.FormatMySQL(prec))
+}
+
+func (a *aggregationAvg) Reset() {
+	a.sum.Reset()
+	a.n = 0
+}
+
+// NewAggregationAvg returns a Sum that yields the average of the aggregated values.
+func NewAggregationAvg(type_ sqltypes.Type) Sum {
+	return &aggregationAvg{sum: NewAggregationSum(type_)}
+}
+
 // aggregationMinMax implements MIN and MAX aggregations for all data types
 // that cannot be more efficiently handled by one of the numeric aggregators.
 // The aggregation is performed using the slow NullSafeComparison path of the
@@ -170,3 +170,54 @@ func TestMinMax(t *testing.T) {
 		})
 	}
 }
+
+func TestAvg(t *testing.T) {
+	tcases := []struct {
+		type_  sqltypes.Type
+		values []sqltypes.Value
+		avg    sqltypes.Value
+	}{
+		{
+			type_:  sqltypes.Int64,
+			values: []sqltypes.Value{},
+			avg:    sqltypes.NULL,
+		},
+		{
+			type_:  sqltypes.Int64,
+			values: []sqltypes.Value{NULL, NULL},
+			avg:    sqltypes.NULL,
+		},
+		{
+			type_:  sqltypes.Int64,
+			values: []sqltypes.Value{NewInt64(1), NULL, NewInt64(2)},
+			avg:    sqltypes.NewDecimal("1.5000"),
+		},
+		{
+			type_:  sqltypes.Int64,
+			values: []sqltypes.Value{NewInt64(1), NewInt64(1), NewInt64(2)},
+			avg:    sqltypes.NewDecimal("1.3333"),
+		},
+		{
+			type_:  sqltypes.Decimal,
+			values: []sqltypes.Value{sqltypes.NewDecimal("1.25"), sqltypes.NewDecimal("2.5")},
+			avg:    sqltypes.NewDecimal("1.875000"),
+		},
+		{
+			type_:  sqltypes.Float64,
+			values: []sqltypes.Value{sqltypes.NewFloat64(1), sqltypes.NewFloat64(2)},
+			avg:    sqltypes.NewFloat64(1.5),
+		},
+	}
+	for i, tcase := range tcases {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			agg := NewAggregationAvg(tcase.type_)
+			for _, v := range tcase.values {
+				require.NoError(t, agg.Add(v))
+			}
+			utils.MustMatch(t, tcase.avg, agg.Result())
+
+			agg.Reset()
+			utils.MustMatch(t, sqltypes.NULL, agg.Result())
+		})
+	}
+}
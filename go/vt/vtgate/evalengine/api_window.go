@@ -0,0 +1,219 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"fmt"
+	"strconv"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+)
+
+// WindowFrameUnit is the unit used to measure the bounds of a window frame
+type WindowFrameUnit int8
+
+const (
+	// WindowFrameRows measures the frame in physical rows
+	WindowFrameRows WindowFrameUnit = iota
+	// WindowFrameRange measures the frame in logical ranges of peer rows
+	WindowFrameRange
+)
+
+// WindowFrameBoundType is the type of one of the two bounds of a window frame
+type WindowFrameBoundType int8
+
+const (
+	UnboundedPreceding WindowFrameBoundType = iota
+	Preceding
+	CurrentRow
+	Following
+	UnboundedFollowing
+)
+
+type (
+	// WindowFrameBound is the start or the end of a window frame.
+	// Offset is only used by the Preceding and Following types.
+	WindowFrameBound struct {
+		Type   WindowFrameBoundType
+		Offset int
+	}
+
+	// WindowFrame is the subset of rows of a partition that a window function
+	// is evaluated over for any given row of the partition.
+	// See https://dev.mysql.com/doc/refman/8.0/en/window-functions-frames.html
+	WindowFrame struct {
+		Unit  WindowFrameUnit
+		Start WindowFrameBound
+		End   WindowFrameBound
+	}
+)
+
+// DefaultWindowFrame returns the frame that MySQL uses for windows without a frame clause.
+// With an ORDER BY, the frame goes from the start of the partition to the last peer of the
+// current row; without one, all the rows of the partition are peers and the frame is the
+// whole partition.
+func DefaultWindowFrame() WindowFrame {
+	return WindowFrame{
+		Unit:  WindowFrameRange,
+		Start: WindowFrameBound{Type: UnboundedPreceding},
+		End:   WindowFrameBound{Type: CurrentRow},
+	}
+}
+
+// TranslateWindowFrame converts a frame clause from the AST into a WindowFrame.
+// A nil frame clause results in the default frame. Only integer literals are
+// supported as offsets, and only for ROWS frames.
+func TranslateWindowFrame(frame *sqlparser.FrameClause) (WindowFrame, error) {
+	if frame == nil {
+		return DefaultWindowFrame(), nil
+	}
+
+	wf := WindowFrame{
+		Unit: WindowFrameRows,
+		End:  WindowFrameBound{Type: CurrentRow},
+	}
+	if frame.Unit == sqlparser.FrameRangeType {
+		wf.Unit = WindowFrameRange
+	}
+
+	var err error
+	wf.Start, err = translateFrameBound(wf.Unit, frame.Start)
+	if err != nil {
+		return WindowFrame{}, err
+	}
+	if frame.End != nil {
+		wf.End, err = translateFrameBound(wf.Unit, frame.End)
+		if err != nil {
+			return WindowFrame{}, err
+		}
+	}
+
+	if wf.Start.Type == UnboundedFollowing || wf.End.Type == UnboundedPreceding || wf.Start.Type > wf.End.Type {
+		return WindowFrame{}, vterrors.VT03012(fmt.Sprintf("illegal window frame %s", sqlparser.String(frame)))
+	}
+	return wf, nil
+}
+
+func translateFrameBound(unit WindowFrameUnit, point *sqlparser.FramePoint) (WindowFrameBound, error) {
+	switch point.Type {
+	case sqlparser.UnboundedPrecedingType:
+		return WindowFrameBound{Type: UnboundedPreceding}, nil
+	case sqlparser.UnboundedFollowingType:
+		return WindowFrameBound{Type: UnboundedFollowing}, nil
+	case sqlparser.CurrentRowType:
+		return WindowFrameBound{Type: CurrentRow}, nil
+	}
+
+	if unit == WindowFrameRange {
+		return WindowFrameBound{}, vterrors.VT12001("RANGE window frame with an offset")
+	}
+	lit, ok := point.Expr.(*sqlparser.Literal)
+	if !ok || lit.Type != sqlparser.IntVal {
+		return WindowFrameBound{}, vterrors.VT12001(fmt.Sprintf("window frame offset '%s'", sqlparser.String(point.Expr)))
+	}
+	offset, err := strconv.Atoi(lit.Val)
+	if err != nil {
+		return WindowFrameBound{}, err
+	}
+
+	bound := WindowFrameBound{Type: Preceding, Offset: offset}
+	if point.Type == sqlparser.ExprFollowingType {
+		bound.Type = Following
+	}
+	return bound, nil
+}
+
+// Bounds returns the half-open interval [start, end) of positions in a partition that
+// form the frame of the row at position pos. The partition has size rows, and the peers of
+// the current row, i.e. the rows with the same ORDER BY values, are at positions [peerStart, peerEnd).
+// The returned frame is empty when start >= end.
+func (wf WindowFrame) Bounds(pos, size, peerStart, peerEnd int) (start, end int) {
+	start = wf.Start.position(wf.Unit, pos, size, peerStart, peerEnd, true)
+	end = wf.End.position(wf.Unit, pos, size, peerStart, peerEnd, false)
+	if start > size {
+		start = size
+	}
+	if end < start {
+		end = start
+	}
+	return start, end
+}
+
+func (b WindowFrameBound) position(unit WindowFrameUnit, pos, size, peerStart, peerEnd int, isStart bool) int {
+	switch b.Type {
+	case UnboundedPreceding:
+		return 0
+	case UnboundedFollowing:
+		return size
+	case CurrentRow:
+		switch {
+		case unit == WindowFrameRange && isStart:
+			return peerStart
+		case unit == WindowFrameRange:
+			return peerEnd
+		case isStart:
+			return pos
+		default:
+			return pos + 1
+		}
+	case Preceding:
+		p := pos - b.Offset
+		if !isStart {
+			p++
+		}
+		return max(p, 0)
+	case Following:
+		p := pos + b.Offset
+		if !isStart {
+			p++
+		}
+		return min(p, size)
+	}
+	panic("unreachable")
+}
+
+// IsCumulative returns true if the frame always starts at the beginning of the partition.
+// The frames of such windows only grow as we move forward, so their aggregations
+// can be computed incrementally.
+func (wf WindowFrame) IsCumulative() bool {
+	return wf.Start.Type == UnboundedPreceding
+}
+
+func (wf WindowFrame) String() string {
+	unit := "ROWS"
+	if wf.Unit == WindowFrameRange {
+		unit = "RANGE"
+	}
+	return fmt.Sprintf("%s BETWEEN %s AND %s", unit, wf.Start.String(), wf.End.String())
+}
+
+func (b WindowFrameBound) String() string {
+	switch b.Type {
+	case UnboundedPreceding:
+		return "UNBOUNDED PRECEDING"
+	case Preceding:
+		return fmt.Sprintf("%d PRECEDING", b.Offset)
+	case CurrentRow:
+		return "CURRENT ROW"
+	case Following:
+		return fmt.Sprintf("%d FOLLOWING", b.Offset)
+	case UnboundedFollowing:
+		return "UNBOUNDED FOLLOWING"
+	}
+	return "ERROR"
+}
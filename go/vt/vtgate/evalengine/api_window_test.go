@@ -0,0 +1,143 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evalengine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+)
+
+func TestTranslateWindowFrame(t *testing.T) {
+	tests := []struct {
+		frame string
+		want  string
+		err   string
+	}{
+		{
+			frame: "",
+			want:  "RANGE BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW",
+		}, {
+			frame: "rows unbounded preceding",
+			want:  "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW",
+		}, {
+			frame: "rows between 2 preceding and 1 following",
+			want:  "ROWS BETWEEN 2 PRECEDING AND 1 FOLLOWING",
+		}, {
+			frame: "range between current row and unbounded following",
+			want:  "RANGE BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING",
+		}, {
+			frame: "range between 1 preceding and current row",
+			err:   "VT12001: unsupported: RANGE window frame with an offset",
+		}, {
+			frame: "rows between current row and 1 preceding",
+			err:   "VT03012: invalid syntax: illegal window frame",
+		}, {
+			frame: "rows between unbounded following and unbounded following",
+			err:   "VT03012: invalid syntax: illegal window frame",
+		},
+	}
+
+	parser := sqlparser.NewTestParser()
+	for _, tt := range tests {
+		t.Run(tt.frame, func(t *testing.T) {
+			expr, err := parser.ParseExpr("sum(x) over (order by y " + tt.frame + ")")
+			require.NoError(t, err)
+			frame := sqlparser.GetOverClause(expr).WindowSpec.FrameClause
+
+			wf, err := TranslateWindowFrame(frame)
+			if tt.err != "" {
+				require.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, wf.String())
+		})
+	}
+}
+
+func TestWindowFrameBounds(t *testing.T) {
+	rows := func(start, end WindowFrameBound) WindowFrame {
+		return WindowFrame{Unit: WindowFrameRows, Start: start, End: end}
+	}
+	rng := func(start, end WindowFrameBound) WindowFrame {
+		return WindowFrame{Unit: WindowFrameRange, Start: start, End: end}
+	}
+	unboundedPreceding := WindowFrameBound{Type: UnboundedPreceding}
+	unboundedFollowing := WindowFrameBound{Type: UnboundedFollowing}
+	current := WindowFrameBound{Type: CurrentRow}
+	preceding := func(n int) WindowFrameBound { return WindowFrameBound{Type: Preceding, Offset: n} }
+	following := func(n int) WindowFrameBound { return WindowFrameBound{Type: Following, Offset: n} }
+
+	// a partition with 6 rows, where rows 2, 3 and 4 are peers
+	const size = 6
+	peers := func(pos int) (int, int) {
+		if pos >= 2 && pos <= 4 {
+			return 2, 5
+		}
+		return pos, pos + 1
+	}
+
+	tests := []struct {
+		name  string
+		frame WindowFrame
+		want  [size][2]int
+	}{
+		{
+			name:  "default frame",
+			frame: DefaultWindowFrame(),
+			want:  [size][2]int{{0, 1}, {0, 2}, {0, 5}, {0, 5}, {0, 5}, {0, 6}},
+		}, {
+			name:  "running total over rows",
+			frame: rows(unboundedPreceding, current),
+			want:  [size][2]int{{0, 1}, {0, 2}, {0, 3}, {0, 4}, {0, 5}, {0, 6}},
+		}, {
+			name:  "whole partition",
+			frame: rows(unboundedPreceding, unboundedFollowing),
+			want:  [size][2]int{{0, 6}, {0, 6}, {0, 6}, {0, 6}, {0, 6}, {0, 6}},
+		}, {
+			name:  "sliding window",
+			frame: rows(preceding(1), following(1)),
+			want:  [size][2]int{{0, 2}, {0, 3}, {1, 4}, {2, 5}, {3, 6}, {4, 6}},
+		}, {
+			name:  "only preceding rows",
+			frame: rows(preceding(2), preceding(1)),
+			want:  [size][2]int{{0, 0}, {0, 1}, {0, 2}, {1, 3}, {2, 4}, {3, 5}},
+		}, {
+			name:  "only following rows",
+			frame: rows(following(1), following(3)),
+			want:  [size][2]int{{1, 4}, {2, 5}, {3, 6}, {4, 6}, {5, 6}, {6, 6}},
+		}, {
+			name:  "peers and following rows",
+			frame: rng(current, unboundedFollowing),
+			want:  [size][2]int{{0, 6}, {1, 6}, {2, 6}, {2, 6}, {2, 6}, {5, 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for pos := 0; pos < size; pos++ {
+				peerStart, peerEnd := peers(pos)
+				start, end := tt.frame.Bounds(pos, size, peerStart, peerEnd)
+				assert.Equal(t, tt.want[pos], [2]int{start, end}, "row %d", pos)
+			}
+		})
+	}
+}
@@ -18,6 +18,7 @@ package evalengine
 
 import (
 	"context"
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -452,3 +453,152 @@ func TestCardinalityWithBindVariables(t *testing.T) {
 		})
 	}
 }
+
+// TestAnyAllRewrite checks that the rewrite the planner uses for ANY, SOME and ALL comparisons
+// gives the same results as comparing against every row of the subquery one by one,
+// including the NULL semantics for NULL rows, a NULL left-hand side and empty subqueries.
+func TestAnyAllRewrite(t *testing.T) {
+	venv := vtenv.NewTestEnv()
+	parser := sqlparser.NewTestParser()
+	evaluate := func(t *testing.T, expr sqlparser.Expr) sqltypes.Value {
+		t.Helper()
+		translated, err := Translate(expr, &Config{
+			Collation:   venv.CollationEnv().DefaultConnectionCharset(),
+			Environment: venv,
+		})
+		require.NoError(t, err)
+		r, err := EmptyExpressionEnv(venv).Evaluate(translated)
+		require.NoError(t, err)
+		return r.Value(collations.MySQL8().DefaultConnectionCharset())
+	}
+	literal := func(v *int) sqlparser.Expr {
+		if v == nil {
+			return &sqlparser.NullVal{}
+		}
+		return sqlparser.NewIntLiteral(fmt.Sprintf("%d", *v))
+	}
+	val := func(i int) *int { return &i }
+
+	sets := [][]*int{
+		{},
+		{nil},
+		{nil, nil},
+		{val(1)},
+		{val(2)},
+		{val(2), val(2)},
+		{val(1), val(3)},
+		{val(1), val(2), val(3)},
+		{val(1), nil},
+		{val(2), nil},
+		{val(3), nil},
+		{val(1), nil, val(3)},
+	}
+	lefts := []*int{nil, val(1), val(2), val(3)}
+
+	for _, op := range []string{"=", "<>", "<", "<=", ">", ">="} {
+		for _, modifier := range []string{"any", "all"} {
+			for _, left := range lefts {
+				for _, set := range sets {
+					var rows []string
+					for _, row := range set {
+						rows = append(rows, sqlparser.String(literal(row)))
+					}
+					name := fmt.Sprintf("%s %s %s (%s)", sqlparser.String(literal(left)), op, modifier, strings.Join(rows, ", "))
+					t.Run(name, func(t *testing.T) {
+						// the reference result combines the row by row comparisons using three-valued logic
+						var sawTrue, sawFalse, sawNull bool
+						for _, row := range set {
+							cmp, err := parser.ParseExpr(fmt.Sprintf("%s %s %s", sqlparser.String(literal(left)), op, sqlparser.String(literal(row))))
+							require.NoError(t, err)
+							switch r := evaluate(t, cmp); {
+							case r.IsNull():
+								sawNull = true
+							case r.ToString() == "1":
+								sawTrue = true
+							default:
+								sawFalse = true
+							}
+						}
+						var expected string
+						switch {
+						case modifier == "any" && sawTrue, modifier == "all" && !sawFalse && !sawNull:
+							expected = "1"
+						case modifier == "all" && sawFalse, modifier == "any" && !sawNull:
+							expected = "0"
+						default:
+							expected = "NULL"
+						}
+
+						expr, err := parser.ParseExpr(fmt.Sprintf("x %s %s (select c from t)", op, modifier))
+						require.NoError(t, err)
+						rewritten, ok := sqlparser.RewriteAnyAllComparison(expr.(*sqlparser.ComparisonExpr))
+						require.True(t, ok)
+
+						if in, isIn := rewritten.(*sqlparser.ComparisonExpr); isIn {
+							if len(set) == 0 {
+								// an empty IN list can't be expressed as a literal tuple
+								t.Skip()
+							}
+							tuple := sqlparser.ValTuple{}
+							for _, row := range set {
+								tuple = append(tuple, literal(row))
+							}
+							in.Right = tuple
+						}
+
+						// replace the left-hand side and the scalar aggregations with the values they evaluate to
+						rewritten = sqlparser.Rewrite(rewritten, nil, func(cursor *sqlparser.Cursor) bool {
+							switch node := cursor.Node().(type) {
+							case *sqlparser.ColName:
+								if node.Name.EqualString("x") {
+									cursor.Replace(literal(left))
+								}
+							case *sqlparser.Subquery:
+								cursor.Replace(aggregate(t, node, set))
+							}
+							return true
+						}).(sqlparser.Expr)
+
+						r := evaluate(t, rewritten)
+						if expected == "NULL" {
+							assert.True(t, r.IsNull(), "%s returned %s", sqlparser.String(rewritten), r.String())
+						} else {
+							assert.Equal(t, expected, r.ToString(), sqlparser.String(rewritten))
+						}
+					})
+				}
+			}
+		}
+	}
+}
+
+// aggregate computes the MIN, MAX or SUM(c IS NULL) scalar subquery the ANY/ALL rewrite produces
+func aggregate(t *testing.T, subq *sqlparser.Subquery, set []*int) sqlparser.Expr {
+	var nonNull []int
+	for _, v := range set {
+		if v != nil {
+			nonNull = append(nonNull, *v)
+		}
+	}
+	aggr := subq.Select.(*sqlparser.Select).SelectExprs[0].(*sqlparser.AliasedExpr).Expr
+	switch aggr.(type) {
+	case *sqlparser.Min, *sqlparser.Max:
+		if len(nonNull) == 0 {
+			return &sqlparser.NullVal{}
+		}
+		res := nonNull[0]
+		for _, v := range nonNull[1:] {
+			if _, isMin := aggr.(*sqlparser.Min); isMin == (v < res) {
+				res = v
+			}
+		}
+		return sqlparser.NewIntLiteral(fmt.Sprintf("%d", res))
+	case *sqlparser.Sum:
+		if len(set) == 0 {
+			return &sqlparser.NullVal{}
+		}
+		return sqlparser.NewIntLiteral(fmt.Sprintf("%d", len(set)-len(nonNull)))
+	}
+	require.FailNow(t, "unexpected aggregation", sqlparser.String(aggr))
+	return nil
+}
@@ -125,6 +125,30 @@ func WeightString(dst []byte, v sqltypes.Value, coerceTo sqltypes.Type, col coll
 	}
 }
 
+// TupleWeightString returns the weight string for a tuple of values, where
+// every value is weighted using the type with the same index in types.
+// Every weight string is prefixed with its length, so that two tuples only
+// have the same weight string if all of their values are equal.
+// A NULL value is written as a single marker byte that is not used as a
+// prefix for any other value.
+func TupleWeightString(dst []byte, values []sqltypes.Value, types []Type, sqlmode SQLMode) ([]byte, error) {
+	for i, v := range values {
+		if v.IsNull() {
+			dst = append(dst, 0)
+			continue
+		}
+		typ := types[i]
+		ws, _, err := WeightString(nil, v, typ.Type(), typ.Collation(), 0, 0, typ.Values(), sqlmode)
+		if err != nil {
+			return dst, err
+		}
+		dst = append(dst, 1)
+		dst = binary.BigEndian.AppendUint32(dst, uint32(len(ws)))
+		dst = append(dst, ws...)
+	}
+	return dst, nil
+}
+
 func fallbackWeightString(dst []byte, v sqltypes.Value, coerceTo sqltypes.Type, col collations.ID, length, precision int, values *EnumSetValues, sqlmode SQLMode) ([]byte, bool, error) {
 	e, err := valueToEvalCast(v, coerceTo, col, values, sqlmode)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"slices"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/mysql/collations"
@@ -181,3 +182,26 @@ func TestWeightStrings(t *testing.T) {
 		}
 	}
 }
+
+func TestTupleWeightString(t *testing.T) {
+	types := []Type{
+		NewType(sqltypes.VarChar, collations.CollationUtf8mb4ID),
+		NewType(sqltypes.Int64, collations.CollationBinaryID),
+	}
+
+	weight := func(values ...sqltypes.Value) string {
+		w, err := TupleWeightString(nil, values, types, 0)
+		require.NoError(t, err)
+		return string(w)
+	}
+
+	// the default collation is case and accent insensitive
+	assert.Equal(t, weight(sqltypes.NewVarChar("abc"), sqltypes.NewInt64(1)), weight(sqltypes.NewVarChar("ABC"), sqltypes.NewInt64(1)))
+	assert.NotEqual(t, weight(sqltypes.NewVarChar("abc"), sqltypes.NewInt64(1)), weight(sqltypes.NewVarChar("abc"), sqltypes.NewInt64(2)))
+	assert.NotEqual(t, weight(sqltypes.NewVarChar("abc"), sqltypes.NULL), weight(sqltypes.NewVarChar("abc"), sqltypes.NewInt64(0)))
+	assert.NotEqual(t, weight(sqltypes.NULL, sqltypes.NewInt64(1)), weight(sqltypes.NewVarChar(""), sqltypes.NewInt64(1)))
+
+	// values that are concatenated into the same bytes must still be told apart
+	types[1] = types[0]
+	assert.NotEqual(t, weight(sqltypes.NewVarChar("ab"), sqltypes.NewVarChar("c")), weight(sqltypes.NewVarChar("a"), sqltypes.NewVarChar("bc")))
+}
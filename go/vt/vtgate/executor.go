@@ -116,6 +116,9 @@ type Executor struct {
 	plans *PlanCache
 	epoch atomic.Uint32
 
+	// resultCache caches the results of read-only queries, if enabled.
+	resultCache *resultCache
+
 	normalize       bool
 	warnShardedOnly bool
 
@@ -349,6 +352,7 @@ func (e *Executor) StreamExecute(
 		}
 
 		if !canReturnRows(plan.Type) {
+			e.invalidateCachedResults(plan, safeSession)
 			return nil
 		}
 
@@ -1057,6 +1061,9 @@ func (e *Executor) SaveVSchema(vschema *vindexes.VSchema, stats *VSchemaStats) {
 	}
 	e.vschemaStats = stats
 	e.ClearPlans()
+	if e.resultCache != nil {
+		e.resultCache.clear()
+	}
 
 	if vschemaCounters != nil {
 		vschemaCounters.Add("Reload", 1)
@@ -1107,12 +1114,23 @@ func (e *Executor) getPlan(
 
 	vcursor.SetIgnoreMaxMemoryRows(sqlparser.IgnoreMaxMaxMemoryRowsDirective(stmt))
 	vcursor.SetConsolidator(sqlparser.Consolidator(stmt))
-	vcursor.SetWorkloadName(sqlparser.GetWorkloadNameFromStatement(stmt))
+	vcursor.SetResultCache(sqlparser.ResultCache(stmt))
+	if e.resultCache != nil {
+		vcursor.SetResultCacheable(resultCacheable(stmt))
+	}
+	workloadName := sqlparser.GetWorkloadNameFromStatement(stmt)
+	if workloadName == "" && vcursor.queryAttributes != nil {
+		workloadName = vcursor.queryAttributes.workloadName
+	}
+	vcursor.SetWorkloadName(workloadName)
 	vcursor.UpdateForeignKeyChecksState(sqlparser.ForeignKeyChecksState(stmt))
 	priority, err := sqlparser.GetPriorityFromStatement(stmt)
 	if err != nil {
 		return nil, err
 	}
+	if priority == "" && vcursor.queryAttributes != nil {
+		priority = vcursor.queryAttributes.priority
+	}
 	vcursor.SetPriority(priority)
 
 	setVarComment, err := prepareSetVarComment(vcursor, stmt)
@@ -1613,6 +1631,9 @@ func (e *Executor) Close() {
 	}
 	topo.Close()
 	e.plans.Close()
+	if e.resultCache != nil {
+		e.resultCache.Close()
+	}
 }
 
 func (e *Executor) environment() *vtenv.Environment {
@@ -16,6 +16,14 @@ limitations under the License.
 
 package vtgate
 
+import "vitess.io/vitess/go/stats"
+
+var (
+	resultCacheHits          = stats.NewCounter("ResultCacheHits", "Result cache hits")
+	resultCacheMisses        = stats.NewCounter("ResultCacheMisses", "Result cache misses, including the lookups of invalidated or expired results")
+	resultCacheInvalidations = stats.NewCountersWithSingleLabel("ResultCacheInvalidations", "Result cache invalidations of the results of a table or keyspace, by source", "Source")
+)
+
 const (
 	// ExecutorTemplate is the HTML template to display ExecutorStats.
 	ExecutorTemplate = `
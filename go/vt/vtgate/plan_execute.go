@@ -77,6 +77,9 @@ func (e *Executor) newExecute(
 		bindVars = make(map[string]*querypb.BindVariable)
 	}
 
+	qa := queryAttributesFromContext(ctx)
+	logStats.QueryAttributes = qa.bindVariables()
+
 	query, comments := sqlparser.SplitMarginComments(sql)
 
 	// 2: Parse and Validate query.
@@ -115,6 +118,9 @@ func (e *Executor) newExecute(
 		if err != nil {
 			return err
 		}
+		if err := vcursor.setQueryAttributes(qa); err != nil {
+			return err
+		}
 
 		// 3: Create a plan for the query.
 		// If we are retrying, it is likely that the routing rules have changed and hence we need to
@@ -318,7 +324,16 @@ func (e *Executor) executePlan(
 ) (*sqltypes.Result, error) {
 
 	// 4: Execute!
-	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	var qr *sqltypes.Result
+	var err error
+	if e.canCacheResult(plan, vcursor, safeSession) {
+		qr, err = e.executeCachingResult(ctx, plan, vcursor, bindVars)
+	} else {
+		qr, err = vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	}
+	if err == nil {
+		e.invalidateCachedResults(plan, safeSession)
+	}
 
 	// 5: Log and add statistics
 	e.setLogStats(logStats, plan, vcursor, execStart, err, qr)
@@ -194,7 +194,7 @@ func createInstructionFor(ctx context.Context, query string, stmt sqlparser.Stat
 	case *sqlparser.Set:
 		return buildSetPlan(stmt, vschema)
 	case *sqlparser.Load:
-		return buildLoadPlan(query, vschema)
+		return buildLoadPlan(query, stmt, vschema)
 	case sqlparser.DBDDLStatement:
 		return buildRoutePlan(stmt, reservedVars, vschema, buildDBDDLPlan)
 	case *sqlparser.Begin, *sqlparser.Commit, *sqlparser.Rollback,
@@ -306,7 +306,14 @@ func buildDBDDLPlan(stmt sqlparser.Statement, _ *sqlparser.ReservedVars, vschema
 	return nil, vterrors.VT13001(fmt.Sprintf("database DDL not recognized: %s", sqlparser.String(dbDDLstmt)))
 }
 
-func buildLoadPlan(query string, vschema plancontext.VSchema) (*planResult, error) {
+// loadBatchSize is the number of rows LOAD DATA LOCAL INFILE sends in a single insert
+const loadBatchSize = 500
+
+func buildLoadPlan(query string, stmt *sqlparser.Load, vschema plancontext.VSchema) (*planResult, error) {
+	if stmt.Local {
+		return buildLoadLocalPlan(stmt, vschema)
+	}
+
 	keyspace, err := vschema.DefaultKeyspace()
 	if err != nil {
 		return nil, err
@@ -329,6 +336,52 @@ func buildLoadPlan(query string, vschema plancontext.VSchema) (*planResult, erro
 	}), nil
 }
 
+// buildLoadLocalPlan plans LOAD DATA LOCAL INFILE. The file is read by vtgate,
+// and its rows are inserted through the regular insert planning, so that every
+// row is routed to its shard and owned lookup vindexes are kept up to date.
+func buildLoadLocalPlan(stmt *sqlparser.Load, vschema plancontext.VSchema) (*planResult, error) {
+	if len(stmt.SetExprs) > 0 {
+		return nil, vterrors.VT12001("SET clause in LOAD DATA")
+	}
+	if stmt.Charset.Name != "" {
+		// the rows are inserted as they are read, without converting them from the character set of the file
+		return nil, vterrors.VT12001("CHARACTER SET clause in LOAD DATA")
+	}
+	vTbl, _, _, _, err := vschema.FindTable(stmt.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	columns := stmt.Columns
+	if len(columns) == 0 {
+		// the fields of the file are the columns of the table, in order
+		if !vTbl.ColumnListAuthoritative {
+			return nil, vterrors.VT09004()
+		}
+		for _, col := range vTbl.Columns {
+			columns = append(columns, col.Name)
+		}
+	}
+
+	ins := &sqlparser.Insert{
+		Action:     stmt.Action,
+		Ignore:     stmt.Ignore,
+		Table:      sqlparser.NewAliasedTableExpr(sqlparser.NewTableNameWithQualifier(vTbl.Name.String(), vTbl.Keyspace.Name), ""),
+		Partitions: stmt.Partitions,
+		Columns:    columns,
+	}
+
+	return newPlanResult(&engine.Load{
+		Keyspace:    vTbl.Keyspace,
+		TableName:   vTbl.Name.String(),
+		FileName:    stmt.FileName,
+		Insert:      ins,
+		Format:      stmt.DataFormat,
+		IgnoreLines: stmt.IgnoreLines,
+		BatchSize:   loadBatchSize,
+	}, singleTable(vTbl.Keyspace.Name, vTbl.Name.String())), nil
+}
+
 func buildVSchemaDDLPlan(stmt *sqlparser.AlterVschema, vschema plancontext.VSchema) (*planResult, error) {
 	_, keyspace, _, err := vschema.TargetDestination(stmt.Table.Qualifier.String())
 	if err != nil {
@@ -18,6 +18,7 @@ package planbuilder
 
 import (
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
@@ -61,6 +62,8 @@ func transformToPrimitive(ctx *plancontext.PlanningContext, op operators.Operato
 		return transformOrdering(ctx, op)
 	case *operators.Aggregator:
 		return transformAggregator(ctx, op)
+	case *operators.Window:
+		return transformWindow(ctx, op)
 	case *operators.Distinct:
 		return transformDistinct(ctx, op)
 	case *operators.FkCascade:
@@ -77,11 +80,79 @@ func transformToPrimitive(ctx *plancontext.PlanningContext, op operators.Operato
 		return transformSequential(ctx, op)
 	case *operators.DMLWithInput:
 		return transformDMLWithInput(ctx, op)
+	case *operators.RecurseCTE:
+		return transformRecurseCTE(ctx, op)
+	case *operators.CTEVars:
+		return transformCTEVars(ctx, op)
 	}
 
 	return nil, vterrors.VT13001(fmt.Sprintf("unknown type encountered: %T (transformToPrimitive)", op))
 }
 
+func transformRecurseCTE(ctx *plancontext.PlanningContext, op *operators.RecurseCTE) (engine.Primitive, error) {
+	seed, err := transformToPrimitive(ctx, op.Seed)
+	if err != nil {
+		return nil, err
+	}
+	term, err := transformToPrimitive(ctx, op.Term)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]int, len(op.BindVars))
+	for i, bv := range op.BindVars {
+		vars[bv] = i
+	}
+
+	var checkCols []engine.CheckCol
+	if op.Def.Distinct() {
+		for idx, expr := range sqlparser.GetFirstSelect(op.Def.Seed()).SelectExprs {
+			ae, ok := expr.(*sqlparser.AliasedExpr)
+			if !ok {
+				return nil, vterrors.VT09015()
+			}
+			typ, _ := ctx.SemTable.TypeForExpr(ae.Expr)
+			checkCols = append(checkCols, engine.CheckCol{
+				Col:          idx,
+				Type:         typ,
+				CollationEnv: ctx.VSchema.Environment().CollationEnv(),
+			})
+		}
+	}
+
+	return &engine.RecurseCTE{
+		Seed:      seed,
+		Term:      term,
+		Vars:      vars,
+		CheckCols: checkCols,
+	}, nil
+}
+
+func transformCTEVars(ctx *plancontext.PlanningContext, op *operators.CTEVars) (engine.Primitive, error) {
+	cfg := &evalengine.Config{
+		ResolveType: ctx.SemTable.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+		Environment: ctx.VSchema.Environment(),
+	}
+
+	var exprs []evalengine.Expr
+	var columnNames []string
+	for _, col := range op.Columns {
+		expr, err := evalengine.Translate(op.ToArguments(ctx, col.Expr), cfg)
+		if err != nil {
+			return nil, err
+		}
+		exprs = append(exprs, expr)
+		columnNames = append(columnNames, col.ColumnName())
+	}
+
+	return &engine.Projection{
+		Input: &engine.SingleRow{},
+		Cols:  columnNames,
+		Exprs: exprs,
+	}, nil
+}
+
 func transformDMLWithInput(ctx *plancontext.PlanningContext, op *operators.DMLWithInput) (engine.Primitive, error) {
 	input, err := transformToPrimitive(ctx, op.Source)
 	if err != nil {
@@ -174,9 +245,14 @@ func transformInsertionSelection(ctx *plancontext.PlanningContext, op *operators
 			ColVindexes:       ins.ColVindexes,
 		},
 		VindexValueOffset: ins.VindexValueOffset,
+		ReplaceKeyOffsets: ins.ReplaceKeyOffsets,
 	}
 
 	eins.Prefix, _, eins.Suffix = generateInsertShardedQuery(ins.AST)
+	if op.Select == nil {
+		// the rows come from the DMLWithInput above.
+		return eins, nil
+	}
 
 	selectionPlan, err := transformToPrimitive(ctx, op.Select)
 	if err != nil {
@@ -254,12 +330,32 @@ func transformSubQuery(ctx *plancontext.PlanningContext, op *operators.SubQuery)
 	}
 
 	return &engine.SemiJoin{
-		Left:  outer,
-		Right: inner,
-		Vars:  op.Vars,
+		Opcode: semiJoinOpcode(op),
+		Left:   outer,
+		Right:  inner,
+		Vars:   op.Vars,
 	}, nil
 }
 
+func semiJoinOpcode(op *operators.SubQuery) engine.SemiJoinOpcode {
+	if !op.IsProjection {
+		if op.FilterType == opcode.PulloutNotExists {
+			return engine.SemiJoinNotExists
+		}
+		return engine.SemiJoinExists
+	}
+	switch op.FilterType {
+	case opcode.PulloutExists:
+		return engine.SemiJoinHasValues
+	case opcode.PulloutIn:
+		return engine.SemiJoinIn
+	case opcode.PulloutNotIn:
+		return engine.SemiJoinNotIn
+	default:
+		return engine.SemiJoinValue
+	}
+}
+
 // transformFkVerify transforms a FkVerify operator into a engine primitive
 func transformFkVerify(ctx *plancontext.PlanningContext, fkv *operators.FkVerify) (engine.Primitive, error) {
 	inputLP, err := transformToPrimitive(ctx, fkv.Input)
@@ -292,9 +388,6 @@ func transformFkVerify(ctx *plancontext.PlanningContext, fkv *operators.FkVerify
 }
 
 func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggregator) (engine.Primitive, error) {
-	if op.WithRollup {
-		return nil, vterrors.VT12001("GROUP BY WITH ROLLUP not supported for sharded queries")
-	}
 	src, err := transformToPrimitive(ctx, op.Source)
 	if err != nil {
 		return nil, err
@@ -313,6 +406,14 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		aggrParam.OrigOpcode = aggr.OriginalOpCode
 		aggrParam.WCol = aggr.WSOffset
 		aggrParam.Type = aggr.GetTypeCollation(ctx)
+		aggrParam.ExtraCols = aggr.ExtraOffsets
+		aggrParam.OrderBy = groupConcatOrderBy(ctx, aggr)
+		if aggr.OpCode == opcode.AggregateGrouping {
+			aggrParam.GroupingKeys, err = groupingKeysFor(ctx, op, aggr)
+			if err != nil {
+				return nil, err
+			}
+		}
 		aggregates = append(aggregates, aggrParam)
 	}
 
@@ -339,6 +440,130 @@ func transformAggregator(ctx *plancontext.PlanningContext, op *operators.Aggrega
 		Aggregates:          aggregates,
 		GroupByKeys:         groupByKeys,
 		TruncateColumnCount: op.ResultColumns,
+		WithRollup:          op.WithRollup,
+		Input:               src,
+	}, nil
+}
+
+// groupConcatOrderBy returns the ordering used inside GROUP_CONCAT, using the columns planned for the ORDER BY expressions
+func groupConcatOrderBy(ctx *plancontext.PlanningContext, aggr operators.Aggr) evalengine.Comparison {
+	f, ok := aggr.Func.(*sqlparser.GroupConcatExpr)
+	if !ok || len(aggr.OrderByOffsets) != len(f.OrderBy) {
+		return nil
+	}
+	var orderBy evalengine.Comparison
+	for idx, order := range f.OrderBy {
+		typ, _ := ctx.SemTable.TypeForExpr(order.Expr)
+		orderBy = append(orderBy, evalengine.OrderByParams{
+			Col:             aggr.OrderByOffsets[idx],
+			WeightStringCol: -1,
+			Desc:            order.Direction == sqlparser.DescOrder,
+			Type:            typ,
+			CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
+		})
+	}
+	return orderBy
+}
+
+// groupingKeysFor returns the indexes of the grouping columns that are passed as arguments to GROUPING()
+func groupingKeysFor(ctx *plancontext.PlanningContext, op *operators.Aggregator, aggr operators.Aggr) ([]int, error) {
+	fnc, ok := aggr.Original.Expr.(*sqlparser.FuncExpr)
+	if !ok {
+		return nil, vterrors.VT13001(fmt.Sprintf("expected GROUPING function, got: %s", sqlparser.String(aggr.Original)))
+	}
+	keys := make([]int, 0, len(fnc.Exprs))
+	for idx, arg := range fnc.Exprs {
+		key := slices.IndexFunc(op.Grouping, func(gb operators.GroupBy) bool {
+			return ctx.SemTable.EqualsExprWithDeps(gb.Inner, arg)
+		})
+		if key < 0 {
+			return nil, vterrors.VT03034(idx + 1)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+func transformWindow(ctx *plancontext.PlanningContext, op *operators.Window) (engine.Primitive, error) {
+	src, err := transformToPrimitive(ctx, op.Source)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &evalengine.Config{
+		ResolveType: ctx.SemTable.TypeForExpr,
+		Collation:   ctx.SemTable.Collation,
+		Environment: ctx.VSchema.Environment(),
+	}
+	translate := func(expr sqlparser.Expr) (evalengine.Expr, error) {
+		if expr == nil {
+			return nil, nil
+		}
+		return evalengine.Translate(expr, cfg)
+	}
+
+	var functions []*engine.WindowParams
+	for _, wf := range op.Functions {
+		frame, err := evalengine.TranslateWindowFrame(wf.Spec.FrameClause)
+		if err != nil {
+			return nil, err
+		}
+
+		var n, def sqlparser.Expr
+		switch fn := wf.Func.(type) {
+		case *sqlparser.NtileExpr:
+			n = fn.N
+		case *sqlparser.LagLeadExpr:
+			n, def = fn.N, fn.Default
+		case *sqlparser.NTHValueExpr:
+			n = fn.N
+		}
+
+		param := &engine.WindowParams{
+			Opcode:       wf.OpCode,
+			Col:          wf.ColOffset,
+			Frame:        frame,
+			Alias:        wf.Original.ColumnName(),
+			Expr:         wf.Func,
+			CollationEnv: ctx.VSchema.Environment().CollationEnv(),
+		}
+		if param.N, err = translate(n); err != nil {
+			return nil, err
+		}
+		if param.Default, err = translate(def); err != nil {
+			return nil, err
+		}
+		if wf.OpCode == opcode.WindowMin || wf.OpCode == opcode.WindowMax {
+			// the collation of the argument is needed to compare its values
+			typ, _ := ctx.SemTable.TypeForExpr(wf.Func.(sqlparser.AggrFunc).GetArg())
+			param.Type = typ
+		}
+		functions = append(functions, param)
+	}
+
+	comparison := func(offsets, wsOffsets []int, exprs []sqlparser.Expr, desc func(int) bool) evalengine.Comparison {
+		var cmp evalengine.Comparison
+		for idx, expr := range exprs {
+			typ, _ := ctx.SemTable.TypeForExpr(expr)
+			cmp = append(cmp, evalengine.OrderByParams{
+				Col:             offsets[idx],
+				WeightStringCol: wsOffsets[idx],
+				Desc:            desc(idx),
+				Type:            typ,
+				CollationEnv:    ctx.VSchema.Environment().CollationEnv(),
+			})
+		}
+		return cmp
+	}
+	orderExprs := slice.Map(op.OrderBy, func(o operators.OrderBy) sqlparser.Expr { return o.SimplifiedExpr })
+
+	return &engine.Window{
+		Functions:   functions,
+		PartitionBy: comparison(op.PartitionOffsets, op.PartitionWSOffsets, op.PartitionBy, func(int) bool { return false }),
+		OrderBy: comparison(op.OrderOffsets, op.OrderWSOffsets, orderExprs, func(idx int) bool {
+			return op.OrderBy[idx].Inner.Direction == sqlparser.DescOrder
+		}),
+		TruncateColumnCount: op.ResultColumns,
 		Input:               src,
 	}, nil
 }
@@ -487,6 +712,10 @@ func transformApplyJoinPlan(ctx *plancontext.PlanningContext, n *operators.Apply
 	if !n.JoinType.IsInner() {
 		opCode = engine.LeftJoin
 	}
+	if vars, ok := n.RHS.(*operators.CTEVars); ok && opCode == engine.InnerJoin && len(vars.Columns) == 0 {
+		// the reference to the recursive CTE produces a single row without any columns, so the join returns the lhs rows
+		return lhsOfCTEVarsJoin(n.Columns, lhs), nil
+	}
 
 	return &engine.Join{
 		Opcode: opCode,
@@ -497,6 +726,20 @@ func transformApplyJoinPlan(ctx *plancontext.PlanningContext, n *operators.Apply
 	}, nil
 }
 
+// lhsOfCTEVarsJoin returns the lhs of a join with a reference to a recursive CTE that doesn't produce any columns
+func lhsOfCTEVarsJoin(joinCols []int, lhs engine.Primitive) engine.Primitive {
+	cols := make([]int, len(joinCols))
+	identity := true
+	for idx, col := range joinCols {
+		cols[idx] = -col - 1
+		identity = identity && cols[idx] == idx
+	}
+	if identity {
+		return lhs
+	}
+	return newSimpleProjection(cols, nil, lhs)
+}
+
 func routeToEngineRoute(ctx *plancontext.PlanningContext, op *operators.Route, hints *queryHints) (*engine.Route, error) {
 	tableNames, err := getAllTableNames(op)
 	if err != nil {
@@ -632,6 +875,7 @@ func buildInsertPrimitive(
 	eins := &engine.Insert{
 		InsertCommon: ic,
 		VindexValues: ins.VindexValues,
+		ReplaceKeys:  ins.ReplaceKeys,
 	}
 
 	// we would need to generate the query on the fly. The only exception here is
@@ -672,7 +916,11 @@ func autoIncGenerate(gen *operators.Generate) *engine.Generate {
 
 func generateInsertShardedQuery(ins *sqlparser.Insert) (prefix string, mids sqlparser.Values, suffix sqlparser.OnDup) {
 	mids, isValues := ins.Rows.(sqlparser.Values)
-	prefixFormat := "insert %v%sinto %v%v "
+	action := sqlparser.InsertStr
+	if ins.Action == sqlparser.ReplaceAct {
+		action = sqlparser.ReplaceStr
+	}
+	prefixFormat := action + " %v%sinto %v%v "
 	if isValues {
 		// the mid values are filled differently
 		// with select uses sqlparser.String for sqlparser.Values
@@ -716,13 +964,19 @@ func buildUpdatePrimitive(
 	var vindexes []*vindexes.ColumnVindex
 	vQuery := ""
 	if len(upd.ChangedVindexValues) > 0 {
+		if stmt.Limit != nil && len(stmt.OrderBy) == 0 {
+			// The owned vindex query and the update must pick the same rows,
+			// so we make the limit deterministic by ordering on the primary key.
+			stmt.OrderBy = primaryKeyOrdering(upd.Target, stmt)
+			if len(stmt.OrderBy) == 0 {
+				return nil, vterrors.VT12001("vindex UPDATE with LIMIT and without ORDER BY on a table without a primary key")
+			}
+			upd.OwnedVindexQuery.OrderBy = stmt.OrderBy
+		}
 		upd.OwnedVindexQuery.From = stmt.GetFrom()
 		upd.OwnedVindexQuery.Where = stmt.Where
 		vQuery = sqlparser.String(upd.OwnedVindexQuery)
 		vindexes = upd.Target.VTable.ColumnVindexes
-		if upd.OwnedVindexQuery.Limit != nil && len(upd.OwnedVindexQuery.OrderBy) == 0 {
-			return nil, vterrors.VT12001("Vindex update should have ORDER BY clause when using LIMIT")
-		}
 	}
 	if upd.VerifyAll {
 		stmt.SetComments(stmt.GetParsedComments().SetMySQLSetVarValue(sysvars.ForeignKeyChecks, "OFF"))
@@ -736,6 +990,22 @@ func buildUpdatePrimitive(
 	}, nil
 }
 
+// primaryKeyOrdering returns an ascending ordering on the primary key columns of the DML target,
+// or nil when the primary key is not known.
+func primaryKeyOrdering(target operators.TargetTable, stmt *sqlparser.Update) sqlparser.OrderBy {
+	var orderBy sqlparser.OrderBy
+	for _, col := range target.VTable.PrimaryKey {
+		var colName *sqlparser.ColName
+		if sqlparser.MultiTable(stmt.TableExprs) {
+			colName = sqlparser.NewColNameWithQualifier(col.String(), target.Name)
+		} else {
+			colName = sqlparser.NewColName(col.String())
+		}
+		orderBy = append(orderBy, sqlparser.NewOrder(colName, sqlparser.AscOrder))
+	}
+	return orderBy
+}
+
 func buildDeletePrimitive(ctx *plancontext.PlanningContext, rb *operators.Route, dmlOp operators.Operator, stmt *sqlparser.Delete, hints *queryHints) (engine.Primitive, error) {
 	del := dmlOp.(*operators.Delete)
 
@@ -34,6 +34,9 @@ type (
 		stmt        sqlparser.Statement
 		tableNames  []string
 		dmlOperator Operator
+
+		// ctes are the recursive CTEs that need to be sent together with the query
+		ctes []*sqlparser.CommonTableExpr
 	}
 )
 
@@ -52,9 +55,37 @@ func ToSQL(ctx *plancontext.PlanningContext, op Operator) (_ sqlparser.Statement
 	if ctx.SemTable != nil {
 		q.sortTables()
 	}
+	if len(q.ctes) > 0 {
+		q.asSelectStatement().SetWith(&sqlparser.With{CTEs: q.ctes, Recursive: true})
+	}
 	return q.stmt, q.dmlOperator, nil
 }
 
+func (qb *queryBuilder) addCTE(cte *sqlparser.CommonTableExpr) {
+	if slices.ContainsFunc(qb.ctes, func(existing *sqlparser.CommonTableExpr) bool { return existing.ID.String() == cte.ID.String() }) {
+		return
+	}
+	qb.ctes = append(qb.ctes, cte)
+}
+
+// cteFor returns the CTE of the table if it is a CTETable. The semantic analysis only creates
+// CTETables for recursive CTEs, both for their uses and for the self reference in their
+// recursive term. Queries built during foreign key planning have no SemTable, and never refer to CTEs.
+func (qb *queryBuilder) cteFor(id semantics.TableSet) *semantics.RecursiveCTE {
+	if qb.ctx.SemTable == nil {
+		return nil
+	}
+	tableInfo, err := qb.ctx.SemTable.TableInfoFor(id)
+	if err != nil {
+		return nil
+	}
+	cte, isCTE := tableInfo.(*semantics.CTETable)
+	if !isCTE {
+		return nil
+	}
+	return cte.CTE
+}
+
 func (qb *queryBuilder) addTable(db, tableName, alias string, tableID semantics.TableSet, hints sqlparser.IndexHints) {
 	tableExpr := sqlparser.TableName{
 		Name:      sqlparser.NewIdentifierCS(tableName),
@@ -200,6 +231,9 @@ func (qb *queryBuilder) clearProjections() {
 }
 
 func (qb *queryBuilder) unionWith(other *queryBuilder, distinct bool) {
+	for _, cte := range other.ctes {
+		qb.addCTE(cte)
+	}
 	qb.stmt = &sqlparser.Union{
 		Left:     qb.asSelectStatement(),
 		Right:    other.asSelectStatement(),
@@ -221,6 +255,9 @@ var _ FromStatement = (*sqlparser.Delete)(nil)
 func (qb *queryBuilder) joinWith(other *queryBuilder, onCondition sqlparser.Expr, joinType sqlparser.JoinType) {
 	stmt := qb.stmt.(FromStatement)
 	otherStmt := other.stmt.(FromStatement)
+	for _, cte := range other.ctes {
+		qb.addCTE(cte)
+	}
 
 	if sel, isSel := stmt.(*sqlparser.Select); isSel {
 		otherSel := otherStmt.(*sqlparser.Select)
@@ -241,6 +278,46 @@ func (qb *queryBuilder) joinWith(other *queryBuilder, onCondition sqlparser.Expr
 	stmt.SetFrom(newFromClause)
 }
 
+// restoreLateralColumns is used when a LATERAL derived table has been merged with the tables it uses.
+// The arguments that were standing in for the columns of these tables are replaced by the columns again,
+// and the derived tables using them are marked as LATERAL.
+func (qb *queryBuilder) restoreLateralColumns(vars []BindVarExpr) {
+	if len(vars) == 0 {
+		return
+	}
+	// replaced keeps track of how many arguments have been replaced,
+	// and derivedTables of how many had been replaced when entering each derived table
+	replaced := 0
+	var derivedTables []int
+	pre := func(cursor *sqlparser.Cursor) bool {
+		if _, isDT := cursor.Node().(*sqlparser.DerivedTable); isDT {
+			derivedTables = append(derivedTables, replaced)
+		}
+		return true
+	}
+	post := func(cursor *sqlparser.Cursor) bool {
+		switch node := cursor.Node().(type) {
+		case *sqlparser.Argument:
+			idx := slices.IndexFunc(vars, func(bve BindVarExpr) bool { return bve.Name == node.Name })
+			if idx < 0 {
+				return true
+			}
+			col := sqlparser.CloneExpr(vars[idx].Expr)
+			sqlparser.RemoveKeyspaceInCol(col)
+			cursor.Replace(col)
+			replaced++
+		case *sqlparser.DerivedTable:
+			before := derivedTables[len(derivedTables)-1]
+			derivedTables = derivedTables[:len(derivedTables)-1]
+			if replaced > before {
+				node.Lateral = true
+			}
+		}
+		return true
+	}
+	qb.stmt = sqlparser.Rewrite(qb.stmt, pre, post).(sqlparser.Statement)
+}
+
 func (qb *queryBuilder) mergeWhereClauses(stmt, otherStmt FromStatement) {
 	predicate := stmt.GetWherePredicate()
 	if otherPredicate := otherStmt.GetWherePredicate(); otherPredicate != nil {
@@ -318,9 +395,23 @@ func (ts *tableSorter) Less(i, j int) bool {
 		return i < j
 	}
 
+	leftLateral, rightLateral := isLateral(left), isLateral(right)
+	switch {
+	case leftLateral && rightLateral:
+		return i < j
+	case leftLateral || rightLateral:
+		// LATERAL derived tables have to stay after the tables they use
+		return rightLateral
+	}
+
 	return ts.tbl.TableSetFor(left).TableOffset() < ts.tbl.TableSetFor(right).TableOffset()
 }
 
+func isLateral(tbl *sqlparser.AliasedTableExpr) bool {
+	dt, ok := tbl.Expr.(*sqlparser.DerivedTable)
+	return ok && dt.Lateral
+}
+
 // Swap implements the Sort interface
 func (ts *tableSorter) Swap(i, j int) {
 	ts.sel.From[i], ts.sel.From[j] = ts.sel.From[j], ts.sel.From[i]
@@ -388,6 +479,8 @@ func buildQuery(op Operator, qb *queryBuilder) {
 		buildOrdering(op, qb)
 	case *Aggregator:
 		buildAggregation(op, qb)
+	case *Window:
+		buildWindow(op, qb)
 	case *Union:
 		buildUnion(op, qb)
 	case *Distinct:
@@ -468,6 +561,17 @@ func buildAggregation(op *Aggregator, qb *queryBuilder) {
 	}
 }
 
+func buildWindow(op *Window, qb *queryBuilder) {
+	buildQuery(op.Source, qb)
+
+	qb.clearProjections()
+
+	cols := op.GetColumns(qb.ctx)
+	for _, column := range cols {
+		qb.addProjection(column)
+	}
+}
+
 func buildOrdering(op *Ordering, qb *queryBuilder) {
 	buildQuery(op.Source, qb)
 
@@ -487,6 +591,11 @@ func buildTable(op *Table, qb *queryBuilder) {
 	if op.QTable.IsInfSchema {
 		dbName = op.QTable.Table.Qualifier.String()
 	}
+	if cte := qb.cteFor(op.QTable.ID); cte != nil {
+		def := sqlparser.CloneRefOfCommonTableExpr(cte.Definition())
+		sqlparser.RemoveKeyspace(def)
+		qb.addCTE(def)
+	}
 	qb.addTable(dbName, op.QTable.Table.Name.String(), op.QTable.Alias.As.String(), TableID(op), op.QTable.Alias.Hints)
 	for _, pred := range op.QTable.Predicates {
 		qb.addPredicate(pred)
@@ -499,12 +608,13 @@ func buildTable(op *Table, qb *queryBuilder) {
 func buildProjection(op *Projection, qb *queryBuilder) {
 	buildQuery(op.Source, qb)
 
-	_, isSel := qb.stmt.(*sqlparser.Select)
+	sel, isSel := qb.stmt.(*sqlparser.Select)
 	if isSel {
+		srcCols := sel.SelectExprs
 		qb.clearProjections()
 		cols := op.GetSelectExprs(qb.ctx)
 		for _, column := range cols {
-			qb.addProjection(column)
+			qb.addProjection(passThroughColumn(column, srcCols))
 		}
 	}
 
@@ -526,6 +636,30 @@ func buildProjection(op *Projection, qb *queryBuilder) {
 	}
 }
 
+// passThroughColumn returns the column of the source a projection column passes through when the source projects
+// the value of a subquery under that name, since the name can't be used in the same SELECT list it is given in
+func passThroughColumn(column sqlparser.SelectExpr, srcCols sqlparser.SelectExprs) sqlparser.SelectExpr {
+	ae, ok := column.(*sqlparser.AliasedExpr)
+	if !ok {
+		return column
+	}
+	col, ok := ae.Expr.(*sqlparser.ColName)
+	if !ok || col.Qualifier.NonEmpty() {
+		return column
+	}
+	for _, srcCol := range srcCols {
+		srcAe, ok := srcCol.(*sqlparser.AliasedExpr)
+		if !ok || !srcAe.As.Equal(col.Name) {
+			continue
+		}
+		if _, isSubq := srcAe.Expr.(*sqlparser.Subquery); !isSubq {
+			return column
+		}
+		return &sqlparser.AliasedExpr{Expr: srcAe.Expr, As: sqlparser.NewIdentifierCI(ae.ColumnName())}
+	}
+	return column
+}
+
 func buildApplyJoin(op *ApplyJoin, qb *queryBuilder) {
 	predicates := slice.Map(op.JoinPredicates.columns, func(jc applyJoinColumn) sqlparser.Expr {
 		// since we are adding these join predicates, we need to mark to broken up version (RHSExpr) of it as done
@@ -542,6 +676,7 @@ func buildApplyJoin(op *ApplyJoin, qb *queryBuilder) {
 	qbR := &queryBuilder{ctx: qb.ctx}
 	buildQuery(op.RHS, qbR)
 	qb.joinWith(qbR, pred, op.JoinType)
+	qb.restoreLateralColumns(lateralVarsFor(qb.ctx, op.LHS, op.RHS))
 }
 
 func buildUnion(op *Union, qb *queryBuilder) {
@@ -27,20 +27,14 @@ import (
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
 )
 
-func errDistinctAggrWithMultiExpr(f sqlparser.AggrFunc) {
-	if f == nil {
-		panic(vterrors.VT12001("distinct aggregation function with multiple expressions"))
-	}
-	panic(vterrors.VT12001(fmt.Sprintf("distinct aggregation function with multiple expressions '%s'", sqlparser.String(f))))
-}
-
 func tryPushAggregator(ctx *plancontext.PlanningContext, aggregator *Aggregator) (output Operator, applyResult *ApplyResult) {
 	if aggregator.Pushed {
 		return aggregator, NoRewrite
 	}
 
-	// this rewrite is always valid, and we should do it whenever possible
-	if route, ok := aggregator.Source.(*Route); ok && (route.IsSingleShard() || overlappingUniqueVindex(ctx, aggregator.Grouping)) {
+	// this rewrite is always valid, and we should do it whenever possible.
+	// the super-aggregate rows of a rollup are built from several groups, so they can only be calculated by mysql if all rows are on a single shard
+	if route, ok := aggregator.Source.(*Route); ok && (route.IsSingleShard() || !aggregator.WithRollup && overlappingUniqueVindex(ctx, aggregator.Grouping)) {
 		return Swap(aggregator, route, "push down aggregation under route - remove original")
 	}
 
@@ -95,6 +89,9 @@ func pushAggregationThroughSubquery(
 	rootAggr *Aggregator,
 	src *SubQueryContainer,
 ) (Operator, *ApplyResult) {
+	if hasOrderedGroupConcat(rootAggr) {
+		return nil, nil
+	}
 	pushedAggr := rootAggr.SplitAggregatorBelowOperators([]Operator{src.Outer})
 	for _, subQuery := range src.Inner {
 		lhsCols := subQuery.OuterExpressionsNeeded(ctx, src.Outer)
@@ -138,7 +135,25 @@ func aggregateTheAggregate(a *Aggregator, i int) {
 		// Think of it as we are SUMming together a bunch of distributed COUNTs.
 		aggr.OriginalOpCode, aggr.OpCode = aggr.OpCode, opcode.AggregateSum
 		a.Aggregations[i] = aggr
+	case opcode.AggregateGroupConcat:
+		// The pushed down GROUP_CONCAT has already concatenated the arguments and removed
+		// the duplicates, so we only need to concatenate the results using the same separator
+		f := aggr.Func.(*sqlparser.GroupConcatExpr)
+		aggr.Func = &sqlparser.GroupConcatExpr{Exprs: f.Exprs[:1], Separator: f.Separator}
+		a.Aggregations[i] = aggr
+	}
+}
+
+// hasOrderedGroupConcat returns true if the aggregator has a GROUP_CONCAT with an ORDER BY.
+// The results of these can't be concatenated after they have been aggregated separately,
+// so all the rows have to be sent to the vtgate instead.
+func hasOrderedGroupConcat(a *Aggregator) bool {
+	for _, aggr := range a.Aggregations {
+		if aggr.OpCode == opcode.AggregateGroupConcat && len(aggr.orderBy()) > 0 {
+			return true
+		}
 	}
+	return false
 }
 
 func pushAggregationThroughRoute(
@@ -146,6 +161,15 @@ func pushAggregationThroughRoute(
 	aggregator *Aggregator,
 	route *Route,
 ) (Operator, *ApplyResult) {
+	if hasOrderedGroupConcat(aggregator) {
+		// the aggregation is done on the vtgate, and the distinct aggregations rely on
+		// the rows being ordered by the distinct expression, just like when we push the aggregation
+		if _, distinctExprs := checkIfWeCanPush(ctx, aggregator); len(distinctExprs) == 1 {
+			aggregator.DistinctExpr = distinctExprs[0]
+		}
+		return nil, nil
+	}
+
 	// Create a new aggregator to be placed below the route.
 	aggrBelowRoute := aggregator.SplitAggregatorBelowOperators(route.Inputs())
 	aggrBelowRoute.Aggregations = nil
@@ -171,16 +195,17 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 	distinctAggrGroupByAdded := false
 
 	for i, aggr := range aggregator.Aggregations {
+		if aggr.OpCode == opcode.AggregateGrouping {
+			aggrBelowRoute.Aggregations = append(aggrBelowRoute.Aggregations, aggrBelowRoute.pushGroupingPlaceholder(aggr))
+			continue
+		}
+
 		if !aggr.Distinct || canPushDistinctAggr {
 			aggrBelowRoute.Aggregations = append(aggrBelowRoute.Aggregations, aggr)
 			aggregateTheAggregate(aggregator, i)
 			continue
 		}
 
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(aggr.Func)
-		}
-
 		// We handle a distinct aggregation by turning it into a group by and
 		// doing the aggregating on the vtgate level instead
 		aeDistinctExpr := aeWrap(distinctExprs[0])
@@ -189,15 +214,22 @@ func pushAggregations(ctx *plancontext.PlanningContext, aggregator *Aggregator,
 		// We handle a distinct aggregation by turning it into a group by and
 		// doing the aggregating on the vtgate level instead
 		// Adding to group by can be done only once even though there are multiple distinct aggregation with same expression.
+		// When there are multiple distinct expressions, we group by all of them, and the columns
+		// for the remaining expressions are added when planning the offsets of the aggregator
 		if !distinctAggrGroupByAdded {
 			groupBy := NewGroupBy(distinctExprs[0])
 			groupBy.ColOffset = aggr.ColOffset
 			aggrBelowRoute.Grouping = append(aggrBelowRoute.Grouping, groupBy)
+			for _, expr := range distinctExprs[1:] {
+				aggrBelowRoute.Grouping = append(aggrBelowRoute.Grouping, NewGroupBy(expr))
+			}
 			distinctAggrGroupByAdded = true
 		}
 	}
 
-	if !canPushDistinctAggr {
+	// The vtgate uses the tuple of values to find the duplicates when there are multiple
+	// distinct expressions, so it's only a single expression that needs the input to be ordered
+	if !canPushDistinctAggr && len(distinctExprs) == 1 {
 		aggregator.DistinctExpr = distinctExprs[0]
 	}
 }
@@ -226,6 +258,10 @@ func checkIfWeCanPush(ctx *plancontext.PlanningContext, aggregator *Aggregator)
 		if len(distinctExprs) == 0 {
 			distinctExprs = args
 		}
+		if len(args) != len(distinctExprs) {
+			differentExpr = aggr.Original
+			continue
+		}
 		for idx, expr := range distinctExprs {
 			if !ctx.SemTable.EqualsExpr(expr, args[idx]) {
 				differentExpr = aggr.Original
@@ -543,10 +579,9 @@ func splitAggrColumnsToLeftAndRight(
 	// Distinct aggregation cannot be pushed down in the join.
 	// We keep node of the distinct aggregation expression to be used later for ordering.
 	if !canPushDistinctAggr {
-		if len(distinctExprs) != 1 {
-			errDistinctAggrWithMultiExpr(nil)
+		if len(distinctExprs) == 1 {
+			aggregator.DistinctExpr = distinctExprs[0]
 		}
-		aggregator.DistinctExpr = distinctExprs[0]
 		return nil, errAbortAggrPushing
 	}
 
@@ -613,13 +648,9 @@ func splitAvgAggregations(ctx *plancontext.PlanningContext, aggr *Aggregator) (O
 			continue
 		}
 
-		if avg.Distinct {
-			panic(vterrors.VT12001("AVG(distinct <>)"))
-		}
-
-		// We have an AVG that we need to split
-		sumExpr := &sqlparser.Sum{Arg: avg.Arg}
-		countExpr := &sqlparser.Count{Args: []sqlparser.Expr{avg.Arg}}
+		// We have an AVG that we need to split. AVG(DISTINCT x) is split into SUM(DISTINCT x) / COUNT(DISTINCT x)
+		sumExpr := &sqlparser.Sum{Arg: avg.Arg, Distinct: avg.Distinct}
+		countExpr := &sqlparser.Count{Args: []sqlparser.Expr{avg.Arg}, Distinct: avg.Distinct}
 		calcExpr := &sqlparser.BinaryExpr{
 			Operator: sqlparser.DivOp,
 			Left:     sumExpr,
@@ -634,10 +665,15 @@ func splitAvgAggregations(ctx *plancontext.PlanningContext, aggr *Aggregator) (O
 		for aggrOffset, aggregation := range aggr.Aggregations {
 			if offset == aggregation.ColOffset {
 				// We have found the AVG column. We'll change it to SUM, and then we add a COUNT as well
-				aggr.Aggregations[aggrOffset].OpCode = opcode.AggregateSum
+				sumCode, countCode := opcode.AggregateSum, opcode.AggregateCount
+				if avg.Distinct {
+					sumCode, countCode = opcode.AggregateSumDistinct, opcode.AggregateCountDistinct
+				}
+				aggr.Aggregations[aggrOffset].OpCode = sumCode
 
 				countExprAlias := aeWrap(countExpr)
-				countAggr := NewAggr(opcode.AggregateCount, countExpr, countExprAlias, sqlparser.String(countExpr))
+				countAggr := NewAggr(countCode, countExpr, countExprAlias, sqlparser.String(countExpr))
+				countAggr.Distinct = avg.Distinct
 				countAggr.ColOffset = len(aggr.Columns) + len(columns)
 				aggregations = append(aggregations, countAggr)
 				columns = append(columns, countExprAlias)
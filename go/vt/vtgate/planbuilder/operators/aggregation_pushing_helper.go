@@ -133,10 +133,6 @@ func (ab *aggBuilder) handleAggr(ctx *plancontext.PlanningContext, aggr Aggr) er
 	case opcode.AggregateMax, opcode.AggregateMin, opcode.AggregateAnyValue:
 		return ab.handlePushThroughAggregation(ctx, aggr)
 	case opcode.AggregateGroupConcat:
-		f := aggr.Func.(*sqlparser.GroupConcatExpr)
-		if f.Distinct || len(f.OrderBy) > 0 || f.Separator != "" {
-			panic("fail here")
-		}
 		// this needs special handling, currently aborting the push of function
 		// and later will try pushing the column instead.
 		// TODO: this should be handled better by pushing the function down.
@@ -146,6 +142,10 @@ func (ab *aggBuilder) handleAggr(ctx *plancontext.PlanningContext, aggr Aggr) er
 	case opcode.AggregateGtid:
 		// this is only used for SHOW GTID queries that will never contain joins
 		panic(vterrors.VT13001("cannot do join with vgtid"))
+	case opcode.AggregateGrouping:
+		// GROUPING() can only be evaluated by the aggregator doing the rollup,
+		// so we abort the splitting and keep the aggregation above the join
+		return errAbortAggrPushing
 	case opcode.AggregateSumDistinct, opcode.AggregateCountDistinct:
 		// we are not going to see values multiple times, so we don't need to multiply with the count(*) from the other side
 		return ab.handlePushThroughAggregation(ctx, aggr)
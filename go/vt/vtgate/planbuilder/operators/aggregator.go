@@ -98,9 +98,12 @@ func (a *Aggregator) addColumnWithoutPushing(ctx *plancontext.PlanningContext, e
 		case sqlparser.AggrFunc:
 			aggr = createAggrFromAggrFunc(e, expr)
 		case *sqlparser.FuncExpr:
-			if IsAggr(ctx, e) {
+			switch {
+			case isGroupingFunc(e):
+				aggr = NewAggr(opcode.AggregateGrouping, nil, expr, expr.As.String())
+			case IsAggr(ctx, e):
 				aggr = NewAggr(opcode.AggregateUDF, nil, expr, expr.As.String())
-			} else {
+			default:
 				aggr = NewAggr(opcode.AggregateAnyValue, nil, expr, expr.As.String())
 			}
 		default:
@@ -174,15 +177,22 @@ func (a *Aggregator) AddColumn(ctx *plancontext.PlanningContext, reuse bool, gro
 		}
 	}
 
+	pushedAe := ae
 	if !groupBy {
-		aggr := NewAggr(opcode.AggregateAnyValue, nil, ae, ae.As.String())
+		code := opcode.AggregateAnyValue
+		if isGroupingFunc(rewritten) {
+			// GROUPING() is evaluated by this operator, so the input only has to provide a placeholder column
+			code = opcode.AggregateGrouping
+			pushedAe = aeWrap(groupingPlaceholder())
+		}
+		aggr := NewAggr(code, nil, ae, ae.As.String())
 		aggr.ColOffset = len(a.Columns)
 		a.Aggregations = append(a.Aggregations, aggr)
 	}
 
 	offset := len(a.Columns)
 	a.Columns = append(a.Columns, ae)
-	incomingOffset := a.Source.AddColumn(ctx, false, groupBy, ae)
+	incomingOffset := a.Source.AddColumn(ctx, false, groupBy, pushedAe)
 
 	if offset != incomingOffset {
 		panic(errFailedToPlan(ae))
@@ -376,19 +386,35 @@ func (a *Aggregator) planOffsets(ctx *plancontext.PlanningContext) Operator {
 		offset := a.internalAddColumn(ctx, aeWrap(weightStringFor(arg)), true)
 		a.Aggregations[idx].WSOffset = offset
 	}
+	a.planArgOffsets(ctx)
 	return nil
 }
 
+// planArgOffsets adds the columns needed by aggregations that use more than one argument,
+// and by the ORDER BY inside GROUP_CONCAT
+func (a *Aggregator) planArgOffsets(ctx *plancontext.PlanningContext) {
+	for idx, aggr := range a.Aggregations {
+		for _, arg := range aggr.extraArgs() {
+			offset := a.internalAddColumn(ctx, aeWrap(arg), false)
+			a.Aggregations[idx].ExtraOffsets = append(a.Aggregations[idx].ExtraOffsets, offset)
+		}
+		for _, order := range aggr.orderBy() {
+			offset := a.internalAddColumn(ctx, aeWrap(order.Expr), false)
+			a.Aggregations[idx].OrderByOffsets = append(a.Aggregations[idx].OrderByOffsets, offset)
+		}
+	}
+}
+
 func (aggr Aggr) getPushColumn() sqlparser.Expr {
 	switch aggr.OpCode {
 	case opcode.AggregateAnyValue:
 		return aggr.Original.Expr
 	case opcode.AggregateCountStar:
 		return sqlparser.NewIntLiteral("1")
-	case opcode.AggregateGroupConcat:
-		if len(aggr.Func.GetArgs()) > 1 {
-			panic(vterrors.VT12001("group_concat with more than 1 column"))
-		}
+	case opcode.AggregateGrouping:
+		return groupingPlaceholder()
+	case opcode.AggregateGroupConcat, opcode.AggregateCountDistinct:
+		// any other arguments are added by planArgOffsets
 		return aggr.Func.GetArg()
 	default:
 		if len(aggr.Func.GetArgs()) > 1 {
@@ -415,6 +441,7 @@ func (a *Aggregator) planOffsetsNotPushed(ctx *plancontext.PlanningContext) {
 	}
 
 	a.pushRemainingGroupingColumnsAndWeightStrings(ctx)
+	a.planArgOffsets(ctx)
 }
 
 func (a *Aggregator) addIfAggregationColumn(ctx *plancontext.PlanningContext, colIdx int) int {
@@ -503,9 +530,32 @@ func (a *Aggregator) SplitAggregatorBelowOperators(input []Operator) *Aggregator
 	newOp.Pushed = false
 	newOp.Original = false
 	newOp.DT = nil
+	// the super-aggregate rows are produced by the original aggregator, so the pushed one just groups the rows
+	newOp.WithRollup = false
+	for i, aggr := range newOp.Aggregations {
+		if aggr.OpCode == opcode.AggregateGrouping {
+			newOp.Aggregations[i] = newOp.pushGroupingPlaceholder(aggr)
+		}
+	}
 	return newOp
 }
 
+// groupingPlaceholder returns the expression used instead of GROUPING() when the rollup is done at the vtgate level.
+// The function can't be evaluated without the rollup, so all we need from the input is a column to hold its value.
+func groupingPlaceholder() sqlparser.Expr {
+	return sqlparser.NewIntLiteral("0")
+}
+
+// pushGroupingPlaceholder replaces the column of a GROUPING() aggregation with the grouping placeholder,
+// and returns the aggregation that takes its place in an aggregator that is pushed down.
+func (a *Aggregator) pushGroupingPlaceholder(aggr Aggr) Aggr {
+	ae := aeWrap(groupingPlaceholder())
+	a.Columns[aggr.ColOffset] = ae
+	placeholder := NewAggr(opcode.AggregateAnyValue, nil, ae, "")
+	placeholder.ColOffset = aggr.ColOffset
+	return placeholder
+}
+
 func (a *Aggregator) introducesTableID() semantics.TableSet {
 	return a.DT.introducesTableID()
 }
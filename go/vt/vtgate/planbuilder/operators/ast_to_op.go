@@ -246,6 +246,10 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 			panic(err)
 		}
 
+		if cte, isCTE := tableInfo.(*semantics.CTETable); isCTE && cte.GetVindexTable() == nil {
+			return createRecurseCTE(ctx, cte, tableID)
+		}
+
 		if vt, isVindex := tableInfo.(*semantics.VindexTable); isVindex {
 			solves := tableID
 			return &Vindex{
@@ -269,13 +273,21 @@ func getOperatorFromAliasedTableExpr(ctx *plancontext.PlanningContext, tableExpr
 			tbl.Select.SetOrderBy(nil)
 		}
 
+		var lateralVars []BindVarExpr
+		if tbl.Lateral {
+			lateralVars = replaceLateralColumns(ctx, tbl)
+		}
+
 		inner := translateQueryToOp(ctx, tbl.Select)
 		if horizon, ok := inner.(*Horizon); ok {
 			horizon.TableId = &tableID
 			horizon.Alias = tableExpr.As.String()
 			horizon.ColumnAliases = tableExpr.Columns
+			horizon.LateralVars = lateralVars
 			qp := CreateQPFromSelectStatement(ctx, tbl.Select)
 			horizon.QP = qp
+		} else if len(lateralVars) > 0 {
+			panic(vterrors.VT13001(fmt.Sprintf("expected a horizon for the LATERAL derived table %s, got %T", tableExpr.As.String(), inner)))
 		}
 
 		return inner
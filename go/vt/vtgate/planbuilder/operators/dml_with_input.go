@@ -89,15 +89,18 @@ func (d *DMLWithInput) GetOrdering(ctx *plancontext.PlanningContext) []OrderBy {
 }
 
 func (d *DMLWithInput) planOffsets(ctx *plancontext.PlanningContext) Operator {
-	// go through the primary key columns to get offset from the input
-	offsets := make([][]int, len(d.cols))
-	for idx, columns := range d.cols {
-		for _, col := range columns {
-			offset := d.Source.AddColumn(ctx, true, false, aeWrap(col))
-			offsets[idx] = append(offsets[idx], offset)
+	// the offsets are already known when the input is a planned select, as for REPLACE ... SELECT.
+	if d.Offsets == nil {
+		// go through the primary key columns to get offset from the input
+		offsets := make([][]int, len(d.cols))
+		for idx, columns := range d.cols {
+			for _, col := range columns {
+				offset := d.Source.AddColumn(ctx, true, false, aeWrap(col))
+				offsets[idx] = append(offsets[idx], offset)
+			}
 		}
+		d.Offsets = offsets
 	}
-	d.Offsets = offsets
 
 	// go through the update list and get offset for input columns
 	bvList := make([]map[string]int, len(d.updList))
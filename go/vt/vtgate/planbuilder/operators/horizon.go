@@ -41,6 +41,10 @@ type Horizon struct {
 	Alias         string
 	ColumnAliases sqlparser.Columns // derived tables can have their column aliases specified outside the subquery
 
+	// LateralVars is only set for LATERAL derived tables. It holds the columns of the tables
+	// to the left of the derived table that are used inside it, and that have been replaced by arguments
+	LateralVars []BindVarExpr
+
 	// QP contains the QueryProjection for this op
 	QP *QueryProjection
 
@@ -60,6 +64,7 @@ func (h *Horizon) Clone(inputs []Operator) Operator {
 	klone := *h
 	klone.Source = inputs[0]
 	klone.ColumnAliases = sqlparser.Clone(h.ColumnAliases)
+	klone.LateralVars = slices.Clone(h.LateralVars)
 	klone.Columns = slices.Clone(h.Columns)
 	klone.ColumnsOffset = slices.Clone(h.ColumnsOffset)
 	klone.QP = h.QP
@@ -103,6 +108,14 @@ func (h *Horizon) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparser.
 	if ContainsAggr(ctx, newExpr) {
 		return newFilter(h, expr)
 	}
+	if sel, isSel := h.Query.(*sqlparser.Select); isSel && sqlparser.ContainsWindowFunc(sel.SelectExprs) {
+		// filtering the rows of the derived table would change the result of its window functions
+		return newFilter(h, expr)
+	}
+	if sel, isSel := h.Query.(*sqlparser.Select); isSel && sel.GroupBy != nil && sel.GroupBy.WithRollup {
+		// the super-aggregate rows have to be filtered themselves, not be calculated using only the filtered rows
+		return newFilter(h, expr)
+	}
 	h.Source = h.Source.AddPredicate(ctx, newExpr)
 	return h
 }
@@ -79,6 +79,8 @@ func expandSelectHorizon(ctx *plancontext.PlanningContext, horizon *Horizon, sel
 	var extracted []string
 	if qp.HasAggr {
 		extracted = append(extracted, "Aggregation")
+	} else if qp.HasWindow {
+		extracted = append(extracted, "Window", "Projection")
 	} else {
 		extracted = append(extracted, "Projection")
 	}
@@ -115,10 +117,21 @@ func expandSelectHorizon(ctx *plancontext.PlanningContext, horizon *Horizon, sel
 }
 
 func expandOrderBy(ctx *plancontext.PlanningContext, op Operator, qp *QueryProjection) Operator {
-	proj := newAliasedProjection(op)
+	proj := newAliasedProjection(nil)
 	var newOrder []OrderBy
 	sqc := &SubQueryBuilder{}
 	for _, expr := range qp.OrderExprs {
+		if colExpr := selectedSubqueryColumn(ctx, op, qp, expr.SimplifiedExpr); colExpr != nil {
+			// the subquery is already evaluated by the projection of the select expressions
+			newOrder = append(newOrder, OrderBy{
+				Inner: &sqlparser.Order{
+					Expr:      colExpr,
+					Direction: expr.Inner.Direction,
+				},
+				SimplifiedExpr: colExpr,
+			})
+			continue
+		}
 		newExpr, subqs := sqc.pullOutValueSubqueries(ctx, expr.SimplifiedExpr, TableID(op), false)
 		if newExpr == nil {
 			// no subqueries found, let's move on
@@ -139,7 +152,15 @@ func expandOrderBy(ctx *plancontext.PlanningContext, op Operator, qp *QueryProje
 	if len(proj.Columns.GetColumns()) > 0 {
 		// if we had to project columns for the ordering,
 		// we need the projection as source
-		op = proj
+		if src, ok := op.(*Projection); ok && src.DT == nil && !src.isStarProjection() {
+			// the subquery values are added after the select expressions,
+			// so the columns returned by the query keep their offsets
+			src.Source = sqc.getRootOperator(src.Source, nil)
+			src.addProjExpr(proj.Columns.(AliasedProjections)...)
+		} else {
+			proj.Source = sqc.getRootOperator(op, nil)
+			op = proj
+		}
 	}
 
 	return &Ordering{
@@ -148,6 +169,29 @@ func expandOrderBy(ctx *plancontext.PlanningContext, op Operator, qp *QueryProje
 	}
 }
 
+// selectedSubqueryColumn returns the column of the select projection that evaluates the given
+// expression using subqueries, or nil if the expression is not one of the select expressions
+func selectedSubqueryColumn(ctx *plancontext.PlanningContext, op Operator, qp *QueryProjection, expr sqlparser.Expr) sqlparser.Expr {
+	proj, ok := op.(*Projection)
+	if !ok {
+		return nil
+	}
+	ap, err := proj.GetAliasedProjections()
+	if err != nil || len(ap) != len(qp.SelectExprs) {
+		return nil
+	}
+	for idx, selectExpr := range qp.SelectExprs {
+		ae, err := selectExpr.GetAliasedExpr()
+		if err != nil || !ctx.SemTable.EqualsExprWithDeps(ae.Expr, expr) {
+			continue
+		}
+		if _, isSubq := ap[idx].Info.(SubQueryExpression); isSubq {
+			return ap[idx].ColExpr
+		}
+	}
+	return nil
+}
+
 func createProjectionFromSelect(ctx *plancontext.PlanningContext, horizon *Horizon) Operator {
 	qp := horizon.getQP(ctx)
 
@@ -160,6 +204,10 @@ func createProjectionFromSelect(ctx *plancontext.PlanningContext, horizon *Horiz
 		}
 	}
 
+	if qp.HasWindow {
+		return createProjectionWithWindow(ctx, qp, dt, horizon.src())
+	}
+
 	if !qp.NeedsAggregation() {
 		projX := createProjectionWithoutAggr(ctx, qp, horizon.src())
 		projX.DT = dt
@@ -169,6 +217,21 @@ func createProjectionFromSelect(ctx *plancontext.PlanningContext, horizon *Horiz
 	return createProjectionWithAggr(ctx, qp, dt, horizon.src())
 }
 
+func createProjectionWithWindow(ctx *plancontext.PlanningContext, qp *QueryProjection, dt *DerivedTable, src Operator) Operator {
+	if qp.NeedsAggregation() {
+		panic(vterrors.VT12001("window functions together with aggregations on a sharded keyspace"))
+	}
+	for _, expr := range qp.SelectExprs {
+		if _, isStar := expr.Col.(*sqlparser.StarExpr); isStar {
+			panic(vterrors.VT09015())
+		}
+	}
+
+	proj := createProjectionWithoutAggr(ctx, qp, newWindow(ctx, qp, src))
+	proj.DT = dt
+	return proj
+}
+
 func createProjectionWithAggr(ctx *plancontext.PlanningContext, qp *QueryProjection, dt *DerivedTable, src Operator) Operator {
 	aggregations, complexAggr := qp.AggregationExpressions(ctx, true)
 	aggrOp := &Aggregator{
@@ -191,7 +254,33 @@ func createProjectionWithAggr(ctx *plancontext.PlanningContext, qp *QueryProject
 			aggregations[idx].SubQueryExpression = subqs
 		}
 	}
+
+	// subqueries in the grouping are evaluated by a projection under the aggregator,
+	// so the aggregator can group on the value of the subquery like on any other column
+	proj := newAliasedProjection(nil)
+	copies := map[*sqlparser.Subquery]*sqlparser.Subquery{}
+	for idx, grouping := range aggrOp.Grouping {
+		expr := copyAroundSubqueries(ctx, grouping.Inner)
+		copySubqueries(ctx, expr, copies)
+		newExpr, subqs := sqc.pullOutValueSubqueries(ctx, expr, outerID, false)
+		if newExpr != nil {
+			aggrOp.Grouping[idx].Inner = restoreSubqueries(grouping.Inner, copies)
+			proj.addSubqueryExpr(aeWrap(aggrOp.Grouping[idx].Inner), newExpr, subqs...)
+		}
+	}
+	if len(copies) > 0 {
+		// the select expressions can use the same subqueries as the grouping
+		for _, expr := range qp.SelectExprs {
+			if ae, ok := expr.Col.(*sqlparser.AliasedExpr); ok {
+				ae.Expr = restoreSubqueries(ae.Expr, copies)
+			}
+		}
+	}
 	aggrOp.Source = sqc.getRootOperator(src, nil)
+	if len(proj.Columns.GetColumns()) > 0 {
+		proj.Source = aggrOp.Source
+		aggrOp.Source = proj
+	}
 
 	// create the projection columns from aggregator.
 	if complexAggr {
@@ -200,6 +289,44 @@ func createProjectionWithAggr(ctx *plancontext.PlanningContext, qp *QueryProject
 	return createProjectionForSimpleAggregation(ctx, aggrOp, qp)
 }
 
+// copyAroundSubqueries copies the nodes of the expression leading to its subqueries, so the subqueries
+// can be replaced without changing the original expression. The subqueries themselves are not copied,
+// since the semantic analysis of the tables they use is tied to the original AST nodes.
+func copyAroundSubqueries(ctx *plancontext.PlanningContext, expr sqlparser.Expr) sqlparser.Expr {
+	return sqlparser.CopyOnRewrite(expr, dontEnterSubqueries, func(cursor *sqlparser.CopyOnWriteCursor) {
+		if _, isSubq := cursor.Node().(*sqlparser.Subquery); isSubq {
+			cursor.Replace(cursor.Node())
+		}
+	}, ctx.SemTable.CopySemanticInfo).(sqlparser.Expr)
+}
+
+// copySubqueries stores copies of the subqueries found in the expression. Pulling out a subquery
+// rewrites the predicates of the subquery AST, so the copies are used where the original subquery is needed
+func copySubqueries(ctx *plancontext.PlanningContext, expr sqlparser.Expr, copies map[*sqlparser.Subquery]*sqlparser.Subquery) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		subq, ok := node.(*sqlparser.Subquery)
+		if !ok {
+			return true, nil
+		}
+		if _, found := copies[subq]; !found {
+			copies[subq] = cloneASTAndSemState(ctx, subq)
+		}
+		return false, nil
+	}, expr)
+}
+
+// restoreSubqueries replaces the subqueries in the expression with the copies taken by copySubqueries
+func restoreSubqueries(expr sqlparser.Expr, copies map[*sqlparser.Subquery]*sqlparser.Subquery) sqlparser.Expr {
+	return sqlparser.Rewrite(expr, nil, func(cursor *sqlparser.Cursor) bool {
+		if subq, ok := cursor.Node().(*sqlparser.Subquery); ok {
+			if cp, found := copies[subq]; found {
+				cursor.Replace(cp)
+			}
+		}
+		return true
+	}).(sqlparser.Expr)
+}
+
 func createProjectionForSimpleAggregation(ctx *plancontext.PlanningContext, a *Aggregator, qp *QueryProjection) Operator {
 outer:
 	for colIdx, expr := range qp.SelectExprs {
@@ -17,8 +17,11 @@ limitations under the License.
 package operators
 
 import (
+	"fmt"
+	"slices"
 	"strconv"
 
+	"vitess.io/vitess/go/slice"
 	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -50,6 +53,15 @@ type Insert struct {
 	// that will appear in the result set of the select query.
 	VindexValueOffset [][]int
 
+	// ReplaceKeys specifies, for a REPLACE, the values of the primary and unique key columns.
+	// They are used to drop the rows that are replaced by a later row of the same statement.
+	// The structure is key, column, row.
+	ReplaceKeys [][][]evalengine.Expr
+
+	// ReplaceKeyOffsets stores, for a REPLACE, the offsets of the primary and unique key columns
+	// in the rows that are inserted.
+	ReplaceKeyOffsets [][]int
+
 	noInputs
 	noColumns
 	noPredicates
@@ -93,6 +105,8 @@ func (i *Insert) Clone([]Operator) Operator {
 		ColVindexes:       i.ColVindexes,
 		VindexValues:      i.VindexValues,
 		VindexValueOffset: i.VindexValueOffset,
+		ReplaceKeys:       i.ReplaceKeys,
+		ReplaceKeyOffsets: i.ReplaceKeyOffsets,
 	}
 }
 
@@ -109,39 +123,294 @@ func createOperatorFromInsert(ctx *plancontext.PlanningContext, ins *sqlparser.I
 
 	vTbl, routing := buildVindexTableForDML(ctx, tableInfo, qt, ins, "insert")
 
-	deleteBeforeInsert := false
-	if ins.Action == sqlparser.ReplaceAct &&
-		(ctx.SemTable.ForeignKeysPresent() || vTbl.Keyspace.Sharded) &&
-		(len(vTbl.PrimaryKey) > 0 || len(vTbl.UniqueKeys) > 0) {
-		// this needs a delete before insert as there can be row clash which needs to be deleted first.
-		ins.Action = sqlparser.InsertAct
-		deleteBeforeInsert = true
+	if ins.Action != sqlparser.ReplaceAct ||
+		!ctx.SemTable.ForeignKeysPresent() && !(vTbl.Keyspace.Sharded && len(vTbl.ColumnVindexes) > 0) {
+		return checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
 	}
 
-	insOp := checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
-
-	if !deleteBeforeInsert {
-		return insOp
+	// REPLACE deletes the rows that clash on a primary or unique key before inserting.
+	// Sending it down as is would skip the owned vindexes and foreign keys of the deleted rows,
+	// so we turn it into a delete followed by an insert.
+	if len(vTbl.PrimaryKey) == 0 && len(vTbl.UniqueKeys) == 0 {
+		if vTbl.Keyspace.Sharded {
+			// we can't tell which rows the REPLACE would remove.
+			panic(vterrors.VT09015())
+		}
+		// no unique keys means no row can clash, so a plain insert does the same thing.
+		ins.Action = sqlparser.InsertAct
+		return checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
 	}
 
+	if ins.Columns == nil {
+		if !vTbl.ColumnListAuthoritative {
+			panic(vterrors.VT09004())
+		}
+		ins = populateInsertColumnlist(ins, vTbl)
+	}
 	rows, isRows := ins.Rows.(sqlparser.Values)
 	if !isRows {
-		panic(vterrors.VT12001("REPLACE INTO using select statement"))
+		return createReplaceSelectOperator(ctx, ins, vTbl, routing)
+	}
+	for _, row := range rows {
+		if len(row) != len(ins.Columns) {
+			panic(vterrors.VT03006())
+		}
 	}
 
+	// the insert planning rewrites the values, so the delete predicate is built first.
 	pkCompExpr := pkCompExpression(vTbl, ins, rows)
 	uniqKeyCompExprs := uniqKeyCompExpressions(vTbl, ins, rows)
 	whereExpr := getWhereCondExpr(append(uniqKeyCompExprs, pkCompExpr))
+	var replaceKeys [][][]evalengine.Expr
+	if vTbl.Keyspace.Sharded {
+		replaceKeys = replaceKeyValues(ctx, vTbl, ins, rows)
+	}
+
+	// the statement is still sent as REPLACE, so rows clashing on unique keys
+	// that are not in the vschema are replaced by the shard itself.
+	insOp := checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
+	if whereExpr == nil {
+		// none of the keys can clash, e.g. the primary key comes from a sequence.
+		return insOp
+	}
+	replacedInsert(insOp).ReplaceKeys = replaceKeys
 
 	delStmt := &sqlparser.Delete{
 		Comments:   ins.Comments,
 		TableExprs: sqlparser.TableExprs{sqlparser.Clone(ins.Table)},
-		Where:      sqlparser.NewWhere(sqlparser.WhereClause, whereExpr),
+		Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.Clone(whereExpr)),
 	}
 	delOp := createOpFromStmt(ctx, delStmt, false, "")
 	return &Sequential{Sources: []Operator{delOp, insOp}}
 }
 
+// createReplaceSelectOperator plans REPLACE ... SELECT as a DMLWithInput. The rows are selected once,
+// the rows clashing with them on the primary key or on a unique key are deleted, and then the rows are inserted.
+func createReplaceSelectOperator(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, vTbl *vindexes.Table, routing Routing) Operator {
+	// the select returns the values in the order of the insert columns,
+	// so a key column is read from the select at the offset of the column in the insert.
+	insCols := make([]*sqlparser.ColName, 0, len(ins.Columns))
+	insOffsets := make([]int, 0, len(ins.Columns))
+	for idx, col := range ins.Columns {
+		insCols = append(insCols, sqlparser.NewColName(col.String()))
+		insOffsets = append(insOffsets, idx)
+	}
+
+	var keys [][]sqlparser.IdentifierCI
+	if len(vTbl.PrimaryKey) > 0 {
+		keys = append(keys, vTbl.PrimaryKey)
+	}
+	for _, uniqKey := range vTbl.UniqueKeys {
+		var keyCols []sqlparser.IdentifierCI
+		for _, expr := range uniqKey {
+			col, isCol := expr.(*sqlparser.ColName)
+			if !isCol {
+				panic(vterrors.VT12001("REPLACE INTO using select statement on a table with an expression in a unique key"))
+			}
+			keyCols = append(keyCols, col.Name)
+		}
+		keys = append(keys, keyCols)
+	}
+
+	var keyOffsets [][]int
+	var keyColNames [][]*sqlparser.ColName
+	for _, keyCols := range keys {
+		offsets, colNames := replaceKeyOffsets(vTbl, ins, keyCols)
+		if offsets == nil {
+			continue
+		}
+		keyOffsets = append(keyOffsets, offsets)
+		keyColNames = append(keyColNames, colNames)
+	}
+
+	insOp := checkAndCreateInsertOperator(ctx, ins, vTbl, routing)
+	if len(keyOffsets) == 0 {
+		// none of the keys can clash, e.g. the primary key comes from a sequence.
+		return insOp
+	}
+
+	if lc, isLC := insOp.(*LockAndComment); isLC {
+		insOp = lc.Source
+	}
+	insSel, isInsSel := insOp.(*InsertSelection)
+	if !isInsSel {
+		panic(vterrors.VT13001(fmt.Sprintf("unexpected operator for REPLACE INTO using select statement: %T", insOp)))
+	}
+
+	var dmls []Operator
+	for _, colNames := range keyColNames {
+		var lhs sqlparser.Expr = sqlparser.ValTuple(slice.Map(colNames, func(col *sqlparser.ColName) sqlparser.Expr { return col }))
+		if len(colNames) == 1 {
+			lhs = colNames[0]
+		}
+		delStmt := &sqlparser.Delete{
+			Comments:   ins.Comments,
+			TableExprs: sqlparser.TableExprs{sqlparser.Clone(ins.Table)},
+			Where:      sqlparser.NewWhere(sqlparser.WhereClause, sqlparser.NewComparisonExpr(sqlparser.InOp, lhs, sqlparser.ListArg(engine.DmlVals), nil)),
+		}
+		dmls = append(dmls, createOpFromStmt(ctx, delStmt, false, ""))
+	}
+
+	// the rows are read once and deleted before they are inserted, so they are locked for update.
+	selOp := insSel.Select
+	if lc, isLC := selOp.(*LockAndComment); isLC {
+		lc.Lock = sqlparser.ForUpdateLock
+	}
+	insSel.Select = nil
+	if vTbl.Keyspace.Sharded {
+		replacedInsert(insSel.Insert).ReplaceKeyOffsets = append(keyOffsets, uniqueVindexOffsets(vTbl, keys, ins)...)
+	}
+
+	var op Operator = &DMLWithInput{
+		Source:  selOp,
+		DML:     append(dmls, insSel),
+		cols:    append(keyColNames, insCols),
+		Offsets: append(keyOffsets, insOffsets),
+	}
+	if ins.Comments != nil {
+		op = &LockAndComment{
+			Source:   op,
+			Comments: ins.Comments,
+		}
+	}
+	return op
+}
+
+// replacedInsert returns the Insert of the operator planned for the rows of a REPLACE.
+func replacedInsert(op Operator) *Insert {
+	if lc, isLC := op.(*LockAndComment); isLC {
+		op = lc.Source
+	}
+	if route, isRoute := op.(*Route); isRoute {
+		if ins, isIns := route.Source.(*Insert); isIns {
+			return ins
+		}
+	}
+	panic(vterrors.VT13001(fmt.Sprintf("unexpected operator for REPLACE: %T", op)))
+}
+
+// replaceKeyValues returns the values of the primary key, of the unique keys and of the unique vindexes
+// for every row of a REPLACE. MySQL inserts the rows one after the other, so a row is replaced by
+// a later row that has the same values for one of the keys.
+// Keys that are not given a value for every row, e.g. a key using a sequence, are skipped.
+func replaceKeyValues(ctx *plancontext.PlanningContext, vTbl *vindexes.Table, ins *sqlparser.Insert, rows sqlparser.Values) (keys [][][]evalengine.Expr) {
+	for _, keyCols := range replaceKeyColumns(vTbl) {
+		offsets := columnOffsets(ins, keyCols)
+		if offsets == nil {
+			continue
+		}
+		key := make([][]evalengine.Expr, len(offsets))
+		for colIdx, offset := range offsets {
+			key[colIdx] = make([]evalengine.Expr, len(rows))
+			for rowNum, row := range rows {
+				expr, err := evalengine.Translate(row[offset], &evalengine.Config{
+					ResolveType: ctx.SemTable.TypeForExpr,
+					Collation:   ctx.SemTable.Collation,
+					Environment: ctx.VSchema.Environment(),
+				})
+				if err != nil {
+					panic(err)
+				}
+				key[colIdx][rowNum] = expr
+			}
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// replaceKeyColumns returns the columns of the primary key, of the unique keys that only use columns
+// and of the unique vindexes of the table.
+func replaceKeyColumns(vTbl *vindexes.Table) (keys [][]sqlparser.IdentifierCI) {
+	addKey := func(keyCols []sqlparser.IdentifierCI) {
+		if !slices.ContainsFunc(keys, func(other []sqlparser.IdentifierCI) bool { return sameColumns(other, keyCols) }) {
+			keys = append(keys, keyCols)
+		}
+	}
+	if len(vTbl.PrimaryKey) > 0 {
+		addKey(vTbl.PrimaryKey)
+	}
+	for _, uniqKey := range vTbl.UniqueKeys {
+		var keyCols []sqlparser.IdentifierCI
+		for _, expr := range uniqKey {
+			col, isCol := expr.(*sqlparser.ColName)
+			if !isCol {
+				keyCols = nil
+				break
+			}
+			keyCols = append(keyCols, col.Name)
+		}
+		if keyCols != nil {
+			addKey(keyCols)
+		}
+	}
+	for _, colVindex := range vTbl.ColumnVindexes {
+		if colVindex.IsUnique() && !colVindex.IsPartialVindex() {
+			addKey(colVindex.Columns)
+		}
+	}
+	return keys
+}
+
+// sameColumns returns true if both keys use the same columns, in any order.
+func sameColumns(a, b []sqlparser.IdentifierCI) bool {
+	return len(a) == len(b) && !slices.ContainsFunc(a, func(col sqlparser.IdentifierCI) bool {
+		return !slices.ContainsFunc(b, col.Equal)
+	})
+}
+
+// uniqueVindexOffsets returns the offsets of the columns of the unique vindexes in the rows of a REPLACE ... SELECT,
+// leaving out the vindexes that use the same columns as one of the keys already used for the delete.
+func uniqueVindexOffsets(vTbl *vindexes.Table, keys [][]sqlparser.IdentifierCI, ins *sqlparser.Insert) (keyOffsets [][]int) {
+	for _, colVindex := range vTbl.ColumnVindexes {
+		if !colVindex.IsUnique() || colVindex.IsPartialVindex() {
+			continue
+		}
+		if slices.ContainsFunc(keys, func(keyCols []sqlparser.IdentifierCI) bool { return sameColumns(keyCols, colVindex.Columns) }) {
+			continue
+		}
+		keys = append(keys, colVindex.Columns)
+		if offsets := columnOffsets(ins, colVindex.Columns); offsets != nil {
+			keyOffsets = append(keyOffsets, offsets)
+		}
+	}
+	return keyOffsets
+}
+
+// columnOffsets returns the offsets of the columns in the insert, or nil if one of them is not inserted.
+func columnOffsets(ins *sqlparser.Insert, cols []sqlparser.IdentifierCI) (offsets []int) {
+	for _, col := range cols {
+		idx := ins.Columns.FindColumn(col)
+		if idx == -1 {
+			return nil
+		}
+		offsets = append(offsets, idx)
+	}
+	return offsets
+}
+
+// replaceKeyOffsets returns the offsets of the key columns in the rows of a REPLACE ... SELECT.
+// It returns nil when none of the inserted rows can clash on the key.
+func replaceKeyOffsets(vTbl *vindexes.Table, ins *sqlparser.Insert, keyCols []sqlparser.IdentifierCI) (offsets []int, colNames []*sqlparser.ColName) {
+	for _, col := range keyCols {
+		idx := ins.Columns.FindColumn(col)
+		if idx == -1 {
+			if vTbl.AutoIncrement != nil && vTbl.AutoIncrement.Column.Equal(col) {
+				// a generated value never clashes with an existing row.
+				return nil, nil
+			}
+			if findDefault(vTbl, col) == nil {
+				// If default value is empty, nothing to compare as it will always be false.
+				return nil, nil
+			}
+			panic(vterrors.VT12001(fmt.Sprintf("REPLACE INTO using select statement without a value for the key column '%s'", col.String())))
+		}
+		offsets = append(offsets, idx)
+		colNames = append(colNames, sqlparser.NewColName(col.String()))
+	}
+	return offsets, colNames
+}
+
 func checkAndCreateInsertOperator(ctx *plancontext.PlanningContext, ins *sqlparser.Insert, vTbl *vindexes.Table, routing Routing) Operator {
 	insOp := createInsertOperator(ctx, ins, vTbl, routing)
 
@@ -159,14 +428,9 @@ func checkAndCreateInsertOperator(ctx *plancontext.PlanningContext, ins *sqlpars
 	if len(parentFKs) > 0 {
 		panic(vterrors.VT12002())
 	}
-	if len(childFks) > 0 {
-		if ins.Action == sqlparser.ReplaceAct {
-			panic(vterrors.VT12001("REPLACE INTO with foreign keys"))
-		}
-		if len(ins.OnDup) > 0 {
-			rows := getRowsOrError(ins)
-			return createUpsertOperator(ctx, ins, insOp, rows, vTbl)
-		}
+	if len(childFks) > 0 && len(ins.OnDup) > 0 {
+		rows := getRowsOrError(ins)
+		return createUpsertOperator(ctx, ins, insOp, rows, vTbl)
 	}
 	return insOp
 }
@@ -201,6 +465,9 @@ func pkCompExpression(vTbl *vindexes.Table, ins *sqlparser.Insert, rows sqlparse
 		return nil
 	}
 	pIndexes, pColTuple := findPKIndexes(vTbl, ins)
+	if len(pColTuple) == 0 {
+		return nil
+	}
 
 	var pValTuple sqlparser.ValTuple
 	for _, row := range rows {
@@ -228,6 +495,10 @@ func findPKIndexes(vTbl *vindexes.Table, ins *sqlparser.Insert) (pIndexes []pCom
 		var def sqlparser.Expr
 		idx := ins.Columns.FindColumn(pCol)
 		if idx == -1 {
+			if vTbl.AutoIncrement != nil && vTbl.AutoIncrement.Column.Equal(pCol) {
+				// a generated value never clashes with an existing row.
+				return nil, nil
+			}
 			def = findDefault(vTbl, pCol)
 			if def == nil {
 				// If default value is empty, nothing to compare as it will always be false.
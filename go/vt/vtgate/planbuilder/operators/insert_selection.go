@@ -22,6 +22,7 @@ import (
 
 // InsertSelection operator represents an INSERT into SELECT FROM query.
 // It holds the operators for running the selection and insertion.
+// Select is nil when the rows come from a DMLWithInput, as for REPLACE ... SELECT.
 type InsertSelection struct {
 	Select Operator
 	Insert Operator
@@ -34,18 +35,23 @@ type InsertSelection struct {
 }
 
 func (is *InsertSelection) Clone(inputs []Operator) Operator {
-	return &InsertSelection{
-		Select:            inputs[0],
-		Insert:            inputs[1],
-		ForceNonStreaming: is.ForceNonStreaming,
-	}
+	newIs := &InsertSelection{ForceNonStreaming: is.ForceNonStreaming}
+	newIs.SetInputs(inputs)
+	return newIs
 }
 
 func (is *InsertSelection) Inputs() []Operator {
+	if is.Select == nil {
+		return []Operator{is.Insert}
+	}
 	return []Operator{is.Select, is.Insert}
 }
 
 func (is *InsertSelection) SetInputs(inputs []Operator) {
+	if len(inputs) == 1 {
+		is.Insert = inputs[0]
+		return
+	}
 	is.Select = inputs[0]
 	is.Insert = inputs[1]
 }
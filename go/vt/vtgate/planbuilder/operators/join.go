@@ -20,6 +20,7 @@ import (
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
 )
 
 // Join represents a join. If we have a predicate, this is an inner join. If no predicate exists, it is a cross join
@@ -106,17 +107,109 @@ func createLeftOuterJoin(ctx *plancontext.PlanningContext, join *sqlparser.JoinT
 	joinOp := &Join{LHS: lhs, RHS: rhs, JoinType: join.Join}
 
 	// for outer joins we have to be careful with the predicates we use
-	var op Operator
-	subq, _ := getSubQuery(join.Condition.On)
-	if subq != nil {
-		panic(vterrors.VT12001("subquery in outer join predicate"))
-	}
 	predicate := join.Condition.On
 	sqlparser.RemoveKeyspaceInCol(predicate)
-	joinOp.Predicate = predicate
-	op = joinOp
+	subq, _ := getSubQuery(predicate)
+	if subq == nil {
+		joinOp.Predicate = predicate
+		return joinOp
+	}
+
+	return addOuterJoinPredicates(ctx, predicate, joinOp)
+}
+
+// addOuterJoinPredicates adds the ON condition of an outer join that uses subqueries.
+// The predicates can't be used as filters on top of the join, since that would remove the NULL-extended rows.
+// Uncorrelated subqueries are evaluated once, before the join, and the join condition uses the arguments the result is
+// passed in. Predicates that only use the tables of the outer side of the join can instead be used to filter the rows
+// of the outer side before the join, so correlated subqueries in them are evaluated once per row of these tables.
+func addOuterJoinPredicates(ctx *plancontext.PlanningContext, predicate sqlparser.Expr, join *Join) Operator {
+	sqc := &SubQueryBuilder{}
+	lhsSqc := &SubQueryBuilder{}
+	rhsSqc := &SubQueryBuilder{}
+	outerID := TableID(join)
+	lhsID := TableID(join.LHS)
+	rhsID := TableID(join.RHS)
+	for _, pred := range sqlparser.SplitAndExpression(nil, predicate) {
+		subq, _ := getSubQuery(pred)
+		switch {
+		case subq == nil:
+			join.AddJoinPredicate(ctx, pred)
+		case ctx.SemTable.RecursiveDeps(subq).IsEmpty():
+			sq := sqc.handleSubquery(ctx, pred, outerID)
+			if sq.usedWithAnyAll() {
+				panic(anyAllSubqueryErr)
+			}
+			if !sq.TopLevel || len(sq.predicateSubqueries) > 0 {
+				panic(subqueryNotAtTopErr)
+			}
+			sq.inOuterJoin = true
+			join.AddJoinPredicate(ctx, sqlparser.AndExpressions(sq.pulloutPredicates(ctx)...))
+		case ctx.SemTable.RecursiveDeps(pred).IsSolvedBy(rhsID):
+			rhsSqc.handleSubquery(ctx, pred, rhsID)
+		case canEvaluateOnOuterSide(ctx, pred, lhsID):
+			newPred, subqs := lhsSqc.pullOutValueSubqueries(ctx, pred, lhsID, false)
+			markSubqueryColumns(ctx, newPred, subqs, lhsID)
+			join.AddJoinPredicate(ctx, newPred)
+		default:
+			panic(vterrors.VT12001("correlated subquery in outer join predicate using columns from the left side of the join"))
+		}
+	}
+	if len(lhsSqc.Inner) > 0 {
+		// the values of the subqueries are projected as columns of the outer side, so the join can pass them
+		// to the inner side like any other column, and the outer rows without a match are still returned
+		proj := newAliasedProjection(lhsSqc.getRootOperator(join.LHS, nil))
+		for _, sq := range lhsSqc.Inner {
+			col := sqlparser.NewColName(sq.ArgName)
+			markSubqueryColumns(ctx, col, lhsSqc.Inner, lhsID)
+			proj.addSubqueryExpr(aeWrap(col), col, sq)
+		}
+		join.LHS = proj
+	}
+	join.RHS = rhsSqc.getRootOperator(join.RHS, nil)
+	return sqc.getRootOperator(join, nil)
+}
+
+// canEvaluateOnOuterSide returns true if the subqueries of the predicate only use columns from the outer side of the
+// join, so their values can be evaluated once per outer row, before the join. The comparison of a correlated [NOT] IN
+// is evaluated together with the subquery, so the other side of it has to come from the outer side as well.
+func canEvaluateOnOuterSide(ctx *plancontext.PlanningContext, pred sqlparser.Expr, lhsID semantics.TableSet) bool {
+	ok := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.ComparisonExpr:
+			subq, isSubq := node.Right.(*sqlparser.Subquery)
+			if isSubq && (node.Operator == sqlparser.InOp || node.Operator == sqlparser.NotInOp) &&
+				!ctx.SemTable.RecursiveDeps(subq).IsEmpty() && !ctx.SemTable.RecursiveDeps(node.Left).IsSolvedBy(lhsID) {
+				ok = false
+			}
+		case *sqlparser.Subquery:
+			if !ctx.SemTable.RecursiveDeps(node).IsSolvedBy(lhsID) {
+				ok = false
+			}
+			return false, nil
+		}
+		return ok, nil
+	}, pred)
+	return ok
+}
 
-	return op
+// markSubqueryColumns sets the dependencies of the columns that replaced the subqueries in the predicate,
+// so the join reads their values from the outer side
+func markSubqueryColumns(ctx *plancontext.PlanningContext, expr sqlparser.Expr, subqs []*SubQuery, lhsID semantics.TableSet) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		col, ok := node.(*sqlparser.ColName)
+		if !ok {
+			return true, nil
+		}
+		for _, sq := range subqs {
+			if sq.isValueColumn(col) {
+				ctx.SemTable.Recursive[col] = lhsID
+				ctx.SemTable.Direct[col] = lhsID
+			}
+		}
+		return true, nil
+	}, expr)
 }
 
 func createInnerJoin(ctx *plancontext.PlanningContext, tableExpr *sqlparser.JoinTableExpr, lhs, rhs Operator) Operator {
@@ -46,6 +46,11 @@ func AddPredicate(
 ) Operator {
 	deps := ctx.SemTable.RecursiveDeps(expr)
 	switch {
+	case joinPredicates && IsOuter(join) && deps.IsSolvedBy(TableID(join.GetLHS())):
+		// the ON condition of an outer join can't remove rows from the outer side,
+		// so predicates that only depend on the lhs can only be evaluated as part of the join
+		join.AddJoinPredicate(ctx, expr)
+		return join
 	case deps.IsSolvedBy(TableID(join.GetLHS())):
 		// predicates can always safely be pushed down to the lhs if that is all they depend on
 		lhs := join.GetLHS().AddPredicate(ctx, expr)
@@ -82,6 +87,10 @@ func AddPredicate(
 			return newFilter(join, expr)
 		}
 
+		if pushCTEVarsPredicate(ctx, join, expr) {
+			return join
+		}
+
 		join.AddJoinPredicate(ctx, expr)
 
 		return join
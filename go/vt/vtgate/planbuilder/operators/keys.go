@@ -0,0 +1,228 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+type (
+	// Column is a column of a table in the vschema
+	Column struct {
+		Table string
+		Name  string
+	}
+
+	// ColumnUse is a column compared using the given operator,
+	// which is either a comparison operator or `is null`/`is not null`
+	ColumnUse struct {
+		Column Column
+		Uses   string
+	}
+
+	// VExplainKeys is the output of VEXPLAIN KEYS. It lists the columns
+	// that a query uses for filtering, joining, grouping and ordering.
+	VExplainKeys struct {
+		StatementType   string      `json:"statementType"`
+		GroupingColumns []Column    `json:"groupingColumns,omitempty"`
+		OrderingColumns []Column    `json:"orderingColumns,omitempty"`
+		JoinColumns     []ColumnUse `json:"joinColumns,omitempty"`
+		FilterColumns   []ColumnUse `json:"filterColumns,omitempty"`
+	}
+)
+
+func (c Column) String() string {
+	return fmt.Sprintf("%s.%s", c.Table, c.Name)
+}
+
+// MarshalJSON serializes the column as `table.column`
+func (c Column) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+func (cu ColumnUse) String() string {
+	return fmt.Sprintf("%s %s", cu.Column, cu.Uses)
+}
+
+// MarshalJSON serializes the column use as `table.column operator`
+func (cu ColumnUse) MarshalJSON() ([]byte, error) {
+	return json.Marshal(cu.String())
+}
+
+// GetVExplainKeys walks the statement and collects the vschema table columns
+// used in WHERE and ON predicates, GROUP BY and ORDER BY.
+// Comparisons between columns of two different tables are reported as join columns.
+func GetVExplainKeys(ctx *plancontext.PlanningContext, stmt sqlparser.Statement) VExplainKeys {
+	var groupingColumns, orderingColumns []Column
+	var filterColumns, joinColumns []ColumnUse
+
+	addColumnUse := func(output *[]ColumnUse, expr sqlparser.Expr, uses string) {
+		col, ok := expr.(*sqlparser.ColName)
+		if !ok {
+			return
+		}
+		if column := createColumn(ctx, col); column != nil {
+			*output = append(*output, ColumnUse{Column: *column, Uses: uses})
+		}
+	}
+
+	addPredicate := func(predicate sqlparser.Expr) {
+		for _, expr := range sqlparser.SplitAndExpression(nil, predicate) {
+			switch expr := expr.(type) {
+			case *sqlparser.ComparisonExpr:
+				lhs, lhsOK := expr.Left.(*sqlparser.ColName)
+				rhs, rhsOK := expr.Right.(*sqlparser.ColName)
+
+				output := &filterColumns
+				if lhsOK && rhsOK && ctx.SemTable.DirectDeps(lhs) != ctx.SemTable.DirectDeps(rhs) {
+					output = &joinColumns
+				}
+				addColumnUse(output, expr.Left, expr.Operator.ToString())
+				if switched, ok := expr.Operator.SwitchSides(); rhsOK && ok {
+					addColumnUse(output, expr.Right, switched.ToString())
+				}
+			case *sqlparser.BetweenExpr:
+				// `col BETWEEN a AND b` is `col >= a AND col <= b`, and the negation is `col < a OR col > b`
+				if expr.IsBetween {
+					addColumnUse(&filterColumns, expr.Left, sqlparser.GreaterEqualStr)
+					addColumnUse(&filterColumns, expr.Left, sqlparser.LessEqualStr)
+				} else {
+					addColumnUse(&filterColumns, expr.Left, sqlparser.LessThanStr)
+					addColumnUse(&filterColumns, expr.Left, sqlparser.GreaterThanStr)
+				}
+			case *sqlparser.IsExpr:
+				if expr.Right == sqlparser.IsNullOp || expr.Right == sqlparser.IsNotNullOp {
+					addColumnUse(&filterColumns, expr.Left, expr.Right.ToString())
+				}
+			}
+		}
+	}
+
+	addColumns := func(output *[]Column, expr sqlparser.Expr) {
+		col, ok := expr.(*sqlparser.ColName)
+		if !ok {
+			return
+		}
+		if column := createColumn(ctx, col); column != nil {
+			*output = append(*output, *column)
+		}
+	}
+
+	_ = sqlparser.VisitSQLNode(stmt, func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Where:
+			addPredicate(node.Expr)
+		case *sqlparser.JoinCondition:
+			if node.On != nil {
+				addPredicate(node.On)
+			}
+		case *sqlparser.GroupBy:
+			for _, expr := range node.Exprs {
+				addColumns(&groupingColumns, expr)
+			}
+		case sqlparser.OrderBy:
+			for _, order := range node {
+				addColumns(&orderingColumns, order.Expr)
+			}
+		}
+		return true, nil
+	})
+
+	return VExplainKeys{
+		StatementType:   sqlparser.ASTToStatementType(stmt).String(),
+		GroupingColumns: uniqueColumns(groupingColumns),
+		OrderingColumns: uniqueColumns(orderingColumns),
+		JoinColumns:     uniqueColumnUses(joinColumns),
+		FilterColumns:   uniqueColumnUses(filterColumns),
+	}
+}
+
+// createColumn returns the vschema table column the ColName points to,
+// or nil if it does not point to a column of a single vschema table.
+// Columns of derived tables and CTEs are followed to the expression that defines them.
+func createColumn(ctx *plancontext.PlanningContext, col *sqlparser.ColName) *Column {
+	for {
+		tableInfo, err := ctx.SemTable.TableInfoForExpr(col)
+		if err != nil {
+			return nil
+		}
+
+		var expr sqlparser.Expr
+		switch tableInfo := tableInfo.(type) {
+		case *semantics.DerivedTable:
+			expr = semantics.RewriteDerivedTableExpression(col, tableInfo)
+		case *semantics.CTETable:
+			expr = recursiveCTEColumn(tableInfo.CTE, col.Name)
+		default:
+			table := tableInfo.GetVindexTable()
+			if table == nil {
+				return nil
+			}
+			return &Column{
+				Table: table.Name.String(),
+				Name:  col.Name.Lowered(),
+			}
+		}
+
+		next, ok := expr.(*sqlparser.ColName)
+		if !ok {
+			return nil
+		}
+		col = next
+	}
+}
+
+// recursiveCTEColumn returns the expression of the seed of the CTE that defines the given column
+func recursiveCTEColumn(cte *semantics.RecursiveCTE, name sqlparser.IdentifierCI) sqlparser.Expr {
+	idx := slices.IndexFunc(cte.Columns(), name.EqualString)
+	if idx < 0 {
+		return nil
+	}
+	seed := sqlparser.GetFirstSelect(cte.Seed())
+	if seed == nil || idx >= len(seed.SelectExprs) {
+		return nil
+	}
+	ae, ok := seed.SelectExprs[idx].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil
+	}
+	return ae.Expr
+}
+
+func uniqueColumns(columns []Column) []Column {
+	slices.SortFunc(columns, func(a, b Column) int {
+		return strings.Compare(a.String(), b.String())
+	})
+	return slices.Compact(columns)
+}
+
+func uniqueColumnUses(columns []ColumnUse) []ColumnUse {
+	slices.SortFunc(columns, func(a, b ColumnUse) int {
+		if c := strings.Compare(a.Column.String(), b.Column.String()); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Uses, b.Uses)
+	})
+	return slices.Compact(columns)
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/test/vschemawrapper"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vschemapb "vitess.io/vitess/go/vt/proto/vschema"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+func TestGetVExplainKeys(t *testing.T) {
+	parser := sqlparser.NewTestParser()
+	vschema := vindexes.BuildVSchema(&vschemapb.SrvVSchema{
+		Keyspaces: map[string]*vschemapb.Keyspace{
+			"main": {
+				Sharded: true,
+				Vindexes: map[string]*vschemapb.Vindex{
+					"hash": {Type: "hash"},
+				},
+				Tables: map[string]*vschemapb.Table{
+					"user": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}},
+					},
+					"music": {
+						ColumnVindexes: []*vschemapb.ColumnVindex{{Column: "id", Name: "hash"}},
+					},
+				},
+			},
+		},
+	}, parser)
+	ks := vschema.Keyspaces["main"].Keyspace
+	wrapper := &vschemawrapper.VSchemaWrapper{
+		V:        vschema,
+		Keyspace: ks,
+		Env:      vtenv.NewTestEnv(),
+	}
+
+	tests := []struct {
+		query    string
+		expected string
+	}{{
+		query:    "select * from user where id = 1 and name > 'a'",
+		expected: `{"statementType":"SELECT","filterColumns":["user.id =","user.name >"]}`,
+	}, {
+		query:    "select u.name, count(*) from user u join music m on u.id = m.user_id where 10 < m.year group by u.name order by u.name, m.year",
+		expected: `{"statementType":"SELECT","groupingColumns":["user.name"],"orderingColumns":["music.year","user.name"],"joinColumns":["music.user_id =","user.id ="],"filterColumns":["music.year >"]}`,
+	}, {
+		query:    "update user set name = 'x' where id in (1, 2) and name like 'a%'",
+		expected: `{"statementType":"UPDATE","filterColumns":["user.id in","user.name like"]}`,
+	}, {
+		query:    "select 1 from dual",
+		expected: `{"statementType":"SELECT"}`,
+	}}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tt.query)
+			require.NoError(t, err)
+			ctx, err := plancontext.CreatePlanningContext(stmt, sqlparser.NewReservedVars("", sqlparser.BindVars{}), wrapper, querypb.ExecuteOptions_Gen4)
+			require.NoError(t, err)
+
+			out, err := json.Marshal(GetVExplainKeys(ctx, stmt))
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, string(out))
+		})
+	}
+}
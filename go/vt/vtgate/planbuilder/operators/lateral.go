@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"io"
+	"slices"
+
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+// lateralJoinMerger is used when merging a LATERAL derived table with the tables it uses.
+// The routing of the derived table can depend on the arguments coming from the other side,
+// so it can't be used as the routing of the merged route.
+type lateralJoinMerger struct {
+	joinMerger
+	vars []BindVarExpr
+}
+
+var _ merger = (*lateralJoinMerger)(nil)
+
+// replaceLateralColumns replaces the columns of the outer tables used inside a LATERAL derived table with arguments.
+// This way the derived table can be planned on its own. The columns the arguments stand in for are returned,
+// so they can be fed to the derived table from the left hand side of the join.
+func replaceLateralColumns(ctx *plancontext.PlanningContext, tbl *sqlparser.DerivedTable) []BindVarExpr {
+	inner := findTablesContained(ctx, tbl.Select)
+	var vars []BindVarExpr
+	_ = sqlparser.Rewrite(tbl.Select, nil, func(cursor *sqlparser.Cursor) bool {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok {
+			return true
+		}
+		deps := ctx.SemTable.RecursiveDeps(col)
+		if deps.IsEmpty() || deps.IsSolvedBy(inner) {
+			return true
+		}
+		name := ctx.GetReservedArgumentFor(col)
+		if !slices.ContainsFunc(vars, func(bve BindVarExpr) bool { return bve.Name == name }) {
+			vars = append(vars, BindVarExpr{Name: name, Expr: col})
+		}
+		typ, found := ctx.SemTable.TypeForExpr(col)
+		if !found {
+			cursor.Replace(sqlparser.NewArgument(name))
+			return true
+		}
+		cursor.Replace(sqlparser.NewTypedArgument(name, typ.Type()))
+		return true
+	})
+	if len(vars) == 0 {
+		return nil
+	}
+
+	// the expressions inside the derived table no longer depend on the outer tables
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		expr, ok := node.(sqlparser.Expr)
+		if !ok || !semantics.ValidAsMapKey(expr) {
+			return true, nil
+		}
+		if deps, found := ctx.SemTable.Recursive[expr]; found {
+			ctx.SemTable.Recursive[expr] = deps.KeepOnly(inner)
+		}
+		if deps, found := ctx.SemTable.Direct[expr]; found {
+			ctx.SemTable.Direct[expr] = deps.KeepOnly(inner)
+		}
+		return true, nil
+	}, tbl.Select)
+
+	return vars
+}
+
+// lateralVarsFor returns the columns of lhs that are needed by LATERAL derived tables in rhs
+func lateralVarsFor(ctx *plancontext.PlanningContext, lhs, rhs Operator) (vars []BindVarExpr) {
+	lhsID := TableID(lhs)
+	_ = Visit(rhs, func(op Operator) error {
+		horizon, ok := op.(*Horizon)
+		if !ok {
+			return nil
+		}
+		for _, bve := range horizon.LateralVars {
+			if !ctx.SemTable.RecursiveDeps(bve.Expr).IsSolvedBy(lhsID) {
+				continue
+			}
+			if slices.ContainsFunc(vars, func(other BindVarExpr) bool { return other.Name == bve.Name }) {
+				continue
+			}
+			vars = append(vars, bve)
+		}
+		return nil
+	})
+	return vars
+}
+
+// mergeOrJoinLateral plans the join between a LATERAL derived table and the tables to the left of it.
+// If both sides can be sent to the same shards, they are merged into a single route.
+// Otherwise, the derived table is evaluated once for every row on the left hand side, using an apply join
+// that passes the columns the derived table needs as bind variables.
+func mergeOrJoinLateral(
+	ctx *plancontext.PlanningContext,
+	lhs, rhs Operator,
+	joinPredicates []sqlparser.Expr,
+	joinType sqlparser.JoinType,
+	vars []BindVarExpr,
+) (Operator, *ApplyResult) {
+	m := &lateralJoinMerger{
+		joinMerger: joinMerger{predicates: joinPredicates, joinType: joinType},
+		vars:       vars,
+	}
+	mergePredicates := append(slices.Clone(joinPredicates), lateralJoinPredicates(rhs, vars)...)
+	newPlan := mergeJoinInputs(ctx, lhs, rhs, mergePredicates, m)
+	if newPlan != nil {
+		return newPlan, Rewrote("merge lateral derived table into the route of the tables it uses")
+	}
+
+	// the lateral derived table has to stay on the RHS, so we don't try switching sides here
+	join := NewApplyJoin(ctx, Clone(lhs), Clone(rhs), nil, joinType)
+	join.ExtraLHSVars = append(join.ExtraLHSVars, vars...)
+	newOp := pushJoinPredicates(ctx, joinPredicates, join)
+	return newOp, Rewrote("logical join to applyJoin for lateral derived table")
+}
+
+// lateralJoinPredicates finds the equality comparisons between the columns of LATERAL derived tables and
+// the columns of the outer tables they use. They are only used to find out if the two sides can be merged.
+func lateralJoinPredicates(rhs Operator, vars []BindVarExpr) (predicates []sqlparser.Expr) {
+	_ = Visit(rhs, func(op Operator) error {
+		horizon, ok := op.(*Horizon)
+		if !ok || len(horizon.LateralVars) == 0 {
+			return nil
+		}
+		sel, ok := horizon.Query.(*sqlparser.Select)
+		if !ok || sel.Where == nil {
+			return nil
+		}
+		for _, pred := range sqlparser.SplitAndExpression(nil, sel.Where.Expr) {
+			cmp, ok := pred.(*sqlparser.ComparisonExpr)
+			if !ok || cmp.Operator != sqlparser.EqualOp {
+				continue
+			}
+			left, right := cmp.Left, cmp.Right
+			if _, isArg := left.(*sqlparser.Argument); isArg {
+				left, right = right, left
+			}
+			col, isCol := left.(*sqlparser.ColName)
+			arg, isArg := right.(*sqlparser.Argument)
+			if !isCol || !isArg {
+				continue
+			}
+			idx := slices.IndexFunc(vars, func(bve BindVarExpr) bool { return bve.Name == arg.Name })
+			if idx < 0 {
+				continue
+			}
+			predicates = append(predicates, sqlparser.NewComparisonExpr(sqlparser.EqualOp, col, vars[idx].Expr, nil))
+		}
+		return nil
+	})
+	return predicates
+}
+
+// usesLateralVars returns true if the expression uses any of the arguments standing in for lateral columns
+func usesLateralVars(expr sqlparser.Expr, vars []BindVarExpr) (found bool) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		arg, ok := node.(*sqlparser.Argument)
+		if ok && slices.ContainsFunc(vars, func(bve BindVarExpr) bool { return bve.Name == arg.Name }) {
+			found = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, expr)
+	return found
+}
+
+func (lm *lateralJoinMerger) mergeShardedRouting(ctx *plancontext.PlanningContext, r1, _ *ShardedRouting, op1, op2 *Route) *Route {
+	// the routes can only be merged if the derived table is found on the same shard as the rows it uses,
+	// so the routing of the outer side is all we need
+	return lm.merge(ctx, op1, op2, r1)
+}
+
+func (lm *lateralJoinMerger) merge(ctx *plancontext.PlanningContext, op1, op2 *Route, r Routing) *Route {
+	if sr, ok := r.(*ShardedRouting); ok {
+		for _, expr := range sr.VindexExpressions() {
+			if usesLateralVars(expr, lm.vars) {
+				// the values come from the other side of the join, so the merged route would not be able to use them
+				return nil
+			}
+		}
+	}
+	return lm.joinMerger.merge(ctx, op1, op2, r)
+}
@@ -61,6 +61,9 @@ func mustFetchFromInput(ctx *plancontext.PlanningContext, e sqlparser.SQLNode) b
 	switch fun := e.(type) {
 	case *sqlparser.ColName, sqlparser.AggrFunc:
 		return true
+	case *sqlparser.ArgumentLessWindowExpr, *sqlparser.NtileExpr, *sqlparser.LagLeadExpr,
+		*sqlparser.FirstOrLastValueExpr, *sqlparser.NTHValueExpr:
+		return true
 	case *sqlparser.FuncExpr:
 		return fun.Name.EqualsAnyString(ctx.VSchema.GetAggregateUDFs())
 	default:
@@ -92,6 +92,9 @@ func (o *Ordering) planOffsets(ctx *plancontext.PlanningContext) Operator {
 		}
 
 		wsExpr := &sqlparser.WeightStringFuncExpr{Expr: order.SimplifiedExpr}
+		if !canPushToRecurseCTE(ctx, o.Source, wsExpr) {
+			o.Source = projectRecurseCTE(ctx, o.Source)
+		}
 		offset = o.Source.AddColumn(ctx, true, false, aeWrap(wsExpr))
 		o.WOffset = append(o.WOffset, offset)
 	}
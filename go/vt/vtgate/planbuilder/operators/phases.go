@@ -37,6 +37,7 @@ const (
 	pullDistinctFromUnion
 	delegateAggregation
 	addAggrOrdering
+	addWindowOrdering
 	cleanOutPerfDistinct
 	dmlWithInput
 	subquerySettling
@@ -55,6 +56,8 @@ func (p Phase) String() string {
 		return "split aggregation between vtgate and mysql"
 	case addAggrOrdering:
 		return "optimize aggregations with ORDER BY"
+	case addWindowOrdering:
+		return "add ORDER BY for window functions"
 	case cleanOutPerfDistinct:
 		return "optimize Distinct operations"
 	case subquerySettling:
@@ -74,6 +77,8 @@ func (p Phase) shouldRun(s semantics.QuerySignature) bool {
 		return s.Aggregation
 	case addAggrOrdering:
 		return s.Aggregation
+	case addWindowOrdering:
+		return s.Window
 	case cleanOutPerfDistinct:
 		return s.Distinct
 	case subquerySettling:
@@ -93,6 +98,8 @@ func (p Phase) act(ctx *plancontext.PlanningContext, op Operator) Operator {
 		return enableDelegateAggregation(ctx, op)
 	case addAggrOrdering:
 		return addOrderingForAllAggregations(ctx, op)
+	case addWindowOrdering:
+		return addOrderingForAllWindows(ctx, op)
 	case cleanOutPerfDistinct:
 		return removePerformanceDistinctAboveRoute(ctx, op)
 	case subquerySettling:
@@ -270,6 +277,50 @@ func needsOrdering(ctx *plancontext.PlanningContext, in *Aggregator) bool {
 	return false
 }
 
+// addOrderingForAllWindows sorts the input of the windows that are evaluated at the vtgate level,
+// so that the rows of every partition, and the peers inside them, arrive next to each other
+func addOrderingForAllWindows(ctx *plancontext.PlanningContext, root Operator) Operator {
+	visitor := func(in Operator, _ semantics.TableSet, isRoot bool) (Operator, *ApplyResult) {
+		window, ok := in.(*Window)
+		if !ok || window.Pushed {
+			return in, NoRewrite
+		}
+
+		orderBys := slice.Map(window.PartitionBy, func(expr sqlparser.Expr) OrderBy {
+			return NewGroupBy(expr).AsOrderBy()
+		})
+		orderBys = append(orderBys, window.OrderBy...)
+		if !needsOrderingForWindow(ctx, window.Source, orderBys) {
+			return in, NoRewrite
+		}
+
+		window.Source = &Ordering{
+			Source: window.Source,
+			Order:  orderBys,
+		}
+		return in, Rewrote("added ordering before window")
+	}
+
+	return BottomUp(root, TableID, visitor, stopAtRoute)
+}
+
+func needsOrderingForWindow(ctx *plancontext.PlanningContext, src Operator, required []OrderBy) bool {
+	if len(required) == 0 {
+		return false
+	}
+	srcOrdering := src.GetOrdering(ctx)
+	if len(srcOrdering) < len(required) {
+		return true
+	}
+	for idx, order := range required {
+		if srcOrdering[idx].Inner == nil || srcOrdering[idx].Inner.Direction != order.Inner.Direction ||
+			!ctx.SemTable.EqualsExprWithDeps(srcOrdering[idx].SimplifiedExpr, order.SimplifiedExpr) {
+			return true
+		}
+	}
+	return false
+}
+
 func addGroupByOnRHSOfJoin(root Operator) Operator {
 	visitor := func(in Operator, _ semantics.TableSet, isRoot bool) (Operator, *ApplyResult) {
 		join, ok := in.(*ApplyJoin)
@@ -284,16 +335,24 @@ func addGroupByOnRHSOfJoin(root Operator) Operator {
 }
 
 func addLiteralGroupingToRHS(in *ApplyJoin) (Operator, *ApplyResult) {
-	_ = Visit(in.RHS, func(op Operator) error {
-		aggr, isAggr := op.(*Aggregator)
-		if !isAggr {
-			return nil
-		}
-		if len(aggr.Grouping) == 0 {
+	addLiteralGrouping(in.RHS)
+	return in, NoRewrite
+}
+
+func addLiteralGrouping(op Operator) {
+	switch op := op.(type) {
+	case *Aggregator:
+		if len(op.Grouping) == 0 {
 			gb := sqlparser.NewIntLiteral(".0")
-			aggr.Grouping = append(aggr.Grouping, NewGroupBy(gb))
+			op.Grouping = append(op.Grouping, NewGroupBy(gb))
 		}
-		return nil
-	})
-	return in, NoRewrite
+	case *SubQuery:
+		// the aggregations of the subquery are over the rows it finds for each outer row,
+		// so a scalar aggregation still has to return a row when it finds none
+		addLiteralGrouping(op.Outer)
+		return
+	}
+	for _, input := range op.Inputs() {
+		addLiteralGrouping(input)
+	}
 }
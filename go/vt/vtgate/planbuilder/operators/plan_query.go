@@ -147,6 +147,10 @@ func tryTruncateColumnsAt(op Operator, truncateAt int) bool {
 	case *Limit:
 		return tryTruncateColumnsAt(op.Source, truncateAt)
 	case *SubQuery:
+		if op.projectsValue() {
+			// the first column is the value of the subquery, the rest come from the outer side
+			truncateAt--
+		}
 		for _, offset := range op.Vars {
 			if offset >= truncateAt {
 				return false
@@ -291,6 +291,26 @@ func (p *Projection) addSubqueryExpr(ae *sqlparser.AliasedExpr, expr sqlparser.E
 	_ = p.addProjExpr(pe)
 }
 
+// isSubqueryValue returns true if the expression is a column that the projection evaluates using subqueries,
+// so the value is not available below the projection
+func (p *Projection) isSubqueryValue(ctx *plancontext.PlanningContext, expr sqlparser.Expr) bool {
+	ap, err := p.GetAliasedProjections()
+	if err != nil {
+		return false
+	}
+	for _, pe := range ap {
+		if _, isSubq := pe.Info.(SubQueryExpression); isSubq && ctx.SemTable.EqualsExprWithDeps(pe.ColExpr, expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Projection) isStarProjection() bool {
+	_, isStar := p.Columns.(StarProjections)
+	return isStar
+}
+
 func (p *Projection) addColumnWithoutPushing(ctx *plancontext.PlanningContext, expr *sqlparser.AliasedExpr, _ bool) int {
 	return p.addColumn(ctx, true, false, expr, false)
 }
@@ -359,6 +379,7 @@ func (p *Projection) addColumn(
 	}
 
 	// ok, we need to add the expression. let's check if we should rewrite a ws expression first
+	var subqs SubQueryExpression
 	ws, ok := expr.(*sqlparser.WeightStringFuncExpr)
 	if ok {
 		cols, ok := p.Columns.(AliasedProjections)
@@ -370,14 +391,26 @@ func (p *Projection) addColumn(
 				// if someone is asking for the ws of something we are projecting,
 				// we need push down the ws of the eval expression
 				ws.Expr = projExpr.EvalExpr
+				subqs, _ = projExpr.Info.(SubQueryExpression)
 			}
 		}
 	}
 
 	pe := newProjExprWithInner(ae, expr)
-	if !push {
+	if subqs != nil {
+		// the value comes from subqueries evaluated by this projection,
+		// so the weight_string has to be evaluated here as well
+		pe.Info = subqs
 		return p.addProjExpr(pe)
 	}
+	if !push || !canPushToRecurseCTE(ctx, p.Source, expr) {
+		return p.addProjExpr(pe)
+	}
+
+	if expr != ae.Expr {
+		// the input only knows the expression as it looks below the derived table
+		ae = &sqlparser.AliasedExpr{Expr: expr, As: ae.As}
+	}
 
 	// we need to push down this column to our input
 	inputOffset := p.Source.AddColumn(ctx, true, addToGroupBy, ae)
@@ -130,6 +130,16 @@ func pushProjectionToOuter(ctx *plancontext.PlanningContext, p *Projection, sq *
 		return p, NoRewrite
 	}
 
+	if sq.projectsValue() {
+		// the value of the subquery is only known once the subquery has been evaluated for the outer row
+		return p, NoRewrite
+	}
+
+	if p.isDerived() && len(sq.Predicates) > 0 {
+		// the subquery uses columns of the outer query that the derived table doesn't expose
+		return p, NoRewrite
+	}
+
 	outer := TableID(sq.Outer)
 	for _, pe := range ap {
 		_, isOffset := pe.Info.(Offset)
@@ -172,6 +182,11 @@ func pushProjectionToOuterContainer(ctx *plancontext.PlanningContext, p *Project
 		return p, NoRewrite
 	}
 
+	if p.isDerived() && slices.ContainsFunc(src.Inner, func(sq *SubQuery) bool { return len(sq.Predicates) > 0 }) {
+		// the subqueries use columns of the outer query that the derived table doesn't expose
+		return p, NoRewrite
+	}
+
 	outer := TableID(src.Outer)
 	for _, pe := range ap {
 		_, isOffset := pe.Info.(Offset)
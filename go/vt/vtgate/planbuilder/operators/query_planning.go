@@ -19,6 +19,7 @@ package operators
 import (
 	"fmt"
 	"io"
+	"slices"
 
 	"vitess.io/vitess/go/vt/vtgate/engine"
 
@@ -87,6 +88,8 @@ func runRewriters(ctx *plancontext.PlanningContext, root Operator) Operator {
 			return tryPushOrdering(ctx, in)
 		case *Aggregator:
 			return tryPushAggregator(ctx, in)
+		case *Window:
+			return tryPushWindow(ctx, in)
 		case *Filter:
 			return tryPushFilter(ctx, in)
 		case *Distinct:
@@ -211,7 +214,8 @@ func pushOrExpandHorizon(ctx *plancontext.PlanningContext, in *Horizon) (Operato
 		!needsOrdering &&
 		!qp.NeedsAggregation() &&
 		!in.selectStatement().IsDistinct() &&
-		in.selectStatement().GetLimit() == nil
+		in.selectStatement().GetLimit() == nil &&
+		(!qp.HasWindow || isSel && windowsPartitionedByUniqueVindex(ctx, sel))
 
 	if canPush {
 		return Swap(in, rb, "push horizon into route")
@@ -220,6 +224,32 @@ func pushOrExpandHorizon(ctx *plancontext.PlanningContext, in *Horizon) (Operato
 	return expandHorizon(ctx, in)
 }
 
+// windowsPartitionedByUniqueVindex returns true if all the window functions of the query
+// are partitioned by a unique vindex column, so every partition lives in a single shard
+func windowsPartitionedByUniqueVindex(ctx *plancontext.PlanningContext, sel *sqlparser.Select) bool {
+	hasUniqueVindex := func(expr sqlparser.Expr) bool {
+		return exprHasUniqueVindex(ctx, expr)
+	}
+	return windowsPartitionedBy(sel.SelectExprs, hasUniqueVindex) && windowsPartitionedBy(sel.OrderBy, hasUniqueVindex)
+}
+
+func tryPushWindow(ctx *plancontext.PlanningContext, in *Window) (Operator, *ApplyResult) {
+	src, ok := in.Source.(*Route)
+	if !ok || in.Pushed {
+		return in, NoRewrite
+	}
+
+	if !src.IsSingleShard() && !slices.ContainsFunc(in.PartitionBy, func(expr sqlparser.Expr) bool {
+		return exprHasUniqueVindex(ctx, expr)
+	}) {
+		// the partitions are spread over multiple shards, so the window has to be evaluated at the vtgate level
+		return in, NoRewrite
+	}
+
+	in.Pushed = true
+	return Swap(in, src, "push window under route")
+}
+
 func tryPushLimit(ctx *plancontext.PlanningContext, in *Limit) (Operator, *ApplyResult) {
 	switch src := in.Source.(type) {
 	case *Route:
@@ -326,9 +356,18 @@ func setUpperLimit(in *Limit) (Operator, *ApplyResult) {
 	var result *ApplyResult
 	shouldVisit := func(op Operator) VisitRule {
 		switch op := op.(type) {
-		case *Join, *ApplyJoin, *SubQueryContainer, *SubQuery:
+		case *Join, *ApplyJoin, *SubQueryContainer, *SubQuery, *RecurseCTE:
 			// we can't push limits down on either side
 			return SkipChildren
+		case *Window:
+			// the window functions need to see all the rows of their partitions
+			return SkipChildren
+		case *Aggregator:
+			if op.WithRollup {
+				// the super-aggregate rows are calculated using all the rows of the input
+				return SkipChildren
+			}
+			return VisitChildren
 		case *Route:
 			newSrc := &Limit{
 				Source: op.Source,
@@ -367,18 +406,30 @@ func tryPushOrdering(ctx *plancontext.PlanningContext, in *Ordering) (Operator,
 	case *Projection:
 		// we can move ordering under a projection if it's not introducing a column we're sorting by
 		for _, by := range in.Order {
-			if !mustFetchFromInput(ctx, by.SimplifiedExpr) {
+			if !mustFetchFromInput(ctx, by.SimplifiedExpr) || src.isSubqueryValue(ctx, by.SimplifiedExpr) {
+				return in, NoRewrite
+			}
+			if src.isDerived() && ctx.SemTable.DirectDeps(by.SimplifiedExpr).IsOverlapping(src.DT.TableID) {
+				// the columns of the derived table don't exist below it
 				return in, NoRewrite
 			}
 		}
 		return Swap(in, src, "push ordering under projection")
 	case *Aggregator:
+		if src.WithRollup {
+			// the super-aggregate rows are produced by the aggregator, so they have to be sorted after it.
+			// the order of the GROUP BY columns also changes the result, so we can't align them with the ORDER BY
+			return in, NoRewrite
+		}
 		if !src.QP.AlignGroupByAndOrderBy(ctx) && !overlaps(ctx, in.Order, src.Grouping) {
 			return in, NoRewrite
 		}
 
 		return pushOrderingUnderAggr(ctx, in, src)
 	case *SubQueryContainer:
+		if orderingUsesSubqueries(ctx, in.Order, src.Inner) {
+			return in, NoRewrite
+		}
 		outerTableID := TableID(src.Outer)
 		for _, order := range in.Order {
 			deps := ctx.SemTable.RecursiveDeps(order.Inner.Expr)
@@ -389,6 +440,9 @@ func tryPushOrdering(ctx *plancontext.PlanningContext, in *Ordering) (Operator,
 		src.Outer, in.Source = in, src.Outer
 		return src, Rewrote("push ordering into outer side of subquery")
 	case *SubQuery:
+		if orderingUsesSubqueries(ctx, in.Order, []*SubQuery{src}) {
+			return in, NoRewrite
+		}
 		outerTableID := TableID(src.Outer)
 		for _, order := range in.Order {
 			deps := ctx.SemTable.RecursiveDeps(order.Inner.Expr)
@@ -402,6 +456,29 @@ func tryPushOrdering(ctx *plancontext.PlanningContext, in *Ordering) (Operator,
 	return in, NoRewrite
 }
 
+// orderingUsesSubqueries returns true if any of the order expressions uses the value of one of the subqueries,
+// which means the ordering can't be pushed under the operator evaluating them
+func orderingUsesSubqueries(ctx *plancontext.PlanningContext, order []OrderBy, subqueries []*SubQuery) bool {
+	for _, by := range order {
+		found := false
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			for _, sq := range subqueries {
+				switch node := node.(type) {
+				case *sqlparser.ColName:
+					found = found || (node.Qualifier.IsEmpty() && node.Name.EqualString(sq.ArgName))
+				case *sqlparser.Subquery:
+					found = found || ctx.SemTable.EqualsExprWithDeps(node, sq.originalSubquery)
+				}
+			}
+			return !found, nil
+		}, by.SimplifiedExpr)
+		if found {
+			return true
+		}
+	}
+	return false
+}
+
 func overlaps(ctx *plancontext.PlanningContext, order []OrderBy, grouping []GroupBy) bool {
 ordering:
 	for _, orderBy := range order {
@@ -22,7 +22,6 @@ import (
 	"io"
 	"slices"
 	"sort"
-	"strings"
 
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
@@ -34,8 +33,9 @@ import (
 type (
 	// SelectExpr provides whether the column is aggregation expression or not.
 	SelectExpr struct {
-		Col  sqlparser.SelectExpr
-		Aggr bool
+		Col    sqlparser.SelectExpr
+		Aggr   bool
+		Window bool
 	}
 
 	// QueryProjection contains the information about the projections, group by and order by expressions used to do horizon planning.
@@ -43,6 +43,7 @@ type (
 		// If you change the contents here, please update the toString() method
 		SelectExprs  []SelectExpr
 		HasAggr      bool
+		HasWindow    bool
 		Distinct     bool
 		WithRollup   bool
 		groupByExprs []GroupBy
@@ -87,6 +88,11 @@ type (
 		ColOffset int
 		WSOffset  int
 
+		// ExtraOffsets point to the arguments after the first one, and OrderByOffsets
+		// to the ORDER BY expressions of GROUP_CONCAT. See Aggr.extraArgs and Aggr.orderBy
+		ExtraOffsets   []int
+		OrderByOffsets []int
+
 		SubQueryExpression []*SubQuery
 	}
 )
@@ -95,6 +101,27 @@ func (aggr Aggr) NeedsWeightString(ctx *plancontext.PlanningContext) bool {
 	return aggr.OpCode.NeedsComparableValues() && ctx.SemTable.NeedsWeightString(aggr.Func.GetArg())
 }
 
+// extraArgs returns the arguments after the first one, for the aggregations that are
+// able to use more than one argument when they are evaluated at the vtgate level
+func (aggr Aggr) extraArgs() sqlparser.Exprs {
+	switch aggr.OpCode {
+	case opcode.AggregateGroupConcat, opcode.AggregateCountDistinct:
+		args := aggr.Func.GetArgs()
+		if len(args) > 1 {
+			return args[1:]
+		}
+	}
+	return nil
+}
+
+// orderBy returns the ORDER BY used inside GROUP_CONCAT
+func (aggr Aggr) orderBy() sqlparser.OrderBy {
+	if f, ok := aggr.Func.(*sqlparser.GroupConcatExpr); ok {
+		return f.OrderBy
+	}
+	return nil
+}
+
 func (aggr Aggr) GetTypeCollation(ctx *plancontext.PlanningContext) evalengine.Type {
 	if aggr.Func == nil {
 		return evalengine.Type{}
@@ -189,6 +216,10 @@ func (qp *QueryProjection) addSelectExpressions(ctx *plancontext.PlanningContext
 				col.Aggr = true
 				qp.HasAggr = true
 			}
+			if sqlparser.ContainsWindowFunc(selExp.Expr) {
+				col.Window = true
+				qp.HasWindow = true
+			}
 
 			qp.SelectExprs = append(qp.SelectExprs, col)
 		case *sqlparser.StarExpr:
@@ -205,14 +236,22 @@ func (qp *QueryProjection) addSelectExpressions(ctx *plancontext.PlanningContext
 func IsAggr(ctx *plancontext.PlanningContext, e sqlparser.SQLNode) bool {
 	switch node := e.(type) {
 	case sqlparser.AggrFunc:
-		return true
+		// aggregation functions with an OVER clause are window functions, and do not aggregate rows
+		return !sqlparser.IsWindowFunc(node)
 	case *sqlparser.FuncExpr:
-		return node.Name.EqualsAnyString(ctx.VSchema.GetAggregateUDFs())
+		return isGroupingFunc(node) || node.Name.EqualsAnyString(ctx.VSchema.GetAggregateUDFs())
 	}
 
 	return false
 }
 
+// isGroupingFunc returns true if the node is a call to GROUPING().
+// The function is evaluated by the aggregation that does the rollup, so it is treated as an aggregation function.
+func isGroupingFunc(node sqlparser.SQLNode) bool {
+	fnc, ok := node.(*sqlparser.FuncExpr)
+	return ok && fnc.Qualifier.IsEmpty() && fnc.Name.EqualString("grouping")
+}
+
 func ContainsAggr(ctx *plancontext.PlanningContext, e sqlparser.SQLNode) (hasAggr bool) {
 	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node.(type) {
@@ -221,6 +260,10 @@ func ContainsAggr(ctx *plancontext.PlanningContext, e sqlparser.SQLNode) (hasAgg
 			// so we don't need to worry about aggregation in the original
 			return false, nil
 		case sqlparser.AggrFunc:
+			if sqlparser.IsWindowFunc(node) {
+				// the arguments of a window function can still contain aggregations
+				return true, nil
+			}
 			hasAggr = true
 			return false, io.EOF
 		case *sqlparser.Subquery:
@@ -281,6 +324,7 @@ func (qp *QueryProjection) addOrderBy(ctx *plancontext.PlanningContext, orderBy
 			SimplifiedExpr: order.Expr,
 		})
 		canPushSorting = canPushSorting && !ContainsAggr(ctx, order.Expr)
+		qp.HasWindow = qp.HasWindow || sqlparser.ContainsWindowFunc(order.Expr)
 	}
 }
 
@@ -378,6 +422,9 @@ func (qp *QueryProjection) toString() string {
 		if expr.Aggr {
 			e = "aggr: " + e
 		}
+		if expr.Window {
+			e = "window: " + e
+		}
 		out.Select = append(out.Select, e)
 	}
 
@@ -482,6 +529,16 @@ func (qp *QueryProjection) extractAggr(
 			addAggr(aggrFunc)
 			return false
 		}
+		if isGroupingFunc(node) {
+			ae := aeWrap(ex)
+			if ex == aliasedExpr.Expr {
+				ae = aliasedExpr
+			}
+			aggr := NewAggr(opcode.AggregateGrouping, nil, ae, ae.ColumnName())
+			aggr.Index = &idx
+			addAggr(aggr)
+			return false
+		}
 		if IsAggr(ctx, node) {
 			// If we are here, we have a function that is an aggregation but not parsed into an AggrFunc.
 			// This is the case for UDFs - we have to be careful with these because we can't evaluate them in VTGate.
@@ -700,14 +757,13 @@ func (qp *QueryProjection) useGroupingOverDistinct(ctx *plancontext.PlanningCont
 
 func checkForInvalidGroupingExpressions(ctx *plancontext.PlanningContext, expr sqlparser.Expr) {
 	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, isSubq := node.(*sqlparser.Subquery); isSubq {
+			// aggregations inside a subquery are evaluated by the subquery
+			return false, nil
+		}
 		if IsAggr(ctx, node) {
 			panic(vterrors.VT03005(sqlparser.String(expr)))
 		}
-		_, isSubQ := node.(*sqlparser.Subquery)
-		arg, isArg := node.(*sqlparser.Argument)
-		if isSubQ || (isArg && strings.HasPrefix(arg.Name, "__sq")) {
-			panic(vterrors.VT12001("subqueries in GROUP BY"))
-		}
 		return true, nil
 	}, expr)
 }
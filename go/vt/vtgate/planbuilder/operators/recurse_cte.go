@@ -0,0 +1,340 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+	"vitess.io/vitess/go/vt/vtgate/semantics"
+)
+
+type (
+	// RecurseCTE is used to evaluate a recursive CTE at the vtgate level.
+	// The Seed is evaluated once, and the Term is then evaluated for each row produced
+	// by the previous iteration, until no more rows are produced.
+	RecurseCTE struct {
+		Seed, Term Operator
+
+		Def *semantics.RecursiveCTE
+
+		// ID is the table id of the reference to the CTE outside its own definition
+		ID    semantics.TableSet
+		Alias string
+
+		// BindVars holds the names of the bind variables used to send the columns
+		// of the previous iteration to the term, one for each column of the CTE
+		BindVars []string
+	}
+
+	// CTEVars is the reference to a recursive CTE from inside its own recursive part.
+	// It produces a single row, built from the bind variables carrying the columns of
+	// the row produced by the previous iteration.
+	CTEVars struct {
+		Def *semantics.RecursiveCTE
+		ID  semantics.TableSet
+
+		// BindVars are the bind variables of the RecurseCTE that owns this operator
+		BindVars []string
+
+		Columns []*sqlparser.AliasedExpr
+
+		noInputs
+	}
+)
+
+var _ Operator = (*RecurseCTE)(nil)
+var _ Operator = (*CTEVars)(nil)
+
+func createRecurseCTE(ctx *plancontext.PlanningContext, tbl *semantics.CTETable, id semantics.TableSet) Operator {
+	def := tbl.CTE
+	bindVars := recurseCTEBindVars(ctx, def)
+	if tbl.SelfRef {
+		return &CTEVars{Def: def, ID: id, BindVars: bindVars}
+	}
+
+	alias := tbl.ASTNode.As.String()
+	if alias == "" {
+		alias = def.Name
+	}
+
+	seed := translateQueryToOp(ctx, def.Seed())
+	term := translateQueryToOp(ctx, def.Term())
+
+	rcte := &RecurseCTE{
+		Seed:     seed,
+		Term:     term,
+		Def:      def,
+		ID:       id,
+		Alias:    alias,
+		BindVars: bindVars,
+	}
+
+	// the recursive CTE only knows about its own columns,
+	// so any expression using them is evaluated by a projection on top of it
+	return newAliasedProjection(rcte)
+}
+
+// recurseCTEBindVars returns the names of the bind variables used to send the columns of the previous iteration
+// to the term, one for each column of the CTE. They are reserved the first time they are asked for, so the
+// references to the CTE from inside the term can use them while the term is being planned.
+func recurseCTEBindVars(ctx *plancontext.PlanningContext, def *semantics.RecursiveCTE) []string {
+	if bindVars, ok := ctx.RecurseCTEBindVars[def]; ok {
+		return bindVars
+	}
+	columns := def.Columns()
+	bindVars := make([]string, len(columns))
+	for i, col := range columns {
+		colName := sqlparser.NewColNameWithQualifier(col, sqlparser.NewTableName(def.Name))
+		bindVars[i] = ctx.ReservedVars.ReserveColName(colName)
+	}
+	ctx.RecurseCTEBindVars[def] = bindVars
+	return bindVars
+}
+
+// Clone implements the Operator interface
+func (r *RecurseCTE) Clone(inputs []Operator) Operator {
+	klone := *r
+	klone.Seed = inputs[0]
+	klone.Term = inputs[1]
+	return &klone
+}
+
+// Inputs implements the Operator interface
+func (r *RecurseCTE) Inputs() []Operator {
+	return []Operator{r.Seed, r.Term}
+}
+
+// SetInputs implements the Operator interface
+func (r *RecurseCTE) SetInputs(operators []Operator) {
+	r.Seed = operators[0]
+	r.Term = operators[1]
+}
+
+func (r *RecurseCTE) introducesTableID() semantics.TableSet {
+	return r.ID
+}
+
+// AddPredicate implements the Operator interface.
+// Predicates can't be pushed into the seed or the term, since that would change the recursion
+func (r *RecurseCTE) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	return newFilter(r, expr)
+}
+
+func (r *RecurseCTE) AddColumn(ctx *plancontext.PlanningContext, _, _ bool, expr *sqlparser.AliasedExpr) int {
+	offset := r.FindCol(ctx, expr.Expr, false)
+	if offset < 0 {
+		panic(vterrors.VT13001(fmt.Sprintf("could not find column '%s' on the recursive CTE '%s'", sqlparser.String(expr.Expr), r.Alias)))
+	}
+	return offset
+}
+
+func (r *RecurseCTE) AddWSColumn(ctx *plancontext.PlanningContext, offset int, _ bool) int {
+	panic(vterrors.VT12001(fmt.Sprintf("weight_string of a column of the recursive CTE '%s'", r.Alias)))
+}
+
+func (r *RecurseCTE) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) int {
+	return findCTEColumn(ctx, r.Def, r.ID, expr)
+}
+
+func (r *RecurseCTE) GetColumns(*plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	return slice.Map(r.Def.Columns(), func(col string) *sqlparser.AliasedExpr {
+		return aeWrap(sqlparser.NewColNameWithQualifier(col, sqlparser.NewTableName(r.Alias)))
+	})
+}
+
+func (r *RecurseCTE) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	return transformColumnsToSelectExprs(ctx, r)
+}
+
+func (r *RecurseCTE) GetOrdering(*plancontext.PlanningContext) []OrderBy {
+	return nil
+}
+
+func (r *RecurseCTE) ShortDescription() string {
+	distinct := ""
+	if r.Def.Distinct() {
+		distinct = " DISTINCT"
+	}
+	return fmt.Sprintf("%s%s (%s)", r.Alias, distinct, strings.Join(r.BindVars, ", "))
+}
+
+// canPushToRecurseCTE returns false if the operator passes the columns it is asked for down to a
+// recursive CTE, and the expression is not a column of the CTE. The CTE can only produce its own
+// columns, so any other expression has to be evaluated above it.
+func canPushToRecurseCTE(ctx *plancontext.PlanningContext, op Operator, expr sqlparser.Expr) bool {
+	for {
+		switch src := op.(type) {
+		case *RecurseCTE:
+			return src.FindCol(ctx, expr, false) >= 0
+		case *Ordering:
+			op = src.Source
+		case *Filter:
+			op = src.Source
+		default:
+			return true
+		}
+	}
+}
+
+// projectRecurseCTE puts a projection of all the columns of the recursive CTE on top of it, if the
+// operator passes the columns it is asked for down to one. The CTE always produces all its columns,
+// so the offsets of the columns don't change, and the projection can evaluate the expressions the
+// CTE can't produce, like weight strings.
+func projectRecurseCTE(ctx *plancontext.PlanningContext, op Operator) Operator {
+	switch src := op.(type) {
+	case *RecurseCTE:
+		proj := newAliasedProjection(src)
+		for offset, ae := range src.GetColumns(ctx) {
+			pe := newProjExpr(ae)
+			pe.Info = Offset(offset)
+			proj.addProjExpr(pe)
+		}
+		return proj
+	case *Ordering:
+		src.Source = projectRecurseCTE(ctx, src.Source)
+	case *Filter:
+		src.Source = projectRecurseCTE(ctx, src.Source)
+	}
+	return op
+}
+
+// findCTEColumn returns the offset of the CTE column the expression refers to, or -1 if it is not a column of the CTE
+func findCTEColumn(ctx *plancontext.PlanningContext, def *semantics.RecursiveCTE, id semantics.TableSet, expr sqlparser.Expr) int {
+	col, ok := expr.(*sqlparser.ColName)
+	if !ok {
+		return -1
+	}
+	if deps := ctx.SemTable.DirectDeps(col); deps.NotEmpty() && deps != id {
+		return -1
+	}
+	return slices.IndexFunc(def.Columns(), func(name string) bool {
+		return col.Name.EqualString(name)
+	})
+}
+
+// Clone implements the Operator interface
+func (c *CTEVars) Clone([]Operator) Operator {
+	klone := *c
+	klone.Columns = slices.Clone(c.Columns)
+	return &klone
+}
+
+func (c *CTEVars) introducesTableID() semantics.TableSet {
+	return c.ID
+}
+
+// AddPredicate implements the Operator interface
+func (c *CTEVars) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	return newFilter(c, expr)
+}
+
+func (c *CTEVars) AddColumn(ctx *plancontext.PlanningContext, reuse bool, _ bool, expr *sqlparser.AliasedExpr) int {
+	if reuse {
+		if offset := c.FindCol(ctx, expr.Expr, false); offset >= 0 {
+			return offset
+		}
+	}
+	c.Columns = append(c.Columns, expr)
+	return len(c.Columns) - 1
+}
+
+func (c *CTEVars) AddWSColumn(ctx *plancontext.PlanningContext, offset int, _ bool) int {
+	return c.AddColumn(ctx, false, false, aeWrap(weightStringFor(c.Columns[offset].Expr)))
+}
+
+func (c *CTEVars) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) int {
+	offset, found := canReuseColumn(ctx, c.Columns, expr, func(ae *sqlparser.AliasedExpr) sqlparser.Expr { return ae.Expr })
+	if !found {
+		return -1
+	}
+	return offset
+}
+
+func (c *CTEVars) GetColumns(*plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	return c.Columns
+}
+
+func (c *CTEVars) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	return transformColumnsToSelectExprs(ctx, c)
+}
+
+func (c *CTEVars) GetOrdering(*plancontext.PlanningContext) []OrderBy {
+	return nil
+}
+
+func (c *CTEVars) ShortDescription() string {
+	return fmt.Sprintf("%s (%s)", c.Def.Name, strings.Join(c.BindVars, ", "))
+}
+
+// ToArguments rewrites an expression using the columns of the CTE to use the bind variables instead
+func (c *CTEVars) ToArguments(ctx *plancontext.PlanningContext, expr sqlparser.Expr) sqlparser.Expr {
+	return c.replaceColumns(ctx, expr, true)
+}
+
+// replaceColumns rewrites the columns of the CTE in the expression to use the bind variables.
+// Other columns are left as they are, unless strict is set, in which case they are not allowed.
+func (c *CTEVars) replaceColumns(ctx *plancontext.PlanningContext, expr sqlparser.Expr, strict bool) sqlparser.Expr {
+	return sqlparser.CopyOnRewrite(expr, nil, func(cursor *sqlparser.CopyOnWriteCursor) {
+		col, ok := cursor.Node().(*sqlparser.ColName)
+		if !ok {
+			return
+		}
+		offset := findCTEColumn(ctx, c.Def, c.ID, col)
+		if offset < 0 {
+			if !strict {
+				return
+			}
+			panic(vterrors.VT13001(fmt.Sprintf("column '%s' is not a column of the recursive CTE '%s'", sqlparser.String(col), c.Def.Name)))
+		}
+		typ, found := ctx.SemTable.TypeForExpr(col)
+		if !found {
+			cursor.Replace(sqlparser.NewArgument(c.BindVars[offset]))
+			return
+		}
+		cursor.Replace(sqlparser.NewTypedArgument(c.BindVars[offset], typ.Type()))
+	}, nil).(sqlparser.Expr)
+}
+
+// pushCTEVarsPredicate pushes a predicate of an inner join with the reference to a recursive CTE from inside its
+// own recursive part to the other side of the join. The CTE columns are the same for the whole evaluation of the
+// term, so they can be used as bind variables there, and the other side can use the predicate to route the query.
+func pushCTEVarsPredicate(ctx *plancontext.PlanningContext, join JoinOp, expr sqlparser.Expr) bool {
+	if !join.IsInner() {
+		return false
+	}
+	if vars, ok := join.GetRHS().(*CTEVars); ok {
+		newExpr := vars.replaceColumns(ctx, expr, false)
+		if ctx.SemTable.RecursiveDeps(newExpr).IsSolvedBy(TableID(join.GetLHS())) {
+			join.SetLHS(join.GetLHS().AddPredicate(ctx, newExpr))
+			return true
+		}
+	}
+	if vars, ok := join.GetLHS().(*CTEVars); ok {
+		newExpr := vars.replaceColumns(ctx, expr, false)
+		if ctx.SemTable.RecursiveDeps(newExpr).IsSolvedBy(TableID(join.GetRHS())) {
+			join.SetRHS(join.GetRHS().AddPredicate(ctx, newExpr))
+			return true
+		}
+	}
+	return false
+}
@@ -375,6 +375,12 @@ func createRoute(
 	if queryTable.IsInfSchema {
 		return createInfSchemaRoute(ctx, queryTable)
 	}
+	if tableInfo, err := ctx.SemTable.TableInfoFor(queryTable.ID); err == nil {
+		if cte, isCTE := tableInfo.(*semantics.CTETable); isCTE {
+			// the whole recursive CTE can be sent to the single unsharded keyspace its tables live in
+			return createRouteFromVSchemaTable(ctx, queryTable, cte.GetVindexTable(), false, nil)
+		}
+	}
 	return findVSchemaTableAndCreateRoute(ctx, queryTable, queryTable.Table, true /*planAlternates*/)
 }
 
@@ -710,6 +716,8 @@ func addWSColumnToInput(ctx *plancontext.PlanningContext, source Operator, offse
 		return true, op.AddWSColumn(ctx, offset, true)
 	case *Aggregator:
 		return true, op.AddWSColumn(ctx, offset, true)
+	case *Window:
+		return true, op.AddWSColumn(ctx, offset, true)
 	}
 	return false, -1
 }
@@ -101,7 +101,7 @@ func buildVindexTableForDML(
 
 	tblName, ok := table.Alias.Expr.(sqlparser.TableName)
 	if !ok {
-		panic(vterrors.VT12001("multi shard UPDATE with LIMIT"))
+		panic(vterrors.VT12001(dmlType + " on a target that is not a table"))
 	}
 
 	_, _, _, typ, dest, err := ctx.VSchema.FindTableOrVindex(tblName)
@@ -288,6 +288,14 @@ func requiresSwitchingSides(ctx *plancontext.PlanningContext, op Operator) (requ
 }
 
 func mergeOrJoin(ctx *plancontext.PlanningContext, lhs, rhs Operator, joinPredicates []sqlparser.Expr, joinType sqlparser.JoinType) (Operator, *ApplyResult) {
+	if joinType.IsCommutative() && len(lateralVarsFor(ctx, rhs, lhs)) > 0 {
+		// a LATERAL derived table has to be joined after the tables it uses
+		lhs, rhs = rhs, lhs
+	}
+	if vars := lateralVarsFor(ctx, lhs, rhs); len(vars) > 0 {
+		return mergeOrJoinLateral(ctx, lhs, rhs, joinPredicates, joinType, vars)
+	}
+
 	newPlan := mergeJoinInputs(ctx, lhs, rhs, joinPredicates, newJoinMerge(joinPredicates, joinType))
 	if newPlan != nil {
 		return newPlan, Rewrote("merge routes into single operator")
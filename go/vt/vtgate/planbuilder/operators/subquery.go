@@ -18,6 +18,7 @@ package operators
 
 import (
 	"fmt"
+	"io"
 	"maps"
 	"slices"
 
@@ -52,6 +53,20 @@ type SubQuery struct {
 	// correlated stores whether this subquery is correlated or not.
 	// We use this information to fail the planning if we are unable to merge the subquery with a route.
 	correlated bool
+	// comparison is the comparison this correlated subquery is used in, with the subquery replaced by the expression
+	// it selects. If the subquery can't be merged, the comparison is evaluated inside the subquery, once per outer row.
+	comparison *sqlparser.ComparisonExpr
+	// inDML is set for subqueries in the SET clause of an UPDATE, where the subquery value can't be evaluated per row
+	inDML bool
+	// inOuterJoin is set for uncorrelated subqueries in the ON condition of an outer join. The subquery is evaluated
+	// before the join, and the join condition uses the arguments the result is passed in.
+	inOuterJoin bool
+	// predicateSubqueries are all the subqueries of the predicate, in the order they are used, when the predicate
+	// uses more than one. It is set on the subquery the predicate was extracted for, and leader is set on the others
+	// to point back to it. It is only merged if the others can be merged as well, and otherwise adds the predicate
+	// using the values of all of them.
+	predicateSubqueries []*SubQuery
+	leader              *SubQuery
 
 	IsProjection bool
 }
@@ -76,6 +91,10 @@ func (sq *SubQuery) OuterExpressionsNeeded(ctx *plancontext.PlanningContext, out
 	if err != nil {
 		return nil
 	}
+	if sq.comparison != nil {
+		// the comparison is moved into the subquery when it is settled, and then needs the outer values as well
+		joinColumns = append(slices.Clone(joinColumns), breakExpressionInLHSandRHS(ctx, sq.comparison, TableID(outer)))
+	}
 	for _, jc := range joinColumns {
 		for _, lhsExpr := range jc.LHSExprs {
 			col, ok := lhsExpr.Expr.(*sqlparser.ColName)
@@ -176,23 +195,63 @@ func (sq *SubQuery) AddPredicate(ctx *plancontext.PlanningContext, expr sqlparse
 }
 
 func (sq *SubQuery) AddColumn(ctx *plancontext.PlanningContext, reuseExisting bool, addToGroupBy bool, exprs *sqlparser.AliasedExpr) int {
-	return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs)
+	if !sq.projectsValue() {
+		return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs)
+	}
+	if sq.isValueColumn(exprs.Expr) {
+		return 0
+	}
+	return sq.Outer.AddColumn(ctx, reuseExisting, addToGroupBy, exprs) + 1
 }
 
 func (sq *SubQuery) AddWSColumn(ctx *plancontext.PlanningContext, offset int, underRoute bool) int {
-	return sq.Outer.AddWSColumn(ctx, offset, underRoute)
+	if !sq.projectsValue() {
+		return sq.Outer.AddWSColumn(ctx, offset, underRoute)
+	}
+	if offset == 0 {
+		panic(vterrors.VT12001("weight_string of a correlated subquery in the SELECT list"))
+	}
+	return sq.Outer.AddWSColumn(ctx, offset-1, underRoute) + 1
 }
 
 func (sq *SubQuery) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, underRoute bool) int {
-	return sq.Outer.FindCol(ctx, expr, underRoute)
+	if !sq.projectsValue() {
+		return sq.Outer.FindCol(ctx, expr, underRoute)
+	}
+	if sq.isValueColumn(expr) {
+		return 0
+	}
+	offset := sq.Outer.FindCol(ctx, expr, underRoute)
+	if offset < 0 {
+		return offset
+	}
+	return offset + 1
 }
 
 func (sq *SubQuery) GetColumns(ctx *plancontext.PlanningContext) []*sqlparser.AliasedExpr {
-	return sq.Outer.GetColumns(ctx)
+	if !sq.projectsValue() {
+		return sq.Outer.GetColumns(ctx)
+	}
+	return append([]*sqlparser.AliasedExpr{aeWrap(sqlparser.NewColName(sq.ArgName))}, sq.Outer.GetColumns(ctx)...)
 }
 
 func (sq *SubQuery) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
-	return sq.Outer.GetSelectExprs(ctx)
+	if !sq.projectsValue() {
+		return sq.Outer.GetSelectExprs(ctx)
+	}
+	return transformColumnsToSelectExprs(ctx, sq)
+}
+
+// projectsValue returns true if this is a correlated subquery in the SELECT list that is evaluated
+// once for every row of the outer query. The value it produces is added as the first column of the outer rows.
+func (sq *SubQuery) projectsValue() bool {
+	return sq.IsProjection && len(sq.Predicates) > 0
+}
+
+// isValueColumn returns true if the expression is the column the subquery was replaced with
+func (sq *SubQuery) isValueColumn(expr sqlparser.Expr) bool {
+	col, ok := expr.(*sqlparser.ColName)
+	return ok && col.Qualifier.IsEmpty() && col.Name.EqualString(sq.ArgName)
 }
 
 // GetMergePredicates returns the predicates that we can use to try to merge this subquery with the outer query.
@@ -204,16 +263,36 @@ func (sq *SubQuery) GetMergePredicates() []sqlparser.Expr {
 }
 
 func (sq *SubQuery) settle(ctx *plancontext.PlanningContext, outer Operator) Operator {
-	if !sq.TopLevel {
+	if sq.usedWithAnyAll() {
+		panic(anyAllSubqueryErr)
+	}
+	if sq.leader != nil {
+		return sq.settleWithLeader(ctx, outer)
+	}
+	if (!sq.TopLevel || len(sq.predicateSubqueries) > 0) && !sq.canBePulledOutNested() {
 		panic(subqueryNotAtTopErr)
 	}
-	if sq.correlated && sq.FilterType != opcode.PulloutExists {
+	if sq.inOuterJoin {
+		// the predicates using the subquery result are already part of the join condition
+		return outer
+	}
+	if sq.correlated && len(sq.Predicates) == 0 && sq.FilterType != opcode.PulloutExists {
+		// the subquery uses columns from the outer query, but not in predicates we can send values to
+		panic(correlatedSubqueryErr)
+	}
+	if sq.correlated && sq.usesTablesOutside(ctx, outer) {
+		// the values are only passed in from the query the subquery is directly part of
+		panic(nestedCorrelationErr)
+	}
+	if sq.correlated && sq.aggregatesOuterColumns(ctx) {
+		// an aggregation over columns of the outer query belongs to the outer query,
+		// so it can't be replaced by the value of a single outer row
 		panic(correlatedSubqueryErr)
 	}
 	if sq.IsProjection {
-		if len(sq.GetMergePredicates()) > 0 {
-			// this means that we have a correlated subquery on our hands
-			panic(correlatedSubqueryErr)
+		if len(sq.Predicates) > 0 {
+			sq.settleCorrelatedProjection(ctx, outer)
+			return outer
 		}
 		sq.SubqueryValueName = sq.ArgName
 		return outer
@@ -221,33 +300,216 @@ func (sq *SubQuery) settle(ctx *plancontext.PlanningContext, outer Operator) Ope
 	return sq.settleFilter(ctx, outer)
 }
 
-var correlatedSubqueryErr = vterrors.VT12001("correlated subquery is only supported for EXISTS")
+// usesTablesOutside returns true if the predicates of the subquery use tables that are not part of either
+// the subquery or the outer query, which happens when a nested subquery uses a query further out
+func (sq *SubQuery) usesTablesOutside(ctx *plancontext.PlanningContext, outer Operator) bool {
+	var deps semantics.TableSet
+	for _, pred := range sq.Predicates {
+		deps = deps.Merge(ctx.SemTable.RecursiveDeps(pred))
+	}
+	return !deps.Remove(TableID(sq.Subquery)).IsSolvedBy(TableID(outer))
+}
+
+// aggregatesOuterColumns returns true if any of the predicates of the subquery
+// aggregates columns that come from the outer query
+func (sq *SubQuery) aggregatesOuterColumns(ctx *plancontext.PlanningContext) bool {
+	innerID := TableID(sq.Subquery)
+	found := false
+	for _, pred := range sq.Predicates {
+		_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+			if IsAggr(ctx, node) && !ctx.SemTable.RecursiveDeps(node.(sqlparser.Expr)).IsSolvedBy(innerID) {
+				found = true
+				return false, io.EOF
+			}
+			return true, nil
+		}, pred)
+	}
+	return found
+}
+
+var correlatedSubqueryErr = vterrors.VT12001("correlated subquery that can not be evaluated once per row of the outer query")
+var nestedCorrelationErr = vterrors.VT12001("correlated subquery that uses columns from a query more than one level up")
+var multiRowCorrelatedSubqueryErr = vterrors.VT12001("correlated scalar subquery that can return more than one row")
 var subqueryNotAtTopErr = vterrors.VT12001("unmergable subquery can not be inside complex expression")
+var anyAllSubqueryErr = vterrors.VT12001("ANY/ALL/SOME comparison operator with a subquery that can not be merged")
+
+// usedWithAnyAll returns true if the subquery is compared using ANY, SOME or ALL. These comparisons
+// are only sent to MySQL as they are, so the subquery has to be merged with the outer query.
+func (sq *SubQuery) usedWithAnyAll() bool {
+	found := false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		cmp, ok := node.(*sqlparser.ComparisonExpr)
+		if ok && cmp.Modifier != sqlparser.Missing && sqlparser.Equals.Expr(cmp.Right, sq.originalSubquery) {
+			found = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, sq.Original)
+	return found
+}
+
+// canBePulledOutNested returns true if the subquery, and the others in the same predicate, are evaluated once
+// before the outer query, so their values can be used anywhere in the predicate
+func (sq *SubQuery) canBePulledOutNested() bool {
+	if sq.IsProjection || sq.inOuterJoin {
+		return false
+	}
+	for _, s := range append([]*SubQuery{sq}, sq.predicateSubqueries...) {
+		if s.correlated || s.FilterType != opcode.PulloutValue {
+			return false
+		}
+	}
+	return true
+}
+
+// settleWithLeader settles a subquery that is used in the predicate of another subquery
+func (sq *SubQuery) settleWithLeader(ctx *plancontext.PlanningContext, outer Operator) Operator {
+	if sq.leader.isMerged(ctx) {
+		// the subquery was sent to MySQL as part of the predicate
+		return outer
+	}
+	if !sq.leader.canBePulledOutNested() {
+		panic(subqueryNotAtTopErr)
+	}
+	sq.SubqueryValueName = sq.ArgName
+	return outer
+}
+
+// settleCorrelatedProjection prepares a correlated subquery in the SELECT list to be evaluated once per row of the outer query.
+// For IN and NOT IN, the comparison with the outer value is done inside the subquery.
+func (sq *SubQuery) settleCorrelatedProjection(ctx *plancontext.PlanningContext, outer Operator) {
+	if sq.inDML {
+		panic(correlatedSubqueryErr)
+	}
+	if !sq.FilterType.NeedsListArg() {
+		return
+	}
+	// the subquery returns the result of the comparison for the rows where it is not false,
+	// and the SemiJoin uses these to find out if the comparison is true, false or NULL
+	cmp := sq.pushComparison(ctx, outer, nullAwareComparison)
+	proj := newAliasedProjection(sq.Subquery)
+	proj.addUnexploredExpr(aeWrap(cmp), cmp)
+	sq.Subquery = proj
+}
+
+// settleCorrelatedFilter turns correlated IN, NOT IN and comparison predicates into [NOT] EXISTS,
+// by moving the comparison with the outer value into the subquery.
+func (sq *SubQuery) settleCorrelatedFilter(ctx *plancontext.PlanningContext, outer Operator) {
+	switch sq.FilterType {
+	case opcode.PulloutIn:
+		sq.pushComparison(ctx, outer, nil)
+		sq.FilterType = opcode.PulloutExists
+	case opcode.PulloutValue:
+		if sq.comparison != nil && !sq.returnsSingleRow() {
+			// MySQL fails when the subquery returns more than one row,
+			// but EXISTS would be true as soon as one of the rows matched
+			panic(multiRowCorrelatedSubqueryErr)
+		}
+		sq.pushComparison(ctx, outer, nil)
+		sq.FilterType = opcode.PulloutExists
+	case opcode.PulloutNotIn:
+		sq.pushComparison(ctx, outer, nullAwareComparison)
+		sq.FilterType = opcode.PulloutNotExists
+	}
+}
+
+// returnsSingleRow returns true if the subquery always returns exactly one row,
+// which is the case for an aggregation without GROUP BY or HAVING
+func (sq *SubQuery) returnsSingleRow() bool {
+	sel, ok := sq.originalSubquery.Select.(*sqlparser.Select)
+	if !ok || sel.GroupBy != nil || sel.Having != nil {
+		return false
+	}
+	aggregated, windowed := false, false
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node.(type) {
+		case *sqlparser.Subquery:
+			return false, nil
+		case sqlparser.AggrFunc:
+			aggregated = true
+		}
+		if sqlparser.GetOverClause(node) != nil {
+			windowed = true
+			return false, io.EOF
+		}
+		return true, nil
+	}, sel.SelectExprs)
+	return aggregated && !windowed
+}
+
+// pushComparison adds the comparison this subquery is used in to the predicates of the subquery,
+// so the values it needs from the outer query are passed in as arguments. The comparison, as it
+// will be evaluated inside the subquery, is returned.
+func (sq *SubQuery) pushComparison(
+	ctx *plancontext.PlanningContext,
+	outer Operator,
+	filter func(*sqlparser.ComparisonExpr) sqlparser.Expr,
+) *sqlparser.ComparisonExpr {
+	if sq.comparison == nil {
+		panic(correlatedSubqueryErr)
+	}
+	sq.Predicates = append(sq.Predicates, sq.comparison)
+	sq.JoinColumns = nil
+	columns, err := sq.GetJoinColumns(ctx, outer)
+	if err != nil {
+		panic(err)
+	}
+	cmp := columns[len(columns)-1].RHSExpr.(*sqlparser.ComparisonExpr)
+	if filter == nil {
+		sq.Subquery = newFilter(sq.Subquery, cmp)
+	} else {
+		sq.Subquery = newFilter(sq.Subquery, filter(cmp))
+	}
+	return cmp
+}
+
+// nullAwareComparison returns a predicate that is true for all the rows where the comparison is not false.
+// These are the rows that decide the result of IN and NOT IN, since a NULL in the subquery can turn the result into NULL.
+func nullAwareComparison(cmp *sqlparser.ComparisonExpr) sqlparser.Expr {
+	return &sqlparser.OrExpr{
+		Left: &sqlparser.OrExpr{
+			Left:  cmp,
+			Right: &sqlparser.IsExpr{Left: cmp.Left, Right: sqlparser.IsNullOp},
+		},
+		Right: &sqlparser.IsExpr{Left: cmp.Right, Right: sqlparser.IsNullOp},
+	}
+}
 
 func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer Operator) Operator {
 	if len(sq.Predicates) > 0 {
-		if sq.FilterType != opcode.PulloutExists {
-			panic(correlatedSubqueryErr)
-		}
+		// the subquery is evaluated once per row of the outer query
+		sq.settleCorrelatedFilter(ctx, outer)
 		return outer
 	}
 
+	return newFilter(outer, sq.pulloutPredicates(ctx)...)
+}
+
+// pulloutPredicates returns the predicates that replace an uncorrelated subquery,
+// using the arguments the subquery result is passed in
+func (sq *SubQuery) pulloutPredicates(ctx *plancontext.PlanningContext) []sqlparser.Expr {
 	hasValuesArg := func() string {
 		s := ctx.ReservedVars.ReserveVariable(string(sqlparser.HasValueSubQueryBaseName))
 		sq.HasValuesName = s
 		return s
 	}
+	subqueries := sq.predicateSubqueries
+	if len(subqueries) == 0 {
+		subqueries = []*SubQuery{sq}
+	}
 	post := func(cursor *sqlparser.CopyOnWriteCursor) {
 		node := cursor.Node()
-		if _, ok := node.(*sqlparser.Subquery); !ok {
+		if _, ok := node.(*sqlparser.Subquery); !ok || len(subqueries) == 0 {
 			return
 		}
 
+		s := subqueries[0]
+		subqueries = subqueries[1:]
 		var arg sqlparser.Expr
-		if sq.FilterType.NeedsListArg() {
-			arg = sqlparser.NewListArg(sq.ArgName)
+		if s.FilterType.NeedsListArg() {
+			arg = sqlparser.NewListArg(s.ArgName)
 		} else {
-			arg = sqlparser.NewArgument(sq.ArgName)
+			arg = sqlparser.NewArgument(s.ArgName)
 		}
 		cursor.Replace(arg)
 	}
@@ -273,7 +535,7 @@ func (sq *SubQuery) settleFilter(ctx *plancontext.PlanningContext, outer Operato
 		predicates = append(predicates, rhsPred)
 		sq.SubqueryValueName = sq.ArgName
 	}
-	return newFilter(outer, predicates...)
+	return predicates
 }
 
 func dontEnterSubqueries(node, _ sqlparser.SQLNode) bool {
@@ -283,6 +545,15 @@ func dontEnterSubqueries(node, _ sqlparser.SQLNode) bool {
 	return true
 }
 
+// originalComparison returns the [NOT] IN comparison a correlated subquery in the SELECT list was used in
+func (sq *SubQuery) originalComparison() *sqlparser.ComparisonExpr {
+	op := sqlparser.InOp
+	if sq.FilterType == opcode.PulloutNotIn {
+		op = sqlparser.NotInOp
+	}
+	return sqlparser.NewComparisonExpr(op, sq.comparison.Left, sq.originalSubquery, nil)
+}
+
 func (sq *SubQuery) isMerged(ctx *plancontext.PlanningContext) bool {
 	return slices.Index(ctx.MergedSubqueries, sq.originalSubquery) >= 0
 }
@@ -290,6 +561,9 @@ func (sq *SubQuery) isMerged(ctx *plancontext.PlanningContext) bool {
 // mapExpr rewrites all expressions according to the provided function
 func (sq *SubQuery) mapExpr(f func(expr sqlparser.Expr) sqlparser.Expr) {
 	sq.Predicates = slice.Map(sq.Predicates, f)
+	if sq.comparison != nil {
+		sq.comparison = f(sq.comparison).(*sqlparser.ComparisonExpr)
+	}
 	sq.Original = f(sq.Original)
 	sq.originalSubquery = f(sq.originalSubquery).(*sqlparser.Subquery)
 }
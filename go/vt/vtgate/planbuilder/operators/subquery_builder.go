@@ -17,6 +17,8 @@ limitations under the License.
 package operators
 
 import (
+	"slices"
+
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
 	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
@@ -60,10 +62,56 @@ func (sqb *SubQueryBuilder) handleSubquery(
 	argName := ctx.GetReservedArgumentFor(subq)
 	sqInner := createSubqueryOp(ctx, parentExpr, expr, subq, outerID, argName)
 	sqb.Inner = append(sqb.Inner, sqInner)
+	sqb.handleOtherSubqueries(ctx, expr, subq, sqInner, outerID)
 
 	return sqInner
 }
 
+// handleOtherSubqueries extracts the other subqueries of a predicate that uses more than one. When the subquery
+// the predicate was extracted for is merged, the whole predicate is sent to MySQL, so the others are merged
+// together with it. Otherwise, they are pulled out and their values are used in the predicate.
+func (sqb *SubQueryBuilder) handleOtherSubqueries(
+	ctx *plancontext.PlanningContext,
+	expr sqlparser.Expr,
+	first *sqlparser.Subquery,
+	leader *SubQuery,
+	outerID semantics.TableSet,
+) {
+	var subqueries []*SubQuery
+	_ = sqlparser.Rewrite(expr, func(cursor *sqlparser.Cursor) bool {
+		subq, ok := cursor.Node().(*sqlparser.Subquery)
+		if !ok {
+			return true
+		}
+		argName := ctx.GetReservedArgumentFor(subq)
+		if same := slices.IndexFunc(subqueries, func(sq *SubQuery) bool { return sq.ArgName == argName }); same >= 0 {
+			// the same subquery is used more than once in the predicate, so we only evaluate it once
+			subqueries = append(subqueries, subqueries[same])
+			return false
+		}
+		if subq == first || argName == leader.ArgName {
+			subqueries = append(subqueries, leader)
+			return false
+		}
+		filterType := opcode.PulloutExists
+		if t := getOpCodeFromParent(cursor.Parent()); t != nil {
+			filterType = *t
+		}
+		parent, ok := cursor.Parent().(sqlparser.Expr)
+		if !ok {
+			parent = subq
+		}
+		sqInner := createSubquery(ctx, expr, subq, outerID, parent, argName, filterType, false)
+		sqInner.leader = leader
+		subqueries = append(subqueries, sqInner)
+		sqb.Inner = append(sqb.Inner, sqInner)
+		return false
+	}, nil)
+	if len(subqueries) > 1 {
+		leader.predicateSubqueries = subqueries
+	}
+}
+
 func getSubQuery(expr sqlparser.Expr) (subqueryExprExists *sqlparser.Subquery, parentExpr sqlparser.Expr) {
 	flipped := false
 	_ = sqlparser.Rewrite(expr, func(cursor *sqlparser.Cursor) bool {
@@ -165,6 +213,8 @@ func createSubquery(
 	original = cloneASTAndSemState(ctx, original)
 	originalSq := cloneASTAndSemState(ctx, subq)
 	subqID := findTablesContained(ctx, subq.Select)
+	// when subqueries are nested, the tables of the outer query include the ones of this subquery
+	outerID = outerID.Remove(subqID)
 	totalID := subqID.Merge(outerID)
 	sqc := &SubQueryBuilder{totalID: totalID, subqID: subqID, outerID: outerID}
 
@@ -277,6 +327,15 @@ func createComparisonSubQuery(
 	}
 
 	subquery := createSubquery(ctx, original, subq, outerID, parent, name, filterType, false)
+	if subquery.correlated {
+		subquery.comparison = correlatedComparison(parent, subq)
+	}
+
+	if parent.Modifier != sqlparser.Missing && (parent.Operator != sqlparser.EqualOp || parent.Modifier != sqlparser.Any) {
+		// apart from = ANY, which is the same as IN, the comparison looks at all the rows of the subquery,
+		// so matching rows of the two sides doesn't make it safe to merge them
+		return subquery
+	}
 
 	// if we are comparing with a column from the inner subquery,
 	// we add this extra predicate to check if the two sides are mergable or not
@@ -305,7 +364,13 @@ func (sqb *SubQueryBuilder) pullOutValueSubqueries(
 	var newSubqs []*SubQuery
 
 	for idx, subq := range sqe.subq {
+		if slices.ContainsFunc(newSubqs, func(sq *SubQuery) bool { return sq.ArgName == sqe.cols[idx] }) {
+			// the same subquery is used more than once in the expression, so we only evaluate it once
+			continue
+		}
 		sqInner := createSubquery(ctx, original, subq, outerID, original, sqe.cols[idx], sqe.pullOutCode[idx], true)
+		sqInner.comparison = sqe.comparisons[idx]
+		sqInner.inDML = isDML
 		newSubqs = append(newSubqs, sqInner)
 	}
 
@@ -319,6 +384,36 @@ type subqueryExtraction struct {
 	subq        []*sqlparser.Subquery
 	pullOutCode []opcode.PulloutOpcode
 	cols        []string
+	comparisons []*sqlparser.ComparisonExpr
+}
+
+// correlatedComparison returns the comparison a correlated subquery is used in, with the subquery replaced by the
+// expression it selects. Comparisons that can't be evaluated inside the subquery return nil.
+func correlatedComparison(cmp *sqlparser.ComparisonExpr, subq *sqlparser.Subquery) *sqlparser.ComparisonExpr {
+	sel, ok := subq.Select.(*sqlparser.Select)
+	if !ok || sel.Limit != nil {
+		// filtering the rows of the subquery would change which rows UNION and LIMIT return
+		return nil
+	}
+	ae, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return nil
+	}
+
+	switch cmp.Operator {
+	case sqlparser.InOp, sqlparser.NotInOp:
+		return sqlparser.NewComparisonExpr(sqlparser.EqualOp, cmp.Left, ae.Expr, nil)
+	case sqlparser.NullSafeEqualOp:
+		// this comparison can be true even when the subquery returns no rows
+		return nil
+	}
+	newCmp := sqlparser.NewComparisonExpr(cmp.Operator, cmp.Left, cmp.Right, cmp.Escape)
+	if cmp.Left == subq {
+		newCmp.Left = ae.Expr
+	} else {
+		newCmp.Right = ae.Expr
+	}
+	return newCmp
 }
 
 func getOpCodeFromParent(parent sqlparser.SQLNode) *opcode.PulloutOpcode {
@@ -339,7 +434,7 @@ func getOpCodeFromParent(parent sqlparser.SQLNode) *opcode.PulloutOpcode {
 
 func extractSubQueries(ctx *plancontext.PlanningContext, expr sqlparser.Expr, isDML bool) *subqueryExtraction {
 	sqe := &subqueryExtraction{}
-	replaceWithArg := func(cursor *sqlparser.Cursor, sq *sqlparser.Subquery, t opcode.PulloutOpcode) {
+	replaceWithArg := func(cursor *sqlparser.Cursor, sq *sqlparser.Subquery, t opcode.PulloutOpcode, cmp *sqlparser.ComparisonExpr) {
 		sqName := ctx.GetReservedArgumentFor(sq)
 		sqe.cols = append(sqe.cols, sqName)
 		if isDML {
@@ -352,20 +447,39 @@ func extractSubQueries(ctx *plancontext.PlanningContext, expr sqlparser.Expr, is
 			cursor.Replace(sqlparser.NewColName(sqName))
 		}
 		sqe.subq = append(sqe.subq, sq)
+		sqe.pullOutCode = append(sqe.pullOutCode, t)
+		sqe.comparisons = append(sqe.comparisons, cmp)
+	}
+
+	// a correlated subquery used with [NOT] IN is evaluated together with the comparison,
+	// so the whole comparison is replaced by the column
+	pre := func(cursor *sqlparser.Cursor) bool {
+		cmp, ok := cursor.Node().(*sqlparser.ComparisonExpr)
+		if isDML || !ok || (cmp.Operator != sqlparser.InOp && cmp.Operator != sqlparser.NotInOp) {
+			return true
+		}
+		subq, ok := cmp.Right.(*sqlparser.Subquery)
+		if !ok || ctx.SemTable.RecursiveDeps(subq).IsEmpty() {
+			return true
+		}
+		correlated := correlatedComparison(cmp, subq)
+		if correlated == nil {
+			return true
+		}
+		replaceWithArg(cursor, subq, *getOpCodeFromParent(cmp), correlated)
+		return false
 	}
 
-	expr = sqlparser.Rewrite(expr, nil, func(cursor *sqlparser.Cursor) bool {
+	expr = sqlparser.Rewrite(expr, pre, func(cursor *sqlparser.Cursor) bool {
 		switch node := cursor.Node().(type) {
 		case *sqlparser.Subquery:
 			t := getOpCodeFromParent(cursor.Parent())
 			if t == nil {
 				return true
 			}
-			replaceWithArg(cursor, node, *t)
-			sqe.pullOutCode = append(sqe.pullOutCode, *t)
+			replaceWithArg(cursor, node, *t, nil)
 		case *sqlparser.ExistsExpr:
-			replaceWithArg(cursor, node.Subquery, opcode.PulloutExists)
-			sqe.pullOutCode = append(sqe.pullOutCode, opcode.PulloutExists)
+			replaceWithArg(cursor, node.Subquery, opcode.PulloutExists, nil)
 		}
 		return true
 	}).(sqlparser.Expr)
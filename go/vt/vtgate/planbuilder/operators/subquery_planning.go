@@ -42,6 +42,11 @@ func isMergeable(ctx *plancontext.PlanningContext, query sqlparser.SelectStateme
 
 	switch node := query.(type) {
 	case *sqlparser.Select:
+		if !windowsPartitionedBy(node.SelectExprs, validVindex) {
+			// window functions need all the rows of a partition to be in the same shard
+			return false
+		}
+
 		if node.GroupBy != nil && len(node.GroupBy.Exprs) > 0 {
 			// iff we are grouping, we need to check that we can perform the grouping inside a single shard, and we check that
 			// by checking that one of the grouping expressions used is a unique single column vindex.
@@ -139,9 +144,13 @@ func rewriteMergedSubqueryExpr(ctx *plancontext.PlanningContext, se SubQueryExpr
 						return true
 					}
 					rewritten = true
-					if sq.FilterType == opcode.PulloutExists {
+					switch {
+					case sq.FilterType == opcode.PulloutExists:
 						cursor.Replace(&sqlparser.ExistsExpr{Subquery: sq.originalSubquery})
-					} else {
+					case sq.IsProjection && sq.comparison != nil && sq.FilterType.NeedsListArg():
+						// the column stands in for the whole [NOT] IN comparison
+						cursor.Replace(sq.originalComparison())
+					default:
 						cursor.Replace(sq.originalSubquery)
 					}
 					return false
@@ -206,6 +215,9 @@ func tryPushSubQueryInJoin(
 	for _, predicate := range inner.GetMergePredicates() {
 		deps = deps.Merge(ctx.SemTable.RecursiveDeps(predicate))
 	}
+	if inner.comparison != nil {
+		deps = deps.Merge(ctx.SemTable.RecursiveDeps(inner.comparison))
+	}
 	deps = deps.Remove(innerID)
 
 	// in general, we don't want to push down uncorrelated subqueries into the RHS of a join,
@@ -287,7 +299,7 @@ func tryMergeWithRHS(ctx *plancontext.PlanningContext, inner *SubQuery, outer *A
 		return nil, nil
 	}
 	innerRoute, ok := inner.Subquery.(*Route)
-	if !ok {
+	if !ok || len(inner.predicateSubqueries) > 0 {
 		return nil, nil
 	}
 
@@ -394,7 +406,7 @@ func pushOrMergeSubQueryContainer(ctx *plancontext.PlanningContext, in *SubQuery
 	var remaining []*SubQuery
 	var result *ApplyResult
 	for _, inner := range in.Inner {
-		newOuter, _result := pushOrMerge(ctx, in.Outer, inner)
+		newOuter, _result := pushOrMerge(ctx, in.Outer, inner, in.Inner)
 		if _result == NoRewrite {
 			remaining = append(remaining, inner)
 			continue
@@ -413,11 +425,49 @@ func pushOrMergeSubQueryContainer(ctx *plancontext.PlanningContext, in *SubQuery
 	return in, result
 }
 
+// tryMergeSubQuery tries to merge the subquery with the outer route. The other subqueries it is evaluated with are
+// needed to merge the subqueries used in the same predicate.
 func tryMergeSubQuery(
 	ctx *plancontext.PlanningContext,
 	subQuery *SubQuery,
 	outer *Route,
+	inners []*SubQuery,
 ) (newOuter Operator, result *ApplyResult) {
+	if subQuery.leader != nil {
+		return outer, mergeWithLeader(ctx, subQuery)
+	}
+	mergedBefore := len(ctx.MergedSubqueries)
+	newOuter, result = mergeSubQueryWithRoute(ctx, subQuery, outer)
+	if result == NoRewrite || len(subQuery.predicateSubqueries) == 0 {
+		return newOuter, result
+	}
+
+	// the predicate is sent to MySQL with all its subqueries, so the others have to be merged as well
+	op := newOuter.(*Route)
+	src := op.Source
+	for _, other := range subQuery.predicateSubqueries {
+		if other.ArgName == subQuery.ArgName || other.isMerged(ctx) {
+			continue
+		}
+		// the subqueries have been cloned while planning, so we look up the current version of the other subquery
+		idx := slices.IndexFunc(inners, func(sq *SubQuery) bool { return sq.ArgName == other.ArgName })
+		res := NoRewrite
+		var newOp Operator
+		if idx >= 0 {
+			newOp, res = mergeSubQueryWithRoute(ctx, inners[idx], op)
+		}
+		if res == NoRewrite {
+			ctx.MergedSubqueries = ctx.MergedSubqueries[:mergedBefore]
+			return outer, NoRewrite
+		}
+		op = newOp.(*Route)
+		result = result.Merge(res)
+	}
+	op.Source = src
+	return op, result
+}
+
+func mergeSubQueryWithRoute(ctx *plancontext.PlanningContext, subQuery *SubQuery, outer *Route) (Operator, *ApplyResult) {
 	switch inner := subQuery.Subquery.(type) {
 	case *Route:
 		return tryMergeSubqueryWithOuter(ctx, subQuery, outer, inner)
@@ -427,6 +477,15 @@ func tryMergeSubQuery(
 	return outer, NoRewrite
 }
 
+// mergeWithLeader handles a subquery that is used in the predicate of another subquery. It is merged
+// when the predicate is merged, and is otherwise pulled out together with the other subquery.
+func mergeWithLeader(ctx *plancontext.PlanningContext, subQuery *SubQuery) *ApplyResult {
+	if subQuery.leader.isMerged(ctx) {
+		return Rewrote("merged subquery as part of the predicate using it")
+	}
+	return NoRewrite
+}
+
 // tryMergeSubqueriesRecursively attempts to merge a SubQueryContainer with the outer Route.
 func tryMergeSubqueriesRecursively(
 	ctx *plancontext.PlanningContext,
@@ -449,7 +508,7 @@ func tryMergeSubqueriesRecursively(
 	op.Source = outer.Source
 	var finalResult *ApplyResult
 	for _, subq := range inner.Inner {
-		newOuter, res := tryMergeSubQuery(ctx, subq, op)
+		newOuter, res := tryMergeSubQuery(ctx, subq, op, inner.Inner)
 		if res == NoRewrite {
 			// we failed to merge one of the inners - we need to abort
 			return nil, NoRewrite
@@ -459,6 +518,8 @@ func tryMergeSubqueriesRecursively(
 	}
 
 	op.Source = newFilter(outer.Source, subQuery.Original)
+	// subqueries used in the same predicate as this one need to know that it has been merged
+	ctx.MergedSubqueries = append(ctx.MergedSubqueries, subQuery.originalSubquery)
 	return op, finalResult.Merge(Rewrote("merge outer of two subqueries"))
 }
 
@@ -494,11 +555,22 @@ func mergingIsBlocked(subQuery *SubQuery, updOp *Update) bool {
 	return false
 }
 
-func pushOrMerge(ctx *plancontext.PlanningContext, outer Operator, inner *SubQuery) (Operator, *ApplyResult) {
+func pushOrMerge(ctx *plancontext.PlanningContext, outer Operator, inner *SubQuery, inners []*SubQuery) (Operator, *ApplyResult) {
+	if inner.leader != nil {
+		return outer, mergeWithLeader(ctx, inner)
+	}
+	if inner.inOuterJoin {
+		// the join condition is using the arguments, so the subquery has to be evaluated before the join
+		return outer, NoRewrite
+	}
 	switch o := outer.(type) {
 	case *Route:
-		return tryMergeSubQuery(ctx, inner, o)
+		return tryMergeSubQuery(ctx, inner, o, inners)
 	case *ApplyJoin:
+		if len(inner.predicateSubqueries) > 0 {
+			// the other subqueries of the predicate stay here, so this one does as well
+			return outer, NoRewrite
+		}
 		join, applyResult := tryPushSubQueryInJoin(ctx, inner, o)
 		if join == nil {
 			return outer, NoRewrite
@@ -0,0 +1,487 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operators
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"vitess.io/vitess/go/slice"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
+	"vitess.io/vitess/go/vt/vtgate/planbuilder/plancontext"
+)
+
+type (
+	// Window evaluates window functions over the rows coming from its source.
+	// All the window functions of a query have to share the same PARTITION BY and ORDER BY.
+	// When the window can't be pushed down to MySQL, the input needs to be sorted
+	// on the partition and order columns, and the functions are evaluated at the vtgate level.
+	Window struct {
+		Source  Operator
+		Columns []*sqlparser.AliasedExpr
+
+		PartitionBy []sqlparser.Expr
+		OrderBy     []OrderBy
+		Functions   []WindowFunc
+
+		// the offsets of the partition and order expressions, and of their weight strings, in the input
+		PartitionOffsets   []int
+		PartitionWSOffsets []int
+		OrderOffsets       []int
+		OrderWSOffsets     []int
+
+		// Pushed will be set to true once this window has been pushed under a route
+		Pushed        bool
+		offsetPlanned bool
+
+		ResultColumns int
+	}
+
+	// WindowFunc is a single window function call evaluated by the Window operator
+	WindowFunc struct {
+		Original *sqlparser.AliasedExpr
+		Func     sqlparser.Expr
+		Spec     *sqlparser.WindowSpecification
+		OpCode   opcode.WindowOpcode
+
+		// ColOffset is the offset of the function in the output of the Window.
+		// Before evaluating the function, the same offset holds its argument.
+		ColOffset int
+	}
+)
+
+// newWindow creates a Window operator for all the window functions used in
+// the SELECT expressions and the ORDER BY of a query
+func newWindow(ctx *plancontext.PlanningContext, qp *QueryProjection, src Operator) *Window {
+	w := &Window{Source: src}
+	for _, expr := range qp.SelectExprs {
+		if !expr.Window {
+			continue
+		}
+		ae, err := expr.GetAliasedExpr()
+		if err != nil {
+			panic(err)
+		}
+		w.addWindowFuncs(ctx, ae)
+	}
+	for _, order := range qp.OrderExprs {
+		w.addWindowFuncs(ctx, aeWrap(order.SimplifiedExpr))
+	}
+	return w
+}
+
+func (w *Window) addWindowFuncs(ctx *plancontext.PlanningContext, ae *sqlparser.AliasedExpr) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, isSubq := node.(*sqlparser.Subquery); isSubq {
+			return false, nil
+		}
+		over := sqlparser.GetOverClause(node)
+		if over == nil {
+			return true, nil
+		}
+		expr := node.(sqlparser.Expr)
+		if _, found := canReuseColumn(ctx, w.Columns, expr, extractExpr); found {
+			return false, nil
+		}
+
+		col := aeWrap(expr)
+		if ae.Expr == expr {
+			// the window function is the whole select expression, so we keep the alias
+			col = ae
+		}
+		w.addWindowFunc(ctx, col, over)
+		return false, nil
+	}, ae.Expr)
+}
+
+func (w *Window) addWindowFunc(ctx *plancontext.PlanningContext, col *sqlparser.AliasedExpr, over *sqlparser.OverClause) {
+	spec := over.WindowSpec
+	if spec == nil || !over.WindowName.IsEmpty() || !spec.Name.IsEmpty() {
+		panic(vterrors.VT13001(fmt.Sprintf("named windows should not reach the planner: %s", sqlparser.String(col.Expr))))
+	}
+
+	if len(w.Functions) == 0 {
+		w.PartitionBy = spec.PartitionClause
+		w.OrderBy = slice.Map(spec.OrderClause, func(order *sqlparser.Order) OrderBy {
+			return OrderBy{
+				Inner:          order,
+				SimplifiedExpr: order.Expr,
+			}
+		})
+	} else if !w.sameWindow(ctx, spec) {
+		panic(vterrors.VT12001("window functions with different PARTITION BY or ORDER BY clauses on a sharded keyspace"))
+	}
+
+	w.Functions = append(w.Functions, WindowFunc{
+		Original:  col,
+		Func:      col.Expr,
+		Spec:      spec,
+		OpCode:    windowOpcode(col.Expr),
+		ColOffset: len(w.Columns),
+	})
+	w.Columns = append(w.Columns, col)
+}
+
+// sameWindow returns true if the window specification partitions and sorts rows the same way as this operator
+func (w *Window) sameWindow(ctx *plancontext.PlanningContext, spec *sqlparser.WindowSpecification) bool {
+	if len(spec.PartitionClause) != len(w.PartitionBy) || len(spec.OrderClause) != len(w.OrderBy) {
+		return false
+	}
+	for i, expr := range spec.PartitionClause {
+		if !ctx.SemTable.EqualsExprWithDeps(expr, w.PartitionBy[i]) {
+			return false
+		}
+	}
+	for i, order := range spec.OrderClause {
+		if order.Direction != w.OrderBy[i].Inner.Direction ||
+			!ctx.SemTable.EqualsExprWithDeps(order.Expr, w.OrderBy[i].SimplifiedExpr) {
+			return false
+		}
+	}
+	return true
+}
+
+func windowOpcode(expr sqlparser.Expr) opcode.WindowOpcode {
+	if distinct, ok := expr.(sqlparser.DistinctableAggr); ok && distinct.IsDistinct() {
+		panic(vterrors.VT12001(fmt.Sprintf("DISTINCT in window function: %s", sqlparser.String(expr))))
+	}
+
+	switch expr := expr.(type) {
+	case *sqlparser.ArgumentLessWindowExpr:
+		switch expr.Type {
+		case sqlparser.CumeDistExprType:
+			return opcode.WindowCumeDist
+		case sqlparser.DenseRankExprType:
+			return opcode.WindowDenseRank
+		case sqlparser.PercentRankExprType:
+			return opcode.WindowPercentRank
+		case sqlparser.RankExprType:
+			return opcode.WindowRank
+		case sqlparser.RowNumberExprType:
+			return opcode.WindowRowNumber
+		}
+	case *sqlparser.NtileExpr:
+		return opcode.WindowNtile
+	case *sqlparser.LagLeadExpr:
+		if expr.NullTreatmentClause == nil || expr.NullTreatmentClause.Type == sqlparser.RespectNullsType {
+			if expr.Type == sqlparser.LagExprType {
+				return opcode.WindowLag
+			}
+			return opcode.WindowLead
+		}
+	case *sqlparser.FirstOrLastValueExpr:
+		if expr.NullTreatmentClause == nil || expr.NullTreatmentClause.Type == sqlparser.RespectNullsType {
+			if expr.Type == sqlparser.FirstValueExprType {
+				return opcode.WindowFirstValue
+			}
+			return opcode.WindowLastValue
+		}
+	case *sqlparser.NTHValueExpr:
+		if (expr.NullTreatmentClause == nil || expr.NullTreatmentClause.Type == sqlparser.RespectNullsType) &&
+			(expr.FromFirstLastClause == nil || expr.FromFirstLastClause.Type == sqlparser.FromFirstType) {
+			return opcode.WindowNthValue
+		}
+	case *sqlparser.CountStar:
+		return opcode.WindowCountStar
+	case *sqlparser.Count:
+		if len(expr.Args) == 1 {
+			return opcode.WindowCount
+		}
+	case *sqlparser.Sum:
+		return opcode.WindowSum
+	case *sqlparser.Min:
+		return opcode.WindowMin
+	case *sqlparser.Max:
+		return opcode.WindowMax
+	case *sqlparser.Avg:
+		return opcode.WindowAvg
+	}
+	panic(vterrors.VT12001(fmt.Sprintf("window function on a sharded keyspace: %s", sqlparser.String(expr))))
+}
+
+// getPushColumn returns the expression the window function needs from its input
+func (wf WindowFunc) getPushColumn() sqlparser.Expr {
+	switch fn := wf.Func.(type) {
+	case *sqlparser.LagLeadExpr:
+		return fn.Expr
+	case *sqlparser.FirstOrLastValueExpr:
+		return fn.Expr
+	case *sqlparser.NTHValueExpr:
+		return fn.Expr
+	case *sqlparser.CountStar:
+		return sqlparser.NewIntLiteral("1")
+	case sqlparser.AggrFunc:
+		return fn.GetArg()
+	default:
+		// ranking functions don't use any value from the input,
+		// but we still need a column to write the result into
+		return sqlparser.NewIntLiteral("1")
+	}
+}
+
+// windowsPartitionedBy returns true if every window function found in the expression
+// is partitioned by at least one expression accepted by the given function.
+// Such windows can be evaluated independently on every shard.
+func windowsPartitionedBy(e sqlparser.SQLNode, f func(sqlparser.Expr) bool) bool {
+	result := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		if _, isSubq := node.(*sqlparser.Subquery); isSubq {
+			return false, nil
+		}
+		over := sqlparser.GetOverClause(node)
+		if over == nil {
+			return true, nil
+		}
+		if over.WindowSpec == nil || !slices.ContainsFunc(over.WindowSpec.PartitionClause, f) {
+			result = false
+			return false, io.EOF
+		}
+		return false, nil
+	}, e)
+	return result
+}
+
+func (w *Window) Clone(inputs []Operator) Operator {
+	kopy := *w
+	kopy.Source = inputs[0]
+	kopy.Columns = slices.Clone(w.Columns)
+	kopy.PartitionBy = slices.Clone(w.PartitionBy)
+	kopy.OrderBy = slices.Clone(w.OrderBy)
+	kopy.Functions = slices.Clone(w.Functions)
+	kopy.PartitionOffsets = slices.Clone(w.PartitionOffsets)
+	kopy.PartitionWSOffsets = slices.Clone(w.PartitionWSOffsets)
+	kopy.OrderOffsets = slices.Clone(w.OrderOffsets)
+	kopy.OrderWSOffsets = slices.Clone(w.OrderWSOffsets)
+	return &kopy
+}
+
+func (w *Window) Inputs() []Operator {
+	return []Operator{w.Source}
+}
+
+func (w *Window) SetInputs(operators []Operator) {
+	if len(operators) != 1 {
+		panic(fmt.Sprintf("unexpected number of operators as input in window: %d", len(operators)))
+	}
+	w.Source = operators[0]
+}
+
+func (w *Window) AddPredicate(_ *plancontext.PlanningContext, expr sqlparser.Expr) Operator {
+	// filtering the input would change the result of the window functions
+	return newFilter(w, expr)
+}
+
+func (w *Window) addColumnWithoutPushing(ctx *plancontext.PlanningContext, expr *sqlparser.AliasedExpr, _ bool) int {
+	if w.offsetPlanned {
+		// once the offsets are planned, the columns of the input have to stay aligned with ours
+		return w.AddColumn(ctx, false, false, expr)
+	}
+	offset := len(w.Columns)
+	w.Columns = append(w.Columns, expr)
+	return offset
+}
+
+func (w *Window) addColumnsWithoutPushing(ctx *plancontext.PlanningContext, reuse bool, groupby []bool, exprs []*sqlparser.AliasedExpr) (offsets []int) {
+	for i, ae := range exprs {
+		if reuse {
+			if offset := w.FindCol(ctx, ae.Expr, true); offset >= 0 {
+				offsets = append(offsets, offset)
+				continue
+			}
+		}
+		offsets = append(offsets, w.addColumnWithoutPushing(ctx, ae, groupby[i]))
+	}
+	return
+}
+
+func (w *Window) derivedName() string {
+	return ""
+}
+
+func (w *Window) FindCol(ctx *plancontext.PlanningContext, expr sqlparser.Expr, _ bool) int {
+	if offset, found := canReuseColumn(ctx, w.Columns, expr, extractExpr); found {
+		return offset
+	}
+	return -1
+}
+
+func (w *Window) AddColumn(ctx *plancontext.PlanningContext, reuse bool, groupBy bool, ae *sqlparser.AliasedExpr) int {
+	if w.Pushed {
+		// under a route, the columns are only used to build the query sent to the tablets
+		return w.addColumnsWithoutPushing(ctx, reuse, []bool{groupBy}, []*sqlparser.AliasedExpr{ae})[0]
+	}
+
+	// the input needs to have the same columns as the window, so
+	// the offsets have to be planned before adding anything new
+	w.planOffsets(ctx)
+
+	if reuse {
+		if offset := w.FindCol(ctx, ae.Expr, false); offset >= 0 {
+			return offset
+		}
+	}
+
+	if sqlparser.ContainsWindowFunc(ae.Expr) {
+		if ws, ok := ae.Expr.(*sqlparser.WeightStringFuncExpr); ok {
+			// the result of the window function is sorted or grouped on, but its type is not known
+			panic(vterrors.VT12001(fmt.Sprintf("weight_string of a window function result: %s", sqlparser.String(ws.Expr))))
+		}
+		panic(vterrors.VT13001(fmt.Sprintf("window function not planned: %s", sqlparser.String(ae.Expr))))
+	}
+
+	offset := len(w.Columns)
+	w.Columns = append(w.Columns, ae)
+	incomingOffset := w.Source.AddColumn(ctx, false, groupBy, ae)
+	if offset != incomingOffset {
+		panic(errFailedToPlan(ae))
+	}
+	return offset
+}
+
+func (w *Window) AddWSColumn(ctx *plancontext.PlanningContext, offset int, underRoute bool) int {
+	if len(w.Columns) <= offset {
+		panic(vterrors.VT13001("offset out of range"))
+	}
+
+	expr := w.Columns[offset].Expr
+	if w.isFunctionColumn(offset) {
+		panic(vterrors.VT12001(fmt.Sprintf("weight_string of a window function result: %s", sqlparser.String(expr))))
+	}
+
+	wsExpr := weightStringFor(expr)
+	if wsOffset := w.FindCol(ctx, wsExpr, underRoute); wsOffset >= 0 {
+		return wsOffset
+	}
+
+	if !underRoute {
+		w.planOffsets(ctx)
+	}
+
+	wsAe := aeWrap(wsExpr)
+	wsOffset := len(w.Columns)
+	w.Columns = append(w.Columns, wsAe)
+	if underRoute {
+		// if we are under a route, we are done here.
+		// the column will be use when creating the query to send to the tablet, and that is all we need
+		return wsOffset
+	}
+
+	incomingOffset := w.Source.AddWSColumn(ctx, offset, false)
+	if wsOffset != incomingOffset {
+		panic(errFailedToPlan(wsAe))
+	}
+	return wsOffset
+}
+
+func (w *Window) isFunctionColumn(offset int) bool {
+	return slices.ContainsFunc(w.Functions, func(wf WindowFunc) bool {
+		return wf.ColOffset == offset
+	})
+}
+
+func (w *Window) GetColumns(*plancontext.PlanningContext) []*sqlparser.AliasedExpr {
+	return w.Columns
+}
+
+func (w *Window) GetSelectExprs(ctx *plancontext.PlanningContext) sqlparser.SelectExprs {
+	return transformColumnsToSelectExprs(ctx, w)
+}
+
+func (w *Window) GetOrdering(ctx *plancontext.PlanningContext) []OrderBy {
+	return w.Source.GetOrdering(ctx)
+}
+
+func (w *Window) ShortDescription() string {
+	funcs := slice.Map(w.Functions, func(wf WindowFunc) string {
+		return sqlparser.String(wf.Func)
+	})
+	desc := strings.Join(funcs, ", ")
+	if len(w.PartitionBy) > 0 {
+		desc += " partition by " + sqlparser.String(sqlparser.Exprs(w.PartitionBy))
+	}
+	if len(w.OrderBy) > 0 {
+		order := slice.Map(w.OrderBy, func(o OrderBy) string {
+			return sqlparser.String(o.Inner)
+		})
+		desc += " order by " + strings.Join(order, ", ")
+	}
+	if w.Pushed {
+		desc = "pushed " + desc
+	}
+	return desc
+}
+
+func (w *Window) planOffsets(ctx *plancontext.PlanningContext) Operator {
+	if w.offsetPlanned || w.Pushed {
+		return nil
+	}
+	w.offsetPlanned = true
+
+	w.Source = newAliasedProjection(w.Source)
+	// we need to keep things in the column order, since the results of the
+	// window functions are written over the values of their arguments
+	for colIdx, col := range w.Columns {
+		ae := col
+		if idx := slices.IndexFunc(w.Functions, func(wf WindowFunc) bool { return wf.ColOffset == colIdx }); idx >= 0 {
+			ae = aeWrap(w.Functions[idx].getPushColumn())
+		}
+		offset := w.Source.AddColumn(ctx, false, false, ae)
+		if offset != colIdx {
+			panic(errFailedToPlan(col))
+		}
+	}
+
+	for _, expr := range w.PartitionBy {
+		offset, wsOffset := w.addKeyColumn(ctx, expr)
+		w.PartitionOffsets = append(w.PartitionOffsets, offset)
+		w.PartitionWSOffsets = append(w.PartitionWSOffsets, wsOffset)
+	}
+	for _, order := range w.OrderBy {
+		offset, wsOffset := w.addKeyColumn(ctx, order.SimplifiedExpr)
+		w.OrderOffsets = append(w.OrderOffsets, offset)
+		w.OrderWSOffsets = append(w.OrderWSOffsets, wsOffset)
+	}
+	return nil
+}
+
+// addKeyColumn adds a column used to find partitions and peer rows,
+// and its weight string if it needs one to be compared
+func (w *Window) addKeyColumn(ctx *plancontext.PlanningContext, expr sqlparser.Expr) (offset, wsOffset int) {
+	offset = w.internalAddColumn(ctx, aeWrap(expr))
+	if !ctx.SemTable.NeedsWeightString(expr) {
+		return offset, -1
+	}
+	wsOffset = w.internalAddColumn(ctx, aeWrap(weightStringFor(expr)))
+	return offset, wsOffset
+}
+
+func (w *Window) internalAddColumn(ctx *plancontext.PlanningContext, ae *sqlparser.AliasedExpr) int {
+	offset := w.Source.AddColumn(ctx, true, false, ae)
+	if offset == len(w.Columns) {
+		// if we get an offset at the end of our current column list, it means we added a new column
+		w.Columns = append(w.Columns, ae)
+	}
+	return offset
+}
+
+func (w *Window) setTruncateColumnCount(offset int) {
+	w.ResultColumns = offset
+}
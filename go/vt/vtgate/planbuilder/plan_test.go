@@ -294,6 +294,44 @@ func (s *planTestSuite) TestViews() {
 	s.testFile("view_cases.json", vschemaWrapper, false)
 }
 
+// TestVExplainKeys checks the column usage reported by VEXPLAIN KEYS
+func (s *planTestSuite) TestVExplainKeys() {
+	vschemaWrapper := &vschemawrapper.VSchemaWrapper{
+		V:             loadSchema(s.T(), "vschemas/schema.json", true),
+		TabletType_:   topodatapb.TabletType_PRIMARY,
+		SysVarEnabled: true,
+		TestBuilder:   TestBuilder,
+		Env:           vtenv.NewTestEnv(),
+	}
+
+	var tcases []struct {
+		Comment string          `json:"comment"`
+		Query   string          `json:"query"`
+		Keys    json.RawMessage `json:"keys"`
+	}
+	file, err := os.Open(locateFile("vexplain_keys_cases.json"))
+	require.NoError(s.T(), err)
+	defer file.Close()
+	dec := json.NewDecoder(file)
+	dec.DisallowUnknownFields()
+	require.NoError(s.T(), dec.Decode(&tcases))
+
+	opts := jsondiff.DefaultConsoleOptions()
+	for _, tcase := range tcases {
+		s.T().Run(tcase.Comment, func(t *testing.T) {
+			plan, err := TestBuilder("vexplain keys "+tcase.Query, vschemaWrapper, vschemaWrapper.CurrentDb())
+			require.NoError(t, err)
+			qr, err := plan.Instructions.TryExecute(context.Background(), nil, nil, true)
+			require.NoError(t, err)
+			require.Len(t, qr.Rows, 1)
+
+			out := qr.Rows[0][0].ToString()
+			compare, diff := jsondiff.Compare(tcase.Keys, []byte(out), &opts)
+			require.Equal(t, jsondiff.FullMatch, compare, "%s\n%s", diff, out)
+		})
+	}
+}
+
 func (s *planTestSuite) TestOne() {
 	reset := operators.EnableDebugPrinting()
 	defer reset()
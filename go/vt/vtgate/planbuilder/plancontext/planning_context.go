@@ -51,6 +51,10 @@ type PlanningContext struct {
 	// Projected subqueries that have been merged
 	MergedSubqueries []*sqlparser.Subquery
 
+	// RecurseCTEBindVars holds the names of the bind variables used to send the columns
+	// of the previous iteration of a recursive CTE to its recursive part
+	RecurseCTEBindVars map[*semantics.RecursiveCTE][]string
+
 	// CurrentPhase keeps track of how far we've gone in the planning process
 	// The type should be operators.Phase, but depending on that would lead to circular dependencies
 	CurrentPhase int
@@ -82,14 +86,15 @@ func CreatePlanningContext(stmt sqlparser.Statement,
 	vschema.PlannerWarning(semTable.Warning)
 
 	return &PlanningContext{
-		ReservedVars:      reservedVars,
-		SemTable:          semTable,
-		VSchema:           vschema,
-		joinPredicates:    map[sqlparser.Expr][]sqlparser.Expr{},
-		skipPredicates:    map[sqlparser.Expr]any{},
-		PlannerVersion:    version,
-		ReservedArguments: map[sqlparser.Expr]string{},
-		Statement:         stmt,
+		ReservedVars:       reservedVars,
+		SemTable:           semTable,
+		VSchema:            vschema,
+		joinPredicates:     map[sqlparser.Expr][]sqlparser.Expr{},
+		skipPredicates:     map[sqlparser.Expr]any{},
+		PlannerVersion:     version,
+		ReservedArguments:  map[sqlparser.Expr]string{},
+		Statement:          stmt,
+		RecurseCTEBindVars: map[*semantics.RecursiveCTE][]string{},
 	}, nil
 }
 
@@ -80,6 +80,11 @@ func (r *rewriter) rewriteHavingClause(node *sqlparser.Select) {
 	if node.Having == nil {
 		return
 	}
+	if node.GroupBy != nil && node.GroupBy.WithRollup {
+		// the super-aggregate rows produced by WITH ROLLUP have NULL in the grouping columns,
+		// so the predicates have to be evaluated after the rollup, and can't be moved to the WHERE clause
+		return
+	}
 
 	// for each expression in the having clause, we check if it contains aggregation.
 	// if it does, we keep the expression in the having clause ; and if it does not
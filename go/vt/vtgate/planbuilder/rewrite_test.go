@@ -72,6 +72,9 @@ func TestHavingRewrite(t *testing.T) {
 	}, {
 		input:  "select 1 from t1 group by a having a = 1 and count(*) > 1",
 		output: "select 1 from t1 where a = 1 group by a having count(*) > 1",
+	}, {
+		input:  "select 1 from t1 group by a with rollup having a = 1 and count(*) > 1",
+		output: "select 1 from t1 group by a with rollup having a = 1 and count(*) > 1",
 	}}
 	for _, tcase := range tcases {
 		t.Run(tcase.input, func(t *testing.T) {
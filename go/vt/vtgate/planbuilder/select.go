@@ -19,6 +19,7 @@ package planbuilder
 import (
 	"fmt"
 
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/key"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -66,7 +67,17 @@ func gen4SelectStmtPlanner(
 		return newBuildSelectPlan(selStatement, reservedVars, vschema, plannerVersion)
 	}
 
+	// comparisons using ANY, SOME or ALL are only planned as they are if the subquery can be merged with the
+	// outer query. We keep a copy of the statement to rewrite, since planning modifies the statement we pass in.
+	var anyAllStmt sqlparser.SelectStatement
+	if sqlparser.ContainsAnyAllComparison(stmt) {
+		anyAllStmt = sqlparser.CloneSelectStatement(stmt)
+	}
+
 	plan, tablesUsed, err := getPlan(stmt)
+	if err != nil && anyAllStmt != nil {
+		plan, tablesUsed, err = gen4AnyAllRewrite(anyAllStmt, vschema, getPlan, err)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -194,6 +205,68 @@ func gen4PredicateRewrite(stmt sqlparser.Statement, getPlan func(selStatement sq
 	return nil, nil
 }
 
+// gen4AnyAllRewrite plans the statement where the ANY, SOME and ALL comparisons have been rewritten into
+// IN, NOT IN or aggregations over the subqueries, which can be evaluated by vtgate. If this fails as well,
+// the error of the original statement is returned.
+func gen4AnyAllRewrite(
+	stmt sqlparser.SelectStatement,
+	vschema plancontext.VSchema,
+	getPlan func(selStatement sqlparser.SelectStatement) (engine.Primitive, []string, error),
+	origErr error,
+) (engine.Primitive, []string, error) {
+	ksName := ""
+	if ks, _ := vschema.DefaultKeyspace(); ks != nil {
+		ksName = ks.Name
+	}
+	// the types of the values compared decide whether the comparisons can be rewritten into aggregations
+	semTable, err := semantics.Analyze(stmt, ksName, vschema)
+	if err != nil {
+		return nil, nil, origErr
+	}
+	rewritten := sqlparser.RewriteAnyAllComparisons(stmt, func(cmp *sqlparser.ComparisonExpr) bool {
+		return comparesAsColumnType(semTable, cmp)
+	})
+	if rewritten == nil {
+		return nil, nil, origErr
+	}
+	plan, tablesUsed, err := getPlan(rewritten)
+	if err != nil {
+		return nil, nil, origErr
+	}
+	return plan, tablesUsed, nil
+}
+
+// comparesAsColumnType returns true if the ANY, SOME or ALL comparison compares the rows of the subquery
+// the same way as MIN and MAX over its column do. MySQL compares the values using the type both sides are
+// coerced to, so when the types or collations differ, the smallest or largest row could be a different one.
+func comparesAsColumnType(semTable *semantics.SemTable, cmp *sqlparser.ComparisonExpr) bool {
+	subq, ok := cmp.Right.(*sqlparser.Subquery)
+	if !ok {
+		return false
+	}
+	sel, ok := subq.Select.(*sqlparser.Select)
+	if !ok || len(sel.SelectExprs) != 1 {
+		return false
+	}
+	ae, ok := sel.SelectExprs[0].(*sqlparser.AliasedExpr)
+	if !ok {
+		return false
+	}
+	left, leftKnown := semTable.TypeForExpr(cmp.Left)
+	right, rightKnown := semTable.TypeForExpr(ae.Expr)
+	if !leftKnown || !rightKnown {
+		return false
+	}
+	switch {
+	case sqltypes.IsNumber(left.Type()) && sqltypes.IsNumber(right.Type()):
+		return true
+	case sqltypes.IsText(left.Type()) && sqltypes.IsText(right.Type()):
+		return left.Collation() == right.Collation()
+	default:
+		return left.Type() == right.Type() && left.Collation() == right.Collation()
+	}
+}
+
 func newBuildSelectPlan(
 	selStmt sqlparser.SelectStatement,
 	reservedVars *sqlparser.ReservedVars,
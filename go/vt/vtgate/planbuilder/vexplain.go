@@ -35,10 +35,12 @@ import (
 
 func buildVExplainPlan(ctx context.Context, vexplainStmt *sqlparser.VExplainStmt, reservedVars *sqlparser.ReservedVars, vschema plancontext.VSchema, enableOnlineDDL, enableDirectDDL bool) (*planResult, error) {
 	switch vexplainStmt.Type {
-	case sqlparser.QueriesVExplainType, sqlparser.AllVExplainType:
+	case sqlparser.QueriesVExplainType, sqlparser.AllVExplainType, sqlparser.TraceVExplainType:
 		return buildVExplainLoggingPlan(ctx, vexplainStmt, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
 	case sqlparser.PlanVExplainType:
 		return buildVExplainVtgatePlan(ctx, vexplainStmt.Statement, reservedVars, vschema, enableOnlineDDL, enableDirectDDL)
+	case sqlparser.KeysVExplainType:
+		return buildVExplainKeysPlan(vexplainStmt.Statement, vschema)
 	}
 	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] unexpected vtexplain type: %s", vexplainStmt.Type.ToString())
 }
@@ -89,12 +91,27 @@ func buildVExplainVtgatePlan(ctx context.Context, explainStatement sqlparser.Sta
 		return nil, err
 	}
 	description := engine.PrimitiveToPlanDescription(innerInstruction.primitive)
-	output, err := json.MarshalIndent(description, "", "\t")
+	return getJSONResultPlan(description, "JSON")
+}
+
+// buildVExplainKeysPlan only runs the semantic analysis of the statement and does not plan it,
+// so it does not need any tablet
+func buildVExplainKeysPlan(statement sqlparser.Statement, vschema plancontext.VSchema) (*planResult, error) {
+	ctx, err := plancontext.CreatePlanningContext(statement, sqlparser.NewReservedVars("", sqlparser.BindVars{}), vschema, Gen4)
+	if err != nil {
+		return nil, err
+	}
+	result := operators.GetVExplainKeys(ctx, statement)
+	return getJSONResultPlan(result, "ColumnUsage")
+}
+
+func getJSONResultPlan(v any, colName string) (*planResult, error) {
+	output, err := json.MarshalIndent(v, "", "\t")
 	if err != nil {
 		return nil, err
 	}
 	fields := []*querypb.Field{
-		{Name: "JSON", Type: querypb.Type_VARCHAR},
+		{Name: colName, Type: querypb.Type_VARCHAR},
 	}
 	rows := []sqltypes.Row{
 		{
@@ -43,10 +43,13 @@ import (
 	"vitess.io/vitess/go/vt/log"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/engine"
 	"vitess.io/vitess/go/vt/vttls"
 )
 
@@ -72,6 +75,12 @@ var (
 	mysqlQueryTimeout             time.Duration
 	mysqlSlowConnectWarnThreshold time.Duration
 	mysqlConnBufferPooling        bool
+	mysqlServerAllowLocalInfile   bool
+	mysqlServerAllowCompression   bool
+	mysqlServerAllowZstd          bool
+	mysqlServerAllowBinlogDump    bool
+	mysqlServerBinlogDumpUsers    string
+	mysqlServerAllowQueryAttrs    bool
 
 	mysqlDefaultWorkloadName = "OLTP"
 	mysqlDefaultWorkload     int32
@@ -99,6 +108,12 @@ func registerPluginFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&mysqlConnWriteTimeout, "mysql_server_write_timeout", mysqlConnWriteTimeout, "connection write timeout")
 	fs.DurationVar(&mysqlQueryTimeout, "mysql_server_query_timeout", mysqlQueryTimeout, "mysql query timeout")
 	fs.BoolVar(&mysqlConnBufferPooling, "mysql-server-pool-conn-read-buffers", mysqlConnBufferPooling, "If set, the server will pool incoming connection read buffers")
+	fs.BoolVar(&mysqlServerAllowLocalInfile, "mysql-server-allow-local-infile", mysqlServerAllowLocalInfile, "If set, the server will accept LOAD DATA LOCAL INFILE statements and ask the client for the file.")
+	fs.BoolVar(&mysqlServerAllowCompression, "mysql-server-allow-compression", mysqlServerAllowCompression, "If set, clients can use the compressed protocol with zlib.")
+	fs.BoolVar(&mysqlServerAllowZstd, "mysql-server-allow-zstd-compression", mysqlServerAllowZstd, "If set, clients can use the compressed protocol with zstd.")
+	fs.BoolVar(&mysqlServerAllowQueryAttrs, "mysql-server-allow-query-attributes", mysqlServerAllowQueryAttrs, "If set, clients can send query attributes with their queries. WORKLOAD_NAME, QUERY_TIMEOUT_MS, PRIORITY and TABLET_TYPE are applied like the query directives.")
+	fs.BoolVar(&mysqlServerAllowBinlogDump, "mysql-server-allow-binlog-dump", mysqlServerAllowBinlogDump, "If set, replication clients can stream the changes of a keyspace from vtgate with COM_BINLOG_DUMP and COM_BINLOG_DUMP_GTID.")
+	fs.StringVar(&mysqlServerBinlogDumpUsers, "mysql-server-binlog-dump-authorized-users", mysqlServerBinlogDumpUsers, "List of users authorized to stream the changes of a keyspace with COM_BINLOG_DUMP and COM_BINLOG_DUMP_GTID, or '%' to allow all users.")
 	fs.DurationVar(&mysqlKeepAlivePeriod, "mysql-server-keepalive-period", mysqlKeepAlivePeriod, "TCP period between keep-alives")
 	fs.DurationVar(&mysqlServerFlushDelay, "mysql_server_flush_delay", mysqlServerFlushDelay, "Delay after which buffered response will be flushed to the client.")
 	fs.StringVar(&mysqlDefaultWorkloadName, "mysql_default_workload", mysqlDefaultWorkloadName, "Default session workload (OLTP, OLAP, DBA)")
@@ -238,6 +253,13 @@ func (vh *vtgateHandler) ComQuery(c *mysql.Conn, query string, callback func(*sq
 		c.RemoteAddr().String(), /* component: running client process */
 		"VTGate MySQL Connector" /* subcomponent: part of the client */)
 	ctx = callerid.NewContext(ctx, ef, im)
+	ctx = engine.WithLocalInfile(ctx, c.RequestLocalInfile)
+
+	qa, err := newQueryAttributes(c.QueryAttributes())
+	if err != nil {
+		return sqlerror.NewSQLErrorFromError(err)
+	}
+	ctx = withQueryAttributes(ctx, qa)
 
 	if !session.InTransaction {
 		vh.busyConnections.Add(1)
@@ -344,6 +366,12 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		"VTGate MySQL Connector" /* subcomponent: part of the client */)
 	ctx = callerid.NewContext(ctx, ef, im)
 
+	qa, err := newQueryAttributes(prepare.QueryAttributes)
+	if err != nil {
+		return sqlerror.NewSQLErrorFromError(err)
+	}
+	ctx = withQueryAttributes(ctx, qa)
+
 	session := vh.session(c)
 	if !session.InTransaction {
 		vh.busyConnections.Add(1)
@@ -354,7 +382,9 @@ func (vh *vtgateHandler) ComStmtExecute(c *mysql.Conn, prepare *mysql.PrepareDat
 		}
 	}()
 
-	if session.Options.Workload == querypb.ExecuteOptions_OLAP {
+	// With a cursor, the rows are fetched by the client as it goes, so
+	// the result is streamed instead of being buffered here.
+	if session.Options.Workload == querypb.ExecuteOptions_OLAP || prepare.CursorType&mysql.CursorTypeReadOnly != 0 {
 		_, err := vh.vtg.StreamExecute(ctx, vh, session, prepare.PrepareStmt, prepare.BindVars, callback)
 		if err != nil {
 			return sqlerror.NewSQLErrorFromError(err)
@@ -381,13 +411,91 @@ func (vh *vtgateHandler) ComRegisterReplica(c *mysql.Conn, replicaHost string, r
 }
 
 // ComBinlogDump is part of the mysql.Handler interface.
+// Binlog file positions can't be mapped to the shards of the keyspace, so only
+// dumps without a binlog file are accepted, and they start from the current position.
 func (vh *vtgateHandler) ComBinlogDump(c *mysql.Conn, logFile string, binlogPos uint32) error {
-	return vterrors.VT12001("ComBinlogDump for the VTGate handler")
+	if !mysqlServerAllowBinlogDump {
+		return vterrors.VT12001("ComBinlogDump for the VTGate handler")
+	}
+	if err := binlogDumpAuthorized(c); err != nil {
+		return err
+	}
+	if logFile != "" {
+		return vterrors.VT12001(fmt.Sprintf("binlog dump from the binlog file position %s:%d, use COM_BINLOG_DUMP_GTID instead", logFile, binlogPos))
+	}
+	return vh.binlogDump(c, nil)
 }
 
 // ComBinlogDumpGTID is part of the mysql.Handler interface.
 func (vh *vtgateHandler) ComBinlogDumpGTID(c *mysql.Conn, logFile string, logPos uint64, gtidSet replication.GTIDSet) error {
-	return vterrors.VT12001("ComBinlogDumpGTID for the VTGate handler")
+	if !mysqlServerAllowBinlogDump {
+		return vterrors.VT12001("ComBinlogDumpGTID for the VTGate handler")
+	}
+	if err := binlogDumpAuthorized(c); err != nil {
+		return err
+	}
+	var gtids replication.Mysql56GTIDSet
+	if gtidSet != nil {
+		var ok bool
+		if gtids, ok = gtidSet.(replication.Mysql56GTIDSet); !ok {
+			return vterrors.VT12001(fmt.Sprintf("ComBinlogDumpGTID with a %s GTID set", gtidSet.Flavor()))
+		}
+	}
+	return vh.binlogDump(c, gtids)
+}
+
+// binlogDumpAuthorized returns an error if the user of the connection is not
+// in the list of users that can stream the changes of a keyspace.
+func binlogDumpAuthorized(c *mysql.Conn) error {
+	user := c.UserData.Get().GetUsername()
+	for _, authorized := range strings.Split(mysqlServerBinlogDumpUsers, ",") {
+		authorized = strings.TrimSpace(authorized)
+		if authorized == "%" || (authorized != "" && authorized == user) {
+			return nil
+		}
+	}
+	return vterrors.NewErrorf(vtrpcpb.Code_PERMISSION_DENIED, vterrors.AccessDeniedError, "User '%s' is not authorized to stream binlog events", user)
+}
+
+// binlogDump streams the changes of the keyspace of the session as binlog
+// events, starting after the given GTID set, or from the current position
+// if it is empty. It only returns when the stream fails.
+func (vh *vtgateHandler) binlogDump(c *mysql.Conn, gtids replication.Mysql56GTIDSet) error {
+	session := vh.session(c)
+	keyspace, tabletType, dest, err := topoproto.ParseDestination(session.TargetString, defaultTabletType)
+	if err != nil {
+		return err
+	}
+	if keyspace == "" {
+		return vterrors.VT09005()
+	}
+	if dest != nil {
+		return vterrors.VT12001("binlog dump of a shard or a keyrange")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	c.UpdateCancelCtx(cancel)
+	ctx = callinfo.MysqlCallInfo(ctx, c)
+	im := c.UserData.Get()
+	ef := callerid.NewEffectiveCallerID(
+		c.User,                  /* principal: who */
+		c.RemoteAddr().String(), /* component: running client process */
+		"VTGate MySQL Connector" /* subcomponent: part of the client */)
+	ctx = callerid.NewContext(ctx, ef, im)
+
+	vgtid, shardPositions, gtids, err := vh.binlogDumpPositions(ctx, keyspace, tabletType, gtids)
+	if err != nil {
+		return err
+	}
+	bd := newBinlogDump(keyspace, vh.Env().MySQLVersion(), binlogDumpChecksum(session), gtids, shardPositions, func(ev mysql.BinlogEvent) error {
+		return c.WriteBinlogEvent(ev, false)
+	})
+	if err := bd.start(); err != nil {
+		return err
+	}
+	flags := &vtgatepb.VStreamFlags{HeartbeatInterval: binlogDumpHeartbeatInterval}
+	return vh.vtg.VStream(ctx, tabletType, vgtid, nil, flags, bd.handle)
 }
 
 // KillConnection closes an open connection by connection ID.
@@ -548,6 +656,11 @@ func initMySQLProtocol(vtgate *VTGate) *mysqlServer {
 			_ = initTLSConfig(context.Background(), srv, mysqlSslCert, mysqlSslKey, mysqlSslCa, mysqlSslCrl, mysqlSslServerCA, mysqlServerRequireSecureTransport, tlsVersion)
 		}
 		srv.tcpListener.AllowClearTextWithoutTLS.Store(mysqlAllowClearTextWithoutTLS)
+		srv.tcpListener.AllowLocalInfile = mysqlServerAllowLocalInfile
+		srv.tcpListener.AllowCompression = mysqlServerAllowCompression
+		srv.tcpListener.AllowZstdCompression = mysqlServerAllowZstd
+		srv.tcpListener.AllowQueryAttributes = mysqlServerAllowQueryAttrs
+		srv.tcpListener.MaxCursorMemoryRows = maxMemoryRows
 		// Check for the connection threshold
 		if mysqlSlowConnectWarnThreshold != 0 {
 			log.Infof("setting mysql slow connection threshold to %v", mysqlSlowConnectWarnThreshold)
@@ -422,3 +422,31 @@ func TestGracefulShutdownWithTransaction(t *testing.T) {
 
 	require.True(t, mysqlConn.IsMarkedForClose())
 }
+
+func TestBinlogDumpAuthorization(t *testing.T) {
+	defer func(allow bool, users string) {
+		mysqlServerAllowBinlogDump = allow
+		mysqlServerBinlogDumpUsers = users
+	}(mysqlServerAllowBinlogDump, mysqlServerBinlogDumpUsers)
+	mysqlServerAllowBinlogDump = true
+
+	vh := newVtgateHandler(&VTGate{})
+	mysqlConn := mysql.GetTestConn()
+	mysqlConn.UserData = &mysql.StaticUserData{Username: "user1"}
+
+	mysqlServerBinlogDumpUsers = ""
+	err := vh.ComBinlogDumpGTID(mysqlConn, "", 4, nil)
+	require.ErrorContains(t, err, "User 'user1' is not authorized to stream binlog events")
+
+	mysqlServerBinlogDumpUsers = "user2, user3"
+	err = vh.ComBinlogDump(mysqlConn, "", 4)
+	require.ErrorContains(t, err, "User 'user1' is not authorized to stream binlog events")
+
+	mysqlServerBinlogDumpUsers = "user2, user1"
+	err = vh.ComBinlogDump(mysqlConn, "binlog.000003", 1234)
+	require.EqualError(t, err, "VT12001: unsupported: binlog dump from the binlog file position binlog.000003:1234, use COM_BINLOG_DUMP_GTID instead")
+
+	mysqlServerBinlogDumpUsers = "%"
+	err = vh.ComBinlogDump(mysqlConn, "binlog.000003", 1234)
+	require.ErrorContains(t, err, "VT12001: unsupported: binlog dump from the binlog file position")
+}
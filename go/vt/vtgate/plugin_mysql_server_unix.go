@@ -34,6 +34,11 @@ func setupUnixSocket(srv *mysqlServer, authServer mysql.AuthServer, path string)
 	if err != nil {
 		return err
 	}
+	srv.unixListener.AllowLocalInfile = mysqlServerAllowLocalInfile
+	srv.unixListener.AllowQueryAttributes = mysqlServerAllowQueryAttrs
+	srv.unixListener.AllowCompression = mysqlServerAllowCompression
+	srv.unixListener.AllowZstdCompression = mysqlServerAllowZstd
+	srv.unixListener.MaxCursorMemoryRows = maxMemoryRows
 	// Listen for unix socket
 	go srv.unixListener.Accept()
 	return nil
@@ -0,0 +1,49 @@
+//go:build !windows
+
+/*
+Copyright 2023 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetupUnixSocketListenerFlags(t *testing.T) {
+	oldLocalInfile, oldQueryAttrs := mysqlServerAllowLocalInfile, mysqlServerAllowQueryAttrs
+	defer func() {
+		mysqlServerAllowLocalInfile, mysqlServerAllowQueryAttrs = oldLocalInfile, oldQueryAttrs
+	}()
+	mysqlServerAllowLocalInfile = true
+	mysqlServerAllowQueryAttrs = true
+
+	unixSocket, err := os.CreateTemp("", "mysql_vitess_test.sock")
+	require.NoError(t, err)
+	os.Remove(unixSocket.Name())
+
+	executor, _, _, _, _ := createExecutorEnv(t)
+	srv := &mysqlServer{vtgateHandle: newVtgateHandler(&VTGate{executor: executor})}
+	err = setupUnixSocket(srv, newTestAuthServerStatic(), unixSocket.Name())
+	require.NoError(t, err)
+	defer srv.unixListener.Close()
+
+	assert.True(t, srv.unixListener.AllowLocalInfile)
+	assert.True(t, srv.unixListener.AllowQueryAttributes)
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/topo/topoproto"
+	"vitess.io/vitess/go/vt/vterrors"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Query attributes that map to Vitess directives. Their names are the names of the
+// directives, and they are matched case-insensitively. A directive in the comments
+// of the query takes precedence over the query attribute.
+const (
+	queryAttributeWorkloadName = sqlparser.DirectiveWorkloadName
+	queryAttributeQueryTimeout = sqlparser.DirectiveQueryTimeout
+	queryAttributePriority     = sqlparser.DirectivePriority
+	queryAttributeTabletType   = "TABLET_TYPE"
+)
+
+// queryAttributes are the query attributes a MySQL client sent with a query,
+// and the Vitess directives they map to.
+type queryAttributes struct {
+	values map[string]sqltypes.Value
+
+	workloadName string
+	priority     string
+	// queryTimeout is in milliseconds.
+	queryTimeout int
+	// tabletType is TabletType_UNKNOWN if the query attributes don't set it.
+	tabletType topodatapb.TabletType
+}
+
+// newQueryAttributes parses the well-known query attributes. It returns nil
+// if there are no query attributes.
+func newQueryAttributes(values map[string]sqltypes.Value) (*queryAttributes, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	qa := &queryAttributes{values: values}
+	for name, value := range values {
+		if value.IsNull() {
+			continue
+		}
+		switch strings.ToUpper(name) {
+		case queryAttributeWorkloadName:
+			qa.workloadName = value.ToString()
+		case queryAttributePriority:
+			priority, err := strconv.Atoi(value.ToString())
+			if err != nil || priority < 0 || priority > sqlparser.MaxPriorityValue {
+				return nil, sqlparser.ErrInvalidPriority
+			}
+			qa.priority = strconv.Itoa(priority)
+		case queryAttributeQueryTimeout:
+			timeout, err := strconv.Atoi(value.ToString())
+			if err != nil || timeout < 0 {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for query attribute %s: %s", name, value.ToString())
+			}
+			qa.queryTimeout = timeout
+		case queryAttributeTabletType:
+			tabletType, err := topoproto.ParseTabletType(value.ToString())
+			if err != nil {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid value for query attribute %s: %s", name, value.ToString())
+			}
+			qa.tabletType = tabletType
+		}
+	}
+	return qa, nil
+}
+
+// bindVariables returns the query attributes in the form logstats records them.
+func (qa *queryAttributes) bindVariables() map[string]*querypb.BindVariable {
+	if qa == nil {
+		return nil
+	}
+	bvs := make(map[string]*querypb.BindVariable, len(qa.values))
+	for name, value := range qa.values {
+		bvs[name] = sqltypes.ValueBindVariable(value)
+	}
+	return bvs
+}
+
+type queryAttributesKey struct{}
+
+// withQueryAttributes returns a context that carries the query attributes to the executor.
+func withQueryAttributes(ctx context.Context, qa *queryAttributes) context.Context {
+	if qa == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, queryAttributesKey{}, qa)
+}
+
+// queryAttributesFromContext returns the query attributes of the context, or nil.
+func queryAttributesFromContext(ctx context.Context) *queryAttributes {
+	qa, _ := ctx.Value(queryAttributesKey{}).(*queryAttributes)
+	return qa
+}
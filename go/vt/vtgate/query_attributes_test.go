@@ -0,0 +1,78 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/test/utils"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+)
+
+func TestNewQueryAttributes(t *testing.T) {
+	qa, err := newQueryAttributes(nil)
+	require.NoError(t, err)
+	assert.Nil(t, qa)
+
+	values := map[string]sqltypes.Value{
+		"workload_name":    sqltypes.NewVarBinary("reports"),
+		"Query_Timeout_MS": sqltypes.NewInt64(1500),
+		"PRIORITY":         sqltypes.NewVarBinary("10"),
+		"tablet_type":      sqltypes.NewVarBinary("replica"),
+		"trace_id":         sqltypes.NewVarBinary("abc"),
+	}
+	qa, err = newQueryAttributes(values)
+	require.NoError(t, err)
+	assert.Equal(t, "reports", qa.workloadName)
+	assert.Equal(t, 1500, qa.queryTimeout)
+	assert.Equal(t, "10", qa.priority)
+	assert.Equal(t, topodatapb.TabletType_REPLICA, qa.tabletType)
+	utils.MustMatch(t, sqltypes.BytesBindVariable([]byte("abc")), qa.bindVariables()["trace_id"])
+	assert.Len(t, qa.bindVariables(), 5)
+
+	// NULL attributes are ignored
+	qa, err = newQueryAttributes(map[string]sqltypes.Value{"tablet_type": sqltypes.NULL})
+	require.NoError(t, err)
+	assert.Equal(t, topodatapb.TabletType_UNKNOWN, qa.tabletType)
+	utils.MustMatch(t, map[string]*querypb.BindVariable{"tablet_type": sqltypes.NullBindVariable}, qa.bindVariables())
+
+	for _, values := range []map[string]sqltypes.Value{
+		{"priority": sqltypes.NewInt64(101)},
+		{"priority": sqltypes.NewVarBinary("high")},
+		{"query_timeout_ms": sqltypes.NewInt64(-1)},
+		{"tablet_type": sqltypes.NewVarBinary("leader")},
+	} {
+		_, err = newQueryAttributes(values)
+		assert.Error(t, err, "%v", values)
+	}
+}
+
+func TestQueryAttributesContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Nil(t, queryAttributesFromContext(ctx))
+	assert.Nil(t, queryAttributesFromContext(withQueryAttributes(ctx, nil)))
+
+	qa := &queryAttributes{workloadName: "reports"}
+	assert.Equal(t, qa, queryAttributesFromContext(withQueryAttributes(ctx, qa)))
+}
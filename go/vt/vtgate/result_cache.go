@@ -0,0 +1,484 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"encoding/binary"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"vitess.io/vitess/go/cache/theine"
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vthash"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// resultCacheRetryDelay is the time to wait before restarting the VStream of a
+// keyspace after it ended.
+var resultCacheRetryDelay = 5 * time.Second
+
+// keyspaceStreamer streams the changes of a keyspace, starting from its current
+// position, until the context is done or the stream fails.
+type keyspaceStreamer func(ctx context.Context, keyspace string, send func(events []*binlogdatapb.VEvent) error) error
+
+// resultCache caches the results of read-only queries, keyed by the plan key of
+// the query, its bind variables and the caller executing it. The table ACLs and
+// query rules of the tablets apply to the caller, so one caller is never served
+// a result read by another.
+//
+// A cached result is valid while none of the tables it was read from changed.
+// Every table has a generation, which is bumped by the row events of a VStream
+// of its keyspace, and by the DMLs that this vtgate executes on the table once
+// they are committed. A
+// result is stored with the generations of its tables from before the query was
+// executed, and is only served while they are unchanged, so results are at most
+// as stale as the VStream lags behind. Only results read from primary tablets
+// are cached, as the VStream follows the primaries, which replicas lag behind.
+// The results of a keyspace are not cached while its VStream is down, and
+// entries expire after a TTL regardless.
+type resultCache struct {
+	store *theine.Store[PlanCacheKey, *resultCacheEntry]
+	epoch atomic.Uint32
+	ttl   time.Duration
+
+	// tables are the tables whose results are cached without the RESULT_CACHE
+	// directive, either as keyspace.table or as a table name in any keyspace.
+	tables map[string]bool
+
+	streamer keyspaceStreamer
+	ctx      context.Context
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+
+	mu        sync.Mutex
+	keyspaces map[string]*resultCacheKeyspace
+}
+
+// resultCacheKeyspace tracks the generations of the tables of a keyspace.
+type resultCacheKeyspace struct {
+	name string
+	// live is set while the VStream of the keyspace is running.
+	live atomic.Bool
+
+	mu     sync.Mutex
+	tables map[string]*atomic.Uint64
+}
+
+// resultCacheEntry is a cached result, with the generations of the tables it was
+// read from.
+type resultCacheEntry struct {
+	result   *sqltypes.Result
+	versions []resultCacheVersion
+	expires  time.Time
+}
+
+type resultCacheVersion struct {
+	generation *atomic.Uint64
+	value      uint64
+}
+
+// CachedSize implements the cacheval interface of the theine store.
+func (entry *resultCacheEntry) CachedSize(alloc bool) int64 {
+	size := entry.result.CachedSize(true) + int64(cap(entry.versions))*16
+	if alloc {
+		size += int64(56)
+	}
+	return size
+}
+
+func newResultCache(memory int64, tables []string, ttl time.Duration, streamer keyspaceStreamer) *resultCache {
+	ctx, cancel := context.WithCancel(context.Background())
+	rc := &resultCache{
+		store:     theine.NewStore[PlanCacheKey, *resultCacheEntry](memory, false),
+		ttl:       ttl,
+		tables:    make(map[string]bool, len(tables)),
+		streamer:  streamer,
+		ctx:       ctx,
+		cancel:    cancel,
+		keyspaces: make(map[string]*resultCacheKeyspace),
+	}
+	for _, table := range tables {
+		rc.tables[strings.ToLower(table)] = true
+	}
+	return rc
+}
+
+// vstreamKeyspace returns a keyspaceStreamer that streams the changes of the
+// primaries of a keyspace through the vstreamManager.
+func vstreamKeyspace(vsm *vstreamManager) keyspaceStreamer {
+	return func(ctx context.Context, keyspace string, send func(events []*binlogdatapb.VEvent) error) error {
+		vgtid := &binlogdatapb.VGtid{
+			ShardGtids: []*binlogdatapb.ShardGtid{{
+				Keyspace: keyspace,
+				Gtid:     "current",
+			}},
+		}
+		// The heartbeats mark the stream as live when there are no changes.
+		flags := &vtgatepb.VStreamFlags{HeartbeatInterval: 1}
+		return vsm.VStream(ctx, topodatapb.TabletType_PRIMARY, vgtid, nil, flags, send)
+	}
+}
+
+// enabled returns whether the result of a query on the given tables is cached.
+// The RESULT_CACHE directive of the query takes precedence over the tables the
+// cache is configured with, all of which have to be cached otherwise.
+func (rc *resultCache) enabled(tables []string, directive, directiveSet bool) bool {
+	if directiveSet {
+		return directive
+	}
+	for _, table := range tables {
+		table = strings.ToLower(table)
+		_, name, _ := strings.Cut(table, ".")
+		if !rc.tables[table] && !rc.tables[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// key returns the cache key of a query with the given plan key and bind variables,
+// executed by the caller of the context.
+func (rc *resultCache) key(ctx context.Context, planKey PlanCacheKey, bindVars map[string]*querypb.BindVariable) (PlanCacheKey, error) {
+	names := make([]string, 0, len(bindVars))
+	for name := range bindVars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hasher := vthash.New256()
+	_, _ = hasher.Write(planKey[:])
+	effective, err := callerid.EffectiveCallerIDFromContext(ctx).MarshalVT()
+	if err != nil {
+		return PlanCacheKey{}, err
+	}
+	immediate, err := callerid.ImmediateCallerIDFromContext(ctx).MarshalVT()
+	if err != nil {
+		return PlanCacheKey{}, err
+	}
+	// The lengths keep the caller IDs from running into each other.
+	_, _ = hasher.Write(binary.AppendUvarint(nil, uint64(len(effective))))
+	_, _ = hasher.Write(effective)
+	_, _ = hasher.Write(binary.AppendUvarint(nil, uint64(len(immediate))))
+	_, _ = hasher.Write(immediate)
+	for _, name := range names {
+		bv, err := bindVars[name].MarshalVT()
+		if err != nil {
+			return PlanCacheKey{}, err
+		}
+		_, _ = hasher.WriteString(name)
+		_, _ = hasher.Write(bv)
+	}
+
+	var key PlanCacheKey
+	hasher.Sum(key[:0])
+	return key, nil
+}
+
+// get returns the cached result for the key, if it is still valid.
+func (rc *resultCache) get(key PlanCacheKey) (*sqltypes.Result, bool) {
+	entry, ok := rc.store.Get(key, rc.epoch.Load())
+	if !ok || !entry.valid() {
+		resultCacheMisses.Add(1)
+		return nil, false
+	}
+	resultCacheHits.Add(1)
+	return entry.result.Copy(), true
+}
+
+func (entry *resultCacheEntry) valid() bool {
+	if time.Now().After(entry.expires) {
+		return false
+	}
+	for _, version := range entry.versions {
+		if version.generation.Load() != version.value {
+			return false
+		}
+	}
+	return true
+}
+
+// versions returns the current generations of the given keyspace-qualified
+// tables, to store a result read from them after this call with. It returns
+// false if the result cannot be cached, because the VStream of a keyspace is
+// not running.
+func (rc *resultCache) versions(tables []string) ([]resultCacheVersion, bool) {
+	versions := make([]resultCacheVersion, 0, len(tables))
+	for _, table := range tables {
+		keyspace, name, ok := strings.Cut(table, ".")
+		if !ok {
+			return nil, false
+		}
+		rk := rc.keyspace(keyspace)
+		generation := rk.generation(name)
+		versions = append(versions, resultCacheVersion{generation: generation, value: generation.Load()})
+		// Check that the stream is live after reading the generation, so that the
+		// generation is bumped if the stream ends before the result is stored.
+		if !rk.live.Load() {
+			return nil, false
+		}
+	}
+	return versions, true
+}
+
+// set caches the result of a query, read after the given versions were taken.
+func (rc *resultCache) set(key PlanCacheKey, result *sqltypes.Result, versions []resultCacheVersion) {
+	rc.store.Set(key, &resultCacheEntry{
+		result:   result.Copy(),
+		versions: versions,
+		expires:  time.Now().Add(rc.ttl),
+	}, 0, rc.epoch.Load())
+}
+
+// invalidate invalidates the cached results of the given keyspace-qualified
+// tables, after this vtgate wrote to them.
+func (rc *resultCache) invalidate(tables []string) {
+	for _, table := range tables {
+		keyspace, name, ok := strings.Cut(table, ".")
+		if !ok {
+			continue
+		}
+		rc.mu.Lock()
+		rk := rc.keyspaces[keyspace]
+		rc.mu.Unlock()
+		if rk != nil {
+			rk.invalidate(name)
+			resultCacheInvalidations.Add("DML", 1)
+		}
+	}
+}
+
+// clear invalidates all cached results.
+func (rc *resultCache) clear() {
+	rc.epoch.Add(1)
+}
+
+// keyspace returns the generations of a keyspace, starting to stream its
+// changes the first time it is called for the keyspace.
+func (rc *resultCache) keyspace(name string) *resultCacheKeyspace {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	rk, ok := rc.keyspaces[name]
+	if !ok {
+		rk = &resultCacheKeyspace{
+			name:   name,
+			tables: make(map[string]*atomic.Uint64),
+		}
+		rc.keyspaces[name] = rk
+		if rc.ctx.Err() == nil {
+			rc.wg.Add(1)
+			go rc.watch(rk)
+		}
+	}
+	return rk
+}
+
+// watch streams the changes of a keyspace, invalidating the cached results of
+// its tables, until the cache is closed.
+func (rc *resultCache) watch(rk *resultCacheKeyspace) {
+	defer rc.wg.Done()
+	for {
+		err := rc.streamer(rc.ctx, rk.name, func(events []*binlogdatapb.VEvent) error {
+			rk.live.Store(true)
+			for _, event := range events {
+				switch event.Type {
+				case binlogdatapb.VEventType_ROW:
+					_, table, _ := strings.Cut(event.RowEvent.TableName, ".")
+					rk.invalidate(table)
+					resultCacheInvalidations.Add("VStream", 1)
+				case binlogdatapb.VEventType_DDL:
+					rk.invalidateAll()
+					resultCacheInvalidations.Add("DDL", 1)
+				}
+			}
+			return nil
+		})
+
+		// Changes are missed until the stream is restarted, so every result of
+		// the keyspace that is cached by now may become stale.
+		rk.live.Store(false)
+		rk.invalidateAll()
+		if rc.ctx.Err() != nil {
+			return
+		}
+		log.Warningf("Result cache VStream of keyspace %s ended, restarting in %v: %v", rk.name, resultCacheRetryDelay, err)
+		resultCacheInvalidations.Add("StreamEnded", 1)
+		select {
+		case <-rc.ctx.Done():
+			return
+		case <-time.After(resultCacheRetryDelay):
+		}
+	}
+}
+
+// Close stops streaming the changes of the keyspaces and closes the cache.
+func (rc *resultCache) Close() {
+	rc.cancel()
+	rc.wg.Wait()
+	rc.store.Close()
+}
+
+func (rk *resultCacheKeyspace) generation(table string) *atomic.Uint64 {
+	rk.mu.Lock()
+	defer rk.mu.Unlock()
+	generation, ok := rk.tables[table]
+	if !ok {
+		generation = &atomic.Uint64{}
+		rk.tables[table] = generation
+	}
+	return generation
+}
+
+func (rk *resultCacheKeyspace) invalidate(table string) {
+	rk.generation(table).Add(1)
+}
+
+func (rk *resultCacheKeyspace) invalidateAll() {
+	rk.mu.Lock()
+	defer rk.mu.Unlock()
+	for _, generation := range rk.tables {
+		generation.Add(1)
+	}
+}
+
+// canCacheResult returns whether the result of the plan can be served from, and
+// stored in, the result cache.
+func (e *Executor) canCacheResult(plan *engine.Plan, vcursor *vcursorImpl, safeSession *SafeSession) bool {
+	if e.resultCache == nil || plan.Type != sqlparser.StmtSelect || len(plan.TablesUsed) == 0 {
+		return false
+	}
+	// Results read in a transaction or reserved connection, or that depend on the
+	// system variables of the session, are specific to the session.
+	if safeSession.InTransaction() || safeSession.InReservedConn() || safeSession.HasSystemVariables() {
+		return false
+	}
+	if !vcursor.resultCacheable {
+		return false
+	}
+	// The VStream invalidating the results follows the primaries, so replicas
+	// could serve rows from before a change that was already streamed.
+	if vcursor.tabletType != topodatapb.TabletType_PRIMARY {
+		return false
+	}
+	vschema := vcursor.vschema
+	for _, table := range plan.TablesUsed {
+		keyspace, _, _ := strings.Cut(table, ".")
+		if _, ok := vschema.Keyspaces[keyspace]; !ok {
+			return false
+		}
+	}
+	return e.resultCache.enabled(plan.TablesUsed, vcursor.resultCache, vcursor.resultCacheSet)
+}
+
+// nonDeterministicFuncs are the functions whose result differs between executions
+// or between sessions, even when the tables a query reads don't change.
+var nonDeterministicFuncs = map[string]bool{
+	"rand":           true,
+	"uuid":           true,
+	"uuid_short":     true,
+	"connection_id":  true,
+	"current_user":   true,
+	"user":           true,
+	"session_user":   true,
+	"system_user":    true,
+	"last_insert_id": true,
+	"found_rows":     true,
+	"row_count":      true,
+	"sleep":          true,
+	"curdate":        true,
+	"current_date":   true,
+	"curtime":        true,
+	"current_time":   true,
+	"utc_date":       true,
+	"utc_time":       true,
+	"unix_timestamp": true,
+}
+
+// resultCacheable returns whether the result of the statement only depends on
+// the tables it reads: it doesn't lock the rows it reads, and doesn't call
+// functions whose result differs between executions, like NOW() or RAND().
+func resultCacheable(stmt sqlparser.Statement) bool {
+	if _, ok := stmt.(sqlparser.SelectStatement); !ok {
+		return false
+	}
+	cacheable := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Select:
+			cacheable = node.Lock == sqlparser.NoLock
+		case *sqlparser.Union:
+			cacheable = node.Lock == sqlparser.NoLock
+		case *sqlparser.CurTimeFuncExpr, *sqlparser.LockingFunc:
+			cacheable = false
+		case *sqlparser.FuncExpr:
+			cacheable = !nonDeterministicFuncs[node.Name.Lowered()]
+		}
+		return cacheable, nil
+	}, stmt)
+	return cacheable
+}
+
+// executeCachingResult executes a plan whose result can be cached, serving it
+// from the result cache when possible.
+func (e *Executor) executeCachingResult(ctx context.Context, plan *engine.Plan, vcursor *vcursorImpl, bindVars map[string]*querypb.BindVariable) (*sqltypes.Result, error) {
+	key, err := e.resultCache.key(ctx, e.hashPlan(ctx, vcursor, plan.Original), bindVars)
+	if err != nil {
+		return nil, err
+	}
+	if qr, ok := e.resultCache.get(key); ok {
+		return qr, nil
+	}
+
+	versions, cacheable := e.resultCache.versions(plan.TablesUsed)
+	warnings := len(vcursor.safeSession.GetWarnings())
+	qr, err := vcursor.ExecutePrimitive(ctx, plan.Instructions, bindVars, true)
+	// Results with warnings, like the partial results of scatter queries, are not cached.
+	if err == nil && cacheable && len(vcursor.safeSession.GetWarnings()) == warnings {
+		e.resultCache.set(key, qr, versions)
+	}
+	return qr, err
+}
+
+// invalidateCachedResults invalidates the cached results of the tables that a
+// DML wrote to without waiting for the changes to be streamed, so that the
+// session reads its own writes. The changes of a DML in a transaction are only
+// visible once it commits, so the tables are recorded on the session then, and
+// invalidated by the commit.
+func (e *Executor) invalidateCachedResults(plan *engine.Plan, safeSession *SafeSession) {
+	if e.resultCache == nil {
+		return
+	}
+	switch plan.Type {
+	case sqlparser.StmtInsert, sqlparser.StmtReplace, sqlparser.StmtUpdate, sqlparser.StmtDelete:
+		if safeSession.InTransaction() {
+			safeSession.RecordWrittenTables(plan.TablesUsed)
+			return
+		}
+		e.resultCache.invalidate(plan.TablesUsed)
+	}
+}
@@ -0,0 +1,431 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vtgate
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/sqltypes"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtgate/engine"
+	"vitess.io/vitess/go/vt/vttablet/sandboxconn"
+
+	binlogdatapb "vitess.io/vitess/go/vt/proto/binlogdata"
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	topodatapb "vitess.io/vitess/go/vt/proto/topodata"
+	vtgatepb "vitess.io/vitess/go/vt/proto/vtgate"
+)
+
+// fakeKeyspaceStreamer is a keyspaceStreamer that sends the events written to
+// its channel, and ends the stream when an error is written to it.
+type fakeKeyspaceStreamer struct {
+	events chan []*binlogdatapb.VEvent
+	sent   chan struct{}
+	errors chan error
+}
+
+func newFakeKeyspaceStreamer() *fakeKeyspaceStreamer {
+	return &fakeKeyspaceStreamer{
+		events: make(chan []*binlogdatapb.VEvent),
+		sent:   make(chan struct{}),
+		errors: make(chan error),
+	}
+}
+
+func (fks *fakeKeyspaceStreamer) stream(ctx context.Context, keyspace string, send func(events []*binlogdatapb.VEvent) error) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-fks.errors:
+			return err
+		case events := <-fks.events:
+			err := send(events)
+			fks.sent <- struct{}{}
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send sends the events, and waits for them to be processed.
+func (fks *fakeKeyspaceStreamer) send(events ...*binlogdatapb.VEvent) {
+	fks.events <- events
+	<-fks.sent
+}
+
+func (fks *fakeKeyspaceStreamer) heartbeat() {
+	fks.send(&binlogdatapb.VEvent{Type: binlogdatapb.VEventType_HEARTBEAT})
+}
+
+func (fks *fakeKeyspaceStreamer) rowEvent(table string) {
+	fks.send(&binlogdatapb.VEvent{
+		Type:     binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{TableName: table},
+	})
+}
+
+func TestResultCache(t *testing.T) {
+	streamer := newFakeKeyspaceStreamer()
+	rc := newResultCache(1024*1024, nil, time.Minute, streamer.stream)
+	defer rc.Close()
+
+	tables := []string{"ks.t1"}
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	key, err := rc.key(context.Background(), PlanCacheKey{1}, nil)
+	require.NoError(t, err)
+
+	// Results are not cached until the VStream of the keyspace is live.
+	_, ok := rc.versions(tables)
+	assert.False(t, ok)
+	streamer.heartbeat()
+
+	versions, ok := rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	qr, ok := rc.get(key)
+	require.True(t, ok)
+	assert.Equal(t, result, qr)
+
+	// A row event of another table keeps the result.
+	streamer.rowEvent("ks.t2")
+	_, ok = rc.get(key)
+	assert.True(t, ok)
+
+	// A row event of the table invalidates the result.
+	streamer.rowEvent("ks.t1")
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+
+	// A DML of this vtgate invalidates the result.
+	versions, ok = rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	rc.invalidate(tables)
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+
+	// A result read before a row event is not served.
+	versions, ok = rc.versions(tables)
+	require.True(t, ok)
+	streamer.rowEvent("ks.t1")
+	rc.set(key, result, versions)
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+
+	// Clearing the cache invalidates the result.
+	versions, ok = rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	rc.clear()
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+
+	// The end of the stream invalidates the result, and stops caching results.
+	versions, ok = rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	streamer.errors <- errors.New("stream ended")
+	assert.Eventually(t, func() bool {
+		_, ok := rc.get(key)
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+	_, ok = rc.versions(tables)
+	assert.False(t, ok)
+}
+
+func TestResultCacheInvalidatedOnCommit(t *testing.T) {
+	streamer := newFakeKeyspaceStreamer()
+	rc := newResultCache(1024*1024, nil, time.Minute, streamer.stream)
+	defer rc.Close()
+	e := &Executor{resultCache: rc}
+	txc := &TxConn{resultCache: rc}
+
+	tables := []string{"ks.t1"}
+	result := sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1")
+	key, err := rc.key(context.Background(), PlanCacheKey{1}, nil)
+	require.NoError(t, err)
+	// Start streaming the keyspace.
+	_, _ = rc.versions(tables)
+	streamer.heartbeat()
+	plan := &engine.Plan{Type: sqlparser.StmtUpdate, TablesUsed: tables}
+
+	// A DML in a transaction only invalidates the result when the transaction commits.
+	versions, ok := rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	safeSession := NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	e.invalidateCachedResults(plan, safeSession)
+	_, ok = rc.get(key)
+	assert.True(t, ok)
+	assert.Equal(t, tables, safeSession.GetWrittenTables())
+
+	require.NoError(t, txc.Commit(context.Background(), safeSession))
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+	assert.Empty(t, safeSession.GetWrittenTables())
+
+	// The tables written to in a transaction that is rolled back are not invalidated.
+	versions, ok = rc.versions(tables)
+	require.True(t, ok)
+	rc.set(key, result, versions)
+	safeSession = NewSafeSession(&vtgatepb.Session{InTransaction: true})
+	e.invalidateCachedResults(plan, safeSession)
+	require.NoError(t, txc.Rollback(context.Background(), safeSession))
+	_, ok = rc.get(key)
+	assert.True(t, ok)
+	assert.Empty(t, safeSession.GetWrittenTables())
+
+	// A DML outside of a transaction invalidates the result right away.
+	e.invalidateCachedResults(plan, NewSafeSession(&vtgatepb.Session{Autocommit: true}))
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+}
+
+func TestResultCacheTTL(t *testing.T) {
+	streamer := newFakeKeyspaceStreamer()
+	rc := newResultCache(1024*1024, nil, time.Millisecond, streamer.stream)
+	defer rc.Close()
+
+	rc.keyspace("ks")
+	streamer.heartbeat()
+
+	key, err := rc.key(context.Background(), PlanCacheKey{1}, nil)
+	require.NoError(t, err)
+	versions, ok := rc.versions([]string{"ks.t1"})
+	require.True(t, ok)
+	rc.set(key, sqltypes.MakeTestResult(sqltypes.MakeTestFields("id", "int64"), "1"), versions)
+
+	time.Sleep(10 * time.Millisecond)
+	_, ok = rc.get(key)
+	assert.False(t, ok)
+}
+
+func TestResultCacheKey(t *testing.T) {
+	rc := newResultCache(1024*1024, nil, time.Minute, nil)
+	defer rc.Close()
+
+	ctx := context.Background()
+	key := func(planKey PlanCacheKey, bindVars map[string]*querypb.BindVariable) PlanCacheKey {
+		key, err := rc.key(ctx, planKey, bindVars)
+		require.NoError(t, err)
+		return key
+	}
+
+	bv1 := map[string]*querypb.BindVariable{"a": sqltypes.Int64BindVariable(1), "b": sqltypes.Int64BindVariable(2)}
+	bv2 := map[string]*querypb.BindVariable{"b": sqltypes.Int64BindVariable(2), "a": sqltypes.Int64BindVariable(1)}
+	bv3 := map[string]*querypb.BindVariable{"a": sqltypes.Int64BindVariable(2), "b": sqltypes.Int64BindVariable(1)}
+
+	assert.Equal(t, key(PlanCacheKey{1}, bv1), key(PlanCacheKey{1}, bv2))
+	assert.NotEqual(t, key(PlanCacheKey{1}, bv1), key(PlanCacheKey{1}, bv3))
+	assert.NotEqual(t, key(PlanCacheKey{1}, bv1), key(PlanCacheKey{2}, bv1))
+	assert.NotEqual(t, key(PlanCacheKey{1}, nil), key(PlanCacheKey{1}, bv1))
+
+	// The results of a query are cached for every caller separately.
+	anonymous := key(PlanCacheKey{1}, bv1)
+	ctx = callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("alice"))
+	alice := key(PlanCacheKey{1}, bv1)
+	ctx = callerid.NewContext(context.Background(), callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("bob"))
+	aliceThroughBob := key(PlanCacheKey{1}, bv1)
+	ctx = callerid.NewContext(context.Background(), nil, callerid.NewImmediateCallerID("alice"))
+	immediateAlice := key(PlanCacheKey{1}, bv1)
+	assert.NotEqual(t, anonymous, alice)
+	assert.NotEqual(t, alice, aliceThroughBob)
+	assert.NotEqual(t, alice, immediateAlice)
+}
+
+func TestResultCacheEnabled(t *testing.T) {
+	rc := newResultCache(1024*1024, []string{"ks.t1", "T2"}, time.Minute, nil)
+	defer rc.Close()
+
+	tcases := []struct {
+		tables       []string
+		directive    bool
+		directiveSet bool
+		want         bool
+	}{
+		{tables: []string{"ks.t1"}, want: true},
+		{tables: []string{"other.t1"}, want: false},
+		{tables: []string{"ks.t2", "other.t2"}, want: true},
+		{tables: []string{"ks.t1", "ks.t3"}, want: false},
+		{tables: []string{"ks.t3"}, directive: true, directiveSet: true, want: true},
+		{tables: []string{"ks.t1"}, directive: false, directiveSet: true, want: false},
+	}
+	for _, tcase := range tcases {
+		assert.Equal(t, tcase.want, rc.enabled(tcase.tables, tcase.directive, tcase.directiveSet), "%v", tcase)
+	}
+}
+
+func TestResultCacheable(t *testing.T) {
+	tcases := []struct {
+		query string
+		want  bool
+	}{
+		{query: "select id from t1", want: true},
+		{query: "select id, concat(a, b) from t1 where id in (select id from t2)", want: true},
+		{query: "select id from t1 union select id from t2", want: true},
+		{query: "select id, now() from t1", want: false},
+		{query: "select id from t1 where created < current_timestamp", want: false},
+		{query: "select id from t1 where d = curdate()", want: false},
+		{query: "select id, rand() from t1", want: false},
+		{query: "select uuid() from t1", want: false},
+		{query: "select connection_id(), id from t1", want: false},
+		{query: "select id from t1 where id in (select id from t2 where a = user())", want: false},
+		{query: "select get_lock('lock', 10) from t1", want: false},
+		{query: "select id from t1 for update", want: false},
+		{query: "select id from t1 lock in share mode", want: false},
+		{query: "select id from t1 union select id from t2 for update", want: false},
+		{query: "insert into t1(id) values (1)", want: false},
+	}
+	parser := sqlparser.NewTestParser()
+	for _, tcase := range tcases {
+		t.Run(tcase.query, func(t *testing.T) {
+			stmt, err := parser.Parse(tcase.query)
+			require.NoError(t, err)
+			assert.Equal(t, tcase.want, resultCacheable(stmt))
+		})
+	}
+}
+
+func TestExecutorResultCache(t *testing.T) {
+	executor, _, _, sbclookup, ctx := createExecutorEnv(t)
+	streamer := newFakeKeyspaceStreamer()
+	executor.resultCache = newResultCache(1024*1024, nil, time.Minute, streamer.stream)
+	executor.txConn.resultCache = executor.resultCache
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+	query := "select /*vt+ RESULT_CACHE */ id from main1"
+
+	// The first query starts the VStream of the keyspace.
+	_, err := executor.Execute(ctx, nil, "TestExecutorResultCache", session, query, nil)
+	require.NoError(t, err)
+	streamer.heartbeat()
+	sbclookup.ExecCount.Store(0)
+
+	for i := 0; i < 3; i++ {
+		_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, query, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 1, sbclookup.ExecCount.Load())
+
+	// Queries without the directive are not cached.
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, "select id from main1", nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, sbclookup.ExecCount.Load())
+
+	// Queries with non-deterministic functions or locking clauses are not cached.
+	for _, uncacheable := range []string{
+		"select /*vt+ RESULT_CACHE */ id, now() from main1",
+		"select /*vt+ RESULT_CACHE */ id from main1 for update",
+	} {
+		for i := 0; i < 2; i++ {
+			_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, uncacheable, nil)
+			require.NoError(t, err)
+		}
+	}
+	assert.EqualValues(t, 6, sbclookup.ExecCount.Load())
+
+	// A DML invalidates the cached result right away.
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, "delete from main1", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8, sbclookup.ExecCount.Load())
+
+	// A row event invalidates the cached result.
+	streamer.rowEvent("TestUnsharded.main1")
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, sbclookup.ExecCount.Load())
+
+	// Results read in a transaction are not cached.
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, "begin", nil)
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, query, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 11, sbclookup.ExecCount.Load())
+
+	// A DML in a transaction invalidates the cached result when the transaction
+	// commits, so a result cached by another session before then is not served.
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, "delete from main1", nil)
+	require.NoError(t, err)
+	other := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+	for i := 0; i < 2; i++ {
+		_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", other, query, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 12, sbclookup.ExecCount.Load())
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", session, "commit", nil)
+	require.NoError(t, err)
+	_, err = executor.Execute(ctx, nil, "TestExecutorResultCache", other, query, nil)
+	require.NoError(t, err)
+	assert.EqualValues(t, 13, sbclookup.ExecCount.Load())
+}
+
+func TestExecutorResultCacheCallers(t *testing.T) {
+	var primary, replica *sandboxconn.SandboxConn
+	executor, ctx := createExecutorEnvCallback(t, func(shard, ks string, tabletType topodatapb.TabletType, conn *sandboxconn.SandboxConn) {
+		switch {
+		case ks == KsTestUnsharded && tabletType == topodatapb.TabletType_PRIMARY:
+			primary = conn
+		case ks == KsTestUnsharded && tabletType == topodatapb.TabletType_REPLICA:
+			replica = conn
+		}
+	})
+	streamer := newFakeKeyspaceStreamer()
+	executor.resultCache = newResultCache(1024*1024, nil, time.Minute, streamer.stream)
+	executor.txConn.resultCache = executor.resultCache
+
+	session := NewSafeSession(&vtgatepb.Session{TargetString: "@primary", Autocommit: true})
+	query := "select /*vt+ RESULT_CACHE */ id from main1"
+	aliceCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("alice", "", ""), callerid.NewImmediateCallerID("alice"))
+	bobCtx := callerid.NewContext(ctx, callerid.NewEffectiveCallerID("bob", "", ""), callerid.NewImmediateCallerID("bob"))
+
+	_, err := executor.Execute(aliceCtx, nil, "TestExecutorResultCacheCallers", session, query, nil)
+	require.NoError(t, err)
+	streamer.heartbeat()
+	primary.ExecCount.Store(0)
+
+	// The tablets check the table ACLs of every caller, so the result cached
+	// for one caller is not served to another.
+	for i := 0; i < 2; i++ {
+		_, err = executor.Execute(aliceCtx, nil, "TestExecutorResultCacheCallers", session, query, nil)
+		require.NoError(t, err)
+		_, err = executor.Execute(bobCtx, nil, "TestExecutorResultCacheCallers", session, query, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, primary.ExecCount.Load())
+
+	// Results read from replicas are not cached, as they lag behind the VStream
+	// of the primaries that invalidates them.
+	replicaSession := NewSafeSession(&vtgatepb.Session{TargetString: "@replica", Autocommit: true})
+	for i := 0; i < 2; i++ {
+		_, err = executor.Execute(aliceCtx, nil, "TestExecutorResultCacheCallers", replicaSession, query, nil)
+		require.NoError(t, err)
+	}
+	assert.EqualValues(t, 2, replica.ExecCount.Load())
+}
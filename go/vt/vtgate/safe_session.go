@@ -18,7 +18,9 @@ package vtgate
 
 import (
 	"fmt"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -198,11 +200,30 @@ func (session *SafeSession) resetCommonLocked() {
 	session.Session.InTransaction = false
 	session.commitOrder = vtgatepb.CommitOrder_NORMAL
 	session.Savepoints = nil
+	session.WrittenTables = nil
 	if session.Options != nil {
 		session.Options.TransactionAccessMode = nil
 	}
 }
 
+// RecordWrittenTables records the tables written to in the open transaction.
+func (session *SafeSession) RecordWrittenTables(tables []string) {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	for _, table := range tables {
+		if !slices.Contains(session.WrittenTables, table) {
+			session.WrittenTables = append(session.WrittenTables, table)
+		}
+	}
+}
+
+// GetWrittenTables returns the tables written to in the open transaction.
+func (session *SafeSession) GetWrittenTables() []string {
+	session.mu.Lock()
+	defer session.mu.Unlock()
+	return session.WrittenTables
+}
+
 // SetQueryTimeout sets the query timeout
 func (session *SafeSession) SetQueryTimeout(queryTimeout int64) {
 	session.mu.Lock()
@@ -593,6 +614,22 @@ func (session *SafeSession) ForeignKeyChecks() *bool {
 	return nil
 }
 
+// CTEMaxRecursionDepth returns the cte_max_recursion_depth stored in system_variables map in the session.
+func (session *SafeSession) CTEMaxRecursionDepth() (int, bool) {
+	session.mu.Lock()
+	val, ok := session.SystemVariables[sysvars.CTEMaxRecursionDepth]
+	session.mu.Unlock()
+
+	if !ok {
+		return 0, false
+	}
+	depth, err := strconv.Atoi(val)
+	if err != nil || depth < 0 {
+		return 0, false
+	}
+	return depth, true
+}
+
 // SetOptions sets the options
 func (session *SafeSession) SetOptions(options *querypb.ExecuteOptions) {
 	session.mu.Lock()
@@ -98,3 +98,18 @@ func TestTimeZone(t *testing.T) {
 		})
 	}
 }
+
+func TestCTEMaxRecursionDepth(t *testing.T) {
+	session := NewSafeSession(&vtgatepb.Session{})
+	_, ok := session.CTEMaxRecursionDepth()
+	assert.False(t, ok)
+
+	session.SetSystemVariable("cte_max_recursion_depth", "10")
+	depth, ok := session.CTEMaxRecursionDepth()
+	assert.True(t, ok)
+	assert.Equal(t, 10, depth)
+
+	session.SetSystemVariable("cte_max_recursion_depth", "'foo'")
+	_, ok = session.CTEMaxRecursionDepth()
+	assert.False(t, ok)
+}
@@ -532,6 +532,45 @@ func TestScopeForSubqueries(t *testing.T) {
 	}
 }
 
+func TestScopeForLateralDerivedTables(t *testing.T) {
+	tcases := []struct {
+		sql  string
+		deps TableSet
+	}{
+		{
+			sql:  `select 1 from x as t, lateral (select t.col1 from z) as d`,
+			deps: TS0,
+		}, {
+			sql:  `select 1 from x as t join lateral (select t.col1 from z) as d`,
+			deps: TS0,
+		}, {
+			sql:  `select 1 from x as t, lateral (select t.col1 from z as t) as d`,
+			deps: TS1,
+		}, {
+			sql:  `select 1 from x, y as t, lateral (select t.col1 from z) as d`,
+			deps: TS1,
+		},
+	}
+	for _, tc := range tcases {
+		t.Run(tc.sql, func(t *testing.T) {
+			stmt, semTable := parseAndAnalyze(t, tc.sql, "d")
+			sel, _ := stmt.(*sqlparser.Select)
+
+			var dt *sqlparser.DerivedTable
+			_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+				if n, ok := node.(*sqlparser.DerivedTable); ok {
+					dt = n
+				}
+				return true, nil
+			}, sqlparser.TableExprs(sel.From))
+			require.NotNil(t, dt)
+			exp := extract(dt.Select.(*sqlparser.Select), 0)
+			s1 := semTable.RecursiveDeps(exp)
+			assert.Equal(t, tc.deps, s1)
+		})
+	}
+}
+
 func TestSubqueryOrderByBinding(t *testing.T) {
 	queries := []struct {
 		query    string
@@ -887,9 +926,6 @@ func TestInvalidQueries(t *testing.T) {
 	}, {
 		sql:  "select 1 from t1 where (id, id) in (select 1, 2, 3)",
 		serr: "Operand should contain 2 column(s)",
-	}, {
-		sql:  "WITH RECURSIVE cte (n) AS (SELECT 1 UNION ALL SELECT n + 1 FROM cte WHERE n < 5) SELECT * FROM cte",
-		serr: "VT12001: unsupported: recursive common table expression",
 	}, {
 		sql:  "with x as (select 1), x as (select 1) select * from x",
 		serr: "VT03013: not unique table/alias: 'x'",
@@ -38,26 +38,15 @@ func (a *analyzer) checkForInvalidConstructs(cursor *sqlparser.Cursor) error {
 		return checkUnion(node)
 	case *sqlparser.JSONTableExpr:
 		return &JSONTablesError{}
-	case *sqlparser.DerivedTable:
-		return checkDerived(node)
 	case *sqlparser.AssignmentExpr:
 		return vterrors.VT12001("Assignment expression")
-	case *sqlparser.ComparisonExpr:
-		if node.Modifier != sqlparser.Missing {
-			return NotSingleRouteErr{Inner: &UnsupportedConstruct{errString: "ANY/ALL/SOME comparison operator"}}
-		}
 	case *sqlparser.Subquery:
 		return a.checkSubqueryColumns(cursor.Parent(), node)
-	case *sqlparser.With:
-		if node.Recursive {
-			return vterrors.VT12001("recursive common table expression")
-		}
-	case *sqlparser.Insert:
-		if !a.singleUnshardedKeyspace && node.Action == sqlparser.ReplaceAct {
-			return ShardedError{Inner: &UnsupportedConstruct{errString: "REPLACE INTO with sharded keyspace"}}
-		}
 	case *sqlparser.OverClause:
-		if !a.singleUnshardedKeyspace {
+		// windows defined in the WINDOW clause are not resolved by the planner,
+		// so they can only be used when the whole query is sent to a single unsharded keyspace
+		usesNamedWindow := !node.WindowName.IsEmpty() || node.WindowSpec != nil && !node.WindowSpec.Name.IsEmpty()
+		if !a.singleUnshardedKeyspace && usesNamedWindow {
 			return ShardedError{Inner: &UnsupportedConstruct{errString: "OVER CLAUSE with sharded keyspace"}}
 		}
 	}
@@ -98,13 +87,6 @@ func (a *analyzer) checkSubqueryColumns(parent sqlparser.SQLNode, subq *sqlparse
 	return nil
 }
 
-func checkDerived(node *sqlparser.DerivedTable) error {
-	if node.Lateral {
-		return vterrors.VT12001("lateral derived tables")
-	}
-	return nil
-}
-
 func checkUnion(node *sqlparser.Union) error {
 	err := sqlparser.Walk(func(node sqlparser.SQLNode) (kontinue bool, err error) {
 		switch node := node.(type) {
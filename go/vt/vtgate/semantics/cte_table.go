@@ -0,0 +1,273 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package semantics
+
+import (
+	"strings"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vtgate/vindexes"
+)
+
+type (
+	// RecursiveCTE contains the information about a common table expression that references itself.
+	// The definition has to be a UNION, where the LHS is the seed, and the RHS is the recursive part,
+	// the term, which references the CTE exactly once.
+	RecursiveCTE struct {
+		Name  string
+		Union *sqlparser.Union
+
+		// selfRef is the table expression inside the term that references the CTE
+		selfRef *sqlparser.AliasedTableExpr
+
+		explicitColumns sqlparser.Columns
+		// columns is filled in when the CTE is first used
+		columns []ColumnInfo
+	}
+
+	// CTETable contains the information about a reference to a recursive CTE.
+	// Every reference gets its own CTETable, including the one inside the term of the CTE itself.
+	CTETable struct {
+		tableName string
+		ASTNode   *sqlparser.AliasedTableExpr
+		CTE       *RecursiveCTE
+
+		// SelfRef is true for the reference inside the recursive part of the CTE
+		SelfRef bool
+
+		// vindexTable is only set when all the tables used in the CTE live in the same unsharded keyspace,
+		// in which case the CTE can be sent to that keyspace as is
+		vindexTable *vindexes.Table
+	}
+)
+
+var _ TableInfo = (*CTETable)(nil)
+
+// newRecursiveCTE returns nil if the CTE is not recursive,
+// either because it was not declared using WITH RECURSIVE or because it does not reference itself
+func newRecursiveCTE(with *sqlparser.With, cte *sqlparser.CommonTableExpr) (*RecursiveCTE, error) {
+	if !with.Recursive {
+		return nil, nil
+	}
+	name := cte.ID.String()
+	refs, _ := findCTEReferences(cte.Subquery.Select, name)
+	if len(refs) == 0 {
+		return nil, nil
+	}
+
+	union, ok := cte.Subquery.Select.(*sqlparser.Union)
+	if !ok {
+		return nil, vterrors.VT12001("recursive CTE without a UNION")
+	}
+	if union.OrderBy != nil || union.Limit != nil {
+		return nil, vterrors.VT12001("ORDER BY or LIMIT in a recursive CTE")
+	}
+	if refs, _ := findCTEReferences(union.Left, name); len(refs) > 0 {
+		return nil, vterrors.VT12001("recursive reference in the non-recursive part of a CTE")
+	}
+	term, ok := union.Right.(*sqlparser.Select)
+	if !ok {
+		return nil, vterrors.VT12001("recursive part of a CTE that is not a simple SELECT")
+	}
+	refs, inSubquery := findCTEReferences(term, name)
+	if len(refs) != 1 || inSubquery {
+		return nil, vterrors.VT12001("recursive CTE that does not reference itself exactly once in the FROM clause of the recursive part")
+	}
+
+	return &RecursiveCTE{
+		Name:            name,
+		Union:           union,
+		selfRef:         refs[0],
+		explicitColumns: cte.Columns,
+	}, nil
+}
+
+// findCTEReferences returns all table expressions that reference the CTE with the given name,
+// and whether any of them are used inside a subquery
+func findCTEReferences(node sqlparser.SQLNode, name string) (refs []*sqlparser.AliasedTableExpr, inSubquery bool) {
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		switch node := node.(type) {
+		case *sqlparser.Subquery:
+			subRefs, _ := findCTEReferences(node.Select, name)
+			inSubquery = inSubquery || len(subRefs) > 0
+			refs = append(refs, subRefs...)
+			return false, nil
+		case *sqlparser.AliasedTableExpr:
+			tbl, ok := node.Expr.(sqlparser.TableName)
+			if ok && tbl.Qualifier.IsEmpty() && tbl.Name.String() == name {
+				refs = append(refs, node)
+			}
+		}
+		return true, nil
+	}, node)
+	return
+}
+
+// Seed returns the non-recursive part of the CTE
+func (r *RecursiveCTE) Seed() sqlparser.SelectStatement {
+	return r.Union.Left
+}
+
+// Term returns the recursive part of the CTE
+func (r *RecursiveCTE) Term() *sqlparser.Select {
+	return r.Union.Right.(*sqlparser.Select)
+}
+
+// Distinct returns true if the seed and the term are combined using UNION DISTINCT
+func (r *RecursiveCTE) Distinct() bool {
+	return r.Union.Distinct
+}
+
+// Columns returns the column names of the CTE
+func (r *RecursiveCTE) Columns() []string {
+	names := make([]string, 0, len(r.columns))
+	for _, col := range r.columns {
+		names = append(names, col.Name)
+	}
+	return names
+}
+
+// Definition returns the CTE in the form it needs to have in a WITH clause
+func (r *RecursiveCTE) Definition() *sqlparser.CommonTableExpr {
+	return &sqlparser.CommonTableExpr{
+		ID:       sqlparser.NewIdentifierCS(r.Name),
+		Columns:  r.explicitColumns,
+		Subquery: &sqlparser.Subquery{Select: r.Union},
+	}
+}
+
+// init calculates the columns and their types using the seed of the CTE.
+// It has to be called after the seed has been analyzed.
+func (r *RecursiveCTE) init(org originable) error {
+	if r.columns != nil {
+		return nil
+	}
+	exprs := sqlparser.GetFirstSelect(r.Seed()).SelectExprs
+	if len(r.explicitColumns) > 0 && len(r.explicitColumns) != len(exprs) {
+		return vterrors.VT03033()
+	}
+	columns := make([]ColumnInfo, 0, len(exprs))
+	for i, expr := range exprs {
+		ae, ok := expr.(*sqlparser.AliasedExpr)
+		if !ok {
+			return vterrors.VT09015()
+		}
+		_, _, typ := org.depsForExpr(ae.Expr)
+		name := ae.ColumnName()
+		if len(r.explicitColumns) > 0 {
+			name = r.explicitColumns[i].String()
+		}
+		columns = append(columns, ColumnInfo{Name: name, Type: typ})
+	}
+	r.columns = columns
+	return nil
+}
+
+// singleUnshardedKeyspace returns the keyspace if all tables used by the CTE are in the same unsharded keyspace
+func (r *RecursiveCTE) singleUnshardedKeyspace(tables []TableInfo) *vindexes.Keyspace {
+	var ks *vindexes.Keyspace
+	single := true
+	_ = sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		ate, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok || !single {
+			return single, nil
+		}
+		if _, isTbl := ate.Expr.(sqlparser.TableName); !isTbl || ate == r.selfRef {
+			return true, nil
+		}
+		var vtbl *vindexes.Table
+		for _, table := range tables {
+			if table.GetAliasedTableExpr() == ate {
+				vtbl = table.GetVindexTable()
+				break
+			}
+		}
+		switch {
+		case vtbl == nil || vtbl.Keyspace == nil || vtbl.Keyspace.Sharded || vtbl.Type != "":
+			single = false
+		case ks == nil:
+			ks = vtbl.Keyspace
+		case ks.Name != vtbl.Keyspace.Name:
+			single = false
+		}
+		return single, nil
+	}, r.Union)
+	if !single {
+		return nil
+	}
+	return ks
+}
+
+// dependencies implements the TableInfo interface
+func (c *CTETable) dependencies(colName string, org originable) (dependencies, error) {
+	ts := org.tableSetFor(c.ASTNode)
+	for _, info := range c.CTE.columns {
+		if strings.EqualFold(info.Name, colName) {
+			return createCertain(ts, ts, info.Type), nil
+		}
+	}
+	return &nothing{}, nil
+}
+
+// getTableSet implements the TableInfo interface
+func (c *CTETable) getTableSet(org originable) TableSet {
+	return org.tableSetFor(c.ASTNode)
+}
+
+// getExprFor implements the TableInfo interface
+func (c *CTETable) getExprFor(s string) (sqlparser.Expr, error) {
+	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "Unknown column '%s' in 'field list'", s)
+}
+
+// IsInfSchema implements the TableInfo interface
+func (c *CTETable) IsInfSchema() bool {
+	return false
+}
+
+func (c *CTETable) matches(name sqlparser.TableName) bool {
+	return c.tableName == name.Name.String() && name.Qualifier.IsEmpty()
+}
+
+func (c *CTETable) authoritative() bool {
+	return true
+}
+
+// Name implements the TableInfo interface
+func (c *CTETable) Name() (sqlparser.TableName, error) {
+	return c.ASTNode.TableName()
+}
+
+// GetAliasedTableExpr implements the TableInfo interface
+func (c *CTETable) GetAliasedTableExpr() *sqlparser.AliasedTableExpr {
+	return c.ASTNode
+}
+
+func (c *CTETable) canShortCut() shortCut {
+	return canShortCut
+}
+
+// GetVindexTable implements the TableInfo interface.
+// It is only available when the whole CTE can be sent to a single unsharded keyspace.
+func (c *CTETable) GetVindexTable() *vindexes.Table {
+	return c.vindexTable
+}
+
+func (c *CTETable) getColumns(bool) []ColumnInfo {
+	return c.CTE.columns
+}
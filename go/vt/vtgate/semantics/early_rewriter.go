@@ -123,7 +123,8 @@ func (r *earlyRewriter) handleAliasedTable(node *sqlparser.AliasedTableExpr) err
 	}
 	scope := r.scoper.currentScope()
 	cte := scope.findCTE(tbl.Name.String())
-	if cte == nil {
+	if cte == nil || r.scoper.recursiveCTEs[cte] != nil {
+		// recursive CTEs can't be inlined, they are planned on their own
 		return nil
 	}
 	if node.As.IsEmpty() {
@@ -140,13 +141,24 @@ func (r *earlyRewriter) handleAliasedTable(node *sqlparser.AliasedTableExpr) err
 
 func (r *earlyRewriter) handleWith(node *sqlparser.With) error {
 	scope := r.scoper.currentScope()
+	var recursive []*sqlparser.CommonTableExpr
 	for _, cte := range node.CTEs {
-		err := scope.addCTE(cte)
+		rcte, err := newRecursiveCTE(node, cte)
 		if err != nil {
 			return err
 		}
+		err = scope.addCTE(cte, rcte != nil)
+		if err != nil {
+			return err
+		}
+		if rcte != nil {
+			r.scoper.recursiveCTEs[cte] = rcte
+			recursive = append(recursive, cte)
+		}
 	}
-	node.CTEs = nil
+	// recursive CTEs are kept in the WITH clause, so their definition is analyzed once.
+	// all other CTEs are inlined as derived tables where they are used
+	node.CTEs = recursive
 	return nil
 }
 
@@ -162,6 +174,7 @@ func rewriteNotExpr(cursor *sqlparser.Cursor, node *sqlparser.NotExpr) {
 		return
 	}
 	cmp.Operator = cmp.Operator.Inverse()
+	cmp.Modifier = cmp.Modifier.Inverse()
 	cursor.Replace(cmp)
 }
 
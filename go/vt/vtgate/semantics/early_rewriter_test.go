@@ -837,6 +837,9 @@ func TestRewriteNot(t *testing.T) {
 	}, {
 		sql:      "select a from t1 where not a > 12",
 		expected: "select a from t1 where a <= 12",
+	}, {
+		sql:      "select a from t1 where not a > all (select b from t1)",
+		expected: "select a from t1 where a <= any (select b from t1)",
 	}}
 	for _, tcase := range tcases {
 		t.Run(tcase.sql, func(t *testing.T) {
@@ -854,6 +857,56 @@ func TestRewriteNot(t *testing.T) {
 	}
 }
 
+// TestKeepAnyAll tests that comparisons using ANY, SOME and ALL are left for the planner,
+// so they can be sent to MySQL whenever the subquery is merged with the outer query.
+func TestKeepAnyAll(t *testing.T) {
+	sharded := &vindexes.Keyspace{Name: "main", Sharded: true}
+	unsharded := &vindexes.Keyspace{Name: "unsharded"}
+	schemaInfo := &FakeSI{
+		Tables: map[string]*vindexes.Table{
+			"t1": {
+				Keyspace:                sharded,
+				Name:                    sqlparser.NewIdentifierCS("t1"),
+				Columns:                 []vindexes.Column{{Name: sqlparser.NewIdentifierCI("a")}, {Name: sqlparser.NewIdentifierCI("b")}},
+				ColumnListAuthoritative: true,
+			},
+			"u1": {
+				Keyspace:                unsharded,
+				Name:                    sqlparser.NewIdentifierCS("u1"),
+				Columns:                 []vindexes.Column{{Name: sqlparser.NewIdentifierCI("a")}, {Name: sqlparser.NewIdentifierCI("b")}},
+				ColumnListAuthoritative: true,
+			},
+		},
+	}
+	tcases := []struct {
+		sql      string
+		expected string
+	}{{
+		sql:      "select a from t1 where a = any (select b from t1)",
+		expected: "select a from t1 where a = any (select b from t1)",
+	}, {
+		sql:      "select a from t1 where a > all (select b from t1 where t1.a = 5)",
+		expected: "select a from t1 where a > all (select b from t1 where t1.a = 5)",
+	}, {
+		sql:      "select a from u1 where a > all (select b from u1)",
+		expected: "select a from u1 where a > all (select b from u1)",
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.sql, func(t *testing.T) {
+			ast, err := sqlparser.NewTestParser().Parse(tcase.sql)
+			require.NoError(t, err)
+			selectStatement, isSelectStatement := ast.(*sqlparser.Select)
+			require.True(t, isSelectStatement, "analyzer expects a select statement")
+			st, err := Analyze(selectStatement, "db", schemaInfo)
+
+			require.NoError(t, err)
+			require.NoError(t, st.NotUnshardedErr)
+			require.NoError(t, st.NotSingleRouteErr)
+			assert.Equal(t, tcase.expected, sqlparser.String(selectStatement))
+		})
+	}
+}
+
 // TestConstantFolding tests that the rewriter is able to do various constant foldings properly.
 func TestConstantFolding(t *testing.T) {
 	ks := &vindexes.Keyspace{
@@ -18,6 +18,7 @@ package semantics
 
 import (
 	"reflect"
+	"slices"
 
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -38,6 +39,10 @@ type (
 		specialExprScopes map[*sqlparser.Literal]*scope
 		statementIDs      map[sqlparser.Statement]TableSet
 		si                SchemaInformation
+
+		// recursiveCTEs keeps track of the CTEs that reference themselves.
+		// These are not inlined as derived tables like the other CTEs
+		recursiveCTEs map[*sqlparser.CommonTableExpr]*RecursiveCTE
 	}
 
 	scope struct {
@@ -61,6 +66,7 @@ func newScoper(si SchemaInformation) *scoper {
 		specialExprScopes: map[*sqlparser.Literal]*scope{},
 		statementIDs:      map[sqlparser.Statement]TableSet{},
 		si:                si,
+		recursiveCTEs:     map[*sqlparser.CommonTableExpr]*RecursiveCTE{},
 	}
 }
 
@@ -75,6 +81,10 @@ func (s *scoper) down(cursor *sqlparser.Cursor) error {
 		s.pushUnionScope(node)
 	case sqlparser.TableExpr:
 		s.enterJoinScope(cursor)
+	case *sqlparser.DerivedTable:
+		if node.Lateral {
+			s.enterLateralScope()
+		}
 	case sqlparser.SelectExprs:
 		s.copySelectExprs(cursor, node)
 	case sqlparser.OrderBy:
@@ -182,6 +192,19 @@ func (s *scoper) enterJoinScope(cursor *sqlparser.Cursor) {
 	}
 }
 
+// enterLateralScope creates the scope used by a LATERAL derived table.
+// Unlike other derived tables, these can see the tables that come before them in the FROM clause
+func (s *scoper) enterLateralScope() {
+	currScope := s.currentScope()
+	nScope := newScope(currScope)
+	if sel, isSel := currScope.stmt.(*sqlparser.Select); isSel && s.rScope[sel] != nil {
+		// the tables of the current join are already visible through the join scope,
+		// but the tables to the left of it are only found in the scope of the select
+		nScope.tables = slices.Clone(s.rScope[sel].tables)
+	}
+	s.push(nScope)
+}
+
 func (s *scoper) pushSelectScope(node *sqlparser.Select) {
 	currScope := newScope(s.currentScope())
 	currScope.stmtScope = true
@@ -239,6 +262,10 @@ func (s *scoper) up(cursor *sqlparser.Cursor) error {
 		s.popScope()
 	case sqlparser.AggrFunc:
 		s.currentScope().inHavingAggr = false
+	case *sqlparser.DerivedTable:
+		if node.Lateral {
+			s.popScope()
+		}
 	case sqlparser.TableExpr:
 		if isParentSelect(cursor) {
 			curScope := s.currentScope()
@@ -342,14 +369,16 @@ func newScope(parent *scope) *scope {
 	}
 }
 
-func (s *scope) addCTE(cte *sqlparser.CommonTableExpr) error {
+func (s *scope) addCTE(cte *sqlparser.CommonTableExpr, recursive bool) error {
 	name := cte.ID.String()
 	_, exists := s.ctes[name]
 	if exists {
 		return vterrors.VT03013(name)
 	}
-	if err := checkForInvalidAliasUse(cte, name); err != nil {
-		return err
+	if !recursive {
+		if err := checkForInvalidAliasUse(cte, name); err != nil {
+			return err
+		}
 	}
 	s.ctes[name] = cte
 	return nil
@@ -427,3 +456,15 @@ func (s *scope) findCTE(name string) *sqlparser.CommonTableExpr {
 	}
 	return s.parent.findCTE(name)
 }
+
+// findRecursiveCTE returns the recursive CTE the table name refers to, if any
+func (s *scoper) findRecursiveCTE(tbl sqlparser.TableName) *RecursiveCTE {
+	if tbl.Qualifier.NotEmpty() {
+		return nil
+	}
+	cte := s.currentScope().findCTE(tbl.Name.String())
+	if cte == nil {
+		return nil
+	}
+	return s.recursiveCTEs[cte]
+}
@@ -83,6 +83,7 @@ type (
 		HashJoin    bool
 		SubQueries  bool
 		Union       bool
+		Window      bool
 	}
 
 	// SemTable contains semantic analysis information about the query.
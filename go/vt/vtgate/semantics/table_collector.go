@@ -301,6 +301,10 @@ func buildDerivedTable(colNames []string, rowAlias *sqlparser.RowAlias, types []
 }
 
 func (tc *tableCollector) handleTableName(node *sqlparser.AliasedTableExpr, t sqlparser.TableName) (err error) {
+	if cte := tc.scoper.findRecursiveCTE(t); cte != nil {
+		return tc.handleRecursiveCTE(node, t, cte)
+	}
+
 	var tableInfo TableInfo
 	var found bool
 
@@ -317,6 +321,35 @@ func (tc *tableCollector) handleTableName(node *sqlparser.AliasedTableExpr, t sq
 	return scope.addTable(tableInfo)
 }
 
+func (tc *tableCollector) handleRecursiveCTE(node *sqlparser.AliasedTableExpr, t sqlparser.TableName, cte *RecursiveCTE) error {
+	if err := cte.init(tc.org); err != nil {
+		return err
+	}
+
+	tableName := t.Name.String()
+	if node.As.NotEmpty() {
+		tableName = node.As.String()
+	}
+	tableInfo := &CTETable{
+		tableName: tableName,
+		ASTNode:   node,
+		CTE:       cte,
+		SelfRef:   node == cte.selfRef,
+	}
+	if !tableInfo.SelfRef {
+		if ks := cte.singleUnshardedKeyspace(tc.Tables); ks != nil {
+			tableInfo.vindexTable = &vindexes.Table{
+				Name:     sqlparser.NewIdentifierCS(cte.Name),
+				Keyspace: ks,
+			}
+		}
+	}
+
+	tc.Tables = append(tc.Tables, tableInfo)
+	scope := tc.scoper.currentScope()
+	return scope.addTable(tableInfo)
+}
+
 func getTableInfo(node *sqlparser.AliasedTableExpr, t sqlparser.TableName, si SchemaInformation, currentDb string) (TableInfo, error) {
 	var tbl *vindexes.Table
 	var vindex vindexes.Vindex
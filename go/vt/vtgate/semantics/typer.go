@@ -18,6 +18,7 @@ package semantics
 
 import (
 	"vitess.io/vitess/go/mysql/collations"
+	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vtgate/engine/opcode"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
@@ -61,10 +62,34 @@ func (t *typer) up(cursor *sqlparser.Cursor) error {
 			}
 		}
 		t.m[node] = code.ResolveType(inputType, t.collationEnv)
+	case *sqlparser.ArgumentLessWindowExpr:
+		sqltype := sqltypes.Int64
+		if node.Type == sqlparser.CumeDistExprType || node.Type == sqlparser.PercentRankExprType {
+			sqltype = sqltypes.Float64
+		}
+		t.m[node] = evalengine.NewTypeEx(sqltype, collations.CollationForType(sqltype, t.collationEnv.DefaultConnectionCharset()), false, 0, 0, nil)
+	case *sqlparser.NtileExpr:
+		t.m[node] = evalengine.NewType(sqltypes.Int64, collations.CollationForType(sqltypes.Int64, t.collationEnv.DefaultConnectionCharset()))
+	case *sqlparser.LagLeadExpr:
+		t.setWindowValueType(node, node.Expr)
+	case *sqlparser.FirstOrLastValueExpr:
+		t.setWindowValueType(node, node.Expr)
+	case *sqlparser.NTHValueExpr:
+		t.setWindowValueType(node, node.Expr)
 	}
 	return nil
 }
 
+// setWindowValueType sets the type of a window function returning the values of its argument,
+// which is the type of the argument, except that the window function can always return NULL
+func (t *typer) setWindowValueType(node, arg sqlparser.Expr) {
+	typ, ok := t.m[arg]
+	if !ok {
+		return
+	}
+	t.m[node] = evalengine.NewTypeEx(typ.Type(), typ.Collation(), true, typ.Size(), typ.Scale(), typ.Values())
+}
+
 func (t *typer) setTypeFor(node *sqlparser.ColName, typ evalengine.Type) {
 	t.m[node] = typ
 }
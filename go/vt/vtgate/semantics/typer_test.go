@@ -57,6 +57,34 @@ func TestNormalizerAndSemanticAnalysisIntegration(t *testing.T) {
 	}
 }
 
+func TestWindowFunctionTypes(t *testing.T) {
+	tests := []struct {
+		query, typ string
+		nullable   bool
+	}{
+		{query: "select row_number() over (order by uid) from t2", typ: "INT64"},
+		{query: "select rank() over (partition by name order by uid) from t2", typ: "INT64"},
+		{query: "select percent_rank() over (order by uid) from t2", typ: "FLOAT64"},
+		{query: "select ntile(4) over (order by uid) from t2", typ: "INT64", nullable: true},
+		{query: "select lag(name) over (order by uid) from t2", typ: "VARCHAR", nullable: true},
+		{query: "select first_value(uid) over (order by name) from t2", typ: "INT64", nullable: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			parse, err := sqlparser.NewTestParser().Parse(test.query)
+			require.NoError(t, err)
+
+			st, err := Analyze(parse, "d", fakeSchemaInfo())
+			require.NoError(t, err)
+			typ, found := st.TypeForExpr(extract(parse.(*sqlparser.Select), 0))
+			require.True(t, found, "window function was not typed")
+			require.Equal(t, test.typ, typ.Type().String())
+			require.Equal(t, test.nullable, typ.Nullable())
+		})
+	}
+}
+
 // Tests that the types correctly picks up and sets the collation on columns
 func TestColumnCollations(t *testing.T) {
 	tests := []struct {
@@ -44,6 +44,10 @@ const nonAtomicCommitWarnMaxShards = 16
 type TxConn struct {
 	tabletGateway *TabletGateway
 	mode          vtgatepb.TransactionMode
+
+	// resultCache, if set, has the cached results of the tables written to in a
+	// transaction invalidated when the transaction commits.
+	resultCache *resultCache
 }
 
 // NewTxConn builds a new TxConn.
@@ -98,10 +102,17 @@ func (txc *TxConn) Commit(ctx context.Context, session *SafeSession) error {
 		twopc = txc.mode == vtgatepb.TransactionMode_TWOPC
 	}
 
+	var err error
 	if twopc {
-		return txc.commit2PC(ctx, session)
+		err = txc.commit2PC(ctx, session)
+	} else {
+		err = txc.commitNormal(ctx, session)
 	}
-	return txc.commitNormal(ctx, session)
+	// Some shards may have committed even if the commit failed.
+	if txc.resultCache != nil {
+		txc.resultCache.invalidate(session.GetWrittenTables())
+	}
+	return err
 }
 
 func (txc *TxConn) queryService(ctx context.Context, alias *topodatapb.TabletAlias) (queryservice.QueryService, error) {
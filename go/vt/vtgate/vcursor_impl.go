@@ -124,6 +124,17 @@ type vcursorImpl struct {
 
 	warmingReadsPercent int
 	warmingReadsChannel chan bool
+
+	// queryAttributes are the query attributes the MySQL client sent with the query, if any.
+	queryAttributes *queryAttributes
+
+	// resultCache is the value of the RESULT_CACHE directive of the query, if
+	// resultCacheSet is set.
+	resultCache    bool
+	resultCacheSet bool
+	// resultCacheable is set when the result of the query only depends on the
+	// tables it reads.
+	resultCacheable bool
 }
 
 // newVcursorImpl creates a vcursorImpl. Before creating this object, you have to separate out any marginComments that came with
@@ -229,12 +240,33 @@ func (vc *vcursorImpl) MaxMemoryRows() int {
 	return maxMemoryRows
 }
 
+// MaxCTERecursionDepth returns the cte_max_recursion_depth of the session,
+// or the cteMaxRecursionDepth flag value if the session doesn't set it.
+func (vc *vcursorImpl) MaxCTERecursionDepth() int {
+	if depth, ok := vc.safeSession.CTEMaxRecursionDepth(); ok {
+		return depth
+	}
+	return cteMaxRecursionDepth
+}
+
 // ExceedsMaxMemoryRows returns a boolean indicating whether the maxMemoryRows value has been exceeded.
 // Returns false if the max memory rows override directive is set to true.
 func (vc *vcursorImpl) ExceedsMaxMemoryRows(numRows int) bool {
 	return !vc.ignoreMaxMemoryRows && numRows > maxMemoryRows
 }
 
+// SetResultCache sets the RESULT_CACHE directive of the query.
+func (vc *vcursorImpl) SetResultCache(cache, isSet bool) {
+	vc.resultCache = cache
+	vc.resultCacheSet = isSet
+}
+
+// SetResultCacheable sets whether the result of the query only depends on the
+// tables it reads, and so can be cached.
+func (vc *vcursorImpl) SetResultCacheable(cacheable bool) {
+	vc.resultCacheable = cacheable
+}
+
 // SetIgnoreMaxMemoryRows sets the ignoreMaxMemoryRows value.
 func (vc *vcursorImpl) SetIgnoreMaxMemoryRows(ignoreMaxMemoryRows bool) {
 	vc.ignoreMaxMemoryRows = ignoreMaxMemoryRows
@@ -747,6 +779,20 @@ func (vc *vcursorImpl) SetTarget(target string) error {
 	return nil
 }
 
+// setQueryAttributes applies the query attributes the MySQL client sent with the query.
+// The tablet type they set only applies to this query, and not to the session.
+func (vc *vcursorImpl) setQueryAttributes(qa *queryAttributes) error {
+	vc.queryAttributes = qa
+	if qa == nil || qa.tabletType == topodatapb.TabletType_UNKNOWN {
+		return nil
+	}
+	if vc.safeSession.InTransaction() && qa.tabletType != topodatapb.TabletType_PRIMARY {
+		return vterrors.NewErrorf(vtrpcpb.Code_INVALID_ARGUMENT, vterrors.LockOrActiveTransaction, "can't execute the given command because you have an active transaction")
+	}
+	vc.tabletType = qa.tabletType
+	return nil
+}
+
 func ignoreKeyspace(keyspace string) bool {
 	return keyspace == "" || sqlparser.SystemSchema(keyspace)
 }
@@ -847,12 +893,16 @@ func (vc *vcursorImpl) SetQueryTimeout(maxExecutionTime int64) {
 // GetQueryTimeout implements the SessionActions interface
 // The priority of adding query timeouts -
 // 1. Query timeout comment directive.
-// 2. If the comment directive is unspecified, then we use the session setting.
-// 3. If the comment directive and session settings is unspecified, then we use the global default specified by a flag.
+// 2. If the comment directive is unspecified, then we use the QUERY_TIMEOUT_MS query attribute.
+// 3. If the comment directive and query attribute are unspecified, then we use the session setting.
+// 4. If none of the above is specified, then we use the global default specified by a flag.
 func (vc *vcursorImpl) GetQueryTimeout(queryTimeoutFromComments int) int {
 	if queryTimeoutFromComments != 0 {
 		return queryTimeoutFromComments
 	}
+	if vc.queryAttributes != nil && vc.queryAttributes.queryTimeout != 0 {
+		return vc.queryAttributes.queryTimeout
+	}
 	sessionQueryTimeout := int(vc.safeSession.GetQueryTimeout())
 	if sessionQueryTimeout != 0 {
 		return sessionQueryTimeout
@@ -68,11 +68,19 @@ var (
 	// plan cache related flag
 	queryPlanCacheMemory int64 = 32 * 1024 * 1024 // 32mb
 
+	// result cache related flags
+	resultCacheMemory int64
+	resultCacheTables []string
+	resultCacheTTL    = time.Minute
+
 	maxMemoryRows   = 300000
 	warnMemoryRows  = 30000
 	maxPayloadSize  int
 	warnPayloadSize int
 
+	// cteMaxRecursionDepth limits the number of iterations of recursive CTEs evaluated by vtgate
+	cteMaxRecursionDepth = 1000
+
 	noScatter          bool
 	enableShardRouting bool
 
@@ -126,7 +134,11 @@ func registerFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&truncateErrorLen, "truncate-error-len", truncateErrorLen, "truncate errors sent to client if they are longer than this value (0 means do not truncate)")
 	fs.IntVar(&streamBufferSize, "stream_buffer_size", streamBufferSize, "the number of bytes sent from vtgate for each stream call. It's recommended to keep this value in sync with vttablet's query-server-config-stream-buffer-size.")
 	fs.Int64Var(&queryPlanCacheMemory, "gate_query_cache_memory", queryPlanCacheMemory, "gate server query cache size in bytes, maximum amount of memory to be cached. vtgate analyzes every incoming query and generate a query plan, these plans are being cached in a lru cache. This config controls the capacity of the lru cache.")
+	fs.Int64Var(&resultCacheMemory, "result-cache-memory", resultCacheMemory, "Maximum amount of memory in bytes for vtgate to cache the results of read-only queries with. The result cache is disabled when zero.")
+	fs.StringSliceVar(&resultCacheTables, "result-cache-tables", resultCacheTables, "Tables whose query results are cached, as keyspace.table or as a table name in any keyspace. The results of other queries are cached with the RESULT_CACHE directive.")
+	fs.DurationVar(&resultCacheTTL, "result-cache-ttl", resultCacheTTL, "Maximum time to serve a cached query result for, in case the change streams invalidating the result cache miss changes.")
 	fs.IntVar(&maxMemoryRows, "max_memory_rows", maxMemoryRows, "Maximum number of rows that will be held in memory for intermediate results as well as the final result.")
+	fs.IntVar(&cteMaxRecursionDepth, "cte-max-recursion-depth", cteMaxRecursionDepth, "Maximum number of iterations of the recursive part of a common table expression evaluated by vtgate, similar to MySQL's cte_max_recursion_depth.")
 	fs.IntVar(&warnMemoryRows, "warn_memory_rows", warnMemoryRows, "Warning threshold for in-memory results. A row count higher than this amount will cause the VtGateWarnings.ResultsExceeded counter to be incremented.")
 	fs.StringVar(&defaultDDLStrategy, "ddl_strategy", defaultDDLStrategy, "Set default strategy for DDL statements. Override with @@ddl_strategy session variable")
 	fs.StringVar(&dbDDLPlugin, "dbddl_plugin", dbDDLPlugin, "controls how to handle CREATE/DROP DATABASE. use it if you are using your own database provisioning service")
@@ -332,6 +344,23 @@ func Init(
 		warmingReadsPercent,
 	)
 
+	if resultCacheMemory > 0 {
+		executor.resultCache = newResultCache(resultCacheMemory, resultCacheTables, resultCacheTTL, vstreamKeyspace(vsm))
+		tc.resultCache = executor.resultCache
+		stats.NewGaugeFunc("ResultCacheLength", "Result cache length", func() int64 {
+			return int64(executor.resultCache.store.Len())
+		})
+		stats.NewGaugeFunc("ResultCacheSize", "Result cache size", func() int64 {
+			return int64(executor.resultCache.store.UsedCapacity())
+		})
+		stats.NewGaugeFunc("ResultCacheCapacity", "Result cache capacity", func() int64 {
+			return int64(executor.resultCache.store.MaxCapacity())
+		})
+		stats.NewCounterFunc("ResultCacheEvictions", "Result cache evictions", func() int64 {
+			return executor.resultCache.store.Metrics.Evicted()
+		})
+	}
+
 	if err := executor.defaultQueryLogger(); err != nil {
 		log.Fatalf("error initializing query logger: %v", err)
 	}
@@ -50,5 +50,21 @@ func (tm *TabletManager) CheckThrottler(ctx context.Context, req *tabletmanagerd
 	if checkResult.Error != nil {
 		resp.Error = checkResult.Error.Error()
 	}
+	if len(checkResult.Metrics) > 0 {
+		resp.Metrics = make(map[string]*tabletmanagerdatapb.CheckThrottlerResponse_Metric, len(checkResult.Metrics))
+		for name, metricResult := range checkResult.Metrics {
+			metric := &tabletmanagerdatapb.CheckThrottlerResponse_Metric{
+				Name:       name,
+				StatusCode: int32(metricResult.StatusCode),
+				Value:      metricResult.Value,
+				Threshold:  metricResult.Threshold,
+				Message:    metricResult.Message,
+			}
+			if metricResult.Error != nil {
+				metric.Error = metricResult.Error.Error()
+			}
+			resp.Metrics[name] = metric
+		}
+	}
 	return resp, nil
 }
@@ -0,0 +1,484 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admission provides the vttablet workload class admission control.
+// See the Controller struct for details.
+package admission
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"vitess.io/vitess/go/acl"
+	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/log"
+	"vitess.io/vitess/go/vt/logutil"
+	"vitess.io/vitess/go/vt/servenv"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	querypb "vitess.io/vitess/go/vt/proto/query"
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+// Resource is a vttablet pool which is shared by the workload classes.
+type Resource int
+
+const (
+	// Queries are the connections of the query pool, which are used by
+	// queries that are executed outside of a transaction.
+	Queries Resource = iota
+	// Transactions are the connections of the transaction pool.
+	Transactions
+
+	numResources = 2
+)
+
+var resourceNames = [numResources]string{"Queries", "Transactions"}
+
+// String returns the name of the resource.
+func (r Resource) String() string {
+	return resourceNames[r]
+}
+
+// DefaultClassName is the name of the class of the requests which match no
+// other class.
+const DefaultClassName = "default"
+
+// Request describes a query or a transaction for its classification.
+type Request struct {
+	WorkloadName    string
+	ImmediateCaller *querypb.VTGateCallerID
+	EffectiveCaller *vtrpcpb.CallerID
+
+	// The following fields are only set for queries. They are matched against
+	// the query rules of the classes.
+	Query          string
+	PlanID         planbuilder.PlanType
+	TableNames     []string
+	RemoteAddr     string
+	Username       string
+	BindVars       map[string]*querypb.BindVariable
+	MarginComments sqlparser.MarginComments
+}
+
+func init() {
+	tabletenv.RegisterAdmissionRuleVerifier(func(ruleInfo map[string]any) error {
+		_, err := rules.BuildQueryRule(ruleInfo)
+		return err
+	})
+}
+
+// DoneFunc is returned by Admit() and must be called by the caller once the
+// request is done.
+type DoneFunc func()
+
+func doneNothing() {}
+
+// Controller reserves capacity of the query and transaction pools per
+// workload class, so that e.g. batch jobs cannot starve interactive OLTP
+// traffic of the same pools.
+//
+// Each request is classified by its WORKLOAD_NAME directive, its caller ID or
+// query rules. Of each pool, a class is guaranteed a share which no other
+// class can use, and it can use at most its max share. The capacity which is
+// not guaranteed to any class is shared by all of them.
+// A request for which there is no capacity is queued, and woken up in arrival
+// order when capacity is released. Queued requests whose class is below its
+// guaranteed share are woken up first. If a request cannot get a slot within
+// the queue timeout of its class, it is rejected.
+type Controller struct {
+	// Immutable fields.
+	enabled bool
+	dryRun  bool
+	// classes are the configured classes in the order they are matched.
+	// defaultClass is one of them if it was configured.
+	classes      []*class
+	defaultClass *class
+
+	// waits counts per class and resource how many requests were queued.
+	// waitsDryRun counts in dry-run mode how many requests would have been queued.
+	// rejections counts per class and resource how many requests were rejected
+	// because they could not get a slot within the queue timeout.
+	waits, waitsDryRun, rejections *stats.CountersWithMultiLabels
+	waitTimings                    *servenv.MultiTimingsWrapper
+
+	logDryRun *logutil.ThrottledLogger
+
+	mu       sync.Mutex
+	capacity [numResources]int
+}
+
+// class is the state of a workload class.
+type class struct {
+	name            string
+	guaranteedShare float64
+	maxShare        float64
+	queueTimeout    time.Duration
+
+	workloadNames map[string]bool
+	usernames     map[string]bool
+	principals    map[string]bool
+	// rules is nil if the class has no query rules.
+	rules *rules.Rules
+
+	// NOTE: The following fields are guarded by Controller.mu.
+	// inFlight counts per resource how many requests hold a slot.
+	inFlight [numResources]int
+	// queues holds per resource the requests which wait for a slot, in
+	// arrival order.
+	queues [numResources][]*waiter
+}
+
+// waiter is a queued request.
+type waiter struct {
+	// admitted is closed when the request got a slot.
+	admitted chan struct{}
+}
+
+// New returns a Controller object.
+func New(env tabletenv.Env) *Controller {
+	config := env.Config()
+	ac := &Controller{
+		enabled: config.AdmissionControl.Mode != tabletenv.Disable,
+		dryRun:  config.AdmissionControl.Mode == tabletenv.Dryrun,
+		waits: env.Exporter().NewCountersWithMultiLabels(
+			"AdmissionControlWaits",
+			"Number of requests that were queued because their workload class had no capacity left",
+			[]string{"Class", "Resource"}),
+		waitsDryRun: env.Exporter().NewCountersWithMultiLabels(
+			"AdmissionControlWaitsDryRun",
+			"Dry run number of requests that would have been queued",
+			[]string{"Class", "Resource"}),
+		rejections: env.Exporter().NewCountersWithMultiLabels(
+			"AdmissionControlRejections",
+			"Number of requests that were rejected because they exceeded the queue timeout of their workload class",
+			[]string{"Class", "Resource"}),
+		waitTimings: env.Exporter().NewMultiTimings(
+			"AdmissionControlWaitTimings",
+			"Time spent by queued requests waiting for a slot",
+			[]string{"Class", "Resource"}),
+		logDryRun: logutil.NewThrottledLogger("AdmissionControl DryRun", 5*time.Second),
+	}
+	ac.capacity[Queries] = config.OltpReadPool.Size
+	ac.capacity[Transactions] = config.TxPool.Size
+
+	for _, classConfig := range config.AdmissionControl.Classes {
+		c := newClass(classConfig)
+		ac.classes = append(ac.classes, c)
+		if c.name == DefaultClassName {
+			ac.defaultClass = c
+		}
+	}
+	if ac.defaultClass == nil {
+		ac.defaultClass = newClass(&tabletenv.AdmissionClassConfig{Name: DefaultClassName})
+		ac.classes = append(ac.classes, ac.defaultClass)
+	}
+
+	env.Exporter().NewGaugesFuncWithMultiLabels(
+		"AdmissionControlInFlight",
+		"Number of requests which hold a slot, per workload class and resource",
+		[]string{"Class", "Resource"},
+		func() map[string]int64 {
+			return ac.counts(func(c *class, r Resource) int { return c.inFlight[r] })
+		})
+	env.Exporter().NewGaugesFuncWithMultiLabels(
+		"AdmissionControlQueued",
+		"Number of requests which wait for a slot, per workload class and resource",
+		[]string{"Class", "Resource"},
+		func() map[string]int64 {
+			return ac.counts(func(c *class, r Resource) int { return len(c.queues[r]) })
+		})
+	return ac
+}
+
+func newClass(config *tabletenv.AdmissionClassConfig) *class {
+	c := &class{
+		name:            config.Name,
+		guaranteedShare: config.GuaranteedShare,
+		maxShare:        config.MaxShare,
+		queueTimeout:    config.QueueTimeout.Get(),
+		workloadNames:   toSet(config.WorkloadNames),
+		usernames:       toSet(config.Usernames),
+		principals:      toSet(config.Principals),
+	}
+	if c.maxShare == 0 {
+		c.maxShare = 1
+	}
+	for _, ruleInfo := range config.Rules {
+		qr, err := rules.BuildQueryRule(ruleInfo)
+		if err != nil {
+			// TabletConfig.Verify() rejects invalid rules, so this only happens if it was not called.
+			log.Errorf("Ignoring invalid query rule of admission control class %v: %v", c.name, err)
+			continue
+		}
+		if c.rules == nil {
+			c.rules = rules.New()
+		}
+		c.rules.Add(qr)
+	}
+	return c
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// matches returns true if the request belongs to the class.
+func (c *class) matches(req *Request) bool {
+	if req.WorkloadName != "" && c.workloadNames[req.WorkloadName] {
+		return true
+	}
+	if username := callerid.GetUsername(req.ImmediateCaller); username != "" && c.usernames[username] {
+		return true
+	}
+	if principal := callerid.GetPrincipal(req.EffectiveCaller); principal != "" && c.principals[principal] {
+		return true
+	}
+	if req.Query == "" || c.rules == nil {
+		return false
+	}
+	// The actions of the rules don't apply: classifying a request must not
+	// consume the capacity of throttling rules.
+	return c.rules.FilterByPlan(req.Query, req.PlanID, req.TableNames...).Matches(req.RemoteAddr, req.Username, req.BindVars, req.MarginComments)
+}
+
+// Enabled returns true if admission control is enabled, including the
+// dry-run mode.
+func (ac *Controller) Enabled() bool {
+	return ac.enabled
+}
+
+// Classify returns the name of the class the request belongs to.
+func (ac *Controller) Classify(req *Request) string {
+	return ac.classify(req).name
+}
+
+func (ac *Controller) classify(req *Request) *class {
+	for _, c := range ac.classes {
+		if c != ac.defaultClass && c.matches(req) {
+			return c
+		}
+	}
+	return ac.defaultClass
+}
+
+// SetCapacity updates the capacity of a resource, e.g. after the pool was
+// resized.
+func (ac *Controller) SetCapacity(r Resource, capacity int) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	ac.capacity[r] = capacity
+	ac.admitWaitersLocked(r)
+}
+
+// Admit blocks until the class of the request has capacity of the resource.
+// "done" is != nil if err == nil and must be called once the request does not
+// use the resource anymore.
+// "err" is not nil if a) the context is done or b) the queue timeout of the
+// class was exceeded.
+func (ac *Controller) Admit(ctx context.Context, r Resource, req *Request) (done DoneFunc, err error) {
+	if !ac.enabled {
+		return doneNothing, nil
+	}
+	c := ac.classify(req)
+
+	ac.mu.Lock()
+	if len(c.queues[r]) == 0 && ac.canAdmitLocked(c, r) {
+		c.inFlight[r]++
+		ac.mu.Unlock()
+		return ac.doneFunc(c, r), nil
+	}
+	if ac.dryRun {
+		c.inFlight[r]++
+		ac.mu.Unlock()
+		ac.waitsDryRun.Add([]string{c.name, r.String()}, 1)
+		ac.logDryRun.Warningf("Would have queued request of workload class %v for %v because the class has no capacity left", c.name, r)
+		return ac.doneFunc(c, r), nil
+	}
+	w := &waiter{admitted: make(chan struct{})}
+	c.queues[r] = append(c.queues[r], w)
+	ac.mu.Unlock()
+
+	ac.waits.Add([]string{c.name, r.String()}, 1)
+	start := time.Now()
+	defer ac.waitTimings.Record([]string{c.name, r.String()}, start)
+
+	var timeout <-chan time.Time
+	if c.queueTimeout > 0 {
+		timer := time.NewTimer(c.queueTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+	select {
+	case <-w.admitted:
+		return ac.doneFunc(c, r), nil
+	case <-timeout:
+		err = vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED,
+			"admission control: workload class %v exceeded its queue timeout (%v) waiting for %v", c.name, c.queueTimeout, r)
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	select {
+	case <-w.admitted:
+		// We got a slot after all, but the caller will not use it.
+		c.inFlight[r]--
+		ac.admitWaitersLocked(r)
+	default:
+		c.removeWaiterLocked(r, w)
+	}
+	if vterrors.Code(err) == vtrpcpb.Code_RESOURCE_EXHAUSTED {
+		ac.rejections.Add([]string{c.name, r.String()}, 1)
+	}
+	return nil, err
+}
+
+func (ac *Controller) doneFunc(c *class, r Resource) DoneFunc {
+	var once sync.Once
+	return func() {
+		once.Do(func() { ac.release(c, r) })
+	}
+}
+
+func (ac *Controller) release(c *class, r Resource) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	c.inFlight[r]--
+	ac.admitWaitersLocked(r)
+}
+
+func (c *class) removeWaiterLocked(r Resource, w *waiter) {
+	for i, queued := range c.queues[r] {
+		if queued == w {
+			c.queues[r] = append(c.queues[r][:i], c.queues[r][i+1:]...)
+			return
+		}
+	}
+}
+
+// limitsLocked returns the number of slots of the resource which are
+// guaranteed to the class, and the max number of slots it can use.
+func (ac *Controller) limitsLocked(c *class, r Resource) (guaranteed, max int) {
+	capacity := float64(ac.capacity[r])
+	guaranteed = int(math.Floor(c.guaranteedShare * capacity))
+	max = int(math.Ceil(c.maxShare * capacity))
+	return guaranteed, max
+}
+
+// sharedLocked returns the number of slots of the resource which are not
+// guaranteed to any class, and how many of them are in use.
+func (ac *Controller) sharedLocked(r Resource) (shared, inUse int) {
+	shared = ac.capacity[r]
+	for _, c := range ac.classes {
+		guaranteed, _ := ac.limitsLocked(c, r)
+		shared -= guaranteed
+		if c.inFlight[r] > guaranteed {
+			inUse += c.inFlight[r] - guaranteed
+		}
+	}
+	return shared, inUse
+}
+
+func (ac *Controller) canAdmitLocked(c *class, r Resource) bool {
+	guaranteed, max := ac.limitsLocked(c, r)
+	if c.inFlight[r] >= max {
+		return false
+	}
+	if c.inFlight[r] < guaranteed {
+		return true
+	}
+	shared, inUse := ac.sharedLocked(r)
+	return inUse < shared
+}
+
+// admitWaitersLocked hands out the available slots of the resource to the
+// queued requests. Classes below their guaranteed share go first.
+func (ac *Controller) admitWaitersLocked(r Resource) {
+	for _, belowGuaranteed := range []bool{true, false} {
+		for _, c := range ac.classes {
+			for len(c.queues[r]) > 0 {
+				if guaranteed, _ := ac.limitsLocked(c, r); belowGuaranteed && c.inFlight[r] >= guaranteed {
+					break
+				}
+				if !ac.canAdmitLocked(c, r) {
+					break
+				}
+				w := c.queues[r][0]
+				c.queues[r] = c.queues[r][1:]
+				c.inFlight[r]++
+				close(w.admitted)
+			}
+		}
+	}
+}
+
+func (ac *Controller) counts(f func(c *class, r Resource) int) map[string]int64 {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	counts := make(map[string]int64, len(ac.classes)*numResources)
+	for _, c := range ac.classes {
+		for r := Resource(0); r < numResources; r++ {
+			counts[c.name+"."+r.String()] = int64(f(c, r))
+		}
+	}
+	return counts
+}
+
+// ServeHTTP lists the workload classes with their limits and current usage.
+func (ac *Controller) ServeHTTP(response http.ResponseWriter, request *http.Request) {
+	if err := acl.CheckAccessHTTP(request, acl.DEBUGGING); err != nil {
+		acl.SendError(response, err)
+		return
+	}
+	response.Header().Set("Content-Type", "text/plain")
+	if !ac.enabled {
+		response.Write([]byte("disabled\n"))
+		return
+	}
+	if ac.dryRun {
+		response.Write([]byte("Mode: dry run\n"))
+	}
+
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+	for r := Resource(0); r < numResources; r++ {
+		shared, inUse := ac.sharedLocked(r)
+		response.Write([]byte(fmt.Sprintf("%v: capacity %d, shared %d/%d\n", r, ac.capacity[r], inUse, shared)))
+		for _, c := range ac.classes {
+			guaranteed, max := ac.limitsLocked(c, r)
+			response.Write([]byte(fmt.Sprintf("  %v: in flight %d, queued %d, guaranteed %d, max %d, queue timeout %v\n",
+				c.name, c.inFlight[r], len(c.queues[r]), guaranteed, max, c.queueTimeout)))
+		}
+	}
+}
@@ -0,0 +1,321 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admission
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/callerid"
+	"vitess.io/vitess/go/vt/sqlparser"
+	"vitess.io/vitess/go/vt/vtenv"
+	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+
+	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
+)
+
+func newTestController(mode string, classes ...*tabletenv.AdmissionClassConfig) *Controller {
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.OltpReadPool.Size = 10
+	cfg.TxPool.Size = 10
+	cfg.AdmissionControl.Mode = mode
+	cfg.AdmissionControl.Classes = classes
+	ac := New(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "AdmissionControlTest"))
+	ac.waits.ResetAll()
+	ac.waitsDryRun.ResetAll()
+	ac.rejections.ResetAll()
+	return ac
+}
+
+func admitN(t *testing.T, ac *Controller, r Resource, req *Request, n int) []DoneFunc {
+	var dones []DoneFunc
+	for i := 0; i < n; i++ {
+		done, err := ac.Admit(context.Background(), r, req)
+		require.NoError(t, err)
+		dones = append(dones, done)
+	}
+	return dones
+}
+
+func TestAdmissionControlDisabled(t *testing.T) {
+	ac := newTestController(tabletenv.Disable, &tabletenv.AdmissionClassConfig{Name: "batch", MaxShare: 0.1, WorkloadNames: []string{"batch"}})
+
+	dones := admitN(t, ac, Queries, &Request{WorkloadName: "batch"}, 20)
+	for _, done := range dones {
+		done()
+	}
+	assert.Empty(t, ac.waits.Counts())
+}
+
+func TestAdmissionControlClassify(t *testing.T) {
+	ac := newTestController(tabletenv.Enable,
+		&tabletenv.AdmissionClassConfig{Name: "batch", WorkloadNames: []string{"batch", "etl"}},
+		&tabletenv.AdmissionClassConfig{Name: "reports", Usernames: []string{"reporter"}},
+		&tabletenv.AdmissionClassConfig{Name: "backoffice", Principals: []string{"admin"}},
+		&tabletenv.AdmissionClassConfig{Name: "archive", Rules: []map[string]any{
+			{"Name": "archive", "TableNames": []any{"archive"}},
+		}},
+		&tabletenv.AdmissionClassConfig{Name: "audit", Rules: []map[string]any{
+			{"Name": "throttled audit", "TableNames": []any{"audit"}, "Action": "THROTTLE", "MaxQPS": json.Number("1")},
+			{"Name": "logged audit", "TableNames": []any{"audit_log"}, "Action": "LOG", "SampleRate": json.Number("0.000001")},
+		}},
+	)
+
+	tcases := []struct {
+		name string
+		req  *Request
+		want string
+	}{{
+		name: "workload name",
+		req:  &Request{WorkloadName: "etl"},
+		want: "batch",
+	}, {
+		name: "username",
+		req:  &Request{ImmediateCaller: callerid.NewImmediateCallerID("reporter")},
+		want: "reports",
+	}, {
+		name: "principal",
+		req:  &Request{EffectiveCaller: callerid.NewEffectiveCallerID("admin", "", "")},
+		want: "backoffice",
+	}, {
+		name: "first matching class",
+		req:  &Request{WorkloadName: "batch", ImmediateCaller: callerid.NewImmediateCallerID("reporter")},
+		want: "batch",
+	}, {
+		name: "query rule",
+		req:  &Request{Query: "select * from archive", PlanID: planbuilder.PlanSelect, TableNames: []string{"archive"}},
+		want: "archive",
+	}, {
+		name: "query rule with the throttle action",
+		req:  &Request{Query: "select * from audit", PlanID: planbuilder.PlanSelect, TableNames: []string{"audit"}},
+		want: "audit",
+	}, {
+		name: "query rule with the throttle action, classified again",
+		req:  &Request{Query: "select * from audit", PlanID: planbuilder.PlanSelect, TableNames: []string{"audit"}},
+		want: "audit",
+	}, {
+		name: "query rule with the log action",
+		req:  &Request{Query: "select * from audit_log", PlanID: planbuilder.PlanSelect, TableNames: []string{"audit_log"}},
+		want: "audit",
+	}, {
+		name: "query rule of another table",
+		req:  &Request{Query: "select * from t1", PlanID: planbuilder.PlanSelect, TableNames: []string{"t1"}, MarginComments: sqlparser.MarginComments{}},
+		want: DefaultClassName,
+	}, {
+		name: "transactions are not matched against query rules",
+		req:  &Request{TableNames: []string{"archive"}},
+		want: DefaultClassName,
+	}, {
+		name: "no match",
+		req:  &Request{WorkloadName: "oltp"},
+		want: DefaultClassName,
+	}}
+	for _, tcase := range tcases {
+		t.Run(tcase.name, func(t *testing.T) {
+			assert.Equal(t, tcase.want, ac.Classify(tcase.req))
+		})
+	}
+}
+
+func TestAdmissionControlShares(t *testing.T) {
+	ac := newTestController(tabletenv.Enable,
+		&tabletenv.AdmissionClassConfig{Name: "oltp", GuaranteedShare: 0.5, WorkloadNames: []string{"oltp"}},
+		&tabletenv.AdmissionClassConfig{Name: "batch", MaxShare: 0.3, QueueTimeout: 0.01, WorkloadNames: []string{"batch"}},
+	)
+	oltp := &Request{WorkloadName: "oltp"}
+	batch := &Request{WorkloadName: "batch"}
+
+	// batch is limited to its max share.
+	batchDones := admitN(t, ac, Queries, batch, 3)
+	_, err := ac.Admit(context.Background(), Queries, batch)
+	require.Error(t, err)
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	assert.EqualValues(t, 1, ac.waits.Counts()["batch.Queries"])
+	assert.EqualValues(t, 1, ac.rejections.Counts()["batch.Queries"])
+
+	// Other resources are limited separately.
+	txDones := admitN(t, ac, Transactions, batch, 3)
+
+	// oltp gets its guaranteed share and what is left of the shared capacity.
+	oltpDones := admitN(t, ac, Queries, oltp, 7)
+	admitted := make(chan DoneFunc)
+	go func() {
+		done, err := ac.Admit(context.Background(), Queries, oltp)
+		assert.NoError(t, err)
+		admitted <- done
+	}()
+	require.Eventually(t, func() bool {
+		return ac.counts(func(c *class, r Resource) int { return len(c.queues[r]) })["oltp.Queries"] == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// A released slot of batch goes to the queued oltp request.
+	batchDones[0]()
+	oltpDones = append(oltpDones, <-admitted)
+	assert.EqualValues(t, 1, ac.waits.Counts()["oltp.Queries"])
+
+	inFlight := ac.counts(func(c *class, r Resource) int { return c.inFlight[r] })
+	assert.EqualValues(t, 8, inFlight["oltp.Queries"])
+	assert.EqualValues(t, 2, inFlight["batch.Queries"])
+	assert.EqualValues(t, 3, inFlight["batch.Transactions"])
+	assert.EqualValues(t, 0, inFlight["default.Queries"])
+
+	// Calling done more than once releases the slot only once.
+	batchDones[0]()
+	for _, done := range append(append(oltpDones, batchDones[1:]...), txDones...) {
+		done()
+	}
+	inFlight = ac.counts(func(c *class, r Resource) int { return c.inFlight[r] })
+	for key, count := range inFlight {
+		assert.Zero(t, count, key)
+	}
+}
+
+func TestAdmissionControlGuaranteedShareFirst(t *testing.T) {
+	ac := newTestController(tabletenv.Enable,
+		&tabletenv.AdmissionClassConfig{Name: "batch", WorkloadNames: []string{"batch"}},
+		&tabletenv.AdmissionClassConfig{Name: "oltp", GuaranteedShare: 0.2, WorkloadNames: []string{"oltp"}},
+	)
+	ac.SetCapacity(Transactions, 5)
+	oltp := &Request{WorkloadName: "oltp"}
+	batch := &Request{WorkloadName: "batch"}
+
+	// batch can use the whole shared capacity.
+	dones := admitN(t, ac, Transactions, batch, 4)
+	dones = append(dones, admitN(t, ac, Transactions, oltp, 1)...)
+
+	batchAdmitted := make(chan DoneFunc)
+	go func() {
+		done, err := ac.Admit(context.Background(), Transactions, batch)
+		assert.NoError(t, err)
+		batchAdmitted <- done
+	}()
+	require.Eventually(t, func() bool {
+		return ac.counts(func(c *class, r Resource) int { return len(c.queues[r]) })["batch.Transactions"] == 1
+	}, 5*time.Second, time.Millisecond)
+
+	oltpAdmitted := make(chan DoneFunc)
+	go func() {
+		done, err := ac.Admit(context.Background(), Transactions, oltp)
+		assert.NoError(t, err)
+		oltpAdmitted <- done
+	}()
+	require.Eventually(t, func() bool {
+		return ac.counts(func(c *class, r Resource) int { return len(c.queues[r]) })["oltp.Transactions"] == 1
+	}, 5*time.Second, time.Millisecond)
+
+	// The guaranteed slot of oltp goes to oltp, even though batch queued first.
+	dones[4]()
+	dones[4] = <-oltpAdmitted
+
+	// The shared slot goes to batch.
+	dones[0]()
+	dones[0] = <-batchAdmitted
+
+	for _, done := range dones {
+		done()
+	}
+}
+
+func TestAdmissionControlContextDone(t *testing.T) {
+	ac := newTestController(tabletenv.Enable)
+	ac.SetCapacity(Queries, 1)
+
+	done := admitN(t, ac, Queries, &Request{}, 1)[0]
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := ac.Admit(ctx, Queries, &Request{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Empty(t, ac.rejections.Counts())
+
+	// The request left the queue.
+	done()
+	done = admitN(t, ac, Queries, &Request{}, 1)[0]
+	done()
+}
+
+func TestAdmissionControlSetCapacity(t *testing.T) {
+	ac := newTestController(tabletenv.Enable)
+	ac.SetCapacity(Queries, 1)
+
+	done := admitN(t, ac, Queries, &Request{}, 1)[0]
+	defer done()
+	admitted := make(chan DoneFunc)
+	go func() {
+		done, err := ac.Admit(context.Background(), Queries, &Request{})
+		assert.NoError(t, err)
+		admitted <- done
+	}()
+	require.Eventually(t, func() bool {
+		return ac.counts(func(c *class, r Resource) int { return len(c.queues[r]) })["default.Queries"] == 1
+	}, 5*time.Second, time.Millisecond)
+
+	ac.SetCapacity(Queries, 2)
+	(<-admitted)()
+}
+
+func TestAdmissionControlDryRun(t *testing.T) {
+	ac := newTestController(tabletenv.Dryrun, &tabletenv.AdmissionClassConfig{Name: "batch", MaxShare: 0.1, WorkloadNames: []string{"batch"}})
+
+	dones := admitN(t, ac, Queries, &Request{WorkloadName: "batch"}, 3)
+	assert.EqualValues(t, 2, ac.waitsDryRun.Counts()["batch.Queries"])
+	assert.Empty(t, ac.waits.Counts())
+	for _, done := range dones {
+		done()
+	}
+}
+
+func TestAdmissionControlHTTPHandler(t *testing.T) {
+	ac := newTestController(tabletenv.Enable, &tabletenv.AdmissionClassConfig{Name: "batch", GuaranteedShare: 0.1, MaxShare: 0.5, QueueTimeout: 2, WorkloadNames: []string{"batch"}})
+	done := admitN(t, ac, Transactions, &Request{WorkloadName: "batch"}, 1)[0]
+	defer done()
+
+	req, err := http.NewRequest("GET", "/path-is-ignored-in-test", nil)
+	require.NoError(t, err)
+	rr := httptest.NewRecorder()
+	ac.ServeHTTP(rr, req)
+
+	want := `Queries: capacity 10, shared 0/9
+  batch: in flight 0, queued 0, guaranteed 1, max 5, queue timeout 2s
+  default: in flight 0, queued 0, guaranteed 0, max 10, queue timeout 0s
+Transactions: capacity 10, shared 0/9
+  batch: in flight 1, queued 0, guaranteed 1, max 5, queue timeout 2s
+  default: in flight 0, queued 0, guaranteed 0, max 10, queue timeout 0s
+`
+	assert.Equal(t, want, rr.Body.String())
+}
+
+func TestAdmissionControlVerifyRules(t *testing.T) {
+	config := tabletenv.NewDefaultConfig()
+	config.AdmissionControl.Classes = []*tabletenv.AdmissionClassConfig{{Name: "reports", Rules: []map[string]any{{
+		"Name":         "large ids",
+		"BindVarConds": []any{map[string]any{"Name": "id", "OnAbsent": false, "Operator": ">", "Value": json.Number("5")}},
+	}}}}
+	require.NoError(t, config.Verify())
+
+	// Numbers which were not decoded as json.Number are invalid, as in query rules files.
+	config.AdmissionControl.Classes[0].Rules[0]["BindVarConds"] = []any{map[string]any{"Name": "id", "OnAbsent": false, "Operator": ">", "Value": 5.0}}
+	require.EqualError(t, config.Verify(), "invalid query rule of admission control class reports: want string or number: 5")
+}
@@ -22,7 +22,9 @@ import (
 	"fmt"
 	"io"
 	"math/rand/v2"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"golang.org/x/sync/semaphore"
@@ -53,6 +55,7 @@ type QueryGenerator interface {
 	GenerateAckQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePostponeQuery(ids []string) (string, map[string]*querypb.BindVariable)
 	GeneratePurgeQuery(timeCutoff int64) (string, map[string]*querypb.BindVariable)
+	GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery
 }
 
 type messageReceiver struct {
@@ -166,6 +169,28 @@ type receiverWithStatus struct {
 // The Purge thread
 // This thread is mostly independent. It wakes up periodically
 // to delete old rows that were successfully acked.
+//
+// Dead letters
+// If the table has a max retries setting, a message that has been
+// sent that many times without being acked is not sent again.
+// Instead, it's moved to the dead-letter table in the same
+// transaction, or marked as failed by setting its time_next to
+// null if there is no dead-letter table. Dead letters can be
+// replayed through vtgate with regular DMLs: by moving them back
+// from the dead-letter table, or by resetting the time_next and
+// epoch of failed messages.
+//
+// Partitioned delivery
+// If the table has a partition key, messages that share a key are
+// sent one at a time, in time_scheduled order. The poller only reads
+// the first unacked message of every partition, and row events don't
+// add messages to the cache directly. Instead, they trigger the poller,
+// which is how the next message of a partition is sent once the
+// previous one is acked. Messages with a null partition key are not
+// ordered. Ordering is only guaranteed within a shard, so the partition
+// key must be the sharding key of the table, or a column that
+// determines it, for all the messages of a partition to be in the
+// same shard.
 type messageManager struct {
 	tsv TabletService
 	vs  VStreamer
@@ -176,11 +201,18 @@ type messageManager struct {
 	purgeAfter   time.Duration
 	minBackoff   time.Duration
 	maxBackoff   time.Duration
+	maxRetries   int64
+	partitioned  bool
 	batchSize    int
 	pollerTicks  *timer.Timer
 	purgeTicks   *timer.Timer
 	postponeSema *semaphore.Weighted
 
+	// pollTriggered is set while a trigger of the poller caused by a
+	// row event of a partitioned table is pending, so that bursts of
+	// events don't start a goroutine each.
+	pollTriggered atomic.Bool
+
 	mu     sync.Mutex
 	isOpen bool
 	// cond waits on curReceiver == -1 || cache.IsEmpty():
@@ -236,6 +268,9 @@ type messageManager struct {
 	ackQuery                  *sqlparser.ParsedQuery
 	postponeQuery             *sqlparser.ParsedQuery
 	purgeQuery                *sqlparser.ParsedQuery
+	// deadLetterQueries are executed in one transaction to
+	// dead-letter messages that reached maxRetries.
+	deadLetterQueries []*sqlparser.ParsedQuery
 }
 
 // newMessageManager creates a new message manager.
@@ -253,6 +288,8 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		purgeAfter:      table.MessageInfo.PurgeAfterDuration,
 		minBackoff:      table.MessageInfo.MinBackoff,
 		maxBackoff:      table.MessageInfo.MaxBackoff,
+		maxRetries:      int64(table.MessageInfo.MaxRetries),
+		partitioned:     table.MessageInfo.PartitionKey != "",
 		batchSize:       table.MessageInfo.BatchSize,
 		cache:           newCache(table.MessageInfo.CacheSize),
 		pollerTicks:     timer.NewTimer(table.MessageInfo.PollInterval),
@@ -270,11 +307,26 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 			Filter: vsQuery,
 		}},
 	}
-	mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
-		// There should be a poller_idx defined on (time_acked, priority, time_next desc)
-		// for this to be as efficient as possible
-		"select priority, time_next, epoch, time_acked, %s from %v where time_acked is null and time_next < %a order by priority, time_next desc limit %a",
-		columnList, mm.name, ":time_next", ":max")
+	if mm.partitioned {
+		// A message is pending only if there is no earlier unacked message in its
+		// partition. Failed messages don't hold back their partition. A null time_scheduled
+		// sorts first, and messages scheduled at the same time are ordered by id. There should be
+		// an index defined on (<partition key>, time_acked, time_scheduled) for this
+		// to be as efficient as possible.
+		partitionKey := sqlparser.NewIdentifierCI(table.MessageInfo.PartitionKey)
+		mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
+			"select priority, time_next, epoch, time_acked, %s from %v as m where time_acked is null and time_next < %a and not exists ("+
+				"select 1 from %v as prev where prev.%v = m.%v and prev.time_acked is null and prev.time_next is not null and "+
+				"(coalesce(prev.time_scheduled, 0) < coalesce(m.time_scheduled, 0) or coalesce(prev.time_scheduled, 0) = coalesce(m.time_scheduled, 0) and prev.id < m.id)"+
+				") order by priority, time_next desc limit %a",
+			columnList, mm.name, ":time_next", mm.name, partitionKey, partitionKey, ":max")
+	} else {
+		mm.readByPriorityAndTimeNext = sqlparser.BuildParsedQuery(
+			// There should be a poller_idx defined on (time_acked, priority, time_next desc)
+			// for this to be as efficient as possible
+			"select priority, time_next, epoch, time_acked, %s from %v where time_acked is null and time_next < %a order by priority, time_next desc limit %a",
+			columnList, mm.name, ":time_next", ":max")
+	}
 	mm.ackQuery = sqlparser.BuildParsedQuery(
 		"update %v set time_acked = %a, time_next = null where id in %a and time_acked is null",
 		mm.name, ":time_acked", "::ids")
@@ -282,6 +334,7 @@ func newMessageManager(tsv TabletService, vs VStreamer, table *schema.Table, pos
 		"delete from %v where time_acked < %a limit 500", mm.name, ":time_acked")
 
 	mm.postponeQuery = buildPostponeQuery(mm.name, mm.minBackoff, mm.maxBackoff)
+	mm.deadLetterQueries = buildDeadLetterQueries(table)
 
 	return mm
 }
@@ -332,6 +385,42 @@ func buildPostponeQuery(name sqlparser.IdentifierCS, minBackoff, maxBackoff time
 	return sqlparser.BuildParsedQuery(buf.String(), args...)
 }
 
+// buildDeadLetterQueries builds the queries that dead-letter messages.
+// If there is a dead-letter table, messages are copied to it as new
+// messages and deleted from the message table. Otherwise, they're
+// marked as failed by setting time_next to null, which prevents them
+// from being sent and purged.
+func buildDeadLetterQueries(t *schema.Table) []*sqlparser.ParsedQuery {
+	if t.MessageInfo.MaxRetries == 0 {
+		return nil
+	}
+	if t.MessageInfo.DeadLetterTable == "" {
+		return []*sqlparser.ParsedQuery{sqlparser.BuildParsedQuery(
+			"update %v set time_next = null where id in %a and time_acked is null",
+			t.Name, "::ids")}
+	}
+
+	// All columns other than the ones managed by the messager are copied,
+	// even if they're not streamed to subscribers.
+	buf := sqlparser.NewTrackedBuffer(nil)
+	for _, field := range t.Fields {
+		switch strings.ToLower(field.Name) {
+		case "priority", "time_next", "epoch", "time_acked":
+			continue
+		}
+		buf.Myprintf(", %v", sqlparser.NewIdentifierCI(field.Name))
+	}
+	columnList := buf.String()
+	return []*sqlparser.ParsedQuery{
+		sqlparser.BuildParsedQuery(
+			"insert into %v(priority, time_next, epoch, time_acked%s) select priority, %a, 0, null%s from %v where id in %a and time_acked is null",
+			sqlparser.NewIdentifierCS(t.MessageInfo.DeadLetterTable), columnList, ":time_now", columnList, t.Name, "::ids"),
+		sqlparser.BuildParsedQuery(
+			"delete from %v where id in %a and time_acked is null",
+			t.Name, "::ids"),
+	}
+}
+
 // buildSelectColumnList is a convenience function that
 // builds a 'select' list for the user-defined columns.
 func buildSelectColumnList(t *schema.Table) string {
@@ -549,11 +638,16 @@ func (mm *messageManager) runSend() {
 
 			// Fetch rows from cache.
 			lateCount := int64(0)
+			var deadIDs []string
 			for i := 0; i < mm.batchSize; i++ {
 				mr := mm.cache.Pop()
 				if mr == nil {
 					break
 				}
+				if mm.isDead(mr) {
+					deadIDs = append(deadIDs, mr.Row[0].ToString())
+					continue
+				}
 				if mr.Epoch >= 1 {
 					lateCount++
 				}
@@ -561,6 +655,11 @@ func (mm *messageManager) runSend() {
 			}
 			MessageStats.Add([]string{mm.name.String(), "Delayed"}, lateCount)
 
+			if deadIDs != nil {
+				mm.wg.Add(1)
+				go mm.deadLetter(deadIDs) // calls the offsetting mm.wg.Done()
+			}
+
 			// If we have rows to send, break out of this loop.
 			if rows != nil {
 				break
@@ -642,6 +741,43 @@ func (mm *messageManager) postpone(ctx context.Context, tsv TabletService, ackWa
 	return nil
 }
 
+// isDead returns true if the message has been sent maxRetries times.
+func (mm *messageManager) isDead(mr *MessageRow) bool {
+	return mm.maxRetries > 0 && mr.Epoch >= mm.maxRetries
+}
+
+// deadLetter moves the messages to the dead-letter table, or marks
+// them as failed.
+func (mm *messageManager) deadLetter(ids []string) {
+	defer func() {
+		mm.tsv.LogError()
+		mm.wg.Done()
+	}()
+
+	defer func() {
+		// Hold cacheManagementMu for the same reason as send.
+		mm.cacheManagementMu.Lock()
+		defer mm.cacheManagementMu.Unlock()
+		mm.cache.Discard(ids)
+	}()
+
+	// Dead-lettering shares the semaphore with postponing.
+	if err := mm.postponeSema.Acquire(context.Background(), 1); err != nil {
+		return
+	}
+	defer mm.postponeSema.Release(1)
+	ctx, cancel := context.WithTimeout(tabletenv.LocalContext(), mm.ackWaitTime)
+	defer cancel()
+	count, err := mm.tsv.DeadLetterMessages(ctx, nil, mm, ids)
+	if err != nil {
+		// The messages will be dead-lettered again by the next poll.
+		MessageStats.Add([]string{mm.name.String(), "DeadLetterFailed"}, 1)
+		log.Errorf("messageManager (%v) - Unable to dead-letter messages: %v", mm.name, err)
+		return
+	}
+	MessageStats.Add([]string{mm.name.String(), "DeadLettered"}, count)
+}
+
 func (mm *messageManager) startVStream() {
 	if mm.streamCancel != nil {
 		return
@@ -752,6 +888,17 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 		return fmt.Errorf("internal error: unexpected rows without fields")
 	}
 
+	if mm.partitioned {
+		// Only the poller knows which messages are next in their partition.
+		// Any change, including acks, may make a message eligible.
+		// Trigger asynchronously because we're holding cacheManagementMu,
+		// which the poller obtains.
+		if len(rowEvent.RowChanges) != 0 && mm.pollTriggered.CompareAndSwap(false, true) {
+			go mm.pollerTicks.Trigger()
+		}
+		return nil
+	}
+
 	now := time.Now().UnixNano()
 	for _, rc := range rowEvent.RowChanges {
 		if rc.After == nil {
@@ -765,6 +912,10 @@ func (mm *messageManager) processRowEvent(fields []*querypb.Field, rowEvent *bin
 		if mr.TimeAcked != 0 || mr.TimeNext > now {
 			continue
 		}
+		// A dead message without a time_next was marked as failed.
+		if mr.TimeNext == 0 && mm.isDead(mr) {
+			continue
+		}
 		mm.Add(mr)
 	}
 	return nil
@@ -774,6 +925,9 @@ func (mm *messageManager) runPoller() {
 	// We need to get the flow control lock first
 	mm.cacheManagementMu.Lock()
 	defer mm.cacheManagementMu.Unlock()
+	// Row events processed from now on are not covered by this run,
+	// so they have to trigger the poller again.
+	mm.pollTriggered.Store(false)
 	// Now we can get the main/structure lock and ensure e.g. that the
 	// the receiver count does not change during the run
 	mm.mu.Lock()
@@ -901,6 +1055,34 @@ func (mm *messageManager) GeneratePurgeQuery(timeCutoff int64) (string, map[stri
 	}
 }
 
+// GenerateDeadLetterQueries returns the queries for dead-lettering messages.
+// They must be executed in the same transaction.
+func (mm *messageManager) GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery {
+	idbvs := &querypb.BindVariable{
+		Type:   querypb.Type_TUPLE,
+		Values: make([]*querypb.Value, 0, len(ids)),
+	}
+	for _, id := range ids {
+		idbvs.Values = append(idbvs.Values, &querypb.Value{
+			Type:  querypb.Type_VARBINARY,
+			Value: []byte(id),
+		})
+	}
+	bvs := map[string]*querypb.BindVariable{
+		"time_now": sqltypes.Int64BindVariable(time.Now().UnixNano()),
+		"ids":      idbvs,
+	}
+
+	queries := make([]*querypb.BoundQuery, 0, len(mm.deadLetterQueries))
+	for _, pq := range mm.deadLetterQueries {
+		queries = append(queries, &querypb.BoundQuery{
+			Sql:           pq.Query,
+			BindVariables: bvs,
+		})
+	}
+	return queries
+}
+
 // BuildMessageRow builds a MessageRow from a db row.
 func BuildMessageRow(row []sqltypes.Value) (*MessageRow, error) {
 	mr := &MessageRow{Row: row[4:]}
@@ -29,6 +29,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/semaphore"
 
 	"vitess.io/vitess/go/sqltypes"
@@ -637,6 +638,151 @@ func TestMessageManagerPoller(t *testing.T) {
 	}
 }
 
+func TestMessageManagerDeadLetter(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.BatchSize = 2
+	ti.MessageInfo.MaxRetries = 2
+	ti.MessageInfo.PollInterval = 20 * time.Second
+	deadRow := func(id int64, timeNext sqltypes.Value) *querypb.Row {
+		return sqltypes.RowToProto3([]sqltypes.Value{
+			sqltypes.NewInt64(1),
+			timeNext,
+			sqltypes.NewInt64(2),
+			sqltypes.NULL,
+			sqltypes.NewInt64(id),
+			sqltypes.NewVarBinary(fmt.Sprintf("%v", id)),
+		})
+	}
+	fvs := newFakeVStreamer()
+	fvs.setPollerResponse([]*binlogdatapb.VStreamResultsResponse{{
+		Fields: testDBFields,
+		Gtid:   "MySQL56/33333333-3333-3333-3333-333333333333:1-100",
+	}, {
+		Rows: []*querypb.Row{
+			newMMRow(1),
+			deadRow(2, sqltypes.NewInt64(1)),
+		},
+	}})
+	fts := newFakeTabletServer()
+	ch := make(chan string, 10)
+	fts.SetChannel(ch)
+	waitForDeadLetter := func() {
+		for call := range ch {
+			if call == "deadletter" {
+				return
+			}
+		}
+	}
+	mm := newMessageManager(fts, fvs, ti, semaphore.NewWeighted(1))
+	mm.Open()
+	defer mm.Close()
+	MessageStats.ResetAll()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	// The dead message is not sent.
+	qr := <-r1.ch
+	want := [][]sqltypes.Value{{
+		sqltypes.NewInt64(1),
+		sqltypes.NewVarBinary("1"),
+	}}
+	utils.MustMatch(t, want, qr.Rows)
+	waitForDeadLetter()
+	assert.Equal(t, []string{"2"}, fts.deadLetterIDs())
+	assert.Eventually(t, func() bool {
+		return MessageStats.Counts()["foo.DeadLettered"] == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// A message that was marked as failed is ignored by the streamer,
+	// but a dead message that is still due is dead-lettered again.
+	fvs.setStreamerResponse([][]*binlogdatapb.VEvent{{{
+		Type: binlogdatapb.VEventType_FIELD,
+		FieldEvent: &binlogdatapb.FieldEvent{
+			Fields: testDBFields,
+		},
+	}, {
+		Type: binlogdatapb.VEventType_GTID,
+		Gtid: "MySQL56/33333333-3333-3333-3333-333333333333:1-101",
+	}, {
+		Type: binlogdatapb.VEventType_COMMIT,
+	}}, {{
+		Type: binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{
+			RowChanges: []*binlogdatapb.RowChange{{
+				After: deadRow(3, sqltypes.NULL),
+			}, {
+				After: deadRow(4, sqltypes.NewInt64(1)),
+			}},
+		},
+	}}})
+	waitForDeadLetter()
+	assert.Equal(t, []string{"4"}, fts.deadLetterIDs())
+}
+
+func TestMessageManagerPartitioned(t *testing.T) {
+	ti := newMMTable()
+	ti.MessageInfo.PartitionKey = "message"
+	ti.MessageInfo.PollInterval = 20 * time.Second
+	fvs := newFakeVStreamer()
+	fvs.setPollerResponse([]*binlogdatapb.VStreamResultsResponse{{
+		Fields: testDBFields,
+		Gtid:   "MySQL56/33333333-3333-3333-3333-333333333333:1-100",
+	}})
+	mm := newMessageManager(newFakeTabletServer(), fvs, ti, semaphore.NewWeighted(1))
+	mm.Open()
+	defer mm.Close()
+
+	r1 := newTestReceiver(1)
+	mm.Subscribe(context.Background(), r1.rcv)
+	<-r1.ch
+
+	// Wait for the first poll, which is triggered by the pending messages.
+	assert.Eventually(t, func() bool {
+		return fvs.pollerInvocations.Load() == 1 && mm.getLastPollPosition() != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// New messages are not added to the cache directly. Instead, the poller
+	// is triggered to find out if they're next in their partition.
+	fvs.setStreamerResponse([][]*binlogdatapb.VEvent{{{
+		Type: binlogdatapb.VEventType_FIELD,
+		FieldEvent: &binlogdatapb.FieldEvent{
+			TableName: "foo",
+			Fields:    testDBFields,
+		},
+	}, {
+		Type: binlogdatapb.VEventType_GTID,
+		Gtid: "MySQL56/33333333-3333-3333-3333-333333333333:1-101",
+	}, {
+		Type: binlogdatapb.VEventType_COMMIT,
+	}}, {{
+		Type: binlogdatapb.VEventType_ROW,
+		RowEvent: &binlogdatapb.RowEvent{
+			TableName: "foo",
+			RowChanges: []*binlogdatapb.RowChange{{
+				After: newMMRow(1),
+			}, {
+				After: newMMRow(2),
+			}},
+		},
+	}, {
+		Type: binlogdatapb.VEventType_GTID,
+		Gtid: "MySQL56/33333333-3333-3333-3333-333333333333:1-102",
+	}, {
+		Type: binlogdatapb.VEventType_COMMIT,
+	}}})
+	// Both rows are covered by a single trigger, which is cleared by the poll.
+	assert.Eventually(t, func() bool {
+		return fvs.pollerInvocations.Load() == 2 && !mm.pollTriggered.Load()
+	}, 5*time.Second, 10*time.Millisecond)
+	select {
+	case qr := <-r1.ch:
+		t.Errorf("Expecting no value, got: %v", qr)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
 // TestMessagesPending1 tests for the case where you can't
 // add items because the cache is full.
 func TestMessagesPending1(t *testing.T) {
@@ -787,6 +933,54 @@ func TestMMGenerate(t *testing.T) {
 	}
 }
 
+func TestMMGenerateDeadLetter(t *testing.T) {
+	ti := newMMTable()
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, semaphore.NewWeighted(1))
+	assert.Empty(t, mm.GenerateDeadLetterQueries([]string{"1", "2"}))
+
+	wantids := sqltypes.TestBindVariable([]any{[]byte{'1'}, []byte{'2'}})
+
+	ti.MessageInfo.MaxRetries = 3
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, semaphore.NewWeighted(1))
+	queries := mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	require.Len(t, queries, 1)
+	assert.Equal(t, "update foo set time_next = null where id in ::ids and time_acked is null", queries[0].Sql)
+	utils.MustMatch(t, wantids, queries[0].BindVariables["ids"])
+
+	ti.Fields = []*querypb.Field{
+		{Name: "id"},
+		{Name: "priority"},
+		{Name: "time_next"},
+		{Name: "epoch"},
+		{Name: "time_acked"},
+		{Name: "message"},
+		{Name: "desc"},
+	}
+	ti.MessageInfo.DeadLetterTable = "foo_dlq"
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, semaphore.NewWeighted(1))
+	queries = mm.GenerateDeadLetterQueries([]string{"1", "2"})
+	require.Len(t, queries, 2)
+	assert.Equal(t, "insert into foo_dlq(priority, time_next, epoch, time_acked, id, message, `desc`) select priority, :time_now, 0, null, id, message, `desc` from foo where id in ::ids and time_acked is null", queries[0].Sql)
+	assert.Equal(t, "delete from foo where id in ::ids and time_acked is null", queries[1].Sql)
+	for _, query := range queries {
+		assert.Contains(t, query.BindVariables, "time_now")
+		utils.MustMatch(t, wantids, query.BindVariables["ids"])
+	}
+}
+
+func TestMMReadPendingPartitioned(t *testing.T) {
+	ti := newMMTable()
+	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, semaphore.NewWeighted(1))
+	assert.Equal(t, "select priority, time_next, epoch, time_acked, id, message from foo where time_acked is null and time_next < :time_next order by priority, time_next desc limit :max", mm.readByPriorityAndTimeNext.Query)
+
+	ti.MessageInfo.PartitionKey = "group"
+	mm = newMessageManager(newFakeTabletServer(), newFakeVStreamer(), ti, semaphore.NewWeighted(1))
+	assert.Equal(t, "select priority, time_next, epoch, time_acked, id, message from foo as m where time_acked is null and time_next < :time_next and not exists ("+
+		"select 1 from foo as prev where prev.`group` = m.`group` and prev.time_acked is null and prev.time_next is not null and "+
+		"(coalesce(prev.time_scheduled, 0) < coalesce(m.time_scheduled, 0) or coalesce(prev.time_scheduled, 0) = coalesce(m.time_scheduled, 0) and prev.id < m.id)"+
+		") order by priority, time_next desc limit :max", mm.readByPriorityAndTimeNext.Query)
+}
+
 func TestMMGenerateWithBackoff(t *testing.T) {
 	mm := newMessageManager(newFakeTabletServer(), newFakeVStreamer(), newMMTableWithBackoff(), semaphore.NewWeighted(1))
 	mm.Open()
@@ -827,8 +1021,9 @@ type fakeTabletServer struct {
 	postponeCount atomic.Int64
 	purgeCount    atomic.Int64
 
-	mu sync.Mutex
-	ch chan string
+	mu      sync.Mutex
+	ch      chan string
+	deadIDs []string
 }
 
 func newFakeTabletServer() *fakeTabletServer {
@@ -868,8 +1063,26 @@ func (fts *fakeTabletServer) PurgeMessages(ctx context.Context, target *querypb.
 	return 0, nil
 }
 
+func (fts *fakeTabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, gen QueryGenerator, ids []string) (count int64, err error) {
+	fts.mu.Lock()
+	ch := fts.ch
+	fts.deadIDs = ids
+	fts.mu.Unlock()
+	if ch != nil {
+		ch <- "deadletter"
+	}
+	return int64(len(ids)), nil
+}
+
+func (fts *fakeTabletServer) deadLetterIDs() []string {
+	fts.mu.Lock()
+	defer fts.mu.Unlock()
+	return fts.deadIDs
+}
+
 type fakeVStreamer struct {
 	streamInvocations atomic.Int64
+	pollerInvocations atomic.Int64
 	mu                sync.Mutex
 	streamerResponse  [][]*binlogdatapb.VEvent
 	pollerResponse    []*binlogdatapb.VStreamResultsResponse
@@ -912,6 +1125,7 @@ func (fv *fakeVStreamer) Stream(ctx context.Context, startPos string, tablePKs [
 }
 
 func (fv *fakeVStreamer) StreamResults(ctx context.Context, query string, send func(*binlogdatapb.VStreamResultsResponse) error) error {
+	fv.pollerInvocations.Add(1)
 	fv.mu.Lock()
 	defer fv.mu.Unlock()
 	for _, r := range fv.pollerResponse {
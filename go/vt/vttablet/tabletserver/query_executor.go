@@ -40,6 +40,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/evalengine"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/admission"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	p "vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/rules"
@@ -67,6 +68,8 @@ type QueryExecutor struct {
 	// The target type we requested might be different from tsv's tablet type, if we had a change to the tablet type recently.
 	targetTabletType topodatapb.TabletType
 	setting          *smartconnpool.Setting
+	// rewrites are the query rules with the REWRITE action that matched the query.
+	rewrites []*rules.Rule
 }
 
 const (
@@ -177,6 +180,11 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 
 	switch qre.plan.PlanID {
 	case p.PlanSelect, p.PlanSelectImpossible, p.PlanShow:
+		admissionDone, err := qre.admitQuery()
+		if err != nil {
+			return nil, err
+		}
+		defer admissionDone()
 		maxrows := qre.getSelectLimit()
 		qre.bindVars["#maxLimit"] = sqltypes.Int64BindVariable(maxrows + 1)
 		if qre.bindVars[sqltypes.BvReplaceSchemaName] != nil {
@@ -191,6 +199,11 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 		}
 		return qr, nil
 	case p.PlanOtherRead, p.PlanOtherAdmin, p.PlanFlush, p.PlanSavepoint, p.PlanRelease, p.PlanSRollback:
+		admissionDone, err := qre.admitQuery()
+		if err != nil {
+			return nil, err
+		}
+		defer admissionDone()
 		return qre.execOther()
 	case p.PlanInsert, p.PlanUpdate, p.PlanDelete, p.PlanInsertMessage, p.PlanDDL, p.PlanLoad:
 		return qre.execAutocommit(qre.txConnExec)
@@ -222,6 +235,34 @@ func (qre *QueryExecutor) Execute() (reply *sqltypes.Result, err error) {
 	return nil, vterrors.Errorf(vtrpcpb.Code_INTERNAL, "[BUG] %s unexpected plan type", qre.plan.PlanID.String())
 }
 
+// admitQuery waits until the workload class of the query can use the query pool.
+// Queries which use the transaction pool are admitted when their transaction begins.
+func (qre *QueryExecutor) admitQuery() (admission.DoneFunc, error) {
+	ac := qre.tsv.te.admission
+	if !ac.Enabled() {
+		return func() {}, nil
+	}
+	remoteAddr := ""
+	username := ""
+	ci, ok := callinfo.FromContext(qre.ctx)
+	if ok {
+		remoteAddr = ci.RemoteAddr()
+		username = ci.Username()
+	}
+	return ac.Admit(qre.ctx, admission.Queries, &admission.Request{
+		WorkloadName:    qre.options.GetWorkloadName(),
+		ImmediateCaller: callerid.ImmediateCallerIDFromContext(qre.ctx),
+		EffectiveCaller: callerid.EffectiveCallerIDFromContext(qre.ctx),
+		Query:           qre.query,
+		PlanID:          qre.plan.PlanID,
+		TableNames:      qre.plan.TableNames(),
+		RemoteAddr:      remoteAddr,
+		Username:        username,
+		BindVars:        qre.bindVars,
+		MarginComments:  qre.marginComments,
+	})
+}
+
 func (qre *QueryExecutor) execAutocommit(f func(conn *StatefulConnection) (*sqltypes.Result, error)) (reply *sqltypes.Result, err error) {
 	if qre.options == nil {
 		qre.options = &querypb.ExecuteOptions{}
@@ -458,6 +499,8 @@ func (qre *QueryExecutor) checkPermissions() error {
 		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "disallowed due to rule: %s", desc)
 	case rules.QRFailRetry:
 		return vterrors.Errorf(vtrpcpb.Code_FAILED_PRECONDITION, "disallowed due to rule: %s", desc)
+	case rules.QRThrottle:
+		return vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "throttled due to rule: %s", desc)
 	case rules.QRBuffer:
 		if ruleCancelCtx != nil {
 			// We buffer up to some timeout. The timeout is determined by ctx.Done().
@@ -473,6 +516,13 @@ func (qre *QueryExecutor) checkPermissions() error {
 	default:
 		// no rules against this query. Good to proceed
 	}
+
+	var logRule *rules.Rule
+	qre.rewrites, logRule = qre.plan.Rules.GetRewritesAndLog(remoteAddr, username, qre.bindVars, qre.marginComments)
+	if logRule != nil {
+		qre.logStats.SendToRuleLog = true
+	}
+
 	// Skip ACL check for queries against the dummy dual table
 	if qre.plan.TableName().String() == "dual" {
 		return nil
@@ -815,6 +865,12 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	if err != nil {
 		return "", "", vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "%s", err)
 	}
+	if len(qre.rewrites) != 0 {
+		query, err = qre.rewriteQuery(query)
+		if err != nil {
+			return "", "", err
+		}
+	}
 	if qre.tsv.config.AnnotateQueries {
 		username := callerid.GetPrincipal(callerid.EffectiveCallerIDFromContext(qre.ctx))
 		if username == "" {
@@ -844,6 +900,20 @@ func (qre *QueryExecutor) generateFinalSQL(parsedQuery *sqlparser.ParsedQuery, b
 	return buf.String(), query, nil
 }
 
+// rewriteQuery adds the hints of the matching query rules to the query.
+func (qre *QueryExecutor) rewriteQuery(query string) (string, error) {
+	stmt, err := qre.tsv.env.Parser().Parse(query)
+	if err != nil {
+		return "", vterrors.Wrapf(err, "rewriting query for rule %s", qre.rewrites[0].Name)
+	}
+	for _, qr := range qre.rewrites {
+		if err := qr.Rewrite(stmt); err != nil {
+			return "", vterrors.Wrapf(err, "rewriting query for rule %s", qr.Name)
+		}
+	}
+	return sqlparser.String(stmt), nil
+}
+
 func rewriteOUTParamError(err error) error {
 	sqlErr, ok := err.(*sqlerror.SQLError)
 	if !ok {
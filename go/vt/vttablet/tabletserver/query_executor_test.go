@@ -1242,6 +1242,80 @@ func TestQueryExecutorDenyListQRRetry(t *testing.T) {
 	}
 }
 
+func TestQueryExecutorQRThrottle(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table where name = 1 limit 1000"
+	db.AddQuery("select * from test_table where `name` = 1 limit 1000", &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	throttleRule := rules.NewQueryRule("throttle select", "throttle select", rules.QRThrottle)
+	require.NoError(t, throttleRule.SetMaxQPS(1))
+	throttleRule.AddPlanCond(planbuilder.PlanSelect)
+	throttleRule.AddTableCond("test_table")
+
+	rulesName := "throttleRules"
+	rules := rules.New()
+	rules.Add(throttleRule)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	defer tsv.StopService()
+
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, rules)
+	require.NoError(t, err)
+
+	// The first query is within the rate, the second one isn't.
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.NoError(t, err)
+	_, err = newTestQueryExecutor(ctx, tsv, query, 0).Execute()
+	require.EqualError(t, err, "throttled due to rule: throttle select")
+	assert.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+}
+
+func TestQueryExecutorQRRewriteAndLog(t *testing.T) {
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	query := "select * from test_table where name = 1 limit 1000"
+	rewrittenQuery := "select /*+ MAX_EXECUTION_TIME(1000) */ * from test_table force index (idx_name) where `name` = 1 limit 1000"
+	db.AddQuery(rewrittenQuery, &sqltypes.Result{
+		Fields: getTestTableFields(),
+	})
+
+	rewriteRule := rules.NewQueryRule("add hints", "add hints", rules.QRRewrite)
+	rewriteRule.SetOptimizerHints("MAX_EXECUTION_TIME(1000)")
+	require.NoError(t, rewriteRule.AddIndexHint("test_table", sqlparser.ForceOp, "idx_name"))
+	rewriteRule.AddPlanCond(planbuilder.PlanSelect)
+
+	logRule := rules.NewQueryRule("log test_table", "log test_table", rules.QRLog)
+	logRule.AddTableCond("test_table")
+
+	rulesName := "rewriteRules"
+	rules := rules.New()
+	rules.Add(rewriteRule)
+	rules.Add(logRule)
+
+	ctx := context.Background()
+	tsv := newTestTabletServer(ctx, noFlags, db)
+	tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	tsv.qe.queryRuleSources.RegisterSource(rulesName)
+	defer tsv.qe.queryRuleSources.UnRegisterSource(rulesName)
+	defer tsv.StopService()
+
+	err := tsv.qe.queryRuleSources.SetRules(rulesName, rules)
+	require.NoError(t, err)
+
+	qre := newTestQueryExecutor(ctx, tsv, query, 0)
+	_, err = qre.Execute()
+	require.NoError(t, err)
+	assert.Equal(t, 1, db.GetQueryCalledNum(rewrittenQuery))
+	assert.True(t, qre.logStats.SendToRuleLog)
+}
+
 func TestReplaceSchemaName(t *testing.T) {
 	db := setUpQueryExecutorTest(t)
 	defer db.Close()
@@ -39,13 +39,32 @@ func (cached *BindVarCond) CachedSize(alloc bool) int64 {
 	}
 	return size
 }
+func (cached *IndexHint) CachedSize(alloc bool) int64 {
+	if cached == nil {
+		return int64(0)
+	}
+	size := int64(0)
+	if alloc {
+		size += int64(48)
+	}
+	// field Table string
+	size += hack.RuntimeAllocSize(int64(len(cached.Table)))
+	// field Indexes []string
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.Indexes)) * int64(16))
+		for _, elem := range cached.Indexes {
+			size += hack.RuntimeAllocSize(int64(len(elem)))
+		}
+	}
+	return size
+}
 func (cached *Rule) CachedSize(alloc bool) int64 {
 	if cached == nil {
 		return int64(0)
 	}
 	size := int64(0)
 	if alloc {
-		size += int64(256)
+		size += int64(320)
 	}
 	// field Description string
 	size += hack.RuntimeAllocSize(int64(len(cached.Description)))
@@ -79,6 +98,19 @@ func (cached *Rule) CachedSize(alloc bool) int64 {
 			size += elem.CachedSize(false)
 		}
 	}
+	// field limiter *golang.org/x/time/rate.Limiter
+	if cached.limiter != nil {
+		size += hack.RuntimeAllocSize(int64(80))
+	}
+	// field optimizerHints string
+	size += hack.RuntimeAllocSize(int64(len(cached.optimizerHints)))
+	// field indexHints []vitess.io/vitess/go/vt/vttablet/tabletserver/rules.IndexHint
+	{
+		size += hack.RuntimeAllocSize(int64(cap(cached.indexHints)) * int64(48))
+		for _, elem := range cached.indexHints {
+			size += elem.CachedSize(false)
+		}
+	}
 	return size
 }
 func (cached *Rules) CachedSize(alloc bool) int64 {
@@ -21,11 +21,15 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"math/rand/v2"
 	"reflect"
 	"regexp"
 	"strconv"
 	"time"
 
+	"golang.org/x/time/rate"
+
 	"vitess.io/vitess/go/sqltypes"
 	querypb "vitess.io/vitess/go/vt/proto/query"
 	vtrpcpb "vitess.io/vitess/go/vt/proto/vtrpc"
@@ -170,6 +174,7 @@ func (qrs *Rules) FilterByPlan(query string, planid planbuilder.PlanType, tableN
 }
 
 // GetAction runs the input against the rules engine and returns the action to be performed.
+// QRRewrite and QRLog rules are skipped, see GetRewritesAndLog.
 func (qrs *Rules) GetAction(
 	ip,
 	user string,
@@ -181,6 +186,9 @@ func (qrs *Rules) GetAction(
 	timeout time.Duration,
 	desc string) {
 	for _, qr := range qrs.rules {
+		if qr.act == QRRewrite || qr.act == QRLog {
+			continue
+		}
 		if act := qr.GetAction(ip, user, bindVars, marginComments); act != QRContinue {
 			return act, qr.cancelCtx, qr.timeout, qr.Description
 		}
@@ -188,6 +196,48 @@ func (qrs *Rules) GetAction(
 	return QRContinue, nil, 0, ""
 }
 
+// Matches returns true if any of the rules matches the input, regardless
+// of its action. Unlike GetAction, it doesn't consume throttling capacity
+// or sample the input.
+func (qrs *Rules) Matches(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) bool {
+	for _, qr := range qrs.rules {
+		if qr.Matches(ip, user, bindVars, marginComments) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetRewritesAndLog runs the input against the QRRewrite and QRLog rules.
+// Unlike the other actions, these don't decide the fate of the query, so
+// every matching rule applies. It returns the matching QRRewrite rules in
+// order, and the first QRLog rule that sampled the query, if any.
+func (qrs *Rules) GetRewritesAndLog(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) (rewrites []*Rule, logRule *Rule) {
+	for _, qr := range qrs.rules {
+		switch qr.act {
+		case QRRewrite:
+			if qr.GetAction(ip, user, bindVars, marginComments) == QRRewrite {
+				rewrites = append(rewrites, qr)
+			}
+		case QRLog:
+			if logRule == nil && qr.GetAction(ip, user, bindVars, marginComments) == QRLog {
+				logRule = qr
+			}
+		}
+	}
+	return rewrites, logRule
+}
+
 // -----------------------------------------------
 
 // Rule represents one rule (conditions-action).
@@ -224,6 +274,19 @@ type Rule struct {
 
 	// a rule can timeout.
 	timeout time.Duration
+
+	// maxQPS is the rate a QRThrottle rule lets matching queries through at.
+	// The limiter is shared by all copies of the rule.
+	maxQPS  float64
+	limiter *rate.Limiter
+
+	// optimizerHints and indexHints are added to queries matching a QRRewrite rule.
+	optimizerHints string
+	indexHints     []IndexHint
+
+	// sampleRate is the fraction of queries matching a QRLog rule that are logged.
+	// Zero means all of them.
+	sampleRate float64
 }
 
 type namedRegexp struct {
@@ -272,7 +335,11 @@ func (qr *Rule) Equal(other *Rule) bool {
 		reflect.DeepEqual(qr.plans, other.plans) &&
 		reflect.DeepEqual(qr.tableNames, other.tableNames) &&
 		reflect.DeepEqual(qr.bindVarConds, other.bindVarConds) &&
-		qr.act == other.act)
+		qr.act == other.act &&
+		qr.maxQPS == other.maxQPS &&
+		qr.optimizerHints == other.optimizerHints &&
+		reflect.DeepEqual(qr.indexHints, other.indexHints) &&
+		qr.sampleRate == other.sampleRate)
 }
 
 // Copy performs a deep copy of a Rule.
@@ -288,6 +355,10 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		act:             qr.act,
 		cancelCtx:       qr.cancelCtx,
 		timeout:         qr.timeout,
+		maxQPS:          qr.maxQPS,
+		limiter:         qr.limiter,
+		optimizerHints:  qr.optimizerHints,
+		sampleRate:      qr.sampleRate,
 	}
 	if qr.plans != nil {
 		newqr.plans = make([]planbuilder.PlanType, len(qr.plans))
@@ -301,6 +372,10 @@ func (qr *Rule) Copy() (newqr *Rule) {
 		newqr.bindVarConds = make([]BindVarCond, len(qr.bindVarConds))
 		copy(newqr.bindVarConds, qr.bindVarConds)
 	}
+	if qr.indexHints != nil {
+		newqr.indexHints = make([]IndexHint, len(qr.indexHints))
+		copy(newqr.indexHints, qr.indexHints)
+	}
 	return newqr
 }
 
@@ -336,6 +411,18 @@ func (qr *Rule) MarshalJSON() ([]byte, error) {
 	if qr.act != QRContinue {
 		safeEncode(b, `,"Action":`, qr.act)
 	}
+	if qr.maxQPS != 0 {
+		safeEncode(b, `,"MaxQPS":`, qr.maxQPS)
+	}
+	if qr.optimizerHints != "" {
+		safeEncode(b, `,"OptimizerHints":`, qr.optimizerHints)
+	}
+	if qr.indexHints != nil {
+		safeEncode(b, `,"IndexHints":`, qr.indexHints)
+	}
+	if qr.sampleRate != 0 {
+		safeEncode(b, `,"SampleRate":`, qr.sampleRate)
+	}
 	if qr.timeout != 0 {
 		safeEncode(b, `,"Timeout":`, qr.timeout)
 	}
@@ -394,6 +481,105 @@ func (qr *Rule) SetTrailingCommentCond(pattern string) (err error) {
 	return
 }
 
+// SetMaxQPS sets the rate at which a QRThrottle rule lets matching queries
+// through. Queries over the rate fail. Bursts of up to one second worth of
+// queries are allowed.
+func (qr *Rule) SetMaxQPS(maxQPS float64) error {
+	if maxQPS <= 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "MaxQPS must be positive: %v", maxQPS)
+	}
+	qr.maxQPS = maxQPS
+	qr.limiter = rate.NewLimiter(rate.Limit(maxQPS), int(math.Ceil(maxQPS)))
+	return nil
+}
+
+// SetOptimizerHints sets the optimizer hints that a QRRewrite rule adds to
+// matching queries, e.g. "MAX_EXECUTION_TIME(1000) NO_RANGE_OPTIMIZATION(t1)".
+func (qr *Rule) SetOptimizerHints(hints string) {
+	qr.optimizerHints = hints
+}
+
+// AddIndexHint adds an index hint that a QRRewrite rule adds to every
+// reference to the table in matching queries.
+func (qr *Rule) AddIndexHint(table string, hintType sqlparser.IndexHintType, indexes ...string) error {
+	if table == "" {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Table missing in IndexHints")
+	}
+	if _, ok := indexHintTypeNames[hintType]; !ok {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid index hint type %v", hintType.ToString())
+	}
+	if len(indexes) == 0 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "Indexes missing in IndexHints")
+	}
+	qr.indexHints = append(qr.indexHints, IndexHint{Table: table, Type: hintType, Indexes: indexes})
+	return nil
+}
+
+// SetSampleRate sets the fraction of the queries matching a QRLog rule
+// that are logged.
+func (qr *Rule) SetSampleRate(sampleRate float64) error {
+	if sampleRate <= 0 || sampleRate > 1 {
+		return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "SampleRate must be in (0, 1]: %v", sampleRate)
+	}
+	qr.sampleRate = sampleRate
+	return nil
+}
+
+// Rewrite adds the hints of a QRRewrite rule to the statement.
+// Optimizer hints go to the top level statement, index hints go
+// to every reference to their table.
+func (qr *Rule) Rewrite(stmt sqlparser.Statement) error {
+	if qr.optimizerHints != "" {
+		if node, ok := stmt.(sqlparser.SupportOptimizerHint); ok {
+			comments, err := node.GetParsedComments().AddQueryHint(qr.optimizerHints)
+			if err != nil {
+				return err
+			}
+			node.SetComments(comments)
+		}
+	}
+	if len(qr.indexHints) == 0 {
+		return nil
+	}
+	return sqlparser.Walk(func(node sqlparser.SQLNode) (bool, error) {
+		tableExpr, ok := node.(*sqlparser.AliasedTableExpr)
+		if !ok {
+			return true, nil
+		}
+		// Derived tables have no index hints, but may contain table references.
+		tableName, ok := tableExpr.Expr.(sqlparser.TableName)
+		if !ok {
+			return true, nil
+		}
+		for _, hint := range qr.indexHints {
+			if tableName.Name.String() != hint.Table {
+				continue
+			}
+			indexHint := &sqlparser.IndexHint{Type: hint.Type}
+			for _, index := range hint.Indexes {
+				indexHint.Indexes = append(indexHint.Indexes, sqlparser.NewIdentifierCI(index))
+			}
+			tableExpr.Hints = append(tableExpr.Hints, indexHint)
+		}
+		return true, nil
+	}, stmt)
+}
+
+// validate checks that the rule has the settings its action needs.
+func (qr *Rule) validate() error {
+	switch qr.act {
+	case QRThrottle:
+		if qr.maxQPS == 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "MaxQPS missing for Action THROTTLE")
+		}
+	case QRRewrite:
+		if qr.optimizerHints == "" && len(qr.indexHints) == 0 {
+			return vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "OptimizerHints or IndexHints missing for Action REWRITE")
+		}
+	}
+	return nil
+}
+
 // makeExact forces a full string match for the regex instead of substring
 func makeExact(pattern string) string {
 	return fmt.Sprintf("^%s$", pattern)
@@ -487,34 +673,59 @@ func (qr *Rule) GetAction(
 	bindVars map[string]*querypb.BindVariable,
 	marginComments sqlparser.MarginComments,
 ) Action {
+	if !qr.Matches(ip, user, bindVars, marginComments) {
+		return QRContinue
+	}
+	switch qr.act {
+	case QRThrottle:
+		// The rule only fires for queries over the rate.
+		if qr.limiter == nil || qr.limiter.Allow() {
+			return QRContinue
+		}
+	case QRLog:
+		if qr.sampleRate != 0 && rand.Float64() >= qr.sampleRate {
+			return QRContinue
+		}
+	}
+	return qr.act
+}
+
+// Matches returns true if the input satisfies the conditions of the rule
+// that aren't checked by FilterByPlan. The action of the rule is ignored.
+func (qr *Rule) Matches(
+	ip,
+	user string,
+	bindVars map[string]*querypb.BindVariable,
+	marginComments sqlparser.MarginComments,
+) bool {
 	if qr.cancelCtx != nil {
 		select {
 		case <-qr.cancelCtx.Done():
 			// rule was cancelled. Nothing else to check
-			return QRContinue
+			return false
 		default:
 			// rule will be cancelled in the future. Until then, it applies!
 			// proceed to evaluate rules
 		}
 	}
 	if !reMatch(qr.leadingComment.Regexp, marginComments.Leading) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.trailingComment.Regexp, marginComments.Trailing) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.requestIP.Regexp, ip) {
-		return QRContinue
+		return false
 	}
 	if !reMatch(qr.user.Regexp, user) {
-		return QRContinue
+		return false
 	}
 	for _, bvcond := range qr.bindVarConds {
 		if !bvMatch(bvcond, bindVars) {
-			return QRContinue
+			return false
 		}
 	}
-	return qr.act
+	return true
 }
 
 func reMatch(re *regexp.Regexp, val string) bool {
@@ -573,6 +784,12 @@ const (
 	QRFail
 	QRFailRetry
 	QRBuffer
+	// QRThrottle fails matching queries over the rule's MaxQPS.
+	QRThrottle
+	// QRRewrite adds the rule's optimizer and index hints to matching queries.
+	QRRewrite
+	// QRLog sends a sample of matching queries to the rule log.
+	QRLog
 )
 
 // MarshalJSON marshals to JSON.
@@ -586,12 +803,51 @@ func (act Action) MarshalJSON() ([]byte, error) {
 		str = "FAIL_RETRY"
 	case QRBuffer:
 		str = "BUFFER"
+	case QRThrottle:
+		str = "THROTTLE"
+	case QRRewrite:
+		str = "REWRITE"
+	case QRLog:
+		str = "LOG"
 	default:
 		str = "INVALID"
 	}
 	return json.Marshal(str)
 }
 
+// IndexHint is an index hint that a QRRewrite rule adds to a table.
+type IndexHint struct {
+	Table   string
+	Type    sqlparser.IndexHintType
+	Indexes []string
+}
+
+var indexHintTypeNames = map[sqlparser.IndexHintType]string{
+	sqlparser.UseOp:    "USE",
+	sqlparser.IgnoreOp: "IGNORE",
+	sqlparser.ForceOp:  "FORCE",
+}
+
+// MapStrIndexHintType maps a string representation to an IndexHintType.
+func MapStrIndexHintType(str string) (sqlparser.IndexHintType, error) {
+	for hintType, name := range indexHintTypeNames {
+		if name == str {
+			return hintType, nil
+		}
+	}
+	return 0, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid index hint Type %s", str)
+}
+
+// MarshalJSON marshals to JSON.
+func (ih IndexHint) MarshalJSON() ([]byte, error) {
+	b := bytes.NewBuffer(nil)
+	safeEncode(b, `{"Table":`, ih.Table)
+	safeEncode(b, `,"Type":`, indexHintTypeNames[ih.Type])
+	safeEncode(b, `,"Indexes":`, ih.Indexes)
+	_, _ = b.WriteString("}")
+	return b.Bytes(), nil
+}
+
 // BindVarCond represents a bind var condition.
 type BindVarCond struct {
 	name       string
@@ -874,18 +1130,24 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 	for k, v := range ruleInfo {
 		var sv string
 		var lv []any
+		var nv json.Number
 		var ok bool
 		switch k {
-		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment":
+		case "Name", "Description", "RequestIP", "User", "Query", "Action", "LeadingComment", "TrailingComment", "OptimizerHints":
 			sv, ok = v.(string)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for %s", k)
 			}
-		case "Plans", "BindVarConds", "TableNames":
+		case "Plans", "BindVarConds", "TableNames", "IndexHints":
 			lv, ok = v.([]any)
 			if !ok {
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for %s", k)
 			}
+		case "MaxQPS", "SampleRate":
+			nv, ok = v.(json.Number)
+			if !ok {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want number for %s", k)
+			}
 		default:
 			return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "unrecognized tag %s", k)
 		}
@@ -958,14 +1220,90 @@ func BuildQueryRule(ruleInfo map[string]any) (qr *Rule, err error) {
 				qr.act = QRFailRetry
 			case "BUFFER":
 				qr.act = QRBuffer
+			case "THROTTLE":
+				qr.act = QRThrottle
+			case "REWRITE":
+				qr.act = QRRewrite
+			case "LOG":
+				qr.act = QRLog
 			default:
 				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "invalid Action %s", sv)
 			}
+		case "MaxQPS":
+			maxQPS, err := nv.Float64()
+			if err != nil {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want float for MaxQPS: %s", nv)
+			}
+			if err := qr.SetMaxQPS(maxQPS); err != nil {
+				return nil, err
+			}
+		case "OptimizerHints":
+			qr.SetOptimizerHints(sv)
+		case "IndexHints":
+			for _, ih := range lv {
+				table, hintType, indexes, err := buildIndexHint(ih)
+				if err != nil {
+					return nil, err
+				}
+				if err := qr.AddIndexHint(table, hintType, indexes...); err != nil {
+					return nil, err
+				}
+			}
+		case "SampleRate":
+			sampleRate, err := nv.Float64()
+			if err != nil {
+				return nil, vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want float for SampleRate: %s", nv)
+			}
+			if err := qr.SetSampleRate(sampleRate); err != nil {
+				return nil, err
+			}
 		}
 	}
+	if err := qr.validate(); err != nil {
+		return nil, err
+	}
 	return qr, nil
 }
 
+func buildIndexHint(ih any) (table string, hintType sqlparser.IndexHintType, indexes []string, err error) {
+	ihinfo, ok := ih.(map[string]any)
+	if !ok {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want json object for index hints")
+		return
+	}
+
+	table, ok = ihinfo["Table"].(string)
+	if !ok {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for Table in IndexHints")
+		return
+	}
+
+	strtype, ok := ihinfo["Type"].(string)
+	if !ok {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for Type in IndexHints")
+		return
+	}
+	hintType, err = MapStrIndexHintType(strtype)
+	if err != nil {
+		return
+	}
+
+	lv, ok := ihinfo["Indexes"].([]any)
+	if !ok {
+		err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want list for Indexes in IndexHints")
+		return
+	}
+	for _, v := range lv {
+		index, ok := v.(string)
+		if !ok {
+			err = vterrors.Errorf(vtrpcpb.Code_INVALID_ARGUMENT, "want string for Indexes in IndexHints")
+			return
+		}
+		indexes = append(indexes, index)
+	}
+	return
+}
+
 func buildBindVarCondition(bvc any) (name string, onAbsent, onMismatch bool, op Operator, value any, err error) {
 	bvcinfo, ok := bvc.(map[string]any)
 	if !ok {
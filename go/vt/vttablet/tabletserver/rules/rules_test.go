@@ -26,6 +26,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"vitess.io/vitess/go/sqltypes"
 	"vitess.io/vitess/go/vt/sqlparser"
@@ -586,6 +587,98 @@ func TestAction(t *testing.T) {
 	assert.Equalf(t, desc, "rule 5", "want rule 5, got %s", desc)
 }
 
+func TestThrottleAction(t *testing.T) {
+	qrs := New()
+	qr := NewQueryRule("throttle", "r1", QRThrottle)
+	require.NoError(t, qr.SetMaxQPS(1))
+	qr.AddPlanCond(planbuilder.PlanSelect)
+	qrs.Add(qr)
+
+	// Copies of the rule share its rate.
+	plan1 := qrs.FilterByPlan("select * from a", planbuilder.PlanSelect)
+	plan2 := qrs.FilterByPlan("select * from b", planbuilder.PlanSelect)
+
+	action, _, _, _ := plan1.GetAction("", "", nil, sqlparser.MarginComments{})
+	assert.Equal(t, QRContinue, action)
+	action, _, _, desc := plan2.GetAction("", "", nil, sqlparser.MarginComments{})
+	assert.Equal(t, QRThrottle, action)
+	assert.Equal(t, "throttle", desc)
+}
+
+func TestRulesMatches(t *testing.T) {
+	qrs := New()
+	qr1 := NewQueryRule("throttle", "r1", QRThrottle)
+	require.NoError(t, qr1.SetMaxQPS(1))
+	qr1.SetUserCond("batch")
+	qrs.Add(qr1)
+	qr2 := NewQueryRule("log", "r2", QRLog)
+	require.NoError(t, qr2.SetSampleRate(0.000001))
+	qr2.SetUserCond("reporter")
+	qrs.Add(qr2)
+
+	// Matching doesn't consume the rate of throttling rules.
+	for i := 0; i < 3; i++ {
+		assert.True(t, qrs.Matches("", "batch", nil, sqlparser.MarginComments{}))
+	}
+	action, _, _, _ := qrs.GetAction("", "batch", nil, sqlparser.MarginComments{})
+	assert.Equal(t, QRContinue, action)
+
+	// Rules whose action is skipped by GetAction, or that don't sample the input, still match.
+	assert.True(t, qrs.Matches("", "reporter", nil, sqlparser.MarginComments{}))
+	assert.False(t, qrs.Matches("", "oltp", nil, sqlparser.MarginComments{}))
+}
+
+func TestRewriteAndLogActions(t *testing.T) {
+	qrs := New()
+
+	qr1 := NewQueryRule("log", "r1", QRLog)
+	qrs.Add(qr1)
+
+	qr2 := NewQueryRule("rewrite a", "r2", QRRewrite)
+	qr2.SetOptimizerHints("MAX_EXECUTION_TIME(1000)")
+	require.NoError(t, qr2.AddIndexHint("a", sqlparser.ForceOp, "idx1", "idx2"))
+	qrs.Add(qr2)
+
+	qr3 := NewQueryRule("rewrite b", "r3", QRRewrite)
+	qr3.SetUserCond("user")
+	require.NoError(t, qr3.AddIndexHint("b", sqlparser.IgnoreOp, "idx3"))
+	qrs.Add(qr3)
+
+	qr4 := NewQueryRule("fail", "r4", QRFail)
+	qr4.SetUserCond("user")
+	qrs.Add(qr4)
+
+	// Rewrite and log rules don't stop the evaluation of other rules.
+	action, _, _, desc := qrs.GetAction("", "user", nil, sqlparser.MarginComments{})
+	assert.Equal(t, QRFail, action)
+	assert.Equal(t, "fail", desc)
+
+	rewrites, logRule := qrs.GetRewritesAndLog("", "user", nil, sqlparser.MarginComments{})
+	assert.Equal(t, []*Rule{qr2, qr3}, rewrites)
+	assert.Equal(t, qr1, logRule)
+
+	rewrites, _ = qrs.GetRewritesAndLog("", "user1", nil, sqlparser.MarginComments{})
+	assert.Equal(t, []*Rule{qr2}, rewrites)
+
+	stmt, err := sqlparser.NewTestParser().Parse("select * from a join b on a.id = b.id where a.id in (select id from a as c)")
+	require.NoError(t, err)
+	for _, qr := range []*Rule{qr2, qr3} {
+		require.NoError(t, qr.Rewrite(stmt))
+	}
+	assert.Equal(t, "select /*+ MAX_EXECUTION_TIME(1000) */ * from a force index (idx1, idx2) join b ignore index (idx3) on a.id = b.id where a.id in (select id from a as c force index (idx1, idx2))", sqlparser.String(stmt))
+
+	// A sample rate below one logs some of the matching queries.
+	require.NoError(t, qr1.SetSampleRate(0.5))
+	logged := 0
+	for i := 0; i < 1000; i++ {
+		if _, logRule := qrs.GetRewritesAndLog("", "", nil, sqlparser.MarginComments{}); logRule != nil {
+			logged++
+		}
+	}
+	assert.Greater(t, logged, 0)
+	assert.Less(t, logged, 1000)
+}
+
 func TestImport(t *testing.T) {
 	var qrs = New()
 	jsondata := `[{
@@ -612,6 +705,26 @@ func TestImport(t *testing.T) {
 		"Description": "desc2",
 		"Name": "name2",
 		"Action": "FAIL"
+	},{
+		"Description": "desc3",
+		"Name": "name3",
+		"Action": "THROTTLE",
+		"MaxQPS": 2.5
+	},{
+		"Description": "desc4",
+		"Name": "name4",
+		"Action": "REWRITE",
+		"OptimizerHints": "MAX_EXECUTION_TIME(1000)",
+		"IndexHints": [{
+			"Table": "a",
+			"Type": "FORCE",
+			"Indexes": ["idx1", "idx2"]
+		}]
+	},{
+		"Description": "desc5",
+		"Name": "name5",
+		"Action": "LOG",
+		"SampleRate": 0.5
 	}]`
 	err := qrs.UnmarshalJSON([]byte(jsondata))
 	if err != nil {
@@ -734,6 +847,18 @@ var invalidjsons = []InvalidJSONCase{
 	{`[{"BindVarConds": [{"Name": "a", "OnAbsent": true, "OnMismatch": true, "Operator": "NOMATCH", "Value": "["}]}]`, "processing [: error parsing regexp: missing closing ]: `[$`"},
 	{`[{"Action": 1 }]`, "want string for Action"},
 	{`[{"Action": "foo" }]`, "invalid Action foo"},
+	{`[{"Action": "THROTTLE" }]`, "MaxQPS missing for Action THROTTLE"},
+	{`[{"MaxQPS": "1" }]`, "want number for MaxQPS"},
+	{`[{"MaxQPS": -1 }]`, "MaxQPS must be positive: -1"},
+	{`[{"Action": "REWRITE" }]`, "OptimizerHints or IndexHints missing for Action REWRITE"},
+	{`[{"OptimizerHints": 1 }]`, "want string for OptimizerHints"},
+	{`[{"IndexHints": 1 }]`, "want list for IndexHints"},
+	{`[{"IndexHints": [1] }]`, "want json object for index hints"},
+	{`[{"IndexHints": [{"Type": "USE", "Indexes": ["i"]}] }]`, "want string for Table in IndexHints"},
+	{`[{"IndexHints": [{"Table": "a", "Type": "PREFER", "Indexes": ["i"]}] }]`, "invalid index hint Type PREFER"},
+	{`[{"IndexHints": [{"Table": "a", "Type": "USE", "Indexes": "i"}] }]`, "want list for Indexes in IndexHints"},
+	{`[{"IndexHints": [{"Table": "a", "Type": "USE", "Indexes": []}] }]`, "Indexes missing in IndexHints"},
+	{`[{"SampleRate": 2 }]`, "SampleRate must be in (0, 1]: 2"},
 }
 
 func TestInvalidJSON(t *testing.T) {
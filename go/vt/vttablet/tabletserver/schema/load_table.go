@@ -107,6 +107,26 @@ func loadMessageInfo(ta *Table, comment string, collationEnv *collations.Environ
 
 	ta.MessageInfo.MaxBackoff, _ = getDuration(keyvals, "vt_max_backoff")
 
+	// vt_max_retries is optional too, but a malformed value is an error: silently
+	// ignoring it would let poison messages be retried forever.
+	if keyvals["vt_max_retries"] != "" {
+		if ta.MessageInfo.MaxRetries, err = getNum(keyvals, "vt_max_retries"); err != nil {
+			return err
+		}
+		if ta.MessageInfo.MaxRetries < 0 {
+			return fmt.Errorf("vt_max_retries must not be negative: %s", ta.Name.String())
+		}
+	}
+	ta.MessageInfo.DeadLetterTable = strings.TrimSpace(keyvals["vt_dead_letter_table"])
+	if ta.MessageInfo.DeadLetterTable != "" {
+		if ta.MessageInfo.MaxRetries == 0 {
+			return fmt.Errorf("vt_dead_letter_table requires vt_max_retries: %s", ta.Name.String())
+		}
+		if strings.EqualFold(ta.MessageInfo.DeadLetterTable, ta.Name.String()) {
+			return fmt.Errorf("vt_dead_letter_table must be a different table: %s", ta.Name.String())
+		}
+	}
+
 	// these columns are required for message manager to function properly, but only
 	// id is required to be streamed to subscribers
 	requiredCols := []string{
@@ -117,6 +137,12 @@ func loadMessageInfo(ta *Table, comment string, collationEnv *collations.Environ
 		"time_acked",
 	}
 
+	// partitioned messages are ordered by time_scheduled, which is otherwise optional
+	ta.MessageInfo.PartitionKey = strings.TrimSpace(keyvals["vt_partition_key"])
+	if ta.MessageInfo.PartitionKey != "" {
+		requiredCols = append(requiredCols, ta.MessageInfo.PartitionKey, "time_scheduled")
+	}
+
 	// by default, these columns are loaded for the message manager, but not sent to subscribers
 	// via stream * from msg_tbl
 	hiddenCols := map[string]struct{}{
@@ -160,6 +160,32 @@ func TestLoadTableMessage(t *testing.T) {
 	want.MessageInfo.MaxBackoff = 100 * time.Second
 	assert.Equal(t, want, table)
 
+	// Test loading max retries and dead letter table
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_retries=5", db)
+	require.NoError(t, err)
+	want.MessageInfo.MaxRetries = 5
+	assert.Equal(t, want, table)
+
+	table, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_min_backoff=10,vt_max_backoff=100,vt_max_retries=5,vt_dead_letter_table=test_table_dlq", db)
+	require.NoError(t, err)
+	want.MessageInfo.DeadLetterTable = "test_table_dlq"
+	assert.Equal(t, want, table)
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_retries=five", db)
+	require.ErrorContains(t, err, "invalid syntax")
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_retries=-1", db)
+	require.Equal(t, errors.New("vt_max_retries must not be negative: test_table"), err)
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_dead_letter_table=test_table_dlq", db)
+	require.Equal(t, errors.New("vt_dead_letter_table requires vt_max_retries: test_table"), err)
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_max_retries=5,vt_dead_letter_table=test_table", db)
+	require.Equal(t, errors.New("vt_dead_letter_table must be a different table: test_table"), err)
+
+	want.MessageInfo.MaxRetries = 0
+	want.MessageInfo.DeadLetterTable = ""
+
 	//
 	// multiple tests for vt_message_cols
 	//
@@ -228,6 +254,50 @@ func TestLoadTableMessage(t *testing.T) {
 	}
 }
 
+func TestLoadTablePartitionedMessage(t *testing.T) {
+	db := fakesqldb.New(t)
+	defer db.Close()
+	mockMessageTableQueries(db)
+
+	_, err := newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_partition_key=tenant_id", db)
+	require.Equal(t, errors.New("tenant_id missing from message table: test_table"), err)
+
+	_, err = newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_partition_key=message", db)
+	require.Equal(t, errors.New("time_scheduled missing from message table: test_table"), err)
+
+	db.ClearQueryPattern()
+	db.MockQueriesForTable("test_table", &sqltypes.Result{
+		Fields: []*querypb.Field{{
+			Name: "id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "priority",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "time_next",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "epoch",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "time_acked",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "time_scheduled",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "tenant_id",
+			Type: sqltypes.Int64,
+		}, {
+			Name: "message",
+			Type: sqltypes.VarBinary,
+		}},
+	})
+	table, err := newTestLoadTable("USER_TABLE", "vitess_message,vt_ack_wait=30,vt_purge_after=120,vt_batch_size=1,vt_cache_size=10,vt_poller_interval=30,vt_partition_key=tenant_id", db)
+	require.NoError(t, err)
+	assert.Equal(t, "tenant_id", table.MessageInfo.PartitionKey)
+}
+
 func newTestLoadTable(tableType string, comment string, db *fakesqldb.DB) (*Table, error) {
 	ctx := context.Background()
 	appParams := dbconfigs.New(db.ConnParams())
@@ -125,6 +125,23 @@ type MessageInfo struct {
 	// MaxBackoff specifies the longest duration message manager
 	// should wait before rescheduling a message
 	MaxBackoff time.Duration
+
+	// MaxRetries specifies the number of times a message is sent
+	// before it's dead-lettered. Zero means no limit.
+	MaxRetries int
+
+	// DeadLetterTable specifies the message table to which messages
+	// are moved once they reach MaxRetries. If empty, such messages
+	// are marked as failed by setting their time_next to null.
+	DeadLetterTable string
+
+	// PartitionKey specifies the column by which messages are
+	// partitioned. If set, messages that share a key are sent
+	// one at a time in time_scheduled order: a message is sent
+	// only after all earlier ones are acked or dead-lettered.
+	// Ordering is per shard, so it must be the sharding key of the
+	// table or a column that determines it.
+	PartitionKey string
 }
 
 // NewTable creates a new Table.
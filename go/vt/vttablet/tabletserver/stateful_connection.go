@@ -58,6 +58,9 @@ type Properties struct {
 	ImmediateCaller *querypb.VTGateCallerID
 	StartTime       time.Time
 	Stats           *servenv.TimingsWrapper
+
+	// AdmissionDone releases the admission control slot of the reserved connection.
+	AdmissionDone func()
 }
 
 // Close closes the underlying connection. When the connection is Unblocked, it will be Released
@@ -168,6 +171,13 @@ func (sc *StatefulConnection) Releasef(reasonFormat string, a ...any) {
 	if sc.dbConn == nil {
 		return
 	}
+	if sc.txProps != nil && sc.txProps.AdmissionDone != nil {
+		// The transaction did not complete, e.g. because its connection broke.
+		sc.txProps.AdmissionDone()
+	}
+	if sc.reservedProps != nil && sc.reservedProps.AdmissionDone != nil {
+		sc.reservedProps.AdmissionDone()
+	}
 	sc.pool.unregister(sc.ConnID, fmt.Sprintf(reasonFormat, a...))
 	sc.dbConn.Recycle()
 	sc.dbConn = nil
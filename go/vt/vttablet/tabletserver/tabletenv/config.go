@@ -17,10 +17,12 @@ limitations under the License.
 package tabletenv
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"sync"
 	"time"
 
@@ -66,9 +68,15 @@ var (
 	// StatsLogger is the main stream logger object
 	StatsLogger = streamlog.New[*LogStats]("TabletServer", 50)
 
+	// RuleLogger receives the queries sampled by query rules with the LOG action.
+	RuleLogger = streamlog.New[*LogStats]("RuleLog", 50)
+
 	// The following vars are used for custom initialization of Tabletconfig.
 	enableHotRowProtection       bool
 	enableHotRowProtectionDryRun bool
+	enableAdmissionControl       bool
+	enableAdmissionControlDryRun bool
+	admissionControlConfigFile   string
 	enableConsolidator           bool
 	enableConsolidatorReplicas   bool
 	enableHeartbeat              bool
@@ -91,6 +99,7 @@ func init() {
 var (
 	queryLogHandler = "/debug/querylog"
 	txLogHandler    = "/debug/txlog"
+	ruleLogHandler  = "/debug/rulelog"
 )
 
 type TxThrottlerConfigFlag struct {
@@ -120,6 +129,7 @@ func RegisterTabletEnvFlags(fs *pflag.FlagSet) {
 func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&queryLogHandler, "query-log-stream-handler", queryLogHandler, "URL handler for streaming queries log")
 	fs.StringVar(&txLogHandler, "transaction-log-stream-handler", txLogHandler, "URL handler for streaming transactions log")
+	fs.StringVar(&ruleLogHandler, "rule-log-stream-handler", ruleLogHandler, "URL handler for streaming the queries sampled by query rules with the LOG action")
 
 	fs.IntVar(&currentConfig.OltpReadPool.Size, "queryserver-config-pool-size", defaultConfig.OltpReadPool.Size, "query server read pool size, connection pool is used by regular queries (non streaming, not in a transaction)")
 	fs.IntVar(&currentConfig.OlapReadPool.Size, "queryserver-config-stream-pool-size", defaultConfig.OlapReadPool.Size, "query server stream connection pool size, stream pool is used by stream queries: queries that return results to client in a streaming fashion")
@@ -174,6 +184,10 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&currentConfig.HotRowProtection.MaxGlobalQueueSize, "hot_row_protection_max_global_queue_size", defaultConfig.HotRowProtection.MaxGlobalQueueSize, "Global queue limit across all row (ranges). Useful to prevent that the queue can grow unbounded.")
 	fs.IntVar(&currentConfig.HotRowProtection.MaxConcurrency, "hot_row_protection_concurrent_transactions", defaultConfig.HotRowProtection.MaxConcurrency, "Number of concurrent transactions let through to the txpool/MySQL for the same hot row. Should be > 1 to have enough 'ready' transactions in MySQL and benefit from a pipelining effect.")
 
+	fs.BoolVar(&enableAdmissionControl, "enable-admission-control", false, "If true, queries and transactions are classified into the workload classes of --admission-control-config, and each class is limited to its share of the query and transaction pools.")
+	fs.BoolVar(&enableAdmissionControlDryRun, "enable-admission-control-dry-run", false, "If true, admission control is not enforced but records which queries and transactions would have been queued.")
+	fs.StringVar(&admissionControlConfigFile, "admission-control-config", "", "Path to a JSON file with the list of workload classes of admission control.")
+
 	fs.BoolVar(&currentConfig.EnableTransactionLimit, "enable_transaction_limit", defaultConfig.EnableTransactionLimit, "If true, limit on number of transactions open at the same time will be enforced for all users. User trying to open a new transaction after exhausting their limit will receive an error immediately, regardless of whether there are available slots or not.")
 	fs.BoolVar(&currentConfig.EnableTransactionLimitDryRun, "enable_transaction_limit_dry_run", defaultConfig.EnableTransactionLimitDryRun, "If true, limit on number of transactions open at the same time will be tracked for all users, but not enforced.")
 	fs.Float64Var(&currentConfig.TransactionLimitPerUser, "transaction_limit_per_user", defaultConfig.TransactionLimitPerUser, "Maximum number of transactions a single user is allowed to use at any time, represented as fraction of -transaction_cap.")
@@ -215,6 +229,7 @@ func registerTabletEnvFlags(fs *pflag.FlagSet) {
 var (
 	queryLogHandlerOnce sync.Once
 	txLogHandlerOnce    sync.Once
+	ruleLogHandlerOnce  sync.Once
 )
 
 // Init must be called after flag.Parse, and before doing any other operations.
@@ -236,6 +251,25 @@ func Init() {
 		currentConfig.HotRowProtection.Mode = Disable
 	}
 
+	if enableAdmissionControl {
+		if enableAdmissionControlDryRun {
+			currentConfig.AdmissionControl.Mode = Dryrun
+		} else {
+			currentConfig.AdmissionControl.Mode = Enable
+		}
+	} else {
+		currentConfig.AdmissionControl.Mode = Disable
+	}
+	if admissionControlConfigFile != "" {
+		data, err := os.ReadFile(admissionControlConfigFile)
+		if err != nil {
+			log.Exitf("Failed to read admission control config %v: %v", admissionControlConfigFile, err)
+		}
+		if currentConfig.AdmissionControl.Classes, err = parseAdmissionClasses(data); err != nil {
+			log.Exitf("Failed to parse admission control config %v: %v", admissionControlConfigFile, err)
+		}
+	}
+
 	switch {
 	case enableConsolidatorReplicas:
 		currentConfig.Consolidator = NotOnPrimary
@@ -289,6 +323,12 @@ func Init() {
 			TxLogger.ServeLogs(txLogHandler, streamlog.GetFormatter(TxLogger))
 		})
 	}
+
+	if ruleLogHandler != "" {
+		ruleLogHandlerOnce.Do(func() {
+			RuleLogger.ServeLogs(ruleLogHandler, streamlog.GetFormatter(RuleLogger))
+		})
+	}
 }
 
 // TabletConfig contains all the configuration for query service
@@ -304,6 +344,7 @@ type TabletConfig struct {
 	Olap             OlapConfig             `json:"olap,omitempty"`
 	Oltp             OltpConfig             `json:"oltp,omitempty"`
 	HotRowProtection HotRowProtectionConfig `json:"hotRowProtection,omitempty"`
+	AdmissionControl AdmissionControlConfig `json:"admissionControl,omitempty"`
 
 	Healthcheck  HealthcheckConfig  `json:"healthcheck,omitempty"`
 	GracePeriods GracePeriodsConfig `json:"gracePeriods,omitempty"`
@@ -622,6 +663,39 @@ type HotRowProtectionConfig struct {
 	MaxConcurrency     int    `json:"maxConcurrency,omitempty"`
 }
 
+// AdmissionControlConfig contains the config for workload class admission control.
+type AdmissionControlConfig struct {
+	// Mode can be disable, dryRun or enable. Default is disable.
+	Mode    string                  `json:"mode,omitempty"`
+	Classes []*AdmissionClassConfig `json:"classes,omitempty"`
+}
+
+// AdmissionClassConfig contains the config of a workload class: which queries
+// and transactions belong to it, and how much of the query and transaction
+// pools it can use.
+// A request belongs to the first class that matches its WORKLOAD_NAME
+// directive, its immediate caller's username, its effective caller's
+// principal or one of its query rules. Requests that match no class belong
+// to the class named "default", which is implicitly created if not configured.
+type AdmissionClassConfig struct {
+	Name string `json:"name,omitempty"`
+	// GuaranteedShare is the fraction of each pool that is reserved for the class.
+	GuaranteedShare float64 `json:"guaranteedShare,omitempty"`
+	// MaxShare is the fraction of each pool that the class can use at most.
+	// 0 means the whole pool.
+	MaxShare float64 `json:"maxShare,omitempty"`
+	// QueueTimeout is how long a request waits for a slot before it is
+	// rejected. 0 means it waits for as long as its context allows.
+	QueueTimeout Seconds `json:"queueTimeoutSeconds,omitempty"`
+
+	WorkloadNames []string `json:"workloadNames,omitempty"`
+	Usernames     []string `json:"usernames,omitempty"`
+	Principals    []string `json:"principals,omitempty"`
+	// Rules are query rules in the format of the query rules files.
+	// Their actions are ignored. They only classify queries, not transactions.
+	Rules []map[string]any `json:"rules,omitempty"`
+}
+
 // HealthcheckConfig contains the config for healthcheck.
 type HealthcheckConfig struct {
 	Interval           time.Duration
@@ -869,6 +943,9 @@ func (c *TabletConfig) Verify() error {
 	if err := c.verifyTxThrottlerConfig(); err != nil {
 		return err
 	}
+	if err := c.verifyAdmissionControlConfig(); err != nil {
+		return err
+	}
 	if v := c.HotRowProtection.MaxQueueSize; v <= 0 {
 		return fmt.Errorf("--hot_row_protection_max_queue_size must be > 0 (specified value: %v)", v)
 	}
@@ -928,6 +1005,69 @@ func (c *TabletConfig) checkConnectionForExternalMysql() error {
 	return conn.Ping()
 }
 
+// parseAdmissionClasses parses the admission control config file.
+func parseAdmissionClasses(data []byte) ([]*AdmissionClassConfig, error) {
+	var classes []*AdmissionClassConfig
+	// Like in query rules files, numbers must be decoded as json.Number
+	// for the bind variable conditions of the rules.
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&classes); err != nil {
+		return nil, err
+	}
+	return classes, nil
+}
+
+// verifyAdmissionRule checks a query rule of an admission control class.
+// The rules package depends on this one, so it's registered by the admission package.
+var verifyAdmissionRule func(ruleInfo map[string]any) error
+
+// RegisterAdmissionRuleVerifier registers the function which checks the
+// query rules of the admission control classes in Verify().
+func RegisterAdmissionRuleVerifier(verify func(ruleInfo map[string]any) error) {
+	verifyAdmissionRule = verify
+}
+
+// verifyAdmissionControlConfig checks AdmissionControlConfig for sanity
+func (c *TabletConfig) verifyAdmissionControlConfig() error {
+	names := make(map[string]bool, len(c.AdmissionControl.Classes))
+	var guaranteed float64
+	for _, class := range c.AdmissionControl.Classes {
+		if class.Name == "" {
+			return errors.New("admission control class without a name")
+		}
+		if names[class.Name] {
+			return fmt.Errorf("duplicate admission control class %v", class.Name)
+		}
+		names[class.Name] = true
+		if v := class.GuaranteedShare; v < 0 || v > 1 {
+			return fmt.Errorf("guaranteed share of admission control class %v must be within range [0, 1] (specified value: %v)", class.Name, v)
+		}
+		if v := class.MaxShare; v < 0 || v > 1 {
+			return fmt.Errorf("max share of admission control class %v must be within range [0, 1] (specified value: %v)", class.Name, v)
+		}
+		if class.MaxShare != 0 && class.MaxShare < class.GuaranteedShare {
+			return fmt.Errorf("max share of admission control class %v must be >= its guaranteed share (%v < %v)", class.Name, class.MaxShare, class.GuaranteedShare)
+		}
+		if v := class.QueueTimeout; v < 0 {
+			return fmt.Errorf("queue timeout of admission control class %v must be >= 0 (specified value: %v)", class.Name, v)
+		}
+		for _, ruleInfo := range class.Rules {
+			if verifyAdmissionRule == nil {
+				break
+			}
+			if err := verifyAdmissionRule(ruleInfo); err != nil {
+				return fmt.Errorf("invalid query rule of admission control class %v: %v", class.Name, err)
+			}
+		}
+		guaranteed += class.GuaranteedShare
+	}
+	if guaranteed > 1 {
+		return fmt.Errorf("guaranteed shares of admission control classes add up to more than 1 (%v)", guaranteed)
+	}
+	return nil
+}
+
 // verifyTransactionLimitConfig checks TransactionLimitConfig for sanity
 func (c *TabletConfig) verifyTransactionLimitConfig() error {
 	actual, dryRun := c.EnableTransactionLimit, c.EnableTransactionLimitDryRun
@@ -1033,6 +1173,9 @@ var defaultConfig = TabletConfig{
 		// of them ready in MySQL and profit from a pipelining effect.
 		MaxConcurrency: 5,
 	},
+	AdmissionControl: AdmissionControlConfig{
+		Mode: Disable,
+	},
 	Consolidator:                Enable,
 	ConsolidatorStreamTotalSize: 128 * 1024 * 1024,
 	ConsolidatorStreamQuerySize: 2 * 1024 * 1024,
@@ -17,6 +17,8 @@ limitations under the License.
 package tabletenv
 
 import (
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -62,7 +64,8 @@ func TestConfigParse(t *testing.T) {
 
 	gotBytes, err := yaml2.Marshal(&cfg)
 	require.NoError(t, err)
-	wantBytes := `db:
+	wantBytes := `admissionControl: {}
+db:
   allprivs:
     password: '****'
   app:
@@ -122,7 +125,9 @@ oltpReadPool:
 func TestDefaultConfig(t *testing.T) {
 	gotBytes, err := yaml2.Marshal(NewDefaultConfig())
 	require.NoError(t, err)
-	want := `consolidator: enable
+	want := `admissionControl:
+  mode: disable
+consolidator: enable
 consolidatorStreamQuerySize: 2097152
 consolidatorStreamTotalSize: 134217728
 gracePeriods:
@@ -205,6 +210,7 @@ func TestFlags(t *testing.T) {
 	want.OlapReadPool.IdleTimeout = 30 * time.Minute
 	want.TxPool.IdleTimeout = 30 * time.Minute
 	want.HotRowProtection.Mode = Disable
+	want.AdmissionControl.Mode = Disable
 	want.Consolidator = Enable
 	want.Healthcheck.Interval = 20 * time.Second
 	want.Healthcheck.DegradedThreshold = 30 * time.Second
@@ -481,3 +487,96 @@ func TestVerifyUnmanagedTabletConfig(t *testing.T) {
 	err = config.verifyUnmanagedTabletConfig()
 	assert.Nil(t, err)
 }
+
+func TestVerifyAdmissionControlConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		classes []*AdmissionClassConfig
+		wantErr string
+	}{
+		{
+			name: "no classes",
+		},
+		{
+			name: "valid classes",
+			classes: []*AdmissionClassConfig{
+				{Name: "oltp", GuaranteedShare: 0.6, QueueTimeout: 1},
+				{Name: "batch", GuaranteedShare: 0.1, MaxShare: 0.3},
+			},
+		},
+		{
+			name:    "missing name",
+			classes: []*AdmissionClassConfig{{GuaranteedShare: 0.5}},
+			wantErr: "admission control class without a name",
+		},
+		{
+			name:    "duplicate name",
+			classes: []*AdmissionClassConfig{{Name: "oltp"}, {Name: "oltp"}},
+			wantErr: "duplicate admission control class oltp",
+		},
+		{
+			name:    "invalid guaranteed share",
+			classes: []*AdmissionClassConfig{{Name: "oltp", GuaranteedShare: 1.5}},
+			wantErr: "guaranteed share of admission control class oltp must be within range [0, 1] (specified value: 1.5)",
+		},
+		{
+			name:    "max share below guaranteed share",
+			classes: []*AdmissionClassConfig{{Name: "oltp", GuaranteedShare: 0.5, MaxShare: 0.2}},
+			wantErr: "max share of admission control class oltp must be >= its guaranteed share (0.2 < 0.5)",
+		},
+		{
+			name:    "negative queue timeout",
+			classes: []*AdmissionClassConfig{{Name: "oltp", QueueTimeout: -1}},
+			wantErr: "queue timeout of admission control class oltp must be >= 0 (specified value: -1)",
+		},
+		{
+			name:    "invalid query rule",
+			classes: []*AdmissionClassConfig{{Name: "reports", Rules: []map[string]any{{"Name": "r1"}, {}}}},
+			wantErr: "invalid query rule of admission control class reports: missing name",
+		},
+		{
+			name: "guaranteed shares above 1",
+			classes: []*AdmissionClassConfig{
+				{Name: "oltp", GuaranteedShare: 0.75},
+				{Name: "batch", GuaranteedShare: 0.5},
+			},
+			wantErr: "guaranteed shares of admission control classes add up to more than 1 (1.25)",
+		},
+	}
+	defer RegisterAdmissionRuleVerifier(verifyAdmissionRule)
+	RegisterAdmissionRuleVerifier(func(ruleInfo map[string]any) error {
+		if _, ok := ruleInfo["Name"]; !ok {
+			return errors.New("missing name")
+		}
+		return nil
+	})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			config := NewDefaultConfig()
+			config.AdmissionControl.Classes = test.classes
+			err := config.verifyAdmissionControlConfig()
+			if test.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, test.wantErr)
+		})
+	}
+}
+
+func TestParseAdmissionClasses(t *testing.T) {
+	classes, err := parseAdmissionClasses([]byte(`[{
+		"name": "reports",
+		"maxShare": 0.5,
+		"rules": [{"Name": "r1", "BindVarConds": [{"Name": "id", "OnAbsent": false, "Operator": ">", "Value": 5}]}]
+	}]`))
+	require.NoError(t, err)
+	require.Len(t, classes, 1)
+	assert.Equal(t, 0.5, classes[0].MaxShare)
+	// Like in query rules files, the numbers of the rules are not decoded as float64.
+	conds := classes[0].Rules[0]["BindVarConds"].([]any)
+	assert.Equal(t, json.Number("5"), conds[0].(map[string]any)["Value"])
+
+	_, err = parseAdmissionClasses([]byte(`{"name": "reports"}`))
+	assert.Error(t, err)
+}
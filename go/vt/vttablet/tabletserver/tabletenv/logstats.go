@@ -62,6 +62,8 @@ type LogStats struct {
 	ReservedID           int64
 	Error                error
 	CachedPlan           bool
+	// SendToRuleLog is set when a query rule with the LOG action sampled the query.
+	SendToRuleLog bool
 }
 
 // NewLogStats constructs a new LogStats with supplied Method and ctx
@@ -78,6 +80,9 @@ func NewLogStats(ctx context.Context, methodName string) *LogStats {
 func (stats *LogStats) Send() {
 	stats.EndTime = time.Now()
 	StatsLogger.Send(stats)
+	if stats.SendToRuleLog {
+		RuleLogger.Send(stats)
+	}
 }
 
 // ImmediateCaller returns the immediate caller stored in LogStats.Ctx
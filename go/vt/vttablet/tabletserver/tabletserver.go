@@ -54,6 +54,7 @@ import (
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vttablet/onlineddl"
 	"vitess.io/vitess/go/vt/vttablet/queryservice"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/admission"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/gc"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/planbuilder"
@@ -225,6 +226,7 @@ func NewTabletServer(ctx context.Context, env *vtenv.Environment, name string, c
 	tsv.registerTxlogzHandler()
 	tsv.registerQueryListHandlers([]*QueryList{tsv.statelessql, tsv.statefulql, tsv.olapql})
 	tsv.registerTwopczHandler()
+	tsv.registerAdmissionHandler()
 	tsv.registerMigrationStatusHandler()
 	tsv.registerThrottlerHandlers()
 	tsv.registerDebugEnvHandler()
@@ -1144,15 +1146,36 @@ func (tsv *TabletServer) PurgeMessages(ctx context.Context, target *querypb.Targ
 	})
 }
 
+// DeadLetterMessages moves the list of messages for a given message table
+// to its dead-letter table, or marks them as failed.
+// It returns the number of messages successfully dead-lettered.
+func (tsv *TabletServer) DeadLetterMessages(ctx context.Context, target *querypb.Target, querygen messager.QueryGenerator, ids []string) (count int64, err error) {
+	return tsv.execDMLs(ctx, target, func() ([]*querypb.BoundQuery, error) {
+		return querygen.GenerateDeadLetterQueries(ids), nil
+	})
+}
+
 func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, queryGenerator func() (string, map[string]*querypb.BindVariable, error)) (count int64, err error) {
+	return tsv.execDMLs(ctx, target, func() ([]*querypb.BoundQuery, error) {
+		query, bv, err := queryGenerator()
+		if err != nil {
+			return nil, err
+		}
+		return []*querypb.BoundQuery{{Sql: query, BindVariables: bv}}, nil
+	})
+}
+
+// execDMLs executes the queries in one transaction, and returns
+// the number of rows affected by the last one.
+func (tsv *TabletServer) execDMLs(ctx context.Context, target *querypb.Target, queryGenerator func() ([]*querypb.BoundQuery, error)) (count int64, err error) {
 	if err = tsv.sm.StartRequest(ctx, target, false /* allowOnShutdown */); err != nil {
 		return 0, err
 	}
 	defer tsv.sm.EndRequest()
 	defer tsv.handlePanicAndSendLogStats("ack", nil, nil)
 
-	query, bv, err := queryGenerator()
-	if err != nil {
+	queries, err := queryGenerator()
+	if err != nil || len(queries) == 0 {
 		return 0, err
 	}
 
@@ -1167,9 +1190,11 @@ func (tsv *TabletServer) execDML(ctx context.Context, target *querypb.Target, qu
 			tsv.Rollback(ctx, target, state.TransactionID)
 		}
 	}()
-	qr, err := tsv.Execute(ctx, target, query, bv, state.TransactionID, 0, nil)
-	if err != nil {
-		return 0, err
+	var qr *sqltypes.Result
+	for _, query := range queries {
+		if qr, err = tsv.Execute(ctx, target, query.Sql, query.BindVariables, state.TransactionID, 0, nil); err != nil {
+			return 0, err
+		}
 	}
 	if _, err = tsv.Commit(ctx, target, state.TransactionID); err != nil {
 		state.TransactionID = 0
@@ -1869,6 +1894,10 @@ func (tsv *TabletServer) registerTwopczHandler() {
 	})
 }
 
+func (tsv *TabletServer) registerAdmissionHandler() {
+	tsv.exporter.HandleFunc("/debug/admission", tsv.te.admission.ServeHTTP)
+}
+
 func (tsv *TabletServer) registerMigrationStatusHandler() {
 	tsv.exporter.HandleFunc("/schema-migration/report-status", func(w http.ResponseWriter, r *http.Request) {
 		ctx := tabletenv.LocalContext()
@@ -1999,7 +2028,11 @@ func (tsv *TabletServer) SetPoolSize(ctx context.Context, val int) error {
 	if val <= 0 {
 		return nil
 	}
-	return tsv.qe.conns.SetCapacity(ctx, int64(val))
+	if err := tsv.qe.conns.SetCapacity(ctx, int64(val)); err != nil {
+		return err
+	}
+	tsv.te.admission.SetCapacity(admission.Queries, val)
+	return nil
 }
 
 // PoolSize returns the pool size.
@@ -2024,7 +2057,11 @@ func (tsv *TabletServer) StreamPoolSize() int {
 
 // SetTxPoolSize changes the tx pool size to the specified value.
 func (tsv *TabletServer) SetTxPoolSize(ctx context.Context, val int) error {
-	return tsv.te.txPool.scp.conns.SetCapacity(ctx, int64(val))
+	if err := tsv.te.txPool.scp.conns.SetCapacity(ctx, int64(val)); err != nil {
+		return err
+	}
+	tsv.te.admission.SetCapacity(admission.Transactions, val)
+	return nil
 }
 
 // TxPoolSize returns the tx pool size.
@@ -52,6 +52,7 @@ import (
 	"vitess.io/vitess/go/vt/tableacl/simpleacl"
 	"vitess.io/vitess/go/vt/topo/memorytopo"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/messager"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 
 	querypb "vitess.io/vitess/go/vt/proto/query"
@@ -1576,6 +1577,54 @@ func TestPurgeMessages(t *testing.T) {
 	require.EqualValues(t, 1, count)
 }
 
+// deadLetterGenerator overrides the dead-letter queries of a message table.
+type deadLetterGenerator struct {
+	messager.QueryGenerator
+	queries []*querypb.BoundQuery
+}
+
+func (gen *deadLetterGenerator) GenerateDeadLetterQueries(ids []string) []*querypb.BoundQuery {
+	return gen.queries
+}
+
+func TestDeadLetterMessages(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, tsv, db := newTestTxExecutor(t, ctx)
+	defer db.Close()
+	defer tsv.StopService()
+	target := querypb.Target{TabletType: topodatapb.TabletType_PRIMARY}
+
+	gen, err := tsv.messager.GetGenerator("msg")
+	require.NoError(t, err)
+
+	// msg has no max retries, so there is nothing to execute.
+	count, err := tsv.DeadLetterMessages(ctx, &target, gen, []string{"1", "2"})
+	require.NoError(t, err)
+	require.EqualValues(t, 0, count)
+
+	dlgen := &deadLetterGenerator{
+		QueryGenerator: gen,
+		queries: []*querypb.BoundQuery{{
+			Sql: "insert into msg_dlq(priority, time_next, epoch, time_acked, id) select priority, 1, 0, null, id from msg where id in (1, 2) and time_acked is null",
+		}, {
+			Sql: "delete from msg where id in (1, 2) and time_acked is null",
+		}},
+	}
+	_, err = tsv.DeadLetterMessages(ctx, &target, dlgen, []string{"1", "2"})
+	require.ErrorContains(t, err, "query: 'insert into msg_dlq")
+
+	// The queries are executed in one transaction.
+	deleteQuery := "delete from msg where id in (1, 2) and time_acked is null limit 10001"
+	db.AddQuery(dlgen.queries[0].Sql, &sqltypes.Result{RowsAffected: 2})
+	db.AddQuery(deleteQuery, &sqltypes.Result{RowsAffected: 2})
+	db.ResetQueryLog()
+	count, err = tsv.DeadLetterMessages(ctx, &target, dlgen, []string{"1", "2"})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, count)
+	assert.Equal(t, "begin;"+dlgen.queries[0].Sql+";"+deleteQuery+";commit", db.QueryLog())
+}
+
 func TestHandleExecUnknownError(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -0,0 +1,109 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MetricName is the name of a metric the throttler collects and checks
+type MetricName string
+
+// MetricNames is a list of metric names
+type MetricNames []MetricName
+
+const (
+	// DefaultMetricName is an alias to the metric that apps are checked against unless configured otherwise:
+	// the custom metric if the throttler has a custom query, or else replication lag.
+	DefaultMetricName           MetricName = "default"
+	LagMetricName               MetricName = "lag"
+	ThreadsRunningMetricName    MetricName = "threads_running"
+	LoadAvgMetricName           MetricName = "loadavg" // of vttablet's host, which is MySQL's host only if they run on the same host
+	HistoryListLengthMetricName MetricName = "history_list_length"
+	CustomMetricName            MetricName = "custom"
+)
+
+// KnownMetricNames are the names of the metrics a throttler may collect, in the order they are checked
+var KnownMetricNames = MetricNames{
+	LagMetricName,
+	ThreadsRunningMetricName,
+	LoadAvgMetricName,
+	HistoryListLengthMetricName,
+	CustomMetricName,
+}
+
+var defaultMetricThresholds = map[MetricName]float64{
+	LagMetricName:               5,    // seconds
+	ThreadsRunningMetricName:    100,  // threads
+	LoadAvgMetricName:           1,    // load average per CPU core
+	HistoryListLengthMetricName: 5000, // undo log records
+}
+
+// String implements fmt.Stringer
+func (metric MetricName) String() string {
+	return string(metric)
+}
+
+// DefaultThreshold returns the built-in threshold of a metric, which applies unless configured otherwise.
+// The custom metric has no meaningful built-in threshold and returns 0.
+func (metric MetricName) DefaultThreshold() float64 {
+	return defaultMetricThresholds[metric]
+}
+
+// AggregatedName returns the name under which the aggregated value of the metric in the given store is kept
+func (metric MetricName) AggregatedName(storeName string) string {
+	return fmt.Sprintf("mysql/%s/%s", storeName, metric)
+}
+
+// Contains returns true if the list contains the given metric name
+func (names MetricNames) Contains(name MetricName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// String returns a comma separated list of the metric names
+func (names MetricNames) String() string {
+	tokens := make([]string, 0, len(names))
+	for _, name := range names {
+		tokens = append(tokens, name.String())
+	}
+	return strings.Join(tokens, ",")
+}
+
+// ParseMetricNames parses and validates metric names. Empty names are skipped.
+func ParseMetricNames(names []string) (MetricNames, error) {
+	var metricNames MetricNames
+	for _, name := range names {
+		name = strings.TrimSpace(strings.ToLower(name))
+		if name == "" {
+			continue
+		}
+		metricName := MetricName(name)
+		if metricName != DefaultMetricName && !KnownMetricNames.Contains(metricName) {
+			return nil, fmt.Errorf("unknown metric name: %s, expecting one of %s,%s", name, DefaultMetricName, KnownMetricNames)
+		}
+		if !metricNames.Contains(metricName) {
+			metricNames = append(metricNames, metricName)
+		}
+	}
+	return metricNames, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package base
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMetricNames(t *testing.T) {
+	tcases := []struct {
+		names     []string
+		expect    MetricNames
+		expectErr bool
+	}{
+		{
+			names: nil,
+		},
+		{
+			names:  []string{"lag"},
+			expect: MetricNames{LagMetricName},
+		},
+		{
+			names:  []string{"Default", " threads_running", "", "lag", "default"},
+			expect: MetricNames{DefaultMetricName, ThreadsRunningMetricName, LagMetricName},
+		},
+		{
+			names:     []string{"lag", "no_such_metric"},
+			expectErr: true,
+		},
+	}
+	for _, tcase := range tcases {
+		t.Run(strings.Join(tcase.names, ","), func(t *testing.T) {
+			metricNames, err := ParseMetricNames(tcase.names)
+			if tcase.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tcase.expect, metricNames)
+		})
+	}
+}
+
+func TestMetricName(t *testing.T) {
+	assert.Equal(t, "mysql/self/lag", LagMetricName.AggregatedName("self"))
+	assert.Equal(t, "lag,loadavg", MetricNames{LagMetricName, LoadAvgMetricName}.String())
+	assert.True(t, KnownMetricNames.Contains(HistoryListLengthMetricName))
+	assert.False(t, KnownMetricNames.Contains(DefaultMetricName))
+	assert.Equal(t, 100.0, ThreadsRunningMetricName.DefaultThreshold())
+	assert.Zero(t, CustomMetricName.DefaultThreshold())
+}
@@ -87,12 +87,10 @@ func NewThrottlerCheck(throttler *Throttler) *ThrottlerCheck {
 }
 
 // checkAppMetricResult allows an app to check on a metric
-func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName string, storeType string, storeName string, metricResultFunc base.MetricResultFunc, flags *CheckFlags) (checkResult *CheckResult) {
-	// Handle deprioritized app logic
-	denyApp := false
-	//
+func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName string, metricName base.MetricName, metricResultFunc base.MetricResultFunc, denyApp bool, flags *CheckFlags) (checkResult *CheckResult) {
 	metricResult, threshold := check.throttler.AppRequestMetricResult(ctx, appName, metricResultFunc, denyApp)
-	if flags.OverrideThreshold > 0 {
+	if flags.OverrideThreshold > 0 && metricName == check.throttler.defaultMetricName() {
+		// The override applies to the default metric, which is what the general threshold applies to.
 		threshold = flags.OverrideThreshold
 	}
 	value, err := metricResult.Get()
@@ -123,22 +121,34 @@ func (check *ThrottlerCheck) checkAppMetricResult(ctx context.Context, appName s
 	return NewCheckResult(statusCode, value, threshold, err)
 }
 
-// Check is the core function that runs when a user wants to check a metric
-func (check *ThrottlerCheck) Check(ctx context.Context, appName string, storeType string, storeName string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
-	var metricResultFunc base.MetricResultFunc
-	switch storeType {
-	case "mysql":
-		{
-			metricResultFunc = func() (metricResult base.MetricResult, threshold float64) {
-				return check.throttler.getMySQLClusterMetrics(ctx, storeName)
-			}
-		}
-	}
-	if metricResultFunc == nil {
+// Check is the core function that runs when a user wants to check metrics. Each of the given metrics is checked,
+// and the result is that of the first metric that is not OK, or else of the first metric. The "vitess" app,
+// used by the throttler itself, always gets the result of the first metric, which is the default metric: tablets
+// that do not read per-metric results read that value.
+func (check *ThrottlerCheck) Check(ctx context.Context, appName string, storeType string, storeName string, metricNames base.MetricNames, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
+	if storeType != "mysql" || len(metricNames) == 0 {
 		return NoSuchMetricCheckResult
 	}
+	// Handle deprioritized app logic
+	denyApp := check.throttler.IsAppThrottled(appName)
+
+	metrics := make(map[string]*CheckResult, len(metricNames))
+	var mainResult *CheckResult
+	for _, metricName := range metricNames {
+		metricName := metricName
+		metricResultFunc := func() (metricResult base.MetricResult, threshold float64) {
+			return check.throttler.getMySQLClusterMetrics(ctx, storeName, metricName)
+		}
+		metricCheckResult := check.checkAppMetricResult(ctx, appName, metricName, metricResultFunc, denyApp, flags)
+		metrics[metricName.String()] = metricCheckResult
+		if mainResult == nil || (mainResult.StatusCode == http.StatusOK && metricCheckResult.StatusCode != http.StatusOK && !throttlerapp.VitessName.Equals(appName)) {
+			mainResult = metricCheckResult
+		}
+	}
+	checkResult = &CheckResult{}
+	*checkResult = *mainResult
+	checkResult.Metrics = metrics
 
-	checkResult = check.checkAppMetricResult(ctx, appName, storeType, storeName, metricResultFunc, flags)
 	check.throttler.markRecentApp(appName, remoteAddr)
 	if !throttlerapp.VitessName.Equals(appName) {
 		go func(statusCode int) {
@@ -154,42 +164,55 @@ func (check *ThrottlerCheck) Check(ctx context.Context, appName string, storeTyp
 	return checkResult
 }
 
-func (check *ThrottlerCheck) splitMetricTokens(metricName string) (storeType string, storeName string, err error) {
-	metricTokens := strings.Split(metricName, "/")
-	if len(metricTokens) != 2 {
-		return storeType, storeName, base.ErrNoSuchMetric
+func (check *ThrottlerCheck) splitMetricTokens(aggregatedName string) (storeType string, storeName string, metricName base.MetricName, err error) {
+	metricTokens := strings.Split(aggregatedName, "/")
+	if len(metricTokens) != 3 {
+		return storeType, storeName, metricName, base.ErrNoSuchMetric
 	}
 	storeType = metricTokens[0]
 	storeName = metricTokens[1]
+	metricName = base.MetricName(metricTokens[2])
+
+	return storeType, storeName, metricName, nil
+}
 
-	return storeType, storeName, nil
+// metricStatsName returns the name by which a metric is exported in stats: <StoreType><StoreName>[<MetricName>].
+// The default metric keeps the name it had before the throttler supported multiple metrics.
+func (check *ThrottlerCheck) metricStatsName(storeType string, storeName string, metricName base.MetricName) string {
+	name := textutil.SingleWordCamel(storeType) + textutil.SingleWordCamel(storeName)
+	if metricName != check.throttler.defaultMetricName() {
+		for _, word := range strings.Split(metricName.String(), "_") {
+			name += textutil.SingleWordCamel(word)
+		}
+	}
+	return name
 }
 
 // localCheck
-func (check *ThrottlerCheck) localCheck(ctx context.Context, metricName string) (checkResult *CheckResult) {
-	storeType, storeName, err := check.splitMetricTokens(metricName)
+func (check *ThrottlerCheck) localCheck(ctx context.Context, aggregatedName string) (checkResult *CheckResult) {
+	storeType, storeName, metricName, err := check.splitMetricTokens(aggregatedName)
 	if err != nil {
 		return NoSuchMetricCheckResult
 	}
-	checkResult = check.Check(ctx, throttlerapp.VitessName.String(), storeType, storeName, "local", StandardCheckFlags)
+	checkResult = check.Check(ctx, throttlerapp.VitessName.String(), storeType, storeName, base.MetricNames{metricName}, "local", StandardCheckFlags)
 
 	if checkResult.StatusCode == http.StatusOK {
-		check.throttler.markMetricHealthy(metricName)
+		check.throttler.markMetricHealthy(aggregatedName)
 	}
-	if timeSinceHealthy, found := check.throttler.timeSinceMetricHealthy(metricName); found {
-		stats.GetOrNewGauge(fmt.Sprintf("ThrottlerCheck%s%sSecondsSinceHealthy", textutil.SingleWordCamel(storeType), textutil.SingleWordCamel(storeName)), fmt.Sprintf("seconds since last healthy cehck for %s.%s", storeType, storeName)).Set(int64(timeSinceHealthy.Seconds()))
+	if timeSinceHealthy, found := check.throttler.timeSinceMetricHealthy(aggregatedName); found {
+		stats.GetOrNewGauge(fmt.Sprintf("ThrottlerCheck%sSecondsSinceHealthy", check.metricStatsName(storeType, storeName, metricName)), fmt.Sprintf("seconds since last healthy check for %s.%s.%s", storeType, storeName, metricName)).Set(int64(timeSinceHealthy.Seconds()))
 	}
 
 	return checkResult
 }
 
-func (check *ThrottlerCheck) reportAggregated(metricName string, metricResult base.MetricResult) {
-	storeType, storeName, err := check.splitMetricTokens(metricName)
+func (check *ThrottlerCheck) reportAggregated(aggregatedName string, metricResult base.MetricResult) {
+	storeType, storeName, metricName, err := check.splitMetricTokens(aggregatedName)
 	if err != nil {
 		return
 	}
 	if value, err := metricResult.Get(); err == nil {
-		stats.GetOrNewGaugeFloat64(fmt.Sprintf("ThrottlerAggregated%s%s", textutil.SingleWordCamel(storeType), textutil.SingleWordCamel(storeName)), fmt.Sprintf("aggregated value for %s.%s", storeType, storeName)).Set(value)
+		stats.GetOrNewGaugeFloat64(fmt.Sprintf("ThrottlerAggregated%s", check.metricStatsName(storeType, storeName, metricName)), fmt.Sprintf("aggregated value for %s.%s.%s", storeType, storeName, metricName)).Set(value)
 	}
 }
 
@@ -55,6 +55,8 @@ type CheckResult struct {
 	Error           error   `json:"-"`
 	Message         string  `json:"Message"`
 	RecentlyChecked bool    `json:"RecentlyChecked"`
+
+	Metrics map[string]*CheckResult `json:"Metrics,omitempty"` // per-metric results, when checking multiple metrics
 }
 
 // NewCheckResult returns a CheckResult
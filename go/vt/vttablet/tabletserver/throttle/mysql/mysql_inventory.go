@@ -64,7 +64,7 @@ type Inventory struct {
 	ClustersProbes       map[string](Probes)
 	IgnoreHostsCount     map[string]int
 	IgnoreHostsThreshold map[string]float64
-	TabletMetrics        TabletResultMap
+	TabletMetrics        map[base.MetricName]TabletResultMap
 }
 
 // NewInventory creates a Inventory
@@ -73,7 +73,19 @@ func NewInventory() *Inventory {
 		ClustersProbes:       make(map[string](Probes)),
 		IgnoreHostsCount:     make(map[string]int),
 		IgnoreHostsThreshold: make(map[string]float64),
-		TabletMetrics:        make(map[ClusterTablet]base.MetricResult),
+		TabletMetrics:        make(map[base.MetricName]TabletResultMap),
 	}
 	return inventory
 }
+
+// AddTabletMetrics adds or replaces the metrics of a tablet in the inventory
+func (inventory *Inventory) AddTabletMetrics(metrics MySQLThrottleMetrics) {
+	for name, metric := range metrics {
+		tabletResultMap, ok := inventory.TabletMetrics[name]
+		if !ok {
+			tabletResultMap = make(TabletResultMap)
+			inventory.TabletMetrics[name] = tabletResultMap
+		}
+		tabletResultMap[metric.GetClusterTablet()] = metric
+	}
+}
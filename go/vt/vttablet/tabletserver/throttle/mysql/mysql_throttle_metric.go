@@ -49,6 +49,8 @@ import (
 	"github.com/patrickmn/go-cache"
 
 	"vitess.io/vitess/go/stats"
+	"vitess.io/vitess/go/textutil"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
 )
 
 // MetricsQueryType indicates the type of metrics query on MySQL backend. See following.
@@ -71,23 +73,27 @@ func getMySQLMetricCacheKey(probe *Probe) string {
 	return fmt.Sprintf("%s:%s", probe.Alias, probe.MetricQuery)
 }
 
-func cacheMySQLThrottleMetric(probe *Probe, mySQLThrottleMetric *MySQLThrottleMetric) *MySQLThrottleMetric {
-	if mySQLThrottleMetric.Err != nil {
-		return mySQLThrottleMetric
+// cacheMySQLThrottleMetrics caches the metrics, unless the probe failed altogether, in which case
+// it is retried on the next round. A metric that fails on its own is cached along with the others:
+// otherwise a metric that never works, e.g. the load average on a host without /proc/loadavg,
+// would disable caching for all of them.
+func cacheMySQLThrottleMetrics(probe *Probe, mySQLThrottleMetrics MySQLThrottleMetrics) MySQLThrottleMetrics {
+	if mySQLThrottleMetrics.AllErr() {
+		return mySQLThrottleMetrics
 	}
 	if probe.CacheMillis > 0 {
-		mysqlMetricCache.Set(getMySQLMetricCacheKey(probe), mySQLThrottleMetric, time.Duration(probe.CacheMillis)*time.Millisecond)
+		mysqlMetricCache.Set(getMySQLMetricCacheKey(probe), mySQLThrottleMetrics, time.Duration(probe.CacheMillis)*time.Millisecond)
 	}
-	return mySQLThrottleMetric
+	return mySQLThrottleMetrics
 }
 
-func getCachedMySQLThrottleMetric(probe *Probe) *MySQLThrottleMetric {
+func getCachedMySQLThrottleMetrics(probe *Probe) MySQLThrottleMetrics {
 	if probe.CacheMillis == 0 {
 		return nil
 	}
-	if metric, found := mysqlMetricCache.Get(getMySQLMetricCacheKey(probe)); found {
-		mySQLThrottleMetric, _ := metric.(*MySQLThrottleMetric)
-		return mySQLThrottleMetric
+	if metrics, found := mysqlMetricCache.Get(getMySQLMetricCacheKey(probe)); found {
+		mySQLThrottleMetrics, _ := metrics.(MySQLThrottleMetrics)
+		return mySQLThrottleMetrics
 	}
 	return nil
 }
@@ -108,6 +114,7 @@ func GetMetricsQueryType(query string) MetricsQueryType {
 
 // MySQLThrottleMetric has the probed metric for a tablet
 type MySQLThrottleMetric struct { // nolint:revive
+	Name        base.MetricName
 	ClusterName string
 	Alias       string
 	Value       float64
@@ -129,29 +136,70 @@ func (metric *MySQLThrottleMetric) Get() (float64, error) {
 	return metric.Value, metric.Err
 }
 
-// ReadThrottleMetric returns a metric for the given probe. Either by explicit query
-// or via SHOW REPLICA STATUS
-func ReadThrottleMetric(probe *Probe, clusterName string, overrideGetMetricFunc func() *MySQLThrottleMetric) (mySQLThrottleMetric *MySQLThrottleMetric) {
-	if mySQLThrottleMetric := getCachedMySQLThrottleMetric(probe); mySQLThrottleMetric != nil {
-		return mySQLThrottleMetric
+// MySQLThrottleMetrics has the probed metrics of a tablet, by metric name
+type MySQLThrottleMetrics map[base.MetricName]*MySQLThrottleMetric // nolint:revive
+
+// Err returns the first error found in the metrics, if any
+func (metrics MySQLThrottleMetrics) Err() error {
+	for _, metric := range metrics {
+		if metric.Err != nil {
+			return metric.Err
+		}
+	}
+	return nil
+}
+
+// AllErr returns true if there are no metrics, or all of them have an error, e.g. because the probed tablet
+// could not be reached
+func (metrics MySQLThrottleMetrics) AllErr() bool {
+	for _, metric := range metrics {
+		if metric.Err == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ReadThrottleMetrics returns the metrics for the given probe, as read by the given function.
+func ReadThrottleMetrics(probe *Probe, clusterName string, overrideGetMetricsFunc func() MySQLThrottleMetrics) (mySQLThrottleMetrics MySQLThrottleMetrics) {
+	if mySQLThrottleMetrics := getCachedMySQLThrottleMetrics(probe); mySQLThrottleMetrics != nil {
+		return mySQLThrottleMetrics
 		// On cached results we avoid taking latency metrics
 	}
 
 	started := time.Now()
-	mySQLThrottleMetric = NewMySQLThrottleMetric()
-	mySQLThrottleMetric.ClusterName = clusterName
-	mySQLThrottleMetric.Alias = probe.Alias
-
-	defer func(metric *MySQLThrottleMetric, started time.Time) {
-		go func() {
-			stats.GetOrNewGauge("ThrottlerProbesLatency", "probes latency").Set(time.Since(started).Nanoseconds())
-			stats.GetOrNewCounter("ThrottlerProbesTotal", "total probes").Add(1)
-			if metric.Err != nil {
-				stats.GetOrNewCounter("ThrottlerProbesError", "total probes errors").Add(1)
-			}
-		}()
-	}(mySQLThrottleMetric, started)
-
-	mySQLThrottleMetric = overrideGetMetricFunc()
-	return cacheMySQLThrottleMetric(probe, mySQLThrottleMetric)
+	mySQLThrottleMetrics = overrideGetMetricsFunc()
+	for name, metric := range mySQLThrottleMetrics {
+		metric.Name = name
+		metric.ClusterName = clusterName
+		metric.Alias = probe.Alias
+	}
+
+	var failedMetricNames []base.MetricName
+	for name, metric := range mySQLThrottleMetrics {
+		if metric.Err != nil {
+			failedMetricNames = append(failedMetricNames, name)
+		}
+	}
+	go func(allErr bool) {
+		stats.GetOrNewGauge("ThrottlerProbesLatency", "probes latency").Set(time.Since(started).Nanoseconds())
+		stats.GetOrNewCounter("ThrottlerProbesTotal", "total probes").Add(1)
+		if allErr {
+			stats.GetOrNewCounter("ThrottlerProbesError", "total probes errors").Add(1)
+		}
+		for _, name := range failedMetricNames {
+			stats.GetOrNewCounter(fmt.Sprintf("ThrottlerProbes%sError", metricStatsName(name)), fmt.Sprintf("total probe errors of the %s metric", name)).Add(1)
+		}
+	}(mySQLThrottleMetrics.AllErr())
+
+	return cacheMySQLThrottleMetrics(probe, mySQLThrottleMetrics)
+}
+
+// metricStatsName returns the metric name in camel case, e.g. HistoryListLength for history_list_length
+func metricStatsName(metricName base.MetricName) string {
+	var name string
+	for _, word := range strings.Split(metricName.String(), "_") {
+		name += textutil.SingleWordCamel(word)
+	}
+	return name
 }
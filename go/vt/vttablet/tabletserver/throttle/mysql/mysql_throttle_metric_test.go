@@ -0,0 +1,69 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mysql
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+)
+
+func TestReadThrottleMetricsCache(t *testing.T) {
+	reads := 0
+	readFunc := func(loadAvgErr, lagErr error) func() MySQLThrottleMetrics {
+		return func() MySQLThrottleMetrics {
+			reads++
+			return MySQLThrottleMetrics{
+				base.LagMetricName:     &MySQLThrottleMetric{Value: 1.5, Err: lagErr},
+				base.LoadAvgMetricName: &MySQLThrottleMetric{Err: loadAvgErr},
+			}
+		}
+	}
+	failed := errors.New("failed")
+
+	// A metric that fails on its own does not prevent the others from being cached.
+	probe := &Probe{Alias: "zone1-0000000100", CacheMillis: 60000}
+	metrics := ReadThrottleMetrics(probe, "cluster", readFunc(failed, nil))
+	assert.Equal(t, 1, reads)
+	assert.Equal(t, base.LagMetricName, metrics[base.LagMetricName].Name)
+	assert.Equal(t, "cluster", metrics[base.LagMetricName].ClusterName)
+	metrics = ReadThrottleMetrics(probe, "cluster", readFunc(failed, nil))
+	assert.Equal(t, 1, reads)
+	value, err := metrics[base.LagMetricName].Get()
+	require.NoError(t, err)
+	assert.Equal(t, 1.5, value)
+	_, err = metrics[base.LoadAvgMetricName].Get()
+	assert.ErrorIs(t, err, failed)
+
+	// A probe that failed altogether is retried.
+	probe = &Probe{Alias: "zone1-0000000101", CacheMillis: 60000}
+	ReadThrottleMetrics(probe, "cluster", readFunc(failed, failed))
+	ReadThrottleMetrics(probe, "cluster", readFunc(failed, failed))
+	assert.Equal(t, 3, reads)
+	ReadThrottleMetrics(probe, "cluster", readFunc(nil, nil))
+	ReadThrottleMetrics(probe, "cluster", readFunc(nil, nil))
+	assert.Equal(t, 4, reads)
+}
+
+func TestMetricStatsName(t *testing.T) {
+	assert.Equal(t, "Lag", metricStatsName(base.LagMetricName))
+	assert.Equal(t, "HistoryListLength", metricStatsName(base.HistoryListLengthMetricName))
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024 The Vitess Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package throttle
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/mysql"
+)
+
+const (
+	threadsRunningQuery    = "show global status like 'threads_running'"
+	historyListLengthQuery = "select count as history_len from information_schema.INNODB_METRICS where name = 'trx_rseg_history_len'"
+	loadAvgFile            = "/proc/loadavg"
+)
+
+func (throttler *Throttler) generateSelfMySQLThrottleMetricFunc(ctx context.Context, probe *mysql.Probe) func() mysql.MySQLThrottleMetrics {
+	f := func() mysql.MySQLThrottleMetrics {
+		return throttler.readSelfThrottleMetrics(ctx, probe)
+	}
+	return f
+}
+
+// readSelfMySQLThrottleMetrics reads all collected metrics from this very tablet's backend mysql and from vttablet's host.
+// The custom metric is read with the probe's query, and only when the throttler is configured with a custom query.
+func (throttler *Throttler) readSelfMySQLThrottleMetrics(ctx context.Context, probe *mysql.Probe) mysql.MySQLThrottleMetrics {
+	metricNames := throttler.collectedMetricNames()
+	metrics := make(mysql.MySQLThrottleMetrics, len(metricNames))
+	for _, metricName := range metricNames {
+		metrics[metricName] = mysql.NewMySQLThrottleMetric()
+	}
+
+	conn, err := throttler.pool.Get(ctx, nil)
+	if err != nil {
+		for _, metric := range metrics {
+			metric.Err = err
+		}
+		return metrics
+	}
+	defer conn.Recycle()
+
+	for metricName, metric := range metrics {
+		switch metricName {
+		case base.LagMetricName:
+			metric.Value, metric.Err = readSelfMetricQuery(ctx, conn, replicationLagQuery())
+		case base.ThreadsRunningMetricName:
+			metric.Value, metric.Err = readSelfMetricQuery(ctx, conn, threadsRunningQuery)
+		case base.HistoryListLengthMetricName:
+			metric.Value, metric.Err = readSelfMetricQuery(ctx, conn, historyListLengthQuery)
+		case base.LoadAvgMetricName:
+			metric.Value, metric.Err = readLoadAvgPerCore()
+		case base.CustomMetricName:
+			metric.Value, metric.Err = readSelfMetricQuery(ctx, conn, probe.MetricQuery)
+		}
+	}
+	return metrics
+}
+
+// readSelfMetricQuery runs a metric query on the backend mysql and returns its single value.
+func readSelfMetricQuery(ctx context.Context, conn *connpool.PooledConn, query string) (float64, error) {
+	tm, err := conn.Conn.Exec(ctx, query, 1, true)
+	if err != nil {
+		return 0, err
+	}
+	row := tm.Named().Row()
+	if row == nil {
+		return 0, fmt.Errorf("no results for metric query: %s", query)
+	}
+
+	switch mysql.GetMetricsQueryType(query) {
+	case mysql.MetricsQueryTypeSelect:
+		// We expect a single row, single column result.
+		// The "for" iteration below is just a way to get first result without knowing column name
+		for k := range row {
+			return row.ToFloat64(k)
+		}
+		return 0, fmt.Errorf("no columns for metric query: %s", query)
+	case mysql.MetricsQueryTypeShowGlobal:
+		return strconv.ParseFloat(row["Value"].ToString(), 64)
+	default:
+		return 0, fmt.Errorf("Unsupported metrics query type for query: %s", query)
+	}
+}
+
+// readLoadAvgPerCore reads the 1 minute load average of the host vttablet runs on, divided by its number of CPU cores.
+// This is the load of MySQL's host only if MySQL runs on the same host as vttablet.
+func readLoadAvgPerCore() (float64, error) {
+	content, err := os.ReadFile(loadAvgFile)
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(string(content))
+	if len(fields) == 0 {
+		return 0, fmt.Errorf("unexpected content in %s: %s", loadAvgFile, content)
+	}
+	loadAvg, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return loadAvg / float64(runtime.NumCPU()), nil
+}
@@ -48,7 +48,6 @@ import (
 	"math"
 	"math/rand/v2"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -181,7 +180,7 @@ type Throttler struct {
 
 	throttleTabletTypesMap map[topodatapb.TabletType]bool
 
-	mysqlThrottleMetricChan chan *mysql.MySQLThrottleMetric
+	mysqlThrottleMetricChan chan mysql.MySQLThrottleMetrics
 	mysqlInventoryChan      chan *mysql.Inventory
 	mysqlClusterProbesChan  chan *mysql.ClusterProbes
 	throttlerConfigChan     chan *topodatapb.ThrottlerConfig
@@ -193,6 +192,8 @@ type Throttler struct {
 	checkAsCheckSelf atomic.Bool
 
 	mysqlClusterThresholds *cache.Cache
+	metricThresholds       *cache.Cache
+	appCheckedMetrics      *cache.Cache
 	aggregatedMetrics      *cache.Cache
 	throttledApps          *cache.Cache
 	recentApps             *cache.Cache
@@ -204,7 +205,7 @@ type Throttler struct {
 	cancelEnableContext context.CancelFunc
 	throttledAppsMutex  sync.Mutex
 
-	readSelfThrottleMetric func(context.Context, *mysql.Probe) *mysql.MySQLThrottleMetric // overwritten by unit test
+	readSelfThrottleMetrics func(context.Context, *mysql.Probe) mysql.MySQLThrottleMetrics // overwritten by unit test
 
 	httpClient *http.Client
 }
@@ -223,6 +224,9 @@ type ThrottlerStatus struct {
 	Query     string
 	Threshold float64
 
+	MetricThresholds  map[string]float64
+	AppCheckedMetrics map[string]string
+
 	AggregatedMetrics map[string]base.MetricResult
 	MetricsHealth     base.MetricHealthMap
 }
@@ -242,7 +246,7 @@ func NewThrottler(env tabletenv.Env, srvTopoServer srvtopo.Server, ts *topo.Serv
 		}),
 	}
 
-	throttler.mysqlThrottleMetricChan = make(chan *mysql.MySQLThrottleMetric)
+	throttler.mysqlThrottleMetricChan = make(chan mysql.MySQLThrottleMetrics)
 	throttler.mysqlInventoryChan = make(chan *mysql.Inventory, 1)
 	throttler.mysqlClusterProbesChan = make(chan *mysql.ClusterProbes)
 	throttler.throttlerConfigChan = make(chan *topodatapb.ThrottlerConfig)
@@ -250,6 +254,8 @@ func NewThrottler(env tabletenv.Env, srvTopoServer srvtopo.Server, ts *topo.Serv
 
 	throttler.throttledApps = cache.New(cache.NoExpiration, 0)
 	throttler.mysqlClusterThresholds = cache.New(cache.NoExpiration, 0)
+	throttler.metricThresholds = cache.New(cache.NoExpiration, 0)
+	throttler.appCheckedMetrics = cache.New(cache.NoExpiration, 0)
 	throttler.aggregatedMetrics = cache.New(aggregatedMetricsExpiration, 0)
 	throttler.recentApps = cache.New(recentAppsExpiration, 0)
 	throttler.metricsHealth = cache.New(cache.NoExpiration, 0)
@@ -272,8 +278,8 @@ func NewThrottler(env tabletenv.Env, srvTopoServer srvtopo.Server, ts *topo.Serv
 	}
 
 	throttler.StoreMetricsThreshold(defaultThrottleLagThreshold.Seconds()) //default
-	throttler.readSelfThrottleMetric = func(ctx context.Context, p *mysql.Probe) *mysql.MySQLThrottleMetric {
-		return throttler.readSelfMySQLThrottleMetric(ctx, p)
+	throttler.readSelfThrottleMetrics = func(ctx context.Context, p *mysql.Probe) mysql.MySQLThrottleMetrics {
+		return throttler.readSelfMySQLThrottleMetrics(ctx, p)
 	}
 
 	return throttler
@@ -313,6 +319,102 @@ func (throttler *Throttler) GetMetricsThreshold() float64 {
 	return math.Float64frombits(throttler.MetricsThreshold.Load())
 }
 
+// replicationLagQuery returns the query reading replication lag off the heartbeat table.
+// The query needs to be dynamically built because the sidecar database name is not known when
+// the TabletServer is created, which in turn creates the Throttler.
+func replicationLagQuery() string {
+	return sqlparser.BuildParsedQuery(defaultReplicationLagQuery, sidecar.GetIdentifier()).Query
+}
+
+// hasCustomMetric returns true when the throttler is configured with a custom metrics query
+func (throttler *Throttler) hasCustomMetric() bool {
+	return throttler.GetMetricsQuery() != replicationLagQuery()
+}
+
+// defaultMetricName resolves the "default" metric: the custom metric if there is a custom query, or else replication lag
+func (throttler *Throttler) defaultMetricName() base.MetricName {
+	if throttler.hasCustomMetric() {
+		return base.CustomMetricName
+	}
+	return base.LagMetricName
+}
+
+// collectedMetricNames returns the names of the metrics this throttler collects, the default metric first.
+// The custom metric is only collected when the throttler is configured with a custom query.
+func (throttler *Throttler) collectedMetricNames() base.MetricNames {
+	defaultMetricName := throttler.defaultMetricName()
+	metricNames := base.MetricNames{defaultMetricName}
+	for _, metricName := range base.KnownMetricNames {
+		if metricName == defaultMetricName {
+			continue
+		}
+		if metricName == base.CustomMetricName && !throttler.hasCustomMetric() {
+			continue
+		}
+		metricNames = append(metricNames, metricName)
+	}
+	return metricNames
+}
+
+// metricThreshold returns the threshold of the given metric: an explicitly configured threshold if there is one,
+// or else the general threshold for the default metric, or else the metric's built-in threshold.
+func (throttler *Throttler) metricThreshold(metricName base.MetricName, defaultMetricThreshold float64) float64 {
+	if thresholdVal, found := throttler.metricThresholds.Get(metricName.String()); found {
+		threshold, _ := thresholdVal.(float64)
+		return threshold
+	}
+	if metricName == throttler.defaultMetricName() {
+		return defaultMetricThreshold
+	}
+	if metricName == base.LagMetricName {
+		return defaultThrottleLagThreshold.Seconds()
+	}
+	return metricName.DefaultThreshold()
+}
+
+// checkedMetricNames returns the names of the metrics the given app is checked against. These are the metrics configured
+// for the app, or for any of its ':' separated tokens, or else those configured for "all" apps, or else the default metric.
+// The "vitess" app, used by the throttler itself, is checked against all collected metrics.
+func (throttler *Throttler) checkedMetricNames(appName string) base.MetricNames {
+	if throttlerapp.VitessName.Equals(appName) {
+		return throttler.collectedMetricNames()
+	}
+	getMetricNames := func(appName string) (base.MetricNames, bool) {
+		if metricNamesVal, found := throttler.appCheckedMetrics.Get(appName); found {
+			metricNames, _ := metricNamesVal.(base.MetricNames)
+			return metricNames, len(metricNames) > 0
+		}
+		return nil, false
+	}
+	metricNames, found := getMetricNames(appName)
+	if !found {
+		for _, singleAppName := range strings.Split(appName, ":") {
+			if singleAppName == "" {
+				continue
+			}
+			if metricNames, found = getMetricNames(singleAppName); found {
+				break
+			}
+		}
+	}
+	if !found {
+		metricNames, found = getMetricNames(throttlerapp.AllName.String())
+	}
+	if !found {
+		metricNames = base.MetricNames{base.DefaultMetricName}
+	}
+	resolvedMetricNames := make(base.MetricNames, 0, len(metricNames))
+	for _, metricName := range metricNames {
+		if metricName == base.DefaultMetricName {
+			metricName = throttler.defaultMetricName()
+		}
+		if !resolvedMetricNames.Contains(metricName) {
+			resolvedMetricNames = append(resolvedMetricNames, metricName)
+		}
+	}
+	return resolvedMetricNames
+}
+
 // initThrottler initializes config
 func (throttler *Throttler) initConfig() {
 	log.Infof("Throttler: initializing config")
@@ -394,11 +496,32 @@ func (throttler *Throttler) WatchSrvKeyspaceCallback(srvks *topodatapb.SrvKeyspa
 func (throttler *Throttler) applyThrottlerConfig(ctx context.Context, throttlerConfig *topodatapb.ThrottlerConfig) {
 	log.Infof("Throttler: applying topo config: %+v", throttlerConfig)
 	if throttlerConfig.CustomQuery == "" {
-		throttler.metricsQuery.Store(sqlparser.BuildParsedQuery(defaultReplicationLagQuery, sidecar.GetIdentifier()).Query)
+		throttler.metricsQuery.Store(replicationLagQuery())
 	} else {
 		throttler.metricsQuery.Store(throttlerConfig.CustomQuery)
 	}
 	throttler.StoreMetricsThreshold(throttlerConfig.Threshold)
+	for metricName, threshold := range throttlerConfig.MetricThresholds {
+		throttler.metricThresholds.Set(metricName, threshold, cache.DefaultExpiration)
+	}
+	for metricName := range throttler.metricThresholds.Items() {
+		if _, ok := throttlerConfig.MetricThresholds[metricName]; !ok {
+			throttler.metricThresholds.Delete(metricName)
+		}
+	}
+	for appName, appMetricNames := range throttlerConfig.AppCheckedMetrics {
+		metricNames, err := base.ParseMetricNames(appMetricNames.GetNames())
+		if err != nil {
+			log.Errorf("Throttler: invalid checked metrics for app %s: %v", appName, err)
+			continue
+		}
+		throttler.appCheckedMetrics.Set(appName, metricNames, cache.DefaultExpiration)
+	}
+	for appName := range throttler.appCheckedMetrics.Items() {
+		if _, ok := throttlerConfig.AppCheckedMetrics[appName]; !ok {
+			throttler.appCheckedMetrics.Delete(appName)
+		}
+	}
 	throttler.checkAsCheckSelf.Store(throttlerConfig.CheckAsCheckSelf)
 	for _, appRule := range throttlerConfig.ThrottledApps {
 		throttler.ThrottleApp(appRule.Name, protoutil.TimeFromProto(appRule.ExpiresAt).UTC(), appRule.Ratio, appRule.Exempt)
@@ -538,10 +661,7 @@ func (throttler *Throttler) Open() error {
 	log.Infof("Throttler: opening")
 	var ctx context.Context
 	ctx, throttler.cancelOpenContext = context.WithCancel(context.Background())
-	// The query needs to be dynamically built because the sidecar database name
-	// is not known when the TabletServer is created, which in turn creates the
-	// Throttler.
-	throttler.metricsQuery.Store(sqlparser.BuildParsedQuery(defaultReplicationLagQuery, sidecar.GetIdentifier()).Query) // default
+	throttler.metricsQuery.Store(replicationLagQuery()) // default
 	throttler.initConfig()
 	throttler.pool.Open(throttler.env.Config().DB.AppWithDB(), throttler.env.Config().DB.DbaWithDB(), throttler.env.Config().DB.AppDebugWithDB())
 
@@ -617,56 +737,6 @@ func (throttler *Throttler) stimulatePrimaryThrottler(ctx context.Context, tmCli
 	return nil
 }
 
-func (throttler *Throttler) generateSelfMySQLThrottleMetricFunc(ctx context.Context, probe *mysql.Probe) func() *mysql.MySQLThrottleMetric {
-	f := func() *mysql.MySQLThrottleMetric {
-		return throttler.readSelfThrottleMetric(ctx, probe)
-	}
-	return f
-}
-
-// readSelfMySQLThrottleMetric reads the mysql metric from thi very tablet's backend mysql.
-func (throttler *Throttler) readSelfMySQLThrottleMetric(ctx context.Context, probe *mysql.Probe) *mysql.MySQLThrottleMetric {
-	metric := &mysql.MySQLThrottleMetric{
-		ClusterName: selfStoreName,
-		Alias:       "",
-		Value:       0,
-		Err:         nil,
-	}
-	conn, err := throttler.pool.Get(ctx, nil)
-	if err != nil {
-		metric.Err = err
-		return metric
-	}
-	defer conn.Recycle()
-
-	tm, err := conn.Conn.Exec(ctx, probe.MetricQuery, 1, true)
-	if err != nil {
-		metric.Err = err
-		return metric
-	}
-	row := tm.Named().Row()
-	if row == nil {
-		metric.Err = fmt.Errorf("no results for readSelfMySQLThrottleMetric")
-		return metric
-	}
-
-	metricsQueryType := mysql.GetMetricsQueryType(throttler.GetMetricsQuery())
-	switch metricsQueryType {
-	case mysql.MetricsQueryTypeSelect:
-		// We expect a single row, single column result.
-		// The "for" iteration below is just a way to get first result without knowing column name
-		for k := range row {
-			metric.Value, metric.Err = row.ToFloat64(k)
-		}
-	case mysql.MetricsQueryTypeShowGlobal:
-		metric.Value, metric.Err = strconv.ParseFloat(row["Value"].ToString(), 64)
-	default:
-		metric.Err = fmt.Errorf("Unsupported metrics query type for query: %s", throttler.GetMetricsQuery())
-	}
-
-	return metric
-}
-
 // throttledAppsSnapshot returns a snapshot (a copy) of current throttled apps
 func (throttler *Throttler) throttledAppsSnapshot() map[string]cache.Item {
 	return throttler.throttledApps.Items()
@@ -815,9 +885,9 @@ func (throttler *Throttler) Operate(ctx context.Context, wg *sync.WaitGroup) {
 						})
 					}
 				}
-			case metric := <-throttler.mysqlThrottleMetricChan:
-				// incoming MySQL metric, frequent, as result of collectMySQLMetrics()
-				throttler.mysqlInventory.TabletMetrics[metric.GetClusterTablet()] = metric
+			case metrics := <-throttler.mysqlThrottleMetricChan:
+				// incoming MySQL metrics, frequent, as result of collectMySQLMetrics()
+				throttler.mysqlInventory.AddTabletMetrics(metrics)
 			case <-mysqlRefreshTicker.C:
 				// sparse
 				if throttler.IsOpen() {
@@ -841,30 +911,48 @@ func (throttler *Throttler) Operate(ctx context.Context, wg *sync.WaitGroup) {
 	}()
 }
 
-func (throttler *Throttler) generateTabletProbeFunction(ctx context.Context, clusterName string, tmClient tmclient.TabletManagerClient, probe *mysql.Probe) (probeFunc func() *mysql.MySQLThrottleMetric) {
-	return func() *mysql.MySQLThrottleMetric {
+func (throttler *Throttler) generateTabletProbeFunction(ctx context.Context, clusterName string, tmClient tmclient.TabletManagerClient, probe *mysql.Probe) (probeFunc func() mysql.MySQLThrottleMetrics) {
+	return func() mysql.MySQLThrottleMetrics {
 		// Some reasonable timeout, to ensure we release connections even if they're hanging (otherwise grpc-go keeps polling those connections forever)
 		ctx, cancel := context.WithTimeout(ctx, 4*mysqlCollectInterval)
 		defer cancel()
 
-		// Hit a tablet's `check-self` via HTTP, and convert its CheckResult JSON output into a MySQLThrottleMetric
-		mySQLThrottleMetric := mysql.NewMySQLThrottleMetric()
-		mySQLThrottleMetric.ClusterName = clusterName
-		mySQLThrottleMetric.Alias = probe.Alias
+		// Hit a tablet's `check-self` via gRPC, and convert its CheckThrottlerResponse into MySQLThrottleMetrics
+		errorMetrics := func(err error) mysql.MySQLThrottleMetrics {
+			metrics := make(mysql.MySQLThrottleMetrics)
+			for _, metricName := range throttler.collectedMetricNames() {
+				metric := mysql.NewMySQLThrottleMetric()
+				metric.Err = err
+				metrics[metricName] = metric
+			}
+			return metrics
+		}
 
 		if probe.Tablet == nil {
-			mySQLThrottleMetric.Err = fmt.Errorf("found nil tablet reference for alias %v, hostname %v", probe.Alias, probe.Tablet.Hostname)
-			return mySQLThrottleMetric
+			return errorMetrics(fmt.Errorf("found nil tablet reference for alias %v", probe.Alias))
 		}
 		req := &tabletmanagerdatapb.CheckThrottlerRequest{} // We leave AppName empty; it will default to VitessName anyway, and we can save some proto space
 		resp, gRPCErr := tmClient.CheckThrottler(ctx, probe.Tablet, req)
 		if gRPCErr != nil {
-			mySQLThrottleMetric.Err = fmt.Errorf("gRPC error accessing tablet %v. Err=%v", probe.Alias, gRPCErr)
-			return mySQLThrottleMetric
+			return errorMetrics(fmt.Errorf("gRPC error accessing tablet %v. Err=%v", probe.Alias, gRPCErr))
+		}
+		metrics := make(mysql.MySQLThrottleMetrics)
+		for metricName, respMetric := range resp.Metrics {
+			metric := mysql.NewMySQLThrottleMetric()
+			metric.Value = respMetric.Value
+			if respMetric.StatusCode == http.StatusInternalServerError {
+				metric.Err = fmt.Errorf("Status code: %d", respMetric.StatusCode)
+			}
+			metrics[base.MetricName(metricName)] = metric
 		}
-		mySQLThrottleMetric.Value = resp.Value
-		if resp.StatusCode == http.StatusInternalServerError {
-			mySQLThrottleMetric.Err = fmt.Errorf("Status code: %d", resp.StatusCode)
+		if len(metrics) == 0 {
+			// The tablet does not report multiple metrics, and only reports the value of its default metric.
+			metric := mysql.NewMySQLThrottleMetric()
+			metric.Value = resp.Value
+			if resp.StatusCode == http.StatusInternalServerError {
+				metric.Err = fmt.Errorf("Status code: %d", resp.StatusCode)
+			}
+			metrics[throttler.defaultMetricName()] = metric
 		}
 		if resp.RecentlyChecked {
 			// We have just probed a tablet, and it reported back that someone just recently "check"ed it.
@@ -872,7 +960,7 @@ func (throttler *Throttler) generateTabletProbeFunction(ctx context.Context, clu
 			throttler.requestHeartbeats()
 			statsThrottlerProbeRecentlyChecked.Add(1)
 		}
-		return mySQLThrottleMetric
+		return metrics
 	}
 }
 
@@ -894,15 +982,15 @@ func (throttler *Throttler) collectMySQLMetrics(ctx context.Context, tmClient tm
 				}
 				defer atomic.StoreInt64(&probe.QueryInProgress, 0)
 
-				var throttleMetricFunc func() *mysql.MySQLThrottleMetric
+				var throttleMetricsFunc func() mysql.MySQLThrottleMetrics
 				if clusterName == selfStoreName {
 					// Throttler is probing its own tablet's metrics:
-					throttleMetricFunc = throttler.generateSelfMySQLThrottleMetricFunc(ctx, probe)
+					throttleMetricsFunc = throttler.generateSelfMySQLThrottleMetricFunc(ctx, probe)
 				} else {
 					// Throttler probing other tablets:
-					throttleMetricFunc = throttler.generateTabletProbeFunction(ctx, clusterName, tmClient, probe)
+					throttleMetricsFunc = throttler.generateTabletProbeFunction(ctx, clusterName, tmClient, probe)
 				}
-				throttleMetrics := mysql.ReadThrottleMetric(probe, clusterName, throttleMetricFunc)
+				throttleMetrics := mysql.ReadThrottleMetrics(probe, clusterName, throttleMetricsFunc)
 				select {
 				case <-ctx.Done():
 					return
@@ -1026,12 +1114,19 @@ func (throttler *Throttler) updateMySQLClusterProbes(ctx context.Context, cluste
 
 // synchronous aggregation of collected data
 func (throttler *Throttler) aggregateMySQLMetrics(ctx context.Context) error {
+	metricNames := throttler.collectedMetricNames()
 	for clusterName, probes := range throttler.mysqlInventory.ClustersProbes {
-		metricName := fmt.Sprintf("mysql/%s", clusterName)
 		ignoreHostsCount := throttler.mysqlInventory.IgnoreHostsCount[clusterName]
 		ignoreHostsThreshold := throttler.mysqlInventory.IgnoreHostsThreshold[clusterName]
-		aggregatedMetric := aggregateMySQLProbes(ctx, probes, clusterName, throttler.mysqlInventory.TabletMetrics, ignoreHostsCount, throttler.configSettings.Stores.MySQL.IgnoreDialTCPErrors, ignoreHostsThreshold)
-		throttler.aggregatedMetrics.Set(metricName, aggregatedMetric, cache.DefaultExpiration)
+		for _, metricName := range metricNames {
+			tabletResultsMap, ok := throttler.mysqlInventory.TabletMetrics[metricName]
+			if !ok {
+				// metric not collected yet
+				continue
+			}
+			aggregatedMetric := aggregateMySQLProbes(ctx, probes, clusterName, tabletResultsMap, ignoreHostsCount, throttler.configSettings.Stores.MySQL.IgnoreDialTCPErrors, ignoreHostsThreshold)
+			throttler.aggregatedMetrics.Set(metricName.AggregatedName(clusterName), aggregatedMetric, cache.DefaultExpiration)
+		}
 	}
 	return nil
 }
@@ -1043,11 +1138,10 @@ func (throttler *Throttler) getNamedMetric(metricName string) base.MetricResult
 	return base.NoSuchMetric
 }
 
-func (throttler *Throttler) getMySQLClusterMetrics(ctx context.Context, clusterName string) (base.MetricResult, float64) {
+func (throttler *Throttler) getMySQLClusterMetrics(ctx context.Context, clusterName string, metricName base.MetricName) (base.MetricResult, float64) {
 	if thresholdVal, found := throttler.mysqlClusterThresholds.Get(clusterName); found {
 		threshold, _ := thresholdVal.(float64)
-		metricName := fmt.Sprintf("mysql/%s", clusterName)
-		return throttler.getNamedMetric(metricName), threshold
+		return throttler.getNamedMetric(metricName.AggregatedName(clusterName)), throttler.metricThreshold(metricName, threshold)
 	}
 
 	return base.NoSuchMetric, 0
@@ -1224,6 +1318,25 @@ func (throttler *Throttler) timeSinceMetricHealthy(metricName string) (timeSince
 	return 0, false
 }
 
+// metricThresholdsSnapshot returns the thresholds of the collected metrics
+func (throttler *Throttler) metricThresholdsSnapshot() map[string]float64 {
+	snapshot := make(map[string]float64)
+	for _, metricName := range throttler.collectedMetricNames() {
+		snapshot[metricName.String()] = throttler.metricThreshold(metricName, throttler.GetMetricsThreshold())
+	}
+	return snapshot
+}
+
+// appCheckedMetricsSnapshot returns the metrics configured per app, as comma separated lists
+func (throttler *Throttler) appCheckedMetricsSnapshot() map[string]string {
+	snapshot := make(map[string]string)
+	for appName, item := range throttler.appCheckedMetrics.Items() {
+		metricNames, _ := item.Object.(base.MetricNames)
+		snapshot[appName] = metricNames.String()
+	}
+	return snapshot
+}
+
 func (throttler *Throttler) metricsHealthSnapshot() base.MetricHealthMap {
 	snapshot := make(base.MetricHealthMap)
 	for key, value := range throttler.metricsHealth.Items() {
@@ -1233,14 +1346,12 @@ func (throttler *Throttler) metricsHealthSnapshot() base.MetricHealthMap {
 	return snapshot
 }
 
-// AppRequestMetricResult gets a metric result in the context of a specific app
+// AppRequestMetricResult gets a metric result in the context of a specific app. The caller is expected
+// to evaluate whether the app is throttled, once for all checked metrics, and pass it as denyApp.
 func (throttler *Throttler) AppRequestMetricResult(ctx context.Context, appName string, metricResultFunc base.MetricResultFunc, denyApp bool) (metricResult base.MetricResult, threshold float64) {
 	if denyApp {
 		return base.AppDeniedMetric, 0
 	}
-	if throttler.IsAppThrottled(appName) {
-		return base.AppDeniedMetric, 0
-	}
 	return metricResultFunc()
 }
 
@@ -1255,7 +1366,7 @@ func (throttler *Throttler) checkStore(ctx context.Context, appName string, stor
 		return okMetricCheckResult
 	}
 
-	checkResult = throttler.check.Check(ctx, appName, "mysql", storeName, remoteAddr, flags)
+	checkResult = throttler.check.Check(ctx, appName, "mysql", storeName, throttler.checkedMetricNames(appName), remoteAddr, flags)
 
 	shouldRequestHeartbeats := !flags.SkipRequestHeartbeats
 	if throttlerapp.VitessName.Equals(appName) {
@@ -1288,7 +1399,7 @@ func (throttler *Throttler) checkShard(ctx context.Context, appName string, remo
 	return throttler.checkStore(ctx, appName, shardStoreName, remoteAddr, flags)
 }
 
-// CheckSelf is checks the mysql/self metric, and is available on each tablet
+// CheckSelf is checks the mysql/self metrics, and is available on each tablet
 func (throttler *Throttler) checkSelf(ctx context.Context, appName string, remoteAddr string, flags *CheckFlags) (checkResult *CheckResult) {
 	return throttler.checkStore(ctx, appName, selfStoreName, remoteAddr, flags)
 }
@@ -1323,6 +1434,9 @@ func (throttler *Throttler) Status() *ThrottlerStatus {
 		Query:     throttler.GetMetricsQuery(),
 		Threshold: throttler.GetMetricsThreshold(),
 
+		MetricThresholds:  throttler.metricThresholdsSnapshot(),
+		AppCheckedMetrics: throttler.appCheckedMetricsSnapshot(),
+
 		AggregatedMetrics: throttler.aggregatedMetricsSnapshot(),
 		MetricsHealth:     throttler.metricsHealthSnapshot(),
 	}
@@ -33,6 +33,7 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/base"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/config"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/mysql"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/throttle/throttlerapp"
@@ -146,7 +147,7 @@ func newTestThrottler() *Throttler {
 		overrideTmClient:       &fakeTMClient{},
 	}
 	throttler.configSettings = configSettings
-	throttler.mysqlThrottleMetricChan = make(chan *mysql.MySQLThrottleMetric)
+	throttler.mysqlThrottleMetricChan = make(chan mysql.MySQLThrottleMetrics)
 	throttler.mysqlInventoryChan = make(chan *mysql.Inventory, 1)
 	throttler.mysqlClusterProbesChan = make(chan *mysql.ClusterProbes)
 	throttler.throttlerConfigChan = make(chan *topodatapb.ThrottlerConfig)
@@ -154,6 +155,8 @@ func newTestThrottler() *Throttler {
 
 	throttler.throttledApps = cache.New(cache.NoExpiration, 0)
 	throttler.mysqlClusterThresholds = cache.New(cache.NoExpiration, 0)
+	throttler.metricThresholds = cache.New(cache.NoExpiration, 0)
+	throttler.appCheckedMetrics = cache.New(cache.NoExpiration, 0)
 	throttler.aggregatedMetrics = cache.New(10*aggregatedMetricsExpiration, 0)
 	throttler.recentApps = cache.New(recentAppsExpiration, 0)
 	throttler.metricsHealth = cache.New(cache.NoExpiration, 0)
@@ -172,12 +175,14 @@ func newTestThrottler() *Throttler {
 	throttler.recentCheckDormantDiff = int64(throttler.dormantPeriod / recentCheckRateLimiterInterval)
 	throttler.recentCheckDiff = int64(3 * time.Second / recentCheckRateLimiterInterval)
 
-	throttler.readSelfThrottleMetric = func(ctx context.Context, p *mysql.Probe) *mysql.MySQLThrottleMetric {
-		return &mysql.MySQLThrottleMetric{
-			ClusterName: selfStoreName,
-			Alias:       "",
-			Value:       1,
-			Err:         nil,
+	throttler.readSelfThrottleMetrics = func(ctx context.Context, p *mysql.Probe) mysql.MySQLThrottleMetrics {
+		return mysql.MySQLThrottleMetrics{
+			base.CustomMetricName: &mysql.MySQLThrottleMetric{
+				ClusterName: selfStoreName,
+				Alias:       "",
+				Value:       1,
+				Err:         nil,
+			},
 		}
 	}
 
@@ -417,17 +422,17 @@ func TestProbesWhileOperating(t *testing.T) {
 		t.Run("aggregated", func(t *testing.T) {
 			assert.Equal(t, 2, throttler.aggregatedMetrics.ItemCount()) // flushed upon Disable()
 			aggr := throttler.aggregatedMetricsSnapshot()
-			assert.Equal(t, 2, len(aggr)) // "self" and "shard" clusters
-			for clusterName, metricResult := range aggr {
+			assert.Equal(t, 2, len(aggr)) // "self" and "shard" clusters, "custom" metric
+			for aggregatedName, metricResult := range aggr {
 				val, err := metricResult.Get()
 				assert.NoError(t, err)
-				switch clusterName {
-				case "mysql/self":
+				switch aggregatedName {
+				case "mysql/self/custom":
 					assert.Equal(t, float64(1), val)
-				case "mysql/shard":
+				case "mysql/shard/custom":
 					assert.Equal(t, float64(0), val)
 				default:
-					assert.Failf(t, "unknown clusterName", "%v", clusterName)
+					assert.Failf(t, "unknown aggregatedName", "%v", aggregatedName)
 				}
 			}
 			assert.NotEmpty(t, tmClient.AppNames())
@@ -485,7 +490,8 @@ func TestProbesPostDisable(t *testing.T) {
 	})
 
 	t.Run("metrics", func(t *testing.T) {
-		assert.Equal(t, 3, len(throttler.mysqlInventory.TabletMetrics)) // 1 self tablet + 2 shard tablets
+		assert.Equal(t, 1, len(throttler.mysqlInventory.TabletMetrics))                        // "custom" metric only
+		assert.Equal(t, 3, len(throttler.mysqlInventory.TabletMetrics[base.CustomMetricName])) // 1 self tablet + 2 shard tablets
 	})
 
 	t.Run("aggregated", func(t *testing.T) {
@@ -607,3 +613,118 @@ func TestReplica(t *testing.T) {
 		}()
 	})
 }
+
+func TestCheckedMetricNames(t *testing.T) {
+	ctx := context.Background()
+	throttler := newTestThrottler()
+	throttler.metricsQuery.Store(replicationLagQuery())
+
+	collected := base.MetricNames{base.LagMetricName, base.ThreadsRunningMetricName, base.LoadAvgMetricName, base.HistoryListLengthMetricName}
+	assert.Equal(t, collected, throttler.collectedMetricNames())
+	assert.Equal(t, collected, throttler.checkedMetricNames(throttlerapp.VitessName.String()))
+	assert.Equal(t, base.MetricNames{base.LagMetricName}, throttler.checkedMetricNames(throttlerapp.OnlineDDLName.String()))
+
+	throttler.applyThrottlerConfig(ctx, &topodatapb.ThrottlerConfig{
+		Threshold: 5,
+		AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_MetricNames{
+			throttlerapp.OnlineDDLName.String(): {Names: []string{"lag", "history_list_length"}},
+			throttlerapp.AllName.String():       {Names: []string{"default", "threads_running", "lag"}},
+			"invalid-app":                       {Names: []string{"no_such_metric"}},
+		},
+	})
+	assert.Equal(t, collected, throttler.checkedMetricNames(throttlerapp.VitessName.String()))
+	assert.Equal(t, base.MetricNames{base.LagMetricName, base.HistoryListLengthMetricName}, throttler.checkedMetricNames(throttlerapp.OnlineDDLName.String()))
+	assert.Equal(t, base.MetricNames{base.LagMetricName, base.HistoryListLengthMetricName}, throttler.checkedMetricNames("online-ddl:some-uuid"))
+	assert.Equal(t, base.MetricNames{base.LagMetricName, base.ThreadsRunningMetricName}, throttler.checkedMetricNames(throttlerapp.VReplicationName.String()))
+	assert.Equal(t, base.MetricNames{base.LagMetricName, base.ThreadsRunningMetricName}, throttler.checkedMetricNames("invalid-app"))
+
+	throttler.applyThrottlerConfig(ctx, &topodatapb.ThrottlerConfig{
+		Threshold:   5,
+		CustomQuery: "show global status like 'threads_connected'",
+		AppCheckedMetrics: map[string]*topodatapb.ThrottlerConfig_MetricNames{
+			throttlerapp.AllName.String(): {Names: []string{"default", "threads_running"}},
+		},
+	})
+	assert.Equal(t, append(base.MetricNames{base.CustomMetricName}, collected...), throttler.collectedMetricNames())
+	assert.Equal(t, base.MetricNames{base.CustomMetricName, base.ThreadsRunningMetricName}, throttler.checkedMetricNames(throttlerapp.OnlineDDLName.String()))
+	assert.Equal(t, map[string]string{"all": "default,threads_running"}, throttler.appCheckedMetricsSnapshot())
+}
+
+func TestMetricStatsName(t *testing.T) {
+	throttler := newTestThrottler()
+	throttler.metricsQuery.Store(replicationLagQuery())
+
+	// The default metric keeps its name from before multiple metrics were supported.
+	assert.Equal(t, "MysqlSelf", throttler.check.metricStatsName("mysql", "self", base.LagMetricName))
+	assert.Equal(t, "MysqlSelfThreadsRunning", throttler.check.metricStatsName("mysql", "self", base.ThreadsRunningMetricName))
+
+	throttler.metricsQuery.Store("select 1")
+	assert.Equal(t, "MysqlShard", throttler.check.metricStatsName("mysql", "shard", base.CustomMetricName))
+	assert.Equal(t, "MysqlShardLag", throttler.check.metricStatsName("mysql", "shard", base.LagMetricName))
+}
+
+func TestMetricThresholds(t *testing.T) {
+	ctx := context.Background()
+	throttler := newTestThrottler()
+
+	throttler.applyThrottlerConfig(ctx, &topodatapb.ThrottlerConfig{Threshold: 2})
+	assert.Equal(t, 2.0, throttler.metricThreshold(base.LagMetricName, throttler.GetMetricsThreshold()))
+	assert.Equal(t, base.ThreadsRunningMetricName.DefaultThreshold(), throttler.metricThreshold(base.ThreadsRunningMetricName, throttler.GetMetricsThreshold()))
+
+	throttler.applyThrottlerConfig(ctx, &topodatapb.ThrottlerConfig{
+		Threshold:        2,
+		MetricThresholds: map[string]float64{"lag": 3, "threads_running": 50},
+	})
+	assert.Equal(t, 3.0, throttler.metricThreshold(base.LagMetricName, throttler.GetMetricsThreshold()))
+	assert.Equal(t, 50.0, throttler.metricThreshold(base.ThreadsRunningMetricName, throttler.GetMetricsThreshold()))
+
+	throttler.applyThrottlerConfig(ctx, &topodatapb.ThrottlerConfig{
+		Threshold:   2,
+		CustomQuery: "select 1",
+	})
+	assert.Equal(t, 2.0, throttler.metricThreshold(base.CustomMetricName, throttler.GetMetricsThreshold()))
+	assert.Equal(t, defaultThrottleLagThreshold.Seconds(), throttler.metricThreshold(base.LagMetricName, throttler.GetMetricsThreshold()))
+	assert.Equal(t, base.ThreadsRunningMetricName.DefaultThreshold(), throttler.metricThreshold(base.ThreadsRunningMetricName, throttler.GetMetricsThreshold()))
+	assert.Equal(t, 2.0, throttler.metricThresholdsSnapshot()["custom"])
+}
+
+func TestCheckMultipleMetrics(t *testing.T) {
+	ctx := context.Background()
+	throttler := newTestThrottler()
+	throttler.metricsQuery.Store(replicationLagQuery())
+	throttler.mysqlClusterThresholds.Set(selfStoreName, 5.0, cache.DefaultExpiration)
+	throttler.aggregatedMetrics.Set(base.LagMetricName.AggregatedName(selfStoreName), base.NewSimpleMetricResult(1), cache.DefaultExpiration)
+	throttler.aggregatedMetrics.Set(base.ThreadsRunningMetricName.AggregatedName(selfStoreName), base.NewSimpleMetricResult(150), cache.DefaultExpiration)
+	metricNames := base.MetricNames{base.LagMetricName, base.ThreadsRunningMetricName}
+
+	t.Run("app", func(t *testing.T) {
+		checkResult := throttler.check.Check(ctx, throttlerapp.OnlineDDLName.String(), "mysql", selfStoreName, metricNames, "", StandardCheckFlags)
+		assert.Equal(t, http.StatusTooManyRequests, checkResult.StatusCode)
+		assert.Equal(t, 150.0, checkResult.Value)
+		assert.Equal(t, base.ThreadsRunningMetricName.DefaultThreshold(), checkResult.Threshold)
+		require.Len(t, checkResult.Metrics, 2)
+		assert.Equal(t, http.StatusOK, checkResult.Metrics["lag"].StatusCode)
+		assert.Equal(t, 1.0, checkResult.Metrics["lag"].Value)
+		assert.Equal(t, 5.0, checkResult.Metrics["lag"].Threshold)
+		assert.Equal(t, http.StatusTooManyRequests, checkResult.Metrics["threads_running"].StatusCode)
+	})
+	t.Run("vitess", func(t *testing.T) {
+		checkResult := throttler.check.Check(ctx, throttlerapp.VitessName.String(), "mysql", selfStoreName, metricNames, "", StandardCheckFlags)
+		assert.Equal(t, http.StatusOK, checkResult.StatusCode)
+		assert.Equal(t, 1.0, checkResult.Value)
+		assert.Len(t, checkResult.Metrics, 2)
+	})
+	t.Run("throttled app", func(t *testing.T) {
+		throttler.ThrottleApp("test-app", time.Now().Add(time.Hour), DefaultThrottleRatio, false)
+		defer throttler.UnthrottleApp("test-app")
+		checkResult := throttler.check.Check(ctx, "test-app", "mysql", selfStoreName, metricNames, "", StandardCheckFlags)
+		assert.Equal(t, http.StatusExpectationFailed, checkResult.StatusCode)
+		for _, metricCheckResult := range checkResult.Metrics {
+			assert.Equal(t, http.StatusExpectationFailed, metricCheckResult.StatusCode)
+		}
+	})
+	t.Run("no such metric", func(t *testing.T) {
+		checkResult := throttler.check.Check(ctx, throttlerapp.OnlineDDLName.String(), "mysql", selfStoreName, base.MetricNames{base.LoadAvgMetricName}, "", StandardCheckFlags)
+		assert.Equal(t, http.StatusNotFound, checkResult.StatusCode)
+	})
+}
@@ -41,6 +41,8 @@ func (n Name) Concatenate(other Name) Name {
 }
 
 const (
+	// AllName is a special app name, which applies to all apps that do not have their own, specific, configuration
+	AllName Name = "all"
 	// DefaultName is the app name used by vitess when app doesn't indicate its name
 	DefaultName             Name = "default"
 	VitessName              Name = "vitess"
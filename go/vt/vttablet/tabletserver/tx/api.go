@@ -59,6 +59,9 @@ type (
 		LogToFile       bool
 
 		Stats *servenv.TimingsWrapper
+
+		// AdmissionDone releases the admission control slot of the transaction.
+		AdmissionDone func()
 	}
 )
 
@@ -25,12 +25,14 @@ import (
 	"vitess.io/vitess/go/pools/smartconnpool"
 	"vitess.io/vitess/go/timer"
 	"vitess.io/vitess/go/trace"
+	"vitess.io/vitess/go/vt/callerid"
 	"vitess.io/vitess/go/vt/concurrency"
 	"vitess.io/vitess/go/vt/dtids"
 	"vitess.io/vitess/go/vt/log"
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/vterrors"
 	"vitess.io/vitess/go/vt/vtgate/vtgateconn"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/admission"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/connpool"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
@@ -86,6 +88,9 @@ type TxEngine struct {
 	// reservedConnStats keeps statistics about reserved connections
 	reservedConnStats *servenv.TimingsWrapper
 
+	// admission is the workload class admission control of queries and transactions.
+	admission *admission.Controller
+
 	txPool       *TxPool
 	preparedPool *TxPreparedPool
 	twoPC        *TwoPC
@@ -101,7 +106,8 @@ func NewTxEngine(env tabletenv.Env) *TxEngine {
 		reservedConnStats:   env.Exporter().NewTimings("ReservedConnections", "Reserved connections stats", "operation"),
 	}
 	limiter := txlimiter.New(env)
-	te.txPool = NewTxPool(env, limiter)
+	te.admission = admission.New(env)
+	te.txPool = NewTxPool(env, limiter, te.admission)
 	te.twopcEnabled = config.TwoPCEnable
 	if te.twopcEnabled {
 		if config.TwoPCCoordinatorAddress == "" {
@@ -561,20 +567,36 @@ func (te *TxEngine) Reserve(ctx context.Context, options *querypb.ExecuteOptions
 	if err != nil {
 		return 0, err
 	}
+	// The connection stays reserved after the transaction ends, so the
+	// admission control slot of the transaction now belongs to the reservation.
+	conn.reservedProps.AdmissionDone, conn.txProps.AdmissionDone = conn.txProps.AdmissionDone, nil
 	return conn.ReservedID(), nil
 }
 
 // Reserve creates a reserved connection and returns the id to it
 func (te *TxEngine) reserve(ctx context.Context, options *querypb.ExecuteOptions, preQueries []string) (*StatefulConnection, error) {
+	// A reserved connection holds a connection of the transaction pool until it is released.
+	admissionDone, err := te.admission.Admit(ctx, admission.Transactions, &admission.Request{
+		WorkloadName:    options.GetWorkloadName(),
+		ImmediateCaller: callerid.ImmediateCallerIDFromContext(ctx),
+		EffectiveCaller: callerid.EffectiveCallerIDFromContext(ctx),
+	})
+	if err != nil {
+		return nil, err
+	}
+
 	conn, err := te.txPool.scp.NewConn(ctx, options, nil)
 	if err != nil {
+		admissionDone()
 		return nil, err
 	}
 
 	err = te.taintConn(ctx, conn, preQueries)
 	if err != nil {
+		admissionDone()
 		return nil, err
 	}
+	conn.reservedProps.AdmissionDone = admissionDone
 
 	return conn, err
 }
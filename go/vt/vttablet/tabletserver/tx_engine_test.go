@@ -603,3 +603,55 @@ func TestTxEngineFailReserve(t *testing.T) {
 	require.Error(t, err)
 	assert.Zero(t, connID)
 }
+
+func TestTxEngineReserveAdmissionControl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	db := setUpQueryExecutorTest(t)
+	defer db.Close()
+	db.AddQueryPattern(".*", &sqltypes.Result{})
+	cfg := tabletenv.NewDefaultConfig()
+	cfg.DB = newDBConfigs(db)
+	cfg.TxPool.Size = 2
+	cfg.AdmissionControl.Mode = tabletenv.Enable
+	cfg.AdmissionControl.Classes = []*tabletenv.AdmissionClassConfig{{
+		Name:          "batch",
+		MaxShare:      0.5,
+		QueueTimeout:  0.01,
+		WorkloadNames: []string{"batch"},
+	}}
+	te := NewTxEngine(tabletenv.NewEnv(vtenv.NewTestEnv(), cfg, "TabletServerTest"))
+	te.AcceptReadWrite()
+	defer te.Close()
+
+	// A reserved connection holds a slot of its class until it is released.
+	options := &querypb.ExecuteOptions{WorkloadName: "batch"}
+	connID, err := te.Reserve(ctx, options, 0, nil)
+	require.NoError(t, err)
+	_, err = te.Reserve(ctx, options, 0, nil)
+	require.ErrorContains(t, err, "admission control: workload class batch exceeded its queue timeout")
+	_, _, _, err = te.Begin(ctx, nil, 0, nil, options)
+	require.ErrorContains(t, err, "admission control: workload class batch exceeded its queue timeout")
+
+	// A transaction begun on the reserved connection uses its slot.
+	txID, _, _, err := te.Begin(ctx, nil, connID, nil, options)
+	require.NoError(t, err)
+	connID, _, err = te.Commit(ctx, txID)
+	require.NoError(t, err)
+	require.NoError(t, te.Release(connID))
+
+	// A transaction which reserves its connection keeps its slot after it ends.
+	txID, _, _, err = te.Begin(ctx, nil, 0, nil, options)
+	require.NoError(t, err)
+	connID, err = te.Reserve(ctx, options, txID, nil)
+	require.NoError(t, err)
+	connID, _, err = te.Commit(ctx, connID)
+	require.NoError(t, err)
+	_, err = te.Reserve(ctx, options, 0, nil)
+	require.ErrorContains(t, err, "admission control: workload class batch exceeded its queue timeout")
+
+	require.NoError(t, te.Release(connID))
+	connID, err = te.Reserve(ctx, options, 0, nil)
+	require.NoError(t, err)
+	require.NoError(t, te.Release(connID))
+}
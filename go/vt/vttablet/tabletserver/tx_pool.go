@@ -31,6 +31,7 @@ import (
 	"vitess.io/vitess/go/vt/servenv"
 	"vitess.io/vitess/go/vt/sqlparser"
 	"vitess.io/vitess/go/vt/vterrors"
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/admission"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tabletenv"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/txlimiter"
@@ -65,10 +66,11 @@ type (
 	// concern itself with a connections life cycle. The two exceptions are Begin, which creates a new StatefulConnection,
 	// and RollbackAndRelease, which does a Release after doing the rollback.
 	TxPool struct {
-		env     tabletenv.Env
-		scp     *StatefulConnectionPool
-		ticks   *timer.Timer
-		limiter txlimiter.TxLimiter
+		env       tabletenv.Env
+		scp       *StatefulConnectionPool
+		ticks     *timer.Timer
+		limiter   txlimiter.TxLimiter
+		admission *admission.Controller
 
 		logMu   sync.Mutex
 		lastLog time.Time
@@ -77,14 +79,15 @@ type (
 )
 
 // NewTxPool creates a new TxPool. It's not operational until it's Open'd.
-func NewTxPool(env tabletenv.Env, limiter txlimiter.TxLimiter) *TxPool {
+func NewTxPool(env tabletenv.Env, limiter txlimiter.TxLimiter, admission *admission.Controller) *TxPool {
 	config := env.Config()
 	axp := &TxPool{
-		env:     env,
-		scp:     NewStatefulConnPool(env),
-		ticks:   timer.NewTimer(txKillerTimeoutInterval(config)),
-		limiter: limiter,
-		txStats: env.Exporter().NewTimings("Transactions", "Transaction stats", "operation"),
+		env:       env,
+		scp:       NewStatefulConnPool(env),
+		ticks:     timer.NewTimer(txKillerTimeoutInterval(config)),
+		limiter:   limiter,
+		admission: admission,
+		txStats:   env.Exporter().NewTimings("Transactions", "Transaction stats", "operation"),
 	}
 	// Careful: conns also exports name+"xxx" vars,
 	// but we know it doesn't export Timeout.
@@ -235,12 +238,14 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 	defer span.Finish()
 
 	var conn *StatefulConnection
+	var admissionDone admission.DoneFunc
 	var err error
 	if reservedID != 0 {
 		conn, err = tp.scp.GetAndLock(reservedID, "start transaction on reserve conn")
 		if err != nil {
 			return nil, "", "", vterrors.Errorf(vtrpcpb.Code_ABORTED, "transaction %d: %v", reservedID, err)
 		}
+		// The transaction uses the admission control slot of the reserved connection.
 		// Update conn timeout.
 		timeout := tp.env.Config().TxTimeoutForWorkload(options.GetWorkload())
 		conn.SetTimeout(timeout)
@@ -250,12 +255,22 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 		if !tp.limiter.Get(immediateCaller, effectiveCaller) {
 			return nil, "", "", vterrors.Errorf(vtrpcpb.Code_RESOURCE_EXHAUSTED, "per-user transaction pool connection limit exceeded")
 		}
+		admissionDone, err = tp.admission.Admit(ctx, admission.Transactions, &admission.Request{
+			WorkloadName:    options.GetWorkloadName(),
+			ImmediateCaller: immediateCaller,
+			EffectiveCaller: effectiveCaller,
+		})
+		if err != nil {
+			tp.limiter.Release(immediateCaller, effectiveCaller)
+			return nil, "", "", err
+		}
 		conn, err = tp.createConn(ctx, options, setting)
 		defer func() {
 			if err != nil {
-				// The transaction limiter frees transactions on rollback or commit. If we fail to create the transaction,
-				// release immediately since there will be no rollback or commit.
+				// The transaction limiter and admission control free transactions on rollback or commit. If we fail
+				// to create the transaction, release immediately since there will be no rollback or commit.
 				tp.limiter.Release(immediateCaller, effectiveCaller)
+				admissionDone()
 			}
 		}()
 	}
@@ -268,6 +283,7 @@ func (tp *TxPool) Begin(ctx context.Context, options *querypb.ExecuteOptions, re
 		conn.Release(tx.ConnInitFail)
 		return nil, "", "", err
 	}
+	conn.txProps.AdmissionDone = admissionDone
 	return conn, sql, sessionStateChanges, nil
 }
 
@@ -457,6 +473,9 @@ func (tp *TxPool) LogActive() {
 func (tp *TxPool) txComplete(conn *StatefulConnection, reason tx.ReleaseReason) {
 	conn.LogTransaction(reason)
 	tp.limiter.Release(conn.TxProperties().ImmediateCaller, conn.TxProperties().EffectiveCaller)
+	if done := conn.TxProperties().AdmissionDone; done != nil {
+		done()
+	}
 	conn.CleanTxState()
 }
 
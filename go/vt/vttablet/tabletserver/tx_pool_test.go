@@ -29,6 +29,7 @@ import (
 	"vitess.io/vitess/go/vt/vtenv"
 	"vitess.io/vitess/go/vt/vterrors"
 
+	"vitess.io/vitess/go/vt/vttablet/tabletserver/admission"
 	"vitess.io/vitess/go/vt/vttablet/tabletserver/tx"
 
 	"github.com/stretchr/testify/require"
@@ -328,6 +329,48 @@ func TestTxPoolWaitTimeoutError(t *testing.T) {
 	require.True(t, conn.TxProperties().LogToFile)
 }
 
+func TestTxPoolAdmissionControl(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	env := newEnv("TabletServerTest")
+	env.Config().TxPool.Size = 2
+	env.Config().AdmissionControl.Mode = tabletenv.Enable
+	env.Config().AdmissionControl.Classes = []*tabletenv.AdmissionClassConfig{{
+		Name:          "batch",
+		MaxShare:      0.5,
+		QueueTimeout:  0.01,
+		WorkloadNames: []string{"batch"},
+	}}
+	_, txPool, limiter, closer := setupWithEnv(t, env)
+	defer closer()
+
+	options := &querypb.ExecuteOptions{WorkloadName: "batch"}
+	conn, _, _, err := txPool.Begin(ctx, options, false, 0, nil, nil)
+	require.NoError(t, err)
+
+	// The batch class may only use half of the pool.
+	_, _, _, err = txPool.Begin(ctx, options, false, 0, nil, nil)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "admission control: workload class batch exceeded its queue timeout")
+	require.Equal(t, vtrpcpb.Code_RESOURCE_EXHAUSTED, vterrors.Code(err))
+	require.Len(t, limiter.Actions(), 3)
+	require.True(t, limiter.Actions()[2].isRelease)
+
+	// Other classes are not affected.
+	conn2, _, _, err := txPool.Begin(ctx, &querypb.ExecuteOptions{}, false, 0, nil, nil)
+	require.NoError(t, err)
+	txPool.RollbackAndRelease(ctx, conn2)
+
+	// Completing the transaction frees the slot.
+	_, err = txPool.Commit(ctx, conn)
+	require.NoError(t, err)
+	conn.Release(tx.TxCommit)
+	conn, _, _, err = txPool.Begin(ctx, options, false, 0, nil, nil)
+	require.NoError(t, err)
+	txPool.RollbackAndRelease(ctx, conn)
+}
+
 func TestTxPoolRollbackFailIsPassedThrough(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -806,7 +849,7 @@ func newTxPool() (*TxPool, *fakeLimiter) {
 
 func newTxPoolWithEnv(env tabletenv.Env) (*TxPool, *fakeLimiter) {
 	limiter := &fakeLimiter{}
-	return NewTxPool(env, limiter), limiter
+	return NewTxPool(env, limiter, admission.New(env)), limiter
 }
 
 func newEnv(exporterName string) tabletenv.Env {